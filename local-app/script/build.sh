@@ -1,10 +1,75 @@
 #!/usr/bin/env bash
+# Builds mindnoscape and logviewer as single binaries, the default config built in.
+#
+# Without arguments, builds for this system into bin/ and clears the data and logs.
+# With targets, such as linux/amd64 or windows/amd64, or "all", builds each into bin/<os>_<arch>/ and keeps them.
+# SQLite needs cgo, so targets other than this system need a C compiler for them: the one in CC_<os>_<arch>, such
+# as CC_windows_amd64=x86_64-w64-mingw32-gcc, or zig if installed. Linux binaries are linked statically.
+# The version reported by "system version" is that of git describe. SQLite is built with FTS5 for the full-text index
+# of node find.
+
+ALL_TARGETS="linux/amd64 linux/arm64 darwin/amd64 darwin/arm64 windows/amd64"
+TAGS="sqlite_omit_load_extension sqlite_fts5 osusergo netgo"
+VERSION="$(git describe --tags --always --dirty 2>/dev/null || echo dev)"
+VERSION_FLAG="-X mindnoscape/local-app/src/pkg/buildinfo.Version=$VERSION"
+
+cd "$(dirname "$0")/.." || exit 1
+
+# zig_target prints the zig target of a Go target
+zig_target() {
+    case "$1" in
+        linux/amd64) echo "x86_64-linux-musl" ;;
+        linux/arm64) echo "aarch64-linux-musl" ;;
+        darwin/amd64) echo "x86_64-macos" ;;
+        darwin/arm64) echo "aarch64-macos" ;;
+        windows/amd64) echo "x86_64-windows-gnu" ;;
+        windows/arm64) echo "aarch64-windows-gnu" ;;
+    esac
+}
+
+# build_target builds both binaries for an <os>/<arch> target
+build_target() (
+    os="${1%/*}"
+    arch="${1#*/}"
+    out="bin/${os}_${arch}"
+    ext=""
+    ldflags="-s -w $VERSION_FLAG"
+    [ "$os" = "windows" ] && ext=".exe"
+    [ "$os" = "linux" ] && ldflags="$ldflags -linkmode external -extldflags -static"
+
+    cc_var="CC_${os}_${arch}"
+    if [ -n "${!cc_var}" ]; then
+        export CC="${!cc_var}"
+    elif [ "$1" != "$(go env GOHOSTOS)/$(go env GOHOSTARCH)" ]; then
+        if ! command -v zig >/dev/null || [ -z "$(zig_target "$1")" ]; then
+            echo "Skipping $1: set $cc_var to a C compiler for it or install zig"
+            return 1
+        fi
+        export CC="zig cc -target $(zig_target "$1")"
+    fi
+
+    echo "Building $1..."
+    mkdir -p "$out"
+    export CGO_ENABLED=1 GOOS="$os" GOARCH="$arch"
+    go build -trimpath -tags "$TAGS" -ldflags "$ldflags" -o "$out/mindnoscape$ext" ./src/cmd/mindnoscape/ &&
+        CGO_ENABLED=0 go build -trimpath -ldflags "-s -w" -o "$out/logviewer$ext" ./src/cmd/logviewer/
+)
+
+if [ $# -gt 0 ]; then
+    [ "$1" = "all" ] && set -- $ALL_TARGETS
+    failed=0
+    for target in "$@"; do
+        build_target "$target" || failed=1
+    done
+    echo "Done"
+    exit $failed
+fi
+
 echo "Starting build..."
 go build -o bin/logviewer ./src/cmd/logviewer/
-go build -o bin/mindnoscape ./src/cmd/mindnoscape/
+go build -tags sqlite_fts5 -ldflags "$VERSION_FLAG" -o bin/mindnoscape ./src/cmd/mindnoscape/
 echo "Starting db and config clear..."
 rm data/*
 echo "Starting log clear..."
 rm logs/*
 echo "Done"
-
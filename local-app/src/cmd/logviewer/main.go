@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"github.com/eiannone/keyboard"
+
+	"mindnoscape/local-app/src/pkg/terminal"
 )
 
 const (
@@ -111,6 +113,10 @@ func printLogEntry(entry string) {
 	fmt.Println(entry)
 	lastPrintMutex.Lock()
 	lastPrintTime = time.Now()
+
+	if err := terminal.EnableSequences(); err != nil {
+		fmt.Printf("WARNING: %v, colors may show as escape codes\n", err)
+	}
 	lastPrintMutex.Unlock()
 	gapPrintedMutex.Lock()
 	gapPrinted = false
@@ -12,6 +12,7 @@ import (
 	"mindnoscape/local-app/src/pkg/config"
 	"mindnoscape/local-app/src/pkg/data"
 	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
 	"mindnoscape/local-app/src/pkg/session"
 	"mindnoscape/local-app/src/pkg/storage"
 )
@@ -20,8 +21,16 @@ import (
 // It sets up signal handling, loads configuration, initializes components
 // (logger, storage, data manager, session manager, adapter manager, CLI adapter),
 // runs the CLI, and handles graceful shutdown.
+// With readOnly, or the read_only setting, the database is opened read-only.
+// With the default_user_select setting the default user is selected on start, and then the named mindmap if any.
+// With serve, the web and gRPC clients are served without the CLI until an interrupt signal, which drains them
+// waiting up to shutdown_timeout for the commands being run.
 // Returns an error if any part of the initialization or execution fails.
-func bootstrap() error {
+func bootstrap(readOnly bool, mindmap string, serve bool) error {
+	if serve && mindmap != "" {
+		return fmt.Errorf("--mindmap selects a mindmap for the CLI, which --serve does not run")
+	}
+
 	// Set up channel to receive interrupt signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -38,9 +47,19 @@ func bootstrap() error {
 		return fmt.Errorf("failed to load configuration: %v", err)
 	}
 	cfg := config.ConfigGet()
+	if readOnly {
+		cfg.ReadOnly = true
+	}
+	if serve && cfg.WebAddress == "" && cfg.GRPCAddress == "" {
+		return fmt.Errorf("--serve needs a web_address or grpc_address configured")
+	}
 
-	// Initialize logger with new info logging
-	logger, err := log.NewLogger(cfg, log.LevelInfo)
+	// Initialize logger at the configured level
+	level, err := log.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %v", err)
+	}
+	logger, err := log.NewLogger(cfg, level)
 	if err != nil {
 		return fmt.Errorf("failed to initialize logger: %v", err)
 	}
@@ -72,12 +91,16 @@ func bootstrap() error {
 		logger.Error(context.Background(), "Failed to initialize data manager", log.Fields{"error": err})
 		return fmt.Errorf("failed to initialize data manager: %v", err)
 	}
+	defer dataManager.Close()
 
 	logger.Info(context.Background(), "Data manager initialized", nil)
 
 	// Initialize session manager
 	sessionManager := session.NewSessionManager(dataManager, logger)
 	defer sessionManager.StopCleanupRoutine()
+	defer sessionManager.StopCaptureRoutine()
+	defer sessionManager.StopReminderRoutine()
+	defer sessionManager.StopBackupRoutine()
 
 	logger.Info(context.Background(), "Session manager initialized", nil)
 
@@ -91,6 +114,10 @@ func bootstrap() error {
 
 	logger.Info(context.Background(), "Adapter manager initialized", nil)
 
+	if serve {
+		return serveClients(adapterManager, sessionManager, store, cfg, logger, exitChan)
+	}
+
 	// Initialize CLI
 	cliInstance, err := cli.NewCLI(adapterManager.GetCLIAdapter(), logger)
 	if err != nil {
@@ -100,6 +127,16 @@ func bootstrap() error {
 
 	logger.Info(context.Background(), "CLI instance created", nil)
 
+	// Select the default user and the requested mindmap before the first input
+	var startup []model.Command
+	if cfg.DefaultUserSelect {
+		startup = append(startup, model.Command{Scope: "user", Operation: "select", Args: []string{cfg.DefaultUser}})
+	}
+	if mindmap != "" {
+		startup = append(startup, model.Command{Scope: "mindmap", Operation: "select", Args: []string{mindmap}})
+	}
+	cliInstance.Startup(startup...)
+
 	// Set up graceful shutdown
 	go func() {
 		<-sigChan
@@ -109,6 +146,7 @@ func bootstrap() error {
 	}()
 
 	// Run cli
+	printReadOnly(cfg, store)
 	if err := cliInstance.Run(); err != nil {
 		logger.Error(context.Background(), "CLI error", log.Fields{"error": err})
 		return fmt.Errorf("CLI error: %v", err)
@@ -119,3 +157,42 @@ func bootstrap() error {
 
 	return nil
 }
+
+// serveClients serves the clients of the web and gRPC adapters until exitChan is closed, then drains the adapters.
+// The components are shut down by the deferred calls of bootstrap once it returns.
+func serveClients(adapterManager *adapter.AdapterManager, sessionManager *session.SessionManager, store *storage.Storage,
+	cfg *model.Config, logger *log.Logger, exitChan <-chan struct{}) error {
+	printReadOnly(cfg, store)
+	if cfg.WebAddress != "" {
+		fmt.Printf("Serving web clients on %s\n", cfg.WebAddress)
+	}
+	if cfg.GRPCAddress != "" {
+		fmt.Printf("Serving gRPC clients on %s\n", cfg.GRPCAddress)
+	}
+	logger.Info(context.Background(), "Serving clients", log.Fields{"web": cfg.WebAddress, "grpc": cfg.GRPCAddress})
+
+	<-exitChan
+	logger.Info(context.Background(), "Received interrupt signal. Shutting down...", nil)
+	fmt.Println("\nReceived interrupt signal. Shutting down...")
+
+	if !adapterManager.Drain(sessionManager.ShutdownTimeout()) {
+		fmt.Println("Commands still running at the shutdown timeout were stopped.")
+	}
+
+	logger.Info(context.Background(), "Application shutting down", nil)
+	fmt.Println("Goodbye!")
+	return nil
+}
+
+// printReadOnly tells that the database is opened read-only, and why if it has a newer schema than this version
+// supports
+func printReadOnly(cfg *model.Config, store *storage.Storage) {
+	if version := store.StoredSchemaVersion(); version > storage.SchemaVersion {
+		fmt.Printf("Warning: the database has schema version %d, newer than the %d this version of Mindnoscape supports. "+
+			"It was written by a newer version and is opened read-only, upgrade Mindnoscape to change its data.\n",
+			version, storage.SchemaVersion)
+	}
+	if cfg.ReadOnly {
+		fmt.Println("The database is opened read-only, commands changing data are refused.")
+	}
+}
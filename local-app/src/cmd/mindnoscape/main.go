@@ -3,13 +3,19 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 )
 
 // main is the entry point of the application.
 func main() {
-	if err := bootstrap(); err != nil {
+	readOnly := flag.Bool("read-only", false, "Open the database read-only, refusing commands that change data")
+	mindmap := flag.String("mindmap", "", "Select the named mindmap on start, with the default user selected on start")
+	serve := flag.Bool("serve", false, "Serve the configured web and gRPC clients without the interactive CLI, until interrupted")
+	flag.Parse()
+
+	if err := bootstrap(*readOnly, *mindmap, *serve); err != nil {
 		fmt.Printf("Error bootstrapping the application: %v\n", err)
 		os.Exit(1)
 	}
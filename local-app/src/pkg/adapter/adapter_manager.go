@@ -2,9 +2,12 @@ package adapter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	"mindnoscape/local-app/src/pkg/event"
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
 	"mindnoscape/local-app/src/pkg/session"
@@ -17,16 +20,23 @@ const (
 	AdapterTypeAPI = "API"
 )
 
+// ErrShuttingDown is returned by CommandRun for the commands of clients once the adapters are drained
+var ErrShuttingDown = errors.New("server is shutting down")
+
 // AdapterManager manages all adapter instances
 type AdapterManager struct {
-	CLIAdapter *CLIAdapter
-	//	WebAdapter     *WebAdapter // Placeholder for future implementation
+	CLIAdapter  *CLIAdapter
+	WebAdapter  *WebAdapter  // Serves WebSocket clients, nil unless a web address is configured
+	GRPCAdapter *GRPCAdapter // Serves gRPC clients, nil unless a gRPC address is configured
 	//	APIAdapter     *APIAdapter // Placeholder for future implementation
 	adapterMutex    sync.RWMutex
 	sessionManager  *session.SessionManager
 	adapterSessions sync.Map
 	cmdChan         chan commandRequest
 	stopChan        chan struct{}
+	drainMutex      sync.Mutex
+	draining        bool           // Set by Drain, new commands being refused
+	running         sync.WaitGroup // Commands being run, waited for by Drain
 	logger          *log.Logger
 }
 
@@ -43,6 +53,23 @@ type commandResult struct {
 	Error  error
 }
 
+// mindmapEvents are the events of changes to a mindmap that adapters push to the clients that have it selected
+var mindmapEvents = []event.EventType{event.NodeAdded, event.NodeUpdated, event.NodeDeleted, event.NodeSorted, event.MindmapUpdated}
+
+// mindmapChange returns the mindmap changed by an event of mindmapEvents, and the node changed if the event has one
+func mindmapChange(e event.Event) (*model.Mindmap, *model.Node, error) {
+	data, ok := e.Data.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid data for %s event", e.Type)
+	}
+	mindmap, ok := data["mindmap"].(*model.Mindmap)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s event without a mindmap", e.Type)
+	}
+	node, _ := data["node"].(*model.Node)
+	return mindmap, node, nil
+}
+
 // NewAdapterManager creates a new AdapterManager
 func NewAdapterManager(sm *session.SessionManager, logger *log.Logger) (*AdapterManager, error) {
 	am := &AdapterManager{
@@ -59,10 +86,29 @@ func NewAdapterManager(sm *session.SessionManager, logger *log.Logger) (*Adapter
 	}
 	am.CLIAdapter = cliAdapter
 
+	// Initialize the web adapter if configured
+	if address := sm.WebAddress(); address != "" {
+		am.WebAdapter = NewWebAdapter(am, logger)
+		if err := am.WebAdapter.AdapterStart(address); err != nil {
+			return nil, fmt.Errorf("failed to start web adapter: %v", err)
+		}
+	}
+
+	// Initialize the gRPC adapter if configured
+	if address := sm.GRPCAddress(); address != "" {
+		am.GRPCAdapter = NewGRPCAdapter(am, logger)
+		if err := am.GRPCAdapter.AdapterStart(address); err != nil {
+			if am.WebAdapter != nil {
+				am.WebAdapter.AdapterStop()
+			}
+			return nil, fmt.Errorf("failed to start gRPC adapter: %v", err)
+		}
+	}
+
 	// Initialize other adapters here as needed
-	// am.WebAdapter = NewWebAdapter(am, logger)
 	// am.APIAdapter = NewAPIAdapter(am, logger)
 
+	sm.Subscribe(event.SessionExpired, am.handleSessionExpired)
 	go am.commandHandler()
 
 	am.logger.Info(context.Background(), "AdapterManager initialized", nil)
@@ -92,13 +138,51 @@ func (am *AdapterManager) SessionAdd() (string, error) {
 	return sessionID, nil
 }
 
+// SessionDelete removes a session that its client closed
+func (am *AdapterManager) SessionDelete(sessionID string) {
+	am.adapterSessions.Delete(sessionID)
+	am.sessionManager.SessionDelete(sessionID)
+	am.logger.Info(context.Background(), "Session closed", log.Fields{"sessionID": sessionID})
+}
+
 // SessionGet retrieves a session by its ID
 func (am *AdapterManager) SessionGet(sessionID string) (*model.Session, bool) {
 	return am.sessionManager.SessionGet(sessionID)
 }
 
+// handleSessionExpired drops a session removed by the session manager for inactivity from the adapters
+func (am *AdapterManager) handleSessionExpired(e event.Event) {
+	sessionID, ok := e.Data.(string)
+	if !ok {
+		am.logger.Error(context.Background(), "Invalid SessionExpired event data", log.Fields{"data": e.Data})
+		return
+	}
+
+	am.adapterSessions.Delete(sessionID)
+	if am.CLIAdapter != nil {
+		am.CLIAdapter.SessionExpire(sessionID)
+	}
+	if am.WebAdapter != nil {
+		am.WebAdapter.SessionExpire(sessionID)
+	}
+	if am.GRPCAdapter != nil {
+		am.GRPCAdapter.SessionExpire(sessionID)
+	}
+	am.logger.Info(context.Background(), "Expired session dropped", log.Fields{"sessionID": sessionID})
+}
+
 // CommandRun runs a command on a specific adapter instance
 func (am *AdapterManager) CommandRun(sessionID string, cmd model.Command) (interface{}, error) {
+	am.drainMutex.Lock()
+	if am.draining {
+		am.drainMutex.Unlock()
+		am.logger.Warn(context.Background(), "Command refused, shutting down", log.Fields{"sessionID": sessionID, "command": cmd})
+		return nil, ErrShuttingDown
+	}
+	am.running.Add(1)
+	am.drainMutex.Unlock()
+	defer am.running.Done()
+
 	am.logger.Info(context.Background(), "Processing command through adapter manager", log.Fields{"sessionID": sessionID, "command": cmd})
 
 	// Log command in command log
@@ -126,6 +210,38 @@ func (am *AdapterManager) CommandRun(sessionID string, cmd model.Command) (inter
 	return result.Result, nil
 }
 
+// Drain stops the web and gRPC adapters taking connections and commands, and waits up to timeout for the commands
+// being run to finish. Returns false if some were still running at the timeout. The adapters are stopped by
+// Shutdown afterwards.
+func (am *AdapterManager) Drain(timeout time.Duration) bool {
+	ctx := context.Background()
+
+	am.drainMutex.Lock()
+	am.draining = true
+	am.drainMutex.Unlock()
+
+	if am.WebAdapter != nil {
+		am.WebAdapter.AdapterDrain()
+	}
+	if am.GRPCAdapter != nil {
+		am.GRPCAdapter.AdapterDrain()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		am.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		am.logger.Info(ctx, "Adapters drained", nil)
+		return true
+	case <-time.After(timeout):
+		am.logger.Warn(ctx, "Commands still running at the shutdown timeout", log.Fields{"timeout": timeout.String()})
+		return false
+	}
+}
+
 // Shutdown stops all adapter instances and the command handler
 func (am *AdapterManager) Shutdown() {
 	close(am.stopChan)
@@ -133,10 +249,13 @@ func (am *AdapterManager) Shutdown() {
 	if am.CLIAdapter != nil {
 		am.CLIAdapter.AdapterStop()
 	}
+	if am.WebAdapter != nil {
+		am.WebAdapter.AdapterStop()
+	}
+	if am.GRPCAdapter != nil {
+		am.GRPCAdapter.AdapterStop()
+	}
 	// Stop other adapters when implemented
-	// if am.WebAdapter != nil {
-	//     am.WebAdapter.AdapterStop()
-	// }
 	// if am.APIAdapter != nil {
 	//     am.APIAdapter.AdapterStop()
 	// }
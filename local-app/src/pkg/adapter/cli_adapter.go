@@ -1,19 +1,28 @@
-// Package adapter provides an adapter for the CLI interface to interact with the session package.
+// Package adapter provides the CLI, WebSocket and gRPC adapters through which clients interact with the session package.
 package adapter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
 	"strings"
 	"sync"
 
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/session"
 )
 
+// ErrSessionExpired is returned for input to a session that expired after inactivity
+var ErrSessionExpired = errors.New("session expired after inactivity")
+
 // CLIAdapter provides command-line interface support for managing multiple CLI connections
 type CLIAdapter struct {
 	sessions       map[string]*model.Session
+	expired        map[string]bool // Sessions removed for inactivity, until their connection notices
 	sessionMutex   sync.RWMutex
 	adapterManager *AdapterManager
 	logger         *log.Logger
@@ -24,6 +33,7 @@ func NewCLIAdapter(am *AdapterManager, logger *log.Logger) (*CLIAdapter, error)
 	logger.Info(context.Background(), "Creating new CLI adapter", nil)
 	return &CLIAdapter{
 		sessions:       make(map[string]*model.Session),
+		expired:        make(map[string]bool),
 		adapterManager: am,
 		logger:         logger,
 	}, nil
@@ -58,6 +68,16 @@ func (a *CLIAdapter) SessionAdd() (string, error) {
 		return "", fmt.Errorf("session %s does not exist after addition by cli adapter", sessionID)
 	}
 
+	// Show the progress of long-running commands instead of a frozen prompt
+	session.Progress = progressRenderer(os.Stdout)
+	session.Passphrase = passphrasePrompt(os.Stdout)
+	if isTerminal() {
+		session.Resolve = conflictPrompt(os.Stdout)
+		session.Confirm = confirmPrompt(os.Stdout)
+	}
+	// Heavy commands run in the background on a terminal, the results are shown before the next prompt
+	session.Background = isTerminal()
+
 	a.sessionMutex.Lock()
 	a.sessions[sessionID] = session
 	a.sessionMutex.Unlock()
@@ -74,15 +94,113 @@ func (a *CLIAdapter) SessionDelete(sessionID string) {
 	a.logger.Info(context.Background(), "CLI session removed", log.Fields{"sessionID": sessionID})
 }
 
-// ProcessInput converts the input string into command and runs it
+// SessionExpire removes a cli session that expired after inactivity, failing the next input to it
+func (a *CLIAdapter) SessionExpire(sessionID string) {
+	a.sessionMutex.Lock()
+	defer a.sessionMutex.Unlock()
+	if _, exists := a.sessions[sessionID]; !exists {
+		return
+	}
+	delete(a.sessions, sessionID)
+	a.expired[sessionID] = true
+	a.logger.Info(context.Background(), "CLI session expired", log.Fields{"sessionID": sessionID})
+}
+
+// ProcessInput converts the input string into command and runs it.
+// ErrSessionExpired is returned once for input to an expired session.
 func (a *CLIAdapter) ProcessInput(connID string, input string) (interface{}, error) {
+	a.sessionMutex.Lock()
+	expired := a.expired[connID]
+	delete(a.expired, connID)
+	a.sessionMutex.Unlock()
+	if expired {
+		return nil, ErrSessionExpired
+	}
+
 	cmd, err := a.parseCommand(input)
 	if err != nil {
 		return nil, err
 	}
+	if cmd.Scope == "node" && cmd.Operation == "edit" {
+		return a.nodeEdit(connID, cmd)
+	}
 	return a.adapterManager.CommandRun(connID, cmd)
 }
 
+// CommandRun runs a command prepared by the CLI, such as the selections on start, without parsing it from input
+func (a *CLIAdapter) CommandRun(connID string, cmd model.Command) (interface{}, error) {
+	return a.adapterManager.CommandRun(connID, cmd)
+}
+
+// nodeEdit handles the node edit command of the CLI: the name of the node, and its fields as label:value with
+// --fields, are edited in place on the terminal and saved by running node update. Without --fields, the fields
+// of the node are kept as they are.
+func (a *CLIAdapter) nodeEdit(connID string, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	var identifier string
+	useID, withFields := false, false
+	for _, arg := range cmd.Args {
+		switch arg {
+		case "--id":
+			useID = true
+		case "--fields":
+			withFields = true
+		default:
+			if identifier != "" {
+				return nil, errors.New("node edit command requires 1 to 3 arguments: <node> [--fields] [--id]")
+			}
+			identifier = arg
+		}
+	}
+	if identifier == "" {
+		return nil, errors.New("node edit command requires 1 to 3 arguments: <node> [--fields] [--id]")
+	}
+	if !isTerminal() {
+		return nil, errors.New("node edit requires a terminal, use node update instead")
+	}
+	if a.adapterManager.sessionManager.ReadOnly() {
+		return nil, errors.New("node edit is not available, the database is opened read-only")
+	}
+
+	node, err := a.adapterManager.sessionManager.NodeGet(connID, identifier, useID)
+	if err != nil {
+		a.logger.Error(ctx, "Failed to get node for editing", log.Fields{"error": err, "identifier": identifier})
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	fields := make([]string, 0, len(node.Content))
+	for _, key := range slices.Sorted(maps.Keys(node.Content)) {
+		fields = append(fields, key+":"+node.Content[key])
+	}
+	initial := node.Name
+	if withFields {
+		initial = strings.Join(append([]string{node.Name}, fields...), " ")
+	}
+
+	edited, ok, err := lineEdit(os.Stdout, "edit> ", initial)
+	if err != nil {
+		a.logger.Error(ctx, "Failed to edit node", log.Fields{"error": err, "nodeID": node.ID})
+		return nil, err
+	}
+	if !ok || edited == initial {
+		return "Node unchanged", nil
+	}
+	args := strings.Fields(edited)
+	if len(args) == 0 {
+		return nil, errors.New("node name cannot be empty")
+	}
+
+	update := model.Command{Scope: "node", Operation: "update", Args: append([]string{identifier}, args...)}
+	if !withFields {
+		update.Args = append(update.Args, fields...)
+	}
+	if useID {
+		update.Args = append(update.Args, "--id")
+	}
+	a.logger.Info(ctx, "Node edited", log.Fields{"nodeID": node.ID, "command": update})
+	return a.adapterManager.CommandRun(connID, update)
+}
+
 func (a *CLIAdapter) parseCommand(input string) (model.Command, error) {
 	args := strings.Fields(input)
 	if len(args) == 0 {
@@ -96,7 +214,10 @@ func (a *CLIAdapter) parseCommand(input string) (model.Command, error) {
 		Args:      []string{},
 	}
 
-	if len(args) > 1 {
+	// The add command has no operation, its text is taken as typed
+	if cmd.Scope == "add" {
+		cmd.Args = args[1:]
+	} else if len(args) > 1 {
 		cmd.Operation = strings.ToLower(args[1])
 		cmd.Args = args[2:]
 	}
@@ -105,26 +226,114 @@ func (a *CLIAdapter) parseCommand(input string) (model.Command, error) {
 	return cmd, nil
 }
 
-// PromptGet gets the current prompt of the session
+// PromptGet gets the current prompt of the session, rendered from the configured template with the current
+// user, mindmap and node. It is built anew for each input, so it always follows the selections of the session.
 func (a *CLIAdapter) PromptGet(sessionID string) string {
 	a.sessionMutex.RLock()
 	defer a.sessionMutex.RUnlock()
 
+	values := map[string]string{"user": "", "mindmap": "", "node": "", "jobs": promptJobs(a.adapterManager.sessionManager.Jobs(sessionID))}
 	session, exists := a.sessions[sessionID]
 	if !exists {
 		a.logger.Warn(context.Background(), "Session not found", log.Fields{"sessionID": sessionID})
-		return "> "
+	} else if session.User != nil {
+		values["user"] = session.User.Username
+		if session.Mindmap != nil {
+			values["mindmap"] = session.Mindmap.Name
+		}
+		if node := session.CurrentNode(); node != nil {
+			values["node"] = a.adapterManager.sessionManager.DisplayStyle().DisplayIndex(node.Index)
+		}
+	}
+
+	// Templates without a place for the running jobs show them in front
+	template := a.adapterManager.sessionManager.PromptTemplate()
+	if !strings.Contains(template, "{jobs}") {
+		template = "[({jobs}) ]" + template
+	}
+	return promptFormat(template, values)
+}
+
+// promptJobs describes the running jobs of a session for the prompt, such as "job 3 40%" or "2 jobs", empty if
+// none runs
+func promptJobs(jobs []model.Job) string {
+	var running []model.Job
+	for _, j := range jobs {
+		if j.Running() {
+			running = append(running, j)
+		}
+	}
+	switch {
+	case len(running) == 0:
+		return ""
+	case len(running) > 1:
+		return fmt.Sprintf("%d jobs", len(running))
+	case running[0].Progress.Total > 0:
+		return fmt.Sprintf("job %d %d%%", running[0].ID, 100*running[0].Progress.Done/running[0].Progress.Total)
+	default:
+		return fmt.Sprintf("job %d", running[0].ID)
+	}
+}
+
+// JobsFinished returns the results of the background jobs of a session that finished since the last call
+func (a *CLIAdapter) JobsFinished(sessionID string) []string {
+	var results []string
+	for _, j := range a.adapterManager.sessionManager.JobsFinished(sessionID) {
+		results = append(results, session.FormatJob(j))
+	}
+	return results
+}
+
+// JobsRunning returns the number of running background jobs of a session
+func (a *CLIAdapter) JobsRunning(sessionID string) int {
+	running := 0
+	for _, j := range a.adapterManager.sessionManager.Jobs(sessionID) {
+		if j.Running() {
+			running++
+		}
 	}
+	return running
+}
+
+// JobsWait waits for the running background jobs of a session to finish
+func (a *CLIAdapter) JobsWait(sessionID string) {
+	a.adapterManager.sessionManager.JobsWait(sessionID)
+}
 
-	if session.User == nil {
-		a.logger.Warn(context.Background(), "No user selected", log.Fields{"sessionID": sessionID})
-		return "> "
+// promptFormat fills the {name} placeholders of a prompt template with values. A section in brackets is left out
+// unless all placeholders in it have values, and the spaces an empty value leaves at the start are trimmed.
+func promptFormat(template string, values map[string]string) string {
+	pairs := make([]string, 0, 2*len(values))
+	for name, value := range values {
+		pairs = append(pairs, "{"+name+"}", value)
 	}
+	replacer := strings.NewReplacer(pairs...)
 
-	if session.Mindmap == nil {
-		a.logger.Warn(context.Background(), "No mindmap selected", log.Fields{"sessionID": sessionID})
-		return fmt.Sprintf("%s > ", session.User.Username)
+	var b strings.Builder
+	for template != "" {
+		start := strings.IndexByte(template, '[')
+		end := strings.IndexByte(template[max(start, 0):], ']') + start
+		if start < 0 || end < start {
+			b.WriteString(replacer.Replace(template))
+			break
+		}
+
+		b.WriteString(replacer.Replace(template[:start]))
+		section := template[start+1 : end]
+		if sectionFilled(section, values) {
+			b.WriteString(replacer.Replace(section))
+		}
+		template = template[end+1:]
 	}
+	return strings.TrimLeft(b.String(), " ")
+}
 
-	return fmt.Sprintf("%s @ %s > ", session.User.Username, session.Mindmap.Name)
+// sectionFilled reports whether all placeholders in a section of a prompt template have values
+func sectionFilled(section string, values map[string]string) bool {
+	for name, value := range values {
+		if value == "" && strings.Contains(section, "{"+name+"}") {
+			return false
+		}
+	}
+	return true
 }
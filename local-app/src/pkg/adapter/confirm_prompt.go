@@ -0,0 +1,21 @@
+package adapter
+
+import (
+	"io"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// confirmPrompt returns a ConfirmFunc asking a question on out, answered with y or yes. Any other answer, Escape
+// and Ctrl+C decline.
+func confirmPrompt(out io.Writer) model.ConfirmFunc {
+	return func(question string) (bool, error) {
+		answer, ok, err := lineEdit(out, question+" [y/N] ", "")
+		if err != nil || !ok {
+			return false, err
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes", nil
+	}
+}
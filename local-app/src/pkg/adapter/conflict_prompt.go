@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// conflictPrompt returns a ConflictFunc showing both sides of a conflict of an import on out and asking whether to
+// keep mine, theirs or both, or to edit the node starting from theirs. Escape and Ctrl+C cancel the import.
+func conflictPrompt(out io.Writer) model.ConflictFunc {
+	return func(conflict model.ImportConflict) (model.ConflictResolution, error) {
+		mine, theirs := conflict.Mine, conflict.Theirs
+		fmt.Fprintf(out, "Conflict on node %s, changed in the mindmap since the file's copy:\n", mine.Index)
+		fmt.Fprintf(out, "  mine:   %s\n", conflictNode(mine))
+		fmt.Fprintf(out, "  theirs: %s\n", conflictNode(theirs))
+
+		for {
+			answer, ok, err := lineEdit(out, "Keep [m]ine, [t]heirs, [b]oth or [e]dit? ", "")
+			if err != nil {
+				return model.ConflictResolution{}, err
+			}
+			if !ok {
+				return model.ConflictResolution{}, errors.New("import canceled")
+			}
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "m", "mine":
+				return model.ConflictResolution{Policy: model.ConflictMine}, nil
+			case "t", "theirs":
+				return model.ConflictResolution{Policy: model.ConflictTheirs}, nil
+			case "b", "both":
+				return model.ConflictResolution{Policy: model.ConflictBoth}, nil
+			case "e", "edit":
+				return conflictEdit(out, mine, theirs)
+			}
+		}
+	}
+}
+
+// conflictEdit lets the user edit the name and the differing fields of a conflicting node, each starting from
+// theirs. A field edited to empty is removed.
+func conflictEdit(out io.Writer, mine, theirs *model.Node) (model.ConflictResolution, error) {
+	resolution := model.ConflictResolution{Policy: model.ConflictEdit, Content: maps.Clone(mine.Content)}
+	if resolution.Content == nil {
+		resolution.Content = make(map[string]string)
+	}
+	maps.Copy(resolution.Content, theirs.Content)
+
+	name, ok, err := lineEdit(out, "Name: ", cmp.Or(theirs.Name, mine.Name))
+	if err != nil || !ok {
+		return model.ConflictResolution{}, cmp.Or(err, errors.New("import canceled"))
+	}
+	resolution.Name = name
+
+	for _, label := range slices.Sorted(maps.Keys(resolution.Content)) {
+		if mine.Content[label] == theirs.Content[label] {
+			continue
+		}
+		value, ok, err := lineEdit(out, label+": ", resolution.Content[label])
+		if err != nil || !ok {
+			return model.ConflictResolution{}, cmp.Or(err, errors.New("import canceled"))
+		}
+		resolution.Content[label] = value
+	}
+	return resolution, nil
+}
+
+// conflictNode formats the name and fields of a side of a conflict on a line
+func conflictNode(node *model.Node) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "'%s'", node.Name)
+	for _, label := range slices.Sorted(maps.Keys(node.Content)) {
+		fmt.Fprintf(&b, ", %s: %s", label, node.Content[label])
+	}
+	return b.String()
+}
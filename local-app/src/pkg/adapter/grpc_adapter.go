@@ -0,0 +1,343 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"mindnoscape/local-app/src/pkg/adapter/grpcapi"
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// grpcEventQueue is the number of events waiting to be sent on a stream before it is ended as too slow
+const grpcEventQueue = 256
+
+// GRPCAdapter serves the command protocol as the gRPC service of grpcapi, for typed clients in any language. Its
+// clients open sessions to run commands in, and stream the changes to the mindmap a session has selected.
+type GRPCAdapter struct {
+	grpcapi.UnimplementedMindnoscapeServer
+	server         *grpc.Server
+	sessions       map[string]*model.Session // Sessions of the adapter, until closed or expired
+	peers          map[string]string         // Connection each session is bound to, the one that opened or resumed it
+	maxSessions    int                       // Sessions open at once, any number if 0 or less
+	streams        map[*grpcStream]bool
+	mutex          sync.RWMutex
+	adapterManager *AdapterManager
+	logger         *log.Logger
+}
+
+// grpcStream is a running Events call, the events of its session queued for it
+type grpcStream struct {
+	sessionID string
+	events    chan *grpcapi.Event
+	done      chan struct{}
+	reason    error // Why the stream was ended by the adapter
+	closeOnce sync.Once
+}
+
+// NewGRPCAdapter creates a new GRPCAdapter, subscribed to the changes of mindmaps. It takes calls once started.
+func NewGRPCAdapter(am *AdapterManager, logger *log.Logger) *GRPCAdapter {
+	logger.Info(context.Background(), "Creating new gRPC adapter", nil)
+	a := &GRPCAdapter{
+		sessions:       make(map[string]*model.Session),
+		peers:          make(map[string]string),
+		maxSessions:    am.sessionManager.MaxConnections(),
+		streams:        make(map[*grpcStream]bool),
+		adapterManager: am,
+		logger:         logger,
+	}
+	for _, eventType := range mindmapEvents {
+		am.sessionManager.Subscribe(eventType, a.handleMindmapEvent)
+	}
+	return a
+}
+
+// AdapterStart listens on address, such as 127.0.0.1:9090, and serves gRPC calls in the background
+func (a *GRPCAdapter) AdapterStart(address string) error {
+	ctx := context.Background()
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		a.logger.Error(ctx, "Failed to listen for gRPC clients", log.Fields{"error": err, "address": address})
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	a.server = grpc.NewServer()
+	grpcapi.RegisterMindnoscapeServer(a.server, a)
+	go func() {
+		if err := a.server.Serve(listener); err != nil {
+			a.logger.Error(ctx, "gRPC adapter stopped serving", log.Fields{"error": err})
+		}
+	}()
+
+	a.logger.Info(ctx, "gRPC adapter listening", log.Fields{"address": listener.Addr().String()})
+	return nil
+}
+
+// AdapterDrain stops taking calls and ends the event streams, the running calls finishing in the background until
+// the adapter is stopped
+func (a *GRPCAdapter) AdapterDrain() {
+	if a.server == nil {
+		return
+	}
+	a.mutex.RLock()
+	for s := range a.streams {
+		s.close(status.Error(codes.Unavailable, "server shutting down"))
+	}
+	a.mutex.RUnlock()
+	go a.server.GracefulStop()
+	a.logger.Info(context.Background(), "gRPC adapter no longer taking calls", nil)
+}
+
+// AdapterStop stops serving, ending the running calls
+func (a *GRPCAdapter) AdapterStop() error {
+	a.logger.Info(context.Background(), "gRPC adapter stopping", nil)
+	if a.server != nil {
+		a.server.Stop()
+	}
+	a.logger.Info(context.Background(), "gRPC adapter stopped", nil)
+	return nil
+}
+
+// SessionOpen opens a session for a client, or resumes the session it gives if it is one of the adapter's. The
+// session is bound to the connection of the call, the calls of other connections refused until they resume it.
+// Sessions beyond max_connections are refused.
+func (a *GRPCAdapter) SessionOpen(ctx context.Context, req *grpcapi.SessionOpenRequest) (*grpcapi.SessionOpenResponse, error) {
+	a.mutex.Lock()
+	if _, exists := a.sessions[req.GetSession()]; exists && req.GetSession() != "" {
+		a.peers[req.GetSession()] = grpcPeer(ctx)
+		a.mutex.Unlock()
+		a.logger.Info(ctx, "gRPC session resumed", log.Fields{"sessionID": req.GetSession(), "peer": grpcPeer(ctx)})
+		return &grpcapi.SessionOpenResponse{Session: req.GetSession()}, nil
+	}
+	full := a.maxSessions > 0 && len(a.sessions) >= a.maxSessions
+	a.mutex.Unlock()
+	if full {
+		a.logger.Warn(ctx, "gRPC session refused, too many sessions", log.Fields{"peer": grpcPeer(ctx), "limit": a.maxSessions})
+		return nil, status.Error(codes.ResourceExhausted, "too many sessions, close one or try again later")
+	}
+
+	sessionID, err := a.adapterManager.SessionAdd()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	session, exists := a.adapterManager.SessionGet(sessionID)
+	if !exists {
+		return nil, status.Error(codes.Internal, "session not found after adding it")
+	}
+	session.Authenticate = true
+	a.mutex.Lock()
+	a.sessions[sessionID] = session
+	a.peers[sessionID] = grpcPeer(ctx)
+	a.mutex.Unlock()
+
+	a.logger.Info(ctx, "New gRPC session added", log.Fields{"sessionID": sessionID})
+	return &grpcapi.SessionOpenResponse{Session: sessionID}, nil
+}
+
+// SessionClose closes a session of the adapter, ending its event streams
+func (a *GRPCAdapter) SessionClose(ctx context.Context, req *grpcapi.SessionCloseRequest) (*grpcapi.SessionCloseResponse, error) {
+	if _, err := a.session(ctx, req.GetSession()); err != nil {
+		return nil, err
+	}
+	a.sessionDrop(req.GetSession(), status.Error(codes.Canceled, "session closed"))
+	a.adapterManager.SessionDelete(req.GetSession())
+	return &grpcapi.SessionCloseResponse{}, nil
+}
+
+// CommandRun runs a command in a session of the adapter
+func (a *GRPCAdapter) CommandRun(ctx context.Context, req *grpcapi.CommandRunRequest) (*grpcapi.CommandRunResponse, error) {
+	if _, err := a.session(ctx, req.GetSession()); err != nil {
+		return nil, err
+	}
+	if req.GetCommand().GetScope() == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty command")
+	}
+
+	cmd := model.Command{
+		Scope:     strings.ToLower(req.GetCommand().GetScope()),
+		Operation: strings.ToLower(req.GetCommand().GetOperation()),
+		Args:      append([]string{}, req.GetCommand().GetArgs()...),
+	}
+	if cmd.Scope == "node" && cmd.Operation == "edit" {
+		return nil, status.Error(codes.InvalidArgument, "node edit requires a terminal, use node update instead")
+	}
+	result, err := a.adapterManager.CommandRun(req.GetSession(), cmd)
+	if errors.Is(err, ErrShuttingDown) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+	return grpcResult(result), nil
+}
+
+// grpcResult returns the typed response of the result of a command. Values other than text, numbers and flags are
+// returned as JSON, or as text if they cannot be encoded.
+func grpcResult(result interface{}) *grpcapi.CommandRunResponse {
+	switch value := result.(type) {
+	case nil:
+		return &grpcapi.CommandRunResponse{}
+	case string:
+		return &grpcapi.CommandRunResponse{Result: &grpcapi.CommandRunResponse_Text{Text: value}}
+	case int:
+		return &grpcapi.CommandRunResponse{Result: &grpcapi.CommandRunResponse_Number{Number: int64(value)}}
+	case int64:
+		return &grpcapi.CommandRunResponse{Result: &grpcapi.CommandRunResponse_Number{Number: value}}
+	case bool:
+		return &grpcapi.CommandRunResponse{Result: &grpcapi.CommandRunResponse_Flag{Flag: value}}
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return &grpcapi.CommandRunResponse{Result: &grpcapi.CommandRunResponse_Text{Text: fmt.Sprint(result)}}
+	}
+	return &grpcapi.CommandRunResponse{Result: &grpcapi.CommandRunResponse_Json{Json: string(data)}}
+}
+
+// Events streams the changes to the mindmap selected in a session of the adapter. The stream ends with an error
+// status once the session is closed or expires, or if the client is too slow to take the events.
+func (a *GRPCAdapter) Events(req *grpcapi.EventsRequest, stream grpc.ServerStreamingServer[grpcapi.Event]) error {
+	if _, err := a.session(stream.Context(), req.GetSession()); err != nil {
+		return err
+	}
+
+	s := &grpcStream{sessionID: req.GetSession(), events: make(chan *grpcapi.Event, grpcEventQueue), done: make(chan struct{})}
+	a.mutex.Lock()
+	a.streams[s] = true
+	a.mutex.Unlock()
+	defer func() {
+		a.mutex.Lock()
+		delete(a.streams, s)
+		a.mutex.Unlock()
+	}()
+	a.logger.Info(stream.Context(), "gRPC event stream started", log.Fields{"sessionID": s.sessionID})
+
+	for {
+		select {
+		case e := <-s.events:
+			if err := stream.Send(e); err != nil {
+				a.logger.Warn(stream.Context(), "Failed to send gRPC event", log.Fields{"error": err, "sessionID": s.sessionID})
+				return err
+			}
+		case <-s.done:
+			return s.reason
+		case <-stream.Context().Done():
+			a.logger.Info(stream.Context(), "gRPC event stream ended", log.Fields{"sessionID": s.sessionID})
+			return nil
+		}
+	}
+}
+
+// session returns a session of the adapter for a call, a NotFound status if the client has none by that ID and a
+// FailedPrecondition status if the session is bound to another connection
+func (a *GRPCAdapter) session(ctx context.Context, sessionID string) (*model.Session, error) {
+	a.mutex.RLock()
+	session, exists := a.sessions[sessionID]
+	bound := a.peers[sessionID]
+	a.mutex.RUnlock()
+	if !exists || sessionID == "" {
+		return nil, status.Error(codes.NotFound, "session not found, open one with SessionOpen")
+	}
+	if bound != grpcPeer(ctx) {
+		return nil, status.Error(codes.FailedPrecondition, "session is bound to another connection, resume it with SessionOpen")
+	}
+	return session, nil
+}
+
+// grpcPeer returns the address of the client connection of a call, empty if unknown
+func grpcPeer(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// SessionExpire removes a gRPC session that expired after inactivity, ending its event streams
+func (a *GRPCAdapter) SessionExpire(sessionID string) {
+	if a.sessionDrop(sessionID, status.Error(codes.NotFound, "session expired after inactivity")) {
+		a.logger.Info(context.Background(), "gRPC session expired", log.Fields{"sessionID": sessionID})
+	}
+}
+
+// sessionDrop removes a session of the adapter and ends its event streams with reason. Returns false if the
+// session is not one of the adapter's.
+func (a *GRPCAdapter) sessionDrop(sessionID string, reason error) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if _, exists := a.sessions[sessionID]; !exists {
+		return false
+	}
+	delete(a.sessions, sessionID)
+	delete(a.peers, sessionID)
+	for s := range a.streams {
+		if s.sessionID == sessionID {
+			s.close(reason)
+		}
+	}
+	return true
+}
+
+// handleMindmapEvent queues a change to a mindmap on the streams whose session has it selected, and may see the
+// node changed, see session.SessionManager.MindmapChangeSessions. A stream too slow to take its events is ended,
+// for the client to load the mindmap again and stream anew.
+func (a *GRPCAdapter) handleMindmapEvent(e event.Event) {
+	ctx := context.Background()
+	mindmap, node, err := mindmapChange(e)
+	if err != nil {
+		a.logger.Error(ctx, "Invalid event for gRPC clients", log.Fields{"error": err, "event": e.Type.String()})
+		return
+	}
+
+	a.mutex.RLock()
+	var sessionIDs []string
+	for s := range a.streams {
+		sessionIDs = append(sessionIDs, s.sessionID)
+	}
+	a.mutex.RUnlock()
+	if len(sessionIDs) == 0 {
+		return
+	}
+
+	recipients, node, err := a.adapterManager.sessionManager.MindmapChangeSessions(mindmap, node, sessionIDs)
+	if err != nil {
+		a.logger.Error(ctx, "Failed to find the gRPC streams of a change", log.Fields{"error": err, "event": e.Type.String()})
+		return
+	}
+	change := &grpcapi.Event{Type: e.Type.String(), MindmapId: int64(mindmap.ID)}
+	if node != nil {
+		change.Node = &grpcapi.Node{Id: int64(node.ID), ParentId: int64(node.ParentID), Name: node.Name, Index: node.Index, Content: node.Content}
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	for s := range a.streams {
+		if !slices.Contains(recipients, s.sessionID) {
+			continue
+		}
+		select {
+		case s.events <- change:
+		default:
+			a.logger.Warn(ctx, "gRPC client too slow for its events, ending the stream", log.Fields{"sessionID": s.sessionID})
+			s.close(status.Error(codes.ResourceExhausted, "too slow to take the events, stream them again"))
+		}
+	}
+}
+
+// close ends the stream with reason
+func (s *grpcStream) close(reason error) {
+	s.closeOnce.Do(func() {
+		s.reason = reason
+		close(s.done)
+	})
+}
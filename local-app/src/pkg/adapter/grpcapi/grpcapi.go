@@ -0,0 +1,5 @@
+// Package grpcapi holds the gRPC service of Mindnoscape, generated from mindnoscape.proto for the gRPC adapter and
+// Go clients. Clients in other languages generate their code from the same file.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative mindnoscape.proto
@@ -0,0 +1,906 @@
+// The gRPC service of Mindnoscape: the commands of the CLI, run in sessions, and the changes to the selected
+// mindmap streamed as they happen. Generate the Go code with 'go generate' in this directory.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: mindnoscape.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Command is a command as typed into the CLI: "node add 1 Idea" is scope node, operation add and args 1 and Idea
+type Command struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Scope     string   `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`
+	Operation string   `protobuf:"bytes,2,opt,name=operation,proto3" json:"operation,omitempty"`
+	Args      []string `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Command) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *Command) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *Command) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+type SessionOpenRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Session string `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"` // The session to resume, a new session is opened if empty or expired
+}
+
+func (x *SessionOpenRequest) Reset() {
+	*x = SessionOpenRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionOpenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionOpenRequest) ProtoMessage() {}
+
+func (x *SessionOpenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionOpenRequest.ProtoReflect.Descriptor instead.
+func (*SessionOpenRequest) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SessionOpenRequest) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+type SessionOpenResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Session string `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+}
+
+func (x *SessionOpenResponse) Reset() {
+	*x = SessionOpenResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionOpenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionOpenResponse) ProtoMessage() {}
+
+func (x *SessionOpenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionOpenResponse.ProtoReflect.Descriptor instead.
+func (*SessionOpenResponse) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SessionOpenResponse) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+type SessionCloseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Session string `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+}
+
+func (x *SessionCloseRequest) Reset() {
+	*x = SessionCloseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionCloseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionCloseRequest) ProtoMessage() {}
+
+func (x *SessionCloseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionCloseRequest.ProtoReflect.Descriptor instead.
+func (*SessionCloseRequest) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SessionCloseRequest) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+type SessionCloseResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SessionCloseResponse) Reset() {
+	*x = SessionCloseResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionCloseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionCloseResponse) ProtoMessage() {}
+
+func (x *SessionCloseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionCloseResponse.ProtoReflect.Descriptor instead.
+func (*SessionCloseResponse) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{4}
+}
+
+type CommandRunRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Session string   `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	Command *Command `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+}
+
+func (x *CommandRunRequest) Reset() {
+	*x = CommandRunRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandRunRequest) ProtoMessage() {}
+
+func (x *CommandRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandRunRequest.ProtoReflect.Descriptor instead.
+func (*CommandRunRequest) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CommandRunRequest) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+func (x *CommandRunRequest) GetCommand() *Command {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+// CommandRunResponse is the result of a command, as text such as a view, a number such as the ID of an added
+// node, a flag such as of mindmap exists or JSON for other values
+type CommandRunResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Result:
+	//	*CommandRunResponse_Text
+	//	*CommandRunResponse_Number
+	//	*CommandRunResponse_Flag
+	//	*CommandRunResponse_Json
+	Result isCommandRunResponse_Result `protobuf_oneof:"result"`
+}
+
+func (x *CommandRunResponse) Reset() {
+	*x = CommandRunResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandRunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandRunResponse) ProtoMessage() {}
+
+func (x *CommandRunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandRunResponse.ProtoReflect.Descriptor instead.
+func (*CommandRunResponse) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{6}
+}
+
+func (m *CommandRunResponse) GetResult() isCommandRunResponse_Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (x *CommandRunResponse) GetText() string {
+	if x, ok := x.GetResult().(*CommandRunResponse_Text); ok {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *CommandRunResponse) GetNumber() int64 {
+	if x, ok := x.GetResult().(*CommandRunResponse_Number); ok {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *CommandRunResponse) GetFlag() bool {
+	if x, ok := x.GetResult().(*CommandRunResponse_Flag); ok {
+		return x.Flag
+	}
+	return false
+}
+
+func (x *CommandRunResponse) GetJson() string {
+	if x, ok := x.GetResult().(*CommandRunResponse_Json); ok {
+		return x.Json
+	}
+	return ""
+}
+
+type isCommandRunResponse_Result interface {
+	isCommandRunResponse_Result()
+}
+
+type CommandRunResponse_Text struct {
+	Text string `protobuf:"bytes,1,opt,name=text,proto3,oneof"`
+}
+
+type CommandRunResponse_Number struct {
+	Number int64 `protobuf:"varint,2,opt,name=number,proto3,oneof"`
+}
+
+type CommandRunResponse_Flag struct {
+	Flag bool `protobuf:"varint,3,opt,name=flag,proto3,oneof"`
+}
+
+type CommandRunResponse_Json struct {
+	Json string `protobuf:"bytes,4,opt,name=json,proto3,oneof"`
+}
+
+func (*CommandRunResponse_Text) isCommandRunResponse_Result() {}
+
+func (*CommandRunResponse_Number) isCommandRunResponse_Result() {}
+
+func (*CommandRunResponse_Flag) isCommandRunResponse_Result() {}
+
+func (*CommandRunResponse_Json) isCommandRunResponse_Result() {}
+
+type EventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Session string `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+}
+
+func (x *EventsRequest) Reset() {
+	*x = EventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventsRequest) ProtoMessage() {}
+
+func (x *EventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventsRequest.ProtoReflect.Descriptor instead.
+func (*EventsRequest) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *EventsRequest) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+// Event is a change to a mindmap
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type      string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // NodeAdded, NodeUpdated, NodeDeleted, NodeSorted or MindmapUpdated
+	MindmapId int64  `protobuf:"varint,2,opt,name=mindmap_id,json=mindmapId,proto3" json:"mindmap_id,omitempty"`
+	Node      *Node  `protobuf:"bytes,3,opt,name=node,proto3" json:"node,omitempty"` // The node added, changed, deleted or sorted, without its children
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetMindmapId() int64 {
+	if x != nil {
+		return x.MindmapId
+	}
+	return 0
+}
+
+func (x *Event) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+// Node is a node of a mindmap
+type Node struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       int64             `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ParentId int64             `protobuf:"varint,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Name     string            `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Index    string            `protobuf:"bytes,4,opt,name=index,proto3" json:"index,omitempty"`
+	Content  map[string]string `protobuf:"bytes,5,rep,name=content,proto3" json:"content,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mindnoscape_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_mindnoscape_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_mindnoscape_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Node) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Node) GetParentId() int64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *Node) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Node) GetIndex() string {
+	if x != nil {
+		return x.Index
+	}
+	return ""
+}
+
+func (x *Node) GetContent() map[string]string {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+var File_mindnoscape_proto protoreflect.FileDescriptor
+
+var file_mindnoscape_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x6d, 0x69, 0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x6d, 0x69, 0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65,
+	0x2e, 0x76, 0x31, 0x22, 0x51, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73,
+	0x63, 0x6f, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x22, 0x2e, 0x0a, 0x12, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x2f, 0x0a, 0x13, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x2f, 0x0a, 0x13, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x16, 0x0a, 0x14, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x60, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x31, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x22, 0x7a, 0x0a, 0x12, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x18,
+	0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00,
+	0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x04, 0x66, 0x6c, 0x61, 0x67,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x12, 0x14,
+	0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04,
+	0x6a, 0x73, 0x6f, 0x6e, 0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x29,
+	0x0a, 0x0d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x18, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x64, 0x0a, 0x05, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x69, 0x6e, 0x64, 0x6d, 0x61,
+	0x70, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6d, 0x69, 0x6e, 0x64,
+	0x6d, 0x61, 0x70, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x22,
+	0xd6, 0x01, 0x0a, 0x04, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x65,
+	0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x70, 0x61, 0x72,
+	0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12,
+	0x3b, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x21, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x1a, 0x3a, 0x0a, 0x0c,
+	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x32, 0xd7, 0x02, 0x0a, 0x0b, 0x4d, 0x69, 0x6e,
+	0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x12, 0x56, 0x0a, 0x0b, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x22, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x6e, 0x6f,
+	0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x6d, 0x69,
+	0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x59, 0x0a, 0x0c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65,
+	0x12, 0x23, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x6d, 0x69, 0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63,
+	0x61, 0x70, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6c,
+	0x6f, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x53, 0x0a, 0x0a, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e, 0x12, 0x21, 0x2e, 0x6d, 0x69, 0x6e, 0x64,
+	0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x52, 0x75, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x6d,
+	0x69, 0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x75, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x40, 0x0a, 0x06, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1d, 0x2e, 0x6d, 0x69, 0x6e,
+	0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x6d, 0x69, 0x6e, 0x64,
+	0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x30, 0x01, 0x42, 0x2f, 0x5a, 0x2d, 0x6d, 0x69, 0x6e, 0x64, 0x6e, 0x6f, 0x73, 0x63, 0x61, 0x70,
+	0x65, 0x2f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x2d, 0x61, 0x70, 0x70, 0x2f, 0x73, 0x72, 0x63, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x61, 0x64, 0x61, 0x70, 0x74, 0x65, 0x72, 0x2f, 0x67, 0x72, 0x70, 0x63,
+	0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_mindnoscape_proto_rawDescOnce sync.Once
+	file_mindnoscape_proto_rawDescData = file_mindnoscape_proto_rawDesc
+)
+
+func file_mindnoscape_proto_rawDescGZIP() []byte {
+	file_mindnoscape_proto_rawDescOnce.Do(func() {
+		file_mindnoscape_proto_rawDescData = protoimpl.X.CompressGZIP(file_mindnoscape_proto_rawDescData)
+	})
+	return file_mindnoscape_proto_rawDescData
+}
+
+var file_mindnoscape_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_mindnoscape_proto_goTypes = []any{
+	(*Command)(nil),              // 0: mindnoscape.v1.Command
+	(*SessionOpenRequest)(nil),   // 1: mindnoscape.v1.SessionOpenRequest
+	(*SessionOpenResponse)(nil),  // 2: mindnoscape.v1.SessionOpenResponse
+	(*SessionCloseRequest)(nil),  // 3: mindnoscape.v1.SessionCloseRequest
+	(*SessionCloseResponse)(nil), // 4: mindnoscape.v1.SessionCloseResponse
+	(*CommandRunRequest)(nil),    // 5: mindnoscape.v1.CommandRunRequest
+	(*CommandRunResponse)(nil),   // 6: mindnoscape.v1.CommandRunResponse
+	(*EventsRequest)(nil),        // 7: mindnoscape.v1.EventsRequest
+	(*Event)(nil),                // 8: mindnoscape.v1.Event
+	(*Node)(nil),                 // 9: mindnoscape.v1.Node
+	nil,                          // 10: mindnoscape.v1.Node.ContentEntry
+}
+var file_mindnoscape_proto_depIdxs = []int32{
+	0,  // 0: mindnoscape.v1.CommandRunRequest.command:type_name -> mindnoscape.v1.Command
+	9,  // 1: mindnoscape.v1.Event.node:type_name -> mindnoscape.v1.Node
+	10, // 2: mindnoscape.v1.Node.content:type_name -> mindnoscape.v1.Node.ContentEntry
+	1,  // 3: mindnoscape.v1.Mindnoscape.SessionOpen:input_type -> mindnoscape.v1.SessionOpenRequest
+	3,  // 4: mindnoscape.v1.Mindnoscape.SessionClose:input_type -> mindnoscape.v1.SessionCloseRequest
+	5,  // 5: mindnoscape.v1.Mindnoscape.CommandRun:input_type -> mindnoscape.v1.CommandRunRequest
+	7,  // 6: mindnoscape.v1.Mindnoscape.Events:input_type -> mindnoscape.v1.EventsRequest
+	2,  // 7: mindnoscape.v1.Mindnoscape.SessionOpen:output_type -> mindnoscape.v1.SessionOpenResponse
+	4,  // 8: mindnoscape.v1.Mindnoscape.SessionClose:output_type -> mindnoscape.v1.SessionCloseResponse
+	6,  // 9: mindnoscape.v1.Mindnoscape.CommandRun:output_type -> mindnoscape.v1.CommandRunResponse
+	8,  // 10: mindnoscape.v1.Mindnoscape.Events:output_type -> mindnoscape.v1.Event
+	7,  // [7:11] is the sub-list for method output_type
+	3,  // [3:7] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_mindnoscape_proto_init() }
+func file_mindnoscape_proto_init() {
+	if File_mindnoscape_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_mindnoscape_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Command); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mindnoscape_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*SessionOpenRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mindnoscape_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*SessionOpenResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mindnoscape_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*SessionCloseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mindnoscape_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*SessionCloseResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mindnoscape_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*CommandRunRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mindnoscape_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*CommandRunResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mindnoscape_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*EventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mindnoscape_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mindnoscape_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*Node); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_mindnoscape_proto_msgTypes[6].OneofWrappers = []any{
+		(*CommandRunResponse_Text)(nil),
+		(*CommandRunResponse_Number)(nil),
+		(*CommandRunResponse_Flag)(nil),
+		(*CommandRunResponse_Json)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_mindnoscape_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mindnoscape_proto_goTypes,
+		DependencyIndexes: file_mindnoscape_proto_depIdxs,
+		MessageInfos:      file_mindnoscape_proto_msgTypes,
+	}.Build()
+	File_mindnoscape_proto = out.File
+	file_mindnoscape_proto_rawDesc = nil
+	file_mindnoscape_proto_goTypes = nil
+	file_mindnoscape_proto_depIdxs = nil
+}
@@ -0,0 +1,256 @@
+// The gRPC service of Mindnoscape: the commands of the CLI, run in sessions, and the changes to the selected
+// mindmap streamed as they happen. Generate the Go code with 'go generate' in this directory.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: mindnoscape.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Mindnoscape_SessionOpen_FullMethodName  = "/mindnoscape.v1.Mindnoscape/SessionOpen"
+	Mindnoscape_SessionClose_FullMethodName = "/mindnoscape.v1.Mindnoscape/SessionClose"
+	Mindnoscape_CommandRun_FullMethodName   = "/mindnoscape.v1.Mindnoscape/CommandRun"
+	Mindnoscape_Events_FullMethodName       = "/mindnoscape.v1.Mindnoscape/Events"
+)
+
+// MindnoscapeClient is the client API for Mindnoscape service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Mindnoscape runs commands in sessions, each with its own selected user and mindmap
+type MindnoscapeClient interface {
+	// SessionOpen starts a session, or resumes one opened before that has not expired
+	SessionOpen(ctx context.Context, in *SessionOpenRequest, opts ...grpc.CallOption) (*SessionOpenResponse, error)
+	// SessionClose ends a session
+	SessionClose(ctx context.Context, in *SessionCloseRequest, opts ...grpc.CallOption) (*SessionCloseResponse, error)
+	// CommandRun runs a command in a session. Failed commands return an error status with the message of the CLI.
+	CommandRun(ctx context.Context, in *CommandRunRequest, opts ...grpc.CallOption) (*CommandRunResponse, error)
+	// Events streams the changes to the mindmap the session has selected, whichever session made them, until the
+	// session expires or the call is cancelled
+	Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type mindnoscapeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMindnoscapeClient(cc grpc.ClientConnInterface) MindnoscapeClient {
+	return &mindnoscapeClient{cc}
+}
+
+func (c *mindnoscapeClient) SessionOpen(ctx context.Context, in *SessionOpenRequest, opts ...grpc.CallOption) (*SessionOpenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionOpenResponse)
+	err := c.cc.Invoke(ctx, Mindnoscape_SessionOpen_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mindnoscapeClient) SessionClose(ctx context.Context, in *SessionCloseRequest, opts ...grpc.CallOption) (*SessionCloseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionCloseResponse)
+	err := c.cc.Invoke(ctx, Mindnoscape_SessionClose_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mindnoscapeClient) CommandRun(ctx context.Context, in *CommandRunRequest, opts ...grpc.CallOption) (*CommandRunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommandRunResponse)
+	err := c.cc.Invoke(ctx, Mindnoscape_CommandRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mindnoscapeClient) Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Mindnoscape_ServiceDesc.Streams[0], Mindnoscape_Events_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Mindnoscape_EventsClient = grpc.ServerStreamingClient[Event]
+
+// MindnoscapeServer is the server API for Mindnoscape service.
+// All implementations must embed UnimplementedMindnoscapeServer
+// for forward compatibility.
+//
+// Mindnoscape runs commands in sessions, each with its own selected user and mindmap
+type MindnoscapeServer interface {
+	// SessionOpen starts a session, or resumes one opened before that has not expired
+	SessionOpen(context.Context, *SessionOpenRequest) (*SessionOpenResponse, error)
+	// SessionClose ends a session
+	SessionClose(context.Context, *SessionCloseRequest) (*SessionCloseResponse, error)
+	// CommandRun runs a command in a session. Failed commands return an error status with the message of the CLI.
+	CommandRun(context.Context, *CommandRunRequest) (*CommandRunResponse, error)
+	// Events streams the changes to the mindmap the session has selected, whichever session made them, until the
+	// session expires or the call is cancelled
+	Events(*EventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedMindnoscapeServer()
+}
+
+// UnimplementedMindnoscapeServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMindnoscapeServer struct{}
+
+func (UnimplementedMindnoscapeServer) SessionOpen(context.Context, *SessionOpenRequest) (*SessionOpenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SessionOpen not implemented")
+}
+func (UnimplementedMindnoscapeServer) SessionClose(context.Context, *SessionCloseRequest) (*SessionCloseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SessionClose not implemented")
+}
+func (UnimplementedMindnoscapeServer) CommandRun(context.Context, *CommandRunRequest) (*CommandRunResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommandRun not implemented")
+}
+func (UnimplementedMindnoscapeServer) Events(*EventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method Events not implemented")
+}
+func (UnimplementedMindnoscapeServer) mustEmbedUnimplementedMindnoscapeServer() {}
+func (UnimplementedMindnoscapeServer) testEmbeddedByValue()                     {}
+
+// UnsafeMindnoscapeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MindnoscapeServer will
+// result in compilation errors.
+type UnsafeMindnoscapeServer interface {
+	mustEmbedUnimplementedMindnoscapeServer()
+}
+
+func RegisterMindnoscapeServer(s grpc.ServiceRegistrar, srv MindnoscapeServer) {
+	// If the following call pancis, it indicates UnimplementedMindnoscapeServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Mindnoscape_ServiceDesc, srv)
+}
+
+func _Mindnoscape_SessionOpen_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionOpenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MindnoscapeServer).SessionOpen(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mindnoscape_SessionOpen_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MindnoscapeServer).SessionOpen(ctx, req.(*SessionOpenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mindnoscape_SessionClose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionCloseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MindnoscapeServer).SessionClose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mindnoscape_SessionClose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MindnoscapeServer).SessionClose(ctx, req.(*SessionCloseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mindnoscape_CommandRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommandRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MindnoscapeServer).CommandRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mindnoscape_CommandRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MindnoscapeServer).CommandRun(ctx, req.(*CommandRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mindnoscape_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MindnoscapeServer).Events(m, &grpc.GenericServerStream[EventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Mindnoscape_EventsServer = grpc.ServerStreamingServer[Event]
+
+// Mindnoscape_ServiceDesc is the grpc.ServiceDesc for Mindnoscape service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Mindnoscape_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mindnoscape.v1.Mindnoscape",
+	HandlerType: (*MindnoscapeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SessionOpen",
+			Handler:    _Mindnoscape_SessionOpen_Handler,
+		},
+		{
+			MethodName: "SessionClose",
+			Handler:    _Mindnoscape_SessionClose_Handler,
+		},
+		{
+			MethodName: "CommandRun",
+			Handler:    _Mindnoscape_CommandRun_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Events",
+			Handler:       _Mindnoscape_Events_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "mindnoscape.proto",
+}
@@ -0,0 +1,83 @@
+package adapter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/eiannone/keyboard"
+)
+
+// isTerminal reports whether both the input and the output of the program are a terminal
+func isTerminal() bool {
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := f.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// lineEdit lets the user edit a line on the terminal, pre-filled with initial and the cursor at its end.
+// Left, Right, Home, End, Ctrl+A and Ctrl+E move the cursor, Backspace and Delete remove characters and Ctrl+U
+// clears the line. Enter accepts the line, Escape and Ctrl+C cancel the edit and return ok false.
+func lineEdit(out io.Writer, prompt string, initial string) (line string, ok bool, err error) {
+	if err := keyboard.Open(); err != nil {
+		return "", false, fmt.Errorf("failed to open the terminal for editing: %w", err)
+	}
+	defer keyboard.Close()
+
+	buf := []rune(initial)
+	cursor := len(buf)
+	for {
+		// Redraw the line and move the cursor back from its end
+		fmt.Fprintf(out, "\r%s%s\x1b[K", prompt, string(buf))
+		if back := len(buf) - cursor; back > 0 {
+			fmt.Fprintf(out, "\x1b[%dD", back)
+		}
+
+		char, key, err := keyboard.GetKey()
+		if err != nil {
+			fmt.Fprintln(out)
+			return "", false, fmt.Errorf("failed to read key: %w", err)
+		}
+
+		switch key {
+		case keyboard.KeyEnter:
+			fmt.Fprintln(out)
+			return string(buf), true, nil
+		case keyboard.KeyEsc, keyboard.KeyCtrlC:
+			fmt.Fprintln(out)
+			return "", false, nil
+		case keyboard.KeyArrowLeft:
+			cursor = max(cursor-1, 0)
+		case keyboard.KeyArrowRight:
+			cursor = min(cursor+1, len(buf))
+		case keyboard.KeyHome, keyboard.KeyCtrlA:
+			cursor = 0
+		case keyboard.KeyEnd, keyboard.KeyCtrlE:
+			cursor = len(buf)
+		case keyboard.KeyBackspace, keyboard.KeyBackspace2:
+			if cursor > 0 {
+				buf = slices.Delete(buf, cursor-1, cursor)
+				cursor--
+			}
+		case keyboard.KeyDelete:
+			if cursor < len(buf) {
+				buf = slices.Delete(buf, cursor, cursor+1)
+			}
+		case keyboard.KeyCtrlU:
+			buf, cursor = buf[:0], 0
+		case keyboard.KeySpace:
+			buf = slices.Insert(buf, cursor, ' ')
+			cursor++
+		default:
+			if key == 0 && char != 0 {
+				buf = slices.Insert(buf, cursor, char)
+				cursor++
+			}
+		}
+	}
+}
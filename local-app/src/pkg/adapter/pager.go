@@ -0,0 +1,123 @@
+package adapter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPagerHeight = 24 // Terminal lines assumed if $LINES is not set
+	pagerPromptLines   = 1  // Lines kept free below each page for the pager prompt
+)
+
+// Pager shows command output longer than the terminal a page at a time. The program in $PAGER is used if set,
+// such as less; otherwise the built-in pager reads its commands as lines of input: Enter shows the next page,
+// b the previous one, /pattern searches forward without regard to case, n repeats the search and q quits.
+type Pager struct {
+	out      io.Writer
+	readLine func() (string, error)
+	height   int
+	program  []string
+}
+
+// NewPager creates a pager writing to out and reading its commands with readLine, sized from $LINES
+func NewPager(out io.Writer, readLine func() (string, error)) *Pager {
+	height := defaultPagerHeight
+	if lines, err := strconv.Atoi(os.Getenv("LINES")); err == nil && lines > pagerPromptLines {
+		height = lines
+	}
+	return &Pager{
+		out:      out,
+		readLine: readLine,
+		height:   height,
+		program:  strings.Fields(os.Getenv("PAGER")),
+	}
+}
+
+// Show writes text, through the pager if it does not fit on one page
+func (p *Pager) Show(text string) {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	pageSize := p.height - pagerPromptLines
+	if len(lines) <= pageSize {
+		fmt.Fprintln(p.out, text)
+		return
+	}
+
+	if len(p.program) > 0 {
+		cmd := exec.Command(p.program[0], p.program[1:]...)
+		cmd.Stdin = strings.NewReader(text + "\n")
+		cmd.Stdout, cmd.Stderr = p.out, p.out
+		if err := cmd.Run(); err == nil {
+			return
+		}
+		// The built-in pager takes over if the program fails, such as when it is not installed
+		fmt.Fprintf(p.out, "Pager %s failed, using the built-in pager\n", p.program[0])
+	}
+	p.page(lines, pageSize)
+}
+
+// page runs the built-in pager over lines
+func (p *Pager) page(lines []string, pageSize int) {
+	top, pattern := 0, ""
+	for {
+		end := min(top+pageSize, len(lines))
+		for _, line := range lines[top:end] {
+			fmt.Fprintln(p.out, line)
+		}
+		if end == len(lines) {
+			return
+		}
+
+		for {
+			fmt.Fprintf(p.out, "-- lines %d-%d of %d: Enter next, b back, /pattern search, n next match, q quit -- ", top+1, end, len(lines))
+			input, err := p.readLine()
+			if err != nil {
+				fmt.Fprintln(p.out)
+				return
+			}
+			input = strings.TrimSpace(input)
+
+			next := -1
+			switch {
+			case input == "":
+				next = end
+			case input == "q":
+				return
+			case input == "b":
+				next = max(top-pageSize, 0)
+			case strings.HasPrefix(input, "/") || input == "n":
+				if input != "n" {
+					pattern = strings.ToLower(input[1:])
+				}
+				if pattern == "" {
+					fmt.Fprintln(p.out, "No search pattern")
+					continue
+				}
+				next = searchLines(lines, top+1, pattern)
+				if next < 0 {
+					fmt.Fprintf(p.out, "Pattern not found: %s\n", pattern)
+					continue
+				}
+			default:
+				fmt.Fprintf(p.out, "Unknown pager command: %s\n", input)
+				continue
+			}
+			top = next
+			break
+		}
+	}
+}
+
+// searchLines returns the first line from the given line on containing the lowercase pattern, or -1 if none does
+func searchLines(lines []string, from int, pattern string) int {
+	for i := from; i < len(lines); i++ {
+		if strings.Contains(strings.ToLower(lines[i]), pattern) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eiannone/keyboard"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// passphrasePrompt returns a PassphraseFunc prompting on out. On a terminal the typed passphrase is not echoed,
+// otherwise it is read as a line of the standard input, unbuffered so that the following commands are kept.
+func passphrasePrompt(out io.Writer) model.PassphraseFunc {
+	return func(prompt string) (string, error) {
+		fmt.Fprint(out, prompt)
+		if isTerminal() {
+			return passphraseRead(out)
+		}
+		return stdinLine()
+	}
+}
+
+// passphraseRead reads a passphrase from the terminal without echoing it. Enter accepts it, Backspace removes the
+// last character and Escape and Ctrl+C cancel.
+func passphraseRead(out io.Writer) (string, error) {
+	if err := keyboard.Open(); err != nil {
+		return "", fmt.Errorf("failed to open the terminal for the passphrase: %w", err)
+	}
+	defer keyboard.Close()
+	defer fmt.Fprintln(out)
+
+	var buf []rune
+	for {
+		char, key, err := keyboard.GetKey()
+		if err != nil {
+			return "", fmt.Errorf("failed to read key: %w", err)
+		}
+		switch key {
+		case keyboard.KeyEnter:
+			return string(buf), nil
+		case keyboard.KeyEsc, keyboard.KeyCtrlC:
+			return "", errors.New("passphrase entry canceled")
+		case keyboard.KeyBackspace, keyboard.KeyBackspace2:
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+			}
+		case keyboard.KeySpace:
+			buf = append(buf, ' ')
+		default:
+			if key == 0 && char != 0 {
+				buf = append(buf, char)
+			}
+		}
+	}
+}
+
+// stdinLine reads a line of the standard input a byte at a time, without the line ending
+func stdinLine() (string, error) {
+	var line strings.Builder
+	var b [1]byte
+	for {
+		n, err := os.Stdin.Read(b[:])
+		if err != nil {
+			if err == io.EOF && line.Len() > 0 {
+				break
+			}
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if n == 0 {
+			continue
+		}
+		if b[0] == '\n' {
+			break
+		}
+		line.WriteByte(b[0])
+	}
+	return strings.TrimSuffix(line.String(), "\r"), nil
+}
@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+const (
+	progressMinNodes = 1000                   // Operations on fewer nodes finish too fast to need a progress bar
+	progressInterval = 100 * time.Millisecond // Minimum time between redraws of the progress bar
+	progressBarWidth = 30
+)
+
+// progressRenderer returns a ProgressFunc drawing a progress bar with node counts and ETA on a single line of w
+func progressRenderer(w io.Writer) model.ProgressFunc {
+	var mu sync.Mutex
+	var lastDraw time.Time
+
+	return func(p model.Progress) {
+		if p.Total < progressMinNodes {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		finished := p.Done >= p.Total
+		if !finished && time.Since(lastDraw) < progressInterval {
+			return
+		}
+		lastDraw = time.Now()
+
+		fmt.Fprintf(w, "\r%s", formatProgress(p))
+		if finished {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// formatProgress formats a progress report as a bar with node counts, percentage and estimated time remaining
+func formatProgress(p model.Progress) string {
+	fraction := float64(p.Done) / float64(p.Total)
+	filled := int(fraction * progressBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled)
+
+	eta := "--"
+	elapsed := time.Since(p.Started)
+	switch {
+	case p.Done >= p.Total:
+		eta = "done in " + elapsed.Round(time.Second).String()
+	case p.Done > 0:
+		remaining := time.Duration(float64(elapsed) * float64(p.Total-p.Done) / float64(p.Done))
+		eta = "ETA " + remaining.Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("%s [%s] %d/%d nodes %3.0f%% %-16s", p.Operation, bar, p.Done, p.Total, fraction*100, eta)
+}
@@ -0,0 +1,453 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/session"
+)
+
+const (
+	webPath          = "/ws"   // The path WebSocket clients connect to
+	webSendQueue     = 256     // Frames waiting to be sent to a connection before it is dropped as too slow
+	webMaxFrameBytes = 1 << 20 // The largest command frame taken from a client
+)
+
+// WebAdapter serves the command protocol over WebSockets, for browser and other real-time clients. Each
+// connection runs its commands in a session of its own, and is pushed the changes to the mindmap the session has
+// selected, whichever session made them, so a client can show the mindmap as it changes.
+type WebAdapter struct {
+	server         *http.Server
+	conns          map[*webConn]bool
+	sessions       map[string]bool // Sessions of the adapter, resumed by reconnecting clients until they expire
+	serving        int             // Connections being served, at most maxConns
+	maxConns       int             // Connections served at once, any number if 0 or less
+	connMutex      sync.RWMutex
+	renders        webRenders // Rendered calendar feeds and published mindmaps
+	adapterManager *AdapterManager
+	logger         *log.Logger
+}
+
+// webConn is a connected WebSocket client, its frames sent in order by a writer of its own
+type webConn struct {
+	ws        *websocket.Conn
+	sessionID string
+	send      chan webFrame
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// webRequest is a command frame sent by a client. ID is chosen by the client and returned with the result.
+type webRequest struct {
+	ID        int      `json:"id"`
+	Scope     string   `json:"scope"`
+	Operation string   `json:"operation"`
+	Args      []string `json:"args"`
+}
+
+// webFrame is a frame sent to a client: its session on connecting, the result of a command, a change to the
+// selected mindmap or the expiry of its session
+type webFrame struct {
+	Type      string      `json:"type"`              // session, result, event or expired
+	ID        int         `json:"id,omitempty"`      // The ID of the request of a result
+	Session   string      `json:"session,omitempty"` // The session of the connection, to resume it when reconnecting
+	Result    interface{} `json:"result,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Event     string      `json:"event,omitempty"` // The event type, such as NodeUpdated
+	MindmapID int         `json:"mindmap_id,omitempty"`
+	Node      *webNode    `json:"node,omitempty"` // The node added, changed, deleted or sorted, without its children
+}
+
+// webNode is a node as pushed with an event
+type webNode struct {
+	ID       int               `json:"id"`
+	ParentID int               `json:"parent_id"`
+	Name     string            `json:"name"`
+	Index    string            `json:"index"`
+	Content  map[string]string `json:"content,omitempty"`
+}
+
+// NewWebAdapter creates a new WebAdapter, subscribed to the changes of mindmaps. It takes connections once started.
+func NewWebAdapter(am *AdapterManager, logger *log.Logger) *WebAdapter {
+	logger.Info(context.Background(), "Creating new web adapter", nil)
+	a := &WebAdapter{
+		conns:          make(map[*webConn]bool),
+		sessions:       make(map[string]bool),
+		maxConns:       am.sessionManager.MaxConnections(),
+		renders:        webRenders{renders: make(map[string]*webRender)},
+		adapterManager: am,
+		logger:         logger,
+	}
+	for _, eventType := range mindmapEvents {
+		am.sessionManager.Subscribe(eventType, a.handleMindmapEvent)
+	}
+	for _, eventType := range renderEvents {
+		am.sessionManager.Subscribe(eventType, a.handleRenderEvent)
+	}
+	return a
+}
+
+// AdapterStart listens on address, such as 127.0.0.1:8080, and serves WebSocket clients at /ws, captures at
+// /capture, calendar feeds under /calendar/, the mindmaps of web_maps under /maps/ and read-only views of mindmaps
+// under /view/ in the background
+func (a *WebAdapter) AdapterStart(address string) error {
+	ctx := context.Background()
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		a.logger.Error(ctx, "Failed to listen for web clients", log.Fields{"error": err, "address": address})
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(webPath, websocket.Server{Handshake: webHandshake, Handler: a.serveConn})
+	mux.HandleFunc(webCapturePath, a.serveCapture)
+	mux.HandleFunc(session.CalendarFeedPath, a.serveCalendar)
+	mux.HandleFunc(session.PublishedMapsPath, a.serveMaps)
+	mux.HandleFunc(webViewPath, a.serveView)
+	a.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := a.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.logger.Error(ctx, "Web adapter stopped serving", log.Fields{"error": err})
+		}
+	}()
+
+	a.logger.Info(ctx, "Web adapter listening", log.Fields{"address": listener.Addr().String()})
+	return nil
+}
+
+// AdapterDrain stops taking connections, the connected ones staying open until the adapter is stopped
+func (a *WebAdapter) AdapterDrain() {
+	if a.server == nil {
+		return
+	}
+	// Only the listener is closed, the WebSocket connections are no longer the server's
+	if err := a.server.Close(); err != nil {
+		a.logger.Warn(context.Background(), "Failed to stop listening for web clients", log.Fields{"error": err})
+	}
+	a.logger.Info(context.Background(), "Web adapter no longer taking connections", nil)
+}
+
+// AdapterStop stops taking connections and closes the connected ones
+func (a *WebAdapter) AdapterStop() error {
+	ctx := context.Background()
+	a.logger.Info(ctx, "Web adapter stopping", nil)
+
+	var err error
+	if a.server != nil {
+		err = a.server.Close()
+	}
+	a.connMutex.RLock()
+	for conn := range a.conns {
+		conn.close()
+	}
+	a.connMutex.RUnlock()
+
+	a.logger.Info(ctx, "Web adapter stopped", nil)
+	return err
+}
+
+// webHandshake accepts clients without an origin, which are not browsers, and browsers on pages of the same host,
+// refusing other sites that would run commands with the sessions of the browser's user
+func webHandshake(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil || originURL.Host != req.Host {
+		return fmt.Errorf("origin %s not allowed", origin)
+	}
+	config.Origin = originURL
+	return nil
+}
+
+// serveConn runs the commands of a connection until it closes. A client resumes its session by connecting with
+// the session it was given as the session query parameter, while no other connection uses it. Connections beyond
+// max_connections are refused.
+func (a *WebAdapter) serveConn(ws *websocket.Conn) {
+	ctx := context.Background()
+	ws.MaxPayloadBytes = webMaxFrameBytes
+
+	a.connMutex.Lock()
+	if a.maxConns > 0 && a.serving >= a.maxConns {
+		a.connMutex.Unlock()
+		a.logger.Warn(ctx, "Web client refused, too many connections", log.Fields{"remote": ws.Request().RemoteAddr, "limit": a.maxConns})
+		websocket.JSON.Send(ws, webFrame{Type: "result", Error: "too many connections, try again later"})
+		ws.Close()
+		return
+	}
+	a.serving++
+	a.connMutex.Unlock()
+	defer func() {
+		a.connMutex.Lock()
+		a.serving--
+		a.connMutex.Unlock()
+	}()
+
+	sessionID, err := a.getOrCreateSession(ws.Request().URL.Query().Get("session"))
+	if err != nil {
+		websocket.JSON.Send(ws, webFrame{Type: "result", Error: err.Error()})
+		ws.Close()
+		return
+	}
+
+	if _, exists := a.adapterManager.SessionGet(sessionID); !exists {
+		websocket.JSON.Send(ws, webFrame{Type: "result", Error: "session not found"})
+		ws.Close()
+		return
+	}
+
+	conn := &webConn{ws: ws, sessionID: sessionID, send: make(chan webFrame, webSendQueue), done: make(chan struct{})}
+	a.connMutex.Lock()
+	// A session is bound to one connection at a time, so that its commands and events are not split between them
+	for other := range a.conns {
+		if other.sessionID == sessionID {
+			a.connMutex.Unlock()
+			a.logger.Warn(ctx, "Web session already connected", log.Fields{"sessionID": sessionID})
+			websocket.JSON.Send(ws, webFrame{Type: "result", Error: "session is in use by another connection"})
+			ws.Close()
+			return
+		}
+	}
+	a.conns[conn] = true
+	a.connMutex.Unlock()
+	defer func() {
+		a.connMutex.Lock()
+		delete(a.conns, conn)
+		a.connMutex.Unlock()
+		conn.close()
+		a.logger.Info(ctx, "Web client disconnected", log.Fields{"sessionID": sessionID})
+	}()
+	go a.writeFrames(conn)
+	a.logger.Info(ctx, "Web client connected", log.Fields{"sessionID": sessionID, "remote": ws.Request().RemoteAddr})
+
+	conn.queue(webFrame{Type: "session", Session: sessionID})
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				a.logger.Warn(ctx, "Failed to read from web client", log.Fields{"error": err, "sessionID": sessionID})
+			}
+			return
+		}
+
+		var req webRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			conn.queue(webFrame{Type: "result", Error: fmt.Sprintf("invalid command frame: %v", err)})
+			continue
+		}
+		frame := webFrame{Type: "result", ID: req.ID}
+		frame.Result, err = a.commandRun(sessionID, req)
+		if err != nil {
+			frame.Error = err.Error()
+		}
+		if !conn.queue(frame) {
+			return
+		}
+	}
+}
+
+// commandRun runs the command of a request in the session of its connection
+func (a *WebAdapter) commandRun(sessionID string, req webRequest) (interface{}, error) {
+	if req.Scope == "" {
+		return nil, errors.New("empty command")
+	}
+	cmd := model.Command{Scope: strings.ToLower(req.Scope), Operation: strings.ToLower(req.Operation), Args: req.Args}
+	if cmd.Args == nil {
+		cmd.Args = []string{}
+	}
+	if cmd.Scope == "node" && cmd.Operation == "edit" {
+		return nil, errors.New("node edit requires a terminal, use node update instead")
+	}
+	return a.adapterManager.CommandRun(sessionID, cmd)
+}
+
+// writeFrames sends the queued frames of a connection until it closes. Results that cannot be encoded as JSON are
+// sent as text.
+func (a *WebAdapter) writeFrames(conn *webConn) {
+	for {
+		select {
+		case frame := <-conn.send:
+			data, err := json.Marshal(frame)
+			if err != nil {
+				frame.Result = fmt.Sprint(frame.Result)
+				data, err = json.Marshal(frame)
+			}
+			if err == nil {
+				err = websocket.Message.Send(conn.ws, string(data))
+			}
+			if err != nil {
+				a.logger.Warn(context.Background(), "Failed to send to web client", log.Fields{"error": err, "sessionID": conn.sessionID})
+				conn.close()
+				return
+			}
+			if frame.Type == "expired" {
+				conn.close()
+				return
+			}
+		case <-conn.done:
+			return
+		}
+	}
+}
+
+// getOrCreateSession returns the session of a reconnecting client if it is a session of the adapter that has not
+// expired, otherwise a new session
+func (a *WebAdapter) getOrCreateSession(sessionID string) (string, error) {
+	a.connMutex.RLock()
+	resumable := sessionID != "" && a.sessions[sessionID]
+	a.connMutex.RUnlock()
+	if resumable {
+		if _, exists := a.adapterManager.SessionGet(sessionID); exists {
+			a.logger.Info(context.Background(), "Web session resumed", log.Fields{"sessionID": sessionID})
+			return sessionID, nil
+		}
+	}
+
+	sessionID, err := a.adapterManager.SessionAdd()
+	if err != nil {
+		return "", err
+	}
+	if session, exists := a.adapterManager.SessionGet(sessionID); exists {
+		session.Authenticate = true
+	}
+	a.connMutex.Lock()
+	a.sessions[sessionID] = true
+	a.connMutex.Unlock()
+	a.logger.Info(context.Background(), "New web session added", log.Fields{"sessionID": sessionID})
+	return sessionID, nil
+}
+
+// httpSession opens a session for a plain HTTP request, such as a capture, with the user of its basic
+// authentication selected as a web client would select it. The caller closes the session with SessionDelete.
+// Returns false if the request was answered with an error instead.
+func (a *WebAdapter) httpSession(w http.ResponseWriter, r *http.Request) (string, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Mindnoscape", charset="UTF-8"`)
+		http.Error(w, "authentication required", http.StatusUnauthorized)
+		return "", false
+	}
+
+	sessionID, err := a.adapterManager.SessionAdd()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", false
+	}
+	if session, exists := a.adapterManager.SessionGet(sessionID); exists {
+		session.Authenticate = true
+	}
+	if _, err := a.adapterManager.CommandRun(sessionID, model.Command{Scope: "user", Operation: "select", Args: []string{username, password}}); err != nil {
+		a.adapterManager.SessionDelete(sessionID)
+		if errors.Is(err, ErrShuttingDown) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return "", false
+		}
+		a.logger.Warn(context.Background(), "Web request refused, authentication failed", log.Fields{"username": username, "remote": r.RemoteAddr})
+		w.Header().Set("WWW-Authenticate", `Basic realm="Mindnoscape", charset="UTF-8"`)
+		http.Error(w, "authentication failed", http.StatusUnauthorized)
+		return "", false
+	}
+	return sessionID, true
+}
+
+// SessionExpire removes a web session that expired after inactivity, telling its clients, which are closed once
+// told
+func (a *WebAdapter) SessionExpire(sessionID string) {
+	a.connMutex.Lock()
+	defer a.connMutex.Unlock()
+	if !a.sessions[sessionID] {
+		return
+	}
+	delete(a.sessions, sessionID)
+	for conn := range a.conns {
+		if conn.sessionID != sessionID {
+			continue
+		}
+		select {
+		case conn.send <- webFrame{Type: "expired", Session: sessionID}:
+		default:
+			conn.close()
+		}
+	}
+	a.logger.Info(context.Background(), "Web session expired", log.Fields{"sessionID": sessionID})
+}
+
+// handleMindmapEvent pushes a change to a mindmap to the clients whose session has it selected, and may see the
+// node changed, see session.SessionManager.MindmapChangeSessions. A client too slow to take its frames is
+// disconnected, to load the mindmap again once it reconnects.
+func (a *WebAdapter) handleMindmapEvent(e event.Event) {
+	ctx := context.Background()
+	mindmap, node, err := mindmapChange(e)
+	if err != nil {
+		a.logger.Error(ctx, "Invalid event for web clients", log.Fields{"error": err, "event": e.Type.String()})
+		return
+	}
+
+	a.connMutex.RLock()
+	var sessionIDs []string
+	for conn := range a.conns {
+		sessionIDs = append(sessionIDs, conn.sessionID)
+	}
+	a.connMutex.RUnlock()
+	if len(sessionIDs) == 0 {
+		return
+	}
+
+	recipients, node, err := a.adapterManager.sessionManager.MindmapChangeSessions(mindmap, node, sessionIDs)
+	if err != nil {
+		a.logger.Error(ctx, "Failed to find the web clients of a change", log.Fields{"error": err, "event": e.Type.String()})
+		return
+	}
+	frame := webFrame{Type: "event", Event: e.Type.String(), MindmapID: mindmap.ID}
+	if node != nil {
+		frame.Node = &webNode{ID: node.ID, ParentID: node.ParentID, Name: node.Name, Index: node.Index, Content: node.Content}
+	}
+
+	a.connMutex.RLock()
+	defer a.connMutex.RUnlock()
+	for conn := range a.conns {
+		if !slices.Contains(recipients, conn.sessionID) {
+			continue
+		}
+		select {
+		case conn.send <- frame:
+		default:
+			a.logger.Warn(ctx, "Web client too slow for its events, disconnecting", log.Fields{"sessionID": conn.sessionID})
+			conn.close()
+		}
+	}
+}
+
+// queue queues a frame to be sent, waiting while the queue is full. Returns false if the connection is closed.
+func (c *webConn) queue(frame webFrame) bool {
+	select {
+	case c.send <- frame:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// close closes the connection, ending its reader and writer
+func (c *webConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.ws.Close()
+	})
+}
@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/session"
+)
+
+// serveCalendar serves the calendar feed of the token in the path, for calendars subscribed to it. Calendars poll
+// feeds, so a feed is rendered once and served as rendered until a change to its mindmaps, see
+// handleRenderEvent. The token is checked on each request, so a feed set again or cleared stops being served.
+func (a *WebAdapter) serveCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, session.CalendarFeedPath), ".ics")
+	if !ok || token == "" || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	sm := a.adapterManager.sessionManager
+	key := session.CalendarFeedPath + token
+	feed, err := sm.CalendarFind(token)
+	if err != nil {
+		http.Error(w, "failed to find calendar feed", http.StatusInternalServerError)
+		return
+	}
+	if feed == nil {
+		a.renders.drop(key)
+		a.logger.Warn(ctx, "Unknown calendar feed requested", log.Fields{"remote": r.RemoteAddr})
+		http.NotFound(w, r)
+		return
+	}
+
+	data, generation, cached := a.renders.get(key)
+	if !cached {
+		data, err = sm.CalendarEncode(feed)
+		if err != nil {
+			a.logger.Error(ctx, "Failed to render calendar feed", log.Fields{"error": err, "username": feed.Username, "mindmapID": feed.MindmapID})
+			http.Error(w, "failed to render calendar feed", http.StatusInternalServerError)
+			return
+		}
+		// A feed covers its mindmap, or all own mindmaps of its user
+		a.renders.put(key, data, func(mindmap *model.Mindmap) bool {
+			return feed.MindmapID == mindmap.ID || (feed.MindmapID == 0 && feed.Username == mindmap.Owner)
+		}, generation)
+		a.logger.Debug(ctx, "Calendar feed rendered", log.Fields{"username": feed.Username, "mindmapID": feed.MindmapID})
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(data)
+}
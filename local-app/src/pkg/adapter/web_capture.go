@@ -0,0 +1,147 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"mime"
+	"net/http"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// webCapturePath is the path text is captured at, as a node added by the add command
+const webCapturePath = "/capture"
+
+// webCapture is the body of a capture request
+type webCapture struct {
+	Text    string `json:"text"`
+	Mindmap string `json:"mindmap,omitempty"` // The own mindmap added to, the default mindmap if empty
+	Parent  string `json:"parent,omitempty"`  // The index of the node added under, the inbox of the mindmap if empty
+}
+
+// webCaptureResult is the response to a capture request
+type webCaptureResult struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// webCapturePage is the form served for GET requests, prefilled from the text, mindmap and parent query
+// parameters, so that a bookmarklet can open it with the page it was clicked on. It posts to the endpoint as
+// scripts do, from the same origin.
+var webCapturePage = template.Must(template.New("capture").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Capture - Mindnoscape</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 1rem; max-width: 40rem; }
+label, input, textarea, button { display: block; width: 100%; box-sizing: border-box; margin-bottom: .5rem; }
+textarea { min-height: 6rem; }
+</style>
+</head>
+<body>
+<form id="capture">
+<label>Text <textarea name="text" required>{{.Text}}</textarea></label>
+<label>Mindmap <input name="mindmap" value="{{.Mindmap}}" placeholder="Default mindmap"></label>
+<label>Under node <input name="parent" value="{{.Parent}}" placeholder="Inbox"></label>
+<button>Add</button>
+</form>
+<p id="result" role="status"></p>
+<script>
+document.getElementById("capture").addEventListener("submit", async (e) => {
+	e.preventDefault();
+	const form = new FormData(e.target);
+	const status = document.getElementById("result");
+	const response = await fetch("/capture", {
+		method: "POST",
+		headers: {"Content-Type": "application/json"},
+		body: JSON.stringify({text: form.get("text"), mindmap: form.get("mindmap"), parent: form.get("parent")}),
+	});
+	const body = await response.json().catch(() => ({error: response.statusText}));
+	status.textContent = body.error || body.result;
+	if (response.ok) e.target.text.value = "";
+});
+</script>
+</body>
+</html>
+`))
+
+// serveCapture adds the text of a capture request as a node, for scripts, shortcuts of phones and bookmarklets.
+// POST takes a JSON webCapture, and GET serves a form posting one. The user is authenticated with HTTP basic
+// authentication. Only JSON is taken, which pages of other sites cannot post without being allowed to.
+func (a *WebAdapter) serveCapture(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	switch r.Method {
+	case http.MethodGet:
+		sessionID, ok := a.httpSession(w, r)
+		if !ok {
+			return
+		}
+		a.adapterManager.SessionDelete(sessionID)
+
+		query := r.URL.Query()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page := webCapture{Text: query.Get("text"), Mindmap: query.Get("mindmap"), Parent: query.Get("parent")}
+		if err := webCapturePage.Execute(w, page); err != nil {
+			a.logger.Warn(ctx, "Failed to send capture form", log.Fields{"error": err})
+		}
+		return
+	case http.MethodPost:
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		http.Error(w, "capture requests must be JSON", http.StatusUnsupportedMediaType)
+		return
+	}
+	var capture webCapture
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, webMaxFrameBytes)).Decode(&capture); err != nil {
+		webCaptureSend(w, http.StatusBadRequest, webCaptureResult{Error: "invalid capture request: " + err.Error()})
+		return
+	}
+	capture.Text = strings.TrimSpace(capture.Text)
+	if capture.Text == "" {
+		webCaptureSend(w, http.StatusBadRequest, webCaptureResult{Error: "no text to capture"})
+		return
+	}
+
+	sessionID, ok := a.httpSession(w, r)
+	if !ok {
+		return
+	}
+	defer a.adapterManager.SessionDelete(sessionID)
+
+	var args []string
+	if capture.Mindmap != "" {
+		args = append(args, "--mindmap", capture.Mindmap)
+	}
+	if capture.Parent != "" {
+		args = append(args, "--parent", capture.Parent)
+	}
+	result, err := a.adapterManager.CommandRun(sessionID, model.Command{Scope: "add", Args: append(args, capture.Text)})
+	switch {
+	case errors.Is(err, ErrShuttingDown):
+		webCaptureSend(w, http.StatusServiceUnavailable, webCaptureResult{Error: err.Error()})
+	case err != nil:
+		webCaptureSend(w, http.StatusUnprocessableEntity, webCaptureResult{Error: err.Error()})
+	default:
+		a.logger.Info(ctx, "Text captured over HTTP", log.Fields{"sessionID": sessionID})
+		webCaptureSend(w, http.StatusCreated, webCaptureResult{Result: result})
+	}
+}
+
+// webCaptureSend sends the response to a capture request as JSON
+func webCaptureSend(w http.ResponseWriter, status int, result webCaptureResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,147 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/session"
+)
+
+// webMapTypes are the content types of the published formats
+var webMapTypes = map[string]string{
+	"html": "text/html; charset=utf-8",
+	"svg":  "image/svg+xml",
+}
+
+// webMapsPage lists the published mindmaps the user may see, linking their exports
+var webMapsPage = template.Must(template.New("maps").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Mindmaps - Mindnoscape</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 1rem; line-height: 1.6; }
+a { margin-left: .5rem; }
+</style>
+</head>
+<body>
+<h1>Mindmaps</h1>
+{{if .}}<ul>{{range .}}<li>{{.Owner}}/{{.Name}}{{range .Links}}<a href="{{.URL}}">{{.Format}}</a>{{end}}</li>
+{{end}}</ul>{{else}}<p>No mindmaps are published.</p>{{end}}
+</body>
+</html>
+`))
+
+// webMapsEntry is a published mindmap listed by webMapsPage, with the links to its exports
+type webMapsEntry struct {
+	Owner, Name string
+	Links       []webMapsLink
+}
+
+// webMapsLink is the link to a published export
+type webMapsLink struct {
+	Format, URL string
+}
+
+// serveMaps serves the exports of the mindmaps of web_maps at /maps/<owner>/<mindmap>.<format>, as the user of the
+// basic authentication exports them, and lists them at /maps/. The user sees own and public mindmaps, without the
+// private subtrees of others. An export is rendered once and served as rendered until a change to its mindmap,
+// see handleRenderEvent.
+func (a *WebAdapter) serveMaps(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, session.PublishedMapsPath)
+	var owner, name, format string
+	if path != "" {
+		var file string
+		var ok bool
+		owner, file, ok = strings.Cut(path, "/")
+		dot := strings.LastIndex(file, ".")
+		if !ok || dot <= 0 || strings.Contains(file, "/") || !slices.Contains(session.PublishedFormats, file[dot+1:]) {
+			http.NotFound(w, r)
+			return
+		}
+		name, format = file[:dot], file[dot+1:]
+	}
+
+	sessionID, ok := a.httpSession(w, r)
+	if !ok {
+		return
+	}
+	defer a.adapterManager.SessionDelete(sessionID)
+	sm := a.adapterManager.sessionManager
+
+	if path == "" {
+		mindmaps, err := sm.PublishedMindmaps(sessionID)
+		if err != nil {
+			webMapsError(w, err)
+			return
+		}
+		var entries []webMapsEntry
+		for _, mindmap := range mindmaps {
+			entry := webMapsEntry{Owner: mindmap.Owner, Name: mindmap.Name}
+			for _, format := range session.PublishedFormats {
+				link := session.PublishedMapsPath + url.PathEscape(mindmap.Owner) + "/" + url.PathEscape(mindmap.Name) + "." + format
+				entry.Links = append(entry.Links, webMapsLink{Format: format, URL: link})
+			}
+			entries = append(entries, entry)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := webMapsPage.Execute(w, entries); err != nil {
+			a.logger.Warn(ctx, "Failed to send published mindmaps", log.Fields{"error": err})
+		}
+		return
+	}
+
+	mindmap, err := sm.PublishedMindmap(sessionID, owner, name)
+	if err != nil {
+		webMapsError(w, err)
+		return
+	}
+	// Owners see their private subtrees, other users do not, so each is served a rendering of its own
+	view := "public"
+	if s, exists := a.adapterManager.SessionGet(sessionID); exists && s.User != nil && s.User.Username == mindmap.Owner {
+		view = "owner"
+	}
+	key := fmt.Sprintf("%s%d.%s.%s", session.PublishedMapsPath, mindmap.ID, view, format)
+
+	data, generation, cached := a.renders.get(key)
+	if !cached {
+		data, err = sm.PublishedEncode(sessionID, mindmap, format)
+		if err != nil {
+			a.logger.Error(ctx, "Failed to render published mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID, "format": format})
+			webMapsError(w, err)
+			return
+		}
+		mindmapID := mindmap.ID
+		a.renders.put(key, data, func(mindmap *model.Mindmap) bool { return mindmap.ID == mindmapID }, generation)
+		a.logger.Debug(ctx, "Published mindmap rendered", log.Fields{"mindmapID": mindmap.ID, "format": format})
+	}
+
+	w.Header().Set("Content-Type", webMapTypes[format])
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(data)
+}
+
+// webMapsError answers a request for published mindmaps with an error
+func webMapsError(w http.ResponseWriter, err error) {
+	if errors.Is(err, session.ErrNotPublished) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, "failed to render mindmap", http.StatusInternalServerError)
+}
@@ -0,0 +1,75 @@
+package adapter
+
+import (
+	"context"
+	"sync"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// renderEvents are the events of changes to the nodes of mindmaps, or to the mindmaps themselves, that outdate
+// what the web adapter rendered of them
+var renderEvents = append([]event.EventType{event.MindmapAdded, event.MindmapDeleted}, mindmapEvents...)
+
+// webRenders holds what the web adapter rendered of mindmaps for plain HTTP requests, such as calendar feeds and
+// published exports, by a key of its feature, until a change outdates it
+type webRenders struct {
+	mutex      sync.Mutex
+	renders    map[string]*webRender
+	generation uint64 // Raised by each change, so that a render made meanwhile is not kept as current
+}
+
+// webRender is a rendering of mindmaps, outdated by the changes to the mindmaps it covers
+type webRender struct {
+	data   []byte
+	covers func(mindmap *model.Mindmap) bool
+}
+
+// get returns the rendering of a key, if there is a current one, and the generation to put a new rendering with
+func (c *webRenders) get(key string) ([]byte, uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if render, ok := c.renders[key]; ok {
+		return render.data, c.generation, true
+	}
+	return nil, c.generation, false
+}
+
+// put keeps the rendering of a key, unless a change since get outdated it
+func (c *webRenders) put(key string, data []byte, covers func(mindmap *model.Mindmap) bool, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.generation == generation {
+		c.renders[key] = &webRender{data: data, covers: covers}
+	}
+}
+
+// drop discards the rendering of a key
+func (c *webRenders) drop(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.renders, key)
+}
+
+// handleRenderEvent discards the renderings covering a changed mindmap, to be rendered anew on their next request
+func (a *WebAdapter) handleRenderEvent(e event.Event) {
+	mindmap, ok := e.Data.(*model.Mindmap)
+	if !ok {
+		var err error
+		if mindmap, _, err = mindmapChange(e); err != nil {
+			a.logger.Error(context.Background(), "Invalid event for web renderings", log.Fields{"error": err, "event": e.Type.String()})
+			return
+		}
+	}
+
+	a.renders.mutex.Lock()
+	defer a.renders.mutex.Unlock()
+	a.renders.generation++
+	for key, render := range a.renders.renders {
+		if render.covers(mindmap) {
+			delete(a.renders.renders, key)
+		}
+	}
+}
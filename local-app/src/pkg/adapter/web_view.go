@@ -0,0 +1,103 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// webViewPath is the path mindmaps are viewed under, followed by the name of the mindmap
+const webViewPath = "/view/"
+
+// webViewsPage lists the mindmaps the user may view
+var webViewsPage = template.Must(template.New("views").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Mindmaps - Mindnoscape</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 1rem; line-height: 1.8; }
+.owner { color: #888; }
+</style>
+</head>
+<body>
+<h1>Mindmaps</h1>
+{{if .}}<ul>{{range .}}<li><a href="{{.URL}}">{{.Name}}</a> <span class="owner">{{.Owner}}</span></li>
+{{end}}</ul>{{else}}<p>No mindmaps to view.</p>{{end}}
+</body>
+</html>
+`))
+
+// webViewsEntry is a mindmap listed by webViewsPage
+type webViewsEntry struct {
+	Name, Owner, URL string
+}
+
+// serveView serves a read-only page of a mindmap at /view/<mindmap> for reading on phones, rendered on the server
+// without a client application, and lists the mindmaps the user may view at /view/. The user is authenticated with
+// HTTP basic authentication and the mindmap selected as mindmap select selects it, so the private subtrees of
+// others are not shown. The branches are collapsed, and the q query parameter, set by the search box of the page,
+// finds nodes as node find does, expanding the branches to them.
+func (a *WebAdapter) serveView(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, webViewPath)
+	if strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	sessionID, ok := a.httpSession(w, r)
+	if !ok {
+		return
+	}
+	defer a.adapterManager.SessionDelete(sessionID)
+	sm := a.adapterManager.sessionManager
+
+	if name == "" {
+		mindmaps, err := sm.MindmapsVisible(sessionID)
+		if err != nil {
+			http.Error(w, "failed to list mindmaps", http.StatusInternalServerError)
+			return
+		}
+		var entries []webViewsEntry
+		for _, mindmap := range mindmaps {
+			entries = append(entries, webViewsEntry{Name: mindmap.Name, Owner: mindmap.Owner, URL: webViewPath + url.PathEscape(mindmap.Name)})
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := webViewsPage.Execute(w, entries); err != nil {
+			a.logger.Warn(ctx, "Failed to send mindmap list", log.Fields{"error": err})
+		}
+		return
+	}
+
+	if _, err := a.adapterManager.CommandRun(sessionID, model.Command{Scope: "mindmap", Operation: "select", Args: []string{name}}); err != nil {
+		if errors.Is(err, ErrShuttingDown) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	page, err := sm.MindmapPage(sessionID, webViewPath+url.PathEscape(name), strings.TrimSpace(r.URL.Query().Get("q")))
+	if err != nil {
+		a.logger.Error(ctx, "Failed to render mindmap page", log.Fields{"error": err, "mindmapName": name})
+		http.Error(w, "failed to render mindmap", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(page)
+}
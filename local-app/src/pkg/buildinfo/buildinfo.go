@@ -0,0 +1,67 @@
+// Package buildinfo reports the version of Mindnoscape and how its binary was built.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the version of Mindnoscape, set when building a release with
+// -ldflags "-X mindnoscape/local-app/src/pkg/buildinfo.Version=<version>"
+var Version = "dev"
+
+// Info describes the binary that is running
+type Info struct {
+	Version   string   // Version, or the module version go install built if it was not set
+	GoVersion string   // The Go toolchain that built the binary
+	Platform  string   // The operating system and architecture, such as linux/amd64
+	Revision  string   // The commit built from, empty if not built in a git checkout
+	Time      string   // The time of the commit, RFC 3339
+	Modified  bool     // Built with uncommitted changes
+	Tags      string   // The build tags
+	CGO       bool     // Built with cgo, needed by the SQLite driver
+	Modules   []Module // The modules the binary is built with
+}
+
+// Module is a dependency the binary is built with
+type Module struct {
+	Path    string
+	Version string
+	Replace string // The module path replacing it, if any
+}
+
+// Get returns the build information of the running binary. Binaries built without module support only have the
+// version and the platform.
+func Get() Info {
+	info := Info{Version: Version, GoVersion: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.Time = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		case "-tags":
+			info.Tags = setting.Value
+		case "CGO_ENABLED":
+			info.CGO = setting.Value == "1"
+		}
+	}
+	for _, dep := range build.Deps {
+		module := Module{Path: dep.Path, Version: dep.Version}
+		if dep.Replace != nil {
+			module.Replace = dep.Replace.Path + " " + dep.Replace.Version
+		}
+		info.Modules = append(info.Modules, module)
+	}
+	return info
+}
@@ -0,0 +1,276 @@
+// Package capture reads new emails from IMAP mailboxes so they can be captured as nodes.
+// It implements the small part of IMAP4rev1 (RFC 3501) needed to find, fetch and flag new emails.
+package capture
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+const (
+	defaultIMAPPort = "993"
+	maxLiteralSize  = 32 << 20 // Largest email fetched, larger ones are rejected
+)
+
+var (
+	literalPattern     = regexp.MustCompile(`\{(\d+)\+?\}$`)
+	uidValidityPattern = regexp.MustCompile(`(?i)\[UIDVALIDITY (\d+)\]`)
+	fetchUIDPattern    = regexp.MustCompile(`(?i)\bUID (\d+)`)
+)
+
+// response is an untagged server response, with the data of its literals apart from its text
+type response struct {
+	text     string
+	literals [][]byte
+}
+
+// Client is a connection to an IMAP server over TLS. It is not safe for concurrent use.
+type Client struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+	tag     int
+}
+
+// Dial connects to an IMAP server given as host[:port], on the IMAPS port 993 by default, and reads its greeting.
+// Every later command must complete within timeout.
+func Dial(server string, timeout time.Duration) (*Client, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, defaultIMAPPort)
+	}
+	host, _, _ := net.SplitHostPort(server)
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", server, &tls.Config{ServerName: host})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", server, err)
+	}
+
+	c := &Client{conn: conn, reader: bufio.NewReader(conn), timeout: timeout}
+	conn.SetDeadline(time.Now().Add(timeout))
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("server refused connection: %s", greeting)
+	}
+	return c, nil
+}
+
+// Close logs out and closes the connection
+func (c *Client) Close() error {
+	c.command("LOGOUT")
+	return c.conn.Close()
+}
+
+// Login authenticates with a login name and password
+func (c *Client) Login(login, password string) error {
+	if _, err := c.command("LOGIN " + quote(login) + " " + quote(password)); err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	return nil
+}
+
+// Select opens a mailbox and returns its UID validity. UIDs of different UID validities are not comparable.
+func (c *Client) Select(mailbox string) (uint32, error) {
+	responses, err := c.command("SELECT " + quote(mailbox))
+	if err != nil {
+		return 0, fmt.Errorf("failed to select mailbox %s: %w", mailbox, err)
+	}
+	for _, r := range responses {
+		if m := uidValidityPattern.FindStringSubmatch(r.text); m != nil {
+			validity, err := strconv.ParseUint(m[1], 10, 32)
+			if err == nil {
+				return uint32(validity), nil
+			}
+		}
+	}
+	return 0, errors.New("server reported no UID validity")
+}
+
+// Unseen returns the UIDs above after of the emails of the selected mailbox not flagged as seen, in ascending order
+func (c *Client) Unseen(after uint32) ([]uint32, error) {
+	responses, err := c.command(fmt.Sprintf("UID SEARCH UNSEEN UID %d:*", after+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search mailbox: %w", err)
+	}
+
+	var uids []uint32
+	for _, r := range responses {
+		fields := strings.Fields(r.text)
+		if len(fields) < 2 || !strings.EqualFold(fields[1], "SEARCH") {
+			continue
+		}
+		for _, field := range fields[2:] {
+			// The range n:* always includes the last email, even if its UID is below n
+			if uid, err := strconv.ParseUint(field, 10, 32); err == nil && uint32(uid) > after {
+				uids = append(uids, uint32(uid))
+			}
+		}
+	}
+	slices.Sort(uids)
+	return uids, nil
+}
+
+// Fetch returns the raw RFC 5322 message of an email without flagging it as seen
+func (c *Client) Fetch(uid uint32) ([]byte, error) {
+	responses, err := c.command(fmt.Sprintf("UID FETCH %d (UID BODY.PEEK[])", uid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch email %d: %w", uid, err)
+	}
+	for _, r := range responses {
+		m := fetchUIDPattern.FindStringSubmatch(r.text)
+		if m == nil || m[1] != strconv.FormatUint(uint64(uid), 10) || len(r.literals) == 0 {
+			continue
+		}
+		return r.literals[0], nil
+	}
+	return nil, fmt.Errorf("email %d not found", uid)
+}
+
+// MarkSeen flags emails as seen
+func (c *Client) MarkSeen(uids []uint32) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	set := make([]string, len(uids))
+	for i, uid := range uids {
+		set[i] = strconv.FormatUint(uint64(uid), 10)
+	}
+	if _, err := c.command("UID STORE " + strings.Join(set, ",") + ` +FLAGS.SILENT (\Seen)`); err != nil {
+		return fmt.Errorf("failed to flag emails as seen: %w", err)
+	}
+	return nil
+}
+
+// command sends a command and returns its untagged responses once it completed successfully
+func (c *Client) command(cmd string) ([]response, error) {
+	c.tag++
+	tag := "m" + strconv.Itoa(c.tag)
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if _, err := io.WriteString(c.conn, tag+" "+cmd+"\r\n"); err != nil {
+		return nil, err
+	}
+
+	var responses []response
+	for {
+		r, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if rest, ok := strings.CutPrefix(r.text, tag+" "); ok {
+			status, text, _ := strings.Cut(rest, " ")
+			if !strings.EqualFold(status, "OK") {
+				return nil, fmt.Errorf("server replied %s %s", status, text)
+			}
+			return responses, nil
+		}
+		if strings.HasPrefix(r.text, "* ") {
+			responses = append(responses, r)
+		}
+	}
+}
+
+// readResponse reads a response line, along with the literals it announces and the rest of the line after them
+func (c *Client) readResponse() (response, error) {
+	var r response
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return r, err
+		}
+		r.text += line
+
+		m := literalPattern.FindStringSubmatch(line)
+		if m == nil {
+			return r, nil
+		}
+		size, err := strconv.Atoi(m[1])
+		if err != nil || size > maxLiteralSize {
+			return r, fmt.Errorf("literal of %s bytes too large", m[1])
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.reader, literal); err != nil {
+			return r, err
+		}
+		r.literals = append(r.literals, literal)
+	}
+}
+
+// readLine reads a line without its CRLF
+func (c *Client) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// quote returns a string as an IMAP quoted string
+func quote(s string) string {
+	s = strings.NewReplacer("\r", "", "\n", "").Replace(s)
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// Poll reads the unseen emails of the mailbox of a capture account received after its last captured email, at
+// most limit at a time, and passes them to commit along with the UID validity of the mailbox. If the UID
+// validity changed, all unseen emails are read again. Once commit succeeded the emails are flagged as seen.
+func Poll(account *model.CaptureAccount, timeout time.Duration, limit int, commit func(validity uint32, items []model.CaptureItem) error) error {
+	client, err := Dial(account.Server, timeout)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Login(account.Login, account.Password); err != nil {
+		return err
+	}
+	validity, err := client.Select(account.Mailbox)
+	if err != nil {
+		return err
+	}
+	after := account.LastUID
+	if validity != account.UIDValidity {
+		after = 0
+	}
+
+	uids, err := client.Unseen(after)
+	if err != nil {
+		return err
+	}
+	if len(uids) > limit {
+		uids = uids[:limit]
+	}
+
+	items := make([]model.CaptureItem, 0, len(uids))
+	for _, uid := range uids {
+		raw, err := client.Fetch(uid)
+		if err != nil {
+			return err
+		}
+		item, err := ParseMessage(uid, raw)
+		if err != nil {
+			// An unreadable email is still captured so it is not fetched again on every poll
+			item = model.CaptureItem{UID: uid, Name: noSubject, Content: map[string]string{NoteField: err.Error()}}
+		}
+		items = append(items, item)
+	}
+
+	if err := commit(validity, items); err != nil {
+		return err
+	}
+	return client.MarkSeen(uids)
+}
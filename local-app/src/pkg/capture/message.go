@@ -0,0 +1,172 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// Content fields of captured email nodes
+const (
+	NoteField = "note"
+	FromField = "from"
+	DateField = "date"
+)
+
+const (
+	maxNoteLength = 8000 // Longest note kept of an email body, in characters
+	noSubject     = "(no subject)"
+)
+
+var (
+	htmlTagPattern   = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+	wordDecoder      = &mime.WordDecoder{CharsetReader: charsetReader}
+)
+
+// ParseMessage converts a raw email into a capture item: the subject becomes the name, the plain text body the
+// note and the sender and date become fields. HTML-only bodies are reduced to their text.
+func ParseMessage(uid uint32, raw []byte) (model.CaptureItem, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return model.CaptureItem{}, fmt.Errorf("invalid email %d: %w", uid, err)
+	}
+
+	item := model.CaptureItem{UID: uid, Content: make(map[string]string)}
+
+	subject, err := wordDecoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	item.Name = strings.Join(strings.Fields(subject), " ")
+	if item.Name == "" {
+		item.Name = noSubject
+	}
+
+	if from, err := msg.Header.AddressList("From"); err == nil && len(from) > 0 {
+		item.Content[FromField] = from[0].String()
+		if from[0].Name != "" {
+			item.Content[FromField] = from[0].Name + " <" + from[0].Address + ">"
+		}
+	} else if sender := strings.TrimSpace(msg.Header.Get("From")); sender != "" {
+		item.Content[FromField] = sender
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		item.Content[DateField] = date.Format("2006-01-02 15:04")
+	}
+
+	note, err := messageText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return model.CaptureItem{}, fmt.Errorf("invalid body of email %d: %w", uid, err)
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		if runes := []rune(note); len(runes) > maxNoteLength {
+			note = string(runes[:maxNoteLength]) + "…"
+		}
+		item.Content[NoteField] = note
+	}
+	return item, nil
+}
+
+// messageText returns the text of a message body or part, preferring plain text over HTML among alternatives.
+// Parts other than text, such as attachments, have no text.
+func messageText(contentType, transferEncoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var plain, html string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			if strings.HasPrefix(part.Header.Get("Content-Disposition"), "attachment") {
+				continue
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			text, err := messageText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case text == "":
+			case partType == "text/html":
+				html = firstNonEmpty(html, text)
+			default:
+				plain = firstNonEmpty(plain, text)
+			}
+		}
+		return firstNonEmpty(plain, html), nil
+	}
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", nil
+	}
+
+	// Quoted-printable parts are already decoded by the multipart reader, which drops their encoding header
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	if charset := params["charset"]; charset != "" {
+		if body, err = charsetReader(charset, body); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if mediaType == "text/html" {
+		text = htmlText(text)
+	}
+	return text, nil
+}
+
+// htmlText reduces an HTML body to its text
+func htmlText(html string) string {
+	html = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n", "</div>", "\n").Replace(html)
+	text := htmlTagPattern.ReplaceAllString(html, "")
+	text = strings.NewReplacer("&nbsp;", " ", "&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&#39;", "'").Replace(text)
+	return blankLinePattern.ReplaceAllString(text, "\n\n")
+}
+
+// charsetReader decodes text of a charset to UTF-8, unknown charsets are read as they are
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return input, nil
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
+
+// firstNonEmpty returns the first of the texts that is not empty
+func firstNonEmpty(texts ...string) string {
+	for _, text := range texts {
+		if strings.TrimSpace(text) != "" {
+			return text
+		}
+	}
+	return ""
+}
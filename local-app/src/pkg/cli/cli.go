@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"mindnoscape/local-app/src/pkg/adapter"
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/terminal"
 )
 
 // CLI represents the command-line interface
@@ -19,6 +21,8 @@ type CLI struct {
 	stopCh  chan struct{}
 	reader  io.Reader
 	writer  io.Writer
+	pager   *adapter.Pager // Pages long results, nil unless the output is a terminal
+	startup []model.Command
 	logger  *log.Logger
 }
 
@@ -42,12 +46,37 @@ func NewCLI(adapter *adapter.CLIAdapter, logger *log.Logger) (*CLI, error) {
 	return cli, nil
 }
 
+// Startup sets the commands run when the CLI starts, before the first input, such as the selection of a user
+func (c *CLI) Startup(commands ...model.Command) {
+	c.startup = commands
+}
+
 // Run starts the CLI and handles user input
 func (c *CLI) Run() error {
+	// Colored output, such as of diffs, and the line editor rely on escape sequences
+	if err := terminal.EnableSequences(); err != nil {
+		c.logger.Warn(context.Background(), "Failed to enable terminal escape sequences", log.Fields{"error": err})
+	}
+
 	fmt.Println("Welcome to Mindnoscape CLI!")
 	fmt.Println("Type 'system help' for a list of commands or 'system exit' to quit.")
 
+	// Only a terminal needs paging, piped and redirected output is written at once
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		c.pager = adapter.NewPager(c.writer, c.readLine)
+	}
+
+	for _, cmd := range c.startup {
+		result, err := c.adapter.CommandRun(c.session.ID, cmd)
+		if err != nil {
+			fmt.Printf("Error: %s %s: %v\n", cmd.Scope, cmd.Operation, err)
+		} else if result != nil {
+			c.resultWrite(result)
+		}
+	}
+
 	for {
+		c.jobsWrite()
 		prompt := c.adapter.PromptGet(c.session.ID)
 		fmt.Print(prompt)
 
@@ -63,10 +92,13 @@ func (c *CLI) Run() error {
 
 		// Send raw input to CLIAdapter
 		result, err := c.adapter.ProcessInput(c.session.ID, input)
+		if errors.Is(err, adapter.ErrSessionExpired) {
+			err = c.sessionRenew()
+		}
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 		} else if result != nil {
-			fmt.Printf("%v\n", result)
+			c.resultWrite(result)
 		}
 
 		// Check if the command was to exit/quit
@@ -75,11 +107,76 @@ func (c *CLI) Run() error {
 		}
 	}
 
+	// Leaving while background jobs run would lose their work, such as an import not stored yet
+	if running := c.adapter.JobsRunning(c.session.ID); running > 0 {
+		fmt.Printf("Waiting for %d background jobs to finish...\n", running)
+	}
+	c.adapter.JobsWait(c.session.ID)
+	c.jobsWrite()
+
 	c.logger.Info(context.Background(), "CLI stopped", nil)
 	return nil
 }
 
-// readLine reads a line of input from the reader
+// jobsWrite writes the results of the background jobs finished since the last prompt
+func (c *CLI) jobsWrite() {
+	for _, result := range c.adapter.JobsFinished(c.session.ID) {
+		c.resultWrite(result)
+	}
+}
+
+// sessionRenew replaces the expired session of the CLI with a new one, the input to the expired session is dropped
+func (c *CLI) sessionRenew() error {
+	sessionID, err := c.adapter.SessionAdd()
+	if err != nil {
+		c.logger.Error(context.Background(), "Failed to renew expired session", log.Fields{"error": err})
+		return fmt.Errorf("session expired and a new session could not be created: %w", err)
+	}
+
+	c.logger.Info(context.Background(), "Expired CLI session renewed", log.Fields{"expiredSessionID": c.session.ID, "sessionID": sessionID})
+	c.session.ID = sessionID
+	fmt.Println("Session expired after inactivity, started a new session. Select the user and mindmap again to continue.")
+	return nil
+}
+
+// resultWrite writes the result of a command, a list one item per line, through the pager if there is one.
+// A partial page of a list is followed by its position in the list and the offset of the next page.
+// A node update is shown as the diff of the node.
+func (c *CLI) resultWrite(result interface{}) {
+	text := fmt.Sprintf("%v", result)
+	switch r := result.(type) {
+	case []string:
+		text = strings.Join(r, "\n")
+	case model.Page[string]:
+		lines := r.Items
+		if r.Offset > 0 || r.More() {
+			lines = append(lines, pageFooter(r.Offset, len(r.Items), r.Total, r.More()))
+		}
+		text = strings.Join(lines, "\n")
+	case model.NodeChange:
+		// Changed words are colored on a terminal unless NO_COLOR is set
+		text = r.Format(c.pager != nil && os.Getenv("NO_COLOR") == "")
+	}
+
+	if c.pager != nil {
+		c.pager.Show(text)
+		return
+	}
+	fmt.Fprintln(c.writer, text)
+}
+
+// pageFooter describes the position of a page of count items in a list of total items
+func pageFooter(offset, count, total int, more bool) string {
+	if count == 0 {
+		return fmt.Sprintf("-- no results at offset %d of %d --", offset, total)
+	}
+	if !more {
+		return fmt.Sprintf("-- %d-%d of %d --", offset+1, offset+count, total)
+	}
+	return fmt.Sprintf("-- %d-%d of %d, next page with --offset %d --", offset+1, offset+count, total, offset+count)
+}
+
+// readLine reads a line of input from the reader, without the line break, also a Windows \r\n
 func (c *CLI) readLine() (string, error) {
 	var line strings.Builder
 	for {
@@ -87,7 +184,7 @@ func (c *CLI) readLine() (string, error) {
 		n, err := c.reader.Read(b[:])
 		if err != nil {
 			if err == io.EOF && line.Len() > 0 {
-				return line.String(), nil
+				return strings.TrimSuffix(line.String(), "\r"), nil
 			}
 			return "", err
 		}
@@ -95,7 +192,7 @@ func (c *CLI) readLine() (string, error) {
 			continue
 		}
 		if b[0] == '\n' {
-			return line.String(), nil
+			return strings.TrimSuffix(line.String(), "\r"), nil
 		}
 		line.WriteByte(b[0])
 	}
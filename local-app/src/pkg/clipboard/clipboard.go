@@ -0,0 +1,58 @@
+// Package clipboard places text on the system clipboard through the clipboard tool of the platform.
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// toolTimeout is the time allowed for the clipboard tool to take the text
+const toolTimeout = 5 * time.Second
+
+// ErrUnavailable is returned when none of the clipboard tools of the platform is installed
+var ErrUnavailable = errors.New("no clipboard tool found, install wl-clipboard, xclip or xsel")
+
+// tools returns the clipboard tools of the platform with their arguments, in order of preference
+func tools() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+
+	unix := [][]string{{"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return append([][]string{{"wl-copy"}}, unix...)
+	}
+	return unix
+}
+
+// Write places text on the clipboard using the first clipboard tool installed
+func Write(text string) error {
+	for _, tool := range tools() {
+		path, err := exec.LookPath(tool[0])
+		if err != nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), toolTimeout)
+		defer cancel()
+
+		// The output is not captured, X11 tools keep running in the background to serve the clipboard
+		// and would hold its pipes open
+		cmd := exec.CommandContext(ctx, path, tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("clipboard tool %s failed: %w", tool[0], err)
+		}
+		return nil
+	}
+	return ErrUnavailable
+}
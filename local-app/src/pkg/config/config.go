@@ -3,12 +3,15 @@
 package config
 
 import (
+	"bytes"
+	_ "embed"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
 )
 
 // Global variables to store the current configuration and its file path.
@@ -28,22 +31,12 @@ func ConfigLoad() error {
 
 	// Check if the config file exists, if not create a default one
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		defaultConfig := &model.Config{
-			DatabaseDir:         "./data",
-			DatabaseFile:        "mindnoscape.db",
-			DatabaseType:        "sqlite",
-			LogFolder:           "./logs",
-			CommandLog:          "commands.log",
-			ErrorLog:            "errors.log",
-			InfoLog:             "info.log",
-			DefaultUser:         "a",
-			DefaultUserActive:   true,
-			DefaultUserPassword: "",
-		}
+		defaultConfig := ConfigDefault()
 		if err := ConfigSave(defaultConfig); err != nil {
 			return fmt.Errorf("failed to create default config: %v", err)
 		}
 		currentConfig = defaultConfig
+		currentConfig.ConfigFile = ConfigPath()
 		return nil
 	}
 
@@ -58,6 +51,7 @@ func ConfigLoad() error {
 	if err := json.Unmarshal(file, currentConfig); err != nil {
 		return fmt.Errorf("error parsing config file: %v", err)
 	}
+	currentConfig.ConfigFile = ConfigPath()
 
 	// Set default database type if not specified
 	if currentConfig.DatabaseType == "" {
@@ -67,25 +61,219 @@ func ConfigLoad() error {
 		}
 	}
 
+	// Set default journal log if not specified
+	if currentConfig.JournalLog == "" {
+		currentConfig.JournalLog = "journal.log"
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default export directory and filename template if not specified
+	if currentConfig.ExportDir == "" || currentConfig.ExportTemplate == "" {
+		if currentConfig.ExportDir == "" {
+			currentConfig.ExportDir = "./exports"
+		}
+		if currentConfig.ExportTemplate == "" {
+			currentConfig.ExportTemplate = "{mindmap}-{date}.{format}"
+		}
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default signing key directory if not specified
+	if currentConfig.KeyDir == "" {
+		currentConfig.KeyDir = "./data/keys"
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default large operation threshold if not specified, a negative threshold disables the check
+	if currentConfig.LargeOpThreshold == 0 {
+		currentConfig.LargeOpThreshold = 1000
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default reindex threshold if not specified, a negative threshold disables the automatic reindex
+	if currentConfig.ReindexThreshold == 0 {
+		currentConfig.ReindexThreshold = 500
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default email capture interval if not specified, a negative interval disables email capture
+	if currentConfig.CaptureInterval == 0 {
+		currentConfig.CaptureInterval = 300
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default reminder interval if not specified, a negative interval disables reminders
+	if currentConfig.ReminderInterval == 0 {
+		currentConfig.ReminderInterval = 60
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default backup directory if not specified
+	if currentConfig.BackupDir == "" {
+		currentConfig.BackupDir = "./data/backups"
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default backup interval if not specified, a negative interval disables automatic backups
+	if currentConfig.BackupInterval == 0 {
+		currentConfig.BackupInterval = 86400
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default backup retention if not specified, a negative retention keeps all snapshots
+	if currentConfig.BackupRetention == 0 {
+		currentConfig.BackupRetention = 7
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default version interval if not specified, a negative interval disables automatic versions
+	if currentConfig.VersionInterval == 0 {
+		currentConfig.VersionInterval = 100
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default version retention if not specified, a negative retention keeps all automatic versions
+	if currentConfig.VersionRetention == 0 {
+		currentConfig.VersionRetention = 20
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default connection limit if not specified, a negative limit serves any number of clients
+	if currentConfig.MaxConnections == 0 {
+		currentConfig.MaxConnections = 64
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default shutdown timeout if not specified, a negative timeout stops without waiting
+	if currentConfig.ShutdownTimeout == 0 {
+		currentConfig.ShutdownTimeout = 10
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default reminder sinks if not specified, an empty list delivers reminders nowhere
+	if currentConfig.ReminderSinks == nil {
+		currentConfig.ReminderSinks = []model.ReminderSink{{Type: "log"}}
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default redaction profiles if not specified, an empty map configures none
+	if currentConfig.RedactionProfiles == nil {
+		currentConfig.RedactionProfiles = ConfigDefault().RedactionProfiles
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default daily journal mindmap if not specified
+	if currentConfig.JournalMindmap == "" {
+		currentConfig.JournalMindmap = "Journal"
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default root display if not specified
+	if currentConfig.RootDisplay == "" {
+		currentConfig.RootDisplay = model.RootAsTitle
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default log level if not specified
+	if currentConfig.LogLevel == "" {
+		currentConfig.LogLevel = "info"
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
+	// Set default prompt if not specified
+	if currentConfig.Prompt == "" {
+		currentConfig.Prompt = DefaultPrompt
+		if err := ConfigSave(currentConfig); err != nil {
+			return fmt.Errorf("failed to save updated config: %v", err)
+		}
+	}
+
 	return nil
 }
 
+// DefaultPrompt is the CLI prompt template showing the user, the mindmap and the current node once selected
+const DefaultPrompt = "{user}[ @ {mindmap}][:{node}] > "
+
+// defaultConfig is the configuration written for a new data directory, built into the binary
+//
+//go:embed default_config.json
+var defaultConfig []byte
+
+// ConfigDefault returns the default configuration, with all paths relative to the working directory.
+func ConfigDefault() *model.Config {
+	cfg := &model.Config{}
+	if err := json.Unmarshal(defaultConfig, cfg); err != nil {
+		panic(fmt.Sprintf("invalid built-in default config: %v", err))
+	}
+	return cfg
+}
+
 // ConfigSave saves the provided configuration to the JSON file.
 func ConfigSave(cfg *model.Config) error {
-	// Marshal the config to JSON
-	data, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
+	// Marshal the config to JSON, leaving characters such as the > of the prompt readable
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cfg); err != nil {
 		return fmt.Errorf("error marshaling config: %v", err)
 	}
 
-	// Write the JSON data to the config file
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	// Write the JSON data to the config file, replacing it only once fully written
+	if err := storage.WriteFileAtomic(configPath, data.Bytes(), 0644); err != nil {
 		return fmt.Errorf("error writing config file: %v", err)
 	}
 
 	return nil
 }
 
+// ConfigPath returns the absolute path of the configuration file, or the path relative to the working directory if
+// it cannot be made absolute.
+func ConfigPath() string {
+	if path, err := filepath.Abs(configPath); err == nil {
+		return path
+	}
+	return configPath
+}
+
 // ConfigGet returns the current configuration.
 func ConfigGet() *model.Config {
 	return currentConfig
@@ -0,0 +1,108 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains operations related to the audit log.
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// AuditOperations defines the interface for audit log operations
+type AuditOperations interface {
+	AuditRecord(entry model.AuditEntry) error
+	AuditTail(username string, limit int) ([]*model.AuditEntry, error)
+	AuditFind(auditInfo model.AuditEntry, auditFilter model.AuditFilter, limit int) ([]*model.AuditEntry, error)
+	AuditSearch(query string, username string, limit int) ([]*model.AuditEntry, error)
+}
+
+// AuditManager handles recording and querying of the append-only audit log.
+type AuditManager struct {
+	auditStore storage.AuditStore
+	logger     *log.Logger
+}
+
+// NewAuditManager creates a new AuditManager instance.
+func NewAuditManager(auditStore storage.AuditStore, logger *log.Logger) (*AuditManager, error) {
+	ctx := context.Background()
+	logger.Info(ctx, "Creating new AuditManager", nil)
+
+	if auditStore == nil {
+		logger.Error(ctx, "AuditStore not initialized", nil)
+		return nil, fmt.Errorf("auditStore not initialized")
+	}
+
+	am := &AuditManager{
+		auditStore: auditStore,
+		logger:     logger,
+	}
+
+	logger.Info(ctx, "AuditManager created successfully", nil)
+	return am, nil
+}
+
+// AuditRecord appends an entry to the audit log.
+func (am *AuditManager) AuditRecord(entry model.AuditEntry) error {
+	ctx := context.Background()
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	_, err := am.auditStore.AuditAdd(entry)
+	if err != nil {
+		am.logger.Error(ctx, "Failed to record audit entry", log.Fields{"error": err, "scope": entry.Scope, "operation": entry.Operation})
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// AuditTail returns the last limit entries of the audit log in chronological order, those of a user if username is
+// not empty.
+func (am *AuditManager) AuditTail(username string, limit int) ([]*model.AuditEntry, error) {
+	ctx := context.Background()
+	am.logger.Info(ctx, "Retrieving audit log tail", log.Fields{"username": username, "limit": limit})
+
+	entries, err := am.auditStore.AuditGet(model.AuditEntry{Username: username}, model.AuditFilter{Username: username != ""}, limit)
+	if err != nil {
+		am.logger.Error(ctx, "Failed to get audit entries", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// AuditFind returns the last limit entries of the audit log matching the info and filter in chronological order,
+// all of them if limit is 0 or less.
+func (am *AuditManager) AuditFind(auditInfo model.AuditEntry, auditFilter model.AuditFilter, limit int) ([]*model.AuditEntry, error) {
+	ctx := context.Background()
+	am.logger.Info(ctx, "Finding audit entries", log.Fields{"filter": auditFilter, "limit": limit})
+
+	entries, err := am.auditStore.AuditGet(auditInfo, auditFilter, limit)
+	if err != nil {
+		am.logger.Error(ctx, "Failed to get audit entries", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// AuditSearch returns the last limit entries of the audit log matching the query in chronological order, those of a
+// user if username is not empty.
+func (am *AuditManager) AuditSearch(query string, username string, limit int) ([]*model.AuditEntry, error) {
+	ctx := context.Background()
+	am.logger.Info(ctx, "Searching audit log", log.Fields{"query": query, "username": username, "limit": limit})
+
+	entries, err := am.auditStore.AuditSearch(query, username, limit)
+	if err != nil {
+		am.logger.Error(ctx, "Failed to search audit entries", log.Fields{"error": err, "query": query})
+		return nil, fmt.Errorf("failed to search audit entries: %w", err)
+	}
+
+	return entries, nil
+}
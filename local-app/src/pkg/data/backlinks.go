@@ -0,0 +1,118 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the index of references between nodes.
+package data
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+// referencePattern matches node references in node names and content, [[Name]] by name or [[#12]] by ID
+var referencePattern = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+
+// backlinkIndex maps the references of the nodes of a mindmap to the nodes containing them. References are kept
+// as written, by name or ID, so renaming or adding a referenced node needs no update of the index.
+type backlinkIndex struct {
+	refs      map[int][]string        // Reference keys contained in each node
+	referrers map[string]map[int]bool // Nodes containing each reference key
+}
+
+// newBacklinkIndex builds the backlink index of the loaded nodes of a mindmap
+func newBacklinkIndex(mindmap *model.Mindmap) *backlinkIndex {
+	index := &backlinkIndex{
+		refs:      make(map[int][]string),
+		referrers: make(map[string]map[int]bool),
+	}
+	for _, node := range mindmap.Nodes {
+		index.update(node)
+	}
+	return index
+}
+
+// update replaces the references of a node with those currently in its name and content
+func (b *backlinkIndex) update(node *model.Node) {
+	b.remove(node.ID)
+
+	keys := nodeReferences(node)
+	if len(keys) == 0 {
+		return
+	}
+	b.refs[node.ID] = keys
+	for _, key := range keys {
+		if b.referrers[key] == nil {
+			b.referrers[key] = make(map[int]bool)
+		}
+		b.referrers[key][node.ID] = true
+	}
+}
+
+// remove drops the references of a node
+func (b *backlinkIndex) remove(nodeID int) {
+	for _, key := range b.refs[nodeID] {
+		delete(b.referrers[key], nodeID)
+		if len(b.referrers[key]) == 0 {
+			delete(b.referrers, key)
+		}
+	}
+	delete(b.refs, nodeID)
+}
+
+// referring returns the IDs of the nodes referring to a node by its name or ID
+func (b *backlinkIndex) referring(node *model.Node) map[int]bool {
+	ids := make(map[int]bool)
+	for _, key := range []string{idReferenceKey(node.ID), referenceKey(node.Name)} {
+		for id := range b.referrers[key] {
+			if id != node.ID {
+				ids[id] = true
+			}
+		}
+	}
+	return ids
+}
+
+// nodeReferences returns the distinct reference keys in the name and content values of a node
+func nodeReferences(node *model.Node) []string {
+	var keys []string
+	texts := []string{node.Name}
+	for _, value := range node.Content {
+		texts = append(texts, value)
+	}
+	for _, text := range texts {
+		for _, target := range ParseReferences(text) {
+			if key := referenceKey(target); !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// ParseReferences returns the targets of the [[...]] node references in text, in order of appearance
+func ParseReferences(text string) []string {
+	var targets []string
+	for _, match := range referencePattern.FindAllStringSubmatch(text, -1) {
+		if target := strings.TrimSpace(match[1]); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// referenceKey returns the index key of a reference target, ID references are kept, names are compared
+// case-insensitively
+func referenceKey(target string) string {
+	if strings.HasPrefix(target, "#") {
+		return target
+	}
+	return names.Key(target, false)
+}
+
+// idReferenceKey returns the index key of a reference to a node ID
+func idReferenceKey(id int) string {
+	return "#" + strconv.Itoa(id)
+}
@@ -0,0 +1,188 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the snapshots of the database kept in the backup directory and the restore of mindmaps from
+// them.
+package data
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// Snapshots are named by the time they were taken, such as mindnoscape-20261017-153000.db
+const (
+	backupPrefix     = "mindnoscape-"
+	backupExt        = ".db"
+	backupTimeLayout = "20060102-150405"
+)
+
+// BackupCreate writes a snapshot of the database to the backup directory and deletes the oldest snapshots beyond
+// the configured retention. Returns the new snapshot.
+func (m *DataManager) BackupCreate() (model.Backup, error) {
+	ctx := context.Background()
+
+	if err := os.MkdirAll(m.Config.BackupDir, 0755); err != nil {
+		m.Logger.Error(ctx, "Failed to create backup directory", log.Fields{"error": err, "dir": m.Config.BackupDir})
+		return model.Backup{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// Snapshots taken within the same second, such as the one on exit right after a manual one, get a counter
+	stamp := backupPrefix + time.Now().Format(backupTimeLayout)
+	name := stamp + backupExt
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(m.Config.BackupDir, name)); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		name = stamp + "-" + strconv.Itoa(i) + backupExt
+	}
+
+	path := filepath.Join(m.Config.BackupDir, name)
+	if err := m.store.Snapshot(path); err != nil {
+		return model.Backup{}, err
+	}
+	backup := model.Backup{Name: name, Created: time.Now()}
+	if info, err := os.Stat(path); err == nil {
+		backup.Size = info.Size()
+	}
+	m.Logger.Info(ctx, "Database snapshot written", log.Fields{"path": path, "size": backup.Size})
+
+	if err := m.backupRotate(); err != nil {
+		return backup, err
+	}
+	return backup, nil
+}
+
+// Backups returns the snapshots in the backup directory, newest first
+func (m *DataManager) Backups() ([]model.Backup, error) {
+	entries, err := os.ReadDir(m.Config.BackupDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		m.Logger.Error(context.Background(), "Failed to read backup directory", log.Fields{"error": err, "dir": m.Config.BackupDir})
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []model.Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, model.Backup{Name: name, Size: info.Size(), Created: info.ModTime()})
+	}
+	slices.SortFunc(backups, func(a, b model.Backup) int {
+		return cmp.Or(b.Created.Compare(a.Created), strings.Compare(b.Name, a.Name))
+	})
+	return backups, nil
+}
+
+// backupRotate deletes the oldest snapshots beyond the configured retention, a retention below 1 keeping them all
+func (m *DataManager) backupRotate() error {
+	ctx := context.Background()
+	if m.Config.BackupRetention < 1 {
+		return nil
+	}
+
+	backups, err := m.Backups()
+	if err != nil || len(backups) <= m.Config.BackupRetention {
+		return err
+	}
+	for _, backup := range backups[m.Config.BackupRetention:] {
+		path := filepath.Join(m.Config.BackupDir, backup.Name)
+		if err := os.Remove(path); err != nil {
+			m.Logger.Error(ctx, "Failed to delete old snapshot", log.Fields{"error": err, "path": path})
+			return fmt.Errorf("failed to delete old snapshot %s: %w", backup.Name, err)
+		}
+		m.Logger.Info(ctx, "Old snapshot deleted", log.Fields{"path": path})
+	}
+	return nil
+}
+
+// backupPath returns the path of a snapshot of the backup directory given by its name, with or without its
+// extension, or latest for the newest one
+func (m *DataManager) backupPath(name string) (string, error) {
+	backups, err := m.Backups()
+	if err != nil {
+		return "", err
+	}
+	for _, backup := range backups {
+		if name == "latest" || backup.Name == name || backup.Name == name+backupExt {
+			return filepath.Join(m.Config.BackupDir, backup.Name), nil
+		}
+	}
+	if name == "latest" {
+		return "", errors.New("there are no snapshots in the backup directory")
+	}
+	return "", fmt.Errorf("snapshot not found: %s", name)
+}
+
+// MindmapRestore replaces a mindmap of user with its copy in a snapshot, with its nodes, links and attachments, or
+// adds it back if it was deleted since. The snapshot is opened read-only and left as it is. Returns the restored
+// mindmap and warnings about the links and attachments that could not be restored.
+func (m *DataManager) MindmapRestore(user *model.User, snapshot, name string) (*model.Mindmap, []string, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Restoring mindmap", log.Fields{"user": user.Username, "snapshot": snapshot, "mindmapName": name})
+
+	path, err := m.backupPath(snapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := *m.Config
+	config.DatabaseDir, config.DatabaseFile = filepath.Dir(path), filepath.Base(path)
+	config.ReadOnly = true
+	store, err := storage.NewStorage(&config, m.Logger)
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to open snapshot", log.Fields{"error": err, "path": path})
+		return nil, nil, fmt.Errorf("failed to open snapshot %s: %w", filepath.Base(path), err)
+	}
+	defer store.Close()
+	backup, err := NewDataManager(store, &config, m.Logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open snapshot %s: %w", filepath.Base(path), err)
+	}
+	defer backup.Close()
+
+	users, err := backup.UserManager.UserGet(model.UserInfo{Username: user.Username}, model.UserFilter{Username: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user from snapshot: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, nil, fmt.Errorf("snapshot %s has no user %s", filepath.Base(path), user.Username)
+	}
+	mindmaps, err := backup.MindmapManager.MindmapGet(users[0], model.MindmapInfo{Name: name, Owner: user.Username}, model.MindmapFilter{Name: true, Owner: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get mindmap from snapshot: %w", err)
+	}
+	if len(mindmaps) == 0 {
+		return nil, nil, fmt.Errorf("snapshot %s has no mindmap %s of yours", filepath.Base(path), name)
+	}
+
+	// The mindmap is restored as exported from the snapshot and imported again, replacing the current one
+	mindmap := mindmaps[0]
+	if err := backup.NodeManager.loadNodes(mindmap); err != nil {
+		m.Logger.Error(ctx, "Failed to load mindmap from snapshot", log.Fields{"error": err, "path": path})
+		return nil, nil, fmt.Errorf("failed to load mindmap %s from snapshot: %w", name, err)
+	}
+	restored, err := backup.ExportedMindmap(users[0], mindmap, model.ExportOptions{Format: "json"})
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.MindmapImport(user, model.ImportOptions{File: &model.ImportFile{Mindmap: restored, Format: "json"}})
+}
@@ -0,0 +1,165 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains operations related to the calendar feeds of users.
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// calendarTokenBytes is the number of random bytes of the token of a calendar feed
+const calendarTokenBytes = 24
+
+// CalendarManager handles the calendar feeds of users, served by the web adapter.
+type CalendarManager struct {
+	calendarStore storage.CalendarStore
+	logger        *log.Logger
+}
+
+// NewCalendarManager creates a new CalendarManager instance.
+func NewCalendarManager(calendarStore storage.CalendarStore, logger *log.Logger) (*CalendarManager, error) {
+	ctx := context.Background()
+	logger.Info(ctx, "Creating new CalendarManager", nil)
+
+	if calendarStore == nil {
+		logger.Error(ctx, "CalendarStore not initialized", nil)
+		return nil, fmt.Errorf("calendarStore not initialized")
+	}
+
+	cm := &CalendarManager{
+		calendarStore: calendarStore,
+		logger:        logger,
+	}
+
+	logger.Info(ctx, "CalendarManager created successfully", nil)
+	return cm, nil
+}
+
+// handleUserDeleted removes the calendar feeds of a deleted user
+func (cm *CalendarManager) handleUserDeleted(e event.Event) {
+	ctx := context.Background()
+	cm.logger.Info(ctx, "Handling UserDeleted event", nil)
+
+	user, ok := e.Data.(*model.User)
+	if !ok {
+		cm.logger.Error(ctx, "Invalid event data for user delete event", nil)
+		return
+	}
+	if err := cm.calendarStore.CalendarDeleteUser(user.Username); err != nil {
+		cm.logger.Error(ctx, "Failed to delete calendar feeds of deleted user", log.Fields{"error": err, "username": user.Username})
+	}
+}
+
+// handleMindmapDeleted removes the calendar feed of a deleted mindmap
+func (cm *CalendarManager) handleMindmapDeleted(e event.Event) {
+	ctx := context.Background()
+	cm.logger.Info(ctx, "Handling MindmapDeleted event", nil)
+
+	mindmap, ok := e.Data.(*model.Mindmap)
+	if !ok {
+		cm.logger.Error(ctx, "Invalid event data for mindmap delete event", nil)
+		return
+	}
+	if err := cm.calendarStore.CalendarDeleteMindmap(mindmap.ID); err != nil {
+		cm.logger.Error(ctx, "Failed to delete calendar feed of deleted mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+	}
+}
+
+// CalendarSet adds the calendar feed of a user for a mindmap, or for all own mindmaps if mindmapID is 0. An
+// existing feed gets a new token, so the calendars subscribed to the old one stop receiving it.
+func (cm *CalendarManager) CalendarSet(username string, mindmapID int) (*model.CalendarFeed, error) {
+	ctx := context.Background()
+	cm.logger.Info(ctx, "Setting calendar feed", log.Fields{"username": username, "mindmapID": mindmapID})
+
+	token := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		cm.logger.Error(ctx, "Failed to generate calendar feed token", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to generate calendar feed token: %w", err)
+	}
+	feed := &model.CalendarFeed{Token: hex.EncodeToString(token), Username: username, MindmapID: mindmapID, Created: time.Now()}
+
+	if err := cm.calendarStore.CalendarSet(*feed); err != nil {
+		cm.logger.Error(ctx, "Failed to set calendar feed", log.Fields{"error": err, "username": username})
+		return nil, err
+	}
+	return feed, nil
+}
+
+// CalendarGet returns the calendar feeds of a user
+func (cm *CalendarManager) CalendarGet(username string) ([]*model.CalendarFeed, error) {
+	ctx := context.Background()
+	cm.logger.Debug(ctx, "Retrieving calendar feeds", log.Fields{"username": username})
+
+	feeds, err := cm.calendarStore.CalendarGet(username)
+	if err != nil {
+		cm.logger.Error(ctx, "Failed to get calendar feeds", log.Fields{"error": err, "username": username})
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// CalendarFind returns the calendar feed of a token, nil if there is none
+func (cm *CalendarManager) CalendarFind(token string) (*model.CalendarFeed, error) {
+	feed, err := cm.calendarStore.CalendarFind(token)
+	if err != nil {
+		cm.logger.Error(context.Background(), "Failed to find calendar feed", log.Fields{"error": err})
+		return nil, err
+	}
+	return feed, nil
+}
+
+// CalendarDelete removes the calendar feed of a user for a mindmap, or for all own mindmaps if mindmapID is 0,
+// reporting whether there was one
+func (cm *CalendarManager) CalendarDelete(username string, mindmapID int) (bool, error) {
+	ctx := context.Background()
+	cm.logger.Info(ctx, "Deleting calendar feed", log.Fields{"username": username, "mindmapID": mindmapID})
+
+	deleted, err := cm.calendarStore.CalendarDelete(username, mindmapID)
+	if err != nil {
+		cm.logger.Error(ctx, "Failed to delete calendar feed", log.Fields{"error": err, "username": username})
+		return false, err
+	}
+	return deleted, nil
+}
+
+// CalendarReminders returns the name of a calendar feed and the reminders of its nodes, those of the own mindmaps
+// of its user or of its mindmap, in order of their time
+func (m *DataManager) CalendarReminders(feed *model.CalendarFeed) (string, []*model.Reminder, error) {
+	name := feed.Username
+	if feed.MindmapID != 0 {
+		user := &model.User{Username: feed.Username}
+		mindmaps, err := m.MindmapManager.MindmapGet(user, model.MindmapInfo{ID: feed.MindmapID}, model.MindmapFilter{ID: true})
+		if err != nil {
+			return "", nil, err
+		}
+		if len(mindmaps) == 0 || mindmaps[0].Owner != feed.Username {
+			return "", nil, fmt.Errorf("mindmap of calendar feed not found: %d", feed.MindmapID)
+		}
+		name = mindmaps[0].Name
+	}
+
+	reminders, err := m.store.ReminderStore.ReminderGet()
+	if err != nil {
+		m.Logger.Error(context.Background(), "Failed to get reminders", log.Fields{"error": err})
+		return "", nil, err
+	}
+	var feedReminders []*model.Reminder
+	for _, reminder := range reminders {
+		if reminder.Owner == feed.Username && (feed.MindmapID == 0 || reminder.MindmapID == feed.MindmapID) {
+			feedReminders = append(feedReminders, reminder)
+		}
+	}
+	slices.SortStableFunc(feedReminders, func(a, b *model.Reminder) int {
+		return a.At.Compare(b.At)
+	})
+	return name, feedReminders, nil
+}
@@ -0,0 +1,180 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains operations related to the email capture accounts of users.
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// CaptureOperations defines the interface for email capture account operations
+type CaptureOperations interface {
+	CaptureSet(account model.CaptureAccount) error
+	CaptureGet(username string) ([]*model.CaptureAccount, error)
+	CaptureDelete(username string) error
+}
+
+// CaptureManager handles the mailboxes polled for users by the email capture service.
+type CaptureManager struct {
+	captureStore storage.CaptureStore
+	logger       *log.Logger
+}
+
+// NewCaptureManager creates a new CaptureManager instance.
+func NewCaptureManager(captureStore storage.CaptureStore, logger *log.Logger) (*CaptureManager, error) {
+	ctx := context.Background()
+	logger.Info(ctx, "Creating new CaptureManager", nil)
+
+	if captureStore == nil {
+		logger.Error(ctx, "CaptureStore not initialized", nil)
+		return nil, fmt.Errorf("captureStore not initialized")
+	}
+
+	cm := &CaptureManager{
+		captureStore: captureStore,
+		logger:       logger,
+	}
+
+	logger.Info(ctx, "CaptureManager created successfully", nil)
+	return cm, nil
+}
+
+// handleUserDeleted removes the capture account of a deleted user
+func (cm *CaptureManager) handleUserDeleted(e event.Event) {
+	ctx := context.Background()
+	cm.logger.Info(ctx, "Handling UserDeleted event", nil)
+
+	user, ok := e.Data.(*model.User)
+	if !ok {
+		cm.logger.Error(ctx, "Invalid event data for user delete event", nil)
+		return
+	}
+	if err := cm.captureStore.CaptureDelete(user.Username); err != nil {
+		cm.logger.Error(ctx, "Failed to delete capture account of deleted user", log.Fields{"error": err, "username": user.Username})
+	}
+}
+
+// handleMindmapDeleted removes the capture accounts capturing into a deleted mindmap
+func (cm *CaptureManager) handleMindmapDeleted(e event.Event) {
+	ctx := context.Background()
+	cm.logger.Info(ctx, "Handling MindmapDeleted event", nil)
+
+	mindmap, ok := e.Data.(*model.Mindmap)
+	if !ok {
+		cm.logger.Error(ctx, "Invalid event data for mindmap delete event", nil)
+		return
+	}
+	if err := cm.captureStore.CaptureDeleteMindmap(mindmap.ID); err != nil {
+		cm.logger.Error(ctx, "Failed to delete capture accounts of deleted mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+	}
+}
+
+// CaptureSet sets the capture account of a user, replacing an existing one. A changed server, login or mailbox
+// starts capturing anew, from the emails not yet seen.
+func (cm *CaptureManager) CaptureSet(account model.CaptureAccount) error {
+	ctx := context.Background()
+	cm.logger.Info(ctx, "Setting capture account", log.Fields{"username": account.Username, "server": account.Server, "mailbox": account.Mailbox})
+
+	existing, err := cm.captureStore.CaptureGet(account.Username)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		old := existing[0]
+		if old.Server == account.Server && old.Login == account.Login && old.Mailbox == account.Mailbox {
+			account.UIDValidity, account.LastUID = old.UIDValidity, old.LastUID
+		}
+		account.Captured, account.LastPoll, account.LastError = old.Captured, old.LastPoll, old.LastError
+	}
+
+	if err := cm.captureStore.CaptureSet(account); err != nil {
+		cm.logger.Error(ctx, "Failed to set capture account", log.Fields{"error": err, "username": account.Username})
+		return err
+	}
+	return nil
+}
+
+// CaptureGet returns the capture account of a user, or those of all users if username is empty
+func (cm *CaptureManager) CaptureGet(username string) ([]*model.CaptureAccount, error) {
+	ctx := context.Background()
+	cm.logger.Debug(ctx, "Retrieving capture accounts", log.Fields{"username": username})
+
+	accounts, err := cm.captureStore.CaptureGet(username)
+	if err != nil {
+		cm.logger.Error(ctx, "Failed to get capture accounts", log.Fields{"error": err, "username": username})
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// CaptureDelete removes the capture account of a user, which stops capturing emails for the user
+func (cm *CaptureManager) CaptureDelete(username string) error {
+	ctx := context.Background()
+	cm.logger.Info(ctx, "Deleting capture account", log.Fields{"username": username})
+
+	if err := cm.captureStore.CaptureDelete(username); err != nil {
+		cm.logger.Error(ctx, "Failed to delete capture account", log.Fields{"error": err, "username": username})
+		return err
+	}
+	return nil
+}
+
+// CaptureFailed records a failed poll of a capture account
+func (cm *CaptureManager) CaptureFailed(account *model.CaptureAccount, pollErr error) error {
+	ctx := context.Background()
+	cm.logger.Warn(ctx, "Email capture failed", log.Fields{"error": pollErr, "username": account.Username, "server": account.Server})
+
+	account.LastPoll = time.Now()
+	account.LastError = pollErr.Error()
+	return cm.captureStore.CaptureUpdate(*account)
+}
+
+// CaptureAdd adds captured emails as nodes under the Inbox node of the loaded target mindmap of a capture account,
+// and records the UID validity of the mailbox, the last captured UID and the successful poll in the account.
+// The changes are made in one transaction, so an email is either captured and recorded or neither.
+func (m *DataManager) CaptureAdd(account *model.CaptureAccount, validity uint32, mindmap *model.Mindmap, items []model.CaptureItem) ([]int, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Adding captured emails", log.Fields{"username": account.Username, "mindmapID": account.MindmapID, "count": len(items)})
+
+	// The account is only updated once the transaction succeeded
+	updated := *account
+	updated.LastPoll = time.Now()
+	updated.LastError = ""
+	if validity != updated.UIDValidity {
+		updated.UIDValidity, updated.LastUID = validity, 0
+	}
+
+	var ids []int
+	err := m.NodeBatch(mindmap, func() error {
+		if len(items) > 0 {
+			inbox, err := m.inboxNode(mindmap)
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				id, _, err := m.NodeManager.NodeAdd(mindmap, model.NodeInfo{ParentID: inbox.ID, Name: item.Name, Content: item.Content})
+				if err != nil {
+					return fmt.Errorf("failed to add node for email %d: %w", item.UID, err)
+				}
+				ids = append(ids, id)
+				updated.LastUID = max(updated.LastUID, item.UID)
+				updated.Captured++
+			}
+		}
+		return m.store.CaptureUpdate(updated)
+	})
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to add captured emails", log.Fields{"error": err, "username": account.Username})
+		return nil, fmt.Errorf("failed to add captured emails: %w", err)
+	}
+	*account = updated
+
+	m.Logger.Info(ctx, "Captured emails added", log.Fields{"username": account.Username, "nodes": len(ids)})
+	return ids, nil
+}
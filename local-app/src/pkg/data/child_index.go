@@ -0,0 +1,106 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the cached highest child indexes and name counts of the nodes of mindmaps.
+package data
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// childIndexes caches the stored nodes of a mindmap that new nodes are numbered and counted against, so that they
+// don't depend on the nodes loaded, which may be only a few levels of the mindmap
+type childIndexes struct {
+	highest map[int]int    // Highest last index part among the children, by parent node ID
+	names   map[string]int // Number of nodes, by node name
+}
+
+// childIndex returns the cached child indexes of a mindmap, creating them if there are none. The caller holds
+// childIndexMu.
+func (nm *NodeManager) childIndex(mindmap *model.Mindmap) *childIndexes {
+	indexes := nm.childIndexes[mindmap.ID]
+	if indexes == nil {
+		indexes = &childIndexes{highest: make(map[int]int), names: make(map[string]int)}
+		nm.childIndexes[mindmap.ID] = indexes
+	}
+	return indexes
+}
+
+// nextChildIndex returns the last index part of a new child of a parent node, after the highest among its
+// children. The highest index of a parent is queried from storage on its first new child and cached after that.
+func (nm *NodeManager) nextChildIndex(mindmap *model.Mindmap, parentID int) (int, error) {
+	nm.childIndexMu.Lock()
+	highest, ok := nm.childIndex(mindmap).highest[parentID]
+	nm.childIndexMu.Unlock()
+	if ok {
+		return highest + 1, nil
+	}
+
+	siblings, err := nm.nodeStore.NodeGet(mindmap, model.NodeInfo{ParentID: parentID}, model.NodeFilter{ParentID: true})
+	if err != nil {
+		nm.logger.Error(context.Background(), "Failed to get sibling nodes", log.Fields{"error": err, "parentID": parentID})
+		return 0, fmt.Errorf("failed to get sibling nodes: %w", err)
+	}
+	for _, sibling := range siblings {
+		parts := strings.Split(sibling.Index, ".")
+		if lastPart, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			highest = max(highest, lastPart)
+		}
+	}
+	return highest + 1, nil
+}
+
+// nodeCopies returns the number of stored nodes of a mindmap with the given name. It is queried from storage on
+// the first new node of the name and cached after that.
+func (nm *NodeManager) nodeCopies(mindmap *model.Mindmap, name string) (int, error) {
+	nm.childIndexMu.Lock()
+	copies, ok := nm.childIndex(mindmap).names[name]
+	nm.childIndexMu.Unlock()
+	if ok {
+		return copies, nil
+	}
+
+	nodes, err := nm.nodeStore.NodeGet(mindmap, model.NodeInfo{Name: name}, model.NodeFilter{Name: true})
+	if err != nil {
+		nm.logger.Error(context.Background(), "Failed to get nodes of the same name", log.Fields{"error": err, "name": name})
+		return 0, fmt.Errorf("failed to get nodes of the same name: %w", err)
+	}
+	return len(nodes), nil
+}
+
+// childIndexAdd records a node added under a parent node, its last index part as the highest of the parent and
+// copies as the number of nodes of its name. A root node has no parent, parentID -1.
+func (nm *NodeManager) childIndexAdd(mindmap *model.Mindmap, parentID int, index int, name string, copies int) {
+	nm.childIndexMu.Lock()
+	defer nm.childIndexMu.Unlock()
+	indexes := nm.childIndex(mindmap)
+	if parentID != -1 {
+		indexes.highest[parentID] = index
+	}
+	indexes.names[name] = copies
+}
+
+// childIndexRename moves a renamed node from the count of its old name to that of the new one, for the names
+// whose counts are cached
+func (nm *NodeManager) childIndexRename(mindmap *model.Mindmap, oldName, newName string) {
+	nm.childIndexMu.Lock()
+	defer nm.childIndexMu.Unlock()
+	names := nm.childIndex(mindmap).names
+	if copies, ok := names[oldName]; ok {
+		names[oldName] = max(copies-1, 0)
+	}
+	if copies, ok := names[newName]; ok {
+		names[newName] = copies + 1
+	}
+}
+
+// childIndexReset drops the cached child indexes of a mindmap whose nodes were renumbered, reloaded or deleted
+func (nm *NodeManager) childIndexReset(mindmap *model.Mindmap) {
+	nm.childIndexMu.Lock()
+	defer nm.childIndexMu.Unlock()
+	delete(nm.childIndexes, mindmap.ID)
+}
@@ -0,0 +1,81 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the day nodes of the daily journal mindmaps.
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+// JournalDateLayout is the layout of the names of the day nodes of a daily journal
+const JournalDateLayout = "2006-01-02"
+
+// JournalDay returns the node of a day in a daily journal mindmap, under its top-level node named branch or under
+// the root if branch is empty. The branch node and the day node, with the template applied, are added if missing
+// in one transaction. created reports whether the day node was added.
+func (m *DataManager) JournalDay(mindmap *model.Mindmap, branch string, day time.Time, template model.JournalTemplate) (*model.Node, bool, error) {
+	ctx := context.Background()
+	date := day.Format(JournalDateLayout)
+
+	parent := mindmap.Root
+	if branch != "" {
+		parent = childNode(mindmap.Root, branch)
+	}
+	if parent != nil {
+		if node := childNode(parent, date); node != nil {
+			return node, false, nil
+		}
+	}
+
+	m.Logger.Info(ctx, "Adding journal day node", log.Fields{"mindmapID": mindmap.ID, "date": date})
+	expand := strings.NewReplacer("{date}", date, "{weekday}", day.Weekday().String()).Replace
+
+	var node *model.Node
+	err := m.NodeBatch(mindmap, func() error {
+		if parent == nil {
+			id, _, err := m.NodeManager.NodeAdd(mindmap, model.NodeInfo{ParentID: mindmap.Root.ID, Name: branch})
+			if err != nil {
+				return fmt.Errorf("failed to add journal branch node: %w", err)
+			}
+			parent = mindmap.Nodes[id]
+		}
+
+		content := make(map[string]string, len(template.Fields))
+		for key, value := range template.Fields {
+			content[key] = expand(value)
+		}
+		id, _, err := m.NodeManager.NodeAdd(mindmap, model.NodeInfo{ParentID: parent.ID, Name: date, Content: content})
+		if err != nil {
+			return fmt.Errorf("failed to add journal day node: %w", err)
+		}
+		node = mindmap.Nodes[id]
+
+		for _, child := range template.Children {
+			if _, _, err := m.NodeManager.NodeAdd(mindmap, model.NodeInfo{ParentID: node.ID, Name: expand(child)}); err != nil {
+				return fmt.Errorf("failed to add journal template node: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to add journal day node", log.Fields{"error": err, "mindmapID": mindmap.ID, "date": date})
+		return nil, false, err
+	}
+	return node, true, nil
+}
+
+// childNode returns the first child of a node with the given name, or nil if it has none
+func childNode(parent *model.Node, name string) *model.Node {
+	for _, child := range parent.Children {
+		if names.Equal(child.Name, name, false) {
+			return child
+		}
+	}
+	return nil
+}
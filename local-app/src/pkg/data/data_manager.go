@@ -4,22 +4,36 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"mindnoscape/local-app/src/pkg/event"
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
 	"mindnoscape/local-app/src/pkg/storage"
 )
 
 // DataManager is the main struct that coordinates all data operations
 type DataManager struct {
-	UserManager    *UserManager
-	MindmapManager *MindmapManager
-	NodeManager    *NodeManager
-	EventManager   *event.EventManager
-	Config         *model.Config
-	Logger         *log.Logger
+	UserManager      *UserManager
+	MindmapManager   *MindmapManager
+	NodeManager      *NodeManager
+	AuditManager     *AuditManager
+	TelemetryManager *TelemetryManager
+	LinkManager      *LinkManager
+	CaptureManager   *CaptureManager
+	CalendarManager  *CalendarManager
+	VersionManager   *VersionManager
+	EventManager     *event.EventManager
+	Config           *model.Config
+	Logger           *log.Logger
+
+	store *storage.Storage
 }
 
 // NewDataManager creates a new Manager instance
@@ -32,6 +46,7 @@ func NewDataManager(store *storage.Storage, cfg *model.Config, logger *log.Logge
 		EventManager: eventManager,
 		Config:       cfg,
 		Logger:       logger,
+		store:        store,
 	}
 
 	// Initialize UserManager
@@ -50,14 +65,57 @@ func NewDataManager(store *storage.Storage, cfg *model.Config, logger *log.Logge
 	}
 
 	// Initialize NodeManager
-	m.NodeManager, err = NewNodeManager(store.NodeStore, eventManager, logger)
+	m.NodeManager, err = NewNodeManager(store.NodeStore, eventManager, cfg.LargeOpThreshold, cfg.ReindexThreshold, logger)
 	if err != nil {
 		logger.Error(ctx, "Failed to create NodeManager", log.Fields{"error": err})
 		return nil, fmt.Errorf("failed to create NodeManager: %w", err)
 	}
+	m.MindmapManager.nodeStats = m.NodeManager.MindmapStats
 
-	// Handle default user logic
-	if cfg.DefaultUserActive {
+	// Initialize AuditManager
+	m.AuditManager, err = NewAuditManager(store.AuditStore, logger)
+	if err != nil {
+		logger.Error(ctx, "Failed to create AuditManager", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to create AuditManager: %w", err)
+	}
+
+	// Initialize TelemetryManager
+	m.TelemetryManager, err = NewTelemetryManager(store.UsageStore, logger)
+	if err != nil {
+		logger.Error(ctx, "Failed to create TelemetryManager", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to create TelemetryManager: %w", err)
+	}
+
+	// Initialize LinkManager
+	m.LinkManager, err = NewLinkManager(store.LinkStore, logger)
+	if err != nil {
+		logger.Error(ctx, "Failed to create LinkManager", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to create LinkManager: %w", err)
+	}
+
+	// Initialize CaptureManager
+	m.CaptureManager, err = NewCaptureManager(store.CaptureStore, logger)
+	if err != nil {
+		logger.Error(ctx, "Failed to create CaptureManager", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to create CaptureManager: %w", err)
+	}
+
+	// Initialize CalendarManager
+	m.CalendarManager, err = NewCalendarManager(store.CalendarStore, logger)
+	if err != nil {
+		logger.Error(ctx, "Failed to create CalendarManager", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to create CalendarManager: %w", err)
+	}
+
+	// Initialize VersionManager
+	m.VersionManager, err = NewVersionManager(store.VersionStore, cfg.VersionInterval, cfg.VersionRetention, logger)
+	if err != nil {
+		logger.Error(ctx, "Failed to create VersionManager", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to create VersionManager: %w", err)
+	}
+
+	// Handle default user logic, a read-only database is used with the users it has
+	if cfg.DefaultUserActive && !cfg.ReadOnly {
 		logger.Debug(ctx, "Handling default user logic", nil)
 		defaultUserInfo := model.UserInfo{Username: cfg.DefaultUser}
 		exists, err := m.UserManager.UserGet(defaultUserInfo, model.UserFilter{Username: true})
@@ -80,12 +138,18 @@ func NewDataManager(store *storage.Storage, cfg *model.Config, logger *log.Logge
 
 	// Subscribe MindmapManager to UserDeleted events
 	eventManager.Subscribe(event.UserDeleted, m.MindmapManager.handleUserDeleted)
+	eventManager.Subscribe(event.UserDeleted, m.CaptureManager.handleUserDeleted)
+	eventManager.Subscribe(event.UserDeleted, m.CalendarManager.handleUserDeleted)
 
 	// Subscribe NodeManager to MindmapCreated events
 	eventManager.Subscribe(event.MindmapAdded, m.NodeManager.handleMindmapAdded)
 
 	// Subscribe to MindmapDeleted events
 	eventManager.Subscribe(event.MindmapDeleted, m.NodeManager.handleMindmapDeleted)
+	eventManager.Subscribe(event.MindmapDeleted, m.LinkManager.handleMindmapDeleted)
+	eventManager.Subscribe(event.MindmapDeleted, m.CaptureManager.handleMindmapDeleted)
+	eventManager.Subscribe(event.MindmapDeleted, m.CalendarManager.handleMindmapDeleted)
+	eventManager.Subscribe(event.MindmapDeleted, m.VersionManager.handleMindmapDeleted)
 
 	// Subscribe to MindmapUpdated events
 	eventManager.Subscribe(event.MindmapUpdated, m.NodeManager.handleMindmapUpdated)
@@ -96,44 +160,244 @@ func NewDataManager(store *storage.Storage, cfg *model.Config, logger *log.Logge
 	return m, nil
 }
 
-// MindmapExport exports a mindmap to a file in the specified format.
-func (m *DataManager) MindmapExport(user *model.User, mindmap *model.Mindmap, filename, format string) error {
+// Close stops event delivery after the already published events have been handled
+func (m *DataManager) Close() {
+	m.Logger.Info(context.Background(), "Closing DataManager", nil)
+	m.EventManager.Close()
+}
+
+// DatabaseCheck checks the integrity of the database, returning the problems found
+func (m *DataManager) DatabaseCheck() ([]string, error) {
+	return m.store.IntegrityCheck()
+}
+
+// DatabaseVacuum compacts the database, returning the bytes reclaimed
+func (m *DataManager) DatabaseVacuum() (int64, error) {
+	return m.store.Vacuum()
+}
+
+// DatabaseOrphans counts the links and attachments left without their node or mindmap, removing them if remove is
+// set, see storage.Storage.Orphans
+func (m *DataManager) DatabaseOrphans(remove bool) (model.Orphans, error) {
+	return m.store.Orphans(remove)
+}
+
+// DatabaseSchemaVersion returns the schema version of the database, see storage.Storage.StoredSchemaVersion
+func (m *DataManager) DatabaseSchemaVersion() int {
+	return m.store.StoredSchemaVersion()
+}
+
+// NodeBatch runs fn, a series of node operations on mindmap, in a single storage transaction recorded as a change
+// of the mindmap, see MindmapManager.MindmapRevise. If fn fails, the stored changes are rolled back and the nodes of
+// mindmap are reloaded to discard the in-memory changes.
+func (m *DataManager) NodeBatch(mindmap *model.Mindmap, fn func() error) error {
+	ctx := context.Background()
+
+	err := m.store.Batch(func() error {
+		if err := fn(); err != nil {
+			return err
+		}
+		return m.MindmapManager.MindmapRevise(mindmap)
+	})
+	if err == nil {
+		return nil
+	}
+
+	m.Logger.Warn(ctx, "Node batch rolled back", log.Fields{"error": err, "mindmapID": mindmap.ID})
+	if reloadErr := m.NodeManager.loadNodes(mindmap); reloadErr != nil {
+		m.Logger.Error(ctx, "Failed to reload nodes after rollback", log.Fields{"error": reloadErr, "mindmapID": mindmap.ID})
+		return fmt.Errorf("%w (reloading nodes failed: %v)", err, reloadErr)
+	}
+	return err
+}
+
+// IsExportFormat reports whether format is a supported export format
+func IsExportFormat(format string) bool {
+	return storage.IsExportFormat(format)
+}
+
+// IsTextFormat reports whether format is an export format whose data is text
+func IsTextFormat(format string) bool {
+	return storage.IsTextFormat(format)
+}
+
+// IsImportFormat reports whether format is a supported import format
+func IsImportFormat(format string) bool {
+	return storage.IsImportFormat(format)
+}
+
+// IsSubtreeFormat reports whether format is an export format that can export the subtree of a node
+func IsSubtreeFormat(format string) bool {
+	return storage.IsSubtreeFormat(format)
+}
+
+// ExportFormats returns the registered export formats sorted by name
+func ExportFormats() []model.ExportFormat {
+	return storage.ExportFormats()
+}
+
+// ImportFormats returns the registered import formats sorted by name
+func ImportFormats() []model.ImportFormat {
+	return storage.ImportFormats()
+}
+
+// IsEncryptedPath reports whether the file name suffix of a path marks an encrypted export file
+func IsEncryptedPath(path string) bool {
+	return storage.IsEncryptedPath(path)
+}
+
+// IsNativeFormat reports whether format is a native format, which can be both exported and imported
+func IsNativeFormat(format string) bool {
+	return storage.IsNativeFormat(format)
+}
+
+// TODO: files hold a single mindmap, there is no workspace archive yet. Once there is, it should take flags to
+// include or exclude the per-user preferences, templates, saved searches and bookmarks, which do not exist yet
+// either; today the preferences are the global configuration file and the journal template is part of it.
+
+// MindmapExport exports a mindmap to a file with the given options and returns the path of the written file.
+// An existing file is only overwritten if forced.
+func (m *DataManager) MindmapExport(user *model.User, mindmap *model.Mindmap, options model.ExportOptions) (string, error) {
+	exported, err := m.ExportedMindmap(user, mindmap, options)
+	if err != nil {
+		return "", err
+	}
+	return m.MindmapExportFile(user, mindmap, exported, options)
+}
+
+// MindmapExportFile writes exported, the mindmap as ExportedMindmap returns it, to a file like MindmapExport. It
+// reads nothing from storage, so it can run alongside the command executor.
+func (m *DataManager) MindmapExportFile(user *model.User, mindmap *model.Mindmap, exported *model.Mindmap, options model.ExportOptions) (string, error) {
 	ctx := context.Background()
-	m.Logger.Info(ctx, "Exporting mindmap", log.Fields{"user": user.Username, "mindmapID": mindmap.ID, "filename": filename, "format": format})
+	m.Logger.Info(ctx, "Exporting mindmap", log.Fields{"user": user.Username, "mindmapID": mindmap.ID, "options": options})
 
-	err := storage.FileExport(mindmap, filename, format, m.Logger)
+	path, err := m.ExportPath(mindmap, options.Filename, options.Format)
+	if err != nil {
+		m.Logger.Error(ctx, "Invalid export path", log.Fields{"error": err, "filename": options.Filename})
+		return "", err
+	}
+
+	// The compression and encryption of a file follow its suffixes, so requested ones are added as suffixes,
+	// the encryption suffix coming last
+	encrypted := options.Encrypt || storage.IsEncryptedPath(path)
+	path = storage.TrimEncryptionExt(path)
+	if options.Compression != model.CompressionNone && storage.CompressionFromPath(path) != options.Compression {
+		path += "." + options.Compression
+	}
+	if encrypted {
+		path += storage.EncryptionExt
+	}
+
+	checksum, err := storage.FileExport(exported, path, options.Format, options.Force, options.Passphrase, m.Config.DisplayStyle(), options.Progress, m.Logger)
 	if err != nil {
 		m.Logger.Error(ctx, "Failed to export mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
-		return fmt.Errorf("failed to export mindmap: %w", err)
+		return "", fmt.Errorf("failed to export mindmap: %w", err)
 	}
 
-	m.Logger.Info(ctx, "Mindmap exported successfully", log.Fields{"mindmapID": mindmap.ID})
-	return nil
+	// Sign the content checksum with the exporting user's key into a detached signature file
+	if options.Sign {
+		if err := storage.SignatureWrite(path, checksum, m.Config.KeyDir, user.Username); err != nil {
+			m.Logger.Error(ctx, "Failed to sign exported mindmap", log.Fields{"error": err, "path": path})
+			return "", fmt.Errorf("failed to sign exported mindmap: %w", err)
+		}
+		m.Logger.Info(ctx, "Exported mindmap signed", log.Fields{"path": storage.SignaturePath(path), "username": user.Username})
+	}
+
+	m.Logger.Info(ctx, "Mindmap exported successfully", log.Fields{"mindmapID": mindmap.ID, "path": path})
+	return path, nil
 }
 
-// MindmapImport imports a mindmap from a file in the specified format.
-func (m *DataManager) MindmapImport(user *model.User, filename, format string) (*model.Mindmap, error) {
+// MindmapEncode encodes a mindmap, or the subtree of options.Node, in the export format of options and returns the
+// data instead of writing it to a file
+func (m *DataManager) MindmapEncode(user *model.User, mindmap *model.Mindmap, options model.ExportOptions) ([]byte, error) {
 	ctx := context.Background()
-	m.Logger.Info(ctx, "Importing mindmap", log.Fields{"user": user.Username, "filename": filename, "format": format})
+	m.Logger.Info(ctx, "Encoding mindmap", log.Fields{"mindmapID": mindmap.ID, "format": options.Format})
 
-	// Import the mindmap
-	importedMindmap, err := storage.FileImport(filename, format, m.Logger)
+	exported, err := m.ExportedMindmap(user, mindmap, options)
 	if err != nil {
-		m.Logger.Error(ctx, "Failed to import mindmap", log.Fields{"error": err, "filename": filename})
-		return nil, fmt.Errorf("failed to import mindmap: %w", err)
+		return nil, err
 	}
 
-	// Validate the imported mindmap structure
-	if err := m.validateMindmap(importedMindmap); err != nil {
-		m.Logger.Error(ctx, "Invalid mindmap structure", log.Fields{"error": err})
-		return nil, fmt.Errorf("invalid mindmap structure: %w", err)
+	data, _, err := storage.ExportEncode(exported, options.Format, m.Config.DisplayStyle(), m.Logger)
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to encode mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to encode mindmap: %w", err)
+	}
+	return data, nil
+}
+
+// ExportedMindmap returns the mindmap as exported with options for user: the subtree of options.Node, as a mindmap
+// of its own rooted and named by its top node, with the computed fields, redacted by the profile of options.Redact
+// and with the links from its nodes and their attachments, read from storage
+func (m *DataManager) ExportedMindmap(user *model.User, mindmap *model.Mindmap, options model.ExportOptions) (*model.Mindmap, error) {
+	exported := mindmap
+	if options.Node != nil && options.Node.ID != mindmap.Root.ID {
+		if !storage.IsSubtreeFormat(options.Format) {
+			return nil, fmt.Errorf("subtrees can't be exported as %s", options.Format)
+		}
+		exported = subtreeMindmap(mindmap, options.Node)
+	}
+	if options.Computed {
+		exported = exported.WithComputedFields(m.Config.DisplayStyle())
+	}
+	if options.Redact != "" {
+		profile, ok := m.Config.RedactionProfiles[options.Redact]
+		if !ok {
+			m.Logger.Warn(context.Background(), "Unknown redaction profile", log.Fields{"profile": options.Redact})
+			return nil, fmt.Errorf("unknown redaction profile: %s", options.Redact)
+		}
+		exported = exported.WithRedaction(profile)
+	}
+
+	links, err := m.exportLinks(user, exported)
+	if err != nil {
+		m.Logger.Error(context.Background(), "Failed to get links of exported mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to get links: %w", err)
+	}
+	attachments, err := m.exportAttachments(mindmap, exported)
+	if err != nil {
+		m.Logger.Error(context.Background(), "Failed to get attachments of exported mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	if len(links) > 0 || len(attachments) > 0 {
+		complete := *exported
+		complete.Links = links
+		complete.Attachments = attachments
+		exported = &complete
+	}
+	return exported, nil
+}
+
+// subtreeMindmap returns a copy of the subtree of a node as a mindmap rooted and named by the node
+func subtreeMindmap(mindmap *model.Mindmap, node *model.Node) *model.Mindmap {
+	clone := mindmap.Clone()
+	subtree := *clone
+	subtree.Name = node.Name
+	subtree.Root = clone.Nodes[node.ID]
+	subtree.Nodes = make(map[int]*model.Node)
+	for n := range clone.Subtree(subtree.Root, nil) {
+		subtree.Nodes[n.ID] = n
+	}
+	return &subtree
+}
+
+// MindmapImport imports a mindmap from a file in the specified format, or the format indicated by its extension if empty.
+// The embedded checksum and a detached signature, if present, are verified before anything is stored; failed
+// verification aborts the import unless forced. Returns the imported mindmap and warnings about its integrity.
+func (m *DataManager) MindmapImport(user *model.User, options model.ImportOptions) (*model.Mindmap, []string, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Importing mindmap", log.Fields{"user": user.Username, "options": options})
+
+	importedMindmap, _, warnings, err := m.importRead(options)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Check if a mindmap with the same name exists for the user
 	existingMindmaps, err := m.MindmapManager.MindmapGet(user, model.MindmapInfo{Name: importedMindmap.Name}, model.MindmapFilter{Name: true})
 	if err != nil {
 		m.Logger.Error(ctx, "Failed to check for existing mindmap", log.Fields{"error": err, "mindmapName": importedMindmap.Name})
-		return nil, fmt.Errorf("failed to check for existing mindmap: %w", err)
+		return nil, nil, fmt.Errorf("failed to check for existing mindmap: %w", err)
 	}
 
 	if len(existingMindmaps) > 0 {
@@ -142,10 +406,372 @@ func (m *DataManager) MindmapImport(user *model.User, filename, format string) (
 		err = m.MindmapManager.MindmapDelete(user, existingMindmaps[0])
 		if err != nil {
 			m.Logger.Error(ctx, "Failed to delete existing mindmap", log.Fields{"error": err, "mindmapName": importedMindmap.Name})
-			return nil, fmt.Errorf("failed to delete existing mindmap: %w", err)
+			return nil, nil, fmt.Errorf("failed to delete existing mindmap: %w", err)
+		}
+	}
+
+	linkWarnings, err := m.importAdd(user, importedMindmap, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings = append(warnings, linkWarnings...)
+
+	m.Logger.Info(ctx, "Mindmap imported successfully", log.Fields{"mindmapID": importedMindmap.ID, "mindmapName": importedMindmap.Name})
+	return importedMindmap, warnings, nil
+}
+
+// MindmapImportMerge imports a mindmap from a file like MindmapImport, but merges it into an existing mindmap of
+// the user with the same name instead of replacing it. Imported nodes are matched to the existing nodes by ID, as
+// stored in the JSON and XML files, and handled by the import policy of options; the other nodes are added under
+// their matched or added parents. A sync also moves the matched nodes to their imported parents and deletes the
+// nodes missing from the file, so that an export edited elsewhere is applied back onto the mindmap. Without an
+// existing mindmap, the imported mindmap is added as a whole. Returns the merged mindmap with its nodes, the counts
+// of the merge and warnings about the integrity of the file.
+func (m *DataManager) MindmapImportMerge(user *model.User, options model.ImportOptions) (*model.Mindmap, model.ImportMerge, []string, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Importing mindmap into existing mindmap", log.Fields{"user": user.Username, "options": options})
+
+	var merge model.ImportMerge
+	importedMindmap, format, warnings, err := m.importRead(options)
+	if err != nil {
+		return nil, merge, nil, err
+	}
+	if !storage.IsNativeFormat(format) {
+		m.Logger.Error(ctx, "Import format has no node IDs to merge by", log.Fields{"format": format})
+		return nil, merge, nil, fmt.Errorf("%s files have no node IDs to merge by, only json and xml files can be merged", format)
+	}
+
+	existingMindmaps, err := m.MindmapManager.MindmapGet(user, model.MindmapInfo{Name: importedMindmap.Name, Owner: user.Username}, model.MindmapFilter{Name: true, Owner: true})
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to check for existing mindmap", log.Fields{"error": err, "mindmapName": importedMindmap.Name})
+		return nil, merge, nil, fmt.Errorf("failed to check for existing mindmap: %w", err)
+	}
+	sync := options.Existing == model.ImportSync
+	if len(existingMindmaps) == 0 {
+		m.Logger.Debug(ctx, "No existing mindmap to merge into, adding it", log.Fields{"mindmapName": importedMindmap.Name})
+		if sync {
+			if err := m.importSyncTree(importedMindmap, 1); err != nil {
+				return nil, merge, nil, err
+			}
+		}
+		linkWarnings, err := m.importAdd(user, importedMindmap, options)
+		if err != nil {
+			return nil, merge, nil, err
 		}
+		merge.Added = len(importedMindmap.Nodes) - 1
+		return importedMindmap, merge, append(warnings, linkWarnings...), nil
 	}
 
+	mindmap := existingMindmaps[0]
+	if err := m.NodeManager.loadNodes(mindmap); err != nil {
+		m.Logger.Error(ctx, "Failed to load existing mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, merge, nil, fmt.Errorf("failed to load mindmap %s: %w", mindmap.Name, err)
+	}
+	if sync {
+		if err := m.importSyncTree(importedMindmap, slices.Max(slices.Collect(maps.Keys(mindmap.Nodes)))+1); err != nil {
+			return nil, merge, nil, err
+		}
+	}
+
+	// Without a policy or a resolver for conflicts, the merge is refused before changing anything
+	update := options.Existing == model.ImportUpdate || sync
+	if update && options.Conflicts == model.ConflictRefuse && options.Resolve == nil {
+		if conflicts := importConflicts(mindmap, importedMindmap, sync); len(conflicts) > 0 {
+			names := make([]string, 0, len(conflicts))
+			for _, conflict := range conflicts {
+				names = append(names, fmt.Sprintf("%s '%s'", conflict.Mine.Index, conflict.Mine.Name))
+			}
+			m.Logger.Warn(ctx, "Import conflicts with changes to the mindmap", log.Fields{"mindmapID": mindmap.ID, "conflicts": len(conflicts)})
+			return nil, merge, nil, fmt.Errorf("%d nodes changed in the mindmap since the file's copy of them, keep mine, theirs or both: %s", len(conflicts), strings.Join(names, ", "))
+		}
+	}
+
+	progress := model.Progress{Operation: "import", Total: len(importedMindmap.Nodes), Started: time.Now()}
+	if options.Progress != nil {
+		options.Progress(progress)
+	}
+
+	// ids maps the IDs of the imported nodes to those of the nodes they were merged into or added as
+	ids := map[int]int{0: 0}
+	// added holds the nodes added by the merge, which a sync keeps although the file has no node of their ID
+	added := make(map[int]bool)
+	err = m.MindmapChange(mindmap, func() error {
+		// The root is the mindmap itself, only its fields are merged
+		if update {
+			updated, err := m.importMergeNode(mindmap, mindmap.Root, importedMindmap.Root, sync)
+			if err != nil {
+				return err
+			}
+			if updated {
+				merge.Updated++
+			}
+		}
+
+		// Nodes are visited parents first, so a node moved by a sync always lands under its already placed parent
+		isChild := func(node *model.Node) bool { return node.ID != 0 }
+		for node := range importedMindmap.Subtree(nil, isChild) {
+			if existing, ok := mindmap.Nodes[node.ID]; ok && options.Existing != model.ImportDuplicate {
+				ids[node.ID] = existing.ID
+				updated := false
+				if update {
+					imported := node
+					if importConflict(existing, node, sync) {
+						merge.Conflicts++
+						resolution, err := m.importResolve(options, model.ImportConflict{Mine: existing, Theirs: node})
+						if err != nil {
+							return err
+						}
+						switch resolution.Policy {
+						case model.ConflictMine:
+							imported = nil
+						case model.ConflictBoth:
+							// The node of the file is added next to the kept one, its children still merged into mine
+							info := model.NodeInfo{ParentID: existing.ParentID, Name: node.Name, Content: node.Content, Tags: node.Tags}
+							id, _, err := m.NodeManager.NodeAdd(mindmap, info)
+							if err != nil {
+								m.Logger.Error(ctx, "Failed to add conflicting imported node", log.Fields{"error": err, "nodeID": node.ID})
+								return fmt.Errorf("failed to add node %s: %w", node.Name, err)
+							}
+							added[id] = true
+							merge.Added++
+							imported = nil
+						case model.ConflictEdit:
+							imported = &model.Node{Name: resolution.Name, Content: resolution.Content}
+						}
+					}
+					if imported != nil {
+						if updated, err = m.importMergeNode(mindmap, existing, imported, sync); err != nil {
+							return err
+						}
+					}
+				}
+				if sync && existing.ParentID != ids[node.ParentID] {
+					if err := m.NodeManager.NodeMove(mindmap, existing, mindmap.Nodes[ids[node.ParentID]], -1); err != nil {
+						m.Logger.Error(ctx, "Failed to move imported node", log.Fields{"error": err, "nodeID": node.ID})
+						return fmt.Errorf("failed to move node %s: %w", node.Name, err)
+					}
+					merge.Moved++
+				}
+				if updated {
+					merge.Updated++
+				} else {
+					merge.Skipped++
+				}
+			} else {
+				info := model.NodeInfo{ParentID: ids[node.ParentID], Name: node.Name, Content: node.Content, Tags: node.Tags}
+				id, _, err := m.NodeManager.NodeAdd(mindmap, info)
+				if err != nil {
+					m.Logger.Error(ctx, "Failed to add imported node", log.Fields{"error": err, "nodeID": node.ID})
+					return fmt.Errorf("failed to add node %s: %w", node.Name, err)
+				}
+				ids[node.ID] = id
+				added[id] = true
+				merge.Added++
+			}
+			progress.Done++
+			if options.Progress != nil {
+				options.Progress(progress)
+			}
+		}
+
+		// A sync deletes the nodes missing from the file, the topmost of each deleted subtree taking the rest along
+		if sync {
+			var missing []*model.Node
+			for node := range mindmap.Subtree(nil, isChild) {
+				if _, ok := importedMindmap.Nodes[node.ID]; !ok && !added[node.ID] {
+					if _, ok := importedMindmap.Nodes[node.ParentID]; ok || node.ParentID == 0 {
+						missing = append(missing, node)
+					}
+				}
+			}
+			for _, node := range missing {
+				merge.Deleted += m.NodeManager.NodeCount(mindmap, node)
+				if err := m.NodeManager.NodeDelete(mindmap, node); err != nil {
+					m.Logger.Error(ctx, "Failed to delete node missing from import", log.Fields{"error": err, "nodeID": node.ID})
+					return fmt.Errorf("failed to delete node %s: %w", node.Name, err)
+				}
+			}
+		}
+
+		// The links of the file are added between the nodes they were merged into, those already present kept
+		linkWarnings, err := m.importLinks(user, mindmap, importedMindmap.Links, ids)
+		warnings = append(warnings, linkWarnings...)
+		if err != nil {
+			return err
+		}
+
+		// So are its attachments, those of the same name a node has already kept
+		attachmentWarnings, err := m.importAttachments(mindmap, importedMindmap.Attachments, ids)
+		warnings = append(warnings, attachmentWarnings...)
+		return err
+	})
+	if err != nil {
+		return nil, model.ImportMerge{}, nil, fmt.Errorf("failed to merge mindmap: %w", err)
+	}
+
+	m.Logger.Info(ctx, "Mindmap merged successfully", log.Fields{"mindmapID": mindmap.ID, "mindmapName": mindmap.Name, "merge": merge})
+	return mindmap, merge, warnings, nil
+}
+
+// importSyncTree makes the nested tree of an imported mindmap, the one edited in a file, its nodes and validates
+// them again. Nodes added in the file get IDs from firstID, so that they are not matched to existing nodes.
+func (m *DataManager) importSyncTree(mindmap *model.Mindmap, firstID int) error {
+	mindmap.NodesFromTree(firstID)
+	mindmap.RemoveComputedFields()
+	if err := m.validateMindmap(mindmap); err != nil {
+		m.Logger.Error(context.Background(), "Invalid mindmap structure", log.Fields{"error": err})
+		return fmt.Errorf("invalid mindmap structure: %w", err)
+	}
+	return nil
+}
+
+// importMergeNode merges the name and fields of an imported node into an existing node of a mindmap, the
+// imported fields replacing those of the same label, or all fields if replace is set. The name of the root, the
+// mindmap name, is kept. Returns whether the existing node changed.
+func (m *DataManager) importMergeNode(mindmap *model.Mindmap, existing, imported *model.Node, replace bool) (bool, error) {
+	update, changed := importMergeInfo(existing, imported, replace)
+	if !changed {
+		return false, nil
+	}
+	if err := m.NodeManager.NodeUpdate(mindmap, existing, update, model.NodeFilter{Name: update.Name != existing.Name, Content: true}); err != nil {
+		return false, fmt.Errorf("failed to update node %s: %w", existing.Name, err)
+	}
+	return true, nil
+}
+
+// importMergeInfo returns the update merging an imported node into an existing one, as importMergeNode does, and
+// whether it changes the existing node
+func importMergeInfo(existing, imported *model.Node, replace bool) (model.NodeInfo, bool) {
+	name := imported.Name
+	if existing.ID == 0 || name == "" {
+		name = existing.Name
+	}
+	content := maps.Clone(existing.Content)
+	if content == nil {
+		content = make(map[string]string)
+	}
+	if replace {
+		// Empty values remove the fields missing from the imported node
+		for label := range content {
+			content[label] = ""
+		}
+	}
+	maps.Copy(content, imported.Content)
+
+	kept := maps.Clone(content)
+	maps.DeleteFunc(kept, func(_, value string) bool { return value == "" })
+	// Storage replaces all fields of the node, so the update carries the kept fields along with the merged ones
+	return model.NodeInfo{Name: name, Content: content}, name != existing.Name || !maps.Equal(kept, existing.Content)
+}
+
+// importConflict reports whether merging an imported node into an existing one conflicts: the existing node
+// changed since the imported copy of it was last changed, such as after an export, and the merge would change it.
+// The root holds the mindmap fields, merged without conflicts.
+func importConflict(existing, imported *model.Node, replace bool) bool {
+	if existing.ID == 0 || imported.Updated.IsZero() || !existing.Updated.After(imported.Updated) {
+		return false
+	}
+	_, changed := importMergeInfo(existing, imported, replace)
+	return changed
+}
+
+// importResolve resolves a conflict of a merge by the policy of the options, or else by asking their resolver
+func (m *DataManager) importResolve(options model.ImportOptions, conflict model.ImportConflict) (model.ConflictResolution, error) {
+	resolution := model.ConflictResolution{Policy: options.Conflicts}
+	if resolution.Policy == model.ConflictRefuse && options.Resolve != nil {
+		var err error
+		if resolution, err = options.Resolve(conflict); err != nil {
+			m.Logger.Warn(context.Background(), "Import conflict not resolved", log.Fields{"error": err, "nodeID": conflict.Mine.ID})
+			return resolution, fmt.Errorf("conflict on node %s not resolved: %w", conflict.Mine.Index, err)
+		}
+	}
+	switch resolution.Policy {
+	case model.ConflictMine, model.ConflictTheirs, model.ConflictBoth, model.ConflictEdit:
+		return resolution, nil
+	default:
+		return resolution, fmt.Errorf("invalid conflict resolution for node %s: %q", conflict.Mine.Index, resolution.Policy)
+	}
+}
+
+// importConflicts returns the conflicts of merging an imported mindmap into an existing one, parents first
+func importConflicts(mindmap, imported *model.Mindmap, replace bool) []model.ImportConflict {
+	var conflicts []model.ImportConflict
+	for node := range imported.Subtree(nil, func(node *model.Node) bool { return node.ID != 0 }) {
+		if existing, ok := mindmap.Nodes[node.ID]; ok && importConflict(existing, node, replace) {
+			conflicts = append(conflicts, model.ImportConflict{Mine: existing, Theirs: node})
+		}
+	}
+	return conflicts
+}
+
+// MindmapImportRead reads and verifies the file of an import without storing anything, so that the import can be
+// done later with the file set in its options. It only uses the files and the configuration, not the loaded
+// mindmaps, so it can run alongside the commands of the sessions.
+func (m *DataManager) MindmapImportRead(options model.ImportOptions) (*model.ImportFile, error) {
+	mindmap, format, warnings, err := m.importRead(options)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ImportFile{Mindmap: mindmap, Format: format, Warnings: warnings}, nil
+}
+
+// importRead reads the mindmap to import from a file, in the specified format or the one of its extension, and
+// verifies its integrity and structure, unless the file was read in advance. Returns the mindmap, its format and
+// warnings about its integrity.
+func (m *DataManager) importRead(options model.ImportOptions) (*model.Mindmap, string, []string, error) {
+	ctx := context.Background()
+	if options.File != nil {
+		return options.File.Mindmap, options.File.Format, options.File.Warnings, nil
+	}
+
+	path, err := m.ImportPath(options.Filename)
+	if err != nil {
+		m.Logger.Error(ctx, "Invalid import path", log.Fields{"error": err, "filename": options.Filename})
+		return nil, "", nil, err
+	}
+
+	// Without an explicit format, the format is recognized by extension, also when compressed, defaulting to JSON
+	format := options.Format
+	if format == "" {
+		format = storage.ImportFormat(path)
+	}
+	if format == "" {
+		format = "json"
+	}
+
+	// Import the mindmap
+	importedMindmap, warnings, err := storage.FileImport(path, format, options.Passphrase, m.Logger)
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to import mindmap", log.Fields{"error": err, "filename": path})
+		return nil, "", nil, fmt.Errorf("failed to import mindmap: %w", err)
+	}
+
+	// Verify integrity before applying anything to storage, files of other tools have no integrity data
+	if storage.IsNativeFormat(format) {
+		integrity, err := m.verifyImport(path, importedMindmap, options.Force)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		warnings = append(warnings, integrity...)
+	}
+
+	// Computed fields of an export are derived from the structure, not kept as content
+	importedMindmap.RemoveComputedFields()
+
+	// Validate the imported mindmap structure
+	if err := m.validateMindmap(importedMindmap); err != nil {
+		m.Logger.Error(ctx, "Invalid mindmap structure", log.Fields{"error": err})
+		return nil, "", nil, fmt.Errorf("invalid mindmap structure: %w", err)
+	}
+
+	return importedMindmap, format, warnings, nil
+}
+
+// importAdd adds an imported mindmap with its nodes, links and attachments for user. Returns warnings about the
+// links and attachments that could not be added.
+func (m *DataManager) importAdd(user *model.User, importedMindmap *model.Mindmap, options model.ImportOptions) ([]string, error) {
+	ctx := context.Background()
+
 	// Add the new mindmap
 	importedMindmap.Owner = user.Username
 	newMindmapID, err := m.MindmapManager.MindmapAdd(user, model.MindmapInfo{
@@ -158,8 +784,14 @@ func (m *DataManager) MindmapImport(user *model.User, filename, format string) (
 	}
 	importedMindmap.ID = newMindmapID
 
-	// Add nodes
-	for _, node := range importedMindmap.Nodes {
+	// Add nodes in document order so parents are stored before their children, reporting progress as they are
+	// stored. The root node is added along with the mindmap.
+	progress := model.Progress{Operation: "import", Total: len(importedMindmap.Nodes), Started: time.Now()}
+	if options.Progress != nil {
+		options.Progress(progress)
+	}
+	isChild := func(node *model.Node) bool { return node.ID != 0 }
+	for node := range importedMindmap.Subtree(nil, isChild) {
 		m.Logger.Debug(ctx, "Adding node to imported mindmap", log.Fields{"nodeID": node.ID, "nodeName": node.Name})
 		_, _, err := m.NodeManager.NodeAdd(importedMindmap, m.NodeManager.NodeToInfo(node), true)
 		if err != nil {
@@ -168,10 +800,70 @@ func (m *DataManager) MindmapImport(user *model.User, filename, format string) (
 			m.MindmapManager.MindmapDelete(user, importedMindmap)
 			return nil, fmt.Errorf("failed to add node: %w", err)
 		}
+		progress.Done++
+		if options.Progress != nil {
+			options.Progress(progress)
+		}
 	}
 
-	m.Logger.Info(ctx, "Mindmap imported successfully", log.Fields{"mindmapID": importedMindmap.ID, "mindmapName": importedMindmap.Name})
-	return importedMindmap, nil
+	warnings, err := m.importLinks(user, importedMindmap, importedMindmap.Links, nil)
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to add links, rolling back", log.Fields{"error": err})
+		m.MindmapManager.MindmapDelete(user, importedMindmap)
+		return nil, fmt.Errorf("failed to add links: %w", err)
+	}
+
+	attachmentWarnings, err := m.importAttachments(importedMindmap, importedMindmap.Attachments, nil)
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to add attachments, rolling back", log.Fields{"error": err})
+		m.MindmapManager.MindmapDelete(user, importedMindmap)
+		return nil, fmt.Errorf("failed to add attachments: %w", err)
+	}
+	return append(warnings, attachmentWarnings...), nil
+}
+
+// verifyImport checks the checksum and signature of an imported file. Missing integrity data only produces
+// warnings; corrupted or tampered files are rejected unless forced, in which case a warning is returned instead.
+func (m *DataManager) verifyImport(path string, mindmap *model.Mindmap, force bool) ([]string, error) {
+	ctx := context.Background()
+	var warnings []string
+
+	err := storage.ChecksumVerify(mindmap)
+	switch {
+	case err == nil:
+		m.Logger.Debug(ctx, "Import checksum verified", log.Fields{"path": path})
+	case errors.Is(err, storage.ErrChecksumMissing):
+		m.Logger.Warn(ctx, "Imported file has no checksum", log.Fields{"path": path})
+		warnings = append(warnings, "file has no checksum, its integrity could not be verified")
+	default:
+		m.Logger.Warn(ctx, "Import checksum verification failed", log.Fields{"error": err, "path": path, "force": force})
+		if !force {
+			return nil, fmt.Errorf("%w; use --force to import anyway", err)
+		}
+		warnings = append(warnings, err.Error())
+	}
+
+	if mindmap.Checksum == "" {
+		return warnings, nil
+	}
+	err = storage.SignatureVerify(path, mindmap.Checksum, m.Config.KeyDir, mindmap.Owner)
+	switch {
+	case err == nil:
+		m.Logger.Info(ctx, "Import signature verified", log.Fields{"path": path, "signer": mindmap.Owner})
+	case errors.Is(err, storage.ErrSignatureMissing):
+		m.Logger.Debug(ctx, "Imported file is not signed", log.Fields{"path": path})
+	case errors.Is(err, storage.ErrSignatureInvalid):
+		m.Logger.Warn(ctx, "Import signature verification failed", log.Fields{"error": err, "path": path, "force": force})
+		if !force {
+			return nil, fmt.Errorf("%w; use --force to import anyway", err)
+		}
+		warnings = append(warnings, err.Error())
+	default:
+		m.Logger.Warn(ctx, "Import signature could not be verified", log.Fields{"error": err, "path": path})
+		warnings = append(warnings, fmt.Sprintf("signature could not be verified: %v", err))
+	}
+
+	return warnings, nil
 }
 
 // validateMindmap checks the imported mindmap structure for validity.
@@ -185,6 +877,20 @@ func (mm *DataManager) validateMindmap(mindmap *model.Mindmap) error {
 		return fmt.Errorf("invalid root node structure")
 	}
 
+	// Check the mindmap name and the field names of all nodes
+	if err := names.Validate(names.Mindmap, mindmap.Name); err != nil {
+		mm.Logger.Warn(ctx, "Invalid mindmap name", log.Fields{"error": err})
+		return err
+	}
+	for _, node := range mindmap.Nodes {
+		for field := range node.Content {
+			if err := names.Validate(names.Field, field); err != nil {
+				mm.Logger.Warn(ctx, "Invalid field name", log.Fields{"error": err, "nodeID": node.ID})
+				return err
+			}
+		}
+	}
+
 	nodeIDs := make(map[int]bool)
 	nodeIDs[0] = true // Root node
 
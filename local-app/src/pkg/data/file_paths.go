@@ -0,0 +1,109 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the resolution of export and import file paths.
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// ExportPath resolves the file path a mindmap is exported to. An empty filename uses the configured
+// export template. Filenames may contain the placeholders {mindmap}, {owner}, {id}, {date}, {time} and
+// {format}, which is replaced with the file extension of the format; name placeholders are sanitized for use in paths. The result must stay within the export directory.
+func (m *DataManager) ExportPath(mindmap *model.Mindmap, filename, format string) (string, error) {
+	if filename == "" {
+		filename = m.Config.ExportTemplate
+	}
+
+	now := time.Now()
+	replacer := strings.NewReplacer(
+		"{mindmap}", names.Filename(mindmap.Name, "mindmap"),
+		"{owner}", names.Filename(mindmap.Owner, "owner"),
+		"{id}", strconv.Itoa(mindmap.ID),
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("150405"),
+		"{format}", storage.FormatExtension(format),
+	)
+	return m.resolvePath(replacer.Replace(filename))
+}
+
+// TelemetryPath resolves the file path the command telemetry is exported to. An empty filename exports to
+// telemetry-{date}.json, {date} is replaced in filenames. The result must stay within the export directory.
+func (m *DataManager) TelemetryPath(filename string) (string, error) {
+	if filename == "" {
+		filename = "telemetry-{date}.json"
+	}
+	return m.resolvePath(strings.ReplaceAll(filename, "{date}", time.Now().Format("2006-01-02")))
+}
+
+// TranscriptPath resolves the file path a session transcript is recorded to. An empty filename records to
+// transcript-{date}-{time}.txt, {date} and {time} are replaced in filenames. The result must stay within the
+// export directory.
+func (m *DataManager) TranscriptPath(filename string) (string, error) {
+	if filename == "" {
+		filename = "transcript-{date}-{time}.txt"
+	}
+	now := time.Now()
+	replacer := strings.NewReplacer("{date}", now.Format("2006-01-02"), "{time}", now.Format("150405"))
+	return m.resolvePath(replacer.Replace(filename))
+}
+
+// ImportPath resolves the file path a mindmap is imported from. The result must stay within the export directory.
+func (m *DataManager) ImportPath(filename string) (string, error) {
+	return m.resolvePath(filename)
+}
+
+// JournalPath resolves the file path a command journal is replayed from. The result must stay within the log
+// folder, which the journal is written to.
+func (m *DataManager) JournalPath(filename string) (string, error) {
+	return m.resolvePathIn(m.Config.LogFolder, "log folder", filename)
+}
+
+// resolvePath joins a relative filename to the export directory, refusing absolute paths
+// and paths that would escape the export directory
+func (m *DataManager) resolvePath(filename string) (string, error) {
+	return m.resolvePathIn(m.Config.ExportDir, "export directory", filename)
+}
+
+// resolvePathIn joins a relative filename to a directory, refusing absolute paths and paths that would escape the
+// directory, which errors name as dirName
+func (m *DataManager) resolvePathIn(dir, dirName, filename string) (string, error) {
+	if filename == "" {
+		return "", fmt.Errorf("filename must not be empty")
+	}
+	if filepath.IsAbs(filename) || !filepath.IsLocal(filename) {
+		m.Logger.Warn(context.Background(), "Refusing path outside the "+dirName, log.Fields{"filename": filename})
+		return "", fmt.Errorf("file '%s' is outside the %s", filename, dirName)
+	}
+	return filepath.Join(dir, filename), nil
+}
+
+// ExportWrite writes a rendered file, such as a link graph or a summary, to a path resolved in the export
+// directory, refusing to overwrite an existing file unless forced
+func (m *DataManager) ExportWrite(path string, data []byte, force bool) error {
+	ctx := context.Background()
+	if _, err := os.Stat(path); err == nil && !force {
+		m.Logger.Warn(ctx, "Export file already exists", log.Fields{"path": path})
+		return fmt.Errorf("file '%s' already exists, use --force to overwrite", path)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to check file: %w", err)
+	}
+	if err := storage.WriteFileAtomic(path, data, 0644); err != nil {
+		m.Logger.Error(ctx, "Failed to write export file", log.Fields{"error": err, "path": path})
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	m.Logger.Info(ctx, "Export file written", log.Fields{"path": path})
+	return nil
+}
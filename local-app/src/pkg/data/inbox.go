@@ -0,0 +1,60 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the inbox node collecting the nodes created without a chosen parent.
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// InboxName is the name of the top-level node under which nodes are created when no other parent is given,
+// such as the nodes for unresolved references and captured emails
+const InboxName = "Inbox"
+
+// inboxNode returns the top-level Inbox node of a mindmap, adding it if the mindmap has none
+func (m *DataManager) inboxNode(mindmap *model.Mindmap) (*model.Node, error) {
+	if inbox := childNode(mindmap.Root, InboxName); inbox != nil {
+		return inbox, nil
+	}
+
+	id, _, err := m.NodeManager.NodeAdd(mindmap, model.NodeInfo{ParentID: mindmap.Root.ID, Name: InboxName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add inbox node: %w", err)
+	}
+	return mindmap.Nodes[id], nil
+}
+
+// InboxAdd adds a node under the inbox of a loaded mindmap: the node of the given ID, or the top-level Inbox node,
+// added as needed, if the ID is 0 or the node is gone. Returns the node added.
+func (m *DataManager) InboxAdd(mindmap *model.Mindmap, inboxID int, info model.NodeInfo) (*model.Node, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Adding node to inbox", log.Fields{"mindmapID": mindmap.ID, "inboxID": inboxID})
+
+	var node *model.Node
+	err := m.NodeBatch(mindmap, func() error {
+		inbox := mindmap.Nodes[inboxID]
+		if inboxID == 0 || inbox == nil {
+			var err error
+			if inbox, err = m.inboxNode(mindmap); err != nil {
+				return err
+			}
+		}
+		info.ParentID = inbox.ID
+		id, _, err := m.NodeManager.NodeAdd(mindmap, info)
+		if err != nil {
+			return fmt.Errorf("failed to add node %s: %w", info.Name, err)
+		}
+		node = mindmap.Nodes[id]
+		return nil
+	})
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to add node to inbox", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, err
+	}
+
+	m.Logger.Info(ctx, "Node added to inbox", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "parentID": node.ParentID})
+	return node, nil
+}
@@ -0,0 +1,140 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the loading of large mindmaps a few levels at a time.
+package data
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// NodeLoadLevels replaces the in-memory nodes of a mindmap with the root and the nodes down to levels below it,
+// recording the children left out in mindmap.Unloaded. NodeLoad and NodeLoadAll load the rest when it is needed.
+func (nm *NodeManager) NodeLoadLevels(mindmap *model.Mindmap, levels int) error {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Loading node levels", log.Fields{"mindmapID": mindmap.ID, "levels": levels})
+
+	nodes, unloaded, err := nm.nodeStore.NodeGetLevels(mindmap, "0", levels)
+	if err != nil {
+		nm.logger.Error(ctx, "Failed to get node levels", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return fmt.Errorf("failed to get nodes: %w", err)
+	}
+
+	mindmap.Nodes = make(map[int]*model.Node, len(nodes))
+	for _, node := range nodes {
+		mindmap.Nodes[node.ID] = node
+		if node.ID == 0 {
+			mindmap.Root = node
+		}
+	}
+	mindmap.Unloaded = unloaded
+	mindmap.Hidden = 0
+	mindmap.LinkChildren()
+	nm.backlinksReset(mindmap)
+	nm.statsReset(mindmap)
+	nm.childIndexReset(mindmap)
+
+	nm.logger.Info(ctx, "Node levels loaded for mindmap", log.Fields{"mindmapID": mindmap.ID, "nodeCount": len(nodes), "unloaded": len(unloaded)})
+	return nil
+}
+
+// NodeLoad loads the node of a stored index of a mindmap loaded only a few levels deep, with the nodes on the way
+// to it from the loaded ones and its descendants down to levels below it. Nothing is loaded for a mindmap loaded
+// whole or a node that is not in storage or hidden.
+func (nm *NodeManager) NodeLoad(mindmap *model.Mindmap, index string, levels int) error {
+	if !mindmap.Partial() {
+		return nil
+	}
+	ctx := context.Background()
+	nm.logger.Debug(ctx, "Loading node subtree", log.Fields{"mindmapID": mindmap.ID, "index": index, "levels": levels})
+
+	loaded := make(map[string]*model.Node, len(mindmap.Nodes))
+	for _, node := range mindmap.Nodes {
+		loaded[node.Index] = node
+	}
+
+	// The children of the nodes above the node are loaded one level at a time, down to the node
+	path := []string{"0"}
+	if index != "0" {
+		parts := strings.Split(index, ".")
+		for i := range parts {
+			path = append(path, strings.Join(parts[:i+1], "."))
+		}
+	}
+	for i, ancestor := range path {
+		node := loaded[ancestor]
+		if node == nil {
+			return nil
+		}
+		depth := levels
+		if i < len(path)-1 {
+			if mindmap.Unloaded[node.ID] == 0 {
+				continue
+			}
+			depth = 1
+		}
+
+		nodes, unloaded, err := nm.nodeStore.NodeGetLevels(mindmap, ancestor, depth)
+		if err != nil {
+			nm.logger.Error(ctx, "Failed to get node levels", log.Fields{"error": err, "mindmapID": mindmap.ID, "index": ancestor})
+			return fmt.Errorf("failed to get nodes: %w", err)
+		}
+		for _, merged := range nodesMerge(mindmap, nodes, unloaded) {
+			loaded[merged.Index] = merged
+		}
+	}
+	nm.backlinksReset(mindmap)
+
+	nm.logger.Debug(ctx, "Node subtree loaded", log.Fields{"mindmapID": mindmap.ID, "nodeCount": len(mindmap.Nodes), "unloaded": len(mindmap.Unloaded)})
+	return nil
+}
+
+// NodeLoadAll loads all nodes of a mindmap loaded only a few levels deep, for the operations needing the whole
+// mindmap. Returns false for a mindmap loaded whole, which is left as it is. Private subtrees hidden before are
+// loaded too and must be hidden again.
+func (nm *NodeManager) NodeLoadAll(mindmap *model.Mindmap) (bool, error) {
+	if !mindmap.Partial() {
+		return false, nil
+	}
+
+	mindmap.Hidden = 0
+	if err := nm.loadNodes(mindmap); err != nil {
+		nm.logger.Error(context.Background(), "Failed to load all nodes of mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return false, err
+	}
+	return true, nil
+}
+
+// nodesMerge adds the nodes of a subtree read from storage to a mindmap loaded only a few levels deep and returns
+// those added. Only the nodes below an unloaded one are new, so loaded nodes are kept and hidden private subtrees
+// stay out. The added nodes of the last level read are recorded in mindmap.Unloaded with their child counts.
+func nodesMerge(mindmap *model.Mindmap, nodes []*model.Node, unloaded map[int]int) []*model.Node {
+	slices.SortFunc(nodes, func(a, b *model.Node) int {
+		return cmp.Compare(model.IndexLevel(a.Index), model.IndexLevel(b.Index))
+	})
+
+	added := make(map[int]bool)
+	var merged []*model.Node
+	for _, node := range nodes {
+		if mindmap.Nodes[node.ID] != nil || !added[node.ParentID] && mindmap.Unloaded[node.ParentID] == 0 {
+			continue
+		}
+		mindmap.Nodes[node.ID] = node
+		added[node.ID] = true
+		merged = append(merged, node)
+	}
+
+	for _, node := range merged {
+		delete(mindmap.Unloaded, node.ParentID)
+		if count := unloaded[node.ID]; count > 0 {
+			mindmap.Unloaded[node.ID] = count
+		}
+	}
+	mindmap.LinkChildren()
+	return merged
+}
@@ -0,0 +1,174 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the check of the references of the nodes of a mindmap.
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// urlCheckWorkers is the number of URLs checked at once
+const urlCheckWorkers = 8
+
+// urlPattern matches web addresses in text, trailing punctuation is trimmed from the matches
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'\[\]]+`)
+
+// LinkCheck reports the references of the nodes of a loaded mindmap that lead nowhere: stored links from or to
+// nodes no longer in the mindmap, [[name]] and [[#id]] references resolving to no node or to several, and
+// malformed URLs, or with options.HTTP URLs that do not respond successfully. Nothing is changed.
+func (m *DataManager) LinkCheck(mindmap *model.Mindmap, options model.LinkCheckOptions) ([]*model.BrokenReference, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Checking links", log.Fields{"mindmapID": mindmap.ID, "http": options.HTTP})
+
+	var broken []*model.BrokenReference
+
+	links, err := m.LinkManager.LinkGetMindmap(mindmap)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		target := idReferenceKey(link.TargetID)
+		switch {
+		case mindmap.Nodes[link.SourceID] == nil:
+			broken = append(broken, &model.BrokenReference{SourceID: link.SourceID, Kind: model.ReferenceLink, Target: target, Problem: "source node no longer exists"})
+		case link.TargetMindmapID == mindmap.ID && mindmap.Nodes[link.TargetID] == nil:
+			broken = append(broken, &model.BrokenReference{SourceID: link.SourceID, Kind: model.ReferenceLink, Target: target, Problem: "target node no longer exists"})
+		}
+	}
+
+	byName := make(map[string]int)
+	for n := range mindmap.Subtree(nil, nil) {
+		byName[referenceKey(n.Name)]++
+	}
+
+	// URLs are checked once each, in order of first appearance, and reported at every node using them
+	urlUses := make(map[string][]*model.BrokenReference)
+	var urls []string
+	for node := range mindmap.Subtree(nil, nil) {
+		for _, key := range slices.Sorted(maps.Keys(node.Content)) {
+			value := node.Content[key]
+			for _, target := range ParseReferences(value) {
+				problem := ""
+				if id, err := strconv.Atoi(strings.TrimPrefix(target, "#")); err == nil && strings.HasPrefix(target, "#") {
+					if mindmap.Nodes[id] == nil {
+						problem = "no node has this ID"
+					}
+				} else if count := byName[referenceKey(target)]; count == 0 {
+					problem = "no node has this name"
+				} else if count > 1 {
+					problem = fmt.Sprintf("%d nodes have this name", count)
+				}
+				if problem != "" {
+					broken = append(broken, &model.BrokenReference{SourceID: node.ID, Field: key, Kind: model.ReferenceWiki, Target: target, Problem: problem})
+				}
+			}
+
+			for _, match := range urlPattern.FindAllString(value, -1) {
+				address := strings.TrimRight(match, ".,;:!?)")
+				if _, seen := urlUses[address]; !seen {
+					urls = append(urls, address)
+				}
+				urlUses[address] = append(urlUses[address], &model.BrokenReference{SourceID: node.ID, Field: key, Kind: model.ReferenceURL, Target: address})
+			}
+		}
+	}
+
+	problems := checkURLs(urls, options)
+	for _, address := range urls {
+		if problem := problems[address]; problem != "" {
+			for _, use := range urlUses[address] {
+				use.Problem = problem
+				broken = append(broken, use)
+			}
+		}
+	}
+
+	m.Logger.Info(ctx, "Links checked", log.Fields{"mindmapID": mindmap.ID, "links": len(links), "urls": len(urls), "broken": len(broken)})
+	return broken, nil
+}
+
+// checkURLs returns the problems of the malformed URLs and, if options.HTTP is set, of the URLs not responding
+// successfully, keyed by URL
+func checkURLs(urls []string, options model.LinkCheckOptions) map[string]string {
+	problems := make(map[string]string)
+	var reachable []string
+	for _, address := range urls {
+		if u, err := url.Parse(address); err != nil || u.Host == "" {
+			problems[address] = "malformed URL"
+		} else if options.HTTP {
+			reachable = append(reachable, address)
+		}
+	}
+	if len(reachable) == 0 {
+		return problems
+	}
+
+	client := &http.Client{Timeout: options.Timeout}
+	started := time.Now()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	done := 0
+	for range min(urlCheckWorkers, len(reachable)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for address := range queue {
+				problem := checkURL(client, address)
+				mu.Lock()
+				if problem != "" {
+					problems[address] = problem
+				}
+				done++
+				if options.Progress != nil {
+					options.Progress(model.Progress{Operation: "Checking URLs", Done: done, Total: len(reachable), Started: started})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, address := range reachable {
+		queue <- address
+	}
+	close(queue)
+	wg.Wait()
+	return problems
+}
+
+// checkURL requests the head of a URL, or the URL itself if the server does not support HEAD requests, and
+// returns the problem if it does not respond successfully
+func checkURL(client *http.Client, address string) string {
+	resp, err := client.Head(address)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = client.Get(address)
+	}
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			if urlErr.Timeout() {
+				return "no response in time"
+			}
+			err = urlErr.Err
+		}
+		return "request failed: " + err.Error()
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "HTTP " + resp.Status
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the graph of the cross-links between nodes.
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// LinkGraph returns the graph of the stored links between the loaded nodes of a mindmap, with the nodes having a
+// link in document order and the links in the order they were added. With tree, all nodes are included along
+// with an edge from each node to each of its children. Links from or to nodes no longer in the mindmap, hidden
+// from the user or of other mindmaps are left out.
+func (m *DataManager) LinkGraph(mindmap *model.Mindmap, tree bool) (*model.LinkGraph, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Building link graph", log.Fields{"mindmapID": mindmap.ID, "tree": tree})
+
+	links, err := m.LinkManager.LinkGetMindmap(mindmap)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &model.LinkGraph{Mindmap: mindmap.Name, Nodes: []model.GraphNode{}, Edges: []model.GraphEdge{}}
+	linked := make(map[int]bool)
+	for _, link := range links {
+		if link.TargetMindmapID != mindmap.ID || mindmap.Nodes[link.SourceID] == nil || mindmap.Nodes[link.TargetID] == nil {
+			continue
+		}
+		graph.Edges = append(graph.Edges, model.GraphEdge{Source: link.SourceID, Target: link.TargetID, Type: link.Type})
+		linked[link.SourceID], linked[link.TargetID] = true, true
+	}
+
+	var treeEdges []model.GraphEdge
+	for node := range mindmap.Subtree(nil, nil) {
+		if !tree && !linked[node.ID] {
+			continue
+		}
+		graph.Nodes = append(graph.Nodes, model.GraphNode{ID: node.ID, Index: node.Index, Name: node.Name})
+		if parent := mindmap.Nodes[node.ParentID]; tree && parent != nil && node.ID != 0 {
+			treeEdges = append(treeEdges, model.GraphEdge{Source: parent.ID, Target: node.ID, Type: model.GraphEdgeTree})
+		}
+	}
+	graph.Edges = append(treeEdges, graph.Edges...)
+
+	m.Logger.Info(ctx, "Link graph built", log.Fields{"mindmapID": mindmap.ID, "nodes": len(graph.Nodes), "edges": len(graph.Edges)})
+	return graph, nil
+}
+
+// EncodeLinkGraph renders a link graph in a graph format, DOT or JSON. In DOT the nodes are labeled with their
+// displayed index and name, the links with their type and the tree edges drawn dashed without a label.
+func EncodeLinkGraph(graph *model.LinkGraph, format string, style model.DisplayStyle) ([]byte, error) {
+	switch format {
+	case model.GraphFormatJSON:
+		data, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode link graph: %w", err)
+		}
+		return append(data, '\n'), nil
+	case model.GraphFormatDOT:
+		var b strings.Builder
+		fmt.Fprintf(&b, "digraph %s {\n", dotQuote(graph.Mindmap))
+		for _, node := range graph.Nodes {
+			label := node.Name
+			if node.ID != 0 {
+				label = style.DisplayIndex(node.Index) + " " + node.Name
+			}
+			fmt.Fprintf(&b, "  n%d [label=%s];\n", node.ID, dotQuote(label))
+		}
+		for _, edge := range graph.Edges {
+			if edge.Type == model.GraphEdgeTree {
+				fmt.Fprintf(&b, "  n%d -> n%d [style=dashed];\n", edge.Source, edge.Target)
+			} else {
+				fmt.Fprintf(&b, "  n%d -> n%d [label=%s];\n", edge.Source, edge.Target, dotQuote(edge.Type))
+			}
+		}
+		b.WriteString("}\n")
+		return []byte(b.String()), nil
+	default:
+		return nil, fmt.Errorf("invalid graph format '%s': must be %s or %s", format, model.GraphFormatDOT, model.GraphFormatJSON)
+	}
+}
+
+// dotQuote quotes text as a DOT string
+func dotQuote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text) + `"`
+}
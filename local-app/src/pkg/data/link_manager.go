@@ -0,0 +1,183 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains operations related to cross-links between nodes.
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// LinkOperations defines the interface for cross-link operations
+type LinkOperations interface {
+	LinkAdd(mindmap *model.Mindmap, source *model.Node, target *model.Node, linkType string) error
+	LinkGet(mindmap *model.Mindmap, source *model.Node, linkType string) ([]*model.Link, error)
+	LinkDelete(link *model.Link) error
+}
+
+// LinkManager handles the typed cross-links between nodes, which are kept apart from the tree hierarchy.
+type LinkManager struct {
+	linkStore storage.LinkStore
+	logger    *log.Logger
+}
+
+// NewLinkManager creates a new LinkManager instance.
+func NewLinkManager(linkStore storage.LinkStore, logger *log.Logger) (*LinkManager, error) {
+	ctx := context.Background()
+	logger.Info(ctx, "Creating new LinkManager", nil)
+
+	if linkStore == nil {
+		logger.Error(ctx, "LinkStore not initialized", nil)
+		return nil, fmt.Errorf("linkStore not initialized")
+	}
+
+	lm := &LinkManager{
+		linkStore: linkStore,
+		logger:    logger,
+	}
+
+	logger.Info(ctx, "LinkManager created successfully", nil)
+	return lm, nil
+}
+
+// handleMindmapDeleted removes the links from and to the nodes of a deleted mindmap
+func (lm *LinkManager) handleMindmapDeleted(e event.Event) {
+	ctx := context.Background()
+	lm.logger.Info(ctx, "Handling MindmapDeleted event", nil)
+
+	mindmap, ok := e.Data.(*model.Mindmap)
+	if !ok {
+		lm.logger.Error(ctx, "Invalid event data for mindmap delete event", nil)
+		return
+	}
+
+	for _, filter := range []model.LinkFilter{{SourceMindmapID: true}, {TargetMindmapID: true}} {
+		_, err := lm.linkStore.LinkDelete(model.Link{SourceMindmapID: mindmap.ID, TargetMindmapID: mindmap.ID}, filter)
+		if err != nil {
+			lm.logger.Error(ctx, "Failed to delete links of deleted mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		}
+	}
+}
+
+// LinkAdd adds a link of the given type from a source node to a target node of the same mindmap
+func (lm *LinkManager) LinkAdd(mindmap *model.Mindmap, source *model.Node, target *model.Node, linkType string) error {
+	ctx := context.Background()
+	lm.logger.Info(ctx, "Adding link", log.Fields{"mindmapID": mindmap.ID, "sourceID": source.ID, "targetID": target.ID, "type": linkType})
+
+	_, err := lm.linkStore.LinkAdd(model.Link{
+		SourceMindmapID: mindmap.ID,
+		SourceID:        source.ID,
+		TargetMindmapID: mindmap.ID,
+		TargetID:        target.ID,
+		Type:            linkType,
+	})
+	if err != nil {
+		lm.logger.Error(ctx, "Failed to add link", log.Fields{"error": err, "sourceID": source.ID, "targetID": target.ID})
+		return fmt.Errorf("failed to add link: %w", err)
+	}
+	return nil
+}
+
+// LinkCreate adds a link between the nodes of any two mindmaps, as set in link. Returns false if the same link
+// exists already.
+func (lm *LinkManager) LinkCreate(link model.Link) (bool, error) {
+	ctx := context.Background()
+	lm.logger.Info(ctx, "Creating link", log.Fields{"link": link})
+
+	existing, err := lm.linkStore.LinkGet(link, linkMatch)
+	if err != nil {
+		lm.logger.Error(ctx, "Failed to check for existing link", log.Fields{"error": err})
+		return false, fmt.Errorf("failed to check for existing link: %w", err)
+	}
+	if len(existing) > 0 {
+		return false, nil
+	}
+
+	if _, err := lm.linkStore.LinkAdd(link); err != nil {
+		lm.logger.Error(ctx, "Failed to add link", log.Fields{"error": err, "sourceID": link.SourceID, "targetID": link.TargetID})
+		return false, fmt.Errorf("failed to add link: %w", err)
+	}
+	return true, nil
+}
+
+// LinkRemove removes the link between two nodes as set in link. Returns false if there is no such link.
+func (lm *LinkManager) LinkRemove(link model.Link) (bool, error) {
+	ctx := context.Background()
+	lm.logger.Info(ctx, "Removing link", log.Fields{"link": link})
+
+	count, err := lm.linkStore.LinkDelete(link, linkMatch)
+	if err != nil {
+		lm.logger.Error(ctx, "Failed to remove link", log.Fields{"error": err, "sourceID": link.SourceID, "targetID": link.TargetID})
+		return false, fmt.Errorf("failed to remove link: %w", err)
+	}
+	return count > 0, nil
+}
+
+// linkMatch selects a link by its source, target and type
+var linkMatch = model.LinkFilter{SourceMindmapID: true, SourceID: true, TargetMindmapID: true, TargetID: true, Type: true}
+
+// LinkGet returns the links of a node of a loaded mindmap, of the given type or of all types if linkType is empty.
+// Links to nodes no longer in the mindmap are removed instead of returned.
+func (lm *LinkManager) LinkGet(mindmap *model.Mindmap, source *model.Node, linkType string) ([]*model.Link, error) {
+	ctx := context.Background()
+
+	filter := model.LinkFilter{SourceMindmapID: true, SourceID: true, Type: linkType != ""}
+	links, err := lm.linkStore.LinkGet(model.Link{SourceMindmapID: mindmap.ID, SourceID: source.ID, Type: linkType}, filter)
+	if err != nil {
+		lm.logger.Error(ctx, "Failed to get links", log.Fields{"error": err, "sourceID": source.ID})
+		return nil, fmt.Errorf("failed to get links: %w", err)
+	}
+
+	var current []*model.Link
+	for _, link := range links {
+		if _, exists := mindmap.Nodes[link.TargetID]; link.TargetMindmapID == mindmap.ID && !exists {
+			lm.logger.Debug(ctx, "Removing link to deleted node", log.Fields{"linkID": link.ID, "targetID": link.TargetID})
+			if err := lm.LinkDelete(link); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		current = append(current, link)
+	}
+	return current, nil
+}
+
+// LinkGetMindmap returns all links from the nodes of a mindmap, including links between nodes no longer in it
+func (lm *LinkManager) LinkGetMindmap(mindmap *model.Mindmap) ([]*model.Link, error) {
+	ctx := context.Background()
+
+	links, err := lm.linkStore.LinkGet(model.Link{SourceMindmapID: mindmap.ID}, model.LinkFilter{SourceMindmapID: true})
+	if err != nil {
+		lm.logger.Error(ctx, "Failed to get links of mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to get links: %w", err)
+	}
+	return links, nil
+}
+
+// LinkGetTargets returns all links to the nodes of a mindmap, from its own nodes or those of other mindmaps
+func (lm *LinkManager) LinkGetTargets(mindmap *model.Mindmap) ([]*model.Link, error) {
+	ctx := context.Background()
+
+	links, err := lm.linkStore.LinkGet(model.Link{TargetMindmapID: mindmap.ID}, model.LinkFilter{TargetMindmapID: true})
+	if err != nil {
+		lm.logger.Error(ctx, "Failed to get links to mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to get links: %w", err)
+	}
+	return links, nil
+}
+
+// LinkDelete removes a link
+func (lm *LinkManager) LinkDelete(link *model.Link) error {
+	ctx := context.Background()
+	lm.logger.Info(ctx, "Deleting link", log.Fields{"linkID": link.ID})
+
+	if _, err := lm.linkStore.LinkDelete(model.Link{ID: link.ID}, model.LinkFilter{ID: true}); err != nil {
+		lm.logger.Error(ctx, "Failed to delete link", log.Fields{"error": err, "linkID": link.ID})
+		return fmt.Errorf("failed to delete link: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the comparison of two mindmaps.
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// MindmapCompare loads the nodes of two mindmaps as seen by user and compares them, see model.CompareMindmaps.
+// Nothing is changed.
+func (m *DataManager) MindmapCompare(user *model.User, a, b *model.Mindmap) (model.MindmapComparison, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Comparing mindmaps", log.Fields{"mindmapA": a.ID, "mindmapB": b.ID})
+
+	for _, mindmap := range []*model.Mindmap{a, b} {
+		if err := m.NodeManager.loadNodes(mindmap); err != nil {
+			m.Logger.Error(ctx, "Failed to load compared mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+			return model.MindmapComparison{}, fmt.Errorf("failed to load mindmap %s: %w", mindmap.Name, err)
+		}
+		if mindmap.Owner != user.Username {
+			m.NodeManager.NodePrivateHide(mindmap)
+		}
+	}
+
+	comparison := model.CompareMindmaps(a, b)
+	m.Logger.Info(ctx, "Mindmaps compared", log.Fields{"mindmapA": a.ID, "mindmapB": b.ID, "shared": comparison.Shared, "editDistance": comparison.EditDistance})
+	return comparison, nil
+}
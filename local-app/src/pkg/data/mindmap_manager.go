@@ -9,6 +9,7 @@ import (
 	"mindnoscape/local-app/src/pkg/event"
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
 	"mindnoscape/local-app/src/pkg/storage"
 )
 
@@ -27,6 +28,8 @@ type MindmapManager struct {
 	mindmapStore storage.MindmapStore
 	eventManager *event.EventManager
 	logger       *log.Logger
+
+	nodeStats func(mindmap *model.Mindmap) (model.MindmapStats, error) // Node count and depth, see NodeManager.MindmapStats
 }
 
 func NewMindmapManager(mindmapStore storage.MindmapStore, eventManager *event.EventManager, logger *log.Logger) (*MindmapManager, error) {
@@ -61,19 +64,22 @@ func (mm *MindmapManager) MindmapAdd(user *model.User, newMindmapInfo model.Mind
 	ctx := context.Background()
 	mm.logger.Info(ctx, "Adding new mindmap", log.Fields{"username": user.Username, "mindmapName": newMindmapInfo.Name})
 
+	// Store the mindmap name in normalized form, so equivalent spellings are one mindmap
+	newMindmapInfo.Name = names.Normalize(newMindmapInfo.Name)
+	newMindmapInfo.Owner = user.Username
+
 	// Check if the user already has a mindmap with the same name
-	existingMindmaps, err := mm.MindmapGet(user, newMindmapInfo, model.MindmapFilter{Name: true})
+	existingMindmaps, err := mm.mindmapStore.MindmapGet(user, newMindmapInfo, model.MindmapFilter{Name: true, Owner: true})
 	if err != nil {
 		mm.logger.Error(ctx, "Failed to check for existing mindmap", log.Fields{"error": err, "mindmapName": newMindmapInfo.Name})
 		return 0, fmt.Errorf("failed to check for existing mindmap: %w", err)
 	}
 	if len(existingMindmaps) > 0 {
 		mm.logger.Warn(ctx, "Mindmap with the same name already exists", log.Fields{"mindmapName": newMindmapInfo.Name})
-		return 0, fmt.Errorf("mindmap with name '%s' already exists for this user", newMindmapInfo.Name)
+		return 0, fmt.Errorf("mindmap with name '%s' already exists for this user", existingMindmaps[0].Name)
 	}
 
 	// Add the new mindmap to storage
-	newMindmapInfo.Owner = user.Username
 	id, err := mm.mindmapStore.MindmapAdd(user, newMindmapInfo)
 	if err != nil {
 		mm.logger.Error(ctx, "Failed to add mindmap", log.Fields{"error": err, "mindmapName": newMindmapInfo.Name})
@@ -98,11 +104,15 @@ func (mm *MindmapManager) MindmapAdd(user *model.User, newMindmapInfo model.Mind
 	// Initialize the Nodes map
 	newMindmap.Nodes = make(map[int]*model.Node)
 
-	// Publish MindmapCreated event
-	mm.eventManager.Publish(event.Event{
+	// Publish MindmapCreated event and wait for the root node to be created
+	err = mm.eventManager.PublishAndWait(event.Event{
 		Type: event.MindmapAdded,
 		Data: newMindmap,
 	})
+	if err != nil {
+		mm.logger.Error(ctx, "Failed to publish MindmapAdded event", log.Fields{"error": err, "mindmapID": id})
+		return 0, fmt.Errorf("failed to initialize the new mindmap: %w", err)
+	}
 	mm.logger.Debug(ctx, "Published MindmapAdded event", log.Fields{"mindmapID": id})
 
 	mm.logger.Info(ctx, "Mindmap added successfully", log.Fields{"mindmapID": id, "mindmapName": newMindmapInfo.Name})
@@ -115,7 +125,7 @@ func (mm *MindmapManager) MindmapPermission(user *model.User, mindmapInfo model.
 	mm.logger.Info(ctx, "Checking mindmap permission", log.Fields{"username": user.Username, "mindmapID": mindmapInfo.ID})
 
 	// Get the mindmap
-	mindmaps, err := mm.MindmapGet(user, mindmapInfo, model.MindmapFilter{ID: true, Owner: true, IsPublic: true})
+	mindmaps, err := mm.MindmapGet(user, mindmapInfo, model.MindmapFilter{ID: true})
 	if err != nil {
 		mm.logger.Error(ctx, "Failed to get mindmap", log.Fields{"error": err, "mindmapID": mindmapInfo.ID})
 		return 0, fmt.Errorf("failed to get mindmap: %w", err)
@@ -167,8 +177,24 @@ func (mm *MindmapManager) MindmapGet(user *model.User, mindmapInfo model.Mindmap
 	return allowedMindmaps, nil
 }
 
+// MindmapExists reports whether the user can see a mindmap of the given name, their own or a public one, without
+// loading its nodes
+func (mm *MindmapManager) MindmapExists(user *model.User, name string) (bool, error) {
+	mindmaps, err := mm.MindmapGet(user, model.MindmapInfo{Name: name}, model.MindmapFilter{Name: true})
+	if err != nil {
+		return false, err
+	}
+	return len(mindmaps) > 0, nil
+}
+
 // MindmapUpdate updates an existing mindmap's information
 func (mm *MindmapManager) MindmapUpdate(user *model.User, mindmap *model.Mindmap, mindmapUpdateInfo model.MindmapInfo, mindmapFilter model.MindmapFilter) error {
+	return mm.mindmapUpdate(user, mindmap, mindmapUpdateInfo, mindmapFilter, true)
+}
+
+// mindmapUpdate updates an existing mindmap's information, recording the update as a change of the mindmap if revise
+// is set
+func (mm *MindmapManager) mindmapUpdate(user *model.User, mindmap *model.Mindmap, mindmapUpdateInfo model.MindmapInfo, mindmapFilter model.MindmapFilter, revise bool) error {
 	ctx := context.Background()
 	mm.logger.Info(ctx, "Updating mindmap", log.Fields{"username": user.Username, "mindmapID": mindmap.ID})
 
@@ -186,6 +212,24 @@ func (mm *MindmapManager) MindmapUpdate(user *model.User, mindmap *model.Mindmap
 	// Store old values for potential rollback and event
 	oldName := mindmap.Name
 	oldIsPublic := mindmap.IsPublic
+	oldSort := mindmap.Sort
+
+	if mindmapFilter.Name {
+		mindmapUpdateInfo.Name = names.Normalize(mindmapUpdateInfo.Name)
+
+		// Check that the owner has no other mindmap with the new name
+		existingMindmaps, err := mm.mindmapStore.MindmapGet(user, model.MindmapInfo{Name: mindmapUpdateInfo.Name, Owner: mindmap.Owner}, model.MindmapFilter{Name: true, Owner: true})
+		if err != nil {
+			mm.logger.Error(ctx, "Failed to check for existing mindmap", log.Fields{"error": err, "mindmapName": mindmapUpdateInfo.Name})
+			return fmt.Errorf("failed to check for existing mindmap: %w", err)
+		}
+		for _, existingMindmap := range existingMindmaps {
+			if existingMindmap.ID != mindmap.ID {
+				mm.logger.Warn(ctx, "Mindmap with the same name already exists", log.Fields{"mindmapName": mindmapUpdateInfo.Name})
+				return fmt.Errorf("mindmap with name '%s' already exists for this user", existingMindmap.Name)
+			}
+		}
+	}
 
 	// Update mindmap fields based on the filter
 	if mindmapFilter.Name && mindmapUpdateInfo.Name != "" {
@@ -194,6 +238,9 @@ func (mm *MindmapManager) MindmapUpdate(user *model.User, mindmap *model.Mindmap
 	if mindmapFilter.IsPublic {
 		mindmap.IsPublic = mindmapUpdateInfo.IsPublic
 	}
+	if mindmapFilter.Sort {
+		mindmap.Sort = mindmapUpdateInfo.Sort
+	}
 
 	// Update in storage
 	err = mm.mindmapStore.MindmapUpdate(mindmap, mindmapUpdateInfo, mindmapFilter)
@@ -201,9 +248,16 @@ func (mm *MindmapManager) MindmapUpdate(user *model.User, mindmap *model.Mindmap
 		// Rollback changes if storage update fails
 		mindmap.Name = oldName
 		mindmap.IsPublic = oldIsPublic
+		mindmap.Sort = oldSort
 		mm.logger.Error(ctx, "Failed to update mindmap in storage", log.Fields{"error": err, "mindmapID": mindmap.ID})
 		return fmt.Errorf("failed to update mindmap in storage: %w", err)
 	}
+	// The sessions sharing the mindmap reload it with the new settings, the update is stored regardless
+	if revise {
+		if err := mm.MindmapRevise(mindmap); err != nil {
+			mm.logger.Warn(ctx, "Mindmap update not recorded as a change", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		}
+	}
 
 	// Publish MindmapUpdated event
 	mm.eventManager.Publish(event.Event{
@@ -236,22 +290,25 @@ func (mm *MindmapManager) MindmapDelete(user *model.User, mindmap *model.Mindmap
 		return fmt.Errorf("failed to delete mindmap: %w", err)
 	}
 
+	// The data of the mindmap kept by the other managers is deleted while the caller waits, so that the deletes don't
+	// run alongside the batches of later commands
+	if err := mm.eventManager.PublishAndWait(event.Event{Type: event.MindmapDeleted, Data: mindmap}); err != nil {
+		mm.logger.Error(ctx, "Failed to delete the data of deleted mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+	}
+
 	mm.logger.Info(ctx, "Mindmap deleted successfully", log.Fields{"mindmapID": mindmap.ID})
 	return nil
 }
 
-// MindmapToInfo extracts MindmapInfo from a Mindmap instance
+// MindmapToInfo extracts MindmapInfo from a Mindmap instance, with the cached node count and depth if available
 func (mm *MindmapManager) MindmapToInfo(mindmap *model.Mindmap) model.MindmapInfo {
 	var nodeCount *int
 	var depth *int
 
-	if mindmap.Nodes != nil {
-		count := len(mindmap.Nodes)
-		nodeCount = &count
-
-		// Calculate depth
-		depthValue := mm.calculateMindmapDepth(mindmap.Root)
-		depth = &depthValue
+	if mm.nodeStats != nil {
+		if stats, err := mm.nodeStats(mindmap); err == nil {
+			nodeCount, depth = &stats.NodeCount, &stats.Depth
+		}
 	}
 
 	return model.MindmapInfo{
@@ -259,26 +316,12 @@ func (mm *MindmapManager) MindmapToInfo(mindmap *model.Mindmap) model.MindmapInf
 		Name:      mindmap.Name,
 		Owner:     mindmap.Owner,
 		IsPublic:  mindmap.IsPublic,
+		Sort:      mindmap.Sort,
 		NodeCount: nodeCount,
 		Depth:     depth,
 	}
 }
 
-// calculateMindmapDepth computes the maximum depth of the mindmap tree structure
-func (mm *MindmapManager) calculateMindmapDepth(root *model.Node) int {
-	if root == nil {
-		return 0
-	}
-	maxDepth := 0
-	for _, child := range root.Children {
-		depth := mm.calculateMindmapDepth(child)
-		if depth > maxDepth {
-			maxDepth = depth
-		}
-	}
-	return maxDepth + 1
-}
-
 // handleUserDeleted deletes all mindmaps associated with the deleted user
 func (mm *MindmapManager) handleUserDeleted(e event.Event) {
 	ctx := context.Background()
@@ -297,8 +340,11 @@ func (mm *MindmapManager) handleUserDeleted(e event.Event) {
 		return
 	}
 
-	// Delete each mindmap
+	// Delete each mindmap still owned by the user; mindmaps deleted by an earlier delivery of the event are no longer found
 	for _, mindmap := range mindmaps {
+		if mindmap.Owner != user.Username {
+			continue
+		}
 		err := mm.MindmapDelete(user, mindmap)
 		if err != nil {
 			mm.logger.Error(ctx, "Failed to delete mindmap for deleted user", log.Fields{"error": err, "username": user.Username, "mindmapID": mindmap.ID})
@@ -343,16 +389,31 @@ func (mm *MindmapManager) handleRootNodeRenamed(e event.Event) {
 		return
 	}
 
+	ownerName, ok := data["owner"].(string)
+	if !ok {
+		mm.logger.Error(ctx, "Invalid owner in root node rename event", nil)
+		return
+	}
+	// The mindmap is renamed on behalf of its owner, whoever renamed the root node
+	owner := &model.User{Username: ownerName}
+
 	// Get the mindmap
-	mindmaps, err := mm.MindmapGet(nil, model.MindmapInfo{ID: mindmapID}, model.MindmapFilter{ID: true})
+	mindmaps, err := mm.MindmapGet(owner, model.MindmapInfo{ID: mindmapID}, model.MindmapFilter{ID: true})
 	if err != nil || len(mindmaps) == 0 {
 		mm.logger.Error(ctx, "Failed to get mindmap", log.Fields{"error": err, "mindmapID": mindmapID})
 		return
 	}
 	mindmap := mindmaps[0]
 
+	// Skip if the mindmap already carries the new name, e.g. for a duplicate or replayed event
+	if mindmap.Name == newName {
+		mm.logger.Debug(ctx, "Mindmap name already up to date", log.Fields{"mindmapID": mindmapID, "eventID": e.ID})
+		return
+	}
+
 	// Update the mindmap name
-	err = mm.MindmapUpdate(nil, mindmap, model.MindmapInfo{Name: newName}, model.MindmapFilter{Name: true})
+	// Not recorded as a change of its own, the node change renaming the root node records it
+	err = mm.mindmapUpdate(owner, mindmap, model.MindmapInfo{Name: newName}, model.MindmapFilter{Name: true}, false)
 	if err != nil {
 		mm.logger.Error(ctx, "Failed to update mindmap name", log.Fields{"error": err, "mindmapID": mindmapID})
 		return
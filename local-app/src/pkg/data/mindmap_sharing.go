@@ -0,0 +1,116 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the sharing of mindmaps between sessions: each session loads a mindmap of its own, kept up to
+// date with the changes of the others by the revision of the stored mindmap.
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// ErrMindmapUnavailable is returned by MindmapRefresh for a mindmap deleted or made private by its owner since it
+// was loaded
+var ErrMindmapUnavailable = errors.New("mindmap was deleted or made private by its owner")
+
+// MindmapRefresh reloads a mindmap loaded by user if another session has changed it since, which the stored
+// revision being ahead of the loaded one tells. The mindmap is loaded again with its settings and nodes, as many
+// levels as before for a mindmap loaded a few levels at a time, hiding the private subtrees from users other than
+// the owner. Returns whether it was reloaded, ErrMindmapUnavailable if user can no longer see the mindmap.
+func (m *DataManager) MindmapRefresh(user *model.User, mindmap *model.Mindmap) (bool, error) {
+	ctx := context.Background()
+
+	revision, err := m.MindmapManager.mindmapStore.MindmapRevision(mindmap.ID)
+	if err != nil {
+		return false, err
+	}
+	if revision == mindmap.Revision {
+		return false, nil
+	}
+	m.Logger.Info(ctx, "Mindmap changed by another session, reloading", log.Fields{"mindmapID": mindmap.ID, "loaded": mindmap.Revision, "stored": revision})
+
+	mindmaps, err := m.MindmapManager.MindmapGet(user, model.MindmapInfo{ID: mindmap.ID}, model.MindmapFilter{ID: true})
+	if err != nil {
+		return false, err
+	}
+	if len(mindmaps) == 0 {
+		m.Logger.Warn(ctx, "Changed mindmap no longer available", log.Fields{"mindmapID": mindmap.ID, "username": user.Username})
+		return false, fmt.Errorf("%w: %s", ErrMindmapUnavailable, mindmap.Name)
+	}
+	stored := mindmaps[0]
+	mindmap.Name, mindmap.Owner, mindmap.IsPublic, mindmap.Sort = stored.Name, stored.Owner, stored.IsPublic, stored.Sort
+	mindmap.Updated, mindmap.Revision = stored.Updated, stored.Revision
+
+	if depth := m.Config.LazyLoadDepth; depth > 0 && mindmap.Partial() {
+		err = m.NodeManager.NodeLoadLevels(mindmap, depth)
+	} else {
+		mindmap.Hidden = 0
+		err = m.NodeManager.loadNodes(mindmap)
+	}
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to reload changed mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		// The mindmap stays behind the stored revision, to be reloaded again by the next refresh
+		mindmap.Revision = -1
+		return false, fmt.Errorf("failed to reload mindmap %s: %w", mindmap.Name, err)
+	}
+	if mindmap.Owner != user.Username {
+		m.NodeManager.NodePrivateHide(mindmap)
+	}
+	return true, nil
+}
+
+// MindmapChange runs fn, a change of a loaded mindmap, in a single storage transaction and raises the revision of
+// the mindmap with it, telling the other sessions sharing the mindmap to reload it. If another change raised the
+// revision since the mindmap was loaded or refreshed, nothing of fn is stored and storage.ErrMindmapChanged is
+// returned, the mindmap being left behind the stored revision for MindmapRefresh to reload before fn is tried
+// again. The same applies if fn fails.
+func (m *DataManager) MindmapChange(mindmap *model.Mindmap, fn func() error) error {
+	err := m.store.Batch(func() error {
+		if err := fn(); err != nil {
+			return err
+		}
+		revision, err := m.MindmapManager.mindmapStore.MindmapRevise(mindmap.ID, mindmap.Revision)
+		if err != nil {
+			return err
+		}
+		mindmap.Revision = revision
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, storage.ErrMindmapChanged) {
+		m.Logger.Warn(context.Background(), "Mindmap changed meanwhile, change rolled back", log.Fields{"mindmapID": mindmap.ID, "revision": mindmap.Revision})
+	}
+	// The loaded nodes may hold changes rolled back, whatever the stored revision
+	mindmap.Revision = -1
+	return err
+}
+
+// MindmapRevise records a change of a mindmap already stored, raising its revision so that the other sessions
+// sharing it reload it. A loaded mindmap that was up to date stays so.
+func (mm *MindmapManager) MindmapRevise(mindmap *model.Mindmap) error {
+	store := mm.mindmapStore
+	revision, err := store.MindmapRevision(mindmap.ID)
+	if err != nil {
+		return err
+	}
+	revised, err := store.MindmapRevise(mindmap.ID, revision)
+	if errors.Is(err, storage.ErrMindmapChanged) {
+		// Raised by another change meanwhile, or a mindmap not in storage that has no revision to raise
+		return nil
+	}
+	if err != nil {
+		mm.logger.Error(context.Background(), "Failed to revise mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return fmt.Errorf("failed to revise mindmap: %w", err)
+	}
+	if mindmap.Revision == revision {
+		mindmap.Revision = revised
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the cached node counts and depths of mindmaps.
+package data
+
+import (
+	"context"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// mindmapStats is the cached size of a mindmap. The node count is kept up to date by the node operations; the
+// depth only grows with added nodes and is recomputed on next use after nodes are deleted or moved.
+type mindmapStats struct {
+	model.MindmapStats
+	depthStale bool
+}
+
+// MindmapStats returns the number of nodes and the depth of a mindmap. They are computed from storage on first
+// use, without loading the nodes, and cached, so listing many mindmaps does not walk their trees.
+func (nm *NodeManager) MindmapStats(mindmap *model.Mindmap) (model.MindmapStats, error) {
+	nm.statsMu.Lock()
+	defer nm.statsMu.Unlock()
+
+	stats, ok := nm.stats[mindmap.ID]
+	if ok && !stats.depthStale {
+		return stats.MindmapStats, nil
+	}
+
+	computed, err := nm.nodeStore.NodeStats(mindmap)
+	if err != nil {
+		nm.logger.Error(context.Background(), "Failed to compute mindmap stats", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return model.MindmapStats{}, err
+	}
+	nm.stats[mindmap.ID] = &mindmapStats{MindmapStats: computed}
+	nm.logger.Debug(context.Background(), "Mindmap stats computed", log.Fields{"mindmapID": mindmap.ID, "nodeCount": computed.NodeCount, "depth": computed.Depth})
+	return computed, nil
+}
+
+// statsAdd counts an added node in the cached stats of its mindmap
+func (nm *NodeManager) statsAdd(mindmap *model.Mindmap, node *model.Node) {
+	nm.statsMu.Lock()
+	defer nm.statsMu.Unlock()
+	if stats, ok := nm.stats[mindmap.ID]; ok {
+		stats.NodeCount++
+		stats.Depth = max(stats.Depth, nodeDepth(node))
+	}
+}
+
+// statsRemove uncounts deleted nodes in the cached stats of their mindmap, whose depth may have shrunk
+func (nm *NodeManager) statsRemove(mindmap *model.Mindmap, count int) {
+	nm.statsMu.Lock()
+	defer nm.statsMu.Unlock()
+	if stats, ok := nm.stats[mindmap.ID]; ok {
+		stats.NodeCount -= count
+		stats.depthStale = true
+	}
+}
+
+// statsMove marks the depth of a mindmap whose nodes were moved to other parents for recomputation
+func (nm *NodeManager) statsMove(mindmap *model.Mindmap) {
+	nm.statsMu.Lock()
+	defer nm.statsMu.Unlock()
+	if stats, ok := nm.stats[mindmap.ID]; ok {
+		stats.depthStale = true
+	}
+}
+
+// statsReset drops the cached stats of a mindmap whose nodes were reloaded or deleted
+func (nm *NodeManager) statsReset(mindmap *model.Mindmap) {
+	nm.statsMu.Lock()
+	defer nm.statsMu.Unlock()
+	delete(nm.stats, mindmap.ID)
+}
+
+// nodeDepth returns the level of a node from its index, the root being at level 1
+func nodeDepth(node *model.Node) int {
+	if node.ID == 0 {
+		return 1
+	}
+	return strings.Count(node.Index, ".") + 2
+}
@@ -0,0 +1,52 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the one-page summaries of mindmaps.
+package data
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// MindmapSummary summarizes the subtree of a node of a loaded mindmap, the whole mindmap if node is nil: its size,
+// the branches down to depth levels below the node in the order of spec and its dated nodes, soonest first so the
+// overdue ones lead.
+func (m *DataManager) MindmapSummary(mindmap *model.Mindmap, node *model.Node, depth int, spec model.SortSpec) model.MindmapSummary {
+	if node == nil {
+		node = mindmap.Root
+	}
+	summary := model.MindmapSummary{Mindmap: mindmap, Node: node, Created: time.Now()}
+
+	// Each node counts in the subtree of all its ancestors up to the summarized node
+	counts := make(map[int]int)
+	for level, n := range mindmap.Walk(node, nil) {
+		summary.Stats.NodeCount++
+		summary.Stats.Depth = max(summary.Stats.Depth, level+1)
+		if len(n.Content) > 0 {
+			summary.Fields++
+		}
+		if at, field, ok := model.ReminderTime(n.Content); ok {
+			summary.Dated = append(summary.Dated, model.SummaryItem{Node: n, Field: field, At: at})
+			if at.Before(summary.Created) {
+				summary.Overdue++
+			}
+		}
+		for a := n; a != nil && a.ID != node.ID; a = mindmap.Nodes[a.ParentID] {
+			counts[a.ID]++
+		}
+	}
+
+	for level, n := range mindmap.SortedWalk(node, spec) {
+		if level > 0 && level <= depth {
+			summary.Branches = append(summary.Branches, model.SummaryBranch{Node: n, Depth: level, Count: counts[n.ID]})
+		}
+	}
+
+	slices.SortStableFunc(summary.Dated, func(a, b model.SummaryItem) int { return a.At.Compare(b.At) })
+
+	m.Logger.Debug(context.Background(), "Mindmap summarized", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "nodes": summary.Stats.NodeCount, "branches": len(summary.Branches), "dated": len(summary.Dated)})
+	return summary
+}
@@ -0,0 +1,191 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the attachments of nodes, files stored apart from the content fields and references to web
+// addresses.
+package data
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// maxAttachmentSize is the size of the largest file that can be attached to a node
+const maxAttachmentSize = 16 << 20
+
+// AttachmentRead returns the attachment for a source given to the node attach command: a reference for an http or
+// https address, otherwise the file of that name in the export directory with its data
+func (m *DataManager) AttachmentRead(source string) (model.Attachment, error) {
+	ctx := context.Background()
+
+	if u, err := url.Parse(source); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		if u.Host == "" {
+			return model.Attachment{}, fmt.Errorf("invalid address: %s", source)
+		}
+		return model.Attachment{Name: source, URL: source}, nil
+	}
+
+	path, err := m.ImportPath(source)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		m.Logger.Warn(ctx, "Attachment file not readable", log.Fields{"error": err, "path": path})
+		return model.Attachment{}, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	if !info.Mode().IsRegular() {
+		return model.Attachment{}, fmt.Errorf("%s is not a file", source)
+	}
+	if info.Size() > maxAttachmentSize {
+		m.Logger.Warn(ctx, "Attachment file too large", log.Fields{"path": path, "size": info.Size()})
+		return model.Attachment{}, fmt.Errorf("%s is larger than the %d MiB allowed for an attachment", source, maxAttachmentSize>>20)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to read attachment file", log.Fields{"error": err, "path": path})
+		return model.Attachment{}, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	name := filepath.Base(path)
+	mediaType := mime.TypeByExtension(filepath.Ext(name))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+	}
+	return model.Attachment{Name: name, MediaType: mediaType, Size: int64(len(data)), Data: data}, nil
+}
+
+// NodeAttachmentAdd attaches a file or a reference to a node. Returns false if the node has an attachment of the
+// same name already, which is left as it is.
+func (nm *NodeManager) NodeAttachmentAdd(mindmap *model.Mindmap, node *model.Node, attachment model.Attachment) (bool, error) {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Adding node attachment", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "name": attachment.Name})
+
+	existing, err := nm.NodeAttachments(mindmap, node, false)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range existing {
+		if a.Name == attachment.Name {
+			return false, nil
+		}
+	}
+
+	if _, err := nm.nodeStore.NodeAttachmentAdd(mindmap, node, attachment); err != nil {
+		nm.logger.Error(ctx, "Failed to add node attachment in storage", log.Fields{"error": err, "nodeID": node.ID})
+		return false, fmt.Errorf("failed to add node attachment in storage: %w", err)
+	}
+	nodesTouched(mindmap, node.ID)
+
+	nm.logger.Info(ctx, "Node attachment added", log.Fields{"nodeID": node.ID, "name": attachment.Name})
+	return true, nil
+}
+
+// NodeAttachments returns the attachments of a node in the order they were added, or those of all nodes of the
+// mindmap if node is nil, with the data of stored files if withData is set
+func (nm *NodeManager) NodeAttachments(mindmap *model.Mindmap, node *model.Node, withData bool) ([]*model.Attachment, error) {
+	attachments, err := nm.nodeStore.NodeAttachmentGet(mindmap, node, withData)
+	if err != nil {
+		nm.logger.Error(context.Background(), "Failed to get node attachments", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to get node attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// NodeAttachmentDelete removes an attachment from a node. Returns false if the node has no attachment of that name.
+func (nm *NodeManager) NodeAttachmentDelete(mindmap *model.Mindmap, node *model.Node, name string) (bool, error) {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Deleting node attachment", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "name": name})
+
+	deleted, err := nm.nodeStore.NodeAttachmentDelete(mindmap, node, name)
+	if err != nil {
+		nm.logger.Error(ctx, "Failed to delete node attachment in storage", log.Fields{"error": err, "nodeID": node.ID})
+		return false, fmt.Errorf("failed to delete node attachment in storage: %w", err)
+	}
+	if deleted {
+		nodesTouched(mindmap, node.ID)
+	}
+	return deleted, nil
+}
+
+// exportAttachments returns the attachments of the nodes of an exported mindmap, stored files carried in base64.
+// Attachments of nodes left out of the export are left out.
+func (m *DataManager) exportAttachments(mindmap *model.Mindmap, exported *model.Mindmap) ([]model.MindmapAttachment, error) {
+	attachments, err := m.NodeManager.NodeAttachments(mindmap, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var exportedAttachments []model.MindmapAttachment
+	for _, a := range attachments {
+		if exported.Nodes[a.NodeID] == nil {
+			continue
+		}
+		exportedAttachments = append(exportedAttachments, model.MindmapAttachment{
+			NodeID:    a.NodeID,
+			Name:      a.Name,
+			URL:       a.URL,
+			MediaType: a.MediaType,
+			Data:      base64.StdEncoding.EncodeToString(a.Data),
+		})
+	}
+	return exportedAttachments, nil
+}
+
+// importAttachments adds the attachments of an imported mindmap to the nodes it was stored or merged into, their
+// IDs mapped by ids or kept if ids is nil. Attachments of nodes missing from the mindmap or with invalid data are
+// skipped, returned as warnings; those a node has already are left as they are.
+func (m *DataManager) importAttachments(mindmap *model.Mindmap, attachments []model.MindmapAttachment, ids map[int]int) ([]string, error) {
+	ctx := context.Background()
+
+	var warnings []string
+	for _, a := range attachments {
+		nodeID, ok := a.NodeID, mindmap.Nodes[a.NodeID] != nil
+		if ids != nil {
+			nodeID, ok = ids[a.NodeID]
+		}
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("attachment %s of node %d skipped, the file has no such node", a.Name, a.NodeID))
+			continue
+		}
+
+		attachment, err := importedAttachment(a)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("attachment %s of node %d skipped, %v", a.Name, a.NodeID, err))
+			continue
+		}
+		if _, err := m.NodeManager.NodeAttachmentAdd(mindmap, mindmap.Nodes[nodeID], attachment); err != nil {
+			return nil, err
+		}
+	}
+
+	m.Logger.Debug(ctx, "Attachments of imported mindmap added", log.Fields{"mindmapID": mindmap.ID, "attachments": len(attachments), "skipped": len(warnings)})
+	return warnings, nil
+}
+
+// importedAttachment returns the attachment of an imported mindmap as stored, its data decoded
+func importedAttachment(a model.MindmapAttachment) (model.Attachment, error) {
+	if strings.TrimSpace(a.Name) == "" {
+		return model.Attachment{}, errors.New("it has no name")
+	}
+	if a.URL != "" {
+		return model.Attachment{Name: a.Name, URL: a.URL}, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(a.Data))
+	if err != nil {
+		return model.Attachment{}, fmt.Errorf("its data is not valid base64: %w", err)
+	}
+	if len(data) > maxAttachmentSize {
+		return model.Attachment{}, fmt.Errorf("it is larger than the %d MiB allowed", maxAttachmentSize>>20)
+	}
+	return model.Attachment{Name: a.Name, MediaType: a.MediaType, Size: int64(len(data)), Data: data}, nil
+}
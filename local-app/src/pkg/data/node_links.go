@@ -0,0 +1,106 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the links of exported and imported mindmaps.
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// exportLinks returns the stored links from the nodes of an exported mindmap to its own nodes, or to the nodes of
+// the other mindmaps accessible to user, named by their mindmap. Links from or to nodes left out of the export and
+// to mindmaps the user can't see are left out.
+func (m *DataManager) exportLinks(user *model.User, exported *model.Mindmap) ([]model.MindmapLink, error) {
+	ctx := context.Background()
+
+	links, err := m.LinkManager.LinkGetMindmap(exported)
+	if err != nil {
+		return nil, err
+	}
+
+	// Names of the other mindmaps linked to, empty for those not accessible
+	names := make(map[int]string)
+	var exportedLinks []model.MindmapLink
+	for _, link := range links {
+		if exported.Nodes[link.SourceID] == nil {
+			continue
+		}
+		exportedLink := model.MindmapLink{SourceID: link.SourceID, TargetID: link.TargetID, Type: link.Type}
+		if link.TargetMindmapID == exported.ID {
+			if exported.Nodes[link.TargetID] == nil {
+				continue
+			}
+		} else {
+			name, ok := names[link.TargetMindmapID]
+			if !ok {
+				mindmaps, err := m.MindmapManager.MindmapGet(user, model.MindmapInfo{ID: link.TargetMindmapID}, model.MindmapFilter{ID: true})
+				if err != nil {
+					return nil, err
+				}
+				if len(mindmaps) > 0 {
+					name = mindmaps[0].Name
+				}
+				names[link.TargetMindmapID] = name
+			}
+			if name == "" {
+				continue
+			}
+			exportedLink.TargetMindmap = name
+		}
+		exportedLinks = append(exportedLinks, exportedLink)
+	}
+
+	m.Logger.Debug(ctx, "Links of exported mindmap collected", log.Fields{"mindmapID": exported.ID, "links": len(exportedLinks)})
+	return exportedLinks, nil
+}
+
+// importLinks adds the links of an imported mindmap from the nodes it was stored or merged into, their IDs mapped
+// by ids or kept if ids is nil. Links whose nodes are missing from the mindmap or whose mindmap is not accessible
+// to user are skipped, returned as warnings.
+func (m *DataManager) importLinks(user *model.User, mindmap *model.Mindmap, links []model.MindmapLink, ids map[int]int) ([]string, error) {
+	ctx := context.Background()
+
+	mapped := func(id int) (int, bool) {
+		if ids == nil {
+			return id, mindmap.Nodes[id] != nil
+		}
+		mappedID, ok := ids[id]
+		return mappedID, ok
+	}
+
+	var warnings []string
+	for _, link := range links {
+		sourceID, ok := mapped(link.SourceID)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("link from node %d skipped, the file has no such node", link.SourceID))
+			continue
+		}
+		stored := model.Link{SourceMindmapID: mindmap.ID, SourceID: sourceID, TargetMindmapID: mindmap.ID, TargetID: link.TargetID, Type: link.Type}
+		if link.TargetMindmap == "" {
+			if stored.TargetID, ok = mapped(link.TargetID); !ok {
+				warnings = append(warnings, fmt.Sprintf("link from node %d to node %d skipped, the file has no such node", link.SourceID, link.TargetID))
+				continue
+			}
+		} else {
+			mindmaps, err := m.MindmapManager.MindmapGet(user, model.MindmapInfo{Name: link.TargetMindmap}, model.MindmapFilter{Name: true})
+			if err != nil {
+				return nil, err
+			}
+			if len(mindmaps) == 0 {
+				warnings = append(warnings, fmt.Sprintf("link from node %d to mindmap %s skipped, no such mindmap", link.SourceID, link.TargetMindmap))
+				continue
+			}
+			stored.TargetMindmapID = mindmaps[0].ID
+		}
+
+		if _, err := m.LinkManager.LinkCreate(stored); err != nil {
+			return nil, err
+		}
+	}
+
+	m.Logger.Debug(ctx, "Links of imported mindmap added", log.Fields{"mindmapID": mindmap.ID, "links": len(links), "skipped": len(warnings)})
+	return warnings, nil
+}
@@ -4,10 +4,13 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sort"
+	"maps"
+	"slices"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
 	"mindnoscape/local-app/src/pkg/event"
 	"mindnoscape/local-app/src/pkg/log"
@@ -26,15 +29,39 @@ type NodeOperations interface {
 	NodeDelete(node *model.NodeInfo, nodeFilter model.NodeFilter) error
 }
 
+// nodeOperationCost is the approximate time an operation spends per node, used to estimate the duration of large operations
+var nodeOperationCost = map[string]time.Duration{
+	"sort":   time.Millisecond,
+	"delete": 2 * time.Millisecond,
+	"copy":   2 * time.Millisecond,
+	"export": 100 * time.Microsecond,
+}
+
 // NodeManager handles all node-related operations within a mindmap.
 type NodeManager struct {
-	nodeStore    storage.NodeStore
-	eventManager *event.EventManager
-	logger       *log.Logger
+	nodeStore        storage.NodeStore
+	eventManager     *event.EventManager
+	largeOpThreshold int
+	reindexThreshold int
+	logger           *log.Logger
+
+	backlinksMu sync.Mutex
+	backlinks   map[int]*backlinkIndex // Backlink index per mindmap ID, built on first use
+
+	childIndexMu sync.Mutex
+	childIndexes map[int]*childIndexes // Highest child indexes and name counts per mindmap ID, queried on first use
+
+	statsMu sync.Mutex
+	stats   map[int]*mindmapStats // Node count and depth per mindmap ID, computed on first use
+
+	reindexMu    sync.Mutex
+	reindexEdits map[int]int // Deletes and moves per mindmap ID since its indexes were last rebuilt
 }
 
 // NewNodeManager creates a new NodeManager instance.
-func NewNodeManager(nodeStore storage.NodeStore, eventManager *event.EventManager, logger *log.Logger) (*NodeManager, error) {
+// Operations on subtrees with more nodes than largeOpThreshold must be forced, a threshold of 0 or less disables the check.
+// The indexes of a mindmap are rebuilt after reindexThreshold deletes and moves, a threshold of 0 or less disables it.
+func NewNodeManager(nodeStore storage.NodeStore, eventManager *event.EventManager, largeOpThreshold int, reindexThreshold int, logger *log.Logger) (*NodeManager, error) {
 	ctx := context.Background()
 	logger.Info(ctx, "Creating new NodeManager", nil)
 
@@ -51,9 +78,15 @@ func NewNodeManager(nodeStore storage.NodeStore, eventManager *event.EventManage
 	}
 
 	nm := &NodeManager{
-		nodeStore:    nodeStore,
-		eventManager: eventManager,
-		logger:       logger,
+		nodeStore:        nodeStore,
+		eventManager:     eventManager,
+		largeOpThreshold: largeOpThreshold,
+		reindexThreshold: reindexThreshold,
+		logger:           logger,
+		backlinks:        make(map[int]*backlinkIndex),
+		childIndexes:     make(map[int]*childIndexes),
+		stats:            make(map[int]*mindmapStats),
+		reindexEdits:     make(map[int]int),
 	}
 
 	logger.Info(ctx, "NodeManager created successfully", nil)
@@ -73,6 +106,20 @@ func (nm *NodeManager) handleMindmapAdded(e event.Event) {
 
 	nm.logger.Debug(ctx, "Handling MindmapAdded event", log.Fields{"mindmapID": mindmap.ID})
 
+	// Skip if the root node already exists, e.g. for a duplicate or replayed event
+	existingRoots, err := nm.nodeStore.NodeGet(mindmap, model.NodeInfo{ID: 0}, model.NodeFilter{ID: true})
+	if err != nil {
+		nm.logger.Error(ctx, "Failed to check root node for mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return
+	}
+	if len(existingRoots) > 0 {
+		nm.logger.Warn(ctx, "Root node already exists, skipping creation", log.Fields{"mindmapID": mindmap.ID, "eventID": e.ID})
+		if mindmap.Root == nil {
+			mindmap.Root = existingRoots[0]
+		}
+		return
+	}
+
 	rootNodeInfo := model.NodeInfo{
 		ID:        0,
 		MindmapID: mindmap.ID,
@@ -81,7 +128,7 @@ func (nm *NodeManager) handleMindmapAdded(e event.Event) {
 		Index:     "0",
 	}
 
-	_, _, err := nm.NodeAdd(mindmap, rootNodeInfo, true)
+	_, _, err = nm.NodeAdd(mindmap, rootNodeInfo, true)
 	if err != nil {
 		nm.logger.Error(ctx, "Failed to add root node for mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
 		return
@@ -101,11 +148,17 @@ func (nm *NodeManager) handleMindmapSelected(e event.Event) {
 		return
 	}
 
+	if err := nm.loadNodes(mindmap); err != nil {
+		nm.logger.Error(ctx, "Failed to fetch nodes for mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+	}
+}
+
+// loadNodes replaces the in-memory nodes of a mindmap with the nodes in storage
+func (nm *NodeManager) loadNodes(mindmap *model.Mindmap) error {
 	// Fetch all nodes for the mindmap
 	nodes, err := nm.NodeGet(mindmap, model.NodeInfo{}, model.NodeFilter{})
 	if err != nil {
-		nm.logger.Error(ctx, "Failed to fetch nodes for mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
-		return
+		return err
 	}
 
 	// Populate the Nodes map
@@ -116,11 +169,17 @@ func (nm *NodeManager) handleMindmapSelected(e event.Event) {
 			mindmap.Root = node
 		}
 	}
+	mindmap.Unloaded = nil
+	mindmap.LinkChildren()
+	nm.backlinksReset(mindmap)
+	nm.statsReset(mindmap)
+	nm.childIndexReset(mindmap)
 
-	nm.logger.Info(ctx, "Nodes loaded for mindmap", log.Fields{"mindmapID": mindmap.ID, "nodeCount": len(nodes)})
+	nm.logger.Info(context.Background(), "Nodes loaded for mindmap", log.Fields{"mindmapID": mindmap.ID, "nodeCount": len(nodes)})
+	return nil
 }
 
-// handleMindmapDeleted deletes all the nodes before a mindmap is added
+// handleMindmapDeleted clears the nodes of a deleted mindmap, whose tables were dropped with it
 func (nm *NodeManager) handleMindmapDeleted(e event.Event) {
 	ctx := context.Background()
 	nm.logger.Info(ctx, "Handling MindmapDeleted event", nil)
@@ -131,32 +190,12 @@ func (nm *NodeManager) handleMindmapDeleted(e event.Event) {
 		return
 	}
 
-	// Get the root node
-	rootNodes, err := nm.NodeGet(mindmap, model.NodeInfo{ID: 0}, model.NodeFilter{ID: true})
-	if err != nil || len(rootNodes) == 0 {
-		nm.logger.Error(ctx, "Failed to get root node for mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
-		return
-	}
-	rootNode := rootNodes[0]
-
-	// Delete all children of the root node
-	for _, child := range rootNode.Children {
-		err := nm.NodeDelete(mindmap, child)
-		if err != nil {
-			nm.logger.Error(ctx, "Failed to delete child node", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": child.ID})
-		}
-	}
-
-	// Delete the root node with direct storage call since NodeDelete prevents deleting the root node
-	err = nm.nodeStore.NodeDelete(mindmap, rootNode)
-	if err != nil {
-		nm.logger.Error(ctx, "Failed to delete root node", log.Fields{"error": err, "mindmapID": mindmap.ID})
-	}
-
-	// Clear the nodes map in the mindmap
 	mindmap.Nodes = make(map[int]*model.Node)
+	nm.backlinksReset(mindmap)
+	nm.statsReset(mindmap)
+	nm.childIndexReset(mindmap)
 
-	nm.logger.Info(ctx, "All nodes deleted for mindmap", log.Fields{"mindmapID": mindmap.ID})
+	nm.logger.Info(ctx, "All nodes cleared for deleted mindmap", log.Fields{"mindmapID": mindmap.ID})
 }
 
 // handleMindmapUpdated updates the root node name when a mindmap is renamed
@@ -192,6 +231,12 @@ func (nm *NodeManager) handleMindmapUpdated(e event.Event) {
 		}
 		rootNode := rootNodes[0]
 
+		// Skip if the root node already carries the new name, e.g. for a duplicate or replayed event
+		if rootNode.Name == mindmap.Name {
+			nm.logger.Debug(ctx, "Root node name already up to date", log.Fields{"mindmapID": mindmap.ID, "eventID": e.ID})
+			return
+		}
+
 		// Update the root node name
 		err = nm.NodeUpdate(mindmap, rootNode, model.NodeInfo{Name: mindmap.Name}, model.NodeFilter{Name: true})
 		if err != nil {
@@ -202,78 +247,58 @@ func (nm *NodeManager) handleMindmapUpdated(e event.Event) {
 	}
 }
 
-// NodeAdd adds a new node to the current mindmap.
+// NodeAdd adds a new node to the current mindmap. Only the index of the new node is computed, after the highest
+// index of its siblings; the indexes of the other nodes are left as they are.
 func (nm *NodeManager) NodeAdd(mindmap *model.Mindmap, nodeInfo model.NodeInfo, forceID ...bool) (int, int, error) {
 	ctx := context.Background()
 	nm.logger.Info(ctx, "Adding new node", log.Fields{"mindmapID": mindmap.ID, "parentID": nodeInfo.ParentID})
 
-	// Validate node info
+	// Validate node info, the loaded parent is also the base of the index of the new node
+	var parentNode *model.Node
 	if nodeInfo.ParentID != -1 { // If not root
-		parentNodes, err := nm.nodeStore.NodeGet(mindmap, model.NodeInfo{ID: nodeInfo.ParentID}, model.NodeFilter{ID: true})
-		if err != nil {
-			nm.logger.Error(ctx, "Failed to query parent node", log.Fields{"error": err, "parentID": nodeInfo.ParentID})
-			return 0, 0, fmt.Errorf("failed to query parent node: %w", err)
-		}
-		if len(parentNodes) == 0 {
+		var exists bool
+		parentNode, exists = mindmap.Nodes[nodeInfo.ParentID]
+		if !exists {
 			nm.logger.Warn(ctx, "Parent node not found", log.Fields{"parentID": nodeInfo.ParentID})
 			return 0, 0, fmt.Errorf("parent node not found: ID %d", nodeInfo.ParentID)
 		}
-		nm.logger.Debug(ctx, "Parent node found", log.Fields{"parentNode": parentNodes[0]})
+		nm.logger.Debug(ctx, "Parent node found", log.Fields{"parentNode": parentNode})
 	}
+	if err := validateFieldValues(nodeInfo.Content); err != nil {
+		nm.logger.Warn(ctx, "Invalid node field value", log.Fields{"error": err})
+		return 0, 0, err
+	}
+	tags, tagErr := normalizeTags(nodeInfo.Tags)
+	if tagErr != nil {
+		nm.logger.Warn(ctx, "Invalid node tag", log.Fields{"error": tagErr})
+		return 0, 0, tagErr
+	}
+	nodeInfo.Tags = tags
 
 	nm.logger.Debug(ctx, "Node validation complete", nil)
 
-	// Count nodes with the same name
-	existingNodes, err := nm.nodeStore.NodeGet(mindmap, nodeInfo, model.NodeFilter{Name: true})
+	// Count the stored nodes with the same name, of which only some may be loaded
+	copies, err := nm.nodeCopies(mindmap, nodeInfo.Name)
 	if err != nil {
-		nm.logger.Error(ctx, "Failed to get existing nodes", log.Fields{"error": err})
-		return 0, 0, fmt.Errorf("failed to get existing nodes: %w", err)
-	}
-	copies := 0
-	for _, node := range existingNodes {
-		if node.Name == nodeInfo.Name {
-			copies++
-		}
+		return 0, 0, err
 	}
 	nm.logger.Debug(ctx, "Node count complete", log.Fields{"copies": copies})
 
 	// Assign index
+	childIndex := 0
 	if nodeInfo.ParentID == -1 {
 		// For root node, use "0" as index
 		nodeInfo.Index = "0"
 	} else {
-		// Get the parent node first
-		parentNodes, err := nm.nodeStore.NodeGet(mindmap, model.NodeInfo{ID: nodeInfo.ParentID}, model.NodeFilter{ID: true})
-		if err != nil || len(parentNodes) == 0 {
-			nm.logger.Error(ctx, "Failed to get parent node for index calculation", log.Fields{"error": err, "parentID": nodeInfo.ParentID})
-			return 0, 0, fmt.Errorf("failed to get parent node for index calculation: %w", err)
-		}
-		parentNode := parentNodes[0]
-
-		// Find the highest index among siblings
-		siblings, err := nm.nodeStore.NodeGet(mindmap, model.NodeInfo{ParentID: nodeInfo.ParentID}, model.NodeFilter{ParentID: true})
+		// Follow the highest index among siblings
+		childIndex, err = nm.nextChildIndex(mindmap, nodeInfo.ParentID)
 		if err != nil {
-			nm.logger.Error(ctx, "Failed to get sibling nodes", log.Fields{"error": err, "parentID": nodeInfo.ParentID})
-			return 0, 0, fmt.Errorf("failed to get sibling nodes: %w", err)
-		}
-
-		highestIndex := 0
-		for _, sibling := range siblings {
-			parts := strings.Split(sibling.Index, ".")
-			if len(parts) > 0 {
-				if lastPart, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
-					if lastPart > highestIndex {
-						highestIndex = lastPart
-					}
-				}
-			}
+			return 0, 0, err
 		}
-
-		// Increment the highest index for the new node
 		if parentNode.ParentID == -1 { // If parent is root
-			nodeInfo.Index = fmt.Sprintf("%d", highestIndex+1)
+			nodeInfo.Index = fmt.Sprintf("%d", childIndex)
 		} else {
-			nodeInfo.Index = fmt.Sprintf("%s.%d", parentNode.Index, highestIndex+1)
+			nodeInfo.Index = fmt.Sprintf("%s.%d", parentNode.Index, childIndex)
 		}
 	}
 	nm.logger.Debug(ctx, "Index calculation complete", log.Fields{"index": nodeInfo.Index})
@@ -291,6 +316,7 @@ func (nm *NodeManager) NodeAdd(mindmap *model.Mindmap, nodeInfo model.NodeInfo,
 		return newID, copies, fmt.Errorf("failed to add node to storage: %w", err)
 	}
 	copies++
+	nm.childIndexAdd(mindmap, nodeInfo.ParentID, childIndex, nodeInfo.Name, copies)
 	nm.logger.Debug(ctx, "Node added to storage", log.Fields{"newID": newID})
 
 	// Get the newly created node
@@ -301,12 +327,7 @@ func (nm *NodeManager) NodeAdd(mindmap *model.Mindmap, nodeInfo model.NodeInfo,
 	newNode := newNodes[0]
 
 	// Update in-memory structure
-	if nodeInfo.ParentID != -1 {
-		parentNode, exists := mindmap.Nodes[nodeInfo.ParentID]
-		if !exists {
-			nm.logger.Error(ctx, "Parent node not found in memory", log.Fields{"parentID": nodeInfo.ParentID})
-			return newID, copies, fmt.Errorf("parent node not found in memory: %d", nodeInfo.ParentID)
-		}
+	if parentNode != nil {
 		parentNode.Children = append(parentNode.Children, newNode)
 	}
 
@@ -324,6 +345,17 @@ func (nm *NodeManager) NodeAdd(mindmap *model.Mindmap, nodeInfo model.NodeInfo,
 	if newID == 0 {
 		mindmap.Root = newNode
 	}
+	nm.backlinksUpdate(mindmap, newNode)
+	nm.statsAdd(mindmap, newNode)
+
+	// Publish NodeAdded event
+	nm.eventManager.Publish(event.Event{
+		Type: event.NodeAdded,
+		Data: map[string]interface{}{
+			"mindmap": mindmap,
+			"node":    newNode,
+		},
+	})
 
 	nm.logger.Info(ctx, "Node added successfully", log.Fields{"nodeID": newID, "mindmapID": mindmap.ID})
 	return newID, copies, nil
@@ -344,6 +376,9 @@ func (nm *NodeManager) NodeGet(mindmap *model.Mindmap, nodeInfo model.NodeInfo,
 		nm.logger.Error(ctx, "Failed to get nodes", log.Fields{"error": err, "mindmapID": mindmap.ID})
 		return nil, fmt.Errorf("failed to get nodes: %w", err)
 	}
+	if mindmap.Hidden > 0 {
+		nodes = slices.DeleteFunc(nodes, func(node *model.Node) bool { return mindmap.Nodes[node.ID] == nil })
+	}
 
 	if len(nodes) == 0 {
 		nm.logger.Debug(ctx, "No nodes found", log.Fields{"filter": nodeFilter})
@@ -362,10 +397,13 @@ func (nm *NodeManager) NodeToInfo(node *model.Node) model.NodeInfo {
 		Name:      node.Name,
 		Index:     node.Index,
 		Content:   node.Content,
+		Tags:      node.Tags,
 	}
 }
 
-// NodeFind searches for nodes in the mindmap based on a query string
+// NodeFind searches for nodes in the mindmap with a query, see model.ParseNodeQuery. Terms without a scope search
+// the parts of the nodes selected by nodeFilter. Queries the full-text index of the store can run are searched in
+// it and return the best matches first, others are matched against the loaded nodes in document order.
 func (nm *NodeManager) NodeFind(mindmap *model.Mindmap, nodeFilter model.NodeFilter, query string) ([]*model.Node, error) {
 	ctx := context.Background()
 	nm.logger.Info(ctx, "Searching for nodes", log.Fields{"mindmapID": mindmap.ID, "query": query})
@@ -376,49 +414,235 @@ func (nm *NodeManager) NodeFind(mindmap *model.Mindmap, nodeFilter model.NodeFil
 		return nil, fmt.Errorf("mindmap not specified")
 	}
 
-	// Fetch all nodes for the mindmap
-	allNodes, err := nm.NodeGet(mindmap, model.NodeInfo{}, model.NodeFilter{})
+	nodeQuery, err := model.ParseNodeQuery(query)
 	if err != nil {
-		nm.logger.Error(ctx, "Failed to get nodes", log.Fields{"error": err, "mindmapID": mindmap.ID})
-		return nil, fmt.Errorf("failed to get nodes: %w", err)
+		nm.logger.Warn(ctx, "Invalid node query", log.Fields{"error": err, "query": query})
+		return nil, fmt.Errorf("invalid query: %w", err)
 	}
 
-	// Search for matches based on the filter
-	var matches []*model.Node
-	lowerQuery := strings.ToLower(query)
-
-	for _, node := range allNodes {
-		if nodeFilter.Name && strings.Contains(strings.ToLower(node.Name), lowerQuery) {
-			matches = append(matches, node)
-			continue
-		}
-		if nodeFilter.Content {
-			contentMatch := false
-			for key, value := range node.Content {
-				if strings.Contains(strings.ToLower(key), lowerQuery) || strings.Contains(strings.ToLower(value), lowerQuery) {
-					contentMatch = true
-					break
-				}
-			}
-			if contentMatch {
+	// Search the index of the store if it has one, best matches first, leaving out the nodes not loaded such as
+	// those hidden from the user
+	ids, err := nm.nodeStore.NodeSearch(mindmap, nodeQuery, nodeFilter)
+	switch {
+	case err == nil:
+		var matches []*model.Node
+		for _, id := range ids {
+			if node, ok := mindmap.Nodes[id]; ok {
 				matches = append(matches, node)
-				continue
 			}
 		}
-		if nodeFilter.Index && strings.Contains(node.Index, query) {
-			matches = append(matches, node)
-			continue
-		}
+		nm.logger.Info(ctx, "Node search completed in storage", log.Fields{"matchCount": len(matches)})
+		return matches, nil
+	case !errors.Is(err, storage.ErrSearchUnsupported):
+		nm.logger.Error(ctx, "Failed to search nodes in storage", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to search nodes: %w", err)
+	}
+
+	// Otherwise search for matches based on the filter, in document order
+	match := func(node *model.Node) bool {
+		return nodeQuery.Match(node, nodeFilter)
 	}
+	matches := slices.Collect(mindmap.Subtree(nil, match))
 
 	nm.logger.Info(ctx, "Node search completed", log.Fields{"matchCount": len(matches)})
 	return matches, nil
 }
 
-// NodeSort sorts the children of a node based on a given field
-func (nm *NodeManager) NodeSort(mindmap *model.Mindmap, nodeInfo model.NodeInfo, field string, reverse bool) error {
+// NodeFieldRename renames the content field oldKey to newKey in the subtree of a node, or in the whole mindmap if
+// node is nil, and returns the changed nodes in document order. Nodes that already have a newKey field are not
+// changed and returned separately as conflicts. Nothing is changed on a dry run.
+func (nm *NodeManager) NodeFieldRename(mindmap *model.Mindmap, node *model.Node, oldKey, newKey string, dryRun bool) ([]*model.Node, []*model.Node, error) {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Renaming content field", log.Fields{"mindmapID": mindmap.ID, "oldKey": oldKey, "newKey": newKey, "dryRun": dryRun})
+
+	var changed, conflicts []*model.Node
+	for n := range mindmap.Subtree(node, hasField(oldKey)) {
+		if _, exists := n.Content[newKey]; exists {
+			conflicts = append(conflicts, n)
+			continue
+		}
+		changed = append(changed, n)
+	}
+	if dryRun {
+		return changed, conflicts, nil
+	}
+
+	for _, n := range changed {
+		content := map[string]string{oldKey: "", newKey: n.Content[oldKey]}
+		if err := nm.nodeFieldUpdate(mindmap, n, content); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	nm.logger.Info(ctx, "Content field renamed", log.Fields{"mindmapID": mindmap.ID, "nodeCount": len(changed), "conflictCount": len(conflicts)})
+	return changed, conflicts, nil
+}
+
+// NodeFieldDelete removes the content field key from the subtree of a node, or from the whole mindmap if node is nil,
+// and returns the changed nodes in document order. Nothing is changed on a dry run.
+func (nm *NodeManager) NodeFieldDelete(mindmap *model.Mindmap, node *model.Node, key string, dryRun bool) ([]*model.Node, error) {
 	ctx := context.Background()
-	nm.logger.Info(ctx, "Sorting nodes", log.Fields{"mindmapID": mindmap.ID, "nodeID": nodeInfo.ID, "field": field, "reverse": reverse})
+	nm.logger.Info(ctx, "Deleting content field", log.Fields{"mindmapID": mindmap.ID, "key": key, "dryRun": dryRun})
+
+	changed := slices.Collect(mindmap.Subtree(node, hasField(key)))
+	if dryRun {
+		return changed, nil
+	}
+
+	for _, n := range changed {
+		if err := nm.nodeFieldUpdate(mindmap, n, map[string]string{key: ""}); err != nil {
+			return nil, err
+		}
+	}
+
+	nm.logger.Info(ctx, "Content field deleted", log.Fields{"mindmapID": mindmap.ID, "nodeCount": len(changed)})
+	return changed, nil
+}
+
+// nodeFieldUpdate applies changes to the content fields of a node, an empty value removes a field.
+// The storage replaces all fields, so the unchanged fields are passed along.
+func (nm *NodeManager) nodeFieldUpdate(mindmap *model.Mindmap, node *model.Node, changes map[string]string) error {
+	content := maps.Clone(node.Content)
+	maps.Copy(content, changes)
+	if err := nm.NodeUpdate(mindmap, node, model.NodeInfo{Content: content}, model.NodeFilter{Content: true}); err != nil {
+		return fmt.Errorf("failed to update node %s: %w", node.Index, err)
+	}
+	return nil
+}
+
+// hasField matches the nodes with a content field key
+func hasField(key string) model.NodeMatch {
+	return func(n *model.Node) bool {
+		_, ok := n.Content[key]
+		return ok
+	}
+}
+
+// NodeBacklinks returns the nodes referring to a node with a [[name]] or [[#id]] reference in their name or
+// content, in document order
+func (nm *NodeManager) NodeBacklinks(mindmap *model.Mindmap, node *model.Node) []*model.Node {
+	nm.backlinksMu.Lock()
+	referring := nm.backlinkIndex(mindmap).referring(node)
+	nm.backlinksMu.Unlock()
+
+	return slices.Collect(mindmap.Subtree(nil, func(n *model.Node) bool { return referring[n.ID] }))
+}
+
+// backlinkIndex returns the backlink index of a mindmap, building it from the loaded nodes if needed.
+// The caller must hold backlinksMu.
+func (nm *NodeManager) backlinkIndex(mindmap *model.Mindmap) *backlinkIndex {
+	index, ok := nm.backlinks[mindmap.ID]
+	if !ok {
+		index = newBacklinkIndex(mindmap)
+		nm.backlinks[mindmap.ID] = index
+		nm.logger.Debug(context.Background(), "Backlink index built", log.Fields{"mindmapID": mindmap.ID, "referringNodes": len(index.refs)})
+	}
+	return index
+}
+
+// backlinksUpdate updates the references of a changed node in a built backlink index
+func (nm *NodeManager) backlinksUpdate(mindmap *model.Mindmap, node *model.Node) {
+	nm.backlinksMu.Lock()
+	defer nm.backlinksMu.Unlock()
+	if index, ok := nm.backlinks[mindmap.ID]; ok {
+		index.update(node)
+	}
+}
+
+// backlinksRemove removes the references of a deleted node from a built backlink index
+func (nm *NodeManager) backlinksRemove(mindmap *model.Mindmap, node *model.Node) {
+	nm.backlinksMu.Lock()
+	defer nm.backlinksMu.Unlock()
+	if index, ok := nm.backlinks[mindmap.ID]; ok {
+		index.remove(node.ID)
+	}
+}
+
+// backlinksReset drops the backlink index of a mindmap whose nodes were reloaded or deleted
+func (nm *NodeManager) backlinksReset(mindmap *model.Mindmap) {
+	nm.backlinksMu.Lock()
+	defer nm.backlinksMu.Unlock()
+	delete(nm.backlinks, mindmap.ID)
+}
+
+// NodeCount returns the number of nodes in the subtree of a node, including the node itself, counted from the in-memory index
+func (nm *NodeManager) NodeCount(mindmap *model.Mindmap, node *model.Node) int {
+	if node.ID == 0 {
+		return len(mindmap.Nodes)
+	}
+
+	count := 0
+	for range mindmap.Walk(node, nil) {
+		count++
+	}
+	return count
+}
+
+// NodeExists reports whether a node of a mindmap has the ID or the index of nodeInfo, as selected by nodeFilter.
+// Only the loaded nodes are searched, without querying storage, so the nodes hidden from the user do not exist.
+func (nm *NodeManager) NodeExists(mindmap *model.Mindmap, nodeInfo model.NodeInfo, nodeFilter model.NodeFilter) bool {
+	if nodeFilter.ID {
+		_, ok := mindmap.Nodes[nodeInfo.ID]
+		return ok
+	}
+	for _, node := range mindmap.Nodes {
+		if node.Index == nodeInfo.Index {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeOperationCheck checks the size of the subtree an operation applies to. Operations on more nodes than the
+// configured threshold return an error with the node count and the expected duration, unless forced.
+func (nm *NodeManager) NodeOperationCheck(mindmap *model.Mindmap, node *model.Node, operation string, force bool) error {
+	return nm.NodesOperationCheck(mindmap, []*model.Node{node}, operation, force)
+}
+
+// NodesOperationCheck checks the combined size of the subtrees an operation on several nodes applies to,
+// see NodeOperationCheck. The subtrees must not overlap.
+func (nm *NodeManager) NodesOperationCheck(mindmap *model.Mindmap, nodes []*model.Node, operation string, force bool) error {
+	ctx := context.Background()
+
+	if nm.largeOpThreshold <= 0 {
+		return nil
+	}
+
+	count := 0
+	for _, node := range nodes {
+		count += nm.NodeCount(mindmap, node)
+	}
+	if count <= nm.largeOpThreshold {
+		return nil
+	}
+
+	expected := time.Duration(count) * nodeOperationCost[operation]
+	fields := log.Fields{"mindmapID": mindmap.ID, "nodeCount": count, "operation": operation, "expected": expected.String()}
+	if force {
+		nm.logger.Warn(ctx, "Forced large node operation", fields)
+		return nil
+	}
+
+	nm.logger.Warn(ctx, "Large node operation requires force", fields)
+	return fmt.Errorf("%s affects %d nodes and is expected to take %s, use --force to proceed", operation, count, formatEstimate(expected))
+}
+
+// formatEstimate formats an expected duration at a precision matching its size
+func formatEstimate(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return "less than a second"
+	case d < time.Minute:
+		return "about " + d.Round(time.Second).String()
+	default:
+		return "about " + d.Round(time.Minute).String()
+	}
+}
+
+// NodeSort sorts the children of a node based on a given field, the field, direction and text collation given by spec
+func (nm *NodeManager) NodeSort(mindmap *model.Mindmap, nodeInfo model.NodeInfo, spec model.SortSpec) error {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Sorting nodes", log.Fields{"mindmapID": mindmap.ID, "nodeID": nodeInfo.ID, "sort": spec.String()})
 
 	// Find the node to sort
 	var node *model.Node
@@ -432,28 +656,34 @@ func (nm *NodeManager) NodeSort(mindmap *model.Mindmap, nodeInfo model.NodeInfo,
 			nm.logger.Error(ctx, "Failed to find node to sort", log.Fields{"error": err, "nodeID": nodeInfo.ID})
 			return fmt.Errorf("failed to find node to sort: %w", err)
 		}
+		// Sort the loaded node, its children are linked unlike those of the stored copy
 		node = nodes[0]
+		if loaded, ok := mindmap.Nodes[node.ID]; ok {
+			node = loaded
+		}
 	}
 
 	// Sort the entire subtree
-	sortedChildren := nm.sortNodeSubtreeRecursively(node, field, reverse)
+	sortedChildren := nm.sortNodeSubtreeRecursively(node, spec.Comparer())
 
 	// Update the node's children with the sorted children
 	node.Children = sortedChildren
 
-	// Update indices in memory and database
-	err = nm.updateSubtreeIndex(mindmap, node)
+	// Compute the final indexes first and store only the changed ones, in one pass
+	indexes := make(map[int]string)
+	renumberChildren(node, indexes)
+	nm.childIndexReset(mindmap)
+	err = nm.nodeStore.NodeIndexUpdate(mindmap, indexes)
 	if err != nil {
-		nm.logger.Error(ctx, "Failed to update index after sorting", log.Fields{"error": err, "nodeID": node.ID})
-		return fmt.Errorf("failed to update index after sorting: %w", err)
-	}
-
-	// Update the sorted nodes in storage
-	err = nm.updateSortedNodesInStorage(mindmap, node)
-	if err != nil {
-		nm.logger.Error(ctx, "Failed to update sorted nodes in storage", log.Fields{"error": err, "nodeID": node.ID})
-		return fmt.Errorf("failed to update sorted nodes in storage: %w", err)
+		nm.logger.Error(ctx, "Failed to store sorted node indexes", log.Fields{"error": err, "nodeID": node.ID})
+		// Discard the sorted order in memory, the stored order is unchanged
+		if reloadErr := nm.loadNodes(mindmap); reloadErr != nil {
+			nm.logger.Error(ctx, "Failed to reload nodes after failed sort", log.Fields{"error": reloadErr, "mindmapID": mindmap.ID})
+		}
+		return fmt.Errorf("failed to store sorted node indexes: %w", err)
 	}
+	nodesTouched(mindmap, slices.Collect(maps.Keys(indexes))...)
+	nm.logger.Debug(ctx, "Sorted node indexes stored", log.Fields{"nodeID": node.ID, "changed": len(indexes)})
 
 	// Publish NodeSorted event   // todo: placeholder
 	nm.eventManager.Publish(event.Event{
@@ -461,8 +691,8 @@ func (nm *NodeManager) NodeSort(mindmap *model.Mindmap, nodeInfo model.NodeInfo,
 		Data: map[string]interface{}{
 			"mindmap": mindmap,
 			"node":    node,
-			"field":   field,
-			"reverse": reverse,
+			"field":   spec.Field,
+			"reverse": spec.Reverse,
 		},
 	})
 
@@ -470,68 +700,85 @@ func (nm *NodeManager) NodeSort(mindmap *model.Mindmap, nodeInfo model.NodeInfo,
 	return nil
 }
 
-func (nm *NodeManager) sortNodeSubtreeRecursively(node *model.Node, field string, reverse bool) []*model.Node {
-	sort.Slice(node.Children, func(i, j int) bool {
-		var vi, vj string
-		if field == "" {
-			vi, vj = node.Children[i].Name, node.Children[j].Name
-		} else {
-			vi = node.Children[i].Content[field]
-			vj = node.Children[j].Content[field]
-		}
-		// If the field doesn't exist, fall back to Name
-		if vi == "" && vj == "" {
-			vi, vj = node.Children[i].Name, node.Children[j].Name
-		}
-		// Try to compare as numbers if possible
-		ni, errI := strconv.ParseFloat(vi, 64)
-		nj, errJ := strconv.ParseFloat(vj, 64)
-		if errI == nil && errJ == nil {
-			if reverse {
-				return ni > nj
-			}
-			return ni < nj
-		}
-		// Fall back to string comparison
-		if reverse {
-			return vi > vj
+// NodeSortPreview returns a copy of the mindmap with the subtree of a node sorted as NodeSort would sort it.
+// Neither the mindmap nor the storage is changed.
+func (nm *NodeManager) NodeSortPreview(mindmap *model.Mindmap, node *model.Node, spec model.SortSpec) (*model.Mindmap, error) {
+	preview := mindmap.Clone()
+	previewNode, ok := preview.Nodes[node.ID]
+	if !ok {
+		return nil, fmt.Errorf("node not found: %d", node.ID)
+	}
+
+	previewNode.Children = nm.sortNodeSubtreeRecursively(previewNode, spec.Comparer())
+	reindexChildren(previewNode)
+	return preview, nil
+}
+
+// NodeMovePreview returns a copy of the mindmap with a node moved to a position among the children of a new
+// parent, as NodeMove would place it. Neither the mindmap nor the storage is changed.
+func (nm *NodeManager) NodeMovePreview(mindmap *model.Mindmap, node *model.Node, parent *model.Node, position int) (*model.Mindmap, error) {
+	preview := mindmap.Clone()
+	previewNode, ok := preview.Nodes[node.ID]
+	if !ok {
+		return nil, fmt.Errorf("node not found: %d", node.ID)
+	}
+	previewParent, ok := preview.Nodes[parent.ID]
+	if !ok {
+		return nil, fmt.Errorf("new parent node not found: %d", parent.ID)
+	}
+
+	if _, err := relinkNode(preview, previewNode, previewParent, position); err != nil {
+		return nil, err
+	}
+	reindexChildren(preview.Root)
+	return preview, nil
+}
+
+// nodesTouched sets the modification time of loaded nodes whose change was just stored, as storage stamped them
+func nodesTouched(mindmap *model.Mindmap, ids ...int) {
+	now := time.Now()
+	for _, id := range ids {
+		if node, ok := mindmap.Nodes[id]; ok {
+			node.Updated = now
 		}
-		return vi < vj
-	})
-	// Recursively sort children of children
-	for _, child := range node.Children {
-		child.Children = nm.sortNodeSubtreeRecursively(child, field, reverse)
 	}
-	return node.Children
 }
 
-func (nm *NodeManager) updateSortedNodesInStorage(mindmap *model.Mindmap, node *model.Node) error {
-	ctx := context.Background()
-	nm.logger.Debug(ctx, "Updating sorted nodes in storage", log.Fields{"nodeID": node.ID})
-
-	var updateNode func(*model.Node) error
-	updateNode = func(n *model.Node) error {
-		// Update the current node
-		err := nm.nodeStore.NodeUpdate(mindmap, n, model.NodeInfo{
-			Index:    n.Index,
-			ParentID: n.ParentID,
-		}, model.NodeFilter{Index: true, ParentID: true})
-		if err != nil {
-			nm.logger.Error(ctx, "Failed to update node in storage", log.Fields{"error": err, "nodeID": n.ID})
-			return fmt.Errorf("failed to update node %d in storage: %w", n.ID, err)
+// reindexChildren sets the indexes of the descendants of a node in memory from the order of the Children slices
+func reindexChildren(node *model.Node) {
+	for i, child := range node.Children {
+		if node.Index == "0" {
+			child.Index = strconv.Itoa(i + 1)
+		} else {
+			child.Index = node.Index + "." + strconv.Itoa(i+1)
 		}
+		reindexChildren(child)
+	}
+}
 
-		// Recursively update children
-		for _, child := range n.Children {
-			err := updateNode(child)
-			if err != nil {
-				return err
-			}
+// renumberChildren numbers the subtree of a node in the order of the children, as reindexChildren does, and
+// records the new indexes of the nodes whose index changed by node ID
+func renumberChildren(node *model.Node, changed map[int]string) {
+	for i, child := range node.Children {
+		index := strconv.Itoa(i + 1)
+		if node.Index != "0" {
+			index = node.Index + "." + index
 		}
-		return nil
+		if child.Index != index {
+			child.Index = index
+			changed[child.ID] = index
+		}
+		renumberChildren(child, changed)
 	}
+}
 
-	return updateNode(node)
+func (nm *NodeManager) sortNodeSubtreeRecursively(node *model.Node, compare func(a, b *model.Node) int) []*model.Node {
+	slices.SortStableFunc(node.Children, compare)
+	// Recursively sort children of children
+	for _, child := range node.Children {
+		child.Children = nm.sortNodeSubtreeRecursively(child, compare)
+	}
+	return node.Children
 }
 
 // NodeUpdate updates an existing node's information
@@ -551,6 +798,13 @@ func (nm *NodeManager) NodeUpdate(mindmap *model.Mindmap, node *model.Node, node
 		return fmt.Errorf("node not found")
 	}
 
+	if nodeUpdateFilter.Content {
+		if err := validateFieldValues(nodeUpdateInfo.Content); err != nil {
+			nm.logger.Warn(ctx, "Invalid node field value", log.Fields{"error": err, "nodeID": node.ID})
+			return err
+		}
+	}
+
 	// Store old values for potential rollback and event
 	oldName := node.Name
 	oldContent := make(map[string]string)
@@ -562,18 +816,7 @@ func (nm *NodeManager) NodeUpdate(mindmap *model.Mindmap, node *model.Node, node
 	// Special handling for root node (ID 0)
 	if node.ID == 0 {
 		if nodeUpdateFilter.Name && nodeUpdateInfo.Name != "" {
-			oldName := node.Name
 			node.Name = nodeUpdateInfo.Name
-
-			// Publish RootNodeRenamed event instead of directly updating the mindmap
-			nm.eventManager.Publish(event.Event{
-				Type: event.RootNodeRenamed,
-				Data: map[string]interface{}{
-					"mindmapID": mindmap.ID,
-					"newName":   nodeUpdateInfo.Name,
-					"oldName":   oldName,
-				},
-			})
 		}
 
 		// Ensure root node always has correct index and parentID
@@ -635,13 +878,45 @@ func (nm *NodeManager) NodeUpdate(mindmap *model.Mindmap, node *model.Node, node
 		nm.logger.Error(ctx, "Failed to update node in storage", log.Fields{"error": err, "nodeID": node.ID})
 		return fmt.Errorf("failed to update node in storage: %w", err)
 	}
+	nodesTouched(mindmap, node.ID)
+	if node.Name != oldName {
+		nm.childIndexRename(mindmap, oldName, node.Name)
+	}
+
+	// The mindmap is renamed with its root node, once the root node is stored, while the caller waits so that the
+	// rename doesn't run alongside the batches of later commands
+	if node.ID == 0 && node.Name != oldName {
+		err := nm.eventManager.PublishAndWait(event.Event{
+			Type: event.RootNodeRenamed,
+			Data: map[string]interface{}{
+				"mindmapID": mindmap.ID,
+				"owner":     mindmap.Owner,
+				"newName":   node.Name,
+				"oldName":   oldName,
+			},
+		})
+		if err != nil {
+			nm.logger.Error(ctx, "Failed to rename mindmap with its root node", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		}
+	}
+
+	if nodeUpdateFilter.Name || nodeUpdateFilter.Content {
+		nm.backlinksUpdate(mindmap, node)
+	}
 
 	// Update indices if parent changed
 	if nodeUpdateFilter.ParentID && oldParentID != node.ParentID {
-		err = nm.updateSubtreeIndex(mindmap, mindmap.Root)
-		if err != nil {
-			nm.logger.Error(ctx, "Failed to update indices after parent change", log.Fields{"error": err, "nodeID": node.ID})
-			return fmt.Errorf("failed to update indices after parent change: %w", err)
+		nm.statsMove(mindmap)
+		// Only the subtrees of the old and the new parent are renumbered, both found by ID
+		for _, parentID := range []int{oldParentID, node.ParentID} {
+			parent, ok := mindmap.Nodes[parentID]
+			if !ok {
+				continue
+			}
+			if err := nm.updateSubtreeIndex(mindmap, parent); err != nil {
+				nm.logger.Error(ctx, "Failed to update indices after parent change", log.Fields{"error": err, "nodeID": node.ID})
+				return fmt.Errorf("failed to update indices after parent change: %w", err)
+			}
 		}
 	}
 
@@ -661,6 +936,257 @@ func (nm *NodeManager) NodeUpdate(mindmap *model.Mindmap, node *model.Node, node
 	return nil
 }
 
+// NodeMove moves a node with its subtree to a position among the children of a new parent, a position out of
+// range places it after the last child. The indexes of the old and the new siblings are updated.
+func (nm *NodeManager) NodeMove(mindmap *model.Mindmap, node *model.Node, parent *model.Node, position int) error {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Moving node", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "parentID": parent.ID, "position": position})
+
+	oldParent, ok := mindmap.Nodes[node.ParentID]
+	if !ok {
+		nm.logger.Error(ctx, "Parent node not found", log.Fields{"parentID": node.ParentID})
+		return fmt.Errorf("parent node not found")
+	}
+	oldPosition, err := relinkNode(mindmap, node, parent, position)
+	if err != nil {
+		nm.logger.Warn(ctx, "Invalid node move", log.Fields{"error": err, "nodeID": node.ID, "parentID": parent.ID})
+		return err
+	}
+
+	err = nm.nodeStore.NodeUpdate(mindmap, node, model.NodeInfo{ParentID: parent.ID}, model.NodeFilter{ParentID: true})
+	if err != nil {
+		// Rollback the in-memory move
+		relinkNode(mindmap, node, oldParent, oldPosition)
+		nm.logger.Error(ctx, "Failed to update node in storage", log.Fields{"error": err, "nodeID": node.ID})
+		return fmt.Errorf("failed to update node in storage: %w", err)
+	}
+	nodesTouched(mindmap, node.ID)
+	nm.statsMove(mindmap)
+
+	// Only the subtrees of the old and the new parent are renumbered
+	for _, n := range []*model.Node{oldParent, parent} {
+		if err := nm.updateSubtreeIndex(mindmap, n); err != nil {
+			nm.logger.Error(ctx, "Failed to update indices after move", log.Fields{"error": err, "nodeID": n.ID})
+			return fmt.Errorf("failed to update indices after move: %w", err)
+		}
+	}
+	nm.reindexCount(mindmap)
+
+	nm.eventManager.Publish(event.Event{
+		Type: event.NodeUpdated,
+		Data: map[string]interface{}{
+			"mindmap":     mindmap,
+			"node":        node,
+			"oldParentID": oldParent.ID,
+		},
+	})
+
+	nm.logger.Info(ctx, "Node moved successfully", log.Fields{"nodeID": node.ID, "parentID": parent.ID})
+	return nil
+}
+
+// NodeCopy copies a node with its subtree as the last child of parent. The copies keep the names, content fields
+// and tags of the nodes and get new IDs and indexes. Copying a node into its own subtree copies the subtree as it
+// was before the copy. Returns the copy of the node and the number of nodes copied.
+func (nm *NodeManager) NodeCopy(mindmap *model.Mindmap, node *model.Node, parent *model.Node) (*model.Node, int, error) {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Copying node", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "parentID": parent.ID})
+
+	if node.ID == 0 {
+		nm.logger.Warn(ctx, "Attempt to copy root node", nil)
+		return nil, 0, fmt.Errorf("cannot copy root node")
+	}
+
+	// The subtree is collected before adding, so copies added into it are not copied again
+	nodes := slices.Collect(mindmap.Subtree(node, nil))
+	ids := map[int]int{node.ParentID: parent.ID}
+	for _, n := range nodes {
+		info := model.NodeInfo{ParentID: ids[n.ParentID], Name: n.Name, Content: n.Content, Tags: n.Tags}
+		id, _, err := nm.NodeAdd(mindmap, info)
+		if err != nil {
+			nm.logger.Error(ctx, "Failed to add node copy", log.Fields{"error": err, "nodeID": n.ID})
+			return nil, 0, fmt.Errorf("failed to copy node %s: %w", n.Index, err)
+		}
+		ids[n.ID] = id
+	}
+
+	nm.logger.Info(ctx, "Node copied successfully", log.Fields{"nodeID": node.ID, "copyID": ids[node.ID], "nodeCount": len(nodes)})
+	return mindmap.Nodes[ids[node.ID]], len(nodes), nil
+}
+
+// NodeIndent makes a node the last child of its previous sibling
+func (nm *NodeManager) NodeIndent(mindmap *model.Mindmap, node *model.Node) error {
+	parent, position, err := nodePosition(mindmap, node)
+	if err != nil {
+		return err
+	}
+	if position == 0 {
+		return fmt.Errorf("node %s has no previous sibling to indent under", node.Index)
+	}
+	return nm.NodeMove(mindmap, node, parent.Children[position-1], -1)
+}
+
+// NodeOutdent makes a node the next sibling of its parent
+func (nm *NodeManager) NodeOutdent(mindmap *model.Mindmap, node *model.Node) error {
+	parent, _, err := nodePosition(mindmap, node)
+	if err != nil {
+		return err
+	}
+	if parent.ID == 0 {
+		return fmt.Errorf("node %s is already at the top level", node.Index)
+	}
+	grandparent, parentPosition, err := nodePosition(mindmap, parent)
+	if err != nil {
+		return err
+	}
+	return nm.NodeMove(mindmap, node, grandparent, parentPosition+1)
+}
+
+// NodeSwap exchanges the positions of two sibling nodes. Only the indexes of the subtrees of the two nodes change.
+func (nm *NodeManager) NodeSwap(mindmap *model.Mindmap, a *model.Node, b *model.Node) error {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Swapping nodes", log.Fields{"mindmapID": mindmap.ID, "nodeID": a.ID, "otherNodeID": b.ID})
+
+	parent, positionA, err := nodePosition(mindmap, a)
+	if err != nil {
+		return err
+	}
+	_, positionB, err := nodePosition(mindmap, b)
+	if err != nil {
+		return err
+	}
+	if a.ParentID != b.ParentID {
+		nm.logger.Warn(ctx, "Attempt to swap nodes with different parents", log.Fields{"nodeID": a.ID, "otherNodeID": b.ID})
+		return fmt.Errorf("nodes %s and %s are not siblings", a.Index, b.Index)
+	}
+
+	parent.Children[positionA], parent.Children[positionB] = b, a
+	return nm.reorderChildren(mindmap, parent)
+}
+
+// NodeRotate rotates the children of a node by n positions, moving the last n children to the front.
+// A negative n moves the first children to the back.
+func (nm *NodeManager) NodeRotate(mindmap *model.Mindmap, parent *model.Node, n int) error {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Rotating children", log.Fields{"mindmapID": mindmap.ID, "nodeID": parent.ID, "n": n})
+
+	count := len(parent.Children)
+	if count < 2 {
+		return fmt.Errorf("node %s has fewer than 2 children to rotate", parent.Index)
+	}
+	shift := ((n % count) + count) % count
+	if shift == 0 {
+		return nil
+	}
+
+	parent.Children = append(parent.Children[count-shift:], parent.Children[:count-shift]...)
+	return nm.reorderChildren(mindmap, parent)
+}
+
+// reorderChildren renumbers the subtree of a node after its children were reordered in memory
+func (nm *NodeManager) reorderChildren(mindmap *model.Mindmap, parent *model.Node) error {
+	ctx := context.Background()
+	if err := nm.updateSubtreeIndex(mindmap, parent); err != nil {
+		nm.logger.Error(ctx, "Failed to update indices after reordering", log.Fields{"error": err, "nodeID": parent.ID})
+		return fmt.Errorf("failed to update indices after reordering: %w", err)
+	}
+
+	nm.eventManager.Publish(event.Event{
+		Type: event.NodeUpdated,
+		Data: map[string]interface{}{
+			"mindmap": mindmap,
+			"node":    parent,
+		},
+	})
+
+	nm.logger.Info(ctx, "Children reordered successfully", log.Fields{"nodeID": parent.ID})
+	return nil
+}
+
+// validateFieldValues checks the values of the content fields with a meaning, the coordinates of the location of a
+// node, its due and reminder times, its privacy, its layout hints and its style. Empty values, which remove fields
+// on update, are not checked.
+func validateFieldValues(content map[string]string) error {
+	for _, field := range []string{model.LatField, model.LonField} {
+		if value := content[field]; value != "" {
+			if _, err := model.ParseCoordinate(field, value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, field := range []string{model.DueField, model.RemindField} {
+		if value := content[field]; value != "" {
+			if _, err := model.ParseReminderTime(field, value); err != nil {
+				return err
+			}
+		}
+	}
+	if value := content[model.PrivateField]; value != "" {
+		if _, err := model.ParsePrivate(value); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{model.LayoutSideField, model.LayoutOrderField, model.LayoutWeightField} {
+		if value := content[field]; value != "" {
+			if err := model.ParseLayoutHint(field, value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, field := range []string{model.StyleColorField, model.StyleFillField, model.StyleFontField} {
+		if value := content[field]; value != "" {
+			if err := model.ParseStyle(field, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nodePosition returns the parent of a node and the position of the node among its children
+func nodePosition(mindmap *model.Mindmap, node *model.Node) (*model.Node, int, error) {
+	if node.ID == 0 {
+		return nil, 0, fmt.Errorf("root node has no parent")
+	}
+	parent, ok := mindmap.Nodes[node.ParentID]
+	if !ok {
+		return nil, 0, fmt.Errorf("parent node not found: %d", node.ParentID)
+	}
+	position := slices.Index(parent.Children, node)
+	if position < 0 {
+		return nil, 0, fmt.Errorf("node %d is not linked to its parent", node.ID)
+	}
+	return parent, position, nil
+}
+
+// relinkNode moves a node in memory to a position among the children of a new parent and returns its old position.
+// Indexes are not updated.
+func relinkNode(mindmap *model.Mindmap, node *model.Node, parent *model.Node, position int) (int, error) {
+	if node.ID == 0 {
+		return 0, fmt.Errorf("cannot move root node")
+	}
+	for n := range mindmap.Subtree(node, nil) {
+		if n.ID == parent.ID {
+			return 0, fmt.Errorf("cannot move a node into its own subtree")
+		}
+	}
+
+	oldPosition := -1
+	if oldParent, ok := mindmap.Nodes[node.ParentID]; ok {
+		oldPosition = slices.Index(oldParent.Children, node)
+		if oldPosition >= 0 {
+			oldParent.Children = slices.Delete(oldParent.Children, oldPosition, oldPosition+1)
+		}
+	}
+
+	if position < 0 || position > len(parent.Children) {
+		position = len(parent.Children)
+	}
+	parent.Children = slices.Insert(parent.Children, position, node)
+	node.ParentID = parent.ID
+	return oldPosition, nil
+}
+
 // NodeDelete removes a node and its subtree
 func (nm *NodeManager) NodeDelete(mindmap *model.Mindmap, node *model.Node) error {
 	ctx := context.Background()
@@ -687,8 +1213,7 @@ func (nm *NodeManager) NodeDelete(mindmap *model.Mindmap, node *model.Node) erro
 	}
 
 	// Collect all nodes in the subtree to be deleted
-	nodesToDelete := []*model.Node{node}
-	nm.getSubtreeNodes(mindmap, node, &nodesToDelete)
+	nodesToDelete := slices.Collect(mindmap.Subtree(node, nil))
 
 	// Remove from storage and in-memory structure
 	for _, n := range nodesToDelete {
@@ -698,6 +1223,8 @@ func (nm *NodeManager) NodeDelete(mindmap *model.Mindmap, node *model.Node) erro
 			return fmt.Errorf("failed to delete node %d from storage: %w", n.ID, err)
 		}
 		delete(mindmap.Nodes, n.ID)
+		nm.backlinksRemove(mindmap, n)
+		nm.statsRemove(mindmap, 1)
 	}
 
 	// Update parent's children list
@@ -708,15 +1235,13 @@ func (nm *NodeManager) NodeDelete(mindmap *model.Mindmap, node *model.Node) erro
 		}
 	}
 
-	// Delete the node and its descendants recursively from the in-memory structure
-	nm.deleteNodeRecursive(mindmap, node)
-
-	// Update indexes
-	err := nm.updateSubtreeIndex(mindmap, mindmap.Root)
+	// Only the later siblings of the node and their subtrees are renumbered, not the whole mindmap
+	err := nm.updateSubtreeIndex(mindmap, parentNode)
 	if err != nil {
 		nm.logger.Error(ctx, "Failed to update indexes after deletion", log.Fields{"error": err})
 		return fmt.Errorf("failed to update indexes after deletion: %w", err)
 	}
+	nm.reindexCount(mindmap)
 
 	// Publish NodeDeleted event  // todo: placeholder
 	nm.eventManager.Publish(event.Event{
@@ -730,30 +1255,11 @@ func (nm *NodeManager) NodeDelete(mindmap *model.Mindmap, node *model.Node) erro
 	return nil
 }
 
-// deleteNodeRecursive removes a node and its descendants from the in-memory structure. Traverse the whole mindmap, assuming children slice is unreliable.
-func (nm *NodeManager) deleteNodeRecursive(mindmap *model.Mindmap, node *model.Node) {
-	for _, childNode := range mindmap.Nodes {
-		if childNode.ParentID == node.ID { // extra safety check
-			nm.deleteNodeRecursive(mindmap, childNode)
-		}
-	}
-	delete(mindmap.Nodes, node.ID)
-}
-
-// getSubtreeNodes collects all nodes in a subtree. Traverse the whole mindmap, assuming children slice is unreliable.
-func (nm *NodeManager) getSubtreeNodes(mindmap *model.Mindmap, node *model.Node, nodes *[]*model.Node) {
-	for _, childNode := range mindmap.Nodes {
-		if childNode.ParentID == node.ID { // extra safety check
-			*nodes = append(*nodes, childNode)
-			nm.getSubtreeNodes(mindmap, childNode, nodes)
-		}
-	}
-}
-
 // updateSubtreeIndex updates the indices of all nodes in a subtree.
 func (nm *NodeManager) updateSubtreeIndex(mindmap *model.Mindmap, node *model.Node) error {
 	ctx := context.Background()
 	nm.logger.Debug(ctx, "Updating subtree index", log.Fields{"nodeID": node.ID})
+	nm.childIndexReset(mindmap)
 
 	var recalculate func(*model.Node, string) error
 	recalculate = func(n *model.Node, parentIndex string) error {
@@ -0,0 +1,120 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the search of the references to nodes about to be deleted.
+package data
+
+import (
+	"context"
+	"slices"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// NodeReferences returns the references to the nodes in the subtrees of nodes of a loaded mindmap from nodes
+// outside them, which deleting the subtrees would leave dangling: [[name]] and [[#id]] references in the name or
+// content of nodes of the mindmap, and stored links from nodes of any mindmap. A stored wiki link is reported only
+// through the reference it was made from. The references are ordered by target and source in document order,
+// links from other mindmaps last.
+func (m *DataManager) NodeReferences(mindmap *model.Mindmap, nodes []*model.Node) ([]*model.NodeReference, error) {
+	ctx := context.Background()
+
+	deleted := make(map[int]bool)
+	var targets []*model.Node
+	for _, node := range nodes {
+		for n := range mindmap.Subtree(node, nil) {
+			if !deleted[n.ID] {
+				deleted[n.ID] = true
+				targets = append(targets, n)
+			}
+		}
+	}
+
+	order := make(map[int]int, len(mindmap.Nodes))
+	for n := range mindmap.Subtree(nil, nil) {
+		order[n.ID] = len(order)
+	}
+
+	var references []*model.NodeReference
+	for _, target := range targets {
+		for _, source := range m.NodeManager.NodeBacklinks(mindmap, target) {
+			if !deleted[source.ID] {
+				references = append(references, &model.NodeReference{SourceMindmapID: mindmap.ID, SourceID: source.ID, TargetID: target.ID, Kind: model.ReferenceWiki})
+			}
+		}
+	}
+
+	links, err := m.LinkManager.LinkGetTargets(mindmap)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if !deleted[link.TargetID] {
+			continue
+		}
+		// Links from nodes that are deleted too, gone or hidden from the user are not reported, nor the wiki links
+		// made from the references reported above
+		if link.SourceMindmapID == mindmap.ID && (deleted[link.SourceID] || mindmap.Nodes[link.SourceID] == nil || link.Type == model.LinkTypeWiki) {
+			continue
+		}
+		references = append(references, &model.NodeReference{SourceMindmapID: link.SourceMindmapID, SourceID: link.SourceID, TargetID: link.TargetID, Kind: model.ReferenceLink})
+	}
+
+	slices.SortStableFunc(references, func(a, b *model.NodeReference) int {
+		if c := order[a.TargetID] - order[b.TargetID]; c != 0 {
+			return c
+		}
+		if (a.SourceMindmapID == mindmap.ID) != (b.SourceMindmapID == mindmap.ID) {
+			if a.SourceMindmapID == mindmap.ID {
+				return -1
+			}
+			return 1
+		}
+		return order[a.SourceID] - order[b.SourceID]
+	})
+
+	m.Logger.Debug(ctx, "Node references found", log.Fields{"mindmapID": mindmap.ID, "targets": len(targets), "references": len(references)})
+	return references, nil
+}
+
+// NodeBacklinkReferences returns the references to a node of a loaded mindmap: the [[name]] and [[#id]] references
+// in the name or content of its nodes, see NodeBacklinks, and the stored links from nodes of any mindmap, such as
+// those of the node link command. A stored wiki link is reported only through the reference it was made from. The
+// references are ordered by source in document order, links from other mindmaps last.
+func (m *DataManager) NodeBacklinkReferences(mindmap *model.Mindmap, node *model.Node) ([]*model.NodeReference, error) {
+	var references []*model.NodeReference
+	for _, source := range m.NodeManager.NodeBacklinks(mindmap, node) {
+		references = append(references, &model.NodeReference{SourceMindmapID: mindmap.ID, SourceID: source.ID, TargetID: node.ID, Kind: model.ReferenceWiki})
+	}
+
+	links, err := m.LinkManager.LinkGetTargets(mindmap)
+	if err != nil {
+		return nil, err
+	}
+	order := make(map[int]int, len(mindmap.Nodes))
+	for n := range mindmap.Subtree(nil, nil) {
+		order[n.ID] = len(order)
+	}
+	for _, link := range links {
+		if link.TargetID != node.ID {
+			continue
+		}
+		if link.SourceMindmapID == mindmap.ID && (mindmap.Nodes[link.SourceID] == nil || link.Type == model.LinkTypeWiki) {
+			continue
+		}
+		references = append(references, &model.NodeReference{SourceMindmapID: link.SourceMindmapID, SourceID: link.SourceID, TargetID: node.ID, Kind: model.ReferenceLink})
+	}
+
+	slices.SortStableFunc(references, func(a, b *model.NodeReference) int {
+		if (a.SourceMindmapID == mindmap.ID) != (b.SourceMindmapID == mindmap.ID) {
+			if a.SourceMindmapID == mindmap.ID {
+				return -1
+			}
+			return 1
+		}
+		if a.SourceMindmapID != mindmap.ID {
+			return 0
+		}
+		return order[a.SourceID] - order[b.SourceID]
+	})
+	return references, nil
+}
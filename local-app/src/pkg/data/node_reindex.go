@@ -0,0 +1,133 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the rebuilding of the node indexes of mindmaps.
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// NodeReindex rebuilds the indexes of all nodes of a mindmap, numbering the children of each node from 1 in their
+// current order so that gaps left by deletes and moves are closed. The loaded nodes are first compared with the
+// stored ones and reloaded if they disagree, the stored parents and order winning. The changed indexes are
+// stored in one transaction, after which the loaded and stored nodes must agree.
+func (nm *NodeManager) NodeReindex(mindmap *model.Mindmap) (model.MindmapReindex, error) {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Reindexing nodes", log.Fields{"mindmapID": mindmap.ID})
+
+	if mindmap.Hidden > 0 {
+		nm.logger.Warn(ctx, "Reindex of a mindmap with hidden nodes", log.Fields{"mindmapID": mindmap.ID})
+		return model.MindmapReindex{}, errors.New("only the owner of the mindmap can reindex it")
+	}
+
+	stored, err := nm.nodeStore.NodeGet(mindmap, model.NodeInfo{}, model.NodeFilter{})
+	if err != nil {
+		nm.logger.Error(ctx, "Failed to get stored nodes", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return model.MindmapReindex{}, fmt.Errorf("failed to get stored nodes: %w", err)
+	}
+
+	var result model.MindmapReindex
+	if result.Mismatched = nodeMismatches(mindmap, stored); result.Mismatched > 0 {
+		nm.logger.Warn(ctx, "Loaded nodes differ from storage, reloading", log.Fields{"mindmapID": mindmap.ID, "mismatched": result.Mismatched})
+		if err := nm.loadNodes(mindmap); err != nil {
+			nm.logger.Error(ctx, "Failed to reload nodes", log.Fields{"error": err, "mindmapID": mindmap.ID})
+			return result, fmt.Errorf("failed to reload nodes: %w", err)
+		}
+	} else {
+		// Siblings are ordered by their current indexes, whatever their gaps
+		mindmap.LinkChildren()
+	}
+	if mindmap.Root == nil {
+		return result, errors.New("mindmap has no root node")
+	}
+
+	indexes := make(map[int]string)
+	renumberChildren(mindmap.Root, indexes)
+	nm.childIndexReset(mindmap)
+	if err := nm.nodeStore.NodeIndexUpdate(mindmap, indexes); err != nil {
+		nm.logger.Error(ctx, "Failed to store rebuilt node indexes", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		if reloadErr := nm.loadNodes(mindmap); reloadErr != nil {
+			nm.logger.Error(ctx, "Failed to reload nodes after failed reindex", log.Fields{"error": reloadErr, "mindmapID": mindmap.ID})
+		}
+		return result, fmt.Errorf("failed to store rebuilt node indexes: %w", err)
+	}
+	nodesTouched(mindmap, slices.Collect(maps.Keys(indexes))...)
+	result.Nodes = len(mindmap.Nodes)
+	result.Renumbered = len(indexes)
+
+	// Verify that memory and storage agree now
+	stored, err = nm.nodeStore.NodeGet(mindmap, model.NodeInfo{}, model.NodeFilter{})
+	if err != nil {
+		nm.logger.Error(ctx, "Failed to get stored nodes", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return result, fmt.Errorf("failed to verify rebuilt node indexes: %w", err)
+	}
+	if mismatched := nodeMismatches(mindmap, stored); mismatched > 0 {
+		nm.logger.Error(ctx, "Loaded nodes differ from storage after reindex", log.Fields{"mindmapID": mindmap.ID, "mismatched": mismatched})
+		return result, fmt.Errorf("loaded and stored nodes still differ in %d nodes after reindex", mismatched)
+	}
+
+	nm.reindexMu.Lock()
+	delete(nm.reindexEdits, mindmap.ID)
+	nm.reindexMu.Unlock()
+
+	if result.Renumbered > 0 {
+		nm.eventManager.Publish(event.Event{
+			Type: event.NodeSorted,
+			Data: map[string]interface{}{
+				"mindmap": mindmap,
+				"node":    mindmap.Root,
+			},
+		})
+	}
+
+	nm.logger.Info(ctx, "Nodes reindexed successfully", log.Fields{"mindmapID": mindmap.ID, "nodes": result.Nodes, "mismatched": result.Mismatched, "renumbered": result.Renumbered})
+	return result, nil
+}
+
+// reindexCount counts a delete or move in a mindmap and rebuilds its indexes once the count reaches the reindex
+// threshold. A failed reindex is logged, not returned, as the operation counted has succeeded.
+func (nm *NodeManager) reindexCount(mindmap *model.Mindmap) {
+	if nm.reindexThreshold <= 0 || mindmap.Hidden > 0 {
+		return
+	}
+
+	nm.reindexMu.Lock()
+	nm.reindexEdits[mindmap.ID]++
+	due := nm.reindexEdits[mindmap.ID] >= nm.reindexThreshold
+	nm.reindexMu.Unlock()
+	if !due {
+		return
+	}
+
+	nm.logger.Info(context.Background(), "Reindex threshold reached", log.Fields{"mindmapID": mindmap.ID, "threshold": nm.reindexThreshold})
+	if _, err := nm.NodeReindex(mindmap); err != nil {
+		nm.logger.Warn(context.Background(), "Automatic reindex failed", log.Fields{"error": err, "mindmapID": mindmap.ID})
+	}
+}
+
+// nodeMismatches counts the nodes whose parent or index differ between the loaded nodes of a mindmap and the
+// stored ones, including the nodes missing from either
+func nodeMismatches(mindmap *model.Mindmap, stored []*model.Node) int {
+	mismatched := 0
+	seen := make(map[int]bool, len(stored))
+	for _, s := range stored {
+		seen[s.ID] = true
+		loaded, ok := mindmap.Nodes[s.ID]
+		if !ok || loaded.ParentID != s.ParentID || loaded.Index != s.Index {
+			mismatched++
+		}
+	}
+	for id := range mindmap.Nodes {
+		if !seen[id] {
+			mismatched++
+		}
+	}
+	return mismatched
+}
@@ -0,0 +1,133 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the tags of nodes, labels kept apart from the content fields and looked up by an index.
+package data
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+// normalizeTag returns the stored form of a tag: without a leading '#', case-folded so that tags differing only in
+// case are the same tag, and valid as a tag name
+func normalizeTag(tag string) (string, error) {
+	tag = strings.TrimPrefix(names.Normalize(tag), "#")
+	if err := names.Validate(names.Tag, tag); err != nil {
+		return "", err
+	}
+	return names.Key(tag, false), nil
+}
+
+// normalizeTags returns the stored forms of tags, sorted and without duplicates
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		t, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, t)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// NodeTagAdd tags a node. Returns the stored form of the tag, see normalizeTag, and false if the node has the tag
+// already.
+func (nm *NodeManager) NodeTagAdd(mindmap *model.Mindmap, node *model.Node, tag string) (string, bool, error) {
+	return nm.nodeTagChange(mindmap, node, tag, true)
+}
+
+// NodeTagDelete removes a tag from a node. Returns the stored form of the tag and false if the node has no such
+// tag.
+func (nm *NodeManager) NodeTagDelete(mindmap *model.Mindmap, node *model.Node, tag string) (string, bool, error) {
+	return nm.nodeTagChange(mindmap, node, tag, false)
+}
+
+// nodeTagChange adds a tag to a node or removes it, in storage and in memory
+func (nm *NodeManager) nodeTagChange(mindmap *model.Mindmap, node *model.Node, tag string, add bool) (string, bool, error) {
+	ctx := context.Background()
+	nm.logger.Info(ctx, "Changing node tag", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "tag": tag, "add": add})
+
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		nm.logger.Warn(ctx, "Invalid tag", log.Fields{"error": err})
+		return "", false, err
+	}
+
+	var changed bool
+	if add {
+		changed, err = nm.nodeStore.NodeTagAdd(mindmap, node, tag)
+	} else {
+		changed, err = nm.nodeStore.NodeTagDelete(mindmap, node, tag)
+	}
+	if err != nil {
+		nm.logger.Error(ctx, "Failed to change node tag in storage", log.Fields{"error": err, "nodeID": node.ID})
+		return tag, false, fmt.Errorf("failed to change node tag in storage: %w", err)
+	}
+	if !changed {
+		return tag, false, nil
+	}
+
+	oldTags := slices.Clone(node.Tags)
+	if add {
+		node.Tags = append(node.Tags, tag)
+		slices.Sort(node.Tags)
+	} else {
+		node.Tags = slices.DeleteFunc(node.Tags, func(t string) bool { return t == tag })
+	}
+	nodesTouched(mindmap, node.ID)
+
+	nm.eventManager.Publish(event.Event{
+		Type: event.NodeUpdated,
+		Data: map[string]interface{}{
+			"mindmap": mindmap,
+			"node":    node,
+			"oldTags": oldTags,
+		},
+	})
+
+	nm.logger.Info(ctx, "Node tag changed", log.Fields{"nodeID": node.ID, "tag": tag, "add": add})
+	return tag, true, nil
+}
+
+// NodeTagFind returns the loaded nodes of a mindmap with a tag in document order, looked up by the index of the
+// stored tags. The nodes hidden from the user are left out.
+func (nm *NodeManager) NodeTagFind(mindmap *model.Mindmap, tag string) ([]*model.Node, error) {
+	ctx := context.Background()
+
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := nm.nodeStore.NodeTagFind(mindmap, tag)
+	if err != nil {
+		nm.logger.Error(ctx, "Failed to find tagged nodes", log.Fields{"error": err, "mindmapID": mindmap.ID, "tag": tag})
+		return nil, fmt.Errorf("failed to find tagged nodes: %w", err)
+	}
+
+	tagged := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		tagged[id] = true
+	}
+	nodes := slices.Collect(mindmap.Subtree(nil, func(node *model.Node) bool { return tagged[node.ID] }))
+	nm.logger.Debug(ctx, "Tagged nodes found", log.Fields{"tag": tag, "count": len(nodes)})
+	return nodes, nil
+}
+
+// NodeTagCounts returns the tags of the loaded nodes of a mindmap with the number of nodes having each
+func (nm *NodeManager) NodeTagCounts(mindmap *model.Mindmap) map[string]int {
+	counts := make(map[string]int)
+	for _, node := range mindmap.Nodes {
+		for _, tag := range node.Tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,36 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the hiding of private subtrees from the users other than the owner of a mindmap.
+package data
+
+import (
+	"context"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// NodePrivateHide removes the private subtrees from a mindmap loaded for a user other than its owner and adds the
+// number of removed nodes to mindmap.Hidden. Node queries on the mindmap leave the hidden nodes out too. A mindmap
+// loaded a few levels at a time is hidden from again after each load, its hidden nodes staying out of later loads.
+func (nm *NodeManager) NodePrivateHide(mindmap *model.Mindmap) {
+	hidden := make(map[int]bool)
+	for node := range mindmap.Subtree(nil, nil) {
+		if hidden[node.ParentID] || node.ID != 0 && node.IsPrivate() {
+			hidden[node.ID] = true
+		}
+	}
+	if len(hidden) == 0 {
+		return
+	}
+
+	for id := range hidden {
+		delete(mindmap.Nodes, id)
+		delete(mindmap.Unloaded, id)
+	}
+	mindmap.LinkChildren()
+	mindmap.Hidden += len(hidden)
+
+	// The backlink index is built from the loaded nodes, the owner's is rebuilt when the owner loads the mindmap
+	nm.backlinksReset(mindmap)
+	nm.logger.Info(context.Background(), "Private subtrees hidden", log.Fields{"mindmapID": mindmap.ID, "hidden": len(hidden)})
+}
@@ -0,0 +1,31 @@
+package data
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// ReminderDue returns the reminders of the nodes of all mindmaps that fall due after from and up to to, in order
+// of their time
+func (m *DataManager) ReminderDue(from, to time.Time) ([]*model.Reminder, error) {
+	reminders, err := m.store.ReminderStore.ReminderGet()
+	if err != nil {
+		m.Logger.Error(context.Background(), "Failed to get reminders", log.Fields{"error": err})
+		return nil, err
+	}
+
+	var due []*model.Reminder
+	for _, reminder := range reminders {
+		if reminder.At.After(from) && !reminder.At.After(to) {
+			due = append(due, reminder)
+		}
+	}
+	slices.SortStableFunc(due, func(a, b *model.Reminder) int {
+		return a.At.Compare(b.At)
+	})
+	return due, nil
+}
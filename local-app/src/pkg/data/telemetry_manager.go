@@ -0,0 +1,105 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the opt-in command telemetry, which stays on the local machine unless exported and shared.
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// TelemetryOperations defines the interface for command telemetry operations
+type TelemetryOperations interface {
+	TelemetryRecord(scope, operation string, failed bool) error
+	TelemetryUsage() ([]*model.CommandUsage, error)
+	TelemetryExport(path string) (int, error)
+	TelemetryReset() error
+}
+
+// TelemetryManager handles recording, reporting and exporting of the anonymous command usage counts.
+type TelemetryManager struct {
+	usageStore storage.UsageStore
+	logger     *log.Logger
+}
+
+// NewTelemetryManager creates a new TelemetryManager instance.
+func NewTelemetryManager(usageStore storage.UsageStore, logger *log.Logger) (*TelemetryManager, error) {
+	ctx := context.Background()
+	logger.Info(ctx, "Creating new TelemetryManager", nil)
+
+	if usageStore == nil {
+		logger.Error(ctx, "UsageStore not initialized", nil)
+		return nil, fmt.Errorf("usageStore not initialized")
+	}
+
+	tm := &TelemetryManager{
+		usageStore: usageStore,
+		logger:     logger,
+	}
+
+	logger.Info(ctx, "TelemetryManager created successfully", nil)
+	return tm, nil
+}
+
+// TelemetryRecord counts a use of a command, and its failure if it failed.
+func (tm *TelemetryManager) TelemetryRecord(scope, operation string, failed bool) error {
+	if err := tm.usageStore.UsageAdd(scope, operation, failed); err != nil {
+		tm.logger.Error(context.Background(), "Failed to record command usage", log.Fields{"error": err, "scope": scope, "operation": operation})
+		return fmt.Errorf("failed to record command usage: %w", err)
+	}
+	return nil
+}
+
+// TelemetryUsage returns the recorded usage of the commands, most used first.
+func (tm *TelemetryManager) TelemetryUsage() ([]*model.CommandUsage, error) {
+	usage, err := tm.usageStore.UsageGet()
+	if err != nil {
+		tm.logger.Error(context.Background(), "Failed to get command usage", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to get command usage: %w", err)
+	}
+	return usage, nil
+}
+
+// TelemetryExport writes the recorded usage of the commands with their error rates to a JSON file at path,
+// returning the number of commands exported.
+func (tm *TelemetryManager) TelemetryExport(path string) (int, error) {
+	ctx := context.Background()
+	tm.logger.Info(ctx, "Exporting command usage", log.Fields{"path": path})
+
+	usage, err := tm.TelemetryUsage()
+	if err != nil {
+		return 0, err
+	}
+
+	report := model.UsageReport{Exported: time.Now().UTC(), Commands: make([]model.UsageReportItem, len(usage))}
+	for i, u := range usage {
+		report.Commands[i] = model.UsageReportItem{CommandUsage: *u, ErrorRate: u.ErrorRate()}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		tm.logger.Error(ctx, "Failed to encode command usage", log.Fields{"error": err})
+		return 0, fmt.Errorf("failed to encode command usage: %w", err)
+	}
+	if err := storage.WriteFileAtomic(path, data, 0644); err != nil {
+		tm.logger.Error(ctx, "Failed to write command usage", log.Fields{"error": err, "path": path})
+		return 0, fmt.Errorf("failed to write command usage: %w", err)
+	}
+
+	tm.logger.Info(ctx, "Command usage exported", log.Fields{"path": path, "commands": len(usage)})
+	return len(usage), nil
+}
+
+// TelemetryReset deletes the recorded usage of all commands.
+func (tm *TelemetryManager) TelemetryReset() error {
+	if err := tm.usageStore.UsageReset(); err != nil {
+		tm.logger.Error(context.Background(), "Failed to reset command usage", log.Fields{"error": err})
+		return fmt.Errorf("failed to reset command usage: %w", err)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"mindnoscape/local-app/src/pkg/event"
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
 	"mindnoscape/local-app/src/pkg/storage"
 )
 
@@ -64,6 +65,9 @@ func (um *UserManager) UserAdd(newUserInfo model.UserInfo) (int, error) {
 	ctx := context.Background()
 	um.logger.Info(ctx, "Adding new user", log.Fields{"username": newUserInfo.Username})
 
+	// Store the username in normalized form, so equivalent spellings are one user
+	newUserInfo.Username = names.Normalize(newUserInfo.Username)
+
 	// Check if the user already exists
 	existingUsers, err := um.UserGet(model.UserInfo{Username: newUserInfo.Username}, model.UserFilter{Username: true})
 	if err != nil {
@@ -72,7 +76,7 @@ func (um *UserManager) UserAdd(newUserInfo model.UserInfo) (int, error) {
 	}
 	if len(existingUsers) > 0 {
 		um.logger.Warn(ctx, "User already exists", log.Fields{"username": newUserInfo.Username})
-		return 0, fmt.Errorf("user '%s' already exists", newUserInfo.Username)
+		return 0, fmt.Errorf("user '%s' already exists", existingUsers[0].Username)
 	}
 
 	// Add the new user using the storage layer
@@ -133,6 +137,23 @@ func (um *UserManager) UserUpdate(user *model.User, userUpdateInfo model.UserInf
 	ctx := context.Background()
 	um.logger.Info(ctx, "Updating user", log.Fields{"userID": user.ID, "username": user.Username})
 
+	if userFilter.Username {
+		userUpdateInfo.Username = names.Normalize(userUpdateInfo.Username)
+
+		// Check that no other user already has the new username
+		existingUsers, err := um.UserGet(model.UserInfo{Username: userUpdateInfo.Username}, model.UserFilter{Username: true})
+		if err != nil {
+			um.logger.Error(ctx, "Error checking user existence", log.Fields{"error": err, "username": userUpdateInfo.Username})
+			return fmt.Errorf("error checking user existence: %w", err)
+		}
+		for _, existingUser := range existingUsers {
+			if existingUser.ID != user.ID {
+				um.logger.Warn(ctx, "User already exists", log.Fields{"username": userUpdateInfo.Username})
+				return fmt.Errorf("user '%s' already exists", existingUser.Username)
+			}
+		}
+	}
+
 	err := um.userStore.UserUpdate(user, userUpdateInfo, userFilter)
 	if err != nil {
 		um.logger.Error(ctx, "Failed to update user", log.Fields{"error": err, "userID": user.ID})
@@ -148,18 +169,19 @@ func (um *UserManager) UserDelete(user *model.User) error {
 	ctx := context.Background()
 	um.logger.Info(ctx, "Deleting user", log.Fields{"userID": user.ID, "username": user.Username})
 
+	// The mindmaps and other data of the user are deleted first, as they refer to the user, and while the caller
+	// waits, so that the deletes don't run alongside the batches of later commands
+	if err := um.eventManager.PublishAndWait(event.Event{Type: event.UserDeleted, Data: user}); err != nil {
+		um.logger.Error(ctx, "Failed to delete the data of deleted user", log.Fields{"error": err, "userID": user.ID})
+		return fmt.Errorf("failed to delete the data of user: %w", err)
+	}
+
 	err := um.userStore.UserDelete(user)
 	if err != nil {
 		um.logger.Error(ctx, "Failed to delete user", log.Fields{"error": err, "userID": user.ID})
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
-	// Publish UserDeleted event
-	um.eventManager.Publish(event.Event{
-		Type: event.UserDeleted,
-		Data: user,
-	})
-
 	um.logger.Info(ctx, "User deleted successfully", log.Fields{"userID": user.ID, "username": user.Username})
 	return nil
 }
@@ -0,0 +1,220 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the version history of mindmaps: versions stored automatically and named checkpoints, and
+// the revert of a mindmap to one of them.
+package data
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// VersionOperations defines the interface for mindmap version operations
+type VersionOperations interface {
+	VersionGet(mindmap *model.Mindmap) ([]*model.MindmapVersion, error)
+}
+
+// VersionManager handles the stored versions of mindmaps, which outlive the sessions and the mindmap changes they
+// were taken before.
+type VersionManager struct {
+	versionStore storage.VersionStore
+	interval     int // Node changes of a mindmap after which an automatic version is stored, 0 or less for none
+	retention    int // Automatic versions kept per mindmap, 0 or less to keep them all
+	editsMu      sync.Mutex
+	edits        map[int]int // Node changes per mindmap ID since its last version
+	logger       *log.Logger
+}
+
+// NewVersionManager creates a new VersionManager instance.
+// A version of a mindmap is stored automatically after interval node changes, keeping the newest retention of them.
+func NewVersionManager(versionStore storage.VersionStore, interval, retention int, logger *log.Logger) (*VersionManager, error) {
+	ctx := context.Background()
+	logger.Info(ctx, "Creating new VersionManager", nil)
+
+	if versionStore == nil {
+		logger.Error(ctx, "VersionStore not initialized", nil)
+		return nil, fmt.Errorf("versionStore not initialized")
+	}
+
+	vm := &VersionManager{
+		versionStore: versionStore,
+		interval:     interval,
+		retention:    retention,
+		edits:        make(map[int]int),
+		logger:       logger,
+	}
+
+	logger.Info(ctx, "VersionManager created successfully", nil)
+	return vm, nil
+}
+
+// handleMindmapDeleted removes the versions of a deleted mindmap
+func (vm *VersionManager) handleMindmapDeleted(e event.Event) {
+	ctx := context.Background()
+	vm.logger.Info(ctx, "Handling MindmapDeleted event", nil)
+
+	mindmap, ok := e.Data.(*model.Mindmap)
+	if !ok {
+		vm.logger.Error(ctx, "Invalid event data for mindmap delete event", nil)
+		return
+	}
+	vm.editsMu.Lock()
+	delete(vm.edits, mindmap.ID)
+	vm.editsMu.Unlock()
+	if err := vm.versionStore.VersionDeleteMindmap(mindmap.ID); err != nil {
+		vm.logger.Error(ctx, "Failed to delete versions of deleted mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+	}
+}
+
+// VersionGet retrieves the versions of a mindmap, newest first
+func (vm *VersionManager) VersionGet(mindmap *model.Mindmap) ([]*model.MindmapVersion, error) {
+	versions, err := vm.versionStore.VersionGet(mindmap.ID)
+	if err != nil {
+		vm.logger.Error(context.Background(), "Failed to get mindmap versions", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to get versions: %w", err)
+	}
+	return versions, nil
+}
+
+// versionAdd stores an exported copy of a mindmap as its next version, deleting the oldest automatic versions
+// beyond the retention
+func (vm *VersionManager) versionAdd(exported *model.Mindmap, label string, auto bool) (*model.MindmapVersion, error) {
+	ctx := context.Background()
+
+	version := &model.MindmapVersion{
+		MindmapID: exported.ID,
+		Label:     label,
+		Auto:      auto,
+		NodeCount: len(exported.Nodes),
+		Created:   time.Now(),
+	}
+	if err := vm.versionStore.VersionAdd(version, exported); err != nil {
+		vm.logger.Error(ctx, "Failed to store mindmap version", log.Fields{"error": err, "mindmapID": exported.ID})
+		return nil, fmt.Errorf("failed to store version: %w", err)
+	}
+	vm.editsMu.Lock()
+	delete(vm.edits, exported.ID)
+	vm.editsMu.Unlock()
+	vm.logger.Info(ctx, "Mindmap version stored", log.Fields{"mindmapID": exported.ID, "version": version.Version, "label": label, "auto": auto})
+
+	if !auto || vm.retention <= 0 {
+		return version, nil
+	}
+	versions, err := vm.versionStore.VersionGet(exported.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get versions: %w", err)
+	}
+	var old []int
+	kept := 0
+	for _, v := range versions {
+		if !v.Auto {
+			continue
+		}
+		if kept++; kept > vm.retention {
+			old = append(old, v.Version)
+		}
+	}
+	if err := vm.versionStore.VersionDelete(exported.ID, old); err != nil {
+		vm.logger.Error(ctx, "Failed to delete old mindmap versions", log.Fields{"error": err, "mindmapID": exported.ID})
+		return nil, fmt.Errorf("failed to delete old versions: %w", err)
+	}
+	return version, nil
+}
+
+// versionDue counts a node change of a mindmap and reports whether an automatic version of it is due
+func (vm *VersionManager) versionDue(mindmap *model.Mindmap) bool {
+	if vm.interval <= 0 {
+		return false
+	}
+	vm.editsMu.Lock()
+	defer vm.editsMu.Unlock()
+	vm.edits[mindmap.ID]++
+	return vm.edits[mindmap.ID] >= vm.interval
+}
+
+// MindmapCheckpoint stores the current state of a mindmap of user as a version named label, kept until the
+// mindmap is deleted
+func (m *DataManager) MindmapCheckpoint(user *model.User, mindmap *model.Mindmap, label string) (*model.MindmapVersion, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Storing mindmap checkpoint", log.Fields{"mindmapID": mindmap.ID, "label": label})
+
+	if mindmap.Owner != user.Username {
+		m.Logger.Warn(ctx, "User does not have permission to store a checkpoint", log.Fields{"username": user.Username, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("user %s does not have permission to update mindmap %s", user.Username, mindmap.Name)
+	}
+	return m.versionAdd(user, mindmap, label, false)
+}
+
+// MindmapVersionCount counts a node change of a mindmap and stores an automatic version of it once the changes
+// reach the version interval. A failed version is logged, not returned, as the change counted has succeeded.
+func (m *DataManager) MindmapVersionCount(user *model.User, mindmap *model.Mindmap) {
+	if !m.VersionManager.versionDue(mindmap) {
+		return
+	}
+	if _, err := m.versionAdd(user, mindmap, "", true); err != nil {
+		m.Logger.Warn(context.Background(), "Automatic mindmap version failed", log.Fields{"error": err, "mindmapID": mindmap.ID})
+	}
+}
+
+// MindmapRevert brings a mindmap of user back to a stored version: the nodes changed since are changed back, the
+// nodes deleted added again and the nodes added deleted, as a sync import of the version would. The current state
+// is stored as an automatic version first, so that the revert can be reverted. Returns the reverted mindmap with
+// its nodes, the counts of the changes and warnings about the links and attachments that could not be restored.
+func (m *DataManager) MindmapRevert(user *model.User, mindmap *model.Mindmap, version int) (*model.Mindmap, model.ImportMerge, []string, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Reverting mindmap", log.Fields{"mindmapID": mindmap.ID, "version": version})
+
+	if mindmap.Owner != user.Username {
+		m.Logger.Warn(ctx, "User does not have permission to revert mindmap", log.Fields{"username": user.Username, "mindmapID": mindmap.ID})
+		return nil, model.ImportMerge{}, nil, fmt.Errorf("user %s does not have permission to update mindmap %s", user.Username, mindmap.Name)
+	}
+
+	stored, err := m.VersionManager.versionStore.VersionMindmap(mindmap.ID, version)
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to get mindmap version", log.Fields{"error": err, "mindmapID": mindmap.ID, "version": version})
+		return nil, model.ImportMerge{}, nil, err
+	}
+	if _, err := m.versionAdd(user, mindmap, fmt.Sprintf("before revert to version %d", version), true); err != nil {
+		return nil, model.ImportMerge{}, nil, err
+	}
+
+	// A renamed mindmap keeps its name
+	stored.Name = mindmap.Name
+	options := model.ImportOptions{
+		File:      &model.ImportFile{Mindmap: stored, Format: "json"},
+		Existing:  model.ImportSync,
+		Conflicts: model.ConflictTheirs,
+	}
+	return m.MindmapImportMerge(user, options)
+}
+
+// versionAdd stores the mindmap as stored, with all its nodes, links and attachments, as its next version
+func (m *DataManager) versionAdd(user *model.User, mindmap *model.Mindmap, label string, auto bool) (*model.MindmapVersion, error) {
+	// The loaded nodes may leave out private subtrees or levels not loaded yet, the stored ones are complete
+	stored := *mindmap
+	stored.Hidden, stored.Unloaded = 0, nil
+	nodes, err := m.NodeManager.NodeGet(&stored, model.NodeInfo{}, model.NodeFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes of mindmap %s: %w", mindmap.Name, err)
+	}
+	stored.Nodes = make(map[int]*model.Node, len(nodes))
+	for _, node := range nodes {
+		stored.Nodes[node.ID] = node
+		if node.ID == 0 {
+			stored.Root = node
+		}
+	}
+	stored.LinkChildren()
+
+	exported, err := m.ExportedMindmap(user, &stored, model.ExportOptions{Format: "json"})
+	if err != nil {
+		return nil, err
+	}
+	return m.VersionManager.versionAdd(exported, label, auto)
+}
@@ -0,0 +1,125 @@
+// Package data provides data management functionality for the Mindnoscape application.
+// This file contains the resolution of wiki-style [[name]] references into cross-links.
+package data
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// NodeWikiLink resolves the [[name]] and [[#id]] references in the content of a node to the nodes they name, and
+// replaces the wiki links of the node with links to them. A name resolves only if exactly one node has it.
+// If create is set, a node is created under inbox for each name of no node, under the top-level Inbox node if
+// inbox is nil. The changes are made in one transaction.
+func (m *DataManager) NodeWikiLink(mindmap *model.Mindmap, node *model.Node, create bool, inbox *model.Node) (*model.WikiLinkResult, error) {
+	ctx := context.Background()
+	m.Logger.Info(ctx, "Resolving wiki links", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "create": create})
+
+	var targets []string
+	for _, key := range slices.Sorted(maps.Keys(node.Content)) {
+		for _, target := range ParseReferences(node.Content[key]) {
+			if !slices.Contains(targets, target) {
+				targets = append(targets, target)
+			}
+		}
+	}
+
+	byName := make(map[string][]*model.Node)
+	for n := range mindmap.Subtree(nil, nil) {
+		byName[referenceKey(n.Name)] = append(byName[referenceKey(n.Name)], n)
+	}
+
+	result := &model.WikiLinkResult{}
+	err := m.NodeBatch(mindmap, func() error {
+		for _, target := range targets {
+			var matches []*model.Node
+			if id, err := strconv.Atoi(strings.TrimPrefix(target, "#")); err == nil && strings.HasPrefix(target, "#") {
+				if n, ok := mindmap.Nodes[id]; ok {
+					matches = []*model.Node{n}
+				}
+			} else {
+				matches = byName[referenceKey(target)]
+			}
+
+			switch {
+			case len(matches) == 1:
+				if matches[0].ID != node.ID && !slices.Contains(result.Linked, matches[0]) {
+					result.Linked = append(result.Linked, matches[0])
+				}
+			case len(matches) > 1:
+				result.Ambiguous = append(result.Ambiguous, target)
+			case create && !strings.HasPrefix(target, "#"):
+				created, err := m.wikiNodeCreate(mindmap, &inbox, target)
+				if err != nil {
+					return err
+				}
+				byName[referenceKey(target)] = []*model.Node{created}
+				result.Created = append(result.Created, created)
+				result.Linked = append(result.Linked, created)
+			default:
+				result.Unresolved = append(result.Unresolved, target)
+			}
+		}
+		return m.wikiLinksReplace(mindmap, node, result.Linked)
+	})
+	if err != nil {
+		m.Logger.Error(ctx, "Failed to resolve wiki links", log.Fields{"error": err, "nodeID": node.ID})
+		return nil, fmt.Errorf("failed to resolve wiki links: %w", err)
+	}
+
+	m.Logger.Info(ctx, "Wiki links resolved", log.Fields{"nodeID": node.ID, "linked": len(result.Linked), "created": len(result.Created), "unresolved": len(result.Unresolved), "ambiguous": len(result.Ambiguous)})
+	return result, nil
+}
+
+// wikiNodeCreate creates a node named after an unresolved reference under the inbox node, finding or creating
+// the top-level Inbox node first if no inbox is set
+func (m *DataManager) wikiNodeCreate(mindmap *model.Mindmap, inbox **model.Node, name string) (*model.Node, error) {
+	if *inbox == nil {
+		var err error
+		if *inbox, err = m.inboxNode(mindmap); err != nil {
+			return nil, err
+		}
+	}
+
+	id, _, err := m.NodeManager.NodeAdd(mindmap, model.NodeInfo{ParentID: (*inbox).ID, Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add node %s: %w", name, err)
+	}
+	return mindmap.Nodes[id], nil
+}
+
+// wikiLinksReplace replaces the wiki links of a node with links to the given targets, keeping unchanged links
+func (m *DataManager) wikiLinksReplace(mindmap *model.Mindmap, node *model.Node, targets []*model.Node) error {
+	links, err := m.LinkManager.LinkGet(mindmap, node, model.LinkTypeWiki)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[int]bool)
+	for _, link := range links {
+		if slices.ContainsFunc(targets, func(t *model.Node) bool { return t.ID == link.TargetID }) {
+			existing[link.TargetID] = true
+			continue
+		}
+		if err := m.LinkManager.LinkDelete(link); err != nil {
+			return err
+		}
+	}
+
+	for _, target := range targets {
+		if existing[target.ID] {
+			continue
+		}
+		if err := m.LinkManager.LinkAdd(mindmap, node, target, model.LinkTypeWiki); err != nil {
+			return err
+		}
+	}
+	return nil
+}
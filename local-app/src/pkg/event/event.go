@@ -3,11 +3,33 @@ package event
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"mindnoscape/local-app/src/pkg/log"
 )
 
+const (
+	defaultQueueSize      = 256
+	defaultPublishTimeout = 2 * time.Second
+	defaultHandlerTimeout = 10 * time.Second
+	defaultHandlerRetries = 2
+	retryBackoff          = 100 * time.Millisecond
+)
+
+var (
+	// ErrClosed is returned when publishing to a closed EventManager
+	ErrClosed = errors.New("event manager closed")
+	// ErrQueueFull is returned when an event could not be queued within the publish timeout
+	ErrQueueFull = errors.New("event queue full")
+	// ErrTimeout is returned when waiting for event handlers takes longer than the handler timeout
+	ErrTimeout = errors.New("timed out waiting for event handlers")
+)
+
 // EventType represents the type of event
 type EventType int
 
@@ -21,10 +43,48 @@ const (
 	NodeSorted
 	RootNodeRenamed
 	MindmapSelected
+	ReminderDue
+	SessionExpired
+	NodeAdded
 )
 
-// Event represents an event with its type and associated data
+// String returns the string representation of the EventType
+func (t EventType) String() string {
+	switch t {
+	case UserDeleted:
+		return "UserDeleted"
+	case MindmapAdded:
+		return "MindmapAdded"
+	case MindmapDeleted:
+		return "MindmapDeleted"
+	case MindmapUpdated:
+		return "MindmapUpdated"
+	case NodeUpdated:
+		return "NodeUpdated"
+	case NodeDeleted:
+		return "NodeDeleted"
+	case NodeSorted:
+		return "NodeSorted"
+	case RootNodeRenamed:
+		return "RootNodeRenamed"
+	case MindmapSelected:
+		return "MindmapSelected"
+	case ReminderDue:
+		return "ReminderDue"
+	case SessionExpired:
+		return "SessionExpired"
+	case NodeAdded:
+		return "NodeAdded"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event represents an event with its type and associated data.
+// ID is assigned on publication from a monotonically increasing sequence unless already set,
+// so a republished event keeps its identity and is recognized as a duplicate.
 type Event struct {
+	ID   uint64
 	Type EventType
 	Data interface{}
 }
@@ -32,18 +92,68 @@ type Event struct {
 // EventHandler is a function type for event handlers
 type EventHandler func(Event)
 
-// EventManager manages event subscriptions and publications
+// QueueStats contains the delivery metrics of a single event type queue
+type QueueStats struct {
+	Type       EventType
+	Depth      int
+	MaxDepth   int
+	Capacity   int
+	Published  uint64
+	Delivered  uint64
+	Failed     uint64
+	Dropped    uint64
+	Duplicates uint64
+	LastID     uint64
+}
+
+// queuedEvent is an event waiting in a queue, with an optional channel closed once all handlers ran
+type queuedEvent struct {
+	event Event
+	done  chan struct{}
+}
+
+// eventQueue is the ordered, bounded queue of a single event type
+type eventQueue struct {
+	events     chan queuedEvent
+	publishMu  sync.Mutex
+	maxDepth   atomic.Int64
+	published  atomic.Uint64
+	delivered  atomic.Uint64
+	failed     atomic.Uint64
+	dropped    atomic.Uint64
+	duplicates atomic.Uint64
+	lastID     atomic.Uint64
+}
+
+// EventManager manages event subscriptions and publications.
+// Events of the same type are delivered in publication order by a dedicated worker,
+// so a slow or failing handler delays only events of its own type.
 type EventManager struct {
-	subscribers map[EventType][]EventHandler
-	mu          sync.RWMutex
-	logger      *log.Logger
+	subscribers    map[EventType][]EventHandler
+	queues         map[EventType]*eventQueue
+	mu             sync.RWMutex
+	wg             sync.WaitGroup
+	done           chan struct{}
+	closed         atomic.Bool
+	nextID         atomic.Uint64
+	queueSize      int
+	publishTimeout time.Duration
+	handlerTimeout time.Duration
+	handlerRetries int
+	logger         *log.Logger
 }
 
 // NewEventManager creates a new EventManager instance
 func NewEventManager(logger *log.Logger) *EventManager {
 	return &EventManager{
-		subscribers: make(map[EventType][]EventHandler),
-		logger:      logger,
+		subscribers:    make(map[EventType][]EventHandler),
+		queues:         make(map[EventType]*eventQueue),
+		done:           make(chan struct{}),
+		queueSize:      defaultQueueSize,
+		publishTimeout: defaultPublishTimeout,
+		handlerTimeout: defaultHandlerTimeout,
+		handlerRetries: defaultHandlerRetries,
+		logger:         logger,
 	}
 }
 
@@ -54,21 +164,219 @@ func (em *EventManager) Subscribe(eventType EventType, handler EventHandler) {
 	em.subscribers[eventType] = append(em.subscribers[eventType], handler)
 }
 
-// Publish sends an event to all subscribed handlers
+// Publish queues an event for asynchronous delivery to all subscribed handlers
 func (em *EventManager) Publish(event Event) {
+	if err := em.enqueue(event, nil); err != nil {
+		em.logger.Error(context.Background(), "Failed to publish event", log.Fields{"event": event.Type.String(), "error": err})
+	}
+}
+
+// PublishAndWait queues an event and waits until all subscribed handlers have processed it.
+// It must not be called from a handler of the same event type, as that handler's worker would wait for itself.
+func (em *EventManager) PublishAndWait(event Event) error {
+	done := make(chan struct{})
+	if err := em.enqueue(event, done); err != nil {
+		em.logger.Error(context.Background(), "Failed to publish event", log.Fields{"event": event.Type.String(), "error": err})
+		return err
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(em.handlerTimeout):
+		em.logger.Warn(context.Background(), "Timed out waiting for event handlers", log.Fields{"event": event.Type.String()})
+		return ErrTimeout
+	}
+}
+
+// Stats returns the delivery metrics of all event queues, ordered by event type
+func (em *EventManager) Stats() []QueueStats {
 	em.mu.RLock()
 	defer em.mu.RUnlock()
-	for _, handler := range em.subscribers[event.Type] {
-		go func(h EventHandler) {
-			defer func() {
-				if r := recover(); r != nil {
-					em.logger.Error(context.Background(), "Panic in event handler", log.Fields{
-						"event": event,
-						"panic": r,
-					})
+
+	stats := make([]QueueStats, 0, len(em.queues))
+	for eventType, q := range em.queues {
+		stats = append(stats, QueueStats{
+			Type:       eventType,
+			Depth:      len(q.events),
+			MaxDepth:   int(q.maxDepth.Load()),
+			Capacity:   cap(q.events),
+			Published:  q.published.Load(),
+			Delivered:  q.delivered.Load(),
+			Failed:     q.failed.Load(),
+			Dropped:    q.dropped.Load(),
+			Duplicates: q.duplicates.Load(),
+			LastID:     q.lastID.Load(),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Type < stats[j].Type })
+	return stats
+}
+
+// Close stops accepting events, delivers the events already queued and stops the workers
+func (em *EventManager) Close() {
+	if em.closed.Swap(true) {
+		return
+	}
+	close(em.done)
+	em.wg.Wait()
+	em.logger.Info(context.Background(), "Event manager closed", nil)
+}
+
+// enqueue places the event in the queue of its type, waiting at most the publish timeout for space
+func (em *EventManager) enqueue(event Event, done chan struct{}) error {
+	if em.closed.Load() {
+		return ErrClosed
+	}
+
+	q := em.queue(event.Type)
+	q.published.Add(1)
+
+	// Assign the ID and queue the event atomically, so IDs enter each queue in increasing order
+	q.publishMu.Lock()
+	defer q.publishMu.Unlock()
+	if event.ID == 0 {
+		event.ID = em.nextID.Add(1)
+	}
+
+	select {
+	case q.events <- queuedEvent{event: event, done: done}:
+	case <-time.After(em.publishTimeout):
+		q.dropped.Add(1)
+		return ErrQueueFull
+	}
+
+	depth := int64(len(q.events))
+	for {
+		maxDepth := q.maxDepth.Load()
+		if depth <= maxDepth || q.maxDepth.CompareAndSwap(maxDepth, depth) {
+			break
+		}
+	}
+	return nil
+}
+
+// queue returns the queue of the event type, starting its worker on first use
+func (em *EventManager) queue(eventType EventType) *eventQueue {
+	em.mu.RLock()
+	q, exists := em.queues[eventType]
+	em.mu.RUnlock()
+	if exists {
+		return q
+	}
+
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	if q, exists = em.queues[eventType]; exists {
+		return q
+	}
+
+	q = &eventQueue{events: make(chan queuedEvent, em.queueSize)}
+	em.queues[eventType] = q
+	em.wg.Add(1)
+	go em.dispatch(q)
+	return q
+}
+
+// dispatch delivers the events of a queue in order until the manager is closed
+func (em *EventManager) dispatch(q *eventQueue) {
+	defer em.wg.Done()
+	for {
+		select {
+		case qe := <-q.events:
+			em.deliver(q, qe)
+		case <-em.done:
+			// Drain the events queued before closing
+			for {
+				select {
+				case qe := <-q.events:
+					em.deliver(q, qe)
+				default:
+					return
 				}
-			}()
-			h(event)
-		}(handler)
+			}
+		}
+	}
+}
+
+// deliver runs all handlers subscribed to the event type for a queued event.
+// Events of a type are delivered in ID order, so an event whose ID is not newer than
+// the last delivered one is a duplicate or replay and is skipped.
+func (em *EventManager) deliver(q *eventQueue, qe queuedEvent) {
+	if qe.event.ID <= q.lastID.Load() {
+		em.logger.Warn(context.Background(), "Skipping duplicate event", log.Fields{"event": qe.event.Type.String(), "eventID": qe.event.ID})
+		q.duplicates.Add(1)
+		if qe.done != nil {
+			close(qe.done)
+		}
+		return
+	}
+	q.lastID.Store(qe.event.ID)
+
+	em.mu.RLock()
+	handlers := make([]EventHandler, len(em.subscribers[qe.event.Type]))
+	copy(handlers, em.subscribers[qe.event.Type])
+	em.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if em.runHandler(handler, qe.event) {
+			q.delivered.Add(1)
+		} else {
+			q.failed.Add(1)
+		}
+	}
+
+	if qe.done != nil {
+		close(qe.done)
+	}
+}
+
+// runHandler invokes a handler, retrying after a panic. A handler exceeding the handler timeout
+// is abandoned without retry, since it may still be running. Returns whether the handler succeeded.
+func (em *EventManager) runHandler(handler EventHandler, event Event) bool {
+	ctx := context.Background()
+
+	for attempt := 1; attempt <= em.handlerRetries+1; attempt++ {
+		err := em.invoke(handler, event)
+		if err == nil {
+			return true
+		}
+		if errors.Is(err, ErrTimeout) {
+			em.logger.Error(ctx, "Event handler timed out", log.Fields{"event": event.Type.String(), "timeout": em.handlerTimeout.String()})
+			return false
+		}
+
+		em.logger.Warn(ctx, "Event handler failed", log.Fields{"event": event.Type.String(), "attempt": attempt, "error": err})
+		if attempt <= em.handlerRetries {
+			time.Sleep(time.Duration(attempt) * retryBackoff)
+		}
+	}
+
+	em.logger.Error(ctx, "Event handler failed after retries", log.Fields{"event": event.Type.String(), "retries": em.handlerRetries})
+	return false
+}
+
+// invoke runs a handler in its own goroutine, converting a panic into an error and enforcing the handler timeout
+func (em *EventManager) invoke(handler EventHandler, event Event) error {
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				em.logger.Error(context.Background(), "Panic in event handler", log.Fields{
+					"event": event,
+					"panic": r,
+				})
+				result <- fmt.Errorf("panic in event handler: %v", r)
+			}
+		}()
+		handler(event)
+		result <- nil
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(em.handlerTimeout):
+		return ErrTimeout
 	}
 }
@@ -0,0 +1,86 @@
+// Package hooks runs the user scripts configured to run before or after commands
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// Hook stages
+const (
+	Pre  = "pre"  // Runs before the command, a failing hook refuses the command
+	Post = "post" // Runs after the command with its outcome, failures are only logged
+)
+
+// defaultTimeout is the time allowed for a hook without a configured timeout
+const defaultTimeout = 30 * time.Second
+
+// Payload is the JSON written to the standard input of a hook
+type Payload struct {
+	When      string   `json:"when"`
+	Scope     string   `json:"scope"`
+	Operation string   `json:"operation"`
+	Args      []string `json:"args"` // Passwords are redacted
+	User      string   `json:"user,omitempty"`
+	Mindmap   string   `json:"mindmap,omitempty"`
+	Success   *bool    `json:"success,omitempty"` // Outcome of the command, post hooks only
+	Result    string   `json:"result,omitempty"`  // Text result of the command, post hooks only
+	Error     string   `json:"error,omitempty"`   // Error of the command, post hooks only
+}
+
+// Matching returns the hooks of a stage configured for a command. The command of a hook is a scope and an
+// operation, such as "node delete", or a scope alone for all of its operations.
+func Matching(hooks []model.CommandHook, when, scope, operation string) []model.CommandHook {
+	var matching []model.CommandHook
+	for _, hook := range hooks {
+		if hook.When != when {
+			continue
+		}
+		fields := strings.Fields(hook.Command)
+		if len(fields) == 0 || fields[0] != scope || len(fields) > 1 && fields[1] != operation {
+			continue
+		}
+		matching = append(matching, hook)
+	}
+	return matching
+}
+
+// Run runs a hook with the payload on its standard input, failing if the program cannot run, exits with a
+// non-zero status or exceeds its timeout. The error includes the output of the program.
+func Run(ctx context.Context, hook model.CommandHook, payload Payload) error {
+	if hook.Program == "" {
+		return fmt.Errorf("hook for %s has no program", hook.Command)
+	}
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook payload: %w", err)
+	}
+
+	timeout := defaultTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, hook.Program, hook.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("hook %s timed out after %s", hook.Program, timeout)
+		}
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("hook %s failed: %w: %s", hook.Program, err, message)
+		}
+		return fmt.Errorf("hook %s failed: %w", hook.Program, err)
+	}
+	return nil
+}
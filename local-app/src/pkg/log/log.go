@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"mindnoscape/local-app/src/pkg/model"
 )
@@ -34,7 +36,47 @@ type Logger struct {
 	logChan       chan LogMessage
 	done          chan struct{}
 	wg            sync.WaitGroup
-	level         LogLevel
+	level         atomic.Int32 // Most verbose LogLevel written, messages of higher levels are dropped unformatted
+	sampler       debugSampler
+}
+
+// Debug messages repeated within a sampling window are sampled: the first debugSampleFirst of each message are
+// written, then one in every debugSampleEvery. Loops such as imports log the same messages for every node.
+const (
+	debugSampleWindow = time.Second
+	debugSampleFirst  = 20
+	debugSampleEvery  = 100
+)
+
+// debugSampler counts the debug messages of the current sampling window by message
+type debugSampler struct {
+	mu     sync.Mutex
+	start  time.Time
+	counts map[string]int
+}
+
+// allow counts a debug message and reports whether it is written
+func (s *debugSampler) allow(message string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.counts == nil || now.Sub(s.start) >= debugSampleWindow {
+		s.start = now
+		s.counts = make(map[string]int)
+	}
+	s.counts[message]++
+	count := s.counts[message]
+	return count <= debugSampleFirst || (count-debugSampleFirst)%debugSampleEvery == 0
+}
+
+// attrPool holds the attribute slices the fields of messages are converted to for writing, the JSON handlers
+// encode the records into their own pooled buffers
+var attrPool = sync.Pool{
+	New: func() any {
+		attrs := make([]slog.Attr, 0, 8)
+		return &attrs
+	},
 }
 
 // NewLogger creates a new Logger instance with specified log folder and file names
@@ -82,8 +124,8 @@ func NewLogger(cfg *model.Config, level LogLevel) (*Logger, error) {
 		infoFile:      infoFile,
 		logChan:       make(chan LogMessage, 100), // Buffered channel with capacity of 100
 		done:          make(chan struct{}),
-		level:         level,
 	}
+	logger.level.Store(int32(level))
 
 	// Start the logging goroutine
 	logger.wg.Add(1)
@@ -98,52 +140,89 @@ func (l *Logger) processLogs() {
 	for {
 		select {
 		case msg := <-l.logChan:
-			attrs := make([]slog.Attr, 0, len(msg.Fields))
-			for k, v := range msg.Fields {
-				attrs = append(attrs, slog.Any(k, v))
-			}
-			switch msg.Level {
-			case LevelCommand:
-				l.commandLogger.LogAttrs(msg.Context, msg.Level.toSlogLevel(), msg.Content, attrs...)
-			case LevelError, LevelWarn:
-				l.errorLogger.LogAttrs(msg.Context, msg.Level.toSlogLevel(), msg.Content, attrs...)
-			case LevelInfo, LevelDebug:
-				l.infoLogger.LogAttrs(msg.Context, msg.Level.toSlogLevel(), msg.Content, attrs...)
-			}
+			l.write(msg)
 		case <-l.done:
-			return
+			// Write the messages still buffered before closing
+			for {
+				select {
+				case msg := <-l.logChan:
+					l.write(msg)
+				default:
+					return
+				}
+			}
 		}
 	}
 }
 
+// write writes a log message to the log file of its level
+func (l *Logger) write(msg LogMessage) {
+	pooled := attrPool.Get().(*[]slog.Attr)
+	attrs := (*pooled)[:0]
+	for k, v := range msg.Fields {
+		attrs = append(attrs, slog.Any(k, v))
+	}
+	defer func() {
+		// Drop the references to the field values before the slice is reused
+		clear(attrs)
+		*pooled = attrs[:0]
+		attrPool.Put(pooled)
+	}()
+
+	switch msg.Level {
+	case LevelCommand:
+		l.commandLogger.LogAttrs(msg.Context, msg.Level.toSlogLevel(), msg.Content, attrs...)
+	case LevelError, LevelWarn:
+		l.errorLogger.LogAttrs(msg.Context, msg.Level.toSlogLevel(), msg.Content, attrs...)
+	case LevelInfo, LevelDebug:
+		l.infoLogger.LogAttrs(msg.Context, msg.Level.toSlogLevel(), msg.Content, attrs...)
+	}
+}
+
+// Enabled reports whether messages of a level are written, so that callers can skip building costly fields
+func (l *Logger) Enabled(level LogLevel) bool {
+	return level <= LogLevel(l.level.Load())
+}
+
+// send queues a message for writing, unless its level is filtered or it is a sampled out debug message
+func (l *Logger) send(ctx context.Context, level LogLevel, message string, fields Fields) {
+	if !l.Enabled(level) {
+		return
+	}
+	if level == LevelDebug && !l.sampler.allow(message) {
+		return
+	}
+	l.logChan <- LogMessage{Level: level, Content: message, Fields: fields, Context: ctx}
+}
+
 // Command logs a command message
 func (l *Logger) Command(ctx context.Context, message string, fields Fields) {
-	l.logChan <- LogMessage{Level: LevelCommand, Content: message, Fields: fields, Context: ctx}
+	l.send(ctx, LevelCommand, message, fields)
 }
 
 // Error logs an error message
 func (l *Logger) Error(ctx context.Context, message string, fields Fields) {
-	l.logChan <- LogMessage{Level: LevelError, Content: message, Fields: fields, Context: ctx}
+	l.send(ctx, LevelError, message, fields)
 }
 
 // Warn logs a warning message
 func (l *Logger) Warn(ctx context.Context, message string, fields Fields) {
-	l.logChan <- LogMessage{Level: LevelWarn, Content: message, Fields: fields, Context: ctx}
+	l.send(ctx, LevelWarn, message, fields)
 }
 
 // Info logs an info message
 func (l *Logger) Info(ctx context.Context, message string, fields Fields) {
-	l.logChan <- LogMessage{Level: LevelInfo, Content: message, Fields: fields, Context: ctx}
+	l.send(ctx, LevelInfo, message, fields)
 }
 
 // Debug logs a debug message
 func (l *Logger) Debug(ctx context.Context, message string, fields Fields) {
-	l.logChan <- LogMessage{Level: LevelDebug, Content: message, Fields: fields, Context: ctx}
+	l.send(ctx, LevelDebug, message, fields)
 }
 
 // SetLevel sets the logging level
 func (l *Logger) SetLevel(level LogLevel) {
-	l.level = level
+	l.level.Store(int32(level))
 }
 
 // Close stops the logging goroutine and closes all log files
@@ -151,6 +230,13 @@ func (l *Logger) Close() error {
 	close(l.done)
 	l.wg.Wait() // Wait for the logging goroutine to finish
 
+	// Log files are appended in place, so flush them to disk to not leave a truncated last entry
+	for _, file := range []*os.File{l.commandFile, l.errorFile, l.infoFile} {
+		if err := file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync log file %s: %w", file.Name(), err)
+		}
+	}
+
 	if err := l.commandFile.Close(); err != nil {
 		return fmt.Errorf("failed to close command log file: %w", err)
 	}
@@ -1,7 +1,11 @@
 // Package log provides functionality for logging commands and errors
 package log
 
-import "log/slog"
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
 
 // LogLevel represents the type and severity of a log message
 type LogLevel int
@@ -32,6 +36,16 @@ func (l LogLevel) String() string {
 	}
 }
 
+// ParseLevel returns the LogLevel named by level, case-insensitively. Command messages are always logged.
+func ParseLevel(level string) (LogLevel, error) {
+	for _, l := range []LogLevel{LevelError, LevelWarn, LevelInfo, LevelDebug} {
+		if strings.EqualFold(level, l.String()) {
+			return l, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level: %s", level)
+}
+
 // toSlogLevel converts our custom LogLevel to slog.Level
 func (l LogLevel) toSlogLevel() slog.Level {
 	switch l {
@@ -0,0 +1,43 @@
+// Package mindnoscape embeds the Mindnoscape mindmap engine in other Go programs, without the CLI and adapters.
+//
+// An Engine bundles the storage, the data managers and the logger of one Mindnoscape data directory:
+//
+//	engine, err := mindnoscape.Open(mindnoscape.DefaultConfig("./mindmaps"))
+//	if err != nil {
+//		return err
+//	}
+//	defer engine.Close()
+//
+//	user, err := engine.UserAdd("alice", nil)
+//	if err != nil {
+//		return err
+//	}
+//	mindmap, err := engine.MindmapAdd(user, "Ideas")
+//	if err != nil {
+//		return err
+//	}
+//	if _, err := engine.NodeAdd(mindmap, mindmap.Root, "Topic", map[string]string{"priority": "1"}); err != nil {
+//		return err
+//	}
+//	for depth, node := range mindmap.Walk(nil, nil) {
+//		fmt.Printf("%*s%s\n", depth*2, "", node.Name)
+//	}
+//
+// The Engine methods cover the common operations. The managers in the Users, Mindmaps and Nodes fields expose
+// the complete data API, with the model types aliased in this package. The examples of this package run against
+// a temporary data directory.
+//
+// # Module
+//
+// This package has no go.mod of its own: it is part of the mindnoscape/local-app module, which embedding programs
+// require, and is imported as mindnoscape/local-app/src/pkg/mindnoscape. It builds on the other packages of the
+// module and shares its dependencies, such as go-sqlite3, so a separate module would have to require the whole of
+// local-app anyway, and keeping them together releases the engine along with the program it was tested with.
+//
+// # Versioning
+//
+// The API of this package follows semantic versioning, with the version in Version. Within a major version,
+// exported identifiers of this package are not removed or changed incompatibly. The packages it builds on
+// (model, data, storage) may change between minor versions; only the parts reachable through this package
+// are covered by the guarantee.
+package mindnoscape
@@ -0,0 +1,216 @@
+package mindnoscape
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"mindnoscape/local-app/src/pkg/config"
+	"mindnoscape/local-app/src/pkg/data"
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// Version is the semantic version of the embedding API
+const Version = "0.1.0"
+
+// Model types of the embedding API
+type (
+	Config        = model.Config
+	User          = model.User
+	Mindmap       = model.Mindmap
+	MindmapStats  = model.MindmapStats
+	Node          = model.Node
+	NodeMatch     = model.NodeMatch
+	ExportOptions = model.ExportOptions
+	ExportFormat  = model.ExportFormat
+	ImportFormat  = model.ImportFormat
+	ImportOptions = model.ImportOptions
+	ImportPolicy  = model.ImportPolicy
+	ImportMerge   = model.ImportMerge
+	Progress      = model.Progress
+	ProgressFunc  = model.ProgressFunc
+)
+
+// Import policies for the nodes of an imported mindmap already present in an existing one, see ImportOptions
+const (
+	ImportSkip      = model.ImportSkip
+	ImportUpdate    = model.ImportUpdate
+	ImportDuplicate = model.ImportDuplicate
+	ImportSync      = model.ImportSync
+)
+
+// RegisterExportFormat adds a format mindmaps can be exported in, by the engines and the CLI commands of the
+// program. Formats are registered once by name, before they are used.
+func RegisterExportFormat(format ExportFormat) error {
+	return storage.RegisterExportFormat(format)
+}
+
+// RegisterImportFormat adds a format mindmaps can be imported from, see RegisterExportFormat
+func RegisterImportFormat(format ImportFormat) error {
+	return storage.RegisterImportFormat(format)
+}
+
+// Engine is an embedded Mindnoscape instance working on one data directory
+type Engine struct {
+	Users    *data.UserManager
+	Mindmaps *data.MindmapManager
+	Nodes    *data.NodeManager
+
+	data   *data.DataManager
+	store  *storage.Storage
+	logger *log.Logger
+}
+
+// DefaultConfig returns the default configuration with the database, logs, exports and keys placed in dir.
+// Unlike the CLI, no default user is created.
+func DefaultConfig(dir string) *Config {
+	cfg := config.ConfigDefault()
+	cfg.DatabaseDir = dir
+	cfg.LogFolder = filepath.Join(dir, "logs")
+	cfg.ExportDir = filepath.Join(dir, "exports")
+	cfg.KeyDir = filepath.Join(dir, "keys")
+	cfg.DefaultUserActive = false
+	return cfg
+}
+
+// Open opens the storage described by cfg and starts the data managers. The Engine must be closed after use.
+func Open(cfg *Config) (*Engine, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config not specified")
+	}
+
+	level := log.LevelInfo
+	if cfg.LogLevel != "" {
+		var err error
+		if level, err = log.ParseLevel(cfg.LogLevel); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	logger, err := log.NewLogger(cfg, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	store, err := storage.NewStorage(cfg, logger)
+	if err != nil {
+		logger.Error(context.Background(), "Failed to initialize storage", log.Fields{"error": err})
+		logger.Close()
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	dataManager, err := data.NewDataManager(store, cfg, logger)
+	if err != nil {
+		logger.Error(context.Background(), "Failed to initialize data manager", log.Fields{"error": err})
+		store.Close()
+		logger.Close()
+		return nil, fmt.Errorf("failed to initialize data manager: %w", err)
+	}
+
+	logger.Info(context.Background(), "Engine opened", log.Fields{"version": Version})
+	return &Engine{
+		Users:    dataManager.UserManager,
+		Mindmaps: dataManager.MindmapManager,
+		Nodes:    dataManager.NodeManager,
+		data:     dataManager,
+		store:    store,
+		logger:   logger,
+	}, nil
+}
+
+// Close stops the data managers and closes the storage and the logger
+func (e *Engine) Close() error {
+	e.logger.Info(context.Background(), "Closing engine", nil)
+	e.data.Close()
+
+	if err := e.store.Close(); err != nil {
+		e.logger.Close()
+		return fmt.Errorf("failed to close storage: %w", err)
+	}
+	if err := e.logger.Close(); err != nil {
+		return fmt.Errorf("failed to close logger: %w", err)
+	}
+	return nil
+}
+
+// UserAdd adds a user with the given password hash, which may be nil for users without a password, and returns it
+func (e *Engine) UserAdd(username string, passwordHash []byte) (*User, error) {
+	if passwordHash == nil {
+		passwordHash = []byte{}
+	}
+	if _, err := e.Users.UserAdd(model.UserInfo{Username: username, PasswordHash: passwordHash}); err != nil {
+		return nil, err
+	}
+	return e.User(username)
+}
+
+// User returns the user with the given name
+func (e *Engine) User(username string) (*User, error) {
+	users, err := e.Users.UserGet(model.UserInfo{Username: username}, model.UserFilter{Username: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("user not found: %s", username)
+	}
+	return users[0], nil
+}
+
+// MindmapAdd adds a mindmap owned by user and returns it with its root node loaded
+func (e *Engine) MindmapAdd(user *User, name string) (*Mindmap, error) {
+	if _, err := e.Mindmaps.MindmapAdd(user, model.MindmapInfo{Name: name}); err != nil {
+		return nil, err
+	}
+	return e.Mindmap(user, name)
+}
+
+// Mindmap returns the mindmap of the given name accessible to user, with all its nodes loaded
+func (e *Engine) Mindmap(user *User, name string) (*Mindmap, error) {
+	mindmaps, err := e.Mindmaps.MindmapGet(user, model.MindmapInfo{Name: name}, model.MindmapFilter{Name: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(mindmaps) == 0 {
+		return nil, fmt.Errorf("mindmap not found: %s", name)
+	}
+	mindmap := mindmaps[0]
+
+	// Nodes are loaded by the node manager on selection of the mindmap
+	err = e.data.EventManager.PublishAndWait(event.Event{Type: event.MindmapSelected, Data: mindmap})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mindmap: %w", err)
+	}
+	return mindmap, nil
+}
+
+// NodeAdd adds a node with an optional content map under parent and returns it
+func (e *Engine) NodeAdd(mindmap *Mindmap, parent *Node, name string, content map[string]string) (*Node, error) {
+	if parent == nil {
+		return nil, fmt.Errorf("parent node not specified")
+	}
+	id, _, err := e.Nodes.NodeAdd(mindmap, model.NodeInfo{ParentID: parent.ID, Name: name, Content: content})
+	if err != nil {
+		return nil, err
+	}
+	return mindmap.Nodes[id], nil
+}
+
+// Export writes mindmap to a file in the export directory and returns the path of the written file
+func (e *Engine) Export(user *User, mindmap *Mindmap, options ExportOptions) (string, error) {
+	return e.data.MindmapExport(user, mindmap, options)
+}
+
+// Import reads a mindmap from a file in the export directory and stores it for user, replacing a mindmap of the
+// same name. Integrity problems that did not prevent the import are returned as warnings.
+func (e *Engine) Import(user *User, options ImportOptions) (*Mindmap, []string, error) {
+	return e.data.MindmapImport(user, options)
+}
+
+// ImportMerge reads a mindmap from a JSON or XML file in the export directory and merges it into the mindmap of
+// user with the same name, by the import policy of options, or stores it if there is none. Returns the merged
+// mindmap, the counts of the merged nodes and the integrity warnings.
+func (e *Engine) ImportMerge(user *User, options ImportOptions) (*Mindmap, ImportMerge, []string, error) {
+	return e.data.MindmapImportMerge(user, options)
+}
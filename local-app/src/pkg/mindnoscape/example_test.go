@@ -0,0 +1,111 @@
+package mindnoscape_test
+
+import (
+	"fmt"
+	"os"
+
+	"mindnoscape/local-app/src/pkg/mindnoscape"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// openExample opens an engine on a new data directory, returning a function that closes it and removes the directory
+func openExample() (*mindnoscape.Engine, func(), error) {
+	dir, err := os.MkdirTemp("", "mindnoscape-example")
+	if err != nil {
+		return nil, nil, err
+	}
+	engine, err := mindnoscape.Open(mindnoscape.DefaultConfig(dir))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	return engine, func() {
+		engine.Close()
+		os.RemoveAll(dir)
+	}, nil
+}
+
+// Example opens an engine, adds a user, a mindmap and nodes to it, and walks the mindmap
+func Example() {
+	engine, closeEngine, err := openExample()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer closeEngine()
+
+	user, err := engine.UserAdd("alice", nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	mindmap, err := engine.MindmapAdd(user, "Ideas")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	topic, err := engine.NodeAdd(mindmap, mindmap.Root, "Topic", map[string]string{"priority": "1"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if _, err := engine.NodeAdd(mindmap, topic, "Detail", nil); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for depth, node := range mindmap.Walk(nil, nil) {
+		fmt.Printf("%*s%s\n", depth*2, "", node.Name)
+	}
+	// Output:
+	// Ideas
+	//   Topic
+	//     Detail
+}
+
+// Example_query finds the nodes of a mindmap with a node query, after opening it again as a program would
+func Example_query() {
+	engine, closeEngine, err := openExample()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer closeEngine()
+
+	user, err := engine.UserAdd("alice", nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	mindmap, err := engine.MindmapAdd(user, "Ideas")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for i, name := range []string{"Call the plumber", "Write the report", "Call the bank"} {
+		content := map[string]string{"priority": fmt.Sprint(i + 1)}
+		if _, err := engine.NodeAdd(mindmap, mindmap.Root, name, content); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	// Mindmap loads all the nodes stored
+	mindmap, err = engine.Mindmap(user, "Ideas")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	filter := model.NodeFilter{Name: true, Content: true}
+	nodes, err := engine.Nodes.NodeFind(mindmap, filter, "name:call content.priority:/^[12]$/ OR report")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, node := range nodes {
+		fmt.Println(node.Index, node.Name)
+	}
+	// Unordered output:
+	// 1 Call the plumber
+	// 2 Write the report
+}
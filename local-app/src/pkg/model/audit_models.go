@@ -0,0 +1,62 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Audit result values
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// AuditEntry represents a single record of the append-only audit log.
+type AuditEntry struct {
+	ID        int       `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	SessionID string    `json:"session_id"`
+	Username  string    `json:"username"`
+	Scope     string    `json:"scope"`
+	Operation string    `json:"operation"`
+	MindmapID int       `json:"mindmap_id"`
+	Target    string    `json:"target"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+	Detail    string    `json:"detail,omitempty"` // Changes made by the command, such as the diff of a node update
+}
+
+// AuditFilter defines the options for filtering audit entries.
+type AuditFilter struct {
+	Username  bool
+	Scope     bool
+	Operation bool
+	MindmapID bool
+	Result    bool
+	Since     bool // Entries at or after the Timestamp of the audit info
+}
+
+// sinceUnits are the units of the ages accepted by ParseSince
+var sinceUnits = map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+
+// ParseSince parses the start of a period until now: an age counted back from now as a number of minutes, hours,
+// days or weeks such as 30m, 12h, 2d or 1w, or a date as YYYY-MM-DD or a date and time as YYYY-MM-DDTHH:MM.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if n := len(value); n > 1 {
+		if unit, ok := sinceUnits[value[n-1]]; ok {
+			if count, err := strconv.Atoi(value[:n-1]); err == nil && count >= 0 {
+				return now.Add(-time.Duration(count) * unit), nil
+			}
+		}
+	}
+	for _, layout := range []string{ReminderTimeLayout, ReminderDateLayout} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s': must be an age such as 30m, 12h, 2d or 1w, or a date as YYYY-MM-DD or YYYY-MM-DDTHH:MM", value)
+}
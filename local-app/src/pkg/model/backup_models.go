@@ -0,0 +1,11 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import "time"
+
+// Backup is a snapshot of the database in the backup directory, named by the time it was taken
+type Backup struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+}
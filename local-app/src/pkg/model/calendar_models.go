@@ -0,0 +1,13 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import "time"
+
+// CalendarFeed is a read-only ICS feed of the dated nodes of a user, served by the web adapter to the calendars
+// subscribed to it. Calendars can't log in, so the unguessable token in its URL is what grants access.
+type CalendarFeed struct {
+	Token     string    `json:"-"`
+	Username  string    `json:"username"`
+	MindmapID int       `json:"mindmap_id"` // The mindmap of the feed, all own mindmaps of the user if 0
+	Created   time.Time `json:"created"`
+}
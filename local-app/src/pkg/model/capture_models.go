@@ -0,0 +1,27 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import "time"
+
+// CaptureAccount is the mailbox of a user polled by the email capture service. New emails become nodes under
+// the Inbox node of the target mindmap.
+type CaptureAccount struct {
+	Username    string    `json:"username"`
+	Server      string    `json:"server"` // IMAP server as host[:port], connected with TLS
+	Login       string    `json:"login"`
+	Password    string    `json:"-"` // Stored in plain text, as the IMAP login needs it
+	Mailbox     string    `json:"mailbox"`
+	MindmapID   int       `json:"mindmap_id"`
+	UIDValidity uint32    `json:"uid_validity"` // UIDs are only comparable within the same UID validity
+	LastUID     uint32    `json:"last_uid"`     // UID of the last captured email
+	Captured    int       `json:"captured"`     // Number of emails captured so far
+	LastPoll    time.Time `json:"last_poll"`
+	LastError   string    `json:"last_error"`
+}
+
+// CaptureItem is an email to be captured as a node
+type CaptureItem struct {
+	UID     uint32
+	Name    string
+	Content map[string]string
+}
@@ -1,8 +1,19 @@
 package model
 
+import "time"
+
 // Command represents a user command with its scope, operation, and arguments
 type Command struct {
 	Scope     string
 	Operation string
 	Args      []string
 }
+
+// JournalEntry represents an executed command recorded in the replayable command journal
+type JournalEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Username  string    `json:"username,omitempty"`
+	Scope     string    `json:"scope"`
+	Operation string    `json:"operation"`
+	Args      []string  `json:"args"`
+}
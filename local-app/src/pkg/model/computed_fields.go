@@ -0,0 +1,64 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Content fields computed from the structure of a mindmap, added to exports on request. The leading underscore
+// keeps them apart from the fields of the nodes, they are dropped again when an export is imported.
+const (
+	ComputedIndexField       = "_index"       // The index of the node as displayed
+	ComputedDepthField       = "_depth"       // The level of the node below the exported root, which is at 0
+	ComputedPathField        = "_path"        // The names of the nodes from the exported root to the node
+	ComputedChildrenField    = "_children"    // The number of children of the node
+	ComputedDescendantsField = "_descendants" // The number of nodes in the subtree below the node
+)
+
+// computedFields are the labels of the computed fields
+var computedFields = []string{ComputedIndexField, ComputedDepthField, ComputedPathField, ComputedChildrenField, ComputedDescendantsField}
+
+// computedPathSeparator separates the node names of a computed path
+const computedPathSeparator = " / "
+
+// WithComputedFields returns a copy of the mindmap whose nodes hold the computed fields in their content, their
+// indexes displayed in the display style. The mindmap itself is not changed.
+func (m *Mindmap) WithComputedFields(style DisplayStyle) *Mindmap {
+	clone := m.Clone()
+	if clone.Root == nil {
+		return clone
+	}
+
+	// annotate sets the fields of a node and its subtree and returns the number of nodes of the subtree
+	var annotate func(node *Node, depth int, path []string) int
+	annotate = func(node *Node, depth int, path []string) int {
+		path = append(path, node.Name)
+		descendants := 0
+		for _, child := range node.Children {
+			descendants += annotate(child, depth+1, path)
+		}
+
+		if node.Content == nil {
+			node.Content = make(map[string]string)
+		}
+		node.Content[ComputedIndexField] = style.DisplayIndex(node.Index)
+		node.Content[ComputedDepthField] = strconv.Itoa(depth)
+		node.Content[ComputedPathField] = strings.Join(path, computedPathSeparator)
+		node.Content[ComputedChildrenField] = strconv.Itoa(len(node.Children))
+		node.Content[ComputedDescendantsField] = strconv.Itoa(descendants)
+		return descendants + 1
+	}
+	annotate(clone.Root, 0, nil)
+	return clone
+}
+
+// RemoveComputedFields drops the computed fields from the content of the nodes of the mindmap, such as those of
+// an imported export
+func (m *Mindmap) RemoveComputedFields() {
+	for node := range m.Subtree(m.Root, nil) {
+		for _, label := range computedFields {
+			delete(node.Content, label)
+		}
+	}
+}
@@ -2,14 +2,75 @@
 package model
 
 type Config struct {
-	DatabaseType        string `json:"database_type"`
-	DatabaseDir         string `json:"database_dir"`
-	DatabaseFile        string `json:"database_file"`
-	LogFolder           string `json:"log_folder"`
-	CommandLog          string `json:"command_log"`
-	ErrorLog            string `json:"error_log"`
-	InfoLog             string `json:"info_log"`
-	DefaultUser         string `json:"default_user"`
-	DefaultUserActive   bool   `json:"default_user_active"`
-	DefaultUserPassword string `json:"default_user_password"`
+	DatabaseType        string                      `json:"database_type"`
+	DatabaseDir         string                      `json:"database_dir"`
+	DatabaseFile        string                      `json:"database_file"`
+	LogFolder           string                      `json:"log_folder"`
+	CommandLog          string                      `json:"command_log"`
+	ErrorLog            string                      `json:"error_log"`
+	InfoLog             string                      `json:"info_log"`
+	LogLevel            string                      `json:"log_level"` // Most verbose level logged: error, warn, info or debug
+	JournalLog          string                      `json:"journal_log"`
+	ExportDir           string                      `json:"export_dir"`
+	ExportTemplate      string                      `json:"export_template"`
+	KeyDir              string                      `json:"key_dir"`
+	DefaultUser         string                      `json:"default_user"`
+	DefaultUserActive   bool                        `json:"default_user_active"`
+	DefaultUserPassword string                      `json:"default_user_password"`
+	DefaultUserSelect   bool                        `json:"default_user_select"` // Select the default user on start, for single-user installs
+	CaseSensitiveNames  bool                        `json:"case_sensitive_names"`
+	LargeOpThreshold    int                         `json:"large_op_threshold"`
+	ReindexThreshold    int                         `json:"reindex_threshold"` // Deletes and moves in a mindmap after which its indexes are rebuilt
+	LazyLoadDepth       int                         `json:"lazy_load_depth"`   // Levels below the root loaded on mindmap select, all if 0
+	CommandRateLimit    int                         `json:"command_rate_limit"`
+	CaptureInterval     int                         `json:"capture_interval"`  // Seconds between polls of the email capture accounts
+	ReminderInterval    int                         `json:"reminder_interval"` // Seconds between checks for due reminders
+	ReminderSinks       []ReminderSink              `json:"reminder_sinks"`
+	BackupDir           string                      `json:"backup_dir"`        // Directory of the database snapshots
+	BackupInterval      int                         `json:"backup_interval"`   // Seconds between automatic snapshots, also taken on exit
+	BackupRetention     int                         `json:"backup_retention"`  // Snapshots kept, the oldest deleted beyond it
+	VersionInterval     int                         `json:"version_interval"`  // Node changes of a mindmap after which a version of it is stored
+	VersionRetention    int                         `json:"version_retention"` // Automatic versions kept per mindmap, the oldest deleted beyond it
+	JournalMindmap      string                      `json:"journal_mindmap"`   // Name of the daily journal mindmap of each user
+	JournalBranch       string                      `json:"journal_branch"`    // Top-level node holding the day nodes, the root if empty
+	JournalTemplate     JournalTemplate             `json:"journal_template"`
+	ZeroBasedIndex      bool                        `json:"zero_based_index"`   // Number nodes from 0 in views and commands
+	RootDisplay         string                      `json:"root_display"`       // Show the root as a title, a node or not at all
+	Prompt              string                      `json:"prompt"`             // CLI prompt template with {user}, {mindmap}, {node} and {jobs}
+	ReadOnly            bool                        `json:"read_only"`          // Open the database read-only and refuse changes
+	Telemetry           bool                        `json:"telemetry"`          // Count the uses and failures of the commands locally, off by default
+	AdminUsers          []string                    `json:"admin_users"`        // Users the admin commands show all users to, and who may run admin events and telemetry
+	WebAddress          string                      `json:"web_address"`        // Address the WebSocket adapter listens on, off if empty
+	WebMaps             []string                    `json:"web_maps"`           // Mindmaps the web adapter publishes as exports, as owner/mindmap
+	GRPCAddress         string                      `json:"grpc_address"`       // Address the gRPC adapter listens on, off if empty
+	MaxConnections      int                         `json:"max_connections"`    // Clients each of the web and gRPC adapters serves at once
+	ShutdownTimeout     int                         `json:"shutdown_timeout"`   // Seconds running commands are waited for when --serve stops
+	CommandHooks        []CommandHook               `json:"command_hooks"`      // Scripts run before or after commands
+	RedactionProfiles   map[string]RedactionProfile `json:"redaction_profiles"` // Content fields redacted by exports with --redact
+	ConfigFile          string                      `json:"-"`                  // The file the configuration was loaded from, if any
+}
+
+// RedactionProfile names the content fields an export removes or replaces with hashes of their values. Labels may
+// contain * wildcards.
+type RedactionProfile struct {
+	Remove []string `json:"remove"`
+	Hash   []string `json:"hash"`
+	Salt   string   `json:"salt,omitempty"` // Keys the hashes, so short values can't be found by hashing guesses
+}
+
+// CommandHook is a program run before or after a command with the command as JSON on its standard input. A failing
+// pre hook refuses the command.
+type CommandHook struct {
+	When    string   `json:"when"`              // pre or post
+	Command string   `json:"command"`           // Scope and operation, such as "node delete", or a scope for all its operations
+	Program string   `json:"program"`           // The program to run
+	Args    []string `json:"args,omitempty"`    // The arguments of the program
+	Timeout int      `json:"timeout,omitempty"` // Seconds the program may run, 30 if not set
+}
+
+// JournalTemplate is applied to each new day node of the daily journal, {date} and {weekday} in its names and
+// values are replaced by those of the day
+type JournalTemplate struct {
+	Fields   map[string]string `json:"fields"`   // Content fields of the day node
+	Children []string          `json:"children"` // Names of the child nodes added under the day node
 }
@@ -0,0 +1,53 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"slices"
+)
+
+// redactedHashLength is the number of hex digits of the hashes replacing redacted values
+const redactedHashLength = 16
+
+// redactionMatch reports whether a field label matches one of the labels of a profile, which may contain * wildcards
+func redactionMatch(labels []string, label string) bool {
+	return slices.ContainsFunc(labels, func(pattern string) bool {
+		matched, err := path.Match(pattern, label)
+		return err == nil && matched
+	})
+}
+
+// RedactValue returns the hash replacing a redacted value, keyed by the salt of the profile if it has one. Equal
+// values have equal hashes, so redacted exports can still be compared and joined.
+func (p RedactionProfile) RedactValue(value string) string {
+	var sum []byte
+	if p.Salt != "" {
+		mac := hmac.New(sha256.New, []byte(p.Salt))
+		mac.Write([]byte(value))
+		sum = mac.Sum(nil)
+	} else {
+		digest := sha256.Sum256([]byte(value))
+		sum = digest[:]
+	}
+	return "sha256:" + hex.EncodeToString(sum)[:redactedHashLength]
+}
+
+// WithRedaction returns a copy of the mindmap whose nodes lack the fields the profile removes and hold hashes
+// of the values of the fields it hashes. Removal takes precedence. The mindmap itself is not changed.
+func (m *Mindmap) WithRedaction(profile RedactionProfile) *Mindmap {
+	clone := m.Clone()
+	for _, node := range clone.Nodes {
+		for label, value := range node.Content {
+			switch {
+			case redactionMatch(profile.Remove, label):
+				delete(node.Content, label)
+			case redactionMatch(profile.Hash, label):
+				node.Content[label] = profile.RedactValue(value)
+			}
+		}
+	}
+	return clone
+}
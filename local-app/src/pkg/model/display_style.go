@@ -0,0 +1,70 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Root display modes of views and document exports
+const (
+	RootAsTitle = "title"  // The root name is a title line above the nodes
+	RootAsNode  = "node"   // The root is shown as a node, with its index
+	RootHidden  = "hidden" // The root is not shown, its children are the top level
+)
+
+// RootAlias addresses the root node in commands, also where 0 is the index of the first top-level node
+const RootAlias = "root"
+
+// DisplayStyle holds the preferences for showing nodes to users, shared by views and exports. Nodes keep their
+// stored logical indexes, 0 for the root and positions counted from 1 below it, whatever the style.
+type DisplayStyle struct {
+	ZeroBasedIndex bool   // Positions are counted from 0 and the root is shown as root
+	RootDisplay    string // One of the root display modes, RootAsTitle if empty
+}
+
+// DisplayStyle returns the display style of the configuration
+func (c *Config) DisplayStyle() DisplayStyle {
+	return DisplayStyle{ZeroBasedIndex: c.ZeroBasedIndex, RootDisplay: c.RootDisplay}
+}
+
+// Root returns the root display mode, RootAsTitle unless another valid mode is set
+func (s DisplayStyle) Root() string {
+	if s.RootDisplay == RootAsNode || s.RootDisplay == RootHidden {
+		return s.RootDisplay
+	}
+	return RootAsTitle
+}
+
+// DisplayIndex returns the index shown for a stored logical index
+func (s DisplayStyle) DisplayIndex(index string) string {
+	if !s.ZeroBasedIndex {
+		return index
+	}
+	if index == "0" {
+		return RootAlias
+	}
+	return shiftIndex(index, -1)
+}
+
+// ParseIndex returns the stored logical index of an index typed by a user, or of the root alias
+func (s DisplayStyle) ParseIndex(input string) string {
+	if input == RootAlias {
+		return "0"
+	}
+	if !s.ZeroBasedIndex {
+		return input
+	}
+	return shiftIndex(input, 1)
+}
+
+// shiftIndex adds delta to each numeric segment of an index, other segments are kept
+func shiftIndex(index string, delta int) string {
+	segments := strings.Split(index, ".")
+	for i, segment := range segments {
+		if position, err := strconv.Atoi(segment); err == nil {
+			segments[i] = strconv.Itoa(position + delta)
+		}
+	}
+	return strings.Join(segments, ".")
+}
@@ -0,0 +1,23 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+// ExportFormat is a format mindmaps are exported in, registered by name along with its capabilities
+type ExportFormat struct {
+	Name        string
+	Extension   string // File extension without the dot, the name if empty
+	Description string // Shown in the help of the export command
+	Binary      bool   // The data is not text, so it can't be copied to the clipboard
+	Subtree     bool   // Exports the subtree of a node, as a mindmap rooted and named by the node
+	Encode      func(mindmap *Mindmap, style DisplayStyle) ([]byte, error)
+}
+
+// ImportFormat is a format mindmaps are imported from, registered by name along with its capabilities
+type ImportFormat struct {
+	Name        string
+	Description string   // Shown in the help of the import command
+	Extensions  []string // File extensions without the dot recognizing the format besides its name, such as md
+	Native      bool     // Files carry the node IDs and integrity data of exports in the format of the same name
+	// Decode reads the data of a file, formats without mindmap names naming the mindmap by the file name. It returns
+	// warnings about the features of the file that have no counterpart in a mindmap and were not imported.
+	Decode func(data []byte, name string) (*Mindmap, []string, error)
+}
@@ -0,0 +1,94 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+// Compression values of exported files, matching their file name suffixes
+const (
+	CompressionNone = ""
+	CompressionGzip = "gz"
+	CompressionZstd = "zst"
+)
+
+// ExportOptions holds the options of a mindmap export.
+type ExportOptions struct {
+	Filename    string
+	Format      string
+	Force       bool
+	Compression string
+	Sign        bool
+	Node        *Node  // Root of the exported subtree, the whole mindmap if nil. Not for the importable formats.
+	Computed    bool   // Adds the computed fields of the nodes to their content
+	Redact      string // Name of the configured redaction profile applied to the content, none if empty
+	Encrypt     bool   // Encrypts the file with a key derived from Passphrase, as does an .mnx file name suffix
+	Passphrase  string `json:"-"`
+	Progress    ProgressFunc
+}
+
+// ImportPolicy decides what happens to the imported nodes already present in an existing mindmap of the same
+// name, nodes being identified by their ID
+type ImportPolicy string
+
+const (
+	ImportReplace   ImportPolicy = ""          // Replace the existing mindmap as a whole
+	ImportSkip      ImportPolicy = "skip"      // Keep the nodes already present, add the others
+	ImportUpdate    ImportPolicy = "update"    // Merge the name and fields into the nodes already present, add the others
+	ImportDuplicate ImportPolicy = "duplicate" // Add all nodes, copies of those already present
+	ImportSync      ImportPolicy = "sync"      // Make the existing mindmap match the imported one, such as an edited export
+)
+
+// ImportOptions holds the options of a mindmap import.
+type ImportOptions struct {
+	Filename   string
+	Format     string
+	Force      bool
+	Existing   ImportPolicy   // Merge into an existing mindmap of the same name instead of replacing it
+	Conflicts  ConflictPolicy // Resolves the nodes changed on both sides of an update or sync merge
+	Resolve    ConflictFunc   `json:"-"` // Asks for each conflict when Conflicts is not set, refused if nil
+	Passphrase string         `json:"-"` // Decrypts files with an .mnx file name suffix
+	Progress   ProgressFunc
+	File       *ImportFile `json:"-"` // The file read in advance, such as by a background job, read from Filename if nil
+}
+
+// ConflictPolicy resolves a conflict of a merge: a node changed in the mindmap since the file's copy of it, the
+// two differing in their name or fields
+type ConflictPolicy string
+
+const (
+	ConflictRefuse ConflictPolicy = ""       // Refuse the merge, listing the conflicts
+	ConflictMine   ConflictPolicy = "mine"   // Keep the node as it is in the mindmap
+	ConflictTheirs ConflictPolicy = "theirs" // Merge the node of the file into it
+	ConflictBoth   ConflictPolicy = "both"   // Keep the node and add the one of the file as a sibling
+	ConflictEdit   ConflictPolicy = "edit"   // Merge the name and fields of the resolution, only given by a ConflictFunc
+)
+
+// ImportConflict is a node changed on both sides of a merge: Mine in the mindmap, Theirs in the imported file
+type ImportConflict struct {
+	Mine   *Node
+	Theirs *Node
+}
+
+// ConflictResolution resolves an ImportConflict, Name and Content holding the edited node for ConflictEdit
+type ConflictResolution struct {
+	Policy  ConflictPolicy
+	Name    string
+	Content map[string]string
+}
+
+// ConflictFunc asks the user how to resolve a conflict of a merge
+type ConflictFunc func(conflict ImportConflict) (ConflictResolution, error)
+
+// ImportFile is a file read for import: the mindmap it holds, its format and warnings about its integrity
+type ImportFile struct {
+	Mindmap  *Mindmap
+	Format   string
+	Warnings []string
+}
+
+// ImportMerge counts the nodes of a merge of an imported mindmap into an existing one
+type ImportMerge struct {
+	Added     int
+	Updated   int
+	Skipped   int
+	Moved     int
+	Deleted   int
+	Conflicts int // Nodes changed on both sides, also counted as updated, skipped or added as resolved
+}
@@ -0,0 +1,110 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import "time"
+
+// Link types
+const (
+	LinkTypeWiki = "wiki" // Link from a [[name]] reference in the content of the source node
+	LinkTypeNode = "node" // Link added with the node link command, to a node of the same or another mindmap
+)
+
+// Link is a typed cross-link from a node to another node, outside the tree hierarchy.
+type Link struct {
+	ID              int       `json:"id"`
+	SourceMindmapID int       `json:"source_mindmap_id"`
+	SourceID        int       `json:"source_id"`
+	TargetMindmapID int       `json:"target_mindmap_id"`
+	TargetID        int       `json:"target_id"`
+	Type            string    `json:"type"`
+	Created         time.Time `json:"created"`
+}
+
+// MindmapLink is a stored link from a node of an exported mindmap. Its target is a node of the mindmap itself, or
+// of the mindmap named TargetMindmap, found by name among the mindmaps of the importing user.
+type MindmapLink struct {
+	SourceID      int    `json:"source_id" xml:"source_id,attr"`
+	TargetID      int    `json:"target_id" xml:"target_id,attr"`
+	TargetMindmap string `json:"target_mindmap,omitempty" xml:"target_mindmap,attr,omitempty"`
+	Type          string `json:"type" xml:"type,attr"`
+}
+
+// LinkFilter defines the options for filtering links.
+type LinkFilter struct {
+	ID              bool
+	SourceMindmapID bool
+	SourceID        bool
+	TargetMindmapID bool
+	TargetID        bool
+	Type            bool
+}
+
+// Formats of a link graph
+const (
+	GraphFormatDOT  = "dot"  // Graphviz DOT
+	GraphFormatJSON = "json" // JSON object of nodes and edges
+)
+
+// GraphEdgeTree is the type of the edges of a link graph from a node to each of its children, added on request
+const GraphEdgeTree = "tree"
+
+// LinkGraph is the graph of the cross-links between the nodes of a mindmap, for analysis in graph tools
+type LinkGraph struct {
+	Mindmap string      `json:"mindmap"`
+	Nodes   []GraphNode `json:"nodes"`
+	Edges   []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a node of a link graph
+type GraphNode struct {
+	ID    int    `json:"id"`
+	Index string `json:"index"`
+	Name  string `json:"name"`
+}
+
+// GraphEdge is a typed edge of a link graph, a link or with GraphEdgeTree a parent and child
+type GraphEdge struct {
+	Source int    `json:"source"`
+	Target int    `json:"target"`
+	Type   string `json:"type"`
+}
+
+// WikiLinkResult reports the resolution of the [[name]] references in the content of a node
+type WikiLinkResult struct {
+	Linked     []*Node  // Nodes linked from the node
+	Created    []*Node  // Nodes created for unresolved references, also in Linked
+	Unresolved []string // References to names of no node
+	Ambiguous  []string // References to names shared by several nodes
+}
+
+// Kinds of references checked by a link check
+const (
+	ReferenceLink = "link" // Stored link to another node
+	ReferenceWiki = "wiki" // [[name]] or [[#id]] reference in the content of a node
+	ReferenceURL  = "url"  // Web address in the content of a node
+)
+
+// NodeReference is a reference to a node from a node outside its subtree, such as those keeping the node from
+// being deleted
+type NodeReference struct {
+	SourceMindmapID int // The mindmap of the node holding the reference
+	SourceID        int // The node holding the reference
+	TargetID        int // The node referred to
+	Kind            string
+}
+
+// BrokenReference is a reference of a node that leads nowhere, found by a link check
+type BrokenReference struct {
+	SourceID int    // The node holding the reference
+	Field    string // The content field holding the reference, empty for stored links
+	Kind     string
+	Target   string
+	Problem  string
+}
+
+// LinkCheckOptions defines the options of a link check
+type LinkCheckOptions struct {
+	HTTP     bool          // Check that URLs respond, not only that they are well-formed
+	Timeout  time.Duration // Time allowed for each URL to respond
+	Progress ProgressFunc  // Receives the advance of the URL checks, may be nil
+}
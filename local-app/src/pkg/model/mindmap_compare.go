@@ -0,0 +1,117 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// MindmapComparison is the comparison of two mindmaps, such as a map recalled from memory against a reference map.
+// Nodes are matched by their names, ignoring case and spacing, the roots being matched as the mindmaps themselves.
+type MindmapComparison struct {
+	A      string   `json:"a"`
+	B      string   `json:"b"`
+	NodesA int      `json:"nodes_a"` // Nodes below the root of A
+	NodesB int      `json:"nodes_b"` // Nodes below the root of B
+	Shared int      `json:"shared"`  // Nodes of A with a node of the same name in B
+	Moved  int      `json:"moved"`   // Shared nodes under a parent of another name in B
+	OnlyA  []string `json:"only_a"`  // Names of the nodes of A missing from B, in document order
+	OnlyB  []string `json:"only_b"`  // Names of the nodes of B missing from A, in document order
+
+	// Scores between 0 (nothing in common) and 1 (the same)
+	NameSimilarity      float64 `json:"name_similarity"`      // Shared node names of all names
+	TextSimilarity      float64 `json:"text_similarity"`      // Shared words of names and fields of all words
+	StructureSimilarity float64 `json:"structure_similarity"` // Edit distance relative to the nodes of both
+	EditDistance        int     `json:"edit_distance"`        // Nodes to delete, add or move to turn A into B
+}
+
+// compareKey is the key nodes of compared mindmaps are matched by
+func compareKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// CompareMindmaps compares the nodes of two loaded mindmaps. The edit distance approximates the tree edit distance
+// from A to B by matching nodes by name: the unmatched nodes of A are deleted, those of B added, and the matched
+// nodes whose parents do not match are moved.
+func CompareMindmaps(a, b *Mindmap) MindmapComparison {
+	comparison := MindmapComparison{A: a.Name, B: b.Name}
+
+	// names counts the nodes by name and edges the nodes by the names of their parent and themselves, the
+	// children of the root having no parent name
+	type edge struct{ parent, name string }
+	collect := func(m *Mindmap, count *int) (names map[string]int, edges map[edge]int, words map[string]bool) {
+		names, edges, words = make(map[string]int), make(map[edge]int), make(map[string]bool)
+		for node := range m.Subtree(nil, nil) {
+			if node == m.Root {
+				continue
+			}
+			for _, text := range append([]string{node.Name}, slices.Collect(maps.Values(node.Content))...) {
+				for _, word := range strings.Fields(strings.ToLower(text)) {
+					words[word] = true
+				}
+			}
+			*count++
+			key := compareKey(node.Name)
+			names[key]++
+			parent := ""
+			if p, ok := m.Nodes[node.ParentID]; ok && p != m.Root {
+				parent = compareKey(p.Name)
+			}
+			edges[edge{parent, key}]++
+		}
+		return names, edges, words
+	}
+	namesA, edgesA, wordsA := collect(a, &comparison.NodesA)
+	namesB, edgesB, wordsB := collect(b, &comparison.NodesB)
+
+	for key, n := range namesA {
+		comparison.Shared += min(n, namesB[key])
+	}
+	sharedEdges := 0
+	for e, n := range edgesA {
+		sharedEdges += min(n, edgesB[e])
+	}
+	comparison.Moved = comparison.Shared - sharedEdges
+
+	// Nodes of a name beyond the count of the other mindmap are unmatched, the later ones in document order
+	unmatched := func(m *Mindmap, other map[string]int) []string {
+		seen := make(map[string]int)
+		var only []string
+		for node := range m.Subtree(nil, nil) {
+			if node == m.Root {
+				continue
+			}
+			key := compareKey(node.Name)
+			seen[key]++
+			if seen[key] > other[key] {
+				only = append(only, node.Name)
+			}
+		}
+		return only
+	}
+	comparison.OnlyA = unmatched(a, namesB)
+	comparison.OnlyB = unmatched(b, namesA)
+
+	total := comparison.NodesA + comparison.NodesB
+	comparison.EditDistance = len(comparison.OnlyA) + len(comparison.OnlyB) + comparison.Moved
+	comparison.NameSimilarity = compareRatio(2*comparison.Shared, total)
+	comparison.StructureSimilarity = compareRatio(total-comparison.EditDistance, total)
+
+	sharedWords := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			sharedWords++
+		}
+	}
+	comparison.TextSimilarity = compareRatio(sharedWords, len(wordsA)+len(wordsB)-sharedWords)
+	return comparison
+}
+
+// compareRatio divides a count of common items by the count of all, nothing at all counting as the same
+func compareRatio(part, whole int) float64 {
+	if whole == 0 {
+		return 1
+	}
+	return float64(part) / float64(whole)
+}
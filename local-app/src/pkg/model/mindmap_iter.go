@@ -0,0 +1,199 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NodeMatch reports whether a node is to be visited by a mindmap walk
+type NodeMatch func(node *Node) bool
+
+// Walk returns an iterator over the subtree of a node in document order, depth-first with siblings in index order,
+// yielding the depth of each node relative to the start node along with the node. The walk starts at the root if
+// node is nil. Nodes not matched by match are skipped but their descendants are still visited, a nil match visits all.
+// The tree is derived from the parent IDs of the Nodes map, so it does not depend on the Children slices being linked.
+func (m *Mindmap) Walk(node *Node, match NodeMatch) iter.Seq2[int, *Node] {
+	return m.walk(node, match, m.childrenByParent)
+}
+
+// SortedWalk returns an iterator over the subtree of a node like Walk, with the siblings ordered by spec instead of
+// by index, those comparing equal keeping their index order. A zero spec walks in index order.
+func (m *Mindmap) SortedWalk(node *Node, spec SortSpec) iter.Seq2[int, *Node] {
+	return m.walk(node, nil, func() map[int][]*Node {
+		children := m.childrenByParent()
+		if !spec.IsZero() {
+			// Sorted copies, the groups may be the Children slices of the nodes
+			compare := spec.Comparer()
+			for id, siblings := range children {
+				children[id] = slices.SortedStableFunc(slices.Values(siblings), compare)
+			}
+		}
+		return children
+	})
+}
+
+// walk walks the subtree of a node in depth-first order, the children of each node as grouped by childrenOf
+func (m *Mindmap) walk(node *Node, match NodeMatch, childrenOf func() map[int][]*Node) iter.Seq2[int, *Node] {
+	return func(yield func(int, *Node) bool) {
+		if node == nil {
+			node = m.Root
+		}
+		if node == nil {
+			return
+		}
+
+		children := childrenOf()
+
+		var visit func(n *Node, depth int) bool
+		visit = func(n *Node, depth int) bool {
+			if match == nil || match(n) {
+				if !yield(depth, n) {
+					return false
+				}
+			}
+			for _, child := range children[n.ID] {
+				if !visit(child, depth+1) {
+					return false
+				}
+			}
+			return true
+		}
+		visit(node, 0)
+	}
+}
+
+// Subtree returns an iterator over the nodes of the subtree of a node in document order, see Walk
+func (m *Mindmap) Subtree(node *Node, match NodeMatch) iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for _, n := range m.Walk(node, match) {
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// LinkChildren rebuilds the Children slices of all nodes from their parent IDs, in index order
+func (m *Mindmap) LinkChildren() {
+	children := m.childrenByParent()
+	for _, node := range m.Nodes {
+		node.Children = children[node.ID]
+	}
+}
+
+// NodesFromTree rebuilds the Nodes map from the Children slices reachable from the root, taking the parent IDs and
+// indexes of the nodes from their nesting and order. Nodes without an ID, other than the root, get unused IDs from
+// firstID on. This makes the nested tree of a file edited by hand, not its flat node list, the one that counts.
+func (m *Mindmap) NodesFromTree(firstID int) {
+	m.Nodes = make(map[int]*Node)
+	if m.Root == nil {
+		return
+	}
+
+	maxID := firstID - 1
+	var scan func(n *Node)
+	scan = func(n *Node) {
+		maxID = max(maxID, n.ID)
+		for _, child := range n.Children {
+			scan(child)
+		}
+	}
+	scan(m.Root)
+
+	var link func(n *Node)
+	link = func(n *Node) {
+		m.Nodes[n.ID] = n
+		for i, child := range n.Children {
+			if child.ID == 0 {
+				maxID++
+				child.ID = maxID
+			}
+			child.ParentID = n.ID
+			child.Index = strconv.Itoa(i + 1)
+			if n != m.Root {
+				child.Index = n.Index + "." + child.Index
+			}
+			link(child)
+		}
+	}
+	link(m.Root)
+}
+
+// childrenByParent groups the nodes of the mindmap by parent ID, each group sorted by index, then by ID for nodes
+// sharing an index.
+// A mindmap without a Nodes map falls back to the Children slices reachable from the root.
+func (m *Mindmap) childrenByParent() map[int][]*Node {
+	children := make(map[int][]*Node)
+	if len(m.Nodes) == 0 {
+		if m.Root != nil {
+			var collect func(n *Node)
+			collect = func(n *Node) {
+				children[n.ID] = n.Children
+				for _, child := range n.Children {
+					collect(child)
+				}
+			}
+			collect(m.Root)
+		}
+		return children
+	}
+
+	for _, node := range m.Nodes {
+		if node.ID != node.ParentID {
+			children[node.ParentID] = append(children[node.ParentID], node)
+		}
+	}
+	for _, siblings := range children {
+		slices.SortFunc(siblings, func(a, b *Node) int {
+			return cmp.Or(CompareIndex(a.Index, b.Index), cmp.Compare(a.ID, b.ID))
+		})
+	}
+	return children
+}
+
+// CompareIndex compares two node indexes such as "1.2" and "1.10" segment by segment, numerically where possible.
+// It returns -1, 0 or +1 like strings.Compare.
+func CompareIndex(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, errA := strconv.Atoi(as[i])
+		bn, errB := strconv.Atoi(bs[i])
+		var c int
+		if errA == nil && errB == nil {
+			c = cmp.Compare(an, bn)
+		} else {
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+// Clone returns a copy of the mindmap with copies of all nodes, linked by their parent IDs.
+// Changes to the nodes of the copy do not affect the original.
+func (m *Mindmap) Clone() *Mindmap {
+	clone := *m
+	clone.Nodes = make(map[int]*Node, len(m.Nodes))
+	for id, node := range m.Nodes {
+		copied := *node
+		copied.Content = maps.Clone(node.Content)
+		copied.Tags = slices.Clone(node.Tags)
+		copied.Children = nil
+		clone.Nodes[id] = &copied
+	}
+	clone.Links = slices.Clone(m.Links)
+	clone.Attachments = slices.Clone(m.Attachments)
+	clone.Unloaded = maps.Clone(m.Unloaded)
+	if m.Root != nil {
+		clone.Root = clone.Nodes[m.Root.ID]
+	}
+	clone.LinkChildren()
+	return &clone
+}
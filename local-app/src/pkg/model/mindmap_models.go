@@ -5,14 +5,26 @@ import "time"
 
 // Mindmap represents a complete mind map structure with its nodes and metadata.
 type Mindmap struct {
-	ID       int           `json:"id" xml:"id,attr"`
-	Name     string        `json:"name" xml:"name,attr"`
-	Owner    string        `json:"owner" xml:"owner,attr"`
-	IsPublic bool          `json:"is_public" xml:"is_public,attr"`
-	Root     *Node         `json:"root" xml:"root"`
-	Nodes    map[int]*Node `json:"nodes,omitempty" xml:"nodes>node,omitempty"`
-	Created  time.Time     `json:"created" xml:"created,attr"`
-	Updated  time.Time     `json:"updated" xml:"updated,attr"`
+	ID          int                 `json:"id" xml:"id,attr"`
+	Name        string              `json:"name" xml:"name,attr"`
+	Owner       string              `json:"owner" xml:"owner,attr"`
+	IsPublic    bool                `json:"is_public" xml:"is_public,attr"`
+	Root        *Node               `json:"root" xml:"root"`
+	Nodes       map[int]*Node       `json:"nodes,omitempty" xml:"nodes>node,omitempty"`
+	Links       []MindmapLink       `json:"links,omitempty" xml:"links>link,omitempty"`                   // Links from the nodes, only set in exported mindmaps
+	Attachments []MindmapAttachment `json:"attachments,omitempty" xml:"attachments>attachment,omitempty"` // Attachments of the nodes, only set in exported mindmaps
+	Created     time.Time           `json:"created" xml:"created,attr"`
+	Updated     time.Time           `json:"updated" xml:"updated,attr"`
+	Checksum    string              `json:"checksum,omitempty" xml:"checksum,attr,omitempty"`
+	Hidden      int                 `json:"-" xml:"-"` // Nodes of private subtrees left out of the mindmap loaded for another user than the owner
+	Sort        SortSpec            `json:"-" xml:"-"` // Default order of the children when the mindmap is shown, index order if zero
+	Unloaded    map[int]int         `json:"-" xml:"-"` // Children not loaded yet per node, of a mindmap loaded only a few levels deep
+	Revision    int                 `json:"-" xml:"-"` // Stored revision the mindmap was loaded at, behind it once another session changes the mindmap
+}
+
+// Partial reports whether nodes of the mindmap are not loaded yet
+func (m *Mindmap) Partial() bool {
+	return len(m.Unloaded) > 0
 }
 
 // MindmapInfo contains basic information about a mindmap.
@@ -21,14 +33,63 @@ type MindmapInfo struct {
 	Name      string
 	Owner     string
 	IsPublic  bool
+	Sort      SortSpec
 	NodeCount *int
 	Depth     *int
 }
 
+// MindmapStats holds the size of a mindmap.
+type MindmapStats struct {
+	NodeCount int `json:"node_count"`
+	Depth     int `json:"depth"` // Levels of nodes, 1 for a mindmap of only the root
+}
+
+// Orphans counts the rows left behind by the nodes and mindmaps they belong to, such as the links of nodes deleted
+// before links were deleted along with their nodes
+type Orphans struct {
+	Links           int   `json:"links"`
+	Attachments     int   `json:"attachments"`
+	AttachmentBytes int64 `json:"attachment_bytes"` // Size of the orphaned attachments
+}
+
+// MindmapSummary is a one-page overview of a mindmap or of a branch of it
+type MindmapSummary struct {
+	Mindmap  *Mindmap
+	Node     *Node           // The node summarized, the root for the whole mindmap
+	Stats    MindmapStats    // Size of the subtree of Node
+	Fields   int             // Nodes of the subtree with fields
+	Branches []SummaryBranch // Nodes below Node down to the summary depth, in document order
+	Dated    []SummaryItem   // Nodes of the subtree with a due or remind time, soonest first
+	Overdue  int             // Dated nodes whose time has passed
+	Created  time.Time
+}
+
+// SummaryBranch is a node of the outline of a summary
+type SummaryBranch struct {
+	Node  *Node
+	Depth int // Levels below the summarized node, from 1
+	Count int // Nodes of its subtree, itself included
+}
+
+// SummaryItem is a dated node of a summary, At coming from its Field, remind or due
+type SummaryItem struct {
+	Node  *Node
+	Field string
+	At    time.Time
+}
+
+// MindmapReindex is the outcome of rebuilding the node indexes of a mindmap
+type MindmapReindex struct {
+	Nodes      int `json:"nodes"`      // Nodes of the mindmap
+	Mismatched int `json:"mismatched"` // Nodes whose parent or index in memory differed from storage, which wins
+	Renumbered int `json:"renumbered"` // Nodes given a new index to close gaps or repair the sibling order
+}
+
 // MindmapFilter defines the options for filtering mindmap data.
 type MindmapFilter struct {
 	ID       bool
 	Name     bool
 	Owner    bool
 	IsPublic bool
+	Sort     bool
 }
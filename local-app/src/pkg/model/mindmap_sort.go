@@ -0,0 +1,296 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Sort types of a sort specification, deciding how the values of two nodes compare
+const (
+	SortAuto   = "auto"   // As numbers if both values are numbers, as text otherwise
+	SortText   = "text"   // As text
+	SortNumber = "number" // As numbers, nodes without a number last
+	SortDate   = "date"   // As dates such as due dates, YYYY-MM-DD or YYYY-MM-DDTHH:MM, nodes without a date last
+)
+
+// Sort directions of a sort specification
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// Fields of a sort specification standing for the node name and timestamps instead of content fields
+const (
+	SortName     = "name"
+	SortCreated  = "created"  // When the node was added
+	SortModified = "modified" // When the node was last changed, including its index
+)
+
+// Collation options of a sort specification, deciding how text compares
+const (
+	SortNatural      = "natural" // Runs of digits compare by their value, item2 before item10
+	SortNoCase       = "nocase"  // Letters compare regardless of case
+	SortLocalePrefix = "locale:" // Followed by a language tag such as de or sv, text compares by the rules of the language
+)
+
+// SortSpec is an order of the children of nodes, such as the default order a mindmap is shown in
+type SortSpec struct {
+	Field   string // The content field compared, the node name if SortName or empty, or SortCreated or SortModified
+	Reverse bool   // Descending order
+	Type    string // How the values compare, SortAuto if empty
+
+	Natural    bool   // Runs of digits in text compare by their value
+	IgnoreCase bool   // Text compares regardless of case
+	Locale     string // Language tag whose rules text compares by, byte-wise if empty
+}
+
+// IsZero reports whether the spec is empty, keeping the children in index order
+func (s SortSpec) IsZero() bool {
+	return s == SortSpec{}
+}
+
+// Collation returns the spec without its field, direction and type, the options deciding how text compares
+func (s SortSpec) Collation() SortSpec {
+	return SortSpec{Natural: s.Natural, IgnoreCase: s.IgnoreCase, Locale: s.Locale}
+}
+
+// ParseSortCollation parses a collation option word of a sort spec into spec, reporting whether word is one
+func ParseSortCollation(spec *SortSpec, word string) (bool, error) {
+	lower := strings.ToLower(word)
+	switch {
+	case lower == SortNatural:
+		spec.Natural = true
+	case lower == SortNoCase:
+		spec.IgnoreCase = true
+	case strings.HasPrefix(lower, SortLocalePrefix):
+		tag, err := language.Parse(word[len(SortLocalePrefix):])
+		if err != nil {
+			return true, fmt.Errorf("invalid sort locale '%s': %w", word[len(SortLocalePrefix):], err)
+		}
+		spec.Locale = tag.String()
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// ParseSortSpec parses a sort spec from its words: the field, name for the node name or created or modified for its
+// timestamps, optionally followed by the
+// direction, asc or desc, the sort type, auto, text, number or date, and the collation options, natural, nocase
+// and locale:<tag>, such as "priority desc number" or "name natural locale:de"
+func ParseSortSpec(words []string) (SortSpec, error) {
+	if len(words) == 0 || len(words) > 6 {
+		return SortSpec{}, fmt.Errorf("a sort is a field followed by an optional %s|%s, %s|%s|%s|%s and %s, %s, %s<tag>", SortAscending, SortDescending, SortAuto, SortText, SortNumber, SortDate, SortNatural, SortNoCase, SortLocalePrefix)
+	}
+
+	var spec SortSpec
+	spec.Field = words[0]
+	for _, field := range []string{SortName, SortCreated, SortModified} {
+		if strings.EqualFold(spec.Field, field) {
+			spec.Field = field
+		}
+	}
+	direction, sortType := false, false
+	for _, word := range words[1:] {
+		switch strings.ToLower(word) {
+		case SortAscending, SortDescending:
+			if direction {
+				return SortSpec{}, fmt.Errorf("sort direction given twice: %s", word)
+			}
+			direction, spec.Reverse = true, strings.EqualFold(word, SortDescending)
+		case SortAuto, SortText, SortNumber, SortDate:
+			if sortType {
+				return SortSpec{}, fmt.Errorf("sort type given twice: %s", word)
+			}
+			sortType, spec.Type = true, strings.ToLower(word)
+		default:
+			if ok, err := ParseSortCollation(&spec, word); ok {
+				if err != nil {
+					return SortSpec{}, err
+				}
+				continue
+			}
+			return SortSpec{}, fmt.Errorf("invalid sort '%s': must be %s, %s, %s, %s, %s, %s, %s, %s or %s<tag>", word, SortAscending, SortDescending, SortAuto, SortText, SortNumber, SortDate, SortNatural, SortNoCase, SortLocalePrefix)
+		}
+	}
+	if spec.Type == SortAuto {
+		spec.Type = ""
+	}
+	return spec, nil
+}
+
+// String formats the spec as the words it is parsed from, empty for a zero spec
+func (s SortSpec) String() string {
+	if s.IsZero() {
+		return ""
+	}
+	words := []string{cmp.Or(s.Field, SortName), SortAscending, cmp.Or(s.Type, SortAuto)}
+	if s.Reverse {
+		words[1] = SortDescending
+	}
+	return strings.Join(append(words, s.collationWords()...), " ")
+}
+
+// CollationString formats the collation options of the spec, empty if text compares byte-wise
+func (s SortSpec) CollationString() string {
+	return strings.Join(s.collationWords(), " ")
+}
+
+// collationWords returns the words of the collation options of the spec
+func (s SortSpec) collationWords() []string {
+	var words []string
+	if s.Natural {
+		words = append(words, SortNatural)
+	}
+	if s.IgnoreCase {
+		words = append(words, SortNoCase)
+	}
+	if s.Locale != "" {
+		words = append(words, SortLocalePrefix+s.Locale)
+	}
+	return words
+}
+
+// Comparer returns the comparison of two nodes by the spec, returning -1, 0 or +1 like strings.Compare. Without a
+// value in the field for either node, their names are compared instead. Values that are not numbers or dates for
+// these sort types come last in both directions. Text compares by the collation options. The comparison is meant
+// for one sort at a time, it is not safe for concurrent use.
+func (s SortSpec) Comparer() func(a, b *Node) int {
+	compareText := s.textComparer()
+	return func(a, b *Node) int {
+		return s.compare(a, b, compareText)
+	}
+}
+
+// compare compares two nodes by the spec, text by compareText
+func (s SortSpec) compare(a, b *Node, compareText func(a, b string) int) int {
+	if s.Field == SortCreated || s.Field == SortModified {
+		c := a.Created.Compare(b.Created)
+		if s.Field == SortModified {
+			c = a.Updated.Compare(b.Updated)
+		}
+		if s.Reverse {
+			return -c
+		}
+		return c
+	}
+
+	va, vb := a.Name, b.Name
+	if s.Field != "" && s.Field != SortName {
+		va, vb = a.Content[s.Field], b.Content[s.Field]
+		if va == "" && vb == "" {
+			va, vb = a.Name, b.Name
+		}
+	}
+
+	var c int
+	switch s.Type {
+	case SortText:
+		c = compareText(va, vb)
+	case SortNumber, SortDate:
+		ka, errA := sortKey(s.Type, va)
+		kb, errB := sortKey(s.Type, vb)
+		switch {
+		case errA != nil && errB != nil:
+			c = compareText(va, vb)
+		case errA != nil:
+			return 1
+		case errB != nil:
+			return -1
+		default:
+			c = cmp.Compare(ka, kb)
+		}
+	default:
+		na, errA := strconv.ParseFloat(va, 64)
+		nb, errB := strconv.ParseFloat(vb, 64)
+		if errA == nil && errB == nil {
+			c = cmp.Compare(na, nb)
+		} else {
+			c = compareText(va, vb)
+		}
+	}
+	if s.Reverse {
+		return -c
+	}
+	return c
+}
+
+// sortKey returns the value of a node as a number or, for SortDate, as the Unix time of the date it holds
+func sortKey(sortType, value string) (float64, error) {
+	if sortType == SortDate {
+		t, err := ParseReminderTime("date", value)
+		if err != nil {
+			return 0, err
+		}
+		return float64(t.Unix()), nil
+	}
+	return strconv.ParseFloat(strings.TrimSpace(value), 64)
+}
+
+// textComparer returns the comparison of text by the collation options of the spec
+func (s SortSpec) textComparer() func(a, b string) int {
+	if s.Locale != "" {
+		var options []collate.Option
+		if s.Natural {
+			options = append(options, collate.Numeric)
+		}
+		if s.IgnoreCase {
+			options = append(options, collate.IgnoreCase)
+		}
+		collator := collate.New(language.Make(s.Locale), options...)
+		return collator.CompareString
+	}
+
+	fold := func(text string) string { return text }
+	if s.IgnoreCase {
+		fold = strings.ToLower
+	}
+	if s.Natural {
+		return func(a, b string) int { return compareNatural(fold(a), fold(b)) }
+	}
+	return func(a, b string) int { return strings.Compare(fold(a), fold(b)) }
+}
+
+// compareNatural compares text with the runs of digits compared by their value, so that item2 comes before item10.
+// Runs of the same value compare equal whatever their leading zeros.
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			runA, runB := digitRun(a), digitRun(b)
+			valueA, valueB := strings.TrimLeft(runA, "0"), strings.TrimLeft(runB, "0")
+			if c := cmp.Or(cmp.Compare(len(valueA), len(valueB)), strings.Compare(valueA, valueB)); c != 0 {
+				return c
+			}
+			a, b = a[len(runA):], b[len(runB):]
+			continue
+		}
+		ra, sizeA := utf8.DecodeRuneInString(a)
+		rb, sizeB := utf8.DecodeRuneInString(b)
+		if c := cmp.Compare(ra, rb); c != 0 {
+			return c
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// digitRun returns the ASCII digits text starts with
+func digitRun(text string) string {
+	end := 0
+	for end < len(text) && isDigit(text[end]) {
+		end++
+	}
+	return text[:end]
+}
+
+// isDigit reports whether a byte is an ASCII digit
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseSortCollation(t *testing.T) {
+	tests := []struct {
+		word    string
+		ok      bool
+		want    SortSpec
+		wantErr bool
+	}{
+		{word: "natural", ok: true, want: SortSpec{Natural: true}},
+		{word: "NoCase", ok: true, want: SortSpec{IgnoreCase: true}},
+		{word: "locale:de", ok: true, want: SortSpec{Locale: "de"}},
+		{word: "LOCALE:sv-SE", ok: true, want: SortSpec{Locale: "sv-SE"}},
+		{word: "locale:not a tag", ok: true, wantErr: true},
+		{word: "desc", ok: false},
+		{word: "locale", ok: false},
+	}
+
+	for _, tt := range tests {
+		var spec SortSpec
+		ok, err := ParseSortCollation(&spec, tt.word)
+		if ok != tt.ok || (err != nil) != tt.wantErr {
+			t.Errorf("ParseSortCollation(%q) = %v, %v, want %v with error %v", tt.word, ok, err, tt.ok, tt.wantErr)
+			continue
+		}
+		if err == nil && spec != tt.want {
+			t.Errorf("ParseSortCollation(%q) spec = %+v, want %+v", tt.word, spec, tt.want)
+		}
+	}
+}
+
+func TestParseSortSpec(t *testing.T) {
+	tests := []struct {
+		words   string
+		want    SortSpec
+		wantErr string
+	}{
+		{words: "name", want: SortSpec{Field: SortName}},
+		{words: "Created desc", want: SortSpec{Field: SortCreated, Reverse: true}},
+		{words: "priority desc number", want: SortSpec{Field: "priority", Reverse: true, Type: SortNumber}},
+		{words: "name auto natural nocase locale:de", want: SortSpec{Field: SortName, Natural: true, IgnoreCase: true, Locale: "de"}},
+		{words: "name asc desc", wantErr: "sort direction given twice"},
+		{words: "name text number", wantErr: "sort type given twice"},
+		{words: "name sideways", wantErr: "invalid sort 'sideways'"},
+		{words: "name asc text natural nocase locale:de extra", wantErr: "a sort is a field"},
+	}
+
+	for _, tt := range tests {
+		spec, err := ParseSortSpec(strings.Fields(tt.words))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseSortSpec(%q) error = %v, want %q", tt.words, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSortSpec(%q) failed: %v", tt.words, err)
+			continue
+		}
+		if spec != tt.want {
+			t.Errorf("ParseSortSpec(%q) = %+v, want %+v", tt.words, spec, tt.want)
+		}
+		if again, err := ParseSortSpec(strings.Fields(spec.String())); err != nil || again != spec {
+			t.Errorf("ParseSortSpec(%q) of String of %+v = %+v, %v, want it unchanged", spec.String(), spec, again, err)
+		}
+	}
+}
+
+func TestSortCollation(t *testing.T) {
+	names := []string{"item10", "Item2", "item1", "Äpfel", "apple", "item02", "Zebra"}
+
+	tests := []struct {
+		collation string
+		want      []string
+	}{
+		{"", []string{"Item2", "Zebra", "apple", "item02", "item1", "item10", "Äpfel"}},
+		{"nocase", []string{"apple", "item02", "item1", "item10", "Item2", "Zebra", "Äpfel"}},
+		{"natural", []string{"Item2", "Zebra", "apple", "item1", "item02", "item10", "Äpfel"}},
+		{"natural nocase", []string{"apple", "item1", "Item2", "item02", "item10", "Zebra", "Äpfel"}},
+		{"locale:de", []string{"Äpfel", "apple", "item02", "item1", "item10", "Item2", "Zebra"}},
+		{"locale:de natural", []string{"Äpfel", "apple", "item1", "item02", "Item2", "item10", "Zebra"}},
+	}
+
+	for _, tt := range tests {
+		spec, err := ParseSortSpec(append([]string{SortName, SortText}, strings.Fields(tt.collation)...))
+		if err != nil {
+			t.Fatalf("ParseSortSpec of collation %q failed: %v", tt.collation, err)
+		}
+		nodes := make([]*Node, len(names))
+		for i, name := range names {
+			nodes[i] = &Node{ID: i + 1, Name: name}
+		}
+		slices.SortStableFunc(nodes, spec.Comparer())
+
+		got := make([]string, len(nodes))
+		for i, node := range nodes {
+			got[i] = node.Name
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("collation %q sorts %q, want %q", tt.collation, got, tt.want)
+		}
+	}
+}
+
+func TestCompareNatural(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"item2", "item10", -1},
+		{"item10", "item2", 1},
+		{"item02", "item2", 0},
+		{"a1b2", "a1b10", -1},
+		{"a", "a1", -1},
+		{"10", "9", 1},
+		{"", "", 0},
+		{"é1", "é01", 0},
+	}
+
+	for _, tt := range tests {
+		if got := compareNatural(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareNatural(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
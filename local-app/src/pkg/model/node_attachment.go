@@ -0,0 +1,26 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import "time"
+
+// Attachment is a file stored with a node, or a reference to a web address. Its data is only loaded on request,
+// the content of a node staying small.
+type Attachment struct {
+	ID        int       `json:"id"`
+	NodeID    int       `json:"node_id"`
+	Name      string    `json:"name"`                 // The file name, or the address of a reference; unique among the attachments of the node
+	URL       string    `json:"url,omitempty"`        // The address of a reference, empty for a stored file
+	MediaType string    `json:"media_type,omitempty"` // The media type of a stored file, such as application/pdf
+	Size      int64     `json:"size"`                 // The size of a stored file in bytes
+	Data      []byte    `json:"-"`                    // The stored file, only set when loaded with its data
+	Created   time.Time `json:"created"`
+}
+
+// MindmapAttachment is an attachment of a node of an exported mindmap, a stored file carried in base64
+type MindmapAttachment struct {
+	NodeID    int    `json:"node_id" xml:"node_id,attr"`
+	Name      string `json:"name" xml:"name,attr"`
+	URL       string `json:"url,omitempty" xml:"url,attr,omitempty"`
+	MediaType string `json:"media_type,omitempty" xml:"media_type,attr,omitempty"`
+	Data      string `json:"data,omitempty" xml:",chardata"` // The stored file in standard base64
+}
@@ -0,0 +1,201 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DiffKind tells whether a part of a text diff is kept, inserted or deleted
+type DiffKind int
+
+const (
+	DiffEqual DiffKind = iota
+	DiffInsert
+	DiffDelete
+)
+
+// maxDiffCells bounds the word comparisons of a diff, longer texts are shown as replaced as a whole
+const maxDiffCells = 1 << 20
+
+const (
+	diffColorInsert = "\x1b[32m"
+	diffColorDelete = "\x1b[31;9m"
+	diffColorReset  = "\x1b[0m"
+)
+
+// DiffOp is a run of words of a text diff of the same kind
+type DiffOp struct {
+	Kind DiffKind `json:"kind"`
+	Text string   `json:"text"`
+}
+
+// TextDiff is the word-level difference between an old and a new text
+type TextDiff []DiffOp
+
+// DiffWords computes the word-level difference from old to new, words being separated by whitespace
+func DiffWords(old, new string) TextDiff {
+	a, b := strings.Fields(old), strings.Fields(new)
+
+	var diff TextDiff
+	if len(a)*len(b) > maxDiffCells {
+		diff = diff.add(DiffDelete, a...).add(DiffInsert, b...)
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = diff.add(DiffEqual, a[i])
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = diff.add(DiffDelete, a[i])
+			i++
+		default:
+			diff = diff.add(DiffInsert, b[j])
+			j++
+		}
+	}
+	return diff.add(DiffDelete, a[i:]...).add(DiffInsert, b[j:]...)
+}
+
+// add appends words to the diff, joining them to its last run if that is of the same kind
+func (d TextDiff) add(kind DiffKind, words ...string) TextDiff {
+	if len(words) == 0 {
+		return d
+	}
+	text := strings.Join(words, " ")
+	if n := len(d); n > 0 && d[n-1].Kind == kind {
+		d[n-1].Text += " " + text
+		return d
+	}
+	return append(d, DiffOp{Kind: kind, Text: text})
+}
+
+// Changed reports whether the diff inserts or deletes any words
+func (d TextDiff) Changed() bool {
+	return slices.ContainsFunc(d, func(op DiffOp) bool { return op.Kind != DiffEqual })
+}
+
+// Format writes the diff as text, the inserted words in green and the deleted words struck out in red if color
+// is set, and marked as {+inserted+} and [-deleted-] otherwise
+func (d TextDiff) Format(color bool) string {
+	parts := make([]string, len(d))
+	for i, op := range d {
+		switch {
+		case op.Kind == DiffInsert && color:
+			parts[i] = diffColorInsert + op.Text + diffColorReset
+		case op.Kind == DiffDelete && color:
+			parts[i] = diffColorDelete + op.Text + diffColorReset
+		case op.Kind == DiffInsert:
+			parts[i] = "{+" + op.Text + "+}"
+		case op.Kind == DiffDelete:
+			parts[i] = "[-" + op.Text + "-]"
+		default:
+			parts[i] = op.Text
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// String writes the diff as text without color
+func (d TextDiff) String() string {
+	return d.Format(false)
+}
+
+// FieldDiff is the change of the value of a content field of a node
+type FieldDiff struct {
+	Label string   `json:"label"`
+	Diff  TextDiff `json:"diff"`
+}
+
+// NodeChange is the result of a node update: the changes of the name and the content fields of the node, so that
+// overwritten text is visible and can be restored
+type NodeChange struct {
+	Node   string      `json:"node"` // Index of the node as displayed
+	Name   TextDiff    `json:"name"`
+	Fields []FieldDiff `json:"fields,omitempty"` // Changed fields only, in order of their labels
+	Note   string      `json:"note,omitempty"`   // Further outcome of the update, such as the wiki links updated
+}
+
+// NodeChangeOf computes the changes of a node from its old to its new name and content
+func NodeChangeOf(node string, oldName, newName string, oldContent, newContent map[string]string) NodeChange {
+	change := NodeChange{Node: node, Name: DiffWords(oldName, newName)}
+
+	labels := make([]string, 0, len(oldContent)+len(newContent))
+	for label := range oldContent {
+		labels = append(labels, label)
+	}
+	for label := range newContent {
+		if _, ok := oldContent[label]; !ok {
+			labels = append(labels, label)
+		}
+	}
+	slices.Sort(labels)
+
+	for _, label := range labels {
+		oldValue, hadValue := oldContent[label]
+		newValue, hasValue := newContent[label]
+		if hadValue == hasValue && oldValue == newValue {
+			continue
+		}
+		diff := DiffWords(oldValue, newValue)
+		if !diff.Changed() {
+			// Only the whitespace changed, the values are shown replaced as a whole
+			diff = TextDiff{{Kind: DiffDelete, Text: oldValue}, {Kind: DiffInsert, Text: newValue}}
+		}
+		change.Fields = append(change.Fields, FieldDiff{Label: label, Diff: diff})
+	}
+	return change
+}
+
+// Changed reports whether the update changed the name or any field of the node
+func (c NodeChange) Changed() bool {
+	return c.Name.Changed() || len(c.Fields) > 0
+}
+
+// Lines formats the changes one per line, the name change first, see TextDiff.Format for color
+func (c NodeChange) Lines(color bool) []string {
+	var lines []string
+	if c.Name.Changed() {
+		lines = append(lines, "name: "+c.Name.Format(color))
+	}
+	for _, field := range c.Fields {
+		lines = append(lines, field.Label+": "+field.Diff.Format(color))
+	}
+	return lines
+}
+
+// Format writes the update confirmation with the changes of the node, see TextDiff.Format for color
+func (c NodeChange) Format(color bool) string {
+	text := fmt.Sprintf("Node %s unchanged", c.Node)
+	if c.Changed() {
+		text = fmt.Sprintf("Node %s updated:\n  %s", c.Node, strings.Join(c.Lines(color), "\n  "))
+	}
+	if c.Note != "" {
+		text += "\n" + c.Note
+	}
+	return text
+}
+
+// String writes the update confirmation without color
+func (c NodeChange) String() string {
+	return c.Format(false)
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     TextDiff
+		changed  bool
+	}{
+		{"", "", nil, false},
+		{"same words", "same  words", TextDiff{{DiffEqual, "same words"}}, false},
+		{"", "new text", TextDiff{{DiffInsert, "new text"}}, true},
+		{"old text", "", TextDiff{{DiffDelete, "old text"}}, true},
+		{"buy milk today", "buy bread today", TextDiff{{DiffEqual, "buy"}, {DiffDelete, "milk"}, {DiffInsert, "bread"}, {DiffEqual, "today"}}, true},
+		{"a b c", "a x b c y", TextDiff{{DiffEqual, "a"}, {DiffInsert, "x"}, {DiffEqual, "b c"}, {DiffInsert, "y"}}, true},
+		{"a b c d", "a d", TextDiff{{DiffEqual, "a"}, {DiffDelete, "b c"}, {DiffEqual, "d"}}, true},
+		{"one two", "two one", TextDiff{{DiffDelete, "one"}, {DiffEqual, "two"}, {DiffInsert, "one"}}, true},
+	}
+
+	for _, tt := range tests {
+		got := DiffWords(tt.old, tt.new)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("DiffWords(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+		if got.Changed() != tt.changed {
+			t.Errorf("DiffWords(%q, %q).Changed() = %v, want %v", tt.old, tt.new, got.Changed(), tt.changed)
+		}
+	}
+}
+
+func TestTextDiffFormat(t *testing.T) {
+	diff := DiffWords("buy milk", "buy bread")
+	if got, want := diff.Format(false), "buy [-milk-] {+bread+}"; got != want {
+		t.Errorf("Format(false) = %q, want %q", got, want)
+	}
+	if got, want := diff.Format(true), "buy "+diffColorDelete+"milk"+diffColorReset+" "+diffColorInsert+"bread"+diffColorReset; got != want {
+		t.Errorf("Format(true) = %q, want %q", got, want)
+	}
+}
+
+func TestNodeChangeOf(t *testing.T) {
+	change := NodeChangeOf("1.2", "Plan", "Plan",
+		map[string]string{"due": "2024-05-01", "note": "call  Bob", "old": "gone"},
+		map[string]string{"due": "2024-06-01", "note": "call Bob", "new": "added"})
+
+	if change.Name.Changed() {
+		t.Errorf("name diff %v, want unchanged", change.Name)
+	}
+	want := []string{
+		"due: [-2024-05-01-] {+2024-06-01+}",
+		"new: {+added+}",
+		"note: [-call  Bob-] {+call Bob+}",
+		"old: [-gone-]",
+	}
+	if got := change.Lines(false); !slices.Equal(got, want) {
+		t.Errorf("Lines = %q, want %q", got, want)
+	}
+
+	if unchanged := NodeChangeOf("1", "A", "A", map[string]string{"k": "v"}, map[string]string{"k": "v"}); unchanged.Changed() {
+		t.Errorf("NodeChangeOf of equal nodes = %+v, want unchanged", unchanged)
+	} else if got, want := unchanged.String(), "Node 1 unchanged"; got != want {
+		t.Errorf("String of unchanged node = %q, want %q", got, want)
+	}
+
+	renamed := NodeChangeOf("3", "Old name", "New name", nil, nil)
+	if got, want := renamed.String(), "Node 3 updated:\n  name: [-Old-] {+New+} name"; got != want {
+		t.Errorf("String of renamed node = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Content fields holding the geographic location of a node, in decimal degrees (WGS 84)
+const (
+	LatField = "lat"
+	LonField = "lon"
+)
+
+// ParseCoordinate parses the value of a lat or lon field, checking it lies within the range of the field
+func ParseCoordinate(field, value string) (float64, error) {
+	limit := 180.0
+	if field == LatField {
+		limit = 90
+	}
+	degrees, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s '%s': not a number of degrees", field, value)
+	}
+	if degrees < -limit || degrees > limit {
+		return 0, fmt.Errorf("invalid %s '%s': must be between %g and %g", field, value, -limit, limit)
+	}
+	return degrees, nil
+}
+
+// Location returns the latitude and longitude of a node, ok is false unless the node has valid lat and lon fields
+func (n *Node) Location() (lat, lon float64, ok bool) {
+	if n.Content[LatField] == "" || n.Content[LonField] == "" {
+		return 0, 0, false
+	}
+	lat, errLat := ParseCoordinate(LatField, n.Content[LatField])
+	lon, errLon := ParseCoordinate(LonField, n.Content[LonField])
+	return lat, lon, errLat == nil && errLon == nil
+}
@@ -0,0 +1,132 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Content fields holding hints for the layout of a node in the diagram exports
+const (
+	LayoutSideField   = "layout_side"   // The side of a top-level node, left or right
+	LayoutOrderField  = "layout_order"  // The pinned position of a node among its siblings, lowest first
+	LayoutWeightField = "layout_weight" // The weight of a node, heavier siblings first and sides balanced by weight
+)
+
+// Sides of the top-level nodes in a layout
+const (
+	LayoutLeft  = "left"
+	LayoutRight = "right"
+)
+
+// IsLayoutField reports whether a content field is a layout hint, which layouts apply instead of showing it
+func IsLayoutField(field string) bool {
+	return field == LayoutSideField || field == LayoutOrderField || field == LayoutWeightField
+}
+
+// ParseLayoutHint checks the value of a layout field
+func ParseLayoutHint(field, value string) error {
+	value = strings.TrimSpace(value)
+	switch field {
+	case LayoutSideField:
+		if value != LayoutLeft && value != LayoutRight {
+			return fmt.Errorf("invalid %s '%s': must be '%s' or '%s'", field, value, LayoutLeft, LayoutRight)
+		}
+	case LayoutOrderField:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("invalid %s '%s': not a whole number", field, value)
+		}
+	case LayoutWeightField:
+		if weight, err := strconv.ParseFloat(value, 64); err != nil || weight < 0 {
+			return fmt.Errorf("invalid %s '%s': not a number of at least 0", field, value)
+		}
+	}
+	return nil
+}
+
+// LayoutSide returns the side of a node, empty if it has none
+func (n *Node) LayoutSide() string {
+	side := strings.TrimSpace(n.Content[LayoutSideField])
+	if ParseLayoutHint(LayoutSideField, side) != nil {
+		return ""
+	}
+	return side
+}
+
+// LayoutOrder returns the pinned position of a node, ok is false unless the node has a valid layout_order field
+func (n *Node) LayoutOrder() (order int, ok bool) {
+	order, err := strconv.Atoi(strings.TrimSpace(n.Content[LayoutOrderField]))
+	return order, err == nil
+}
+
+// LayoutWeight returns the weight of a node, 1 unless the node has a valid layout_weight field
+func (n *Node) LayoutWeight() float64 {
+	weight, err := strconv.ParseFloat(strings.TrimSpace(n.Content[LayoutWeightField]), 64)
+	if err != nil || weight < 0 {
+		return 1
+	}
+	return weight
+}
+
+// LayoutSort returns siblings in layout order: the pinned nodes by their order, then the others by descending
+// weight. Nodes of equal order or weight keep their order, so siblings without hints are not reordered.
+func LayoutSort(siblings []*Node) []*Node {
+	sorted := slices.Clone(siblings)
+	slices.SortStableFunc(sorted, func(a, b *Node) int {
+		aOrder, aPinned := a.LayoutOrder()
+		bOrder, bPinned := b.LayoutOrder()
+		switch {
+		case aPinned && bPinned:
+			return cmp.Compare(aOrder, bOrder)
+		case aPinned:
+			return -1
+		case bPinned:
+			return 1
+		}
+		return cmp.Compare(b.LayoutWeight(), a.LayoutWeight())
+	})
+	return sorted
+}
+
+// LayoutSides splits the top-level nodes of a layout into its sides, each in layout order. Nodes with a side
+// hint go to their side. Once any node has a side or weight, the others go to the lighter side in layout order,
+// otherwise all are on the right.
+func LayoutSides(nodes []*Node) (right, left []*Node) {
+	nodes = LayoutSort(nodes)
+	balance := slices.ContainsFunc(nodes, func(n *Node) bool {
+		return n.LayoutSide() != "" || n.Content[LayoutWeightField] != ""
+	})
+
+	var rightWeight, leftWeight float64
+	for _, node := range nodes {
+		if node.LayoutSide() == LayoutLeft {
+			leftWeight += node.LayoutWeight()
+		} else if node.LayoutSide() == LayoutRight {
+			rightWeight += node.LayoutWeight()
+		}
+	}
+
+	for _, node := range nodes {
+		side := node.LayoutSide()
+		if side == "" {
+			side = LayoutRight
+			if balance && leftWeight < rightWeight {
+				side = LayoutLeft
+			}
+			if side == LayoutLeft {
+				leftWeight += node.LayoutWeight()
+			} else {
+				rightWeight += node.LayoutWeight()
+			}
+		}
+		if side == LayoutLeft {
+			left = append(left, node)
+		} else {
+			right = append(right, node)
+		}
+	}
+	return right, left
+}
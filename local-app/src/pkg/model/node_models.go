@@ -1,7 +1,10 @@
 // Package model defines the data structures used throughout the Mindnoscape application.
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Node represents a single node in a mind map.
 type Node struct {
@@ -11,6 +14,7 @@ type Node struct {
 	Name      string            `json:"name" xml:"name,attr"`
 	Index     string            `json:"index" xml:"index,attr"`
 	Content   map[string]string `json:"content,omitempty" xml:"content,omitempty"`
+	Tags      []string          `json:"tags,omitempty" xml:"tags>tag,omitempty"` // Sorted, in the stored lowercase form
 	Children  []*Node           `json:"children,omitempty" xml:"children>node,omitempty"`
 	Created   time.Time         `json:"created" xml:"created,attr"`
 	Updated   time.Time         `json:"updated" xml:"updated,attr"`
@@ -24,6 +28,7 @@ type NodeInfo struct {
 	Name      string
 	Index     string
 	Content   map[string]string
+	Tags      []string
 }
 
 // NodeFilter defines the options for filtering nodes.
@@ -35,3 +40,11 @@ type NodeFilter struct {
 	Index     bool
 	Content   bool
 }
+
+// IndexLevel returns the level below the root of the node of a stored index, 0 for the root with index "0"
+func IndexLevel(index string) int {
+	if index == "0" {
+		return 0
+	}
+	return strings.Count(index, ".") + 1
+}
@@ -0,0 +1,26 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PrivateField marks the subtree of a node as private, hidden from the users other than the owner of a mindmap
+const PrivateField = "private"
+
+// ParsePrivate parses the value of a private field
+func ParsePrivate(value string) (bool, error) {
+	private, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("invalid %s '%s': must be true or false", PrivateField, value)
+	}
+	return private, nil
+}
+
+// IsPrivate reports whether the subtree of a node is private
+func (n *Node) IsPrivate() bool {
+	private, err := ParsePrivate(n.Content[PrivateField])
+	return err == nil && private
+}
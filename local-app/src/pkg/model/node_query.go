@@ -0,0 +1,169 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Keywords combining the terms of a node query, AND binding tighter than OR. Terms next to each other without a
+// keyword must all match.
+const (
+	QueryAnd = "AND"
+	QueryOr  = "OR"
+)
+
+// Scopes of a node query term, written before a colon such as name:idea, index:2.1 or content.priority:high
+const (
+	QueryName    = "name"
+	QueryIndex   = "index"
+	QueryContent = "content" // All content field names and values, or the value of one field as content.<field>
+)
+
+// NodeQuery is a parsed node search query, such as "name:/^todo/ content.priority:high OR urgent"
+type NodeQuery struct {
+	alternatives [][]queryTerm // The query matches if all terms of one of the alternatives match
+}
+
+// queryTerm is a single condition of a node query
+type queryTerm struct {
+	scope   string         // The scope searched, or empty to search the scopes of the filter the query runs with
+	field   string         // The content field searched, or empty for all fields
+	text    string         // The text searched for, lowercased, if the term is not a regular expression
+	pattern *regexp.Regexp // The regular expression of a term written as /regex/
+}
+
+// ParseNodeQuery parses a node search query. Each word is a term, a text matched regardless of case or a regular
+// expression written as /regex/, optionally preceded by a scope, name:, index:, content: or content.<field>:.
+// Words of other forms, such as http://example.com, are texts. Terms are combined with AND and OR.
+func ParseNodeQuery(query string) (NodeQuery, error) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return NodeQuery{}, errors.New("empty query")
+	}
+
+	var q NodeQuery
+	var terms []queryTerm
+	for i, word := range words {
+		if word == QueryAnd || word == QueryOr {
+			if i == 0 || i == len(words)-1 || words[i-1] == QueryAnd || words[i-1] == QueryOr {
+				return NodeQuery{}, fmt.Errorf("%s must be between two terms", word)
+			}
+			if word == QueryOr {
+				q.alternatives = append(q.alternatives, terms)
+				terms = nil
+			}
+			continue
+		}
+
+		term, err := parseQueryTerm(word)
+		if err != nil {
+			return NodeQuery{}, err
+		}
+		terms = append(terms, term)
+	}
+	q.alternatives = append(q.alternatives, terms)
+	return q, nil
+}
+
+// parseQueryTerm parses a word of a query into a term
+func parseQueryTerm(word string) (queryTerm, error) {
+	var term queryTerm
+	if scope, value, ok := strings.Cut(word, ":"); ok {
+		lower := strings.ToLower(scope)
+		switch {
+		case lower == QueryName || lower == QueryIndex || lower == QueryContent:
+			term.scope, word = lower, value
+		case strings.HasPrefix(lower, QueryContent+".") && len(scope) > len(QueryContent)+1:
+			term.scope, term.field, word = QueryContent, scope[len(QueryContent)+1:], value
+		}
+	}
+
+	if len(word) >= 2 && strings.HasPrefix(word, "/") && strings.HasSuffix(word, "/") {
+		pattern, err := regexp.Compile(word[1 : len(word)-1])
+		if err != nil {
+			return queryTerm{}, fmt.Errorf("invalid regular expression %s: %w", word, err)
+		}
+		term.pattern = pattern
+		return term, nil
+	}
+	term.text = strings.ToLower(word)
+	return term, nil
+}
+
+// Match reports whether a node matches the query. Terms without a scope search the name, the content fields and
+// the index of the node as selected by filter.
+func (q NodeQuery) Match(node *Node, filter NodeFilter) bool {
+	for _, terms := range q.alternatives {
+		all := true
+		for _, term := range terms {
+			if !term.match(node, filter) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether a node matches the term
+func (t queryTerm) match(node *Node, filter NodeFilter) bool {
+	switch t.scope {
+	case QueryName:
+		return t.matchText(node.Name)
+	case QueryIndex:
+		return t.matchText(node.Index)
+	case QueryContent:
+		for key, value := range node.Content {
+			if t.field == "" && (t.matchText(key) || t.matchText(value)) {
+				return true
+			}
+			if t.field != "" && strings.EqualFold(key, t.field) && t.matchText(value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return filter.Name && t.matchText(node.Name) ||
+		filter.Content && queryTerm{scope: QueryContent, text: t.text, pattern: t.pattern}.match(node, filter) ||
+		filter.Index && t.matchText(node.Index)
+}
+
+// TextTerm is a term of a node query searching for a text, as run by a full-text index
+type TextTerm struct {
+	Scope string // QueryName, QueryContent for all content field names and values, or empty for the scopes of the filter
+	Text  string // The text searched for, lowercased
+}
+
+// TextTerms returns the alternatives of the query as texts searched in the names and content of the nodes, for
+// queries a full-text index can run. Returns false for queries with regular expressions, index terms or content
+// field terms, which only Match runs.
+func (q NodeQuery) TextTerms() ([][]TextTerm, bool) {
+	alternatives := make([][]TextTerm, 0, len(q.alternatives))
+	for _, terms := range q.alternatives {
+		textTerms := make([]TextTerm, 0, len(terms))
+		for _, term := range terms {
+			if term.pattern != nil || term.scope == QueryIndex || term.field != "" {
+				return nil, false
+			}
+			textTerms = append(textTerms, TextTerm{Scope: term.scope, Text: term.text})
+		}
+		alternatives = append(alternatives, textTerms)
+	}
+	return alternatives, true
+}
+
+// matchText reports whether a text contains the text of the term regardless of case, or matches its regular
+// expression
+func (t queryTerm) matchText(s string) bool {
+	if t.pattern != nil {
+		return t.pattern.MatchString(s)
+	}
+	return strings.Contains(strings.ToLower(s), t.text)
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseNodeQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{query: "idea"},
+		{query: "name:/^todo/ content.priority:high OR urgent"},
+		{query: "a AND b OR c"},
+		{query: "http://example.com"},
+		{query: "   ", wantErr: "empty query"},
+		{query: "AND idea", wantErr: "AND must be between two terms"},
+		{query: "idea OR", wantErr: "OR must be between two terms"},
+		{query: "a OR AND b", wantErr: "AND must be between two terms"},
+		{query: "name:/[/", wantErr: "invalid regular expression"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseNodeQuery(tt.query)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("ParseNodeQuery(%q) failed: %v", tt.query, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("ParseNodeQuery(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNodeQueryMatch(t *testing.T) {
+	nodes := []*Node{
+		{ID: 1, Name: "Todo: call Bob", Index: "1", Content: map[string]string{"priority": "high"}},
+		{ID: 2, Name: "Project plan", Index: "1.1", Content: map[string]string{"Status": "todo"}},
+		{ID: 3, Name: "Urgent fix", Index: "2"},
+		{ID: 4, Name: "Notes", Index: "2.1", Content: map[string]string{"url": "http://example.com"}},
+	}
+	all := NodeFilter{Name: true, Content: true, Index: true}
+
+	tests := []struct {
+		query  string
+		filter NodeFilter
+		want   []int
+	}{
+		{"todo", all, []int{1, 2}},
+		{"TODO", NodeFilter{Name: true}, []int{1}},
+		{"todo", NodeFilter{Content: true}, []int{2}},
+		{"name:todo", NodeFilter{}, []int{1}},
+		{"NAME:/^todo/", all, nil},
+		{"name:/^Todo/", all, []int{1}},
+		{"content:status", all, []int{2}},
+		{"content.status:TODO", all, []int{2}},
+		{"content.priority:todo", all, nil},
+		{"index:2", all, []int{3, 4}},
+		{"2.1", NodeFilter{Name: true}, nil},
+		{"2.1", all, []int{4}},
+		{"todo plan", all, []int{2}},
+		{"todo AND plan", all, []int{2}},
+		{"urgent OR content.priority:high", all, []int{1, 3}},
+		{"plan todo OR urgent fix OR notes", all, []int{2, 3, 4}},
+		{"http://example.com", all, []int{4}},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseNodeQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseNodeQuery(%q) failed: %v", tt.query, err)
+		}
+		var got []int
+		for _, node := range nodes {
+			if q.Match(node, tt.filter) {
+				got = append(got, node.ID)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q with filter %+v matches %v, want %v", tt.query, tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestNodeQueryTextTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  [][]TextTerm
+		ok    bool
+	}{
+		{"Plan", [][]TextTerm{{{Text: "plan"}}}, true},
+		{"name:plan notes OR content:todo", [][]TextTerm{{{Scope: QueryName, Text: "plan"}, {Text: "notes"}}, {{Scope: QueryContent, Text: "todo"}}}, true},
+		{"/plan/", nil, false},
+		{"index:1", nil, false},
+		{"content.status:todo", nil, false},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseNodeQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseNodeQuery(%q) failed: %v", tt.query, err)
+		}
+		got, ok := q.TextTerms()
+		if ok != tt.ok || !slices.EqualFunc(got, tt.want, slices.Equal) {
+			t.Errorf("TextTerms of %q = %v, %v, want %v, %v", tt.query, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Content fields holding the due date of a node and the time to remind of it, in local time. A node without a
+// remind field is reminded of at its due time.
+const (
+	DueField    = "due"
+	RemindField = "remind"
+)
+
+// Layouts of the values of the due and remind fields, a date alone stands for the start of the day
+const (
+	ReminderTimeLayout = "2006-01-02T15:04"
+	ReminderDateLayout = "2006-01-02"
+)
+
+// ParseReminderTime parses the value of a due or remind field as a local time
+func ParseReminderTime(field, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{ReminderTimeLayout, ReminderDateLayout} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s '%s': must be a date and time as YYYY-MM-DDTHH:MM or a date as YYYY-MM-DD", field, value)
+}
+
+// ReminderTime returns the time to remind of a node with the given content, the time of its remind field or else
+// of its due field, and the field it comes from. ok is false if the node has no valid time to remind of it.
+func ReminderTime(content map[string]string) (time.Time, string, bool) {
+	for _, field := range []string{RemindField, DueField} {
+		if value := content[field]; value != "" {
+			at, err := ParseReminderTime(field, value)
+			return at, field, err == nil
+		}
+	}
+	return time.Time{}, "", false
+}
+
+// Reminder is a node to remind its mindmap's owner of
+type Reminder struct {
+	MindmapID   int       `json:"mindmap_id"`
+	MindmapName string    `json:"mindmap"`
+	Owner       string    `json:"owner"`
+	NodeID      int       `json:"node_id"`
+	Index       string    `json:"index"`
+	Name        string    `json:"name"`
+	Field       string    `json:"field"` // The field the reminder time comes from, remind or due
+	Due         string    `json:"due,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// ReminderSink is a destination reminders are delivered to
+type ReminderSink struct {
+	Type   string `json:"type"`   // webhook, command or log
+	Target string `json:"target"` // The URL of a webhook or the program run by a command, unused by log
+}
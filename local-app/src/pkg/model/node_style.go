@@ -0,0 +1,76 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Content fields holding the visual style of a node, kept from the files of other mindmap tools. Icons are kept
+// in the markers field.
+const (
+	StyleColorField = "style_color" // The text color, as #rrggbb
+	StyleFillField  = "style_fill"  // The background color, as #rrggbb
+	StyleShapeField = "style_shape" // The shape of the node in the tool it comes from, such as rounded-rect
+	StyleFontField  = "style_font"  // The font style, bold, italic or both
+)
+
+// Font styles of the style_font field
+const (
+	StyleBold   = "bold"
+	StyleItalic = "italic"
+)
+
+// styleColorPattern matches the colors of the style fields
+var styleColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// IsStyleField reports whether a content field is a style field, which diagram exports apply where they can
+// instead of showing it
+func IsStyleField(field string) bool {
+	return field == StyleColorField || field == StyleFillField || field == StyleShapeField || field == StyleFontField
+}
+
+// ParseStyle checks the value of a style field
+func ParseStyle(field, value string) error {
+	value = strings.TrimSpace(value)
+	switch field {
+	case StyleColorField, StyleFillField:
+		if !styleColorPattern.MatchString(value) {
+			return fmt.Errorf("invalid %s '%s': must be a color such as #4f81bd", field, value)
+		}
+	case StyleFontField:
+		for _, word := range strings.Fields(value) {
+			if word != StyleBold && word != StyleItalic {
+				return fmt.Errorf("invalid %s '%s': must be '%s', '%s' or both", field, value, StyleBold, StyleItalic)
+			}
+		}
+	}
+	return nil
+}
+
+// StyleColor returns the text color of a node, empty if it has none
+func (n *Node) StyleColor() string {
+	return n.styleValue(StyleColorField)
+}
+
+// StyleFill returns the background color of a node, empty if it has none
+func (n *Node) StyleFill() string {
+	return n.styleValue(StyleFillField)
+}
+
+// StyleFont reports whether the text of a node is bold and italic
+func (n *Node) StyleFont() (bold, italic bool) {
+	words := strings.Fields(n.styleValue(StyleFontField))
+	return slices.Contains(words, StyleBold), slices.Contains(words, StyleItalic)
+}
+
+// styleValue returns the value of a style field of a node in lower case, empty if it is missing or invalid
+func (n *Node) styleValue(field string) string {
+	value := strings.TrimSpace(n.Content[field])
+	if ParseStyle(field, value) != nil {
+		return ""
+	}
+	return strings.ToLower(value)
+}
@@ -0,0 +1,30 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+// PageOptions selects a page of a list result. A zero Limit selects all items from Offset on.
+type PageOptions struct {
+	Limit  int
+	Offset int
+}
+
+// Page is one page of a list result, with the total number of items so that adapters can page through them.
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// PageOf takes the page selected by options from items
+func PageOf[T any](items []T, options PageOptions) Page[T] {
+	start := min(options.Offset, len(items))
+	end := len(items)
+	if options.Limit > 0 {
+		end = min(start+options.Limit, end)
+	}
+	return Page[T]{Items: items[start:end], Offset: options.Offset, Total: len(items)}
+}
+
+// More reports whether items follow the page
+func (p Page[T]) More() bool {
+	return p.Offset+len(p.Items) < p.Total
+}
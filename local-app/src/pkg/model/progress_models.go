@@ -0,0 +1,16 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import "time"
+
+// Progress reports the advance of a long-running operation, counted in nodes.
+type Progress struct {
+	Operation string
+	Done      int
+	Total     int
+	Started   time.Time
+}
+
+// ProgressFunc receives progress reports. It is called on the goroutine running the operation,
+// so it must return quickly.
+type ProgressFunc func(Progress)
@@ -6,5 +6,44 @@ type Session struct {
 	ID           string
 	User         *User
 	Mindmap      *Mindmap
+	Node         *Node // Current node of the selected mindmap, set by commands such as journal today
 	LastActivity time.Time
+	Progress     ProgressFunc   // Receives progress of long-running commands, set by the adapter if it can display it
+	Passphrase   PassphraseFunc // Asks the user for a passphrase, set by the adapter if it can prompt for one
+	Resolve      ConflictFunc   // Asks the user how to resolve a conflict of an import, set by the adapter if it can
+	Confirm      ConfirmFunc    // Asks the user a yes or no question, set by the adapter if it can prompt for an answer
+	Background   bool           // Runs heavy commands as background jobs, set by the adapter if it reports their results later
+	Authenticate bool           // Requires the password of a user to select it and limits the commands, set by the adapters of network clients
+	Transcript   string         // File the commands of the session and their results are recorded to, empty if none
+}
+
+// Job is a heavy command run in the background of a session, such as an import, while the session takes other commands
+type Job struct {
+	ID       int
+	Command  Command
+	Started  time.Time
+	Finished time.Time // Zero while the job runs
+	Progress Progress  // The last progress reported, zero if none was
+	Result   interface{}
+	Err      error
+}
+
+// Running reports whether the job has not finished yet
+func (j Job) Running() bool {
+	return j.Finished.IsZero()
+}
+
+// PassphraseFunc prompts the user for a passphrase and returns it, without showing it where possible
+type PassphraseFunc func(prompt string) (string, error)
+
+// ConfirmFunc asks the user a yes or no question and returns whether the answer was yes
+type ConfirmFunc func(question string) (bool, error)
+
+// CurrentNode returns the current node, or nil if none is set or it is not a node of the selected mindmap anymore,
+// because another mindmap was selected since or the node was deleted
+func (s *Session) CurrentNode() *Node {
+	if s.Node == nil || s.Mindmap == nil || s.Mindmap.Nodes[s.Node.ID] != s.Node {
+		return nil
+	}
+	return s.Node
 }
@@ -0,0 +1,33 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import "time"
+
+// CommandUsage counts the uses of a command, identified by scope and operation only, and how many of them failed.
+// No users, arguments or data are recorded.
+type CommandUsage struct {
+	Scope     string `json:"scope"`
+	Operation string `json:"operation"`
+	Count     int    `json:"count"`
+	Errors    int    `json:"errors"`
+}
+
+// ErrorRate returns the share of the uses of the command that failed, from 0 to 1
+func (u CommandUsage) ErrorRate() float64 {
+	if u.Count == 0 {
+		return 0
+	}
+	return float64(u.Errors) / float64(u.Count)
+}
+
+// UsageReport is the exported command telemetry, for the user to share with the maintainers if they wish
+type UsageReport struct {
+	Exported time.Time         `json:"exported"`
+	Commands []UsageReportItem `json:"commands"`
+}
+
+// UsageReportItem is the usage of a command in a UsageReport, with its error rate
+type UsageReportItem struct {
+	CommandUsage
+	ErrorRate float64 `json:"error_rate"`
+}
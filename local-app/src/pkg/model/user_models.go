@@ -5,28 +5,34 @@ import "time"
 
 // User represents a user account in the Mindnoscape application.
 type User struct {
-	ID           int              `json:"id" xml:"id,attr"`
-	Username     string           `json:"username" xml:"username"`
-	PasswordHash []byte           `json:"-" xml:"-"`
-	Mindmaps     map[int]*Mindmap `json:"mindmaps,omitempty" xml:"mindmaps>mindmaps,omitempty"`
-	Active       bool             `json:"active" xml:"active,attr"`
-	Created      time.Time        `json:"created" xml:"created,attr"`
-	Updated      time.Time        `json:"updated" xml:"updated,attr"`
+	ID             int              `json:"id" xml:"id,attr"`
+	Username       string           `json:"username" xml:"username"`
+	PasswordHash   []byte           `json:"-" xml:"-"`
+	Mindmaps       map[int]*Mindmap `json:"mindmaps,omitempty" xml:"mindmaps>mindmaps,omitempty"`
+	Active         bool             `json:"active" xml:"active,attr"`
+	DefaultMindmap string           `json:"default_mindmap,omitempty" xml:"default_mindmap,omitempty"` // Selected along with the user
+	Inbox          int              `json:"inbox,omitempty" xml:"inbox,omitempty"`                     // ID of the node of the default mindmap quick adds go under, the top-level Inbox node if 0
+	Created        time.Time        `json:"created" xml:"created,attr"`
+	Updated        time.Time        `json:"updated" xml:"updated,attr"`
 }
 
 // UserInfo contains basic information about a user.
 type UserInfo struct {
-	ID           int
-	Username     string
-	PasswordHash []byte
-	Active       bool
-	MindmapCount *int
+	ID             int
+	Username       string
+	PasswordHash   []byte
+	Active         bool
+	DefaultMindmap string
+	Inbox          int
+	MindmapCount   *int
 }
 
 // UserFilter defines the options for filtering users.
 type UserFilter struct {
-	ID           bool
-	Username     bool
-	PasswordHash bool
-	Active       bool
+	ID             bool
+	Username       bool
+	PasswordHash   bool
+	Active         bool
+	DefaultMindmap bool
+	Inbox          bool
 }
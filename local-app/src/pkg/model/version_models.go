@@ -0,0 +1,15 @@
+// Package model defines the data structures used throughout the Mindnoscape application.
+package model
+
+import "time"
+
+// MindmapVersion is a stored copy of a mindmap, taken automatically after a number of node changes or as a
+// checkpoint named by the user
+type MindmapVersion struct {
+	MindmapID int       `json:"mindmap_id"`
+	Version   int       `json:"version"` // Numbered from 1 within the mindmap
+	Label     string    `json:"label,omitempty"`
+	Auto      bool      `json:"auto"` // Taken automatically, and deleted beyond the configured retention
+	NodeCount int       `json:"node_count"`
+	Created   time.Time `json:"created"`
+}
@@ -0,0 +1,53 @@
+// Package names provides the normalization of user-visible names such as usernames and mindmap names,
+// so names that look the same to a user are treated as the same name.
+package names
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize returns the name in Unicode NFC form with surrounding whitespace removed.
+// Composed and decomposed forms of the same accented characters normalize to the same string.
+func Normalize(name string) string {
+	return norm.NFC.String(strings.TrimSpace(name))
+}
+
+// Key returns the comparison key of a name. Two names with the same key are considered the same name.
+// Unless caseSensitive is set, the key is case-folded so that names differing only in case collide.
+func Key(name string, caseSensitive bool) string {
+	key := Normalize(name)
+	if !caseSensitive {
+		key = norm.NFC.String(cases.Fold().String(key))
+	}
+	return key
+}
+
+// Equal reports whether two names are the same name under normalization
+func Equal(a, b string, caseSensitive bool) bool {
+	return Key(a, caseSensitive) == Key(b, caseSensitive)
+}
+
+// Filename returns the name made safe for use as a file name component. Characters other than letters,
+// digits, '-', '_' and '.' are replaced with '_', and leading dots are removed so the result is never hidden
+// or a relative path element. An empty result is replaced with the fallback.
+func Filename(name, fallback string) string {
+	var b strings.Builder
+	for _, r := range Normalize(name) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	filename := strings.TrimLeft(b.String(), ".")
+	if filename == "" {
+		return fallback
+	}
+	return filename
+}
@@ -0,0 +1,96 @@
+package names
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind identifies what a validated name is used for
+type Kind int
+
+const (
+	User Kind = iota
+	Mindmap
+	Field
+	Tag
+)
+
+// String returns the string representation of the Kind
+func (k Kind) String() string {
+	switch k {
+	case User:
+		return "username"
+	case Mindmap:
+		return "mindmap name"
+	case Field:
+		return "field name"
+	case Tag:
+		return "tag"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// maxLength is the maximum number of characters of a name per kind
+var maxLength = map[Kind]int{
+	User:    32,
+	Mindmap: 64,
+	Field:   32,
+	Tag:     32,
+}
+
+// reservedNames are names that collide with command flags or special file names
+var reservedNames = map[string]bool{
+	".": true, "..": true,
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// Validate checks that a name is usable as the given kind of name. Names must not be empty, must not exceed
+// the maximum length, must not start with a dash so they can't be mistaken for flags such as --id,
+// must not be reserved and may only contain letters, digits and the punctuation allowed for the kind.
+// Mindmap names may contain spaces, usernames, field names and tags may not; field names may not contain ':',
+// which separates field names from values in node commands, and tags may contain '/' to group them such as
+// work/urgent.
+func Validate(kind Kind, name string) error {
+	name = Normalize(name)
+
+	if name == "" {
+		return fmt.Errorf("%s must not be empty", kind)
+	}
+	if length := utf8.RuneCountInString(name); length > maxLength[kind] {
+		return fmt.Errorf("%s '%s' is too long: %d characters, maximum is %d", kind, name, length, maxLength[kind])
+	}
+	if strings.HasPrefix(name, "-") {
+		return fmt.Errorf("%s '%s' must not start with '-'", kind, name)
+	}
+	if reservedNames[strings.ToLower(name)] {
+		return fmt.Errorf("%s '%s' is reserved", kind, name)
+	}
+
+	for _, r := range name {
+		if !validRune(kind, r) {
+			return fmt.Errorf("%s '%s' contains invalid character %q", kind, name, r)
+		}
+	}
+	return nil
+}
+
+// validRune reports whether a character is allowed in the given kind of name
+func validRune(kind Kind, r rune) bool {
+	switch {
+	case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r):
+		return true
+	case r == '_', r == '-', r == '.':
+		return true
+	case r == ' ', r == '\'', r == '(', r == ')', r == ',', r == '&', r == '+':
+		return kind == Mindmap
+	case r == '/':
+		return kind == Tag
+	default:
+		return false
+	}
+}
@@ -0,0 +1,78 @@
+// Package notify delivers reminders of nodes to webhooks and desktop notification commands
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// Reminder sink types
+const (
+	SinkWebhook = "webhook" // POSTs the reminder as JSON to the target URL
+	SinkCommand = "command" // Runs the target program, such as notify-send, with a title and the reminder text
+	SinkLog     = "log"     // Writes the reminder to the info log, delivered by the caller
+)
+
+// Title is the title of reminder notifications
+const Title = "Mindnoscape reminder"
+
+// Text describes a reminder in one line
+func Text(reminder *model.Reminder) string {
+	text := fmt.Sprintf("%s (%s, node %s)", reminder.Name, reminder.MindmapName, reminder.Index)
+	if reminder.Due != "" {
+		text += ", due " + strings.Replace(reminder.Due, "T", " ", 1)
+	}
+	return text
+}
+
+// Deliver sends a reminder to a webhook or command sink, the context bounds the time allowed
+func Deliver(ctx context.Context, sink model.ReminderSink, reminder *model.Reminder) error {
+	switch sink.Type {
+	case SinkWebhook:
+		return deliverWebhook(ctx, sink.Target, reminder)
+	case SinkCommand:
+		if sink.Target == "" {
+			return fmt.Errorf("command sink has no program")
+		}
+		output, err := exec.CommandContext(ctx, sink.Target, Title, Text(reminder)).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to run %s: %w: %s", sink.Target, err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown reminder sink type: %s", sink.Type)
+	}
+}
+
+// deliverWebhook posts a reminder with its text as JSON to a URL
+func deliverWebhook(ctx context.Context, url string, reminder *model.Reminder) error {
+	body, err := json.Marshal(struct {
+		*model.Reminder
+		Text string `json:"text"`
+	}{reminder, Text(reminder)})
+	if err != nil {
+		return fmt.Errorf("failed to encode reminder: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+const defaultAuditTailCount = 20
+
+// handleAdminAudit handles the admin audit command, showing the entries of all users to admins and their own to
+// other users
+func handleAdminAudit(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling admin audit command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 {
+		sm.logger.Error(ctx, "Insufficient arguments for admin audit", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("admin audit command requires 1 to 3 arguments: tail [count] | search <query> [count]")
+	}
+
+	var entries []*model.AuditEntry
+	var err error
+	username := ""
+	if !sm.isAdmin(session) {
+		username = session.User.Username
+	}
+
+	switch strings.ToLower(cmd.Args[0]) {
+	case "tail":
+		count := defaultAuditTailCount
+		if len(cmd.Args) > 1 {
+			count, err = parseAuditCount(cmd.Args[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		entries, err = sm.dataManager.AuditManager.AuditTail(username, count)
+	case "search":
+		if len(cmd.Args) < 2 {
+			sm.logger.Error(ctx, "Missing query for admin audit search", nil)
+			return nil, errors.New("admin audit search requires a query: search <query> [count]")
+		}
+		count := defaultAuditTailCount
+		if len(cmd.Args) > 2 {
+			count, err = parseAuditCount(cmd.Args[2])
+			if err != nil {
+				return nil, err
+			}
+		}
+		entries, err = sm.dataManager.AuditManager.AuditSearch(cmd.Args[1], username, count)
+	default:
+		sm.logger.Error(ctx, "Invalid admin audit action", log.Fields{"action": cmd.Args[0]})
+		return nil, fmt.Errorf("invalid admin audit action: %s. Must be 'tail' or 'search'", cmd.Args[0])
+	}
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to read audit log", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	sm.logger.Info(ctx, "Audit entries retrieved", log.Fields{"count": len(entries)})
+	return formatAuditEntries(entries), nil
+}
+
+// handleAdminEvents handles the admin events command
+func handleAdminEvents(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling admin events command", nil)
+
+	stats := sm.dataManager.EventManager.Stats()
+	if len(stats) == 0 {
+		return "No events published", nil
+	}
+
+	lines := []string{fmt.Sprintf("%-16s %7s %5s %9s %9s %6s %7s %10s %7s", "EVENT", "DEPTH", "MAX", "PUBLISHED", "DELIVERED", "FAILED", "DROPPED", "DUPLICATES", "LAST ID")}
+	for _, s := range stats {
+		lines = append(lines, fmt.Sprintf("%-16s %3d/%-3d %5d %9d %9d %6d %7d %10d %7d", s.Type, s.Depth, s.Capacity, s.MaxDepth, s.Published, s.Delivered, s.Failed, s.Dropped, s.Duplicates, s.LastID))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// handleAdminTelemetry handles the admin telemetry command
+func handleAdminTelemetry(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling admin telemetry command", log.Fields{"args": cmd.Args})
+
+	action := "show"
+	if len(cmd.Args) > 0 {
+		action = strings.ToLower(cmd.Args[0])
+	}
+	if action != "export" && len(cmd.Args) > 1 {
+		sm.logger.Error(ctx, "Too many arguments for admin telemetry", log.Fields{"action": action, "argCount": len(cmd.Args)})
+		return nil, fmt.Errorf("admin telemetry %s does not accept further arguments", action)
+	}
+
+	telemetry := sm.dataManager.TelemetryManager
+	switch action {
+	case "show":
+		usage, err := telemetry.TelemetryUsage()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read command telemetry: %w", err)
+		}
+		return formatCommandUsage(usage, sm.dataManager.Config.Telemetry), nil
+	case "export":
+		filename := ""
+		if len(cmd.Args) > 1 {
+			filename = cmd.Args[1]
+		}
+		path, err := sm.dataManager.TelemetryPath(filename)
+		if err != nil {
+			return nil, err
+		}
+		count, err := telemetry.TelemetryExport(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export command telemetry: %w", err)
+		}
+		return fmt.Sprintf("Usage of %d commands exported to %s", count, path), nil
+	case "reset":
+		if err := telemetry.TelemetryReset(); err != nil {
+			return nil, fmt.Errorf("failed to reset command telemetry: %w", err)
+		}
+		return "Command telemetry reset", nil
+	default:
+		sm.logger.Error(ctx, "Invalid admin telemetry action", log.Fields{"action": action})
+		return nil, fmt.Errorf("invalid admin telemetry action: %s. Must be 'show', 'export' or 'reset'", action)
+	}
+}
+
+// parseAuditCount parses the optional entry count argument of the admin audit command
+func parseAuditCount(arg string) (int, error) {
+	count, err := strconv.Atoi(arg)
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("invalid count: %s", arg)
+	}
+	return count, nil
+}
+
+// formatAuditEntries formats audit entries for display, one entry per line
+func formatAuditEntries(entries []*model.AuditEntry) string {
+	if len(entries) == 0 {
+		return "No audit entries found"
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		line := fmt.Sprintf("%s  %-10s %-8s %s %s", e.Timestamp.Format("2006-01-02 15:04:05"), e.Username, e.Result, e.Scope, e.Operation)
+		if e.Target != "" {
+			line += " " + e.Target
+		}
+		if e.MindmapID != 0 {
+			line += fmt.Sprintf(" (mindmap %d)", e.MindmapID)
+		}
+		if e.Error != "" {
+			line += ": " + e.Error
+		}
+		if e.Detail != "" {
+			line += " => " + e.Detail
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatCommandUsage formats the command telemetry for display, one command per line, noting if recording is disabled
+func formatCommandUsage(usage []*model.CommandUsage, enabled bool) string {
+	var lines []string
+	if !enabled {
+		lines = append(lines, "Command telemetry is disabled, set telemetry to true in the configuration to record command usage")
+	}
+	if len(usage) == 0 {
+		return strings.Join(append(lines, "No command usage recorded"), "\n")
+	}
+
+	lines = append(lines, fmt.Sprintf("%-24s %7s %7s %6s", "COMMAND", "COUNT", "ERRORS", "RATE"))
+	for _, u := range usage {
+		lines = append(lines, fmt.Sprintf("%-24s %7d %7d %5.1f%%", u.Scope+" "+u.Operation, u.Count, u.Errors, u.ErrorRate()*100))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"context"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+const redactedArg = "<redacted>"
+
+// auditCommand records an executed command and its outcome in the audit log, with the changes it reports in its
+// result, such as the diff of a node update
+func (sm *SessionManager) auditCommand(session *model.Session, cmd model.Command, result interface{}, cmdErr error) {
+	// Reading the audit log is not itself audited, nor are keep-alive pings, and a read-only database is not written
+	if cmd.Scope == "admin" && cmd.Operation == "audit" || cmd.Scope == "mindmap" && cmd.Operation == "changes" || cmd.Scope == "system" && cmd.Operation == "ping" || sm.dataManager.Config.ReadOnly {
+		return
+	}
+
+	entry := model.AuditEntry{
+		SessionID: session.ID,
+		Scope:     cmd.Scope,
+		Operation: cmd.Operation,
+		Target:    strings.Join(redactCommandArgs(cmd), " "),
+		Result:    model.AuditResultSuccess,
+	}
+	if session.User != nil {
+		entry.Username = session.User.Username
+	}
+	if session.Mindmap != nil {
+		entry.MindmapID = session.Mindmap.ID
+	}
+	if cmdErr != nil {
+		entry.Result = model.AuditResultFailure
+		entry.Error = cmdErr.Error()
+	}
+	if change, ok := result.(model.NodeChange); ok {
+		entry.Detail = strings.Join(change.Lines(false), "; ")
+	}
+
+	if err := sm.dataManager.AuditManager.AuditRecord(entry); err != nil {
+		sm.logger.Error(context.Background(), "Failed to audit command", log.Fields{"error": err, "scope": cmd.Scope, "operation": cmd.Operation})
+	}
+}
+
+// redactCommandArgs returns a copy of the command arguments with passwords replaced
+func redactCommandArgs(cmd model.Command) []string {
+	args := make([]string, len(cmd.Args))
+	copy(args, cmd.Args)
+
+	if cmd.Scope == "user" {
+		switch cmd.Operation {
+		case "add":
+			if len(args) > 1 {
+				args[1] = redactedArg
+			}
+		case "select":
+			if len(args) > 1 {
+				args[1] = redactedArg
+			}
+		case "update":
+			if len(args) > 2 {
+				args[2] = redactedArg
+			}
+		case "capture":
+			if len(args) > 3 && args[0] == "set" {
+				args[3] = redactedArg
+			}
+		}
+	}
+	return args
+}
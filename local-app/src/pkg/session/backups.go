@@ -0,0 +1,144 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// startBackupRoutine starts a goroutine that periodically writes a snapshot of the database to the backup directory,
+// unless backups are disabled by a negative backup interval. Another snapshot is taken on a clean shutdown, see
+// StopBackupRoutine.
+func (sm *SessionManager) startBackupRoutine() {
+	ctx := context.Background()
+
+	// A read-only instance changes nothing worth another snapshot, and browses what is often a snapshot already
+	interval := sm.dataManager.Config.BackupInterval
+	if interval <= 0 || sm.dataManager.Config.ReadOnly {
+		sm.logger.Info(ctx, "Backups disabled", nil)
+		return
+	}
+	sm.logger.Info(ctx, "Starting backup routine", log.Fields{"interval": interval, "dir": sm.dataManager.Config.BackupDir})
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sm.backupCreate()
+			case <-sm.backupDone:
+				sm.logger.Info(ctx, "Stopped backup routine", nil)
+				return
+			}
+		}
+	}()
+}
+
+// StopBackupRoutine stops the backup routine and takes the snapshot of the shutdown, if backups are enabled
+func (sm *SessionManager) StopBackupRoutine() {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Stopping backup routine", nil)
+	close(sm.backupDone)
+
+	if sm.dataManager.Config.BackupInterval > 0 && !sm.dataManager.Config.ReadOnly {
+		sm.backupCreate()
+	}
+}
+
+// backupCreate writes a snapshot of the database on the command executor, in order with the commands changing it
+func (sm *SessionManager) backupCreate() {
+	_, err := sm.runTask(func() (interface{}, error) {
+		return sm.dataManager.BackupCreate()
+	})
+	if err != nil {
+		sm.logger.Error(context.Background(), "Failed to write database snapshot", log.Fields{"error": err})
+	}
+}
+
+// handleSystemBackup handles the system backup command, which writes a snapshot of the database to the backup
+// directory now or lists the snapshots in it
+func handleSystemBackup(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling system backup command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) > 1 {
+		sm.logger.Error(ctx, "Invalid number of arguments for system backup", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("system backup command requires 0 or 1 argument: [now|list]")
+	}
+
+	action := "list"
+	if len(cmd.Args) == 1 {
+		action = cmd.Args[0]
+	}
+	switch action {
+	case "now":
+		// Like the db command, the snapshot is safe to take alongside the executor and runs as a whole in the background
+		return sm.jobRun(session, cmd, func(run jobRunner) (interface{}, error) {
+			backup, err := sm.dataManager.BackupCreate()
+			if err != nil {
+				sm.logger.Error(ctx, "Failed to back up database", log.Fields{"error": err})
+				return nil, fmt.Errorf("failed to back up database: %w", err)
+			}
+			return fmt.Sprintf("Database snapshot %s written (%s)", backup.Name, formatSize(backup.Size)), nil
+		})
+	case "list":
+		backups, err := sm.dataManager.Backups()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups: %w", err)
+		}
+		if len(backups) == 0 {
+			return "No snapshots in " + sm.dataManager.Config.BackupDir, nil
+		}
+		lines := []string{fmt.Sprintf("Snapshots in %s, newest first:", sm.dataManager.Config.BackupDir)}
+		for _, backup := range backups {
+			lines = append(lines, fmt.Sprintf("  %s (%s, %s)", backup.Name, formatSize(backup.Size), backup.Created.Format(time.DateTime)))
+		}
+		return strings.Join(lines, "\n"), nil
+	default:
+		sm.logger.Error(ctx, "Invalid system backup action", log.Fields{"action": action})
+		return nil, fmt.Errorf("invalid system backup action: %s. Must be 'now' or 'list'", action)
+	}
+}
+
+// handleMindmapRestore handles the mindmap restore command, which replaces a mindmap of the user, by default the
+// selected one, with its copy in a snapshot of the backup directory
+func handleMindmapRestore(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap restore command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		sm.logger.Error(ctx, "Invalid number of arguments for mindmap restore", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("mindmap restore command requires 1 or 2 arguments: <snapshot|latest> [mindmap name]")
+	}
+
+	var name string
+	switch {
+	case len(cmd.Args) == 2:
+		name = cmd.Args[1]
+	case session.Mindmap != nil:
+		name = session.Mindmap.Name
+	default:
+		sm.logger.Error(ctx, "No mindmap to restore", nil)
+		return nil, errors.New("no mindmap selected, give the name of the mindmap to restore")
+	}
+
+	restored, warnings, err := sm.dataManager.MindmapRestore(session.User, cmd.Args[0], name)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to restore mindmap", log.Fields{"error": err, "snapshot": cmd.Args[0], "mindmapName": name})
+		return nil, fmt.Errorf("failed to restore mindmap: %w", err)
+	}
+	session.Mindmap = restored
+
+	sm.logger.Info(ctx, "Mindmap restored successfully", log.Fields{"mindmapID": restored.ID, "mindmapName": restored.Name, "warnings": len(warnings)})
+	result := fmt.Sprintf("Mindmap '%s' restored from snapshot %s", restored.Name, cmd.Args[0])
+	for _, warning := range warnings {
+		result += "\nWarning: " + warning
+	}
+	return result, nil
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// CalendarFeedPath is the path the web adapter serves calendar feeds under, followed by the token and .ics
+const CalendarFeedPath = "/calendar/"
+
+// CalendarFind returns the calendar feed of a token, nil if there is none, for the web adapter to serve
+func (sm *SessionManager) CalendarFind(token string) (*model.CalendarFeed, error) {
+	result, err := sm.runTask(func() (interface{}, error) {
+		return sm.dataManager.CalendarManager.CalendarFind(token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*model.CalendarFeed), nil
+}
+
+// CalendarEncode renders a calendar feed as an iCalendar of the current dated nodes of its mindmaps
+func (sm *SessionManager) CalendarEncode(feed *model.CalendarFeed) ([]byte, error) {
+	result, err := sm.runTask(func() (interface{}, error) {
+		name, reminders, err := sm.dataManager.CalendarReminders(feed)
+		if err != nil {
+			return nil, err
+		}
+		return storage.EncodeICS(name, reminders, sm.DisplayStyle()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
+// calendarURL returns the URL of a calendar feed at the configured web address, or its path if the web adapter is
+// off
+func (sm *SessionManager) calendarURL(feed *model.CalendarFeed) string {
+	path := CalendarFeedPath + feed.Token + ".ics"
+	address := sm.WebAddress()
+	if address == "" {
+		return path
+	}
+	if strings.HasPrefix(address, ":") {
+		address = "localhost" + address
+	}
+	return "http://" + address + path
+}
+
+// handleUserCalendar handles the user calendar command
+func handleUserCalendar(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling user calendar command", log.Fields{"args": cmd.Args})
+
+	if session.User == nil {
+		sm.logger.Warn(ctx, "No user selected for user calendar", nil)
+		return nil, errors.New("no user selected")
+	}
+	username := session.User.Username
+
+	// A feed covers one own mindmap, or all of them without a mindmap name
+	var mindmap *model.Mindmap
+	if cmd.Args[0] != "show" && len(cmd.Args) == 2 {
+		mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{Name: cmd.Args[1]}, model.MindmapFilter{Name: true})
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to get calendar mindmap", log.Fields{"error": err, "mindmapName": cmd.Args[1]})
+			return nil, fmt.Errorf("failed to get mindmap: %w", err)
+		}
+		if len(mindmaps) == 0 || mindmaps[0].Owner != username {
+			sm.logger.Warn(ctx, "Calendar mindmap not found", log.Fields{"mindmapName": cmd.Args[1]})
+			return nil, fmt.Errorf("mindmap not found: %s. Calendar feeds cover own mindmaps only", cmd.Args[1])
+		}
+		mindmap = mindmaps[0]
+	}
+	mindmapID, feedName := 0, "all own mindmaps"
+	if mindmap != nil {
+		mindmapID, feedName = mindmap.ID, mindmap.Name
+	}
+
+	switch cmd.Args[0] {
+	case "set":
+		feed, err := sm.dataManager.CalendarManager.CalendarSet(username, mindmapID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set calendar feed: %w", err)
+		}
+		sm.logger.Info(ctx, "Calendar feed set", log.Fields{"username": username, "mindmapID": mindmapID})
+		result := fmt.Sprintf("Calendar feed of %s: %s\nAnyone with the URL can read the feed, set it again for a new URL", feedName, sm.calendarURL(feed))
+		if sm.WebAddress() == "" {
+			result += "\nThe web adapter serving calendar feeds is off in the configuration"
+		}
+		return result, nil
+
+	case "show":
+		if len(cmd.Args) != 1 {
+			return nil, errors.New("user calendar show does not accept any arguments")
+		}
+		feeds, err := sm.dataManager.CalendarManager.CalendarGet(username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get calendar feeds: %w", err)
+		}
+		if len(feeds) == 0 {
+			return "No calendar feeds set", nil
+		}
+		var b strings.Builder
+		for i, feed := range feeds {
+			name := "All own mindmaps"
+			if feed.MindmapID != 0 {
+				name = fmt.Sprintf("mindmap ID %d", feed.MindmapID)
+				if mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{ID: feed.MindmapID}, model.MindmapFilter{ID: true}); err == nil && len(mindmaps) > 0 {
+					name = mindmaps[0].Name
+				}
+			}
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "%s: %s", name, sm.calendarURL(feed))
+		}
+		return b.String(), nil
+
+	case "clear":
+		deleted, err := sm.dataManager.CalendarManager.CalendarDelete(username, mindmapID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clear calendar feed: %w", err)
+		}
+		if !deleted {
+			return nil, fmt.Errorf("no calendar feed of %s", feedName)
+		}
+		sm.logger.Info(ctx, "Calendar feed cleared", log.Fields{"username": username, "mindmapID": mindmapID})
+		return fmt.Sprintf("Calendar feed of %s cleared", feedName), nil
+
+	default:
+		sm.logger.Error(ctx, "Invalid user calendar operation", log.Fields{"operation": cmd.Args[0]})
+		return nil, fmt.Errorf("invalid user calendar operation: %s. Must be 'set', 'show' or 'clear'", cmd.Args[0])
+	}
+}
@@ -0,0 +1,251 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/capture"
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+const (
+	captureTimeout        = 30 * time.Second // Time allowed for each exchange with an IMAP server
+	maxCapturePerPoll     = 50               // Emails captured per account and poll, more follow on the next poll
+	defaultCaptureMailbox = "INBOX"
+)
+
+// startCaptureRoutine starts a goroutine that periodically captures new emails of the capture accounts of all
+// users, unless email capture is disabled by a negative capture interval. Mailboxes are read outside the
+// command executor, the captured nodes are added on it in order with the commands of the sessions.
+func (sm *SessionManager) startCaptureRoutine() {
+	ctx := context.Background()
+
+	interval := sm.dataManager.Config.CaptureInterval
+	if interval <= 0 || sm.dataManager.Config.ReadOnly {
+		sm.logger.Info(ctx, "Email capture disabled", nil)
+		return
+	}
+	sm.logger.Info(ctx, "Starting capture routine", log.Fields{"interval": interval})
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sm.captureAll()
+			case <-sm.captureDone:
+				sm.logger.Info(ctx, "Stopped capture routine", nil)
+				return
+			}
+		}
+	}()
+}
+
+// StopCaptureRoutine stops the capture routine
+func (sm *SessionManager) StopCaptureRoutine() {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Stopping capture routine", nil)
+	close(sm.captureDone)
+}
+
+// captureAll polls the capture accounts of all users
+func (sm *SessionManager) captureAll() {
+	ctx := context.Background()
+
+	result, err := sm.runTask(func() (interface{}, error) {
+		return sm.dataManager.CaptureManager.CaptureGet("")
+	})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get capture accounts", log.Fields{"error": err})
+		return
+	}
+
+	for _, account := range result.([]*model.CaptureAccount) {
+		select {
+		case <-sm.captureDone:
+			return
+		default:
+		}
+		sm.captureAccount(account)
+	}
+}
+
+// captureAccount captures the new emails of a capture account as nodes, recording a failure in the account
+func (sm *SessionManager) captureAccount(account *model.CaptureAccount) {
+	ctx := context.Background()
+	sm.logger.Debug(ctx, "Polling capture account", log.Fields{"username": account.Username, "server": account.Server})
+
+	captured := 0
+	err := capture.Poll(account, captureTimeout, maxCapturePerPoll, func(validity uint32, items []model.CaptureItem) error {
+		_, err := sm.runTask(func() (interface{}, error) {
+			mindmap, err := sm.captureMindmap(account)
+			if err != nil {
+				return nil, err
+			}
+			ids, err := sm.dataManager.CaptureAdd(account, validity, mindmap, items)
+			captured = len(ids)
+			return ids, err
+		})
+		return err
+	})
+	if err != nil {
+		sm.runTask(func() (interface{}, error) {
+			return nil, sm.dataManager.CaptureManager.CaptureFailed(account, err)
+		})
+		return
+	}
+
+	if captured > 0 {
+		sm.logger.Info(ctx, "Emails captured", log.Fields{"username": account.Username, "count": captured})
+	}
+}
+
+// captureMindmap returns the loaded target mindmap of a capture account, the one selected in a session if any so
+// the captured nodes show up there at once. It must run on the command executor.
+func (sm *SessionManager) captureMindmap(account *model.CaptureAccount) (*model.Mindmap, error) {
+	if mindmap := sm.sessionMindmap(account.MindmapID); mindmap != nil {
+		return mindmap, nil
+	}
+
+	users, err := sm.dataManager.UserManager.UserGet(model.UserInfo{Username: account.Username}, model.UserFilter{Username: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("user not found: %s", account.Username)
+	}
+	mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(users[0], model.MindmapInfo{ID: account.MindmapID}, model.MindmapFilter{ID: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(mindmaps) == 0 {
+		return nil, fmt.Errorf("mindmap not found: ID %d", account.MindmapID)
+	}
+
+	mindmap := mindmaps[0]
+	if err := sm.dataManager.EventManager.PublishAndWait(event.Event{Type: event.MindmapSelected, Data: mindmap}); err != nil {
+		return nil, fmt.Errorf("failed to load mindmap: %w", err)
+	}
+	return mindmap, nil
+}
+
+// sessionMindmap returns the mindmap of the given ID if a session has it selected, so that nodes added to it show
+// up there at once, or nil. Mindmaps loaded only a few levels deep are left out, they get the nodes on their next
+// full load.
+func (sm *SessionManager) sessionMindmap(id int) *model.Mindmap {
+	sm.sessionMutex.RLock()
+	defer sm.sessionMutex.RUnlock()
+	for _, session := range sm.sessions {
+		if session.Mindmap != nil && session.Mindmap.ID == id && !session.Mindmap.Partial() {
+			return session.Mindmap
+		}
+	}
+	return nil
+}
+
+// handleUserCapture handles the user capture command, which sets, shows or clears the email capture account of
+// the current user
+func handleUserCapture(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling user capture command", log.Fields{"args": redactCommandArgs(cmd)})
+
+	if session.User == nil {
+		sm.logger.Warn(ctx, "No user selected for user capture", nil)
+		return nil, errors.New("no user selected")
+	}
+	username := session.User.Username
+
+	switch cmd.Args[0] {
+	case "set":
+		if len(cmd.Args) < 5 || len(cmd.Args) > 6 {
+			sm.logger.Error(ctx, "Invalid number of arguments for user capture set", log.Fields{"argCount": len(cmd.Args)})
+			return nil, errors.New("user capture set requires 4 or 5 arguments: <server> <login> <password> <mindmap> [mailbox]")
+		}
+		account := model.CaptureAccount{
+			Username: username,
+			Server:   cmd.Args[1],
+			Login:    cmd.Args[2],
+			Password: cmd.Args[3],
+			Mailbox:  defaultCaptureMailbox,
+		}
+		if len(cmd.Args) == 6 {
+			account.Mailbox = cmd.Args[5]
+		}
+
+		mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{Name: cmd.Args[4]}, model.MindmapFilter{Name: true})
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to get capture mindmap", log.Fields{"error": err, "mindmapName": cmd.Args[4]})
+			return nil, fmt.Errorf("failed to get mindmap: %w", err)
+		}
+		if len(mindmaps) == 0 || mindmaps[0].Owner != username {
+			sm.logger.Warn(ctx, "Capture mindmap not found", log.Fields{"mindmapName": cmd.Args[4]})
+			return nil, fmt.Errorf("mindmap not found: %s. Emails can only be captured into own mindmaps", cmd.Args[4])
+		}
+		account.MindmapID = mindmaps[0].ID
+
+		if err := sm.dataManager.CaptureManager.CaptureSet(account); err != nil {
+			return nil, fmt.Errorf("failed to set capture account: %w", err)
+		}
+		sm.logger.Info(ctx, "Capture account set", log.Fields{"username": username, "server": account.Server, "mindmapID": account.MindmapID})
+		result := fmt.Sprintf("Capturing emails of %s at %s (%s) into %s", account.Login, account.Server, account.Mailbox, mindmaps[0].Name)
+		if sm.dataManager.Config.CaptureInterval <= 0 {
+			result += "\nEmail capture is disabled in the configuration"
+		}
+		return result, nil
+
+	case "show":
+		accounts, err := sm.dataManager.CaptureManager.CaptureGet(username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get capture account: %w", err)
+		}
+		if len(accounts) == 0 {
+			return "No email capture account set", nil
+		}
+		return sm.formatCaptureAccount(session.User, accounts[0]), nil
+
+	case "clear":
+		if len(cmd.Args) != 1 {
+			return nil, errors.New("user capture clear does not accept any arguments")
+		}
+		if err := sm.dataManager.CaptureManager.CaptureDelete(username); err != nil {
+			return nil, fmt.Errorf("failed to clear capture account: %w", err)
+		}
+		sm.logger.Info(ctx, "Capture account cleared", log.Fields{"username": username})
+		return "Email capture account cleared", nil
+
+	default:
+		sm.logger.Error(ctx, "Invalid user capture operation", log.Fields{"operation": cmd.Args[0]})
+		return nil, fmt.Errorf("invalid user capture operation: %s. Must be 'set', 'show' or 'clear'", cmd.Args[0])
+	}
+}
+
+// formatCaptureAccount describes a capture account and the outcome of its last poll
+func (sm *SessionManager) formatCaptureAccount(user *model.User, account *model.CaptureAccount) string {
+	mindmapName := fmt.Sprintf("mindmap ID %d", account.MindmapID)
+	if mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(user, model.MindmapInfo{ID: account.MindmapID}, model.MindmapFilter{ID: true}); err == nil && len(mindmaps) > 0 {
+		mindmapName = mindmaps[0].Name
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Capturing emails of %s at %s (%s) into %s\n", account.Login, account.Server, account.Mailbox, mindmapName)
+	if interval := sm.dataManager.Config.CaptureInterval; interval > 0 {
+		fmt.Fprintf(&b, "Polled every %d seconds, %d emails captured", interval, account.Captured)
+	} else {
+		fmt.Fprintf(&b, "Email capture is disabled in the configuration, %d emails captured", account.Captured)
+	}
+	if account.LastPoll.IsZero() {
+		b.WriteString(", not polled yet")
+	} else {
+		fmt.Fprintf(&b, ", last polled %s", account.LastPoll.Format("2006-01-02 15:04:05"))
+	}
+	if account.LastError != "" {
+		fmt.Fprintf(&b, "\nLast poll failed: %s", account.LastError)
+	}
+	return b.String()
+}
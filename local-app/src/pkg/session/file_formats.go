@@ -0,0 +1,94 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/data"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// Placeholders of the help texts replaced by the registered formats when the help is shown, so that formats added
+// by embedding applications are listed along with the built-in ones. The choices replace the placeholder within a
+// text, the lists replace an argument of their own with a line per format.
+const (
+	helpExportFormats    = "{export_formats}"
+	helpImportFormats    = "{import_formats}"
+	helpExportFormatList = "{export_format_list}"
+	helpImportFormatList = "{import_format_list}"
+)
+
+// exportFormatNames returns the names of the registered export formats matching match, all if match is nil
+func exportFormatNames(match func(model.ExportFormat) bool) []string {
+	var names []string
+	for _, format := range data.ExportFormats() {
+		if match == nil || match(format) {
+			names = append(names, format.Name)
+		}
+	}
+	return names
+}
+
+// importFormatNames returns the names of the registered import formats
+func importFormatNames() []string {
+	var names []string
+	for _, format := range data.ImportFormats() {
+		names = append(names, format.Name)
+	}
+	return names
+}
+
+// formatChoice joins format names as alternatives of a command syntax, such as json|xml
+func formatChoice(names []string) string {
+	return strings.Join(names, "|")
+}
+
+// formatList joins format names as quoted alternatives of a message, such as 'json', 'xml' or 'docx'
+func formatList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + name + "'"
+	}
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+// helpWithFormats returns a command help with its format placeholders replaced by the registered formats
+func helpWithFormats(help CommandHelp) CommandHelp {
+	replacer := strings.NewReplacer(
+		helpExportFormats, formatChoice(exportFormatNames(nil)),
+		helpImportFormats, formatChoice(importFormatNames()),
+	)
+	help.Syntax = replacer.Replace(help.Syntax)
+
+	var arguments []string
+	for _, argument := range help.Arguments {
+		switch argument {
+		case helpExportFormatList:
+			for _, format := range data.ExportFormats() {
+				line := fmt.Sprintf("  %s: %s", format.Name, format.Description)
+				if format.Subtree {
+					line += ", exports subtrees"
+				}
+				if format.Binary {
+					line += ", not to the clipboard"
+				}
+				arguments = append(arguments, line)
+			}
+		case helpImportFormatList:
+			for _, format := range data.ImportFormats() {
+				line := fmt.Sprintf("  %s: %s", format.Name, format.Description)
+				if format.Native {
+					line += ", merged into existing mindmaps by node ID"
+				}
+				arguments = append(arguments, line)
+			}
+		default:
+			arguments = append(arguments, replacer.Replace(argument))
+		}
+	}
+	help.Arguments = arguments
+	return help
+}
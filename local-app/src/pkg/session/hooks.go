@@ -0,0 +1,58 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/hooks"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// hookMiddleware runs the hooks configured for the command: the pre hooks before it, refusing the command if one
+// fails, and the post hooks after it with its outcome. Replayed commands run no hooks.
+func (sm *SessionManager) hookMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		configured := sm.dataManager.Config.CommandHooks
+		if len(configured) == 0 || sm.replaying {
+			return next(sm, session, cmd)
+		}
+		ctx := context.Background()
+
+		for _, hook := range hooks.Matching(configured, hooks.Pre, cmd.Scope, cmd.Operation) {
+			if err := hooks.Run(ctx, hook, hookPayload(hooks.Pre, session, cmd)); err != nil {
+				sm.logger.Warn(ctx, "Command refused by pre hook", log.Fields{"error": err, "scope": cmd.Scope, "operation": cmd.Operation, "program": hook.Program})
+				return nil, fmt.Errorf("%s %s refused: %w", cmd.Scope, cmd.Operation, err)
+			}
+		}
+
+		result, err := next(sm, session, cmd)
+
+		for _, hook := range hooks.Matching(configured, hooks.Post, cmd.Scope, cmd.Operation) {
+			payload := hookPayload(hooks.Post, session, cmd)
+			success := err == nil
+			payload.Success = &success
+			if err != nil {
+				payload.Error = err.Error()
+			} else if result != nil {
+				payload.Result = fmt.Sprint(result)
+			}
+			if hookErr := hooks.Run(ctx, hook, payload); hookErr != nil {
+				sm.logger.Error(ctx, "Post hook failed", log.Fields{"error": hookErr, "scope": cmd.Scope, "operation": cmd.Operation, "program": hook.Program})
+			}
+		}
+		return result, err
+	}
+}
+
+// hookPayload describes a command of a session to its hooks
+func hookPayload(when string, session *model.Session, cmd model.Command) hooks.Payload {
+	payload := hooks.Payload{When: when, Scope: cmd.Scope, Operation: cmd.Operation, Args: redactCommandArgs(cmd)}
+	if session.User != nil {
+		payload.User = session.User.Username
+	}
+	if session.Mindmap != nil {
+		payload.Mindmap = session.Mindmap.Name
+	}
+	return payload
+}
@@ -0,0 +1,194 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+// handleAdd handles the add command, which takes no operation: the text is added as a node under the inbox of
+// the default mindmap of the user, or of the selected mindmap if the user has no default mindmap, without
+// selecting it. Leading --mindmap and --parent options add under the top-level Inbox node of another own mindmap,
+// or under a node of the mindmap instead.
+func handleAdd(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling add command", log.Fields{"args": cmd.Args})
+
+	args := cmd.Args
+	var mindmapName, parentIndex string
+	for len(args) > 1 && (args[0] == "--mindmap" || args[0] == "--parent") {
+		if args[0] == "--mindmap" {
+			mindmapName = args[1]
+		} else {
+			parentIndex = args[1]
+		}
+		args = args[2:]
+	}
+	if len(args) == 0 || args[0] == "--mindmap" || args[0] == "--parent" {
+		sm.logger.Error(ctx, "Insufficient arguments for add", log.Fields{"args": cmd.Args})
+		return nil, errors.New("add command requires the text to add: add [--mindmap <mindmap>] [--parent <node>] <text>")
+	}
+
+	mindmap, inboxID, err := inboxMindmap(sm, session, mindmapName)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.Join(args, " ")
+	info := model.NodeInfo{MindmapID: mindmap.ID, Name: text}
+	var node *model.Node
+	if parentIndex == "" {
+		node, err = sm.dataManager.InboxAdd(mindmap, inboxID, info)
+	} else {
+		node, err = addUnder(sm, mindmap, parentIndex, info)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add to inbox: %w", err)
+	}
+
+	sm.logger.Info(ctx, "Node added to inbox", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID})
+	result := fmt.Sprintf("Added at node %s of %s, under %s", sm.nodeIndex(node), mindmap.Name, mindmap.Nodes[node.ParentID].Name)
+	if note := updateWikiLinks(sm, session, mindmap, node); note != "" {
+		result += "\n" + note
+	}
+	return result, nil
+}
+
+// addUnder adds a node under the node of the given index of a mindmap the add command adds to
+func addUnder(sm *SessionManager, mindmap *model.Mindmap, parentIndex string, info model.NodeInfo) (*model.Node, error) {
+	parent, err := getNode(sm, mindmap, parentIndex, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var node *model.Node
+	err = sm.dataManager.NodeBatch(mindmap, func() error {
+		info.ParentID = parent.ID
+		id, _, err := sm.dataManager.NodeManager.NodeAdd(mindmap, info)
+		if err != nil {
+			return fmt.Errorf("failed to add node %s: %w", info.Name, err)
+		}
+		node = mindmap.Nodes[id]
+		return nil
+	})
+	return node, err
+}
+
+// inboxMindmap returns the loaded mindmap the add command adds to and the ID of its inbox node, 0 for the
+// top-level Inbox node: the own mindmap of the given name, or else the default mindmap. The mindmap is the one
+// loaded by a session if any, so its loaded nodes are not replaced.
+func inboxMindmap(sm *SessionManager, session *model.Session, name string) (*model.Mindmap, int, error) {
+	ctx := context.Background()
+	user := session.User
+
+	if name == "" && user.DefaultMindmap == "" {
+		if session.Mindmap == nil {
+			sm.logger.Warn(ctx, "No mindmap to add to", nil)
+			return nil, 0, errors.New("no default mindmap to add to, set one with 'user default <mindmap>' or select a mindmap")
+		}
+		if session.Mindmap.Owner != user.Username {
+			sm.logger.Warn(ctx, "Selected mindmap not owned", log.Fields{"mindmapID": session.Mindmap.ID})
+			return nil, 0, fmt.Errorf("mindmap %s is not yours, only own mindmaps can be added to", session.Mindmap.Name)
+		}
+		return session.Mindmap, 0, nil
+	}
+
+	// The inbox node set with user inbox is a node of the default mindmap
+	inboxID := 0
+	if name == "" || names.Equal(name, user.DefaultMindmap, false) {
+		name, inboxID = user.DefaultMindmap, user.Inbox
+	}
+
+	if session.Mindmap != nil && session.Mindmap.Owner == user.Username && names.Equal(session.Mindmap.Name, name, false) {
+		return session.Mindmap, inboxID, nil
+	}
+
+	mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(user, model.MindmapInfo{Name: name, Owner: user.Username}, model.MindmapFilter{Name: true, Owner: true})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get mindmap to add to", log.Fields{"error": err, "mindmapName": name})
+		return nil, 0, fmt.Errorf("failed to get mindmap: %w", err)
+	}
+	if len(mindmaps) == 0 {
+		sm.logger.Warn(ctx, "Mindmap to add to not found", log.Fields{"mindmapName": name})
+		return nil, 0, fmt.Errorf("mindmap not found: %s. Only own mindmaps can be added to", name)
+	}
+
+	mindmap := mindmaps[0]
+	if loaded := sm.sessionMindmap(mindmap.ID); loaded != nil {
+		return loaded, inboxID, nil
+	}
+	if err := sm.dataManager.EventManager.PublishAndWait(event.Event{Type: event.MindmapSelected, Data: mindmap}); err != nil {
+		sm.logger.Error(ctx, "Failed to load mindmap to add to", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, 0, fmt.Errorf("failed to load mindmap: %w", err)
+	}
+	return mindmap, inboxID, nil
+}
+
+// handleUserInbox handles the user inbox command, which sets the inbox node the add command adds under to a node
+// of the selected mindmap, making it the default mindmap, or resets it to the top-level Inbox node
+func handleUserInbox(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling user inbox command", log.Fields{"args": cmd.Args})
+
+	var identifier string
+	useID := false
+	for _, arg := range cmd.Args {
+		if arg == "--id" {
+			useID = true
+		} else if identifier == "" {
+			identifier = arg
+		} else {
+			sm.logger.Error(ctx, "Invalid arguments for user inbox", log.Fields{"args": cmd.Args})
+			return nil, errors.New("user inbox command accepts at most 2 arguments: [node] [--id]")
+		}
+	}
+
+	user := session.User
+	if identifier == "" {
+		if err := sm.dataManager.UserManager.UserUpdate(user, model.UserInfo{Inbox: 0}, model.UserFilter{Inbox: true}); err != nil {
+			sm.logger.Error(ctx, "Failed to reset inbox", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to reset inbox: %w", err)
+		}
+		user.Inbox = 0
+		return "Inbox reset to the top-level Inbox node", nil
+	}
+
+	mindmap := session.Mindmap
+	if mindmap == nil {
+		sm.logger.Warn(ctx, "No mindmap selected for user inbox", nil)
+		return nil, errors.New("no mindmap selected, the inbox is a node of the selected mindmap")
+	}
+	if mindmap.Owner != user.Username {
+		sm.logger.Warn(ctx, "Inbox mindmap not owned", log.Fields{"mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("mindmap %s is not yours, only own mindmaps can be added to", mindmap.Name)
+	}
+	node, err := getNode(sm, mindmap, identifier, useID)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get inbox node", log.Fields{"error": err, "nodeIdentifier": identifier})
+		return nil, fmt.Errorf("failed to get inbox node: %w", err)
+	}
+	if node.ID == 0 {
+		return nil, errors.New("the root can't be the inbox, use a node under it")
+	}
+
+	update := model.UserInfo{DefaultMindmap: mindmap.Name, Inbox: node.ID}
+	if err := sm.dataManager.UserManager.UserUpdate(user, update, model.UserFilter{DefaultMindmap: true, Inbox: true}); err != nil {
+		sm.logger.Error(ctx, "Failed to set inbox", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to set inbox: %w", err)
+	}
+	changed := !names.Equal(user.DefaultMindmap, mindmap.Name, false)
+	user.DefaultMindmap, user.Inbox = mindmap.Name, node.ID
+
+	sm.logger.Info(ctx, "Inbox set", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID})
+	result := fmt.Sprintf("Inbox set to node %s (%s) of %s", sm.nodeIndex(node), node.Name, mindmap.Name)
+	if changed {
+		result += ", now the default mindmap"
+	}
+	return result, nil
+}
@@ -0,0 +1,223 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// jobHistory is the number of finished jobs kept per session for system jobs
+const jobHistory = 20
+
+// jobList holds the background jobs of the sessions by session ID. It is used by the job goroutines and the
+// adapters as well as the executor, so it has a lock of its own.
+type jobList struct {
+	mu     sync.Mutex
+	lastID int
+	jobs   map[string][]*job
+}
+
+// job is a background job, its state guarded by the lock of its list
+type job struct {
+	model.Job
+	sessionID string
+	reported  bool          // The result was handed to the adapter of the session
+	done      chan struct{} // Closed once the job finished
+}
+
+// jobStarted is the result of a command started as a background job. The audit log and the journal skip it, they
+// record the command once the job finished.
+type jobStarted struct {
+	ID      int
+	Command model.Command
+}
+
+func (s jobStarted) String() string {
+	return fmt.Sprintf("Job %d started: %s, its result follows when it is done", s.ID, jobCommand(s.Command))
+}
+
+// jobRunner runs the work of a command, in the background if the session runs jobs
+type jobRunner struct {
+	sm         *SessionManager
+	background bool
+	progress   model.ProgressFunc
+}
+
+// exclusive runs a part of the work using the loaded mindmaps, the sessions or storage, which only the command
+// executor may use: directly if the work already runs on it, as a task of the executor otherwise
+func (r jobRunner) exclusive(task func() (interface{}, error)) (interface{}, error) {
+	if !r.background {
+		return task()
+	}
+	return r.sm.runTask(task)
+}
+
+// jobBackground reports whether the heavy commands of a session run as background jobs. During a replay the
+// journaled commands run one after the other as they did.
+func (sm *SessionManager) jobBackground(session *model.Session) bool {
+	return session.Background && !sm.replaying
+}
+
+// jobRun runs the work of a heavy command. Unless the session runs jobs, the work runs at once and its result is
+// returned. Otherwise the work runs on a goroutine of its own and jobStarted is returned, the command executor
+// taking the other commands meanwhile. The work only uses the loaded mindmaps, the sessions and storage through
+// jobRunner.exclusive, as a statement run alongside the executor could join the transaction of a command, see
+// storage.BaseDatabase.Batch. The database maintenance of system db and system backup is the exception, it does not
+// go through transactions. Once it is done the command is audited and journaled, and the result is kept for the adapter.
+func (sm *SessionManager) jobRun(session *model.Session, cmd model.Command, work func(run jobRunner) (interface{}, error)) (interface{}, error) {
+	if !sm.jobBackground(session) {
+		return work(jobRunner{sm: sm, progress: session.Progress})
+	}
+
+	j := sm.jobs.add(session.ID, cmd)
+	sm.logger.Info(context.Background(), "Job started", log.Fields{"sessionID": session.ID, "jobID": j.ID, "command": cmd})
+
+	go func() {
+		result, err := work(jobRunner{sm: sm, background: true, progress: func(p model.Progress) {
+			sm.jobs.mu.Lock()
+			j.Progress = p
+			sm.jobs.mu.Unlock()
+		}})
+
+		// Recorded on the executor, as the commands are
+		sm.runTask(func() (interface{}, error) {
+			sm.auditCommand(session, cmd, result, err)
+			if err == nil {
+				sm.journalCommand(session, cmd)
+			}
+			sm.transcriptJob(session, j.ID, cmd, result, err)
+			return nil, nil
+		})
+
+		sm.jobs.finish(j, result, err)
+		if err != nil {
+			sm.logger.Error(context.Background(), "Job failed", log.Fields{"sessionID": session.ID, "jobID": j.ID, "error": err})
+		} else {
+			sm.logger.Info(context.Background(), "Job finished", log.Fields{"sessionID": session.ID, "jobID": j.ID})
+		}
+	}()
+
+	return jobStarted{ID: j.ID, Command: cmd}, nil
+}
+
+// Jobs returns the jobs of a session, running and recently finished, oldest first
+func (sm *SessionManager) Jobs(sessionID string) []model.Job {
+	sm.jobs.mu.Lock()
+	defer sm.jobs.mu.Unlock()
+
+	jobs := make([]model.Job, 0, len(sm.jobs.jobs[sessionID]))
+	for _, j := range sm.jobs.jobs[sessionID] {
+		jobs = append(jobs, j.Job)
+	}
+	return jobs
+}
+
+// JobsFinished returns the jobs of a session that finished since the last call, for the adapter to show their results
+func (sm *SessionManager) JobsFinished(sessionID string) []model.Job {
+	sm.jobs.mu.Lock()
+	defer sm.jobs.mu.Unlock()
+
+	var finished []model.Job
+	for _, j := range sm.jobs.jobs[sessionID] {
+		if !j.Running() && !j.reported {
+			j.reported = true
+			finished = append(finished, j.Job)
+		}
+	}
+	return finished
+}
+
+// JobsWait waits for the running jobs of a session to finish, such as before the adapter closes
+func (sm *SessionManager) JobsWait(sessionID string) {
+	sm.jobs.mu.Lock()
+	var running []chan struct{}
+	for _, j := range sm.jobs.jobs[sessionID] {
+		if j.Running() {
+			running = append(running, j.done)
+		}
+	}
+	sm.jobs.mu.Unlock()
+
+	for _, done := range running {
+		<-done
+	}
+}
+
+// add adds a running job for a command of a session
+func (l *jobList) add(sessionID string, cmd model.Command) *job {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.jobs == nil {
+		l.jobs = make(map[string][]*job)
+	}
+	l.lastID++
+	j := &job{Job: model.Job{ID: l.lastID, Command: cmd, Started: time.Now()}, sessionID: sessionID, done: make(chan struct{})}
+	l.jobs[sessionID] = append(l.jobs[sessionID], j)
+	return j
+}
+
+// finish records the outcome of a job, dropping the oldest finished jobs of its session beyond jobHistory
+func (l *jobList) finish(j *job, result interface{}, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	j.Finished, j.Result, j.Err = time.Now(), result, err
+	close(j.done)
+
+	jobs := l.jobs[j.sessionID]
+	finished := 0
+	for _, other := range jobs {
+		if !other.Running() {
+			finished++
+		}
+	}
+	kept := jobs[:0]
+	for _, other := range jobs {
+		if other.Running() || finished <= jobHistory {
+			kept = append(kept, other)
+		} else {
+			finished--
+		}
+	}
+	if _, exists := l.jobs[j.sessionID]; exists {
+		l.jobs[j.sessionID] = kept
+	}
+}
+
+// drop removes the jobs of a session that was deleted, running jobs still finish
+func (l *jobList) drop(sessionID string) {
+	l.mu.Lock()
+	delete(l.jobs, sessionID)
+	l.mu.Unlock()
+}
+
+// jobCommand formats the command of a job as entered, with the passwords redacted
+func jobCommand(cmd model.Command) string {
+	return strings.Join(append([]string{cmd.Scope, cmd.Operation}, redactCommandArgs(cmd)...), " ")
+}
+
+// FormatJob formats a job for display: its command and whether it runs, with its progress, or finished, with its
+// result or error
+func FormatJob(j model.Job) string {
+	header := fmt.Sprintf("Job %d (%s)", j.ID, jobCommand(j.Command))
+	switch {
+	case j.Running():
+		status := "running for " + time.Since(j.Started).Round(time.Second).String()
+		if j.Progress.Total > 0 {
+			status += fmt.Sprintf(", %d/%d nodes", j.Progress.Done, j.Progress.Total)
+		}
+		return header + " " + status
+	case j.Err != nil:
+		return fmt.Sprintf("%s failed: %v", header, j.Err)
+	case j.Result == nil:
+		return fmt.Sprintf("%s done in %s", header, j.Finished.Sub(j.Started).Round(time.Second))
+	default:
+		return fmt.Sprintf("%s done in %s:\n%v", header, j.Finished.Sub(j.Started).Round(time.Second), j.Result)
+	}
+}
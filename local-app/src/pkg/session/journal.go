@@ -0,0 +1,198 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// journalCommand appends a successfully executed command to the replayable command journal.
+// Only mutating commands and the selections they depend on are recorded, with passwords redacted.
+func (sm *SessionManager) journalCommand(session *model.Session, cmd model.Command) {
+	ctx := context.Background()
+
+	// Nothing changes in read-only mode, so nothing needs replaying
+	if !isMutatingCommand(cmd) && cmd.Operation != "select" || sm.dataManager.Config.ReadOnly {
+		return
+	}
+	// Previews do not change any data
+	if slices.Contains(cmd.Args, "--preview") {
+		return
+	}
+
+	entry := model.JournalEntry{
+		Timestamp: time.Now(),
+		Scope:     cmd.Scope,
+		Operation: cmd.Operation,
+		Args:      redactCommandArgs(cmd),
+	}
+	if session.User != nil {
+		entry.Username = session.User.Username
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to marshal journal entry", log.Fields{"error": err})
+		return
+	}
+
+	cfg := sm.dataManager.Config
+	journalPath := filepath.Join(cfg.LogFolder, cfg.JournalLog)
+	file, err := os.OpenFile(journalPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to open journal file", log.Fields{"error": err, "path": journalPath})
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		sm.logger.Error(ctx, "Failed to write journal entry", log.Fields{"error": err, "path": journalPath})
+	}
+}
+
+// handleSystemReplay handles the system replay command
+func handleSystemReplay(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling system replay command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		sm.logger.Error(ctx, "Invalid number of arguments for system replay", log.Fields{"argCount": len(cmd.Args)})
+		return nil, fmt.Errorf("system replay command requires 1 or 2 arguments: <file> [range]")
+	}
+
+	path, err := sm.dataManager.JournalPath(cmd.Args[0])
+	if err != nil {
+		sm.logger.Error(ctx, "Invalid journal file", log.Fields{"error": err, "file": cmd.Args[0]})
+		return nil, err
+	}
+	entries, err := readJournal(path)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to read journal", log.Fields{"error": err, "file": cmd.Args[0]})
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	first, last := 1, len(entries)
+	if len(cmd.Args) == 2 {
+		first, last, err = parseJournalRange(cmd.Args[1], len(entries))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Replayed commands are part of this command, so they are not journaled again or rate limited
+	sm.replaying = true
+	defer func() { sm.replaying = false }()
+
+	replayed := 0
+	var failures []string
+	for i := first; i <= last; i++ {
+		entry := entries[i-1]
+		replayCmd := model.Command{
+			Scope:     entry.Scope,
+			Operation: entry.Operation,
+			Args:      unredactArgs(entry.Args),
+		}
+
+		if err := sm.replayCommand(session, replayCmd); err != nil {
+			sm.logger.Warn(ctx, "Failed to replay journal entry", log.Fields{"entry": i, "error": err})
+			failures = append(failures, fmt.Sprintf("  #%d %s %s %s: %v", i, entry.Scope, entry.Operation, strings.Join(entry.Args, " "), err))
+			continue
+		}
+		replayed++
+	}
+
+	sm.logger.Info(ctx, "Journal replayed", log.Fields{"replayed": replayed, "failed": len(failures)})
+	summary := fmt.Sprintf("Replayed %d of %d journal entries", replayed, last-first+1)
+	if len(failures) > 0 {
+		summary += fmt.Sprintf(", %d failed:\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+	return summary, nil
+}
+
+// replayCommand runs a journaled command directly in the given session.
+// It runs inside the command executor, so it must not go through the command queue.
+func (sm *SessionManager) replayCommand(session *model.Session, cmd model.Command) error {
+	if !isMutatingCommand(cmd) && cmd.Operation != "select" {
+		return fmt.Errorf("command is not replayable")
+	}
+	_, err := sm.commandRun(session, cmd)
+	return err
+}
+
+// readJournal reads all entries of a journal file
+func readJournal(filename string) ([]model.JournalEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []model.JournalEntry
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry model.JournalEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("invalid journal entry on line %d: %w", lineNumber, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// parseJournalRange parses a 1-based inclusive entry range in the form "N", "N-M", "N-" or "-M"
+func parseJournalRange(spec string, count int) (int, int, error) {
+	if spec == "" {
+		return 0, 0, fmt.Errorf("invalid journal range: %s", spec)
+	}
+	first, last := 1, count
+	from, to, isRange := strings.Cut(spec, "-")
+
+	var err error
+	if from != "" {
+		if first, err = strconv.Atoi(from); err != nil {
+			return 0, 0, fmt.Errorf("invalid journal range: %s", spec)
+		}
+	}
+	if !isRange {
+		last = first
+	} else if to != "" {
+		if last, err = strconv.Atoi(to); err != nil {
+			return 0, 0, fmt.Errorf("invalid journal range: %s", spec)
+		}
+	}
+
+	if first < 1 || last > count || first > last {
+		return 0, 0, fmt.Errorf("journal range %s is out of bounds, journal has %d entries", spec, count)
+	}
+	return first, last, nil
+}
+
+// unredactArgs replaces redacted arguments with empty values so the command can be re-applied
+func unredactArgs(args []string) []string {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		if arg != redactedArg {
+			result[i] = arg
+		}
+	}
+	return result
+}
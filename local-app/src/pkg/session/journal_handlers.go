@@ -0,0 +1,86 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+// handleJournalToday handles the journal today command, which selects the daily journal mindmap of the user and
+// makes the node of the day the current node, adding the mindmap and the node as needed
+func handleJournalToday(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling journal today command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) != 0 {
+		sm.logger.Error(ctx, "Invalid number of arguments for journal today", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("journal today command does not accept any arguments")
+	}
+
+	cfg := sm.dataManager.Config
+	mindmap, err := journalMindmap(sm, session, cfg.JournalMindmap)
+	if err != nil {
+		return nil, err
+	}
+
+	node, created, err := sm.dataManager.JournalDay(mindmap, cfg.JournalBranch, time.Now(), cfg.JournalTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal day: %w", err)
+	}
+
+	session.Mindmap = mindmap
+	session.Node = node
+	sm.logger.Info(ctx, "Journal day selected", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "created": created})
+
+	if created {
+		return fmt.Sprintf("Journal %s added at node %s of %s", node.Name, sm.nodeIndex(node), mindmap.Name), nil
+	}
+	return fmt.Sprintf("Journal %s at node %s of %s", node.Name, sm.nodeIndex(node), mindmap.Name), nil
+}
+
+// journalMindmap returns the loaded daily journal mindmap of the session user, adding it if the user has none
+func journalMindmap(sm *SessionManager, session *model.Session, name string) (*model.Mindmap, error) {
+	ctx := context.Background()
+
+	// Keep the selected mindmap if it is the journal, so its loaded nodes are not replaced
+	if session.Mindmap != nil && session.Mindmap.Owner == session.User.Username && names.Equal(session.Mindmap.Name, name, false) {
+		return session.Mindmap, nil
+	}
+
+	mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{Name: name, Owner: session.User.Username}, model.MindmapFilter{Name: true, Owner: true})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get journal mindmap", log.Fields{"error": err, "mindmapName": name})
+		return nil, fmt.Errorf("failed to get journal mindmap: %w", err)
+	}
+
+	if len(mindmaps) == 0 {
+		if err := names.Validate(names.Mindmap, name); err != nil {
+			sm.logger.Error(ctx, "Invalid journal mindmap name", log.Fields{"error": err, "mindmapName": name})
+			return nil, fmt.Errorf("invalid journal mindmap in configuration: %w", err)
+		}
+		id, err := sm.dataManager.MindmapManager.MindmapAdd(session.User, model.MindmapInfo{Name: name})
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to add journal mindmap", log.Fields{"error": err, "mindmapName": name})
+			return nil, fmt.Errorf("failed to add journal mindmap: %w", err)
+		}
+		mindmaps, err = sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{ID: id}, model.MindmapFilter{ID: true})
+		if err != nil || len(mindmaps) == 0 {
+			sm.logger.Error(ctx, "Failed to retrieve journal mindmap", log.Fields{"error": err, "mindmapID": id})
+			return nil, fmt.Errorf("failed to retrieve journal mindmap: %v", err)
+		}
+		sm.logger.Info(ctx, "Journal mindmap added", log.Fields{"mindmapID": id})
+	}
+
+	mindmap := mindmaps[0]
+	if err := sm.dataManager.EventManager.PublishAndWait(event.Event{Type: event.MindmapSelected, Data: mindmap}); err != nil {
+		sm.logger.Error(ctx, "Failed to load journal mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to load journal mindmap: %w", err)
+	}
+	return mindmap, nil
+}
@@ -0,0 +1,49 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJournalRange(t *testing.T) {
+	tests := []struct {
+		spec        string
+		count       int
+		first, last int
+		wantErr     string
+	}{
+		{spec: "3", count: 5, first: 3, last: 3},
+		{spec: "2-4", count: 5, first: 2, last: 4},
+		{spec: "2-", count: 5, first: 2, last: 5},
+		{spec: "-3", count: 5, first: 1, last: 3},
+		{spec: "-", count: 5, first: 1, last: 5},
+		{spec: "1-5", count: 5, first: 1, last: 5},
+		{spec: "4-4", count: 5, first: 4, last: 4},
+		{spec: "0", count: 5, wantErr: "out of bounds"},
+		{spec: "6", count: 5, wantErr: "out of bounds"},
+		{spec: "2-6", count: 5, wantErr: "out of bounds"},
+		{spec: "4-2", count: 5, wantErr: "out of bounds"},
+		{spec: "1", count: 0, wantErr: "out of bounds"},
+		{spec: "a", count: 5, wantErr: "invalid journal range"},
+		{spec: "1-b", count: 5, wantErr: "invalid journal range"},
+		{spec: "1-2-3", count: 5, wantErr: "invalid journal range"},
+		{spec: "", count: 5, wantErr: "invalid journal range"},
+	}
+
+	for _, tt := range tests {
+		first, last, err := parseJournalRange(tt.spec, tt.count)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseJournalRange(%q, %d) error = %v, want %q", tt.spec, tt.count, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseJournalRange(%q, %d) failed: %v", tt.spec, tt.count, err)
+			continue
+		}
+		if first != tt.first || last != tt.last {
+			t.Errorf("parseJournalRange(%q, %d) = %d-%d, want %d-%d", tt.spec, tt.count, first, last, tt.first, tt.last)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// partialOperations are the mindmap operations working on a mindmap loaded only a few levels deep, the view loading
+// the nodes it shows and checkpoints reading them from storage
+var partialOperations = []string{"view", "select", "list", "exists", "checkpoint", "history"}
+
+// lazyLoadMiddleware loads the rest of the nodes of a mindmap selected only a few levels deep, see lazy_load_depth
+// in the configuration, before a command needing all of them. The commands of the system, user and admin scopes
+// don't use the nodes.
+func (sm *SessionManager) lazyLoadMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		switch {
+		case session.Mindmap == nil || !session.Mindmap.Partial():
+		case cmd.Scope == "system", cmd.Scope == "user", cmd.Scope == "admin":
+		case cmd.Scope == "mindmap" && slices.Contains(partialOperations, cmd.Operation):
+		default:
+			if err := sm.nodesLoadAll(session); err != nil {
+				return nil, err
+			}
+		}
+		return next(sm, session, cmd)
+	}
+}
+
+// nodesLoadAll loads all nodes of the mindmap of a session, hiding the private subtrees from users other than the
+// owner again
+func (sm *SessionManager) nodesLoadAll(session *model.Session) error {
+	ctx := context.Background()
+
+	loaded, err := sm.dataManager.NodeManager.NodeLoadAll(session.Mindmap)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to load all nodes", log.Fields{"error": err, "mindmapID": session.Mindmap.ID})
+		return fmt.Errorf("failed to load mindmap %s: %w", session.Mindmap.Name, err)
+	}
+	if !loaded {
+		return nil
+	}
+	if session.Mindmap.Owner != session.User.Username {
+		sm.dataManager.NodeManager.NodePrivateHide(session.Mindmap)
+	}
+	sm.logger.Info(ctx, "All nodes of mindmap loaded", log.Fields{"mindmapID": session.Mindmap.ID, "nodeCount": len(session.Mindmap.Nodes)})
+	return nil
+}
+
+// nodeLoad loads a node of the mindmap of a session selected only a few levels deep with the levels below it set by
+// lazy_load_depth, hiding newly loaded private subtrees from users other than the owner
+func (sm *SessionManager) nodeLoad(session *model.Session, index string) error {
+	mindmap := session.Mindmap
+	if !mindmap.Partial() {
+		return nil
+	}
+	if err := sm.dataManager.NodeManager.NodeLoad(mindmap, index, sm.dataManager.Config.LazyLoadDepth); err != nil {
+		sm.logger.Error(context.Background(), "Failed to load node", log.Fields{"error": err, "mindmapID": mindmap.ID, "index": index})
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+	if mindmap.Owner != session.User.Username {
+		sm.dataManager.NodeManager.NodePrivateHide(mindmap)
+	}
+	return nil
+}
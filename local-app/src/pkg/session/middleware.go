@@ -0,0 +1,288 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+// Middleware wraps a command handler with behavior shared by many commands, such as checks before the handler
+// runs or processing of its result.
+type Middleware func(next CommandHandler) CommandHandler
+
+// Stage orders middleware around the command handler, commands pass the stages in the order of their values
+type Stage int
+
+const (
+	StageAuth      Stage = iota // Checks of the session state the command depends on
+	StageValidate               // Checks of the command arguments
+	StageRateLimit              // Limits of the number of commands
+	StageAudit                  // Recording of the executed command
+	StageResult                 // Shaping of the handler result, innermost
+	stageCount
+)
+
+// rateLimitWindow is the period the command rate limit applies to
+const rateLimitWindow = time.Minute
+
+// middlewareEntry is middleware registered for a stage and scope, an empty scope applies to all scopes
+type middlewareEntry struct {
+	stage      Stage
+	scope      string
+	middleware Middleware
+}
+
+// rateWindow counts the commands of a session in the current rate limit window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// Use registers middleware for a stage of the commands of a scope, or of all scopes if scope is empty.
+// Within a stage, middleware for all scopes runs before scope middleware, each in order of registration.
+func (sm *SessionManager) Use(scope string, stage Stage, middleware ...Middleware) {
+	for _, mw := range middleware {
+		sm.middleware = append(sm.middleware, middlewareEntry{stage: stage, scope: scope, middleware: mw})
+	}
+}
+
+// initMiddleware registers the middleware of the built-in commands
+func (sm *SessionManager) initMiddleware() {
+	sm.Use("user", StageAuth, requireUser("update", "delete", "default", "inbox"))
+	sm.Use("mindmap", StageAuth, requireUser("add", "delete", "permission", "import", "export", "select", "list", "changes", "compare", "reindex", "restore", "checkpoint", "history", "revert", "set", "graph", "exists", "summary"), requireMindmap("export", "view", "check", "changes", "reindex", "checkpoint", "history", "revert", "set", "graph", "summary"))
+	sm.Use("", StageAuth, sm.refreshMiddleware, sm.lazyLoadMiddleware)
+	sm.Use("node", StageAuth, requireMindmap(), sm.privateMiddleware)
+	sm.Use("journal", StageAuth, requireUser())
+	sm.Use("add", StageAuth, requireUser())
+	sm.Use("admin", StageAuth, requireUser("audit"), sm.adminMiddleware)
+	sm.Use("", StageValidate, sm.validateMiddleware, sm.readOnlyMiddleware)
+	sm.Use("", StageRateLimit, sm.rateLimitMiddleware)
+	sm.Use("", StageAudit, sm.auditMiddleware, sm.journalMiddleware, sm.telemetryMiddleware, sm.hookMiddleware, sm.transcriptMiddleware)
+	sm.Use("node", StageAudit, sm.versionMiddleware)
+	sm.Use("node", StageResult, sm.changeMiddleware)
+	sm.Use("mindmap", StageResult, shapeMindmapResult)
+}
+
+// commandChain wraps the handler of a command of the given scope in the middleware registered for the scope
+func (sm *SessionManager) commandChain(scope string, handler CommandHandler) CommandHandler {
+	var chain []Middleware
+	for stage := Stage(0); stage < stageCount; stage++ {
+		for _, global := range []bool{true, false} {
+			for _, entry := range sm.middleware {
+				if entry.stage == stage && (entry.scope == "") == global && (global || entry.scope == scope) {
+					chain = append(chain, entry.middleware)
+				}
+			}
+		}
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	return handler
+}
+
+// requireUser rejects the given operations, or all operations if none are given, if no user is selected
+func requireUser(operations ...string) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+			if session.User == nil && (len(operations) == 0 || slices.Contains(operations, cmd.Operation)) {
+				sm.logger.Error(context.Background(), "No user selected", log.Fields{"scope": cmd.Scope, "operation": cmd.Operation})
+				return nil, fmt.Errorf("no user selected")
+			}
+			return next(sm, session, cmd)
+		}
+	}
+}
+
+// requireMindmap rejects the given operations, or all operations if none are given, if no mindmap is selected
+func requireMindmap(operations ...string) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+			if session.Mindmap == nil && (len(operations) == 0 || slices.Contains(operations, cmd.Operation)) {
+				sm.logger.Error(context.Background(), "No mindmap selected", log.Fields{"scope": cmd.Scope, "operation": cmd.Operation})
+				return nil, fmt.Errorf("no mindmap selected")
+			}
+			return next(sm, session, cmd)
+		}
+	}
+}
+
+// adminMiddleware rejects the admin commands showing or changing the state of the whole server, which are those
+// other than admin audit, unless the user of the session is an admin. Admin audit limits the entries of other
+// users to their own.
+func (sm *SessionManager) adminMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		if cmd.Operation != "audit" && !sm.isAdmin(session) {
+			sm.logger.Error(context.Background(), "Admin command of a user who is not an admin", log.Fields{"operation": cmd.Operation})
+			return nil, fmt.Errorf("admin %s requires an admin user, listed in admin_users of the configuration", cmd.Operation)
+		}
+		return next(sm, session, cmd)
+	}
+}
+
+// isAdmin returns whether the user selected in a session is listed in admin_users
+func (sm *SessionManager) isAdmin(session *model.Session) bool {
+	if session.User == nil {
+		return false
+	}
+	config := sm.dataManager.Config
+	return slices.ContainsFunc(config.AdminUsers, func(admin string) bool {
+		return names.Equal(admin, session.User.Username, config.CaseSensitiveNames)
+	})
+}
+
+// validateMiddleware checks the command arguments before the command runs
+func (sm *SessionManager) validateMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		if err := sm.validateCommand(cmd); err != nil {
+			sm.logger.Error(context.Background(), "Command validation failed", log.Fields{"sessionID": session.ID, "error": err})
+			return nil, err
+		}
+		return next(sm, session, cmd)
+	}
+}
+
+// readOnlyMiddleware rejects the commands changing the database when it is opened read-only
+func (sm *SessionManager) readOnlyMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		if sm.dataManager.Config.ReadOnly && changesDatabase(cmd) {
+			sm.logger.Warn(context.Background(), "Command refused in read-only mode", log.Fields{"scope": cmd.Scope, "operation": cmd.Operation})
+			return nil, fmt.Errorf("%s %s is not available, the database is opened read-only", cmd.Scope, cmd.Operation)
+		}
+		return next(sm, session, cmd)
+	}
+}
+
+// changesDatabase reports whether a command writes to the database, besides the mutating commands also the
+// maintenance commands that do not change the data itself
+func changesDatabase(cmd model.Command) bool {
+	if isMutatingCommand(cmd) {
+		return true
+	}
+	return cmd.Scope == "system" && (cmd.Operation == "replay" || cmd.Operation == "db" && slices.Contains(cmd.Args, "vacuum") ||
+		cmd.Operation == "gc" && slices.Contains(cmd.Args, "--force")) ||
+		cmd.Scope == "admin" && cmd.Operation == "telemetry" && len(cmd.Args) > 0 && strings.EqualFold(cmd.Args[0], "reset")
+}
+
+// rateLimitMiddleware rejects commands of a session exceeding the configured number of commands per minute.
+// Commands applied by a replay are part of the replay command and not counted.
+func (sm *SessionManager) rateLimitMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		limit := sm.dataManager.Config.CommandRateLimit
+		if limit <= 0 || sm.replaying {
+			return next(sm, session, cmd)
+		}
+
+		now := time.Now()
+		sm.sessionMutex.Lock()
+		window, ok := sm.rateWindows[session.ID]
+		if !ok || now.Sub(window.start) >= rateLimitWindow {
+			window = &rateWindow{start: now}
+			sm.rateWindows[session.ID] = window
+		}
+		sm.sessionMutex.Unlock()
+		if window.count >= limit {
+			sm.logger.Warn(context.Background(), "Command rate limit exceeded", log.Fields{"sessionID": session.ID, "limit": limit})
+			return nil, fmt.Errorf("rate limit of %d commands per minute exceeded, retry in %s", limit, window.start.Add(rateLimitWindow).Sub(now).Round(time.Second))
+		}
+		window.count++
+
+		return next(sm, session, cmd)
+	}
+}
+
+// auditMiddleware records the command and its outcome in the audit log
+func (sm *SessionManager) auditMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		result, err := next(sm, session, cmd)
+		// Background jobs are audited once they are done
+		if _, started := result.(jobStarted); !started {
+			sm.auditCommand(session, cmd, result, err)
+		}
+		return result, err
+	}
+}
+
+// journalMiddleware appends successful commands to the command journal. Replayed commands are already journaled.
+func (sm *SessionManager) journalMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		result, err := next(sm, session, cmd)
+		// Background jobs are journaled once they are done, in the order their changes were made
+		if _, started := result.(jobStarted); err == nil && !started && !sm.replaying {
+			sm.journalCommand(session, cmd)
+		}
+		return result, err
+	}
+}
+
+// telemetryMiddleware counts the command and its failure in the command telemetry if it is enabled. Only the scope
+// and operation are recorded. Replayed commands and keep-alive pings are not counted.
+func (sm *SessionManager) telemetryMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		result, err := next(sm, session, cmd)
+		config := sm.dataManager.Config
+		if config.Telemetry && !config.ReadOnly && !sm.replaying && !(cmd.Scope == "system" && cmd.Operation == "ping") {
+			if recordErr := sm.dataManager.TelemetryManager.TelemetryRecord(cmd.Scope, cmd.Operation, err != nil); recordErr != nil {
+				sm.logger.Error(context.Background(), "Failed to record command telemetry", log.Fields{"error": recordErr, "scope": cmd.Scope, "operation": cmd.Operation})
+			}
+		}
+		return result, err
+	}
+}
+
+// shapeMindmapResult formats mindmaps returned by mindmap commands for display
+func shapeMindmapResult(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		result, err := next(sm, session, cmd)
+		if err != nil {
+			return result, err
+		}
+
+		switch r := result.(type) {
+		case *model.Mindmap:
+			return formatMindmap(sm, r), nil
+		case []*model.Mindmap:
+			if len(r) == 0 {
+				return "No mindmaps found", nil
+			}
+			lines := make([]string, len(r))
+			for i, mindmap := range r {
+				lines[i] = formatMindmap(sm, mindmap)
+			}
+			return strings.Join(lines, "\n"), nil
+		case model.Page[*model.Mindmap]:
+			if r.Total == 0 {
+				return "No mindmaps found", nil
+			}
+			page := model.Page[string]{Items: make([]string, len(r.Items)), Offset: r.Offset, Total: r.Total}
+			for i, mindmap := range r.Items {
+				page.Items[i] = formatMindmap(sm, mindmap)
+			}
+			return page, nil
+		}
+		return result, nil
+	}
+}
+
+// formatMindmap formats a mindmap summary, with its cached node count and depth if available
+func formatMindmap(sm *SessionManager, mindmap *model.Mindmap) string {
+	permission := "private"
+	if mindmap.IsPublic {
+		permission = "public"
+	}
+	if !mindmap.Sort.IsZero() {
+		permission += ", sorted by " + mindmap.Sort.String()
+	}
+	info := sm.dataManager.MindmapManager.MindmapToInfo(mindmap)
+	if info.NodeCount == nil {
+		return fmt.Sprintf("%s (owner: %s, %s)", mindmap.Name, mindmap.Owner, permission)
+	}
+	return fmt.Sprintf("%s (owner: %s, %s, %d nodes, depth %d)", mindmap.Name, mindmap.Owner, permission, *info.NodeCount-mindmap.Hidden, *info.Depth)
+}
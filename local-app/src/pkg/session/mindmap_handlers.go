@@ -4,11 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"mindnoscape/local-app/src/pkg/clipboard"
+	"mindnoscape/local-app/src/pkg/data"
 	"mindnoscape/local-app/src/pkg/event"
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
 )
 
 // handleMindmapAdd handles the mindmap add command
@@ -21,9 +27,9 @@ func handleMindmapAdd(sm *SessionManager, session *model.Session, cmd model.Comm
 		return nil, errors.New("mindmap add command requires exactly 1 argument: <mindmap_name>")
 	}
 
-	if session.User == nil {
-		sm.logger.Error(ctx, "No user selected", nil)
-		return nil, fmt.Errorf("no user selected")
+	if err := names.Validate(names.Mindmap, cmd.Args[0]); err != nil {
+		sm.logger.Error(ctx, "Invalid mindmap name", log.Fields{"error": err, "mindmapName": cmd.Args[0]})
+		return nil, err
 	}
 
 	mindmapInfo := model.MindmapInfo{
@@ -59,11 +65,6 @@ func handleMindmapDelete(sm *SessionManager, session *model.Session, cmd model.C
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Handling mindmap delete command", log.Fields{"args": cmd.Args})
 
-	if session.User == nil {
-		sm.logger.Error(ctx, "No user selected", nil)
-		return nil, fmt.Errorf("no user selected")
-	}
-
 	if len(cmd.Args) == 0 {
 		// Delete current mindmap
 		if session.Mindmap == nil {
@@ -121,11 +122,6 @@ func handleMindmapPermission(sm *SessionManager, session *model.Session, cmd mod
 		return nil, errors.New("mindmap permission command requires 1 or 2 arguments: <mindmap_name> [public|private]")
 	}
 
-	if session.User == nil {
-		sm.logger.Error(ctx, "No user selected", nil)
-		return nil, fmt.Errorf("no user selected")
-	}
-
 	mindmapName := cmd.Args[0]
 	sm.logger.Debug(ctx, "Getting mindmap for permission change", log.Fields{"mindmapName": mindmapName})
 	mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{Name: mindmapName}, model.MindmapFilter{Name: true})
@@ -169,82 +165,323 @@ func handleMindmapImport(sm *SessionManager, session *model.Session, cmd model.C
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Handling mindmap import command", log.Fields{"args": cmd.Args})
 
-	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 6 {
 		sm.logger.Error(ctx, "Invalid number of arguments for mindmap import", log.Fields{"argCount": len(cmd.Args)})
-		return nil, errors.New("mindmap import command requires 1 or 2 arguments: <filename> [json|xml]")
+		return nil, fmt.Errorf("mindmap import command requires 1 to 6 arguments: <filename> [%s] [--force] [--skip-existing|--update-existing|--duplicate|--sync] [--on-conflict %s]", formatChoice(importFormatNames()), formatChoice(conflictPolicyNames))
+	}
+
+	options := model.ImportOptions{Filename: cmd.Args[0], Progress: session.Progress}
+	for i := 1; i < len(cmd.Args); i++ {
+		arg := cmd.Args[i]
+		var policy model.ImportPolicy
+		switch arg {
+		case "--force":
+			options.Force = true
+		case "--skip-existing":
+			policy = model.ImportSkip
+		case "--update-existing":
+			policy = model.ImportUpdate
+		case "--duplicate":
+			policy = model.ImportDuplicate
+		case "--sync":
+			policy = model.ImportSync
+		case "--on-conflict":
+			if i+1 >= len(cmd.Args) || !slices.Contains(conflictPolicyNames, strings.ToLower(cmd.Args[i+1])) {
+				sm.logger.Error(ctx, "Invalid conflict policy", log.Fields{"args": cmd.Args})
+				return nil, fmt.Errorf("--on-conflict must be followed by %s", formatList(conflictPolicyNames))
+			}
+			i++
+			options.Conflicts = model.ConflictPolicy(strings.ToLower(cmd.Args[i]))
+		default:
+			options.Format = strings.ToLower(arg)
+		}
+		if policy != model.ImportReplace {
+			if options.Existing != model.ImportReplace && options.Existing != policy {
+				sm.logger.Error(ctx, "Conflicting import policies", log.Fields{"policy": policy, "existing": options.Existing})
+				return nil, errors.New("only one of --skip-existing, --update-existing, --duplicate and --sync can be given")
+			}
+			options.Existing = policy
+		}
 	}
-
-	if session.User == nil {
-		sm.logger.Error(ctx, "No user selected", nil)
-		return nil, fmt.Errorf("no user selected")
+	if options.Conflicts != model.ConflictRefuse && options.Existing != model.ImportUpdate && options.Existing != model.ImportSync {
+		sm.logger.Error(ctx, "Conflict policy without an updating import", log.Fields{"existing": options.Existing})
+		return nil, errors.New("--on-conflict only applies with --update-existing or --sync")
 	}
 
-	filename := cmd.Args[0]
-	format := "json"
-	if len(cmd.Args) == 2 {
-		format = strings.ToLower(cmd.Args[1])
+	if options.Format != "" && !data.IsImportFormat(strings.ToLower(options.Format)) {
+		sm.logger.Error(ctx, "Invalid import format", log.Fields{"format": options.Format})
+		return nil, fmt.Errorf("invalid format: %s. Must be %s", options.Format, formatList(importFormatNames()))
 	}
 
-	if format != "json" && format != "xml" {
-		sm.logger.Error(ctx, "Invalid import format", log.Fields{"format": format})
-		return nil, fmt.Errorf("invalid format: %s. Must be 'json' or 'xml'", format)
+	if data.IsEncryptedPath(options.Filename) {
+		passphrase, err := sessionPassphrase(sm, session, false)
+		if err != nil {
+			return nil, err
+		}
+		options.Passphrase = passphrase
 	}
 
-	sm.logger.Debug(ctx, "Importing mindmap", log.Fields{"filename": filename, "format": format})
-	importedMindmap, err := sm.dataManager.MindmapImport(session.User, filename, format)
-	if err != nil {
-		sm.logger.Error(ctx, "Failed to import mindmap", log.Fields{"error": err, "filename": filename})
-		return nil, fmt.Errorf("failed to import mindmap: %w", err)
+	// Conflicts of an update without a policy are resolved at the prompt of the session, if it has one
+	updating := options.Existing == model.ImportUpdate || options.Existing == model.ImportSync
+	if updating && options.Conflicts == model.ConflictRefuse && !sm.replaying {
+		options.Resolve = session.Resolve
 	}
 
-	// Set the imported mindmap as the current mindmap
-	session.Mindmap = importedMindmap
-	sm.logger.Debug(ctx, "Set imported mindmap as current", log.Fields{"mindmapID": importedMindmap.ID})
+	sm.logger.Debug(ctx, "Importing mindmap", log.Fields{"options": options})
+	user, selected := session.User, session.Mindmap
+	work := func(run jobRunner) (interface{}, error) {
+		// Reading and verifying the file uses no loaded mindmap, only storing it does
+		options.Progress = run.progress
+		file, err := sm.dataManager.MindmapImportRead(options)
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to import mindmap", log.Fields{"error": err, "filename": options.Filename})
+			return nil, fmt.Errorf("failed to import mindmap: %w", err)
+		}
+		options.File = file
+
+		return run.exclusive(func() (interface{}, error) {
+			var importedMindmap *model.Mindmap
+			var merge model.ImportMerge
+			var warnings []string
+			var err error
+			if options.Existing == model.ImportReplace {
+				importedMindmap, warnings, err = sm.dataManager.MindmapImport(user, options)
+			} else {
+				importedMindmap, merge, warnings, err = sm.dataManager.MindmapImportMerge(user, options)
+			}
+			if err != nil {
+				sm.logger.Error(ctx, "Failed to import mindmap", log.Fields{"error": err, "filename": options.Filename})
+				return nil, fmt.Errorf("failed to import mindmap: %w", err)
+			}
+
+			// Set the imported mindmap as the current mindmap, unless the session selected another one while a
+			// background import ran
+			if !run.background || session.User == user && session.Mindmap == selected {
+				session.Mindmap = importedMindmap
+				sm.logger.Debug(ctx, "Set imported mindmap as current", log.Fields{"mindmapID": importedMindmap.ID})
+			}
 
-	sm.logger.Info(ctx, "Mindmap imported successfully", log.Fields{"mindmapID": importedMindmap.ID, "mindmapName": importedMindmap.Name})
-	return importedMindmap, nil
+			sm.logger.Info(ctx, "Mindmap imported successfully", log.Fields{"mindmapID": importedMindmap.ID, "mindmapName": importedMindmap.Name, "warnings": len(warnings)})
+			result := fmt.Sprintf("Mindmap '%s' imported", importedMindmap.Name)
+			if options.Existing != model.ImportReplace {
+				result = fmt.Sprintf("Mindmap '%s' imported: %d nodes added, %d updated, %d skipped", importedMindmap.Name, merge.Added, merge.Updated, merge.Skipped)
+				if options.Existing == model.ImportSync {
+					result += fmt.Sprintf(", %d moved, %d deleted", merge.Moved, merge.Deleted)
+				}
+				if merge.Conflicts > 0 {
+					result += fmt.Sprintf(", %d conflicts resolved", merge.Conflicts)
+				}
+			}
+			for _, warning := range warnings {
+				result += "\nWarning: " + warning
+			}
+			return result, nil
+		})
+	}
+	if options.Resolve != nil {
+		// A background job can't share the terminal with the prompt of the session
+		return work(jobRunner{sm: sm, progress: session.Progress})
+	}
+	return sm.jobRun(session, cmd, work)
 }
 
-// handleMindmapExport handles the mindmap export command
+// conflictPolicyNames are the policies --on-conflict takes, edit only being a choice at the prompt
+var conflictPolicyNames = []string{string(model.ConflictMine), string(model.ConflictTheirs), string(model.ConflictBoth)}
+
 func handleMindmapExport(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Handling mindmap export command", log.Fields{"args": cmd.Args})
 
-	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+	if len(cmd.Args) > 13 {
 		sm.logger.Error(ctx, "Invalid number of arguments for mindmap export", log.Fields{"argCount": len(cmd.Args)})
-		return nil, errors.New("mindmap export command requires 1 or 2 arguments: <filename> [json|xml]")
+		return nil, fmt.Errorf("mindmap export command requires 0 to 13 arguments: [filename] [%s] [--node <node>] [--id] [--force] [--compress[=gz|zst]] [--sign] [--include-computed] [--redact <profile>] [--encrypt] [--clipboard]", formatChoice(exportFormatNames(nil)))
+	}
+
+	options := model.ExportOptions{Format: "json", Progress: session.Progress}
+	var positional []string
+	nodeIdentifier, useID, toClipboard := "", false, false
+	for i := 0; i < len(cmd.Args); i++ {
+		arg := cmd.Args[i]
+		switch {
+		case arg == "--node":
+			if i+1 >= len(cmd.Args) {
+				sm.logger.Error(ctx, "Missing node for mindmap export", nil)
+				return nil, errors.New("--node requires a node index or ID")
+			}
+			i++
+			nodeIdentifier = cmd.Args[i]
+		case arg == "--id":
+			useID = true
+		case arg == "--force":
+			options.Force = true
+		case arg == "--sign":
+			options.Sign = true
+		case arg == "--include-computed":
+			options.Computed = true
+		case arg == "--redact":
+			if i+1 >= len(cmd.Args) {
+				sm.logger.Error(ctx, "Missing redaction profile for mindmap export", nil)
+				return nil, errors.New("--redact requires a redaction profile")
+			}
+			i++
+			options.Redact = cmd.Args[i]
+		case arg == "--encrypt":
+			options.Encrypt = true
+		case arg == "--clipboard":
+			toClipboard = true
+		case arg == "--compress":
+			options.Compression = model.CompressionGzip
+		case strings.HasPrefix(arg, "--compress="):
+			options.Compression = strings.ToLower(strings.TrimPrefix(arg, "--compress="))
+			if options.Compression != model.CompressionGzip && options.Compression != model.CompressionZstd {
+				sm.logger.Error(ctx, "Invalid export compression", log.Fields{"compression": options.Compression})
+				return nil, fmt.Errorf("invalid compression: %s. Must be 'gz' or 'zst'", options.Compression)
+			}
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	// A single format argument selects the format of the templated filename
+	if len(positional) == 1 && data.IsExportFormat(strings.ToLower(positional[0])) {
+		positional = []string{"", positional[0]}
+	}
+	if len(positional) > 2 {
+		sm.logger.Error(ctx, "Too many arguments for mindmap export", log.Fields{"args": positional})
+		return nil, errors.New("mindmap export command accepts at most a filename and a format")
+	}
+	if len(positional) > 0 {
+		options.Filename = positional[0]
+	}
+	if len(positional) > 1 {
+		options.Format = strings.ToLower(positional[1])
+	}
+
+	if !data.IsExportFormat(options.Format) {
+		sm.logger.Error(ctx, "Invalid export format", log.Fields{"format": options.Format})
+		return nil, fmt.Errorf("invalid format: %s. Must be %s", options.Format, formatList(exportFormatNames(nil)))
 	}
 
-	if session.User == nil {
-		sm.logger.Error(ctx, "No user selected", nil)
-		return nil, fmt.Errorf("no user selected")
+	root := session.Mindmap.Root
+	if nodeIdentifier != "" {
+		node, err := getNode(sm, session.Mindmap, nodeIdentifier, useID)
+		if err != nil {
+			return nil, err
+		}
+		if node.ID != root.ID && !data.IsSubtreeFormat(options.Format) {
+			sm.logger.Error(ctx, "Subtree export in a format without subtrees", log.Fields{"format": options.Format})
+			return nil, fmt.Errorf("subtrees can't be exported as %s, only as %s", options.Format, formatList(exportFormatNames(func(f model.ExportFormat) bool { return f.Subtree })))
+		}
+		root = node
+		options.Node = node
 	}
 
-	if session.Mindmap == nil {
-		sm.logger.Error(ctx, "No mindmap selected", nil)
-		return nil, fmt.Errorf("no mindmap selected")
+	// Forcing an export also allows exporting large mindmaps
+	if err := sm.dataManager.NodeManager.NodeOperationCheck(session.Mindmap, root, "export", options.Force); err != nil {
+		return nil, err
 	}
 
-	filename := cmd.Args[0]
-	format := "json"
-	if len(cmd.Args) == 2 {
-		format = strings.ToLower(cmd.Args[1])
+	if options.Encrypt || data.IsEncryptedPath(options.Filename) {
+		if toClipboard {
+			sm.logger.Error(ctx, "Encrypted clipboard export", nil)
+			return nil, errors.New("encrypted exports can't be copied to the clipboard")
+		}
+		passphrase, err := sessionPassphrase(sm, session, true)
+		if err != nil {
+			return nil, err
+		}
+		options.Encrypt, options.Passphrase = true, passphrase
 	}
 
-	if format != "json" && format != "xml" {
-		sm.logger.Error(ctx, "Invalid export format", log.Fields{"format": format})
-		return nil, fmt.Errorf("invalid format: %s. Must be 'json' or 'xml'", format)
+	if toClipboard {
+		return exportClipboard(sm, session, options)
 	}
 
-	sm.logger.Debug(ctx, "Exporting mindmap", log.Fields{"filename": filename, "format": format, "mindmapID": session.Mindmap.ID})
-	err := sm.dataManager.MindmapExport(session.User, session.Mindmap, filename, format)
+	// A background job exports a copy, the mindmap may change while it runs
+	mindmap, user := session.Mindmap, session.User
+	if sm.jobBackground(session) {
+		mindmap = mindmap.Clone()
+		if options.Node != nil {
+			options.Node = mindmap.Nodes[options.Node.ID]
+		}
+	}
+
+	sm.logger.Debug(ctx, "Exporting mindmap", log.Fields{"options": options, "mindmapID": mindmap.ID})
+	return sm.jobRun(session, cmd, func(run jobRunner) (interface{}, error) {
+		// The links and attachments of the nodes are read from storage, which only the executor uses, the
+		// encoding and writing of the file run in the background
+		exported, err := run.exclusive(func() (interface{}, error) {
+			return sm.dataManager.ExportedMindmap(user, mindmap, options)
+		})
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to export mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+			return nil, fmt.Errorf("failed to export mindmap: %w", err)
+		}
+
+		options.Progress = run.progress
+		path, err := sm.dataManager.MindmapExportFile(user, mindmap, exported.(*model.Mindmap), options)
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to export mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+			return nil, fmt.Errorf("failed to export mindmap: %w", err)
+		}
+
+		sm.logger.Info(ctx, "Mindmap exported successfully", log.Fields{"path": path, "format": options.Format, "mindmapID": mindmap.ID})
+		return fmt.Sprintf("Mindmap exported to %s", path), nil
+	})
+}
+
+// sessionPassphrase asks the user of a session for the passphrase of an encrypted file, twice if confirm is set
+// as a mistyped passphrase of a new file can't be recovered
+func sessionPassphrase(sm *SessionManager, session *model.Session, confirm bool) (string, error) {
+	ctx := context.Background()
+	if session.Passphrase == nil {
+		sm.logger.Error(ctx, "No passphrase prompt for encrypted file", nil)
+		return "", errors.New("encrypted files need a passphrase, which this session can't prompt for")
+	}
+
+	passphrase, err := session.Passphrase("Passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("passphrase cannot be empty")
+	}
+	if confirm {
+		repeated, err := session.Passphrase("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if repeated != passphrase {
+			sm.logger.Warn(ctx, "Passphrases do not match", nil)
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// exportClipboard exports the mindmap of a session to the clipboard instead of a file, in a text format
+func exportClipboard(sm *SessionManager, session *model.Session, options model.ExportOptions) (interface{}, error) {
+	ctx := context.Background()
+	if options.Filename != "" || options.Compression != model.CompressionNone || options.Sign {
+		sm.logger.Error(ctx, "File options for a clipboard export", log.Fields{"options": options})
+		return nil, errors.New("a filename, --compress and --sign only apply to exports to a file, not to the clipboard")
+	}
+	if !data.IsTextFormat(options.Format) {
+		sm.logger.Error(ctx, "Binary format for a clipboard export", log.Fields{"format": options.Format})
+		return nil, fmt.Errorf("%s can't be copied to the clipboard, only %s", options.Format, formatList(exportFormatNames(func(f model.ExportFormat) bool { return !f.Binary })))
+	}
+
+	encoded, err := sm.dataManager.MindmapEncode(session.User, session.Mindmap, options)
 	if err != nil {
 		sm.logger.Error(ctx, "Failed to export mindmap", log.Fields{"error": err, "mindmapID": session.Mindmap.ID})
 		return nil, fmt.Errorf("failed to export mindmap: %w", err)
 	}
+	if err := clipboard.Write(string(encoded)); err != nil {
+		sm.logger.Error(ctx, "Failed to copy export to the clipboard", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to copy to the clipboard: %w", err)
+	}
 
-	sm.logger.Info(ctx, "Mindmap exported successfully", log.Fields{"filename": filename, "format": format, "mindmapID": session.Mindmap.ID})
-	return nil, nil
+	sm.logger.Info(ctx, "Mindmap exported to the clipboard", log.Fields{"format": options.Format, "mindmapID": session.Mindmap.ID, "bytes": len(encoded)})
+	return fmt.Sprintf("Mindmap copied to the clipboard as %s", options.Format), nil
 }
 
 // handleMindmapSelect handles the mindmap select command
@@ -252,11 +489,6 @@ func handleMindmapSelect(sm *SessionManager, session *model.Session, cmd model.C
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Handling mindmap select command", log.Fields{"args": cmd.Args})
 
-	if session.User == nil {
-		sm.logger.Error(ctx, "No user selected", nil)
-		return nil, fmt.Errorf("no user selected")
-	}
-
 	if len(cmd.Args) == 0 {
 		// Deselect current mindmap
 		sm.logger.Debug(ctx, "Deselecting current mindmap", nil)
@@ -278,16 +510,31 @@ func handleMindmapSelect(sm *SessionManager, session *model.Session, cmd model.C
 	}
 
 	selectedMindmap := mindmaps[0]
-	session.Mindmap = selectedMindmap
-	sm.logger.Debug(ctx, "Mindmap selected and set in session", log.Fields{"mindmapID": selectedMindmap.ID})
 
-	// Publish MindmapSelected event
-	sm.dataManager.EventManager.Publish(event.Event{
-		Type: event.MindmapSelected,
-		Data: selectedMindmap,
-	})
+	// Publish MindmapSelected event and wait for the nodes to be loaded, or load only the first levels of the nodes
+	// if lazy loading is configured, the rest being loaded by the commands needing them
+	if depth := sm.dataManager.Config.LazyLoadDepth; depth > 0 {
+		err = sm.dataManager.NodeManager.NodeLoadLevels(selectedMindmap, depth)
+	} else {
+		err = sm.dataManager.EventManager.PublishAndWait(event.Event{
+			Type: event.MindmapSelected,
+			Data: selectedMindmap,
+		})
+	}
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to load selected mindmap", log.Fields{"error": err, "mindmapID": selectedMindmap.ID})
+		return nil, fmt.Errorf("failed to load selected mindmap: %w", err)
+	}
 	sm.logger.Debug(ctx, "Published MindmapSelected event", log.Fields{"mindmapID": selectedMindmap.ID})
 
+	// Users other than the owner don't see the private subtrees of the mindmap
+	if selectedMindmap.Owner != session.User.Username {
+		sm.dataManager.NodeManager.NodePrivateHide(selectedMindmap)
+	}
+
+	session.Mindmap = selectedMindmap
+	sm.logger.Debug(ctx, "Mindmap selected and set in session", log.Fields{"mindmapID": selectedMindmap.ID})
+
 	sm.logger.Info(ctx, "Mindmap selected successfully", log.Fields{"mindmapName": mindmapName, "mindmapID": selectedMindmap.ID})
 	return selectedMindmap, nil
 }
@@ -295,11 +542,16 @@ func handleMindmapSelect(sm *SessionManager, session *model.Session, cmd model.C
 // handleMindmapList handles the mindmap list command
 func handleMindmapList(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
 	ctx := context.Background()
-	sm.logger.Info(ctx, "Handling mindmap list command", nil)
+	sm.logger.Info(ctx, "Handling mindmap list command", log.Fields{"args": cmd.Args})
 
-	if session.User == nil {
-		sm.logger.Error(ctx, "No user selected", nil)
-		return nil, fmt.Errorf("no user selected")
+	options, args, err := pageArgs(cmd.Args)
+	if err != nil {
+		sm.logger.Error(ctx, "Invalid paging arguments for mindmap list", log.Fields{"error": err})
+		return nil, err
+	}
+	if len(args) != 0 {
+		sm.logger.Error(ctx, "Invalid arguments for mindmap list", log.Fields{"args": args})
+		return nil, errors.New("mindmap list command accepts only paging arguments: [--limit <n>] [--offset <n>]")
 	}
 
 	sm.logger.Debug(ctx, "Retrieving mindmaps for user", log.Fields{"username": session.User.Username})
@@ -310,7 +562,7 @@ func handleMindmapList(sm *SessionManager, session *model.Session, cmd model.Com
 	}
 
 	sm.logger.Info(ctx, "Mindmaps retrieved successfully", log.Fields{"count": len(mindmaps)})
-	return mindmaps, nil
+	return model.PageOf(mindmaps, options), nil
 }
 
 // handleMindmapView handles the mindmap view command
@@ -318,27 +570,30 @@ func handleMindmapView(sm *SessionManager, session *model.Session, cmd model.Com
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Handling mindmap view command", log.Fields{"args": cmd.Args})
 
-	if session.Mindmap == nil {
-		sm.logger.Error(ctx, "No mindmap selected", nil)
-		return nil, fmt.Errorf("no mindmap selected")
-	}
-
 	if session.Mindmap.Root == nil {
 		sm.logger.Error(ctx, "Mindmap has no root node", log.Fields{"mindmapID": session.Mindmap.ID})
 		return nil, fmt.Errorf("mindmap has no root node")
 	}
 
-	showID := false
+	showID, showTimes, toClipboard := false, false, false
 	var node *model.Node
 
 	for _, arg := range cmd.Args {
 		if arg == "--id" {
 			showID = true
 			sm.logger.Debug(ctx, "ID display enabled for mindmap view", nil)
+		} else if arg == "--times" {
+			showTimes = true
+		} else if arg == "--copy" {
+			toClipboard = true
 		} else {
 			// Assume the argument is an index
 			sm.logger.Debug(ctx, "Attempting to get node by index", log.Fields{"index": arg})
-			nodes, err := sm.dataManager.NodeManager.NodeGet(session.Mindmap, model.NodeInfo{Index: arg}, model.NodeFilter{Index: true})
+			index := sm.DisplayStyle().ParseIndex(arg)
+			if err := sm.nodeLoad(session, index); err != nil {
+				return nil, err
+			}
+			nodes, err := sm.dataManager.NodeManager.NodeGet(session.Mindmap, model.NodeInfo{Index: index}, model.NodeFilter{Index: true})
 			if err != nil {
 				sm.logger.Error(ctx, "Failed to get node", log.Fields{"error": err, "index": arg})
 				return nil, fmt.Errorf("failed to get node: %w", err)
@@ -348,6 +603,9 @@ func handleMindmapView(sm *SessionManager, session *model.Session, cmd model.Com
 				return nil, fmt.Errorf("node not found with index: %s", arg)
 			}
 			node = nodes[0]
+			if loaded, ok := session.Mindmap.Nodes[node.ID]; ok {
+				node = loaded
+			}
 		}
 	}
 
@@ -356,17 +614,382 @@ func handleMindmapView(sm *SessionManager, session *model.Session, cmd model.Com
 		sm.logger.Debug(ctx, "Using root node for mindmap view", log.Fields{"nodeID": node.ID})
 	}
 
-	// This is a placeholder implementation of formatting node and its children for display
-	formattedView := formatNodeForDisplay(node, showID)
+	links, err := sm.linkAnnotations(session)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get node links", log.Fields{"error": err, "mindmapID": session.Mindmap.ID})
+		return nil, fmt.Errorf("failed to get node links: %w", err)
+	}
+	attachments, err := sm.attachmentAnnotations(session)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get node attachments", log.Fields{"error": err, "mindmapID": session.Mindmap.ID})
+		return nil, fmt.Errorf("failed to get node attachments: %w", err)
+	}
+	now := time.Now()
+	annotate := func(n *model.Node) string {
+		var notes []string
+		if note := links[n.ID]; note != "" {
+			notes = append(notes, note)
+		}
+		if note := attachments[n.ID]; note != "" {
+			notes = append(notes, note)
+		}
+		if count := session.Mindmap.Unloaded[n.ID]; count > 0 {
+			notes = append(notes, fmt.Sprintf("[+%d not loaded]", count))
+		}
+		if showTimes {
+			notes = append(notes, formatNodeTimes(n, now))
+		}
+		return strings.Join(notes, "  ")
+	}
+	formattedView := formatTree(session.Mindmap, node, session.Mindmap.Sort, sm.DisplayStyle(), showID, annotate)
 	sm.logger.Debug(ctx, "Formatted node for display", log.Fields{"nodeID": node.ID})
 
+	if toClipboard {
+		if err := clipboard.Write(formattedView); err != nil {
+			sm.logger.Error(ctx, "Failed to copy view to the clipboard", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to copy to the clipboard: %w", err)
+		}
+		sm.logger.Info(ctx, "Mindmap view copied to the clipboard", log.Fields{"nodeID": node.ID})
+		if lines := strings.Count(formattedView, "\n") + 1; lines > 1 {
+			return fmt.Sprintf("Mindmap view copied to the clipboard (%d lines)", lines), nil
+		}
+		return "Mindmap view copied to the clipboard (1 line)", nil
+	}
+
 	sm.logger.Info(ctx, "Mindmap view generated successfully", log.Fields{"nodeID": node.ID})
 	return formattedView, nil
 }
 
-// formatNodeForDisplay is a helper function to format a node and its children for display
-// This is a placeholder implementation
-func formatNodeForDisplay(node *model.Node, showID bool) string {
-	// TODO: remove and implement in node_handler or a different package
-	return fmt.Sprintf("Node: %s (ID: %d)", node.Name, node.ID)
+// defaultLinkCheckTimeout is the time allowed for each URL to respond in a link check
+const defaultLinkCheckTimeout = 10 * time.Second
+
+// handleMindmapCheck handles the mindmap check command, which reports the broken references of the current mindmap
+func handleMindmapCheck(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap check command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 || len(cmd.Args) > 5 || cmd.Args[0] != "links" {
+		sm.logger.Error(ctx, "Invalid arguments for mindmap check", log.Fields{"args": cmd.Args})
+		return nil, errors.New("mindmap check command requires 1 to 5 arguments: links [--http] [--timeout <seconds>] [--id]")
+	}
+
+	options := model.LinkCheckOptions{Timeout: defaultLinkCheckTimeout, Progress: session.Progress}
+	showID := false
+	for i := 1; i < len(cmd.Args); i++ {
+		switch cmd.Args[i] {
+		case "--http":
+			options.HTTP = true
+		case "--id":
+			showID = true
+		case "--timeout":
+			i++
+			if i == len(cmd.Args) {
+				return nil, errors.New("--timeout requires a number of seconds")
+			}
+			seconds, err := strconv.Atoi(cmd.Args[i])
+			if err != nil || seconds <= 0 {
+				return nil, fmt.Errorf("invalid timeout: %s", cmd.Args[i])
+			}
+			options.Timeout = time.Duration(seconds) * time.Second
+		default:
+			return nil, fmt.Errorf("unknown option: %s", cmd.Args[i])
+		}
+	}
+
+	broken, err := sm.dataManager.LinkCheck(session.Mindmap, options)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to check links", log.Fields{"error": err, "mindmapID": session.Mindmap.ID})
+		return nil, fmt.Errorf("failed to check links: %w", err)
+	}
+
+	sm.logger.Info(ctx, "Mindmap links checked", log.Fields{"mindmapID": session.Mindmap.ID, "broken": len(broken)})
+	if len(broken) == 0 {
+		return fmt.Sprintf("No broken references in %s", session.Mindmap.Name), nil
+	}
+
+	lines := []string{fmt.Sprintf("%d broken references in %s:", len(broken), session.Mindmap.Name)}
+	for _, ref := range broken {
+		location := fmt.Sprintf("deleted node (ID: %d)", ref.SourceID)
+		if node := session.Mindmap.Nodes[ref.SourceID]; node != nil {
+			location = sm.nodeIndex(node) + " " + node.Name
+			if showID {
+				location += fmt.Sprintf(" (ID: %d)", node.ID)
+			}
+		}
+		if ref.Field != "" {
+			location += ", field " + ref.Field
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s %s: %s", location, ref.Kind, ref.Target, ref.Problem))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// defaultChangesAge is how far back mindmap changes looks without --since when the mindmap was not visited before
+const defaultChangesAge = 7 * 24 * time.Hour
+
+// handleMindmapChanges handles the mindmap changes command, listing the node changes made to the current mindmap
+// since a time, by default since the previous time the user selected it
+func handleMindmapChanges(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap changes command", log.Fields{"args": cmd.Args})
+
+	audit := sm.dataManager.AuditManager
+	mindmap := session.Mindmap
+	now := time.Now()
+
+	var since time.Time
+	switch {
+	case len(cmd.Args) == 2 && cmd.Args[0] == "--since":
+		var err error
+		if since, err = model.ParseSince(cmd.Args[1], now); err != nil {
+			sm.logger.Warn(ctx, "Invalid mindmap changes time", log.Fields{"error": err})
+			return nil, err
+		}
+	case len(cmd.Args) == 0:
+		since = now.Add(-defaultChangesAge)
+		// The last entry is the selection of this visit, the one before it ends the previous visit
+		visits, err := audit.AuditFind(
+			model.AuditEntry{Username: session.User.Username, Scope: "mindmap", Operation: "select", MindmapID: mindmap.ID, Result: model.AuditResultSuccess},
+			model.AuditFilter{Username: true, Scope: true, Operation: true, MindmapID: true, Result: true}, 2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the last visit: %w", err)
+		}
+		if len(visits) == 2 {
+			since = visits[0].Timestamp
+		}
+	default:
+		sm.logger.Error(ctx, "Invalid arguments for mindmap changes", log.Fields{"args": cmd.Args})
+		return nil, errors.New("mindmap changes command accepts at most 2 arguments: [--since <age|date>]")
+	}
+
+	entries, err := audit.AuditFind(
+		model.AuditEntry{Scope: "node", MindmapID: mindmap.ID, Result: model.AuditResultSuccess, Timestamp: since},
+		model.AuditFilter{Scope: true, MindmapID: true, Result: true, Since: true}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mindmap changes: %w", err)
+	}
+	changes := slices.DeleteFunc(entries, func(e *model.AuditEntry) bool {
+		return !isMutatingCommand(model.Command{Scope: e.Scope, Operation: e.Operation})
+	})
+
+	sm.logger.Info(ctx, "Mindmap changes retrieved", log.Fields{"mindmapID": mindmap.ID, "count": len(changes)})
+	return formatMindmapChanges(mindmap, since, changes), nil
+}
+
+// formatMindmapChanges formats the node changes of a mindmap for display, one change per line with its author
+func formatMindmapChanges(mindmap *model.Mindmap, since time.Time, changes []*model.AuditEntry) string {
+	header := fmt.Sprintf("changes to '%s' since %s", mindmap.Name, since.Format("2006-01-02 15:04"))
+	if len(changes) == 0 {
+		return "No " + header
+	}
+
+	lines := []string{fmt.Sprintf("%d %s:", len(changes), header)}
+	for _, e := range changes {
+		line := fmt.Sprintf("%s  %-10s %s", e.Timestamp.Format("2006-01-02 15:04:05"), e.Username, e.Operation)
+		if e.Target != "" {
+			line += " " + e.Target
+		}
+		if e.Detail != "" {
+			line += " => " + e.Detail
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleMindmapCompare handles the mindmap compare command
+func handleMindmapCompare(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap compare command", log.Fields{"args": cmd.Args})
+
+	var names []string
+	similarity := false
+	for _, arg := range cmd.Args {
+		if arg == "--similarity" {
+			similarity = true
+		} else {
+			names = append(names, arg)
+		}
+	}
+	if len(names) != 2 {
+		sm.logger.Error(ctx, "Invalid arguments for mindmap compare", log.Fields{"args": cmd.Args})
+		return nil, errors.New("mindmap compare command requires 2 mindmap names: <mindmap_a> <mindmap_b> [--similarity]")
+	}
+
+	mindmaps := make([]*model.Mindmap, len(names))
+	for i, name := range names {
+		found, err := sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{Name: name}, model.MindmapFilter{Name: true})
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to get mindmap", log.Fields{"error": err, "mindmapName": name})
+			return nil, fmt.Errorf("failed to get mindmap: %w", err)
+		}
+		if len(found) == 0 {
+			sm.logger.Warn(ctx, "Mindmap not found", log.Fields{"mindmapName": name})
+			return nil, fmt.Errorf("mindmap not found: %s", name)
+		}
+		mindmaps[i] = found[0]
+	}
+
+	comparison, err := sm.dataManager.MindmapCompare(session.User, mindmaps[0], mindmaps[1])
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to compare mindmaps", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to compare mindmaps: %w", err)
+	}
+
+	sm.logger.Info(ctx, "Mindmaps compared successfully", log.Fields{"mindmapA": names[0], "mindmapB": names[1]})
+	return formatMindmapComparison(comparison, similarity), nil
+}
+
+// formatMindmapComparison formats the comparison of two mindmaps, with the similarity scores if requested
+func formatMindmapComparison(c model.MindmapComparison, similarity bool) string {
+	lines := []string{fmt.Sprintf("%d of %d nodes of '%s' are in '%s' (%d nodes), %d of them under another parent", c.Shared, c.NodesA, c.A, c.B, c.NodesB, c.Moved)}
+	if len(c.OnlyA) > 0 {
+		lines = append(lines, fmt.Sprintf("Only in '%s': %s", c.A, strings.Join(c.OnlyA, ", ")))
+	}
+	if len(c.OnlyB) > 0 {
+		lines = append(lines, fmt.Sprintf("Only in '%s': %s", c.B, strings.Join(c.OnlyB, ", ")))
+	}
+	if similarity {
+		lines = append(lines, fmt.Sprintf("Similarity: names %.2f, text %.2f, structure %.2f (edit distance %d)", c.NameSimilarity, c.TextSimilarity, c.StructureSimilarity, c.EditDistance))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleMindmapReindex handles the mindmap reindex command
+func handleMindmapReindex(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap reindex command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) != 0 {
+		sm.logger.Error(ctx, "Invalid number of arguments for mindmap reindex", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("mindmap reindex command takes no arguments")
+	}
+
+	// Reindexing changes the loaded nodes, so even as a background job it runs on the executor as a whole
+	mindmap := session.Mindmap
+	return sm.jobRun(session, cmd, func(run jobRunner) (interface{}, error) {
+		return run.exclusive(func() (interface{}, error) {
+			result, err := sm.dataManager.NodeManager.NodeReindex(mindmap)
+			if err != nil {
+				sm.logger.Error(ctx, "Failed to reindex mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+				return nil, fmt.Errorf("failed to reindex mindmap: %w", err)
+			}
+			if err := sm.dataManager.MindmapManager.MindmapRevise(mindmap); err != nil {
+				sm.logger.Warn(ctx, "Reindex not recorded as a change", log.Fields{"error": err, "mindmapID": mindmap.ID})
+			}
+
+			sm.logger.Info(ctx, "Mindmap reindexed", log.Fields{"mindmapID": mindmap.ID, "renumbered": result.Renumbered})
+			return fmt.Sprintf("Mindmap '%s' reindexed: %d nodes, %d renumbered, %d mismatched between memory and storage",
+				mindmap.Name, result.Nodes, result.Renumbered, result.Mismatched), nil
+		})
+	})
+}
+
+// handleMindmapSet handles the mindmap set command, which sets the settings of the current mindmap: its default
+// sort, the order its nodes are shown in without changing their indexes
+func handleMindmapSet(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap set command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 2 || cmd.Args[0] != "sort" {
+		sm.logger.Error(ctx, "Invalid arguments for mindmap set", log.Fields{"args": cmd.Args})
+		return nil, errors.New("mindmap set command requires 2 to 7 arguments: sort <field>|none [asc|desc] [auto|text|number|date] [natural] [nocase] [locale:<tag>]")
+	}
+
+	var order model.SortSpec
+	if len(cmd.Args) != 2 || !strings.EqualFold(cmd.Args[1], "none") {
+		var err error
+		if order, err = model.ParseSortSpec(cmd.Args[1:]); err != nil {
+			sm.logger.Warn(ctx, "Invalid mindmap sort", log.Fields{"error": err, "args": cmd.Args})
+			return nil, err
+		}
+	}
+
+	mindmap := session.Mindmap
+	if err := sm.dataManager.MindmapManager.MindmapUpdate(session.User, mindmap, model.MindmapInfo{Sort: order}, model.MindmapFilter{Sort: true}); err != nil {
+		sm.logger.Error(ctx, "Failed to update mindmap sort", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to set mindmap sort: %w", err)
+	}
+
+	sm.logger.Info(ctx, "Mindmap sort set", log.Fields{"mindmapID": mindmap.ID, "sort": order.String()})
+	if order.IsZero() {
+		return fmt.Sprintf("Mindmap '%s' is shown in index order", mindmap.Name), nil
+	}
+	return fmt.Sprintf("Mindmap '%s' is shown sorted by %s", mindmap.Name, order), nil
+}
+
+// handleMindmapGraph handles the mindmap graph command, which emits the graph of the cross-links of the current
+// mindmap for graph tools, to the output or to a file
+func handleMindmapGraph(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap graph command", log.Fields{"args": cmd.Args})
+
+	format := model.GraphFormatDOT
+	filename := ""
+	tree, force := false, false
+	for i := 0; i < len(cmd.Args); i++ {
+		switch arg := cmd.Args[i]; {
+		case arg == "--tree":
+			tree = true
+		case arg == "--force":
+			force = true
+		case arg == "--format":
+			i++
+			if i == len(cmd.Args) {
+				return nil, fmt.Errorf("--format requires %s or %s", model.GraphFormatDOT, model.GraphFormatJSON)
+			}
+			format = cmd.Args[i]
+		case strings.HasPrefix(arg, "--"):
+			sm.logger.Error(ctx, "Invalid option for mindmap graph", log.Fields{"option": arg})
+			return nil, fmt.Errorf("unknown option: %s", arg)
+		case filename == "":
+			filename = arg
+		default:
+			return nil, errors.New("mindmap graph command accepts at most 1 filename: [filename] [--format dot|json] [--tree] [--force]")
+		}
+	}
+	if format != model.GraphFormatDOT && format != model.GraphFormatJSON {
+		return nil, fmt.Errorf("invalid graph format '%s': must be %s or %s", format, model.GraphFormatDOT, model.GraphFormatJSON)
+	}
+
+	graph, err := sm.dataManager.LinkGraph(session.Mindmap, tree)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to build link graph", log.Fields{"error": err, "mindmapID": session.Mindmap.ID})
+		return nil, fmt.Errorf("failed to build link graph: %w", err)
+	}
+	encoded, err := data.EncodeLinkGraph(graph, format, sm.DisplayStyle())
+	if err != nil {
+		return nil, err
+	}
+	if filename == "" {
+		return strings.TrimSuffix(string(encoded), "\n"), nil
+	}
+
+	path, err := sm.dataManager.ExportPath(session.Mindmap, filename, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := sm.dataManager.ExportWrite(path, encoded, force); err != nil {
+		return nil, err
+	}
+	sm.logger.Info(ctx, "Link graph exported", log.Fields{"mindmapID": session.Mindmap.ID, "path": path})
+	return fmt.Sprintf("Link graph of %d nodes and %d edges written to %s", len(graph.Nodes), len(graph.Edges), path), nil
+}
+
+// handleMindmapExists handles the mindmap exists command, printing true or false for scripts
+func handleMindmapExists(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap exists command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) != 1 {
+		sm.logger.Error(ctx, "Invalid number of arguments for mindmap exists", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("mindmap exists command requires 1 argument: <mindmap_name>")
+	}
+
+	exists, err := sm.dataManager.MindmapManager.MindmapExists(session.User, cmd.Args[0])
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to check mindmap existence", log.Fields{"error": err, "mindmapName": cmd.Args[0]})
+		return nil, fmt.Errorf("failed to check mindmap: %w", err)
+	}
+	return strconv.FormatBool(exists), nil
 }
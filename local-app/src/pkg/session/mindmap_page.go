@@ -0,0 +1,73 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// MindmapsVisible returns the mindmaps the user of a session may select, their own and the public ones, without
+// their nodes
+func (sm *SessionManager) MindmapsVisible(sessionID string) ([]*model.Mindmap, error) {
+	result, err := sm.runTask(func() (interface{}, error) {
+		return sm.visibleMindmaps(sessionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*model.Mindmap), nil
+}
+
+// MindmapPage renders the mindmap selected in a session as a read-only web page, with a search box submitting to
+// search and the nodes found by query, as node find finds them, if query is not empty
+func (sm *SessionManager) MindmapPage(sessionID, search, query string) ([]byte, error) {
+	result, err := sm.runTask(func() (interface{}, error) {
+		ctx := context.Background()
+		session, exists := sm.SessionGet(sessionID)
+		if !exists || session.User == nil {
+			return nil, errors.New("no user selected")
+		}
+		if session.Mindmap == nil {
+			return nil, errors.New("no mindmap selected")
+		}
+		if err := sm.nodesLoadAll(session); err != nil {
+			return nil, err
+		}
+
+		view := storage.HTMLView{Search: search, Query: query}
+		if query != "" {
+			nodes, err := sm.dataManager.NodeManager.NodeFind(session.Mindmap, model.NodeFilter{Name: true, Content: true}, query)
+			switch {
+			case err != nil:
+				sm.logger.Warn(ctx, "Failed to find nodes for mindmap page", log.Fields{"error": err, "query": query})
+				view.Message = err.Error()
+			case len(nodes) == 0:
+				view.Message = fmt.Sprintf("No nodes found matching '%s'", query)
+			default:
+				view.Matches = nodes
+			}
+		}
+		return storage.EncodeHTMLView(session.Mindmap, sm.DisplayStyle(), view)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
+// visibleMindmaps returns the mindmaps the user of a session may select. It runs on the command executor.
+func (sm *SessionManager) visibleMindmaps(sessionID string) ([]*model.Mindmap, error) {
+	session, exists := sm.SessionGet(sessionID)
+	if !exists || session.User == nil {
+		return nil, errors.New("no user selected")
+	}
+	mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{}, model.MindmapFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mindmaps: %w", err)
+	}
+	return mindmaps, nil
+}
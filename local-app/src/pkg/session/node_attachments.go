@@ -0,0 +1,204 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// handleNodeAttach handles the node attach command: attaching files of the export directory and web addresses to a
+// node, listing its attachments, saving an attached file back to the export directory and removing attachments
+func handleNodeAttach(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node attach command", log.Fields{"args": cmd.Args})
+
+	usage := "node attach command requires: <node> [<file-or-url>...] | <node> --remove <name> | <node> --save <name> [<file>] [--force], [--id]"
+	var args []string
+	var remove, save string
+	useID, force := false, false
+	for i := 0; i < len(cmd.Args); i++ {
+		switch arg := cmd.Args[i]; arg {
+		case "--id":
+			useID = true
+		case "--force":
+			force = true
+		case "--remove", "--save":
+			if i+1 >= len(cmd.Args) {
+				sm.logger.Error(ctx, "Missing attachment name for node attach", log.Fields{"args": cmd.Args})
+				return nil, fmt.Errorf("%s requires the name of an attachment", arg)
+			}
+			if arg == "--remove" {
+				remove = cmd.Args[i+1]
+			} else {
+				save = cmd.Args[i+1]
+			}
+			i++
+		default:
+			args = append(args, arg)
+		}
+	}
+	switch {
+	case len(args) == 0, remove != "" && save != "", remove != "" && len(args) > 1, save != "" && len(args) > 2:
+		sm.logger.Error(ctx, "Invalid arguments for node attach", log.Fields{"args": cmd.Args})
+		return nil, errors.New(usage)
+	}
+
+	node, err := getNode(sm, session.Mindmap, args[0], useID)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get node", log.Fields{"error": err, "nodeIdentifier": args[0]})
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	index := sm.nodeIndex(node)
+
+	switch {
+	case remove != "":
+		removed, err := sm.dataManager.NodeManager.NodeAttachmentDelete(session.Mindmap, node, remove)
+		if err != nil {
+			return nil, err
+		}
+		if !removed {
+			return fmt.Sprintf("Node %s has no attachment %s", index, remove), nil
+		}
+		sm.logger.Info(ctx, "Node attachment removed", log.Fields{"nodeID": node.ID, "name": remove})
+		return fmt.Sprintf("Removed attachment %s from node %s", remove, index), nil
+
+	case save != "":
+		filename := save
+		if len(args) == 2 {
+			filename = args[1]
+		}
+		return sm.attachmentSave(session, node, save, filename, force)
+
+	case len(args) == 1:
+		attachments, err := sm.dataManager.NodeManager.NodeAttachments(session.Mindmap, node, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(attachments) == 0 {
+			return fmt.Sprintf("Node %s has no attachments", index), nil
+		}
+		return fmt.Sprintf("Attachments of node %s:\n%s", index, formatAttachments(attachments)), nil
+	}
+
+	// Every source is read before anything is attached, so that a missing file attaches none
+	var attachments []model.Attachment
+	for _, source := range args[1:] {
+		attachment, err := sm.dataManager.AttachmentRead(source)
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to read attachment", log.Fields{"error": err, "source": source})
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	var added, present []string
+	err = sm.dataManager.NodeBatch(session.Mindmap, func() error {
+		for _, attachment := range attachments {
+			ok, err := sm.dataManager.NodeManager.NodeAttachmentAdd(session.Mindmap, node, attachment)
+			if err != nil {
+				return err
+			}
+			if ok {
+				added = append(added, attachment.Name)
+			} else {
+				present = append(present, attachment.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to attach to node", log.Fields{"error": err, "nodeID": node.ID})
+		return nil, fmt.Errorf("failed to attach: %w", err)
+	}
+
+	var result []string
+	if len(added) > 0 {
+		result = append(result, fmt.Sprintf("Attached %s to node %s", strings.Join(added, ", "), index))
+	}
+	if len(present) > 0 {
+		result = append(result, fmt.Sprintf("Node %s already has %s attached, remove it first to replace it", index, strings.Join(present, ", ")))
+	}
+
+	sm.logger.Info(ctx, "Node attach command completed", log.Fields{"nodeID": node.ID, "added": len(added)})
+	return strings.Join(result, "\n"), nil
+}
+
+// attachmentSave writes a file attached to a node to a file of the export directory
+func (sm *SessionManager) attachmentSave(session *model.Session, node *model.Node, name, filename string, force bool) (interface{}, error) {
+	ctx := context.Background()
+
+	attachments, err := sm.dataManager.NodeManager.NodeAttachments(session.Mindmap, node, true)
+	if err != nil {
+		return nil, err
+	}
+	var attachment *model.Attachment
+	for _, a := range attachments {
+		if a.Name == name {
+			attachment = a
+		}
+	}
+	switch {
+	case attachment == nil:
+		return nil, fmt.Errorf("node %s has no attachment %s", sm.nodeIndex(node), name)
+	case attachment.URL != "":
+		return nil, fmt.Errorf("attachment %s is a reference to a web address, not a file", name)
+	}
+
+	path, err := sm.dataManager.ImportPath(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := sm.dataManager.ExportWrite(path, attachment.Data, force); err != nil {
+		return nil, err
+	}
+	sm.logger.Info(ctx, "Node attachment saved", log.Fields{"nodeID": node.ID, "name": name, "path": path})
+	return fmt.Sprintf("Attachment %s of node %s saved to %s", name, sm.nodeIndex(node), path), nil
+}
+
+// formatAttachments lists attachments with the size and media type of stored files
+func formatAttachments(attachments []*model.Attachment) string {
+	lines := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		if a.URL != "" {
+			lines = append(lines, "  "+a.Name)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %s (%s, %s)", a.Name, formatSize(a.Size), a.MediaType))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatSize formats a size in bytes for display, such as "12 KiB"
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%d KiB", size>>10)
+	default:
+		return fmt.Sprintf("%d bytes", size)
+	}
+}
+
+// attachmentAnnotations returns the notes of the mindmap view naming the attachments of each node of the selected
+// mindmap, such as "[attached: report.pdf, https://example.com]"
+func (sm *SessionManager) attachmentAnnotations(session *model.Session) (map[int]string, error) {
+	attachments, err := sm.dataManager.NodeManager.NodeAttachments(session.Mindmap, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int][]string)
+	for _, a := range attachments {
+		names[a.NodeID] = append(names[a.NodeID], a.Name)
+	}
+	notes := make(map[int]string, len(names))
+	for id, n := range names {
+		notes[id] = "[attached: " + strings.Join(n, ", ") + "]"
+	}
+	return notes, nil
+}
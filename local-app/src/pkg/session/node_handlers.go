@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 
+	"mindnoscape/local-app/src/pkg/data"
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
 )
 
 // handleNodeAdd handles the node add command
@@ -21,11 +25,6 @@ func handleNodeAdd(sm *SessionManager, session *model.Session, cmd model.Command
 		return nil, errors.New("node add command requires at least 2 arguments: <parent> <content> [<extra field label>:<extra field value>]... [--id]")
 	}
 
-	if session.Mindmap == nil {
-		sm.logger.Error(ctx, "No mindmap selected", nil)
-		return nil, fmt.Errorf("no mindmap selected")
-	}
-
 	parentIdentifier := cmd.Args[0]
 	content := cmd.Args[1]
 	extraFields := make(map[string]string)
@@ -36,6 +35,10 @@ func handleNodeAdd(sm *SessionManager, session *model.Session, cmd model.Command
 			useID = true
 		} else if strings.Contains(arg, ":") {
 			parts := strings.SplitN(arg, ":", 2)
+			if err := names.Validate(names.Field, parts[0]); err != nil {
+				sm.logger.Error(ctx, "Invalid field name", log.Fields{"error": err, "field": parts[0]})
+				return nil, err
+			}
 			extraFields[parts[0]] = parts[1]
 		}
 	}
@@ -63,6 +66,9 @@ func handleNodeAdd(sm *SessionManager, session *model.Session, cmd model.Command
 	}
 
 	sm.logger.Info(ctx, "Node added successfully", log.Fields{"nodeID": nodeID})
+	if note := updateWikiLinks(sm, session, session.Mindmap, session.Mindmap.Nodes[nodeID]); note != "" {
+		return fmt.Sprintf("%d\n%s", nodeID, note), nil
+	}
 	return nodeID, nil
 }
 
@@ -76,11 +82,6 @@ func handleNodeUpdate(sm *SessionManager, session *model.Session, cmd model.Comm
 		return nil, errors.New("node update command requires at least 2 arguments: <node> <content> [<extra field label>:<extra field value>]... [--id]")
 	}
 
-	if session.Mindmap == nil {
-		sm.logger.Error(ctx, "No mindmap selected", nil)
-		return nil, fmt.Errorf("no mindmap selected")
-	}
-
 	nodeIdentifier := cmd.Args[0]
 	content := cmd.Args[1]
 	extraFields := make(map[string]string)
@@ -91,6 +92,10 @@ func handleNodeUpdate(sm *SessionManager, session *model.Session, cmd model.Comm
 			useID = true
 		} else if strings.Contains(arg, ":") {
 			parts := strings.SplitN(arg, ":", 2)
+			if err := names.Validate(names.Field, parts[0]); err != nil {
+				sm.logger.Error(ctx, "Invalid field name", log.Fields{"error": err, "field": parts[0]})
+				return nil, err
+			}
 			extraFields[parts[0]] = parts[1]
 		}
 	}
@@ -102,10 +107,20 @@ func handleNodeUpdate(sm *SessionManager, session *model.Session, cmd model.Comm
 		return nil, fmt.Errorf("failed to get node: %w", err)
 	}
 
+	// The root node name is the mindmap name
+	if node.ID == 0 {
+		if err := names.Validate(names.Mindmap, content); err != nil {
+			sm.logger.Error(ctx, "Invalid mindmap name", log.Fields{"error": err, "mindmapName": content})
+			return nil, err
+		}
+	}
+
 	updateInfo := model.NodeInfo{
 		Name:    content,
 		Content: extraFields,
 	}
+	// The update changes the node in place, its old name and fields are kept for the diff of the change
+	oldName, oldContent := node.Name, maps.Clone(node.Content)
 
 	sm.logger.Debug(ctx, "Updating node", log.Fields{"nodeID": node.ID, "newContent": content})
 	err = sm.dataManager.NodeManager.NodeUpdate(session.Mindmap, node, updateInfo, model.NodeFilter{Name: true, Content: true})
@@ -115,7 +130,64 @@ func handleNodeUpdate(sm *SessionManager, session *model.Session, cmd model.Comm
 	}
 
 	sm.logger.Info(ctx, "Node updated successfully", log.Fields{"nodeID": node.ID})
-	return nil, nil
+	change := model.NodeChangeOf(sm.nodeIndex(node), oldName, node.Name, oldContent, node.Content)
+	change.Note = updateWikiLinks(sm, session, session.Mindmap, node)
+	return change, nil
+}
+
+// updateWikiLinks updates the wiki links of an added or updated node and returns a note on the references that
+// could not be linked, or an empty string. If the session can ask, it offers to create the nodes named by the
+// references to names of no node under the Inbox node, as node wikilink --create does.
+func updateWikiLinks(sm *SessionManager, session *model.Session, mindmap *model.Mindmap, node *model.Node) string {
+	if node == nil {
+		return ""
+	}
+	result, err := sm.dataManager.NodeWikiLink(mindmap, node, false, nil)
+	if err != nil {
+		sm.logger.Warn(context.Background(), "Failed to update wiki links", log.Fields{"error": err, "nodeID": node.ID})
+		return fmt.Sprintf("Links not updated: %v", err)
+	}
+	if len(result.Unresolved) == 0 && len(result.Ambiguous) == 0 {
+		return ""
+	}
+	if len(result.Unresolved) == 0 {
+		return formatWikiLinkProblems(result)
+	}
+
+	if session.Confirm != nil {
+		create, err := session.Confirm(fmt.Sprintf("No node named %s. Create under %s?", strings.Join(result.Unresolved, ", "), data.InboxName))
+		if err != nil {
+			sm.logger.Warn(context.Background(), "Failed to ask to create wiki link targets", log.Fields{"error": err, "nodeID": node.ID})
+		}
+		if create {
+			if result, err = sm.dataManager.NodeWikiLink(mindmap, node, true, nil); err != nil {
+				sm.logger.Warn(context.Background(), "Failed to create wiki link targets", log.Fields{"error": err, "nodeID": node.ID})
+				return fmt.Sprintf("Links not updated: %v", err)
+			}
+			var created []string
+			for _, n := range result.Created {
+				created = append(created, sm.nodeIndex(n)+" "+n.Name)
+			}
+			lines := []string{"Created and linked: " + strings.Join(created, ", ")}
+			if problems := formatWikiLinkProblems(result); problems != "" {
+				lines = append(lines, problems)
+			}
+			return strings.Join(lines, "\n")
+		}
+	}
+	return formatWikiLinkProblems(result) + fmt.Sprintf("\nUse 'node wikilink %s --create' to add the missing nodes under %s", sm.nodeIndex(node), data.InboxName)
+}
+
+// formatWikiLinkProblems lists the references of a wiki link result that were not linked
+func formatWikiLinkProblems(result *model.WikiLinkResult) string {
+	var lines []string
+	if len(result.Unresolved) > 0 {
+		lines = append(lines, "No node named: "+strings.Join(result.Unresolved, ", "))
+	}
+	if len(result.Ambiguous) > 0 {
+		lines = append(lines, "Several nodes named: "+strings.Join(result.Ambiguous, ", "))
+	}
+	return strings.Join(lines, "\n")
 }
 
 // handleNodeMove handles the node move command
@@ -123,21 +195,28 @@ func handleNodeMove(sm *SessionManager, session *model.Session, cmd model.Comman
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Handling node move command", log.Fields{"args": cmd.Args})
 
-	if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+	if len(cmd.Args) < 2 || len(cmd.Args) > 4 {
 		sm.logger.Error(ctx, "Invalid number of arguments for node move", log.Fields{"argCount": len(cmd.Args)})
-		return nil, errors.New("node move command requires 2 or 3 arguments: <source> <target> [--id]")
-	}
-
-	if session.Mindmap == nil {
-		sm.logger.Error(ctx, "No mindmap selected", nil)
-		return nil, fmt.Errorf("no mindmap selected")
+		return nil, errors.New("node move command requires 2 to 4 arguments: <source> <target> [--id] [--preview]")
 	}
 
 	sourceIdentifier := cmd.Args[0]
 	targetIdentifier := cmd.Args[1]
-	useID := len(cmd.Args) == 3 && cmd.Args[2] == "--id"
+	useID := false
+	preview := false
+	for _, arg := range cmd.Args[2:] {
+		switch arg {
+		case "--id":
+			useID = true
+		case "--preview":
+			preview = true
+		default:
+			sm.logger.Error(ctx, "Invalid option for node move", log.Fields{"option": arg})
+			return nil, fmt.Errorf("invalid option for node move: %s", arg)
+		}
+	}
 
-	sm.logger.Debug(ctx, "Parsing node move arguments", log.Fields{"sourceIdentifier": sourceIdentifier, "targetIdentifier": targetIdentifier, "useID": useID})
+	sm.logger.Debug(ctx, "Parsing node move arguments", log.Fields{"sourceIdentifier": sourceIdentifier, "targetIdentifier": targetIdentifier, "useID": useID, "preview": preview})
 
 	sourceNode, err := getNode(sm, session.Mindmap, sourceIdentifier, useID)
 	if err != nil {
@@ -151,12 +230,17 @@ func handleNodeMove(sm *SessionManager, session *model.Session, cmd model.Comman
 		return nil, fmt.Errorf("failed to get target node: %w", err)
 	}
 
-	updateInfo := model.NodeInfo{
-		ParentID: targetNode.ID,
+	if preview {
+		previewMindmap, err := sm.dataManager.NodeManager.NodeMovePreview(session.Mindmap, sourceNode, targetNode, -1)
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to preview node move", log.Fields{"error": err, "sourceNodeID": sourceNode.ID, "targetNodeID": targetNode.ID})
+			return nil, fmt.Errorf("failed to preview node move: %w", err)
+		}
+		return formatPreview("node move", session.Mindmap, previewMindmap, session.Mindmap.Root, sm.DisplayStyle(), useID), nil
 	}
 
 	sm.logger.Debug(ctx, "Moving node", log.Fields{"sourceNodeID": sourceNode.ID, "targetNodeID": targetNode.ID})
-	err = sm.dataManager.NodeManager.NodeUpdate(session.Mindmap, sourceNode, updateInfo, model.NodeFilter{ParentID: true})
+	err = sm.dataManager.NodeManager.NodeMove(session.Mindmap, sourceNode, targetNode, -1)
 	if err != nil {
 		sm.logger.Error(ctx, "Failed to move node", log.Fields{"error": err, "sourceNodeID": sourceNode.ID, "targetNodeID": targetNode.ID})
 		return nil, fmt.Errorf("failed to move node: %w", err)
@@ -166,111 +250,587 @@ func handleNodeMove(sm *SessionManager, session *model.Session, cmd model.Comman
 	return nil, nil
 }
 
-// handleNodeDelete handles the node delete command
-func handleNodeDelete(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+// handleNodeCopy handles the node copy command
+func handleNodeCopy(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
 	ctx := context.Background()
-	sm.logger.Info(ctx, "Handling node delete command", log.Fields{"args": cmd.Args})
+	sm.logger.Info(ctx, "Handling node copy command", log.Fields{"args": cmd.Args})
 
-	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
-		sm.logger.Error(ctx, "Invalid number of arguments for node delete", log.Fields{"argCount": len(cmd.Args)})
-		return nil, errors.New("node delete command requires 1 or 2 arguments: <node> [--id]")
+	if len(cmd.Args) < 2 || len(cmd.Args) > 4 {
+		sm.logger.Error(ctx, "Invalid number of arguments for node copy", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("node copy command requires 2 to 4 arguments: <source> <target> [--id] [--force]")
+	}
+
+	sourceIdentifier := cmd.Args[0]
+	targetIdentifier := cmd.Args[1]
+	useID := false
+	force := false
+	for _, arg := range cmd.Args[2:] {
+		switch arg {
+		case "--id":
+			useID = true
+		case "--force":
+			force = true
+		default:
+			sm.logger.Error(ctx, "Invalid option for node copy", log.Fields{"option": arg})
+			return nil, fmt.Errorf("invalid option for node copy: %s", arg)
+		}
+	}
+
+	sourceNode, err := getNode(sm, session.Mindmap, sourceIdentifier, useID)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get source node", log.Fields{"error": err, "sourceIdentifier": sourceIdentifier})
+		return nil, fmt.Errorf("failed to get source node: %w", err)
 	}
 
-	if session.Mindmap == nil {
-		sm.logger.Error(ctx, "No mindmap selected", nil)
-		return nil, fmt.Errorf("no mindmap selected")
+	targetNode, err := getNode(sm, session.Mindmap, targetIdentifier, useID)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get target node", log.Fields{"error": err, "targetIdentifier": targetIdentifier})
+		return nil, fmt.Errorf("failed to get target node: %w", err)
+	}
+
+	// Refuse to copy large subtrees by accident
+	if err := sm.dataManager.NodeManager.NodeOperationCheck(session.Mindmap, sourceNode, "copy", force); err != nil {
+		return nil, err
+	}
+
+	sm.logger.Debug(ctx, "Copying node", log.Fields{"sourceNodeID": sourceNode.ID, "targetNodeID": targetNode.ID})
+	var copied *model.Node
+	var count int
+	err = sm.dataManager.NodeBatch(session.Mindmap, func() error {
+		copied, count, err = sm.dataManager.NodeManager.NodeCopy(session.Mindmap, sourceNode, targetNode)
+		return err
+	})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to copy node", log.Fields{"error": err, "sourceNodeID": sourceNode.ID, "targetNodeID": targetNode.ID})
+		return nil, fmt.Errorf("failed to copy node: %w", err)
+	}
+
+	sm.logger.Info(ctx, "Node copied successfully", log.Fields{"sourceNodeID": sourceNode.ID, "copyID": copied.ID, "nodeCount": count})
+	if count == 1 {
+		return fmt.Sprintf("Copied 1 node to %s", sm.nodeIndex(copied)), nil
+	}
+	return fmt.Sprintf("Copied %d nodes to %s", count, sm.nodeIndex(copied)), nil
+}
+
+// handleNodeIndent handles the node indent command
+func handleNodeIndent(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	return handleNodeLevel(sm, session, cmd, sm.dataManager.NodeManager.NodeIndent)
+}
+
+// handleNodeOutdent handles the node outdent command
+func handleNodeOutdent(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	return handleNodeLevel(sm, session, cmd, sm.dataManager.NodeManager.NodeOutdent)
+}
+
+// handleNodeLevel moves the node of an indent or outdent command one level with the given operation
+func handleNodeLevel(sm *SessionManager, session *model.Session, cmd model.Command, operation func(*model.Mindmap, *model.Node) error) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node "+cmd.Operation+" command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		sm.logger.Error(ctx, "Invalid number of arguments for node "+cmd.Operation, log.Fields{"argCount": len(cmd.Args)})
+		return nil, fmt.Errorf("node %s command requires 1 or 2 arguments: <node> [--id]", cmd.Operation)
 	}
 
 	nodeIdentifier := cmd.Args[0]
 	useID := len(cmd.Args) == 2 && cmd.Args[1] == "--id"
 
-	sm.logger.Debug(ctx, "Parsing node delete arguments", log.Fields{"nodeIdentifier": nodeIdentifier, "useID": useID})
-
 	node, err := getNode(sm, session.Mindmap, nodeIdentifier, useID)
 	if err != nil {
 		sm.logger.Error(ctx, "Failed to get node", log.Fields{"error": err, "nodeIdentifier": nodeIdentifier})
 		return nil, fmt.Errorf("failed to get node: %w", err)
 	}
 
-	sm.logger.Debug(ctx, "Deleting node", log.Fields{"nodeID": node.ID})
-	err = sm.dataManager.NodeManager.NodeDelete(session.Mindmap, node)
+	if err := operation(session.Mindmap, node); err != nil {
+		sm.logger.Error(ctx, "Failed to "+cmd.Operation+" node", log.Fields{"error": err, "nodeID": node.ID})
+		return nil, fmt.Errorf("failed to %s node: %w", cmd.Operation, err)
+	}
+
+	sm.logger.Info(ctx, "Node "+cmd.Operation+" completed successfully", log.Fields{"nodeID": node.ID, "index": node.Index})
+	return fmt.Sprintf("Node moved to %s", sm.nodeIndex(node)), nil
+}
+
+// handleNodeSwap handles the node swap command
+func handleNodeSwap(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node swap command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+		sm.logger.Error(ctx, "Invalid number of arguments for node swap", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("node swap command requires 2 or 3 arguments: <node> <node> [--id]")
+	}
+
+	useID := len(cmd.Args) == 3 && cmd.Args[2] == "--id"
+
+	nodes := make([]*model.Node, 2)
+	for i, identifier := range cmd.Args[:2] {
+		node, err := getNode(sm, session.Mindmap, identifier, useID)
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to get node", log.Fields{"error": err, "nodeIdentifier": identifier})
+			return nil, fmt.Errorf("failed to get node: %w", err)
+		}
+		nodes[i] = node
+	}
+
+	if err := sm.dataManager.NodeManager.NodeSwap(session.Mindmap, nodes[0], nodes[1]); err != nil {
+		sm.logger.Error(ctx, "Failed to swap nodes", log.Fields{"error": err, "nodeID": nodes[0].ID, "otherNodeID": nodes[1].ID})
+		return nil, fmt.Errorf("failed to swap nodes: %w", err)
+	}
+
+	sm.logger.Info(ctx, "Nodes swapped successfully", log.Fields{"nodeID": nodes[0].ID, "otherNodeID": nodes[1].ID})
+	return nil, nil
+}
+
+// handleNodeRotate handles the node rotate command
+func handleNodeRotate(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node rotate command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 || len(cmd.Args) > 3 {
+		sm.logger.Error(ctx, "Invalid number of arguments for node rotate", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("node rotate command requires 1 to 3 arguments: <parent> [n] [--id]")
+	}
+
+	parentIdentifier := cmd.Args[0]
+	n := 1
+	useID := false
+	for _, arg := range cmd.Args[1:] {
+		if arg == "--id" {
+			useID = true
+			continue
+		}
+		count, err := strconv.Atoi(arg)
+		if err != nil {
+			sm.logger.Error(ctx, "Invalid rotation count", log.Fields{"error": err, "count": arg})
+			return nil, fmt.Errorf("invalid rotation count: %s", arg)
+		}
+		n = count
+	}
+
+	parent, err := getNode(sm, session.Mindmap, parentIdentifier, useID)
 	if err != nil {
-		sm.logger.Error(ctx, "Failed to delete node", log.Fields{"error": err, "nodeID": node.ID})
-		return nil, fmt.Errorf("failed to delete node: %w", err)
+		sm.logger.Error(ctx, "Failed to get node", log.Fields{"error": err, "nodeIdentifier": parentIdentifier})
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	if err := sm.dataManager.NodeManager.NodeRotate(session.Mindmap, parent, n); err != nil {
+		sm.logger.Error(ctx, "Failed to rotate children", log.Fields{"error": err, "nodeID": parent.ID})
+		return nil, fmt.Errorf("failed to rotate children: %w", err)
 	}
 
-	sm.logger.Info(ctx, "Node deleted successfully", log.Fields{"nodeID": node.ID})
+	sm.logger.Info(ctx, "Children rotated successfully", log.Fields{"nodeID": parent.ID, "n": n})
 	return nil, nil
 }
 
+// handleNodeDelete handles the node delete command
+func handleNodeDelete(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node delete command", log.Fields{"args": cmd.Args})
+
+	var selectors []string
+	useID := false
+	force := false
+	for _, arg := range cmd.Args {
+		switch {
+		case arg == "--id":
+			useID = true
+		case arg == "--force":
+			force = true
+		case strings.HasPrefix(arg, "--"):
+			sm.logger.Error(ctx, "Invalid option for node delete", log.Fields{"option": arg})
+			return nil, fmt.Errorf("invalid option for node delete: %s", arg)
+		default:
+			selectors = append(selectors, arg)
+		}
+	}
+	if len(selectors) == 0 {
+		sm.logger.Error(ctx, "No node specified for node delete", nil)
+		return nil, errors.New("node delete command requires at least 1 node: <node>... [--id] [--force]")
+	}
+
+	sm.logger.Debug(ctx, "Parsing node delete arguments", log.Fields{"selectors": selectors, "useID": useID, "force": force})
+
+	selected, err := selectNodes(sm, session.Mindmap, selectors, useID)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to select nodes", log.Fields{"error": err, "selectors": selectors})
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	// Nodes in the subtree of another selected node are deleted along with it
+	nodes := topmostNodes(session.Mindmap, selected)
+	for _, node := range nodes {
+		if node.ID == 0 {
+			return nil, errors.New("cannot delete root node")
+		}
+	}
+
+	// Refuse to delete large subtrees by accident
+	if err := sm.dataManager.NodeManager.NodesOperationCheck(session.Mindmap, nodes, "delete", force); err != nil {
+		return nil, err
+	}
+
+	// Refuse to leave references to the deleted nodes dangling by accident
+	if !force {
+		references, err := sm.dataManager.NodeReferences(session.Mindmap, nodes)
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to get node references", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to check node references: %w", err)
+		}
+		if len(references) > 0 {
+			sm.logger.Warn(ctx, "Delete of referenced nodes requires force", log.Fields{"references": len(references)})
+			return nil, errors.New(formatNodeReferences(sm, session.Mindmap, references))
+		}
+	}
+
+	deleted := 0
+	for _, node := range nodes {
+		deleted += sm.dataManager.NodeManager.NodeCount(session.Mindmap, node)
+	}
+
+	sm.logger.Debug(ctx, "Deleting nodes", log.Fields{"nodeCount": len(nodes)})
+	err = sm.dataManager.NodeBatch(session.Mindmap, func() error {
+		for _, node := range nodes {
+			if err := sm.dataManager.NodeManager.NodeDelete(session.Mindmap, node); err != nil {
+				sm.logger.Error(ctx, "Failed to delete node", log.Fields{"error": err, "nodeID": node.ID})
+				return fmt.Errorf("failed to delete node %s: %w", sm.nodeIndex(node), err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete node: %w", err)
+	}
+
+	sm.logger.Info(ctx, "Nodes deleted successfully", log.Fields{"selected": len(nodes), "deleted": deleted})
+	if deleted == 1 {
+		return "Deleted 1 node", nil
+	}
+	return fmt.Sprintf("Deleted %d nodes", deleted), nil
+}
+
+// formatNodeReferences formats the references keeping nodes from being deleted, one per line, nodes of other
+// mindmaps by ID
+func formatNodeReferences(sm *SessionManager, mindmap *model.Mindmap, references []*model.NodeReference) string {
+	header := fmt.Sprintf("%d references to the nodes would be left dangling", len(references))
+	if len(references) == 1 {
+		header = "1 reference to the nodes would be left dangling"
+	}
+	lines := []string{header + ", use --force to delete them anyway:"}
+	for _, ref := range references {
+		target := mindmap.Nodes[ref.TargetID]
+		source := fmt.Sprintf("node #%d of mindmap #%d", ref.SourceID, ref.SourceMindmapID)
+		if node := mindmap.Nodes[ref.SourceID]; node != nil && ref.SourceMindmapID == mindmap.ID {
+			source = fmt.Sprintf("%s %s", sm.nodeIndex(node), node.Name)
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s <- %s (%s)", sm.nodeIndex(target), target.Name, source, ref.Kind))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleNodeExists handles the node exists command, printing true or false for scripts
+func handleNodeExists(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node exists command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 || len(cmd.Args) == 2 && cmd.Args[1] != "--id" {
+		sm.logger.Error(ctx, "Invalid arguments for node exists", log.Fields{"args": cmd.Args})
+		return nil, errors.New("node exists command requires 1 or 2 arguments: <node> [--id]")
+	}
+
+	var nodeInfo model.NodeInfo
+	var nodeFilter model.NodeFilter
+	if len(cmd.Args) == 2 {
+		id, err := strconv.Atoi(cmd.Args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid node ID: %s", cmd.Args[0])
+		}
+		nodeInfo.ID, nodeFilter.ID = id, true
+	} else {
+		nodeInfo.Index, nodeFilter.Index = sm.DisplayStyle().ParseIndex(cmd.Args[0]), true
+	}
+
+	exists := sm.dataManager.NodeManager.NodeExists(session.Mindmap, nodeInfo, nodeFilter)
+	sm.logger.Debug(ctx, "Node existence checked", log.Fields{"identifier": cmd.Args[0], "exists": exists})
+	return strconv.FormatBool(exists), nil
+}
+
+// handleNodeCount handles the node count command, printing the number of nodes of the mindmap or of the subtree of
+// a node, including the node, for scripts
+func handleNodeCount(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node count command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) > 2 || len(cmd.Args) == 2 && cmd.Args[1] != "--id" {
+		sm.logger.Error(ctx, "Invalid arguments for node count", log.Fields{"args": cmd.Args})
+		return nil, errors.New("node count command requires 0 to 2 arguments: [node] [--id]")
+	}
+
+	node := session.Mindmap.Root
+	if len(cmd.Args) > 0 {
+		var err error
+		if node, err = getNode(sm, session.Mindmap, cmd.Args[0], len(cmd.Args) == 2); err != nil {
+			return nil, err
+		}
+	}
+
+	count := sm.dataManager.NodeManager.NodeCount(session.Mindmap, node)
+	sm.logger.Debug(ctx, "Nodes counted", log.Fields{"nodeID": node.ID, "count": count})
+	return strconv.Itoa(count), nil
+}
+
 // handleNodeFind handles the node find command
 func handleNodeFind(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Handling node find command", log.Fields{"args": cmd.Args})
 
-	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
-		sm.logger.Error(ctx, "Invalid number of arguments for node find", log.Fields{"argCount": len(cmd.Args)})
-		return nil, errors.New("node find command requires 1 or 2 arguments: <query> [--id]")
+	options, args, err := pageArgs(cmd.Args)
+	if err != nil {
+		sm.logger.Error(ctx, "Invalid paging arguments for node find", log.Fields{"error": err})
+		return nil, err
 	}
-
-	if session.Mindmap == nil {
-		sm.logger.Error(ctx, "No mindmap selected", nil)
-		return nil, fmt.Errorf("no mindmap selected")
+	// The words of the query are all arguments other than the options
+	var words, tags []string
+	showID := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--id":
+			showID = true
+		case "--tag":
+			if i+1 >= len(args) {
+				sm.logger.Error(ctx, "Missing tag for node find", log.Fields{"args": args})
+				return nil, errors.New("--tag requires a tag")
+			}
+			tags = append(tags, args[i+1])
+			i++
+		default:
+			words = append(words, args[i])
+		}
 	}
+	if len(words) == 0 && len(tags) == 0 {
+		sm.logger.Error(ctx, "Missing query for node find", log.Fields{"argCount": len(args)})
+		return nil, errors.New("node find command requires a query or a tag: [<query>...] [--tag <tag>]... [--id] [--limit <n>] [--offset <n>]")
+	}
+	query := strings.Join(words, " ")
 
-	query := cmd.Args[0]
-	showID := len(cmd.Args) == 2 && cmd.Args[1] == "--id"
-
-	sm.logger.Debug(ctx, "Searching for nodes", log.Fields{"query": query, "showID": showID})
-	nodes, err := sm.dataManager.NodeManager.NodeFind(session.Mindmap, model.NodeFilter{Name: true, Content: true}, query)
+	sm.logger.Debug(ctx, "Searching for nodes", log.Fields{"query": query, "tags": tags, "showID": showID})
+	var nodes []*model.Node
+	if len(words) > 0 {
+		nodes, err = sm.dataManager.NodeManager.NodeFind(session.Mindmap, model.NodeFilter{Name: true, Content: true}, query)
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to find nodes", log.Fields{"error": err, "query": query})
+			return nil, fmt.Errorf("failed to find nodes: %w", err)
+		}
+	}
+	nodes, err = tagFilter(sm, session.Mindmap, nodes, len(words) > 0, tags)
 	if err != nil {
-		sm.logger.Error(ctx, "Failed to find nodes", log.Fields{"error": err, "query": query})
+		sm.logger.Error(ctx, "Failed to find tagged nodes", log.Fields{"error": err, "tags": tags})
 		return nil, fmt.Errorf("failed to find nodes: %w", err)
 	}
+	if len(nodes) == 0 {
+		if len(tags) > 0 {
+			query = strings.TrimSpace(query + " " + formatTags(tags))
+		}
+		sm.logger.Info(ctx, "No nodes found", log.Fields{"query": query})
+		return fmt.Sprintf("No nodes found matching '%s'", query), nil
+	}
 
-	// Format the results
-	var results []string
-	for _, node := range nodes {
+	// Format the results of the requested page
+	page := model.PageOf(nodes, options)
+	results := model.Page[string]{Items: make([]string, 0, len(page.Items)), Offset: page.Offset, Total: page.Total}
+	for _, node := range page.Items {
 		if showID {
-			results = append(results, fmt.Sprintf("ID: %d, Name: %s, Index: %s", node.ID, node.Name, node.Index))
+			results.Items = append(results.Items, fmt.Sprintf("ID: %d, Name: %s, Index: %s", node.ID, node.Name, sm.nodeIndex(node)))
 		} else {
-			results = append(results, fmt.Sprintf("Name: %s, Index: %s", node.Name, node.Index))
+			results.Items = append(results.Items, fmt.Sprintf("Name: %s, Index: %s", node.Name, sm.nodeIndex(node)))
 		}
 	}
 
-	sm.logger.Info(ctx, "Nodes found", log.Fields{"count": len(nodes)})
+	sm.logger.Info(ctx, "Nodes found", log.Fields{"count": len(nodes), "shown": len(results.Items)})
 	return results, nil
 }
 
+// handleNodeBacklinks handles the node backlinks command
+func handleNodeBacklinks(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node backlinks command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		sm.logger.Error(ctx, "Invalid number of arguments for node backlinks", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("node backlinks command requires 1 or 2 arguments: <node> [--id]")
+	}
+
+	nodeIdentifier := cmd.Args[0]
+	useID := len(cmd.Args) == 2 && cmd.Args[1] == "--id"
+
+	node, err := getNode(sm, session.Mindmap, nodeIdentifier, useID)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get node", log.Fields{"error": err, "nodeIdentifier": nodeIdentifier})
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	references, err := sm.dataManager.NodeBacklinkReferences(session.Mindmap, node)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get node backlinks", log.Fields{"error": err, "nodeID": node.ID})
+		return nil, fmt.Errorf("failed to get backlinks: %w", err)
+	}
+
+	// Links from other mindmaps are listed with the nodes the user sees of them, those gone or hidden left out
+	mindmaps := map[int]*model.Mindmap{session.Mindmap.ID: session.Mindmap}
+	var lines []string
+	listed := make(map[[2]int]int) // Lines of the sources listed, by mindmap and node ID
+	for _, ref := range references {
+		mindmap, ok := mindmaps[ref.SourceMindmapID]
+		if !ok {
+			mindmap, _ = sm.linkMindmap(session, model.MindmapInfo{ID: ref.SourceMindmapID}, model.MindmapFilter{ID: true})
+			mindmaps[ref.SourceMindmapID] = mindmap
+		}
+		if mindmap == nil || mindmap.Nodes[ref.SourceID] == nil || linkTargetHidden(session, mindmap, mindmap.Nodes[ref.SourceID]) {
+			continue
+		}
+		source := mindmap.Nodes[ref.SourceID]
+		if i, ok := listed[[2]int{mindmap.ID, source.ID}]; ok {
+			// A node both referring to the node and linked to it is listed once
+			lines[i] += " (link)"
+			continue
+		}
+		listed[[2]int{mindmap.ID, source.ID}] = len(lines)
+		line := "  " + sm.linkTargetName(session, mindmap, source)
+		if useID {
+			line += fmt.Sprintf(" (ID: %d)", source.ID)
+		}
+		if ref.Kind == model.ReferenceLink {
+			line += " (link)"
+		}
+		lines = append(lines, line)
+	}
+	sm.logger.Info(ctx, "Node backlinks listed", log.Fields{"nodeID": node.ID, "backlinkCount": len(lines)})
+	if len(lines) == 0 {
+		return fmt.Sprintf("No nodes refer to %s", node.Name), nil
+	}
+	return fmt.Sprintf("%d nodes refer to %s:\n", len(lines), node.Name) + strings.Join(lines, "\n"), nil
+}
+
+// handleNodeWikilink handles the node wikilink command
+func handleNodeWikilink(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node wikilink command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 {
+		sm.logger.Error(ctx, "Insufficient arguments for node wikilink", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("node wikilink command requires at least 1 argument: <node> [--create] [--inbox <node>] [--id]")
+	}
+
+	nodeIdentifier := cmd.Args[0]
+	inboxIdentifier := ""
+	create := false
+	useID := false
+	for i := 1; i < len(cmd.Args); i++ {
+		switch cmd.Args[i] {
+		case "--create":
+			create = true
+		case "--id":
+			useID = true
+		case "--inbox":
+			if i+1 >= len(cmd.Args) {
+				return nil, errors.New("--inbox requires a node")
+			}
+			i++
+			inboxIdentifier = cmd.Args[i]
+		default:
+			sm.logger.Error(ctx, "Invalid option for node wikilink", log.Fields{"option": cmd.Args[i]})
+			return nil, fmt.Errorf("invalid option for node wikilink: %s", cmd.Args[i])
+		}
+	}
+
+	node, err := getNode(sm, session.Mindmap, nodeIdentifier, useID)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get node", log.Fields{"error": err, "nodeIdentifier": nodeIdentifier})
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	var inbox *model.Node
+	if inboxIdentifier != "" {
+		if inbox, err = getNode(sm, session.Mindmap, inboxIdentifier, useID); err != nil {
+			sm.logger.Error(ctx, "Failed to get inbox node", log.Fields{"error": err, "nodeIdentifier": inboxIdentifier})
+			return nil, fmt.Errorf("failed to get inbox node: %w", err)
+		}
+	}
+
+	result, err := sm.dataManager.NodeWikiLink(session.Mindmap, node, create, inbox)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{fmt.Sprintf("%s links to %d nodes", node.Name, len(result.Linked))}
+	for _, n := range result.Linked {
+		line := fmt.Sprintf("  %s %s", sm.nodeIndex(n), n.Name)
+		if slices.Contains(result.Created, n) {
+			line += "  (created)"
+		}
+		lines = append(lines, line)
+	}
+	if problems := formatWikiLinkProblems(result); problems != "" {
+		lines = append(lines, problems)
+	}
+
+	sm.logger.Info(ctx, "Node wikilink completed", log.Fields{"nodeID": node.ID, "linked": len(result.Linked), "created": len(result.Created)})
+	return strings.Join(lines, "\n"), nil
+}
+
 // handleNodeSort handles the node sort command
 func handleNodeSort(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Handling node sort command", log.Fields{"args": cmd.Args})
 
-	if session.Mindmap == nil {
-		sm.logger.Error(ctx, "No mindmap selected", nil)
-		return nil, fmt.Errorf("no mindmap selected")
-	}
-
 	var parentNode *model.Node
-	var field string
-	reverse := false
+	var spec, collation model.SortSpec
 	useID := false
+	force := false
+	preview := false
 	var parentIdentifier string
 
-	for i, arg := range cmd.Args {
+	for i := 0; i < len(cmd.Args); i++ {
+		arg := cmd.Args[i]
 		switch {
-		case i == 0 && arg != "--reverse" && arg != "--id":
+		case i == 0 && !strings.HasPrefix(arg, "--"):
 			parentIdentifier = arg
 		case arg == "--reverse":
-			reverse = true
+			spec.Reverse = true
 		case arg == "--id":
 			useID = true
+		case arg == "--force":
+			force = true
+		case arg == "--preview":
+			preview = true
+		case arg == "--natural":
+			collation.Natural = true
+		case arg == "--nocase":
+			collation.IgnoreCase = true
+		case arg == "--sort":
+			if i+1 >= len(cmd.Args) || cmd.Args[i+1] != model.SortCreated && cmd.Args[i+1] != model.SortModified {
+				return nil, fmt.Errorf("--sort requires %s or %s", model.SortCreated, model.SortModified)
+			}
+			i++
+			spec.Field = cmd.Args[i]
+		case arg == "--locale":
+			if i+1 >= len(cmd.Args) {
+				return nil, errors.New("--locale requires a language tag such as de or sv")
+			}
+			i++
+			if _, err := model.ParseSortCollation(&collation, model.SortLocalePrefix+cmd.Args[i]); err != nil {
+				sm.logger.Error(ctx, "Invalid locale for node sort", log.Fields{"error": err, "locale": cmd.Args[i]})
+				return nil, err
+			}
 		default:
-			field = arg
+			spec.Field = arg
 		}
 	}
 
+	// Without collation options text compares as in the default sort of the mindmap
+	if collation.IsZero() {
+		collation = session.Mindmap.Sort.Collation()
+	}
+	spec.Natural, spec.IgnoreCase, spec.Locale = collation.Natural, collation.IgnoreCase, collation.Locale
+
 	if parentIdentifier != "" {
 		var err error
 		parentNode, err = getNode(sm, session.Mindmap, parentIdentifier, useID)
@@ -282,8 +842,22 @@ func handleNodeSort(sm *SessionManager, session *model.Session, cmd model.Comman
 		parentNode = session.Mindmap.Root
 	}
 
-	sm.logger.Debug(ctx, "Sorting nodes", log.Fields{"parentNodeID": parentNode.ID, "field": field, "reverse": reverse})
-	err := sm.dataManager.NodeManager.NodeSort(session.Mindmap, sm.dataManager.NodeManager.NodeToInfo(parentNode), field, reverse)
+	if preview {
+		previewMindmap, err := sm.dataManager.NodeManager.NodeSortPreview(session.Mindmap, parentNode, spec)
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to preview node sort", log.Fields{"error": err, "parentNodeID": parentNode.ID})
+			return nil, fmt.Errorf("failed to preview node sort: %w", err)
+		}
+		return formatPreview("node sort", session.Mindmap, previewMindmap, parentNode, sm.DisplayStyle(), useID), nil
+	}
+
+	// The whole subtree is sorted, refuse to sort large subtrees by accident
+	if err := sm.dataManager.NodeManager.NodeOperationCheck(session.Mindmap, parentNode, "sort", force); err != nil {
+		return nil, err
+	}
+
+	sm.logger.Debug(ctx, "Sorting nodes", log.Fields{"parentNodeID": parentNode.ID, "sort": spec.String()})
+	err := sm.dataManager.NodeManager.NodeSort(session.Mindmap, sm.dataManager.NodeManager.NodeToInfo(parentNode), spec)
 	if err != nil {
 		sm.logger.Error(ctx, "Failed to sort nodes", log.Fields{"error": err, "parentNodeID": parentNode.ID})
 		return nil, fmt.Errorf("failed to sort nodes: %w", err)
@@ -293,6 +867,114 @@ func handleNodeSort(sm *SessionManager, session *model.Session, cmd model.Comman
 	return nil, nil
 }
 
+// handleNodeField handles the node field command, renaming or deleting a content field across many nodes
+func handleNodeField(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node field command", log.Fields{"args": cmd.Args})
+
+	usage := "node field command requires: rename <old> <new> | delete <key>, [--scope mindmap|subtree <node>] [--id] [--dry-run]"
+	if len(cmd.Args) < 2 {
+		sm.logger.Error(ctx, "Insufficient arguments for node field", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New(usage)
+	}
+
+	var keys []string
+	var nodeIdentifier string
+	useID := false
+	dryRun := false
+	for i := 1; i < len(cmd.Args); i++ {
+		switch arg := cmd.Args[i]; arg {
+		case "--id":
+			useID = true
+		case "--dry-run":
+			dryRun = true
+		case "--scope":
+			if i+1 < len(cmd.Args) && cmd.Args[i+1] == "mindmap" {
+				i++
+			} else if i+2 < len(cmd.Args) && cmd.Args[i+1] == "subtree" {
+				nodeIdentifier = cmd.Args[i+2]
+				i += 2
+			} else {
+				sm.logger.Error(ctx, "Invalid scope for node field", log.Fields{"args": cmd.Args})
+				return nil, errors.New("invalid scope for node field, expected --scope mindmap or --scope subtree <node>")
+			}
+		default:
+			keys = append(keys, arg)
+		}
+	}
+
+	operation := cmd.Args[0]
+	switch {
+	case operation == "rename" && len(keys) == 2:
+		if err := names.Validate(names.Field, keys[1]); err != nil {
+			sm.logger.Error(ctx, "Invalid field name", log.Fields{"error": err, "field": keys[1]})
+			return nil, err
+		}
+	case operation == "delete" && len(keys) == 1:
+	default:
+		sm.logger.Error(ctx, "Invalid arguments for node field", log.Fields{"args": cmd.Args})
+		return nil, errors.New(usage)
+	}
+
+	// Without a subtree the whole mindmap is in scope
+	var node *model.Node
+	if nodeIdentifier != "" {
+		var err error
+		node, err = getNode(sm, session.Mindmap, nodeIdentifier, useID)
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to get node", log.Fields{"error": err, "nodeIdentifier": nodeIdentifier})
+			return nil, fmt.Errorf("failed to get node: %w", err)
+		}
+	}
+
+	var changed, conflicts []*model.Node
+	var summary string
+	err := sm.dataManager.NodeBatch(session.Mindmap, func() error {
+		var err error
+		if operation == "rename" {
+			changed, conflicts, err = sm.dataManager.NodeManager.NodeFieldRename(session.Mindmap, node, keys[0], keys[1], dryRun)
+			summary = fmt.Sprintf("field %s renamed to %s", keys[0], keys[1])
+		} else {
+			changed, err = sm.dataManager.NodeManager.NodeFieldDelete(session.Mindmap, node, keys[0], dryRun)
+			summary = fmt.Sprintf("field %s deleted", keys[0])
+		}
+		return err
+	})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to "+operation+" field", log.Fields{"error": err, "keys": keys})
+		return nil, fmt.Errorf("failed to %s field: %w", operation, err)
+	}
+
+	sm.logger.Info(ctx, "Node field command completed", log.Fields{"operation": operation, "nodeCount": len(changed), "conflictCount": len(conflicts), "dryRun": dryRun})
+	return formatFieldReport(sm.DisplayStyle(), summary, changed, conflicts, dryRun, useID), nil
+}
+
+// formatFieldReport lists the nodes changed by a node field command and the nodes skipped due to conflicts
+func formatFieldReport(style model.DisplayStyle, summary string, changed, conflicts []*model.Node, dryRun, showID bool) string {
+	var report strings.Builder
+	if dryRun {
+		fmt.Fprintf(&report, "Dry run, nothing changed: %s in %d nodes", summary, len(changed))
+	} else {
+		fmt.Fprintf(&report, "Content %s in %d nodes", summary, len(changed))
+	}
+
+	list := func(nodes []*model.Node) {
+		for _, n := range nodes {
+			if showID {
+				fmt.Fprintf(&report, "\n  %s %s (ID: %d)", style.DisplayIndex(n.Index), n.Name, n.ID)
+			} else {
+				fmt.Fprintf(&report, "\n  %s %s", style.DisplayIndex(n.Index), n.Name)
+			}
+		}
+	}
+	list(changed)
+	if len(conflicts) > 0 {
+		fmt.Fprintf(&report, "\nSkipped %d nodes that already have the new field:", len(conflicts))
+		list(conflicts)
+	}
+	return report.String()
+}
+
 // getNode is a helper function to get a node by its identifier (index or ID)
 func getNode(sm *SessionManager, mindmap *model.Mindmap, identifier string, useID bool) (*model.Node, error) {
 	ctx := context.Background()
@@ -310,7 +992,7 @@ func getNode(sm *SessionManager, mindmap *model.Mindmap, identifier string, useI
 		nodeInfo.ID = id
 		nodeFilter.ID = true
 	} else {
-		nodeInfo.Index = identifier
+		nodeInfo.Index = sm.DisplayStyle().ParseIndex(identifier)
 		nodeFilter.Index = true
 	}
 
@@ -324,5 +1006,10 @@ func getNode(sm *SessionManager, mindmap *model.Mindmap, identifier string, useI
 		return nil, fmt.Errorf("node not found: %s", identifier)
 	}
 	sm.logger.Debug(ctx, "Node retrieved successfully", log.Fields{"nodeID": nodes[0].ID})
+
+	// Operations must change the loaded node, not the copy read from storage
+	if loaded, ok := mindmap.Nodes[nodes[0].ID]; ok {
+		return loaded, nil
+	}
 	return nodes[0], nil
 }
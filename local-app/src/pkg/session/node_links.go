@@ -0,0 +1,205 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// handleNodeLink handles the node link command, which links a node to a node of the same or another mindmap, or
+// removes the link
+func handleNodeLink(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node link command", log.Fields{"args": cmd.Args})
+
+	usage := "node link command requires 2 arguments: <source> <target> [--mindmap <mindmap>] [--remove] [--id]"
+	var identifiers []string
+	var mindmapName string
+	useID, remove := false, false
+	for i := 0; i < len(cmd.Args); i++ {
+		switch arg := cmd.Args[i]; arg {
+		case "--id":
+			useID = true
+		case "--remove":
+			remove = true
+		case "--mindmap":
+			if i+1 >= len(cmd.Args) {
+				sm.logger.Error(ctx, "Missing mindmap for node link", log.Fields{"args": cmd.Args})
+				return nil, errors.New("--mindmap requires the name of a mindmap")
+			}
+			mindmapName = cmd.Args[i+1]
+			i++
+		default:
+			identifiers = append(identifiers, arg)
+		}
+	}
+	if len(identifiers) != 2 {
+		sm.logger.Error(ctx, "Invalid arguments for node link", log.Fields{"args": cmd.Args})
+		return nil, errors.New(usage)
+	}
+
+	source, err := getNode(sm, session.Mindmap, identifiers[0], useID)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get source node", log.Fields{"error": err, "nodeIdentifier": identifiers[0]})
+		return nil, fmt.Errorf("failed to get source node: %w", err)
+	}
+
+	targetMindmap := session.Mindmap
+	if mindmapName != "" {
+		if targetMindmap, err = sm.linkMindmap(session, model.MindmapInfo{Name: mindmapName}, model.MindmapFilter{Name: true}); err != nil {
+			return nil, err
+		}
+	}
+	target, err := getNode(sm, targetMindmap, identifiers[1], useID)
+	if err == nil && linkTargetHidden(session, targetMindmap, target) {
+		err = fmt.Errorf("node not found: %s", identifiers[1])
+	}
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get target node", log.Fields{"error": err, "nodeIdentifier": identifiers[1], "mindmapID": targetMindmap.ID})
+		return nil, fmt.Errorf("failed to get target node: %w", err)
+	}
+	if targetMindmap.ID == session.Mindmap.ID && target.ID == source.ID {
+		sm.logger.Warn(ctx, "Attempt to link a node to itself", log.Fields{"nodeID": source.ID})
+		return nil, errors.New("a node can't be linked to itself")
+	}
+
+	link := model.Link{SourceMindmapID: session.Mindmap.ID, SourceID: source.ID, TargetMindmapID: targetMindmap.ID, TargetID: target.ID, Type: model.LinkTypeNode}
+	description := sm.linkTargetName(session, targetMindmap, target)
+	var changed bool
+	if remove {
+		changed, err = sm.dataManager.LinkManager.LinkRemove(link)
+	} else {
+		changed, err = sm.dataManager.LinkManager.LinkCreate(link)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sm.logger.Info(ctx, "Node link command completed", log.Fields{"sourceID": source.ID, "targetMindmapID": targetMindmap.ID, "targetID": target.ID, "remove": remove, "changed": changed})
+	switch {
+	case remove && changed:
+		return fmt.Sprintf("Link from node %s to %s removed", sm.nodeIndex(source), description), nil
+	case remove:
+		return fmt.Sprintf("Node %s is not linked to %s", sm.nodeIndex(source), description), nil
+	case changed:
+		return fmt.Sprintf("Node %s linked to %s", sm.nodeIndex(source), description), nil
+	default:
+		return fmt.Sprintf("Node %s is already linked to %s", sm.nodeIndex(source), description), nil
+	}
+}
+
+// linkMindmap returns the mindmap of a link target accessible to the user of a session, loaded with the nodes the
+// user sees: the selected mindmap, one loaded by another session or otherwise loaded for the link
+func (sm *SessionManager) linkMindmap(session *model.Session, info model.MindmapInfo, filter model.MindmapFilter) (*model.Mindmap, error) {
+	ctx := context.Background()
+
+	mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(session.User, info, filter)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get linked mindmap", log.Fields{"error": err, "mindmapName": info.Name, "mindmapID": info.ID})
+		return nil, fmt.Errorf("failed to get mindmap: %w", err)
+	}
+	if len(mindmaps) == 0 {
+		sm.logger.Warn(ctx, "Linked mindmap not found", log.Fields{"mindmapName": info.Name, "mindmapID": info.ID})
+		return nil, fmt.Errorf("mindmap not found: %s", info.Name)
+	}
+
+	mindmap := mindmaps[0]
+	if session.Mindmap != nil && session.Mindmap.ID == mindmap.ID {
+		return session.Mindmap, nil
+	}
+	if loaded := sm.sessionMindmap(mindmap.ID); loaded != nil {
+		return loaded, nil
+	}
+	if err := sm.dataManager.EventManager.PublishAndWait(event.Event{Type: event.MindmapSelected, Data: mindmap}); err != nil {
+		sm.logger.Error(ctx, "Failed to load linked mindmap", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to load mindmap %s: %w", mindmap.Name, err)
+	}
+	if mindmap.Owner != session.User.Username {
+		sm.dataManager.NodeManager.NodePrivateHide(mindmap)
+	}
+	return mindmap, nil
+}
+
+// linkTargetHidden reports whether a node of a linked mindmap is in a private branch hidden from the user of a
+// session, the mindmap having been loaded by another session that sees it
+func linkTargetHidden(session *model.Session, mindmap *model.Mindmap, node *model.Node) bool {
+	if mindmap.Owner == session.User.Username {
+		return false
+	}
+	for n := node; n != nil && n.ID != 0; n = mindmap.Nodes[n.ParentID] {
+		if n.IsPrivate() {
+			return true
+		}
+	}
+	return false
+}
+
+// linkTargetName names the target of a link as shown to the user: its index and name, preceded by its mindmap if
+// that is not the selected one
+func (sm *SessionManager) linkTargetName(session *model.Session, mindmap *model.Mindmap, node *model.Node) string {
+	name := sm.nodeIndex(node) + " " + node.Name
+	if node.ID == 0 {
+		name = node.Name
+	}
+	if mindmap.ID != session.Mindmap.ID {
+		name = mindmap.Name + ":" + name
+	}
+	return name
+}
+
+// linkAnnotations returns the notes of the mindmap view listing the targets of the links of each node of the
+// selected mindmap: those of node link, such as "-> 2.1 Plan, ideas:3 Draft", and the nodes its [[Name]] references
+// resolved to, such as "[wiki: 3 Draft]". Targets that are gone or hidden from the user are left out.
+func (sm *SessionManager) linkAnnotations(session *model.Session) (map[int]string, error) {
+	links, err := sm.dataManager.LinkManager.LinkGetMindmap(session.Mindmap)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[int][]string)
+	wikiTargets := make(map[int][]string)
+	mindmaps := map[int]*model.Mindmap{session.Mindmap.ID: session.Mindmap}
+	for _, link := range links {
+		if session.Mindmap.Nodes[link.SourceID] == nil {
+			continue
+		}
+		if link.Type == model.LinkTypeWiki {
+			// Wiki links are made within the mindmap, from the nodes the user sees
+			if target := session.Mindmap.Nodes[link.TargetID]; target != nil && link.TargetMindmapID == session.Mindmap.ID {
+				wikiTargets[link.SourceID] = append(wikiTargets[link.SourceID], sm.linkTargetName(session, session.Mindmap, target))
+			}
+			continue
+		}
+		if link.Type != model.LinkTypeNode {
+			continue
+		}
+		mindmap, ok := mindmaps[link.TargetMindmapID]
+		if !ok {
+			// Mindmaps deleted or no longer accessible leave their links out
+			mindmap, _ = sm.linkMindmap(session, model.MindmapInfo{ID: link.TargetMindmapID}, model.MindmapFilter{ID: true})
+			mindmaps[link.TargetMindmapID] = mindmap
+		}
+		if mindmap == nil || mindmap.Nodes[link.TargetID] == nil || linkTargetHidden(session, mindmap, mindmap.Nodes[link.TargetID]) {
+			continue
+		}
+		targets[link.SourceID] = append(targets[link.SourceID], sm.linkTargetName(session, mindmap, mindmap.Nodes[link.TargetID]))
+	}
+
+	notes := make(map[int]string, len(targets)+len(wikiTargets))
+	for id, names := range targets {
+		notes[id] = "-> " + strings.Join(names, ", ")
+	}
+	for id, names := range wikiTargets {
+		note := "[wiki: " + strings.Join(names, ", ") + "]"
+		if notes[id] != "" {
+			note = notes[id] + "  " + note
+		}
+		notes[id] = note
+	}
+	return notes, nil
+}
@@ -0,0 +1,157 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// handleNodeTag handles the node tag command: tagging nodes, removing their tags and listing the tags
+func handleNodeTag(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node tag command", log.Fields{"args": cmd.Args})
+
+	usage := "node tag command requires: add <node> <tag>... | remove <node> <tag>... | list [node], [--id]"
+	if len(cmd.Args) < 1 {
+		sm.logger.Error(ctx, "Insufficient arguments for node tag", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New(usage)
+	}
+
+	var args []string
+	useID := false
+	for _, arg := range cmd.Args[1:] {
+		if arg == "--id" {
+			useID = true
+		} else {
+			args = append(args, arg)
+		}
+	}
+
+	operation := cmd.Args[0]
+	switch {
+	case operation == "list" && len(args) == 0:
+		return formatTagCounts(sm.dataManager.NodeManager.NodeTagCounts(session.Mindmap)), nil
+	case operation == "list" && len(args) == 1:
+	case (operation == "add" || operation == "remove") && len(args) >= 2:
+	default:
+		sm.logger.Error(ctx, "Invalid arguments for node tag", log.Fields{"args": cmd.Args})
+		return nil, errors.New(usage)
+	}
+
+	// The node may be a selector such as 2.* or 1.2-1.5, tagging or listing every node it selects
+	nodes, err := selectNodes(sm, session.Mindmap, args[:1], useID)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to select nodes", log.Fields{"error": err, "selector": args[0]})
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	if operation == "list" {
+		var result []string
+		for _, node := range nodes {
+			if len(node.Tags) == 0 {
+				result = append(result, fmt.Sprintf("Node %s has no tags", sm.nodeIndex(node)))
+			} else {
+				result = append(result, fmt.Sprintf("Tags of node %s: %s", sm.nodeIndex(node), formatTags(node.Tags)))
+			}
+		}
+		return strings.Join(result, "\n"), nil
+	}
+
+	changed := make(map[int][]string, len(nodes))
+	unchanged := make(map[int][]string, len(nodes))
+	err = sm.dataManager.NodeBatch(session.Mindmap, func() error {
+		for _, node := range nodes {
+			for _, tag := range args[1:] {
+				var stored string
+				var ok bool
+				var err error
+				if operation == "add" {
+					stored, ok, err = sm.dataManager.NodeManager.NodeTagAdd(session.Mindmap, node, tag)
+				} else {
+					stored, ok, err = sm.dataManager.NodeManager.NodeTagDelete(session.Mindmap, node, tag)
+				}
+				if err != nil {
+					return fmt.Errorf("node %s: %w", sm.nodeIndex(node), err)
+				}
+				if ok {
+					changed[node.ID] = append(changed[node.ID], stored)
+				} else {
+					unchanged[node.ID] = append(unchanged[node.ID], stored)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to change node tags", log.Fields{"error": err, "operation": operation, "selector": args[0]})
+		return nil, fmt.Errorf("failed to %s tag: %w", operation, err)
+	}
+
+	var result []string
+	for _, node := range nodes {
+		index := sm.nodeIndex(node)
+		if operation == "add" {
+			if len(changed[node.ID]) > 0 {
+				result = append(result, fmt.Sprintf("Node %s tagged %s", index, formatTags(changed[node.ID])))
+			}
+			if len(unchanged[node.ID]) > 0 {
+				result = append(result, fmt.Sprintf("Node %s already tagged %s", index, formatTags(unchanged[node.ID])))
+			}
+		} else {
+			if len(changed[node.ID]) > 0 {
+				result = append(result, fmt.Sprintf("Removed %s from node %s", formatTags(changed[node.ID]), index))
+			}
+			if len(unchanged[node.ID]) > 0 {
+				result = append(result, fmt.Sprintf("Node %s not tagged %s", index, formatTags(unchanged[node.ID])))
+			}
+		}
+	}
+
+	sm.logger.Info(ctx, "Node tag command completed", log.Fields{"operation": operation, "nodeCount": len(nodes), "changed": len(changed)})
+	return strings.Join(result, "\n"), nil
+}
+
+// formatTags formats tags as written in the mindmap view, such as "#work #urgent"
+func formatTags(tags []string) string {
+	return "#" + strings.Join(tags, " #")
+}
+
+// formatTagCounts lists the tags of a mindmap with the number of nodes having each
+func formatTagCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "No tags in the mindmap"
+	}
+	var list strings.Builder
+	list.WriteString("Tags:")
+	for _, tag := range slices.Sorted(maps.Keys(counts)) {
+		fmt.Fprintf(&list, "\n  #%s (%d)", tag, counts[tag])
+	}
+	return list.String()
+}
+
+// tagFilter keeps the nodes having all tags, looked up by the index of the stored tags. With find set nodes are
+// the nodes found by a query, otherwise the nodes with the first tag are taken.
+func tagFilter(sm *SessionManager, mindmap *model.Mindmap, nodes []*model.Node, find bool, tags []string) ([]*model.Node, error) {
+	for _, tag := range tags {
+		tagged, err := sm.dataManager.NodeManager.NodeTagFind(mindmap, tag)
+		if err != nil {
+			return nil, err
+		}
+		if !find {
+			nodes, find = tagged, true
+			continue
+		}
+		ids := make(map[int]bool, len(tagged))
+		for _, node := range tagged {
+			ids[node.ID] = true
+		}
+		nodes = slices.DeleteFunc(nodes, func(node *model.Node) bool { return !ids[node.ID] })
+	}
+	return nodes, nil
+}
@@ -0,0 +1,35 @@
+package session
+
+import (
+	"fmt"
+	"strconv"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// pageArgs takes the --limit <n> and --offset <n> options of a list command out of its arguments,
+// returning the page options and the remaining arguments
+func pageArgs(args []string) (model.PageOptions, []string, error) {
+	var options model.PageOptions
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--limit" && args[i] != "--offset" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 == len(args) {
+			return options, nil, fmt.Errorf("%s requires a number", args[i])
+		}
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n < 0 {
+			return options, nil, fmt.Errorf("invalid %s: %s", args[i][2:], args[i+1])
+		}
+		if args[i] == "--limit" {
+			options.Limit = n
+		} else {
+			options.Offset = n
+		}
+		i++
+	}
+	return options, rest, nil
+}
@@ -0,0 +1,86 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// privateMiddleware rejects the node changes of users the mindmap hides private subtrees from. Their changes
+// would renumber the nodes they see without the hidden ones, so only the owner can change such a mindmap.
+func (sm *SessionManager) privateMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		if session.Mindmap != nil && session.Mindmap.Hidden > 0 && isMutatingCommand(cmd) {
+			sm.logger.Warn(context.Background(), "Change of mindmap with hidden nodes refused", log.Fields{"operation": cmd.Operation, "mindmapID": session.Mindmap.ID})
+			return nil, fmt.Errorf("mindmap '%s' has private branches, only its owner can change it", session.Mindmap.Name)
+		}
+		return next(sm, session, cmd)
+	}
+}
+
+// handleNodePrivate handles the node private command
+func handleNodePrivate(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node private command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 || len(cmd.Args) > 3 {
+		sm.logger.Error(ctx, "Invalid number of arguments for node private", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("node private command requires 1 to 3 arguments: <node> [on|off] [--id]")
+	}
+
+	nodeIdentifier := cmd.Args[0]
+	private, useID := true, false
+	for _, arg := range cmd.Args[1:] {
+		switch arg {
+		case "on":
+			private = true
+		case "off":
+			private = false
+		case "--id":
+			useID = true
+		default:
+			sm.logger.Error(ctx, "Invalid argument for node private", log.Fields{"arg": arg})
+			return nil, fmt.Errorf("invalid argument: %s. Must be 'on', 'off' or '--id'", arg)
+		}
+	}
+
+	if session.Mindmap.Owner != session.User.Username {
+		sm.logger.Warn(ctx, "Node private by other user than the owner", log.Fields{"username": session.User.Username, "mindmapID": session.Mindmap.ID})
+		return nil, fmt.Errorf("only the owner of mindmap '%s' can make branches private", session.Mindmap.Name)
+	}
+
+	node, err := getNode(sm, session.Mindmap, nodeIdentifier, useID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	if node.ID == 0 {
+		sm.logger.Warn(ctx, "Attempt to make the root node private", nil)
+		return nil, errors.New("the root node can't be private, make the mindmap private instead")
+	}
+
+	// Storage replaces all the content of a node on update, so the other fields are passed along
+	content := maps.Clone(node.Content)
+	if content == nil {
+		content = make(map[string]string)
+	}
+	content[model.PrivateField] = ""
+	if private {
+		content[model.PrivateField] = "true"
+	}
+
+	err = sm.dataManager.NodeManager.NodeUpdate(session.Mindmap, node, model.NodeInfo{Content: content}, model.NodeFilter{Content: true})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to update node privacy", log.Fields{"error": err, "nodeID": node.ID})
+		return nil, fmt.Errorf("failed to update node: %w", err)
+	}
+
+	sm.logger.Info(ctx, "Node privacy updated", log.Fields{"nodeID": node.ID, "private": private})
+	if private {
+		return fmt.Sprintf("Branch of %s is private, hidden from other users", node.Name), nil
+	}
+	return fmt.Sprintf("Branch of %s is no longer private", node.Name), nil
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+// PublishedMapsPath is the path the web adapter serves the exports of the mindmaps of web_maps under, as
+// <owner>/<mindmap>.<format>
+const PublishedMapsPath = "/maps/"
+
+// PublishedFormats are the export formats the mindmaps of web_maps are served in
+var PublishedFormats = []string{"html", "svg"}
+
+// ErrNotPublished is returned for a mindmap that is not in web_maps, or that the user of a session may not see
+var ErrNotPublished = errors.New("mindmap not published")
+
+// PublishedMindmaps returns the mindmaps of web_maps that the user of a session may see, their own and the public
+// ones, without their nodes
+func (sm *SessionManager) PublishedMindmaps(sessionID string) ([]*model.Mindmap, error) {
+	result, err := sm.runTask(func() (interface{}, error) {
+		return sm.publishedMindmaps(sessionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*model.Mindmap), nil
+}
+
+// PublishedMindmap returns the mindmap of web_maps of an owner and name that the user of a session may see,
+// without its nodes, or ErrNotPublished
+func (sm *SessionManager) PublishedMindmap(sessionID, owner, name string) (*model.Mindmap, error) {
+	result, err := sm.runTask(func() (interface{}, error) {
+		mindmaps, err := sm.publishedMindmaps(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		caseSensitive := sm.dataManager.Config.CaseSensitiveNames
+		for _, mindmap := range mindmaps {
+			if names.Equal(mindmap.Owner, owner, caseSensitive) && names.Equal(mindmap.Name, name, caseSensitive) {
+				return mindmap, nil
+			}
+		}
+		return nil, ErrNotPublished
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*model.Mindmap), nil
+}
+
+// PublishedEncode encodes a published mindmap in an export format as the user of a session exports it, the private
+// subtrees left out unless the user owns it
+func (sm *SessionManager) PublishedEncode(sessionID string, mindmap *model.Mindmap, format string) ([]byte, error) {
+	if !slices.Contains(PublishedFormats, format) {
+		return nil, fmt.Errorf("mindmaps are not published as %s", format)
+	}
+	result, err := sm.runTask(func() (interface{}, error) {
+		ctx := context.Background()
+		session, exists := sm.SessionGet(sessionID)
+		if !exists || session.User == nil {
+			return nil, errors.New("no user selected")
+		}
+
+		// The mindmap is loaded anew, as selecting it would
+		mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{ID: mindmap.ID}, model.MindmapFilter{ID: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get mindmap: %w", err)
+		}
+		if len(mindmaps) == 0 {
+			return nil, ErrNotPublished
+		}
+		loaded := mindmaps[0]
+		if err := sm.dataManager.EventManager.PublishAndWait(event.Event{Type: event.MindmapSelected, Data: loaded}); err != nil {
+			sm.logger.Error(ctx, "Failed to load published mindmap", log.Fields{"error": err, "mindmapID": loaded.ID})
+			return nil, fmt.Errorf("failed to load mindmap: %w", err)
+		}
+		if loaded.Owner != session.User.Username {
+			sm.dataManager.NodeManager.NodePrivateHide(loaded)
+		}
+		return sm.dataManager.MindmapEncode(session.User, loaded, model.ExportOptions{Format: format})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]byte), nil
+}
+
+// publishedMindmaps returns the mindmaps of web_maps the user of a session may see. It runs on the command executor.
+func (sm *SessionManager) publishedMindmaps(sessionID string) ([]*model.Mindmap, error) {
+	mindmaps, err := sm.visibleMindmaps(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	caseSensitive := sm.dataManager.Config.CaseSensitiveNames
+	return slices.DeleteFunc(mindmaps, func(mindmap *model.Mindmap) bool {
+		return !slices.ContainsFunc(sm.dataManager.Config.WebMaps, func(published string) bool {
+			owner, name, ok := strings.Cut(published, "/")
+			return ok && names.Equal(owner, mindmap.Owner, caseSensitive) && names.Equal(name, mindmap.Name, caseSensitive)
+		})
+	}), nil
+}
@@ -0,0 +1,163 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/notify"
+)
+
+// reminderSinkTimeout is the time allowed to deliver a reminder to each sink, well within the event handler timeout
+const reminderSinkTimeout = 5 * time.Second
+
+// startReminderRoutine starts a goroutine that periodically looks for reminders falling due and publishes a
+// ReminderDue event for each, delivered to the configured sinks, unless reminders are disabled by a negative
+// reminder interval. Reminders falling due while the application is not running are not delivered.
+func (sm *SessionManager) startReminderRoutine() {
+	ctx := context.Background()
+
+	// A read-only instance browses data, often a backup, whose reminders are not its own to deliver
+	interval := sm.dataManager.Config.ReminderInterval
+	if interval <= 0 || sm.dataManager.Config.ReadOnly {
+		sm.logger.Info(ctx, "Reminders disabled", nil)
+		return
+	}
+	sm.logger.Info(ctx, "Starting reminder routine", log.Fields{"interval": interval, "sinks": len(sm.dataManager.Config.ReminderSinks)})
+	sm.dataManager.EventManager.Subscribe(event.ReminderDue, sm.handleReminderDue)
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		checked := time.Now()
+		for {
+			select {
+			case <-ticker.C:
+				checked = sm.remindDue(checked)
+			case <-sm.reminderDone:
+				sm.logger.Info(ctx, "Stopped reminder routine", nil)
+				return
+			}
+		}
+	}()
+}
+
+// StopReminderRoutine stops the reminder routine
+func (sm *SessionManager) StopReminderRoutine() {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Stopping reminder routine", nil)
+	close(sm.reminderDone)
+}
+
+// remindDue publishes the reminders falling due since the last check and returns the time of this check, or the
+// time of the last check if the reminders could not be read so they are looked for again on the next check
+func (sm *SessionManager) remindDue(checked time.Time) time.Time {
+	ctx := context.Background()
+
+	now := time.Now()
+	result, err := sm.runTask(func() (interface{}, error) {
+		return sm.dataManager.ReminderDue(checked, now)
+	})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to get due reminders", log.Fields{"error": err})
+		return checked
+	}
+
+	for _, reminder := range result.([]*model.Reminder) {
+		reminder.Index = sm.DisplayStyle().DisplayIndex(reminder.Index)
+		sm.dataManager.EventManager.Publish(event.Event{Type: event.ReminderDue, Data: reminder})
+	}
+	return now
+}
+
+// handleReminderDue delivers a due reminder to all configured sinks at once
+func (sm *SessionManager) handleReminderDue(e event.Event) {
+	ctx := context.Background()
+
+	reminder, ok := e.Data.(*model.Reminder)
+	if !ok {
+		sm.logger.Error(ctx, "Invalid event data for reminder due event", nil)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, sink := range sm.dataManager.Config.ReminderSinks {
+		if sink.Type == notify.SinkLog {
+			sm.logger.Info(ctx, "Reminder due", log.Fields{"owner": reminder.Owner, "mindmap": reminder.MindmapName, "index": reminder.Index, "reminder": notify.Text(reminder)})
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sinkCtx, cancel := context.WithTimeout(ctx, reminderSinkTimeout)
+			defer cancel()
+			if err := notify.Deliver(sinkCtx, sink, reminder); err != nil {
+				sm.logger.Error(ctx, "Failed to deliver reminder", log.Fields{"error": err, "sink": sink.Type, "owner": reminder.Owner, "nodeID": reminder.NodeID})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// handleNodeRemind handles the node remind command, which sets or clears the time to remind of a node
+func handleNodeRemind(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling node remind command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+		sm.logger.Error(ctx, "Invalid number of arguments for node remind", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("node remind command requires 2 or 3 arguments: <node> <time>|clear [--id]")
+	}
+
+	nodeIdentifier := cmd.Args[0]
+	value := cmd.Args[1]
+	useID := len(cmd.Args) == 3 && cmd.Args[2] == "--id"
+
+	var at time.Time
+	if value == "clear" {
+		value = ""
+	} else {
+		var err error
+		if at, err = model.ParseReminderTime(model.RemindField, value); err != nil {
+			sm.logger.Warn(ctx, "Invalid reminder time", log.Fields{"error": err, "time": value})
+			return nil, err
+		}
+	}
+
+	node, err := getNode(sm, session.Mindmap, nodeIdentifier, useID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	// Storage replaces all the content of a node on update, so the other fields are passed along
+	content := maps.Clone(node.Content)
+	if content == nil {
+		content = make(map[string]string)
+	}
+	content[model.RemindField] = value
+
+	err = sm.dataManager.NodeManager.NodeUpdate(session.Mindmap, node, model.NodeInfo{Content: content}, model.NodeFilter{Content: true})
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to update node reminder", log.Fields{"error": err, "nodeID": node.ID})
+		return nil, fmt.Errorf("failed to set reminder: %w", err)
+	}
+
+	if value == "" {
+		sm.logger.Info(ctx, "Node reminder cleared", log.Fields{"nodeID": node.ID})
+		if node.Content[model.DueField] != "" {
+			return fmt.Sprintf("Reminder of %s cleared, it is reminded of at its due time", node.Name), nil
+		}
+		return fmt.Sprintf("Reminder of %s cleared", node.Name), nil
+	}
+	sm.logger.Info(ctx, "Node reminder set", log.Fields{"nodeID": node.ID, "at": at})
+	if !at.After(time.Now()) {
+		return fmt.Sprintf("Reminder of %s set to %s, which has passed", node.Name, at.Format("2006-01-02 15:04")), nil
+	}
+	return fmt.Sprintf("Reminder of %s set to %s", node.Name, at.Format("2006-01-02 15:04")), nil
+}
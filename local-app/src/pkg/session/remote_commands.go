@@ -0,0 +1,40 @@
+package session
+
+import (
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// remoteCommands are the commands network clients may run, by scope and operation, a nil operation set allowing
+// all operations of the scope. The others act on the whole server, such as replaying journals, maintaining the
+// database, adding users and the admin commands, or on files of the server, such as imports, exports and
+// attachments, and are only run on the CLI.
+var remoteCommands = map[string]map[string]bool{
+	"user": {"select": true, "update": true, "delete": true, "capture": true, "calendar": true, "default": true, "inbox": true},
+	"mindmap": {"add": true, "delete": true, "permission": true, "select": true, "list": true, "view": true, "check": true,
+		"changes": true, "compare": true, "reindex": true, "restore": true, "checkpoint": true, "history": true,
+		"revert": true, "set": true, "graph": true, "exists": true},
+	"node": {"add": true, "update": true, "move": true, "copy": true, "indent": true, "outdent": true, "swap": true,
+		"rotate": true, "field": true, "backlinks": true, "remind": true, "private": true, "wikilink": true,
+		"delete": true, "find": true, "tag": true, "link": true, "exists": true, "count": true, "sort": true},
+	"journal": nil,
+	"add":     nil,
+	"system":  {"help": true, "ping": true, "jobs": true},
+}
+
+// remoteCommandCheck refuses the commands network clients may not run, in sessions that authenticate their users.
+// The command is expanded, so that concise forms are checked as the commands they run.
+func remoteCommandCheck(session *model.Session, cmd model.Command) error {
+	if !session.Authenticate {
+		return nil
+	}
+	operations, exists := remoteCommands[cmd.Scope]
+	if exists && (operations == nil || operations[cmd.Operation]) {
+		return nil
+	}
+	if cmd.Operation == "" {
+		return fmt.Errorf("%s is only available on the CLI", cmd.Scope)
+	}
+	return fmt.Errorf("%s %s is only available on the CLI", cmd.Scope, cmd.Operation)
+}
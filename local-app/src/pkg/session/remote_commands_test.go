@@ -0,0 +1,60 @@
+package session
+
+import (
+	"testing"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+func TestRemoteCommandCheck(t *testing.T) {
+	sm := &SessionManager{}
+	remote := &model.Session{Authenticate: true}
+	for _, c := range []struct {
+		scope, operation string
+		allowed          bool
+	}{
+		{"node", "add", true},
+		{"n", "a", true},
+		{"add", "", true},
+		{"user", "select", true},
+		{"user", "add", false},
+		{"system", "replay", false},
+		{"s", "r", false},
+		{"system", "gc", false},
+		{"system", "db", false},
+		{"system", "backup", false},
+		{"system", "transcript", false},
+		{"admin", "audit", false},
+		{"admin", "telemetry", false},
+		{"mindmap", "import", false},
+		{"node", "edit", false},
+		{"unknown", "", false},
+	} {
+		scope, operation := sm.expandCommand(c.scope, c.operation)
+		cmd := model.Command{Scope: scope, Operation: operation}
+		if err := remoteCommandCheck(remote, cmd); (err == nil) != c.allowed {
+			t.Errorf("%s %s: got error %v, want allowed %v", c.scope, c.operation, err, c.allowed)
+		}
+		if err := remoteCommandCheck(&model.Session{}, cmd); err != nil {
+			t.Errorf("%s %s refused on the CLI: %v", c.scope, c.operation, err)
+		}
+	}
+}
+
+// TestRemoteCommandsExist checks that the allowed commands name existing handlers, so a renamed operation isn't
+// silently refused
+func TestRemoteCommandsExist(t *testing.T) {
+	sm := &SessionManager{}
+	sm.initCommandHandlers()
+	for scope, operations := range remoteCommands {
+		handlers, exists := sm.commandHandlers[scope]
+		if !exists {
+			t.Errorf("remote scope %s has no handlers", scope)
+		}
+		for operation := range operations {
+			if _, exists := handlers[operation]; !exists {
+				t.Errorf("remote command %s %s has no handler", scope, operation)
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// selectNodes expands node selectors into the nodes of a mindmap, in document order and without duplicates.
+// A selector is an index ("1.2"), an index pattern matched segment by segment ("2.*", "1.[13]") or a range of
+// siblings ("1.2-1.5"). With useID, selectors are node IDs or ID ranges ("4-9") instead.
+// Every selector must select at least one node.
+func selectNodes(sm *SessionManager, mindmap *model.Mindmap, selectors []string, useID bool) ([]*model.Node, error) {
+	ctx := context.Background()
+	sm.logger.Debug(ctx, "Selecting nodes", log.Fields{"selectors": selectors, "useID": useID})
+
+	selected := make(map[int]bool)
+	for _, selector := range selectors {
+		match, err := selectorMatch(selector, sm.DisplayStyle(), useID)
+		if err != nil {
+			sm.logger.Error(ctx, "Invalid node selector", log.Fields{"selector": selector, "error": err})
+			return nil, err
+		}
+
+		found := false
+		for node := range mindmap.Subtree(nil, match) {
+			selected[node.ID] = true
+			found = true
+		}
+		if !found {
+			sm.logger.Warn(ctx, "Node selector matched no nodes", log.Fields{"selector": selector})
+			return nil, fmt.Errorf("node not found: %s", selector)
+		}
+	}
+
+	var nodes []*model.Node
+	for node := range mindmap.Subtree(nil, func(n *model.Node) bool { return selected[n.ID] }) {
+		nodes = append(nodes, node)
+	}
+
+	sm.logger.Debug(ctx, "Nodes selected", log.Fields{"selectors": selectors, "nodeCount": len(nodes)})
+	return nodes, nil
+}
+
+// selectorMatch returns the node match of a single selector, whose indexes are displayed indexes of the style
+func selectorMatch(selector string, style model.DisplayStyle, useID bool) (model.NodeMatch, error) {
+	if useID {
+		from, to, isRange := strings.Cut(selector, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node ID: %s", selector)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil || last < first {
+				return nil, fmt.Errorf("invalid node ID range: %s", selector)
+			}
+		}
+		return func(n *model.Node) bool { return n.ID >= first && n.ID <= last }, nil
+	}
+
+	if from, to, isRange := strings.Cut(selector, "-"); isRange {
+		parent, first, ok1 := splitIndex(from)
+		toParent, last, ok2 := splitIndex(to)
+		if !ok1 || !ok2 || parent != toParent || last < first {
+			return nil, fmt.Errorf("invalid node range, expected siblings such as 1.2-1.5: %s", selector)
+		}
+		return func(n *model.Node) bool {
+			p, position, ok := splitIndex(style.DisplayIndex(n.Index))
+			return ok && p == parent && position >= first && position <= last
+		}, nil
+	}
+
+	if strings.ContainsAny(selector, "*?[") {
+		segments := strings.Split(selector, ".")
+		for _, segment := range segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid node pattern: %s", selector)
+			}
+		}
+		return func(n *model.Node) bool {
+			indexSegments := strings.Split(style.DisplayIndex(n.Index), ".")
+			if n.ID == 0 || len(indexSegments) != len(segments) {
+				return false
+			}
+			for i, segment := range segments {
+				if ok, _ := path.Match(segment, indexSegments[i]); !ok {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}
+
+	index := style.ParseIndex(selector)
+	return func(n *model.Node) bool { return n.Index == index }, nil
+}
+
+// splitIndex splits a node index into the index of its parent and its position among its siblings. The parent of
+// the top-level nodes is empty, as the index of the root may be a position in other display styles.
+func splitIndex(index string) (string, int, bool) {
+	parent, last := "", index
+	if i := strings.LastIndex(index, "."); i >= 0 {
+		parent, last = index[:i], index[i+1:]
+	}
+	position, err := strconv.Atoi(last)
+	return parent, position, err == nil
+}
+
+// topmostNodes drops the nodes that are in the subtree of another of the given nodes
+func topmostNodes(mindmap *model.Mindmap, nodes []*model.Node) []*model.Node {
+	selected := make(map[int]bool, len(nodes))
+	for _, node := range nodes {
+		selected[node.ID] = true
+	}
+
+	var topmost []*model.Node
+	for _, node := range nodes {
+		nested := false
+		for parent := mindmap.Nodes[node.ParentID]; parent != nil && parent.ID != 0; parent = mindmap.Nodes[parent.ParentID] {
+			if selected[parent.ID] {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			topmost = append(topmost, node)
+		}
+	}
+	return topmost
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"mindnoscape/local-app/src/pkg/data"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// newTestSessionManager returns a session manager with only a configuration and a logger, enough for the helpers
+// of the handlers that don't reach the storage
+func newTestSessionManager(t *testing.T, cfg *model.Config) *SessionManager {
+	t.Helper()
+	cfg.LogFolder = t.TempDir()
+	cfg.CommandLog, cfg.ErrorLog, cfg.InfoLog = "command.log", "error.log", "info.log"
+	logger, err := log.NewLogger(cfg, log.LevelError)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { logger.Close() })
+	return &SessionManager{dataManager: &data.DataManager{Config: cfg}, logger: logger}
+}
+
+// newTestMindmap returns a mindmap of the nodes of the given indexes below the root, the parents listed before
+// their children. Node IDs are their position in indexes, from 1.
+func newTestMindmap(indexes ...string) *model.Mindmap {
+	root := &model.Node{ID: 0, ParentID: -1, Name: "root", Index: "0"}
+	mindmap := &model.Mindmap{ID: 1, Name: "test", Root: root, Nodes: map[int]*model.Node{0: root}}
+	byIndex := map[string]int{"": 0}
+	for i, index := range indexes {
+		parent := ""
+		if dot := strings.LastIndex(index, "."); dot >= 0 {
+			parent = index[:dot]
+		}
+		mindmap.Nodes[i+1] = &model.Node{ID: i + 1, ParentID: byIndex[parent], Name: "N" + index, Index: index}
+		byIndex[index] = i + 1
+	}
+	mindmap.LinkChildren()
+	return mindmap
+}
+
+// testIndexes returns the indexes of nodes
+func testIndexes(nodes []*model.Node) []string {
+	indexes := make([]string, len(nodes))
+	for i, node := range nodes {
+		indexes[i] = node.Index
+	}
+	return indexes
+}
+
+func TestSelectNodes(t *testing.T) {
+	mindmap := newTestMindmap("1", "1.1", "1.2", "1.3", "1.4", "1.5", "1.6", "2", "2.1", "2.2", "2.2.1", "3", "10")
+
+	tests := []struct {
+		name      string
+		selectors []string
+		useID     bool
+		want      []string
+		wantErr   string
+	}{
+		{name: "index", selectors: []string{"1.2"}, want: []string{"1.2"}},
+		{name: "root", selectors: []string{"0"}, want: []string{"0"}},
+		{name: "children pattern", selectors: []string{"2.*"}, want: []string{"2.1", "2.2"}},
+		{name: "top-level pattern", selectors: []string{"*"}, want: []string{"1", "2", "3", "10"}},
+		{name: "single digit pattern", selectors: []string{"?"}, want: []string{"1", "2", "3"}},
+		{name: "class pattern", selectors: []string{"1.[13]"}, want: []string{"1.1", "1.3"}},
+		{name: "grandchildren pattern", selectors: []string{"*.*.*"}, want: []string{"2.2.1"}},
+		{name: "sibling range", selectors: []string{"1.2-1.5"}, want: []string{"1.2", "1.3", "1.4", "1.5"}},
+		{name: "top-level range", selectors: []string{"2-10"}, want: []string{"2", "3", "10"}},
+		{name: "document order", selectors: []string{"2.1", "1.3", "1"}, want: []string{"1", "1.3", "2.1"}},
+		{name: "overlapping", selectors: []string{"1.2-1.4", "1.3-1.5", "1.*"}, want: []string{"1.1", "1.2", "1.3", "1.4", "1.5", "1.6"}},
+		{name: "duplicate", selectors: []string{"2.2", "2.2"}, want: []string{"2.2"}},
+		{name: "ID", selectors: []string{"9"}, useID: true, want: []string{"2.1"}},
+		{name: "ID range", selectors: []string{"2-4"}, useID: true, want: []string{"1.1", "1.2", "1.3"}},
+		{name: "missing index", selectors: []string{"4"}, wantErr: "node not found: 4"},
+		{name: "one selector missing", selectors: []string{"1", "1.7"}, wantErr: "node not found: 1.7"},
+		{name: "pattern without match", selectors: []string{"3.*"}, wantErr: "node not found: 3.*"},
+		{name: "range of cousins", selectors: []string{"1.2-2.1"}, wantErr: "invalid node range"},
+		{name: "reversed range", selectors: []string{"1.5-1.2"}, wantErr: "invalid node range"},
+		{name: "open range", selectors: []string{"1.2-"}, wantErr: "invalid node range"},
+		{name: "malformed pattern", selectors: []string{"1.[2"}, wantErr: "invalid node pattern"},
+		{name: "malformed ID", selectors: []string{"x"}, useID: true, wantErr: "invalid node ID"},
+		{name: "reversed ID range", selectors: []string{"5-2"}, useID: true, wantErr: "invalid node ID range"},
+	}
+
+	sm := newTestSessionManager(t, &model.Config{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := selectNodes(sm, mindmap, tt.selectors, tt.useID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("selectNodes(%q) error = %v, want %q", tt.selectors, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectNodes(%q) failed: %v", tt.selectors, err)
+			}
+			if got := testIndexes(nodes); !slices.Equal(got, tt.want) {
+				t.Errorf("selectNodes(%q) = %q, want %q", tt.selectors, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorMatchZeroBased(t *testing.T) {
+	// Displayed indexes count from 0, so 0.* are the children of the first top-level node, stored as 1.*
+	style := model.DisplayStyle{ZeroBasedIndex: true}
+	mindmap := newTestMindmap("1", "1.1", "1.2", "1.3", "2")
+
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{"0", []string{"1"}},
+		{"0.*", []string{"1.1", "1.2", "1.3"}},
+		{"0.1-0.2", []string{"1.2", "1.3"}},
+		{"0-1", []string{"1", "2"}},
+		{model.RootAlias, []string{"0"}},
+	}
+
+	for _, tt := range tests {
+		match, err := selectorMatch(tt.selector, style, false)
+		if err != nil {
+			t.Fatalf("selectorMatch(%q) failed: %v", tt.selector, err)
+		}
+		if got := testIndexes(slices.Collect(mindmap.Subtree(nil, match))); !slices.Equal(got, tt.want) {
+			t.Errorf("selectorMatch(%q) selects %q, want %q", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestTopmostNodes(t *testing.T) {
+	mindmap := newTestMindmap("1", "1.1", "1.1.1", "1.2", "2")
+	nodes := []*model.Node{mindmap.Nodes[1], mindmap.Nodes[3], mindmap.Nodes[4], mindmap.Nodes[5]}
+	if got, want := testIndexes(topmostNodes(mindmap, nodes)), []string{"1", "2"}; !slices.Equal(got, want) {
+		t.Errorf("topmostNodes = %q, want %q", got, want)
+	}
+}
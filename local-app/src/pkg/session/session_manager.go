@@ -6,9 +6,12 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"maps"
+	"sync"
 	"time"
 
 	"mindnoscape/local-app/src/pkg/data"
+	"mindnoscape/local-app/src/pkg/event"
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
 )
@@ -19,24 +22,47 @@ const (
 	defaultSessionTimeout  = 30 * time.Minute
 )
 
+// mutatingCommands lists the operations per scope that change persistent data
+var mutatingCommands = map[string]map[string]bool{
+	"user":    {"add": true, "update": true, "delete": true, "capture": true, "calendar": true, "default": true, "inbox": true},
+	"mindmap": {"add": true, "delete": true, "permission": true, "import": true, "reindex": true, "set": true, "restore": true, "checkpoint": true, "revert": true},
+	"journal": {"today": true},
+	"add":     {"": true},
+	"node":    {"add": true, "update": true, "move": true, "copy": true, "indent": true, "outdent": true, "swap": true, "rotate": true, "field": true, "wikilink": true, "remind": true, "private": true, "delete": true, "sort": true, "tag": true, "link": true, "attach": true},
+}
+
+// isMutatingCommand reports whether the command changes persistent data
+func isMutatingCommand(cmd model.Command) bool {
+	return mutatingCommands[cmd.Scope][cmd.Operation]
+}
+
 // CommandHandler is a function type for command handlers
 type CommandHandler func(*SessionManager, *model.Session, model.Command) (interface{}, error)
 
 // SessionManager manages multiple concurrent sessions
 type SessionManager struct {
 	sessions        map[string]*model.Session
+	sessionMutex    sync.RWMutex // Guards sessions and rateWindows, used by the adapters, executor and routines
 	dataManager     *data.DataManager
 	cleanupTicker   *time.Ticker
 	done            chan bool
+	captureDone     chan struct{}
+	reminderDone    chan struct{}
+	backupDone      chan struct{}
 	commandQueue    chan commandExecution
 	logger          *log.Logger
 	commandHandlers map[string]map[string]CommandHandler
+	middleware      []middlewareEntry
+	rateWindows     map[string]*rateWindow
+	jobs            jobList // Background jobs of the sessions, see jobRun
+	replaying       bool    // Set while a replay applies journaled commands
 }
 
-// commandExecution represents a command to be executed in a session, its result and error
+// commandExecution represents a command to be executed in a session, or an internal task, its result and error
 type commandExecution struct {
 	session *model.Session
 	command model.Command
+	task    func() (interface{}, error) // Run instead of a command, without the middleware
 	result  chan interface{}
 	err     chan error
 }
@@ -50,12 +76,20 @@ func NewSessionManager(dataManager *data.DataManager, logger *log.Logger) *Sessi
 		sessions:     make(map[string]*model.Session),
 		dataManager:  dataManager,
 		done:         make(chan bool),
+		captureDone:  make(chan struct{}),
+		reminderDone: make(chan struct{}),
+		backupDone:   make(chan struct{}),
 		commandQueue: make(chan commandExecution),
 		logger:       logger,
+		rateWindows:  make(map[string]*rateWindow),
 	}
 	sm.startCleanupRoutine()
 	sm.initCommandHandlers()
+	sm.initMiddleware()
 	go sm.commandExecutor()
+	sm.startCaptureRoutine()
+	sm.startReminderRoutine()
+	sm.startBackupRoutine()
 
 	logger.Info(ctx, "SessionManager created successfully", nil)
 	return sm
@@ -76,17 +110,57 @@ func (sm *SessionManager) SessionAdd() (*model.Session, error) {
 		ID:           sessionID,
 		LastActivity: time.Now(),
 	}
+	sm.sessionMutex.Lock()
 	sm.sessions[sessionID] = session
+	sm.sessionMutex.Unlock()
 	sm.logger.Info(ctx, "New session added", log.Fields{"sessionID": sessionID})
 	return session, nil
 }
 
+// Subscribe registers a handler for events of the given type, for adapters to react to events such as SessionExpired
+func (sm *SessionManager) Subscribe(eventType event.EventType, handler event.EventHandler) {
+	sm.dataManager.EventManager.Subscribe(eventType, handler)
+}
+
+// ReadOnly reports whether the database is opened read-only, refusing the commands that change it
+func (sm *SessionManager) ReadOnly() bool {
+	return sm.dataManager.Config.ReadOnly
+}
+
+// WebAddress returns the configured address of the WebSocket adapter, empty if it is off
+func (sm *SessionManager) WebAddress() string {
+	return sm.dataManager.Config.WebAddress
+}
+
+// GRPCAddress returns the configured address of the gRPC adapter, empty if it is off
+func (sm *SessionManager) GRPCAddress() string {
+	return sm.dataManager.Config.GRPCAddress
+}
+
+// MaxConnections returns the configured number of clients each adapter serving them takes at once, 0 or less for
+// any number
+func (sm *SessionManager) MaxConnections() int {
+	return sm.dataManager.Config.MaxConnections
+}
+
+// ShutdownTimeout returns how long the running commands of clients are waited for when serving them stops
+func (sm *SessionManager) ShutdownTimeout() time.Duration {
+	return time.Duration(max(sm.dataManager.Config.ShutdownTimeout, 0)) * time.Second
+}
+
+// PromptTemplate returns the configured template of the CLI prompt
+func (sm *SessionManager) PromptTemplate() string {
+	return sm.dataManager.Config.Prompt
+}
+
 // SessionGet retrieves a session by its ID
 func (sm *SessionManager) SessionGet(sessionID string) (*model.Session, bool) {
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Retrieving session", log.Fields{"sessionID": sessionID})
 
+	sm.sessionMutex.RLock()
 	session, exists := sm.sessions[sessionID]
+	sm.sessionMutex.RUnlock()
 	if !exists {
 		sm.logger.Warn(ctx, "Session not found", log.Fields{"sessionID": sessionID})
 		return nil, false
@@ -101,12 +175,16 @@ func (sm *SessionManager) SessionDelete(sessionID string) {
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Deleting session", log.Fields{"sessionID": sessionID})
 
+	sm.sessionMutex.Lock()
+	defer sm.sessionMutex.Unlock()
 	if _, exists := sm.sessions[sessionID]; !exists {
 		sm.logger.Warn(ctx, "Attempted to delete non-existent session", log.Fields{"sessionID": sessionID})
 		return
 	}
 
 	delete(sm.sessions, sessionID)
+	delete(sm.rateWindows, sessionID)
+	sm.jobs.drop(sessionID)
 	sm.logger.Info(ctx, "Session deleted", log.Fields{"sessionID": sessionID})
 }
 
@@ -116,19 +194,16 @@ func (sm *SessionManager) SessionRun(sessionID string, cmd model.Command) (inter
 	sm.logger.Info(ctx, "Running command in session", log.Fields{"sessionID": sessionID, "command": cmd})
 
 	// Validate the session
-	// TODO: use SessionGet
-	session, exists := sm.sessions[sessionID]
+	session, exists := sm.SessionGet(sessionID)
 	if !exists {
 		sm.logger.Error(ctx, "Session not found", log.Fields{"sessionID": sessionID})
 		return nil, errors.New("session not found")
 	}
 
-	// Expand the command
+	// Expand the command, it is validated by the middleware chain
 	cmd.Scope, cmd.Operation = sm.expandCommand(cmd.Scope, cmd.Operation)
-
-	// Validate the command
-	if err := sm.validateCommand(cmd); err != nil {
-		sm.logger.Error(ctx, "Command validation failed", log.Fields{"sessionID": sessionID, "error": err})
+	if err := remoteCommandCheck(session, cmd); err != nil {
+		sm.logger.Warn(ctx, "Command refused to network client", log.Fields{"sessionID": sessionID, "scope": cmd.Scope, "operation": cmd.Operation})
 		return nil, err
 	}
 
@@ -152,6 +227,32 @@ func (sm *SessionManager) SessionRun(sessionID string, cmd model.Command) (inter
 	}
 }
 
+// NodeGet returns a copy of a node of the mindmap selected in a session, by index or ID, for adapters that
+// prepare commands from the current content of a node
+func (sm *SessionManager) NodeGet(sessionID string, identifier string, useID bool) (*model.Node, error) {
+	session, exists := sm.SessionGet(sessionID)
+	if !exists {
+		return nil, errors.New("session not found")
+	}
+
+	result, err := sm.runTask(func() (interface{}, error) {
+		if session.Mindmap == nil {
+			return nil, errors.New("no mindmap selected")
+		}
+		node, err := getNode(sm, session.Mindmap, identifier, useID)
+		if err != nil {
+			return nil, err
+		}
+		clone := *node
+		clone.Content = maps.Clone(node.Content)
+		return &clone, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*model.Node), nil
+}
+
 // startCleanupRoutine starts a goroutine that periodically cleans up inactive sessions
 func (sm *SessionManager) startCleanupRoutine() {
 	ctx := context.Background()
@@ -162,7 +263,11 @@ func (sm *SessionManager) startCleanupRoutine() {
 		for {
 			select {
 			case <-sm.cleanupTicker.C:
-				sm.cleanupInactiveSessions()
+				// Cleaned up on the executor, so a session is not removed while it runs a command
+				sm.runTask(func() (interface{}, error) {
+					sm.cleanupInactiveSessions()
+					return nil, nil
+				})
 			case <-sm.done:
 				sm.logger.Info(ctx, "Stopped cleanup routine", nil)
 				sm.cleanupTicker.Stop()
@@ -188,6 +293,10 @@ func (sm *SessionManager) expandCommand(scope, operation string) (string, string
 			expandedScope = "mindmap"
 		case "n":
 			expandedScope = "node"
+		case "a":
+			expandedScope = "admin"
+		case "j":
+			expandedScope = "journal"
 		}
 	}
 
@@ -236,6 +345,12 @@ func (sm *SessionManager) expandCommand(scope, operation string) (string, string
 				expandedOperation = "update"
 			case "m":
 				expandedOperation = "move"
+			case "c":
+				expandedOperation = "copy"
+			case "i":
+				expandedOperation = "indent"
+			case "o":
+				expandedOperation = "outdent"
 			case "d":
 				expandedOperation = "delete"
 			case "f":
@@ -243,6 +358,20 @@ func (sm *SessionManager) expandCommand(scope, operation string) (string, string
 			case "s":
 				expandedOperation = "sort"
 			}
+		case "journal":
+			switch operation {
+			case "t":
+				expandedOperation = "today"
+			}
+		case "admin":
+			switch operation {
+			case "a":
+				expandedOperation = "audit"
+			case "e":
+				expandedOperation = "events"
+			case "t":
+				expandedOperation = "telemetry"
+			}
 		case "system":
 			switch operation {
 			case "e":
@@ -251,6 +380,16 @@ func (sm *SessionManager) expandCommand(scope, operation string) (string, string
 				expandedOperation = "quit"
 			case "h":
 				expandedOperation = "help"
+			case "r":
+				expandedOperation = "replay"
+			case "p":
+				expandedOperation = "ping"
+			case "d":
+				expandedOperation = "db"
+			case "t":
+				expandedOperation = "transcript"
+			case "v":
+				expandedOperation = "version"
 			}
 		}
 	}
@@ -265,16 +404,23 @@ func (sm *SessionManager) initCommandHandlers() {
 		"mindmap": initMindmapCommandHandlers(),
 		"node":    initNodeCommandHandlers(),
 		"system":  initSystemCommandHandlers(),
+		"journal": initJournalCommandHandlers(),
+		"add":     {"": handleAdd},
+		"admin":   initAdminCommandHandlers(),
 	}
 }
 
 // initUserCommandHandlers initializes user command handlers
 func initUserCommandHandlers() map[string]CommandHandler {
 	return map[string]CommandHandler{
-		"add":    handleUserAdd,
-		"update": handleUserUpdate,
-		"delete": handleUserDelete,
-		"select": handleUserSelect,
+		"add":      handleUserAdd,
+		"update":   handleUserUpdate,
+		"delete":   handleUserDelete,
+		"select":   handleUserSelect,
+		"capture":  handleUserCapture,
+		"calendar": handleUserCalendar,
+		"default":  handleUserDefault,
+		"inbox":    handleUserInbox,
 	}
 }
 
@@ -289,27 +435,78 @@ func initMindmapCommandHandlers() map[string]CommandHandler {
 		"select":     handleMindmapSelect,
 		"list":       handleMindmapList,
 		"view":       handleMindmapView,
+		"check":      handleMindmapCheck,
+		"changes":    handleMindmapChanges,
+		"compare":    handleMindmapCompare,
+		"reindex":    handleMindmapReindex,
+		"restore":    handleMindmapRestore,
+		"checkpoint": handleMindmapCheckpoint,
+		"history":    handleMindmapHistory,
+		"revert":     handleMindmapRevert,
+		"set":        handleMindmapSet,
+		"graph":      handleMindmapGraph,
+		"summary":    handleMindmapSummary,
+		"exists":     handleMindmapExists,
 	}
 }
 
 // initNodeCommandHandlers initializes node command handlers
 func initNodeCommandHandlers() map[string]CommandHandler {
 	return map[string]CommandHandler{
-		"add":    handleNodeAdd,
-		"update": handleNodeUpdate,
-		"move":   handleNodeMove,
-		"delete": handleNodeDelete,
-		"find":   handleNodeFind,
-		"sort":   handleNodeSort,
+		"add":       handleNodeAdd,
+		"update":    handleNodeUpdate,
+		"move":      handleNodeMove,
+		"copy":      handleNodeCopy,
+		"indent":    handleNodeIndent,
+		"outdent":   handleNodeOutdent,
+		"swap":      handleNodeSwap,
+		"rotate":    handleNodeRotate,
+		"field":     handleNodeField,
+		"backlinks": handleNodeBacklinks,
+		"remind":    handleNodeRemind,
+		"private":   handleNodePrivate,
+		"wikilink":  handleNodeWikilink,
+		"delete":    handleNodeDelete,
+		"find":      handleNodeFind,
+		"tag":       handleNodeTag,
+		"link":      handleNodeLink,
+		"attach":    handleNodeAttach,
+		"exists":    handleNodeExists,
+		"count":     handleNodeCount,
+		"sort":      handleNodeSort,
 	}
 }
 
 // initSystemCommandHandlers initializes system command handlers
 func initSystemCommandHandlers() map[string]CommandHandler {
 	return map[string]CommandHandler{
-		"help": handleSystemHelp,
-		"exit": handleSystemExit,
-		"quit": handleSystemExit,
+		"help":       handleSystemHelp,
+		"replay":     handleSystemReplay,
+		"ping":       handleSystemPing,
+		"db":         handleSystemDB,
+		"backup":     handleSystemBackup,
+		"jobs":       handleSystemJobs,
+		"version":    handleSystemVersion,
+		"transcript": handleSystemTranscript,
+		"exit":       handleSystemExit,
+		"quit":       handleSystemExit,
+		"gc":         handleSystemGC,
+	}
+}
+
+// initJournalCommandHandlers initializes journal command handlers
+func initJournalCommandHandlers() map[string]CommandHandler {
+	return map[string]CommandHandler{
+		"today": handleJournalToday,
+	}
+}
+
+// initAdminCommandHandlers initializes admin command handlers
+func initAdminCommandHandlers() map[string]CommandHandler {
+	return map[string]CommandHandler{
+		"audit":     handleAdminAudit,
+		"events":    handleAdminEvents,
+		"telemetry": handleAdminTelemetry,
 	}
 }
 
@@ -319,21 +516,20 @@ func (sm *SessionManager) commandExecutor() {
 	sm.logger.Info(ctx, "Starting command executor", nil)
 
 	for cmd := range sm.commandQueue {
-		sm.logger.Debug(ctx, "Processing command", log.Fields{"sessionID": cmd.session.ID, "command": cmd.command})
-
-		scopeHandlers, ok := sm.commandHandlers[cmd.command.Scope]
-		if !ok {
-			cmd.err <- fmt.Errorf("invalid command scope: %s", cmd.command.Scope)
+		if cmd.task != nil {
+			result, err := cmd.task()
+			if err != nil {
+				cmd.err <- err
+			} else {
+				cmd.result <- result
+			}
 			continue
 		}
 
-		handler, ok := scopeHandlers[cmd.command.Operation]
-		if !ok {
-			cmd.err <- fmt.Errorf("invalid command operation: %s", cmd.command.Operation)
-			continue
-		}
+		sm.logger.Debug(ctx, "Processing command", log.Fields{"sessionID": cmd.session.ID, "command": cmd.command})
+		cmd.session.LastActivity = time.Now()
 
-		result, err := handler(sm, cmd.session, cmd.command)
+		result, err := sm.commandRun(cmd.session, cmd.command)
 		if err != nil {
 			sm.logger.Error(ctx, "Command execution failed", log.Fields{"sessionID": cmd.session.ID, "error": err})
 			cmd.err <- err
@@ -344,6 +540,34 @@ func (sm *SessionManager) commandExecutor() {
 	}
 }
 
+// runTask runs an internal task on the command executor, in order with the commands of all sessions, and returns
+// its result. It must not be called by the command executor itself.
+func (sm *SessionManager) runTask(task func() (interface{}, error)) (interface{}, error) {
+	result := make(chan interface{})
+	err := make(chan error)
+	sm.commandQueue <- commandExecution{task: task, result: result, err: err}
+
+	select {
+	case res := <-result:
+		return res, nil
+	case e := <-err:
+		return nil, e
+	}
+}
+
+// commandRun runs a command through the middleware chain of its scope. It must only be called by the command
+// executor, directly or from a handler.
+func (sm *SessionManager) commandRun(session *model.Session, cmd model.Command) (interface{}, error) {
+	handler, ok := sm.commandHandlers[cmd.Scope][cmd.Operation]
+	if !ok {
+		// Commands without a handler are normally rejected by validation already
+		handler = func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+			return nil, fmt.Errorf("invalid command: %s %s", cmd.Scope, cmd.Operation)
+		}
+	}
+	return sm.commandChain(cmd.Scope, handler)(sm, session, cmd)
+}
+
 // StopCleanupRoutine stops the cleanup routine
 func (sm *SessionManager) StopCleanupRoutine() {
 	ctx := context.Background()
@@ -351,18 +575,29 @@ func (sm *SessionManager) StopCleanupRoutine() {
 	sm.done <- true
 }
 
-// cleanupInactiveSessions removes inactive sessions
+// cleanupInactiveSessions removes inactive sessions, publishing a SessionExpired event with the ID of each
 func (sm *SessionManager) cleanupInactiveSessions() {
 	ctx := context.Background()
 	sm.logger.Debug(ctx, "Running cleanup for inactive sessions", nil)
 
 	now := time.Now()
+	var expired []string
+	sm.sessionMutex.Lock()
 	for id, session := range sm.sessions {
 		if now.Sub(session.LastActivity) > defaultSessionTimeout {
-			sm.logger.Info(ctx, "Removing inactive session", log.Fields{"sessionID": id})
-			sm.SessionDelete(id)
+			delete(sm.sessions, id)
+			delete(sm.rateWindows, id)
+			expired = append(expired, id)
 		}
 	}
+	sm.sessionMutex.Unlock()
+
+	// Published once the lock is released, the handlers may look up sessions
+	for _, id := range expired {
+		sm.jobs.drop(id)
+		sm.logger.Info(ctx, "Removed inactive session", log.Fields{"sessionID": id})
+		sm.dataManager.EventManager.Publish(event.Event{Type: event.SessionExpired, Data: id})
+	}
 }
 
 // generateSessionID creates a cryptographically secure random session ID
@@ -399,6 +634,12 @@ func (sm *SessionManager) validateScopeAndOperation(cmd model.Command) error {
 		return sm.validateNodeCommand(cmd)
 	case "system":
 		return sm.validateSystemCommand(cmd)
+	case "journal":
+		return sm.validateJournalCommand(cmd)
+	case "add":
+		return sm.validateAddCommand(cmd)
+	case "admin":
+		return sm.validateAdminCommand(cmd)
 	default:
 		sm.logger.Error(ctx, "Invalid command scope", log.Fields{"scope": cmd.Scope})
 		return fmt.Errorf("invalid command scope: %s", cmd.Scope)
@@ -420,11 +661,36 @@ func (sm *SessionManager) validateUserCommand(cmd model.Command) error {
 			sm.logger.Error(ctx, "Invalid number of arguments for user update command", log.Fields{"argCount": len(cmd.Args)})
 			return errors.New("user update command requires 1 to 3 arguments: <username> [new_username] [new_password]")
 		}
-	case "delete", "select":
+	case "delete":
 		if len(cmd.Args) != 1 {
 			sm.logger.Error(ctx, "Invalid number of arguments for user command", log.Fields{"operation": cmd.Operation, "argCount": len(cmd.Args)})
 			return fmt.Errorf("user %s command requires 1 argument: <username>", cmd.Operation)
 		}
+	case "select":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for user select command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("user select command requires 1 or 2 arguments: <username> [password]")
+		}
+	case "capture":
+		if len(cmd.Args) == 0 {
+			sm.logger.Error(ctx, "Missing arguments for user capture command", nil)
+			return errors.New("user capture command requires arguments: set <server> <login> <password> <mindmap> [mailbox] | show | clear")
+		}
+	case "calendar":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for user calendar command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("user calendar command requires arguments: set [mindmap] | show | clear [mindmap]")
+		}
+	case "default":
+		if len(cmd.Args) > 1 {
+			sm.logger.Error(ctx, "Invalid number of arguments for user default command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("user default command accepts at most 1 argument: [mindmap]")
+		}
+	case "inbox":
+		if len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for user inbox command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("user inbox command accepts at most 2 arguments: [node] [--id]")
+		}
 	default:
 		sm.logger.Error(ctx, "Invalid user operation", log.Fields{"operation": cmd.Operation})
 		return fmt.Errorf("invalid user operation: %s", cmd.Operation)
@@ -452,20 +718,85 @@ func (sm *SessionManager) validateMindmapCommand(cmd model.Command) error {
 			sm.logger.Error(ctx, "Invalid number of arguments for mindmap permission command", log.Fields{"argCount": len(cmd.Args)})
 			return errors.New("mindmap permission command requires 1 or 2 arguments: <mindmap_name> [public|private]")
 		}
-	case "import", "export":
-		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
-			sm.logger.Error(ctx, "Invalid number of arguments for mindmap import/export command", log.Fields{"operation": cmd.Operation, "argCount": len(cmd.Args)})
-			return fmt.Errorf("mindmap %s command requires 1 or 2 arguments: <filename> [json|xml]", cmd.Operation)
+	case "import":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 6 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap import command", log.Fields{"argCount": len(cmd.Args)})
+			return fmt.Errorf("mindmap import command requires 1 to 6 arguments: <filename> [%s] [--force] [--skip-existing|--update-existing|--duplicate|--sync] [--on-conflict %s]", formatChoice(importFormatNames()), formatChoice(conflictPolicyNames))
+		}
+	case "export":
+		if len(cmd.Args) > 13 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap export command", log.Fields{"argCount": len(cmd.Args)})
+			return fmt.Errorf("mindmap export command requires 0 to 13 arguments: [filename] [%s] [--node <node>] [--id] [--force] [--compress[=gz|zst]] [--sign] [--include-computed] [--redact <profile>] [--encrypt] [--clipboard]", formatChoice(exportFormatNames(nil)))
 		}
 	case "list":
-		if len(cmd.Args) != 0 {
+		if len(cmd.Args) > 4 {
 			sm.logger.Error(ctx, "Invalid number of arguments for mindmap list command", log.Fields{"argCount": len(cmd.Args)})
-			return errors.New("mindmap list command does not accept any arguments")
+			return errors.New("mindmap list command accepts at most 4 arguments: [--limit <n>] [--offset <n>]")
 		}
 	case "view":
-		if len(cmd.Args) > 2 {
+		if len(cmd.Args) > 4 {
 			sm.logger.Error(ctx, "Invalid number of arguments for mindmap view command", log.Fields{"argCount": len(cmd.Args)})
-			return errors.New("mindmap view command accepts at most 2 arguments: [index] [--id]")
+			return errors.New("mindmap view command accepts at most 4 arguments: [index] [--id] [--times] [--copy]")
+		}
+	case "check":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 5 || cmd.Args[0] != "links" {
+			sm.logger.Error(ctx, "Invalid arguments for mindmap check command", log.Fields{"args": cmd.Args})
+			return errors.New("mindmap check command requires 1 to 5 arguments: links [--http] [--timeout <seconds>] [--id]")
+		}
+	case "changes":
+		if len(cmd.Args) != 0 && (len(cmd.Args) != 2 || cmd.Args[0] != "--since") {
+			sm.logger.Error(ctx, "Invalid arguments for mindmap changes command", log.Fields{"args": cmd.Args})
+			return errors.New("mindmap changes command accepts at most 2 arguments: [--since <age|date>]")
+		}
+	case "compare":
+		if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap compare command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("mindmap compare command requires 2 or 3 arguments: <mindmap_a> <mindmap_b> [--similarity]")
+		}
+	case "reindex":
+		if len(cmd.Args) != 0 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap reindex command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("mindmap reindex command takes no arguments")
+		}
+	case "checkpoint":
+		if len(cmd.Args) == 0 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap checkpoint command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("mindmap checkpoint command requires a label: <label>")
+		}
+	case "history":
+		if len(cmd.Args) != 0 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap history command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("mindmap history command takes no arguments")
+		}
+	case "revert":
+		if len(cmd.Args) != 1 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap revert command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("mindmap revert command requires 1 argument: <version>")
+		}
+	case "restore":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap restore command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("mindmap restore command requires 1 or 2 arguments: <snapshot|latest> [mindmap name]")
+		}
+	case "exists":
+		if len(cmd.Args) != 1 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap exists command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("mindmap exists command requires 1 argument: <mindmap_name>")
+		}
+	case "graph":
+		if len(cmd.Args) > 5 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap graph command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("mindmap graph command accepts at most 5 arguments: [filename] [--format dot|json] [--tree] [--force]")
+		}
+	case "summary":
+		if len(cmd.Args) > 8 {
+			sm.logger.Error(ctx, "Invalid number of arguments for mindmap summary command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("mindmap summary command accepts at most 8 arguments: [node] [--depth <levels>] [--output <filename>] [--force] [--id]")
+		}
+	case "set":
+		if len(cmd.Args) < 2 || len(cmd.Args) > 7 || cmd.Args[0] != "sort" {
+			sm.logger.Error(ctx, "Invalid arguments for mindmap set command", log.Fields{"args": cmd.Args})
+			return errors.New("mindmap set command requires 2 to 7 arguments: sort <field>|none [asc|desc] [auto|text|number|date] [natural] [nocase] [locale:<tag>]")
 		}
 	default:
 		sm.logger.Error(ctx, "Invalid mindmap operation", log.Fields{"operation": cmd.Operation})
@@ -490,24 +821,94 @@ func (sm *SessionManager) validateNodeCommand(cmd model.Command) error {
 			return errors.New("node update command requires at least 2 arguments: <node> <content> [<extra field label>:<extra field value>]... [--id]")
 		}
 	case "move":
-		if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+		if len(cmd.Args) < 2 || len(cmd.Args) > 4 {
 			sm.logger.Error(ctx, "Invalid number of arguments for node move command", log.Fields{"argCount": len(cmd.Args)})
-			return errors.New("node move command requires 2 or 3 arguments: <source> <target> [--id]")
+			return errors.New("node move command requires 2 to 4 arguments: <source> <target> [--id] [--preview]")
 		}
-	case "delete":
+	case "copy":
+		if len(cmd.Args) < 2 || len(cmd.Args) > 4 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node copy command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node copy command requires 2 to 4 arguments: <source> <target> [--id] [--force]")
+		}
+	case "indent", "outdent":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node command", log.Fields{"operation": cmd.Operation, "argCount": len(cmd.Args)})
+			return fmt.Errorf("node %s command requires 1 or 2 arguments: <node> [--id]", cmd.Operation)
+		}
+	case "wikilink":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 5 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node wikilink command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node wikilink command requires 1 to 5 arguments: <node> [--create] [--inbox <node>] [--id]")
+		}
+	case "exists":
 		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node exists command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node exists command requires 1 or 2 arguments: <node> [--id]")
+		}
+	case "count":
+		if len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node count command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node count command requires 0 to 2 arguments: [node] [--id]")
+		}
+	case "backlinks":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node backlinks command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node backlinks command requires 1 or 2 arguments: <node> [--id]")
+		}
+	case "private":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 3 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node private command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node private command requires 1 to 3 arguments: <node> [on|off] [--id]")
+		}
+	case "remind":
+		if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node remind command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node remind command requires 2 or 3 arguments: <node> <time>|clear [--id]")
+		}
+	case "field":
+		if len(cmd.Args) < 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node field command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node field command requires at least 2 arguments: rename <old> <new> | delete <key>")
+		}
+	case "swap":
+		if len(cmd.Args) < 2 || len(cmd.Args) > 3 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node swap command", log.Fields{"argCount": len(cmd.Args)})
+			return fmt.Errorf("node swap command requires 2 or 3 arguments: <node> <node> [--id]")
+		}
+	case "rotate":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 3 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node rotate command", log.Fields{"argCount": len(cmd.Args)})
+			return fmt.Errorf("node rotate command requires 1 to 3 arguments: <parent> [n] [--id]")
+		}
+	case "delete":
+		if len(cmd.Args) < 1 {
 			sm.logger.Error(ctx, "Invalid number of arguments for node delete command", log.Fields{"argCount": len(cmd.Args)})
-			return errors.New("node delete command requires 1 or 2 arguments: <node> [--id]")
+			return errors.New("node delete command requires at least 1 argument: <node>... [--id] [--force]")
 		}
 	case "find":
-		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		if len(cmd.Args) < 1 {
 			sm.logger.Error(ctx, "Invalid number of arguments for node find command", log.Fields{"argCount": len(cmd.Args)})
-			return errors.New("node find command requires 1 or 2 arguments: <query> [--id]")
+			return errors.New("node find command requires a query or a tag: [<query>...] [--tag <tag>]... [--id] [--limit <n>] [--offset <n>]")
+		}
+	case "link":
+		if len(cmd.Args) < 2 || len(cmd.Args) > 6 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node link command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node link command requires 2 to 6 arguments: <source> <target> [--mindmap <mindmap>] [--remove] [--id]")
+		}
+	case "attach":
+		if len(cmd.Args) < 1 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node attach command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node attach command requires at least 1 argument: <node> [<file-or-url>...] [--remove <name>] [--save <name> [<file>] [--force]] [--id]")
+		}
+	case "tag":
+		if len(cmd.Args) < 1 {
+			sm.logger.Error(ctx, "Invalid number of arguments for node tag command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("node tag command requires: add <node> <tag>... | remove <node> <tag>... | list [node], [--id]")
 		}
 	case "sort":
-		if len(cmd.Args) > 4 {
+		if len(cmd.Args) > 12 {
 			sm.logger.Error(ctx, "Invalid number of arguments for node sort command", log.Fields{"argCount": len(cmd.Args)})
-			return errors.New("node sort command accepts at most 4 arguments: [identifier] [field] [--reverse] [--id]")
+			return errors.New("node sort command accepts at most 12 arguments: [identifier] [field | --sort created|modified] [--reverse] [--natural] [--nocase] [--locale <tag>] [--id] [--force] [--preview]")
 		}
 	default:
 		sm.logger.Error(ctx, "Invalid node operation", log.Fields{"operation": cmd.Operation})
@@ -521,7 +922,7 @@ func (sm *SessionManager) validateSystemCommand(cmd model.Command) error {
 	sm.logger.Debug(ctx, "Validating system command", log.Fields{"operation": cmd.Operation})
 
 	switch cmd.Operation {
-	case "exit", "quit":
+	case "exit", "quit", "ping", "jobs", "version":
 		if len(cmd.Args) != 0 {
 			sm.logger.Error(ctx, "Invalid number of arguments for system command", log.Fields{"operation": cmd.Operation, "argCount": len(cmd.Args)})
 			return fmt.Errorf("system %s command does not accept any arguments", cmd.Operation)
@@ -531,9 +932,93 @@ func (sm *SessionManager) validateSystemCommand(cmd model.Command) error {
 			sm.logger.Error(ctx, "Invalid number of arguments for system command", log.Fields{"operation": cmd.Operation, "argCount": len(cmd.Args)})
 			return fmt.Errorf("system %s command does not accept any arguments", cmd.Operation)
 		}
+	case "db":
+		if len(cmd.Args) != 1 || (cmd.Args[0] != "check" && cmd.Args[0] != "vacuum") {
+			sm.logger.Error(ctx, "Invalid arguments for system db command", log.Fields{"args": cmd.Args})
+			return errors.New("system db command requires 1 argument: check|vacuum")
+		}
+	case "gc":
+		if len(cmd.Args) > 1 || len(cmd.Args) == 1 && cmd.Args[0] != "--force" {
+			sm.logger.Error(ctx, "Invalid arguments for system gc command", log.Fields{"args": cmd.Args})
+			return errors.New("system gc command accepts at most 1 argument: [--force]")
+		}
+	case "backup":
+		if len(cmd.Args) > 1 || len(cmd.Args) == 1 && cmd.Args[0] != "now" && cmd.Args[0] != "list" {
+			sm.logger.Error(ctx, "Invalid arguments for system backup command", log.Fields{"args": cmd.Args})
+			return errors.New("system backup command requires 0 or 1 argument: [now|list]")
+		}
+	case "replay":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for system replay command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("system replay command requires 1 or 2 arguments: <file> [range]")
+		}
+	case "transcript":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 2 || cmd.Args[0] != "start" && cmd.Args[0] != "stop" || cmd.Args[0] == "stop" && len(cmd.Args) != 1 {
+			sm.logger.Error(ctx, "Invalid arguments for system transcript command", log.Fields{"args": cmd.Args})
+			return errors.New("system transcript command requires 1 or 2 arguments: start [file] | stop")
+		}
 	default:
 		sm.logger.Error(ctx, "Invalid system operation", log.Fields{"operation": cmd.Operation})
 		return fmt.Errorf("invalid system operation: %s", cmd.Operation)
 	}
 	return nil
 }
+
+func (sm *SessionManager) validateJournalCommand(cmd model.Command) error {
+	ctx := context.Background()
+	sm.logger.Debug(ctx, "Validating journal command", log.Fields{"operation": cmd.Operation})
+
+	switch cmd.Operation {
+	case "today":
+		if len(cmd.Args) != 0 {
+			sm.logger.Error(ctx, "Invalid number of arguments for journal today command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("journal today command does not accept any arguments")
+		}
+	default:
+		sm.logger.Error(ctx, "Invalid journal operation", log.Fields{"operation": cmd.Operation})
+		return fmt.Errorf("invalid journal operation: %s", cmd.Operation)
+	}
+	return nil
+}
+
+func (sm *SessionManager) validateAddCommand(cmd model.Command) error {
+	ctx := context.Background()
+	sm.logger.Debug(ctx, "Validating add command", log.Fields{"argCount": len(cmd.Args)})
+
+	if cmd.Operation != "" {
+		sm.logger.Error(ctx, "Invalid add operation", log.Fields{"operation": cmd.Operation})
+		return fmt.Errorf("add command takes no operation: %s", cmd.Operation)
+	}
+	if len(cmd.Args) == 0 {
+		sm.logger.Error(ctx, "Insufficient arguments for add command", nil)
+		return errors.New("add command requires the text to add: add [--mindmap <mindmap>] [--parent <node>] <text>")
+	}
+	return nil
+}
+
+func (sm *SessionManager) validateAdminCommand(cmd model.Command) error {
+	ctx := context.Background()
+	sm.logger.Debug(ctx, "Validating admin command", log.Fields{"operation": cmd.Operation})
+
+	switch cmd.Operation {
+	case "audit":
+		if len(cmd.Args) < 1 || len(cmd.Args) > 3 {
+			sm.logger.Error(ctx, "Invalid number of arguments for admin audit command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("admin audit command requires 1 to 3 arguments: tail [count] | search <query> [count]")
+		}
+	case "events":
+		if len(cmd.Args) != 0 {
+			sm.logger.Error(ctx, "Invalid number of arguments for admin events command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("admin events command does not accept any arguments")
+		}
+	case "telemetry":
+		if len(cmd.Args) > 2 {
+			sm.logger.Error(ctx, "Invalid number of arguments for admin telemetry command", log.Fields{"argCount": len(cmd.Args)})
+			return errors.New("admin telemetry command requires 0 to 2 arguments: [show] | export [file] | reset")
+		}
+	default:
+		sm.logger.Error(ctx, "Invalid admin operation", log.Fields{"operation": cmd.Operation})
+		return fmt.Errorf("invalid admin operation: %s", cmd.Operation)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package session
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/data"
+	"mindnoscape/local-app/src/pkg/event"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// TestSessionsConcurrent adds, gets, deletes and expires sessions from many goroutines while they run commands, as
+// the adapters and the cleanup routine do. Run with -race to check the access to the sessions.
+func TestSessionsConcurrent(t *testing.T) {
+	// Read-only, so that the commands need neither storage nor the audit log, with a rate limit to count commands
+	cfg := &model.Config{ReadOnly: true, CommandRateLimit: 1 << 20}
+	logger := newTestSessionManager(t, cfg).logger
+	eventManager := event.NewEventManager(logger)
+	t.Cleanup(eventManager.Close)
+	sm := NewSessionManager(&data.DataManager{Config: cfg, EventManager: eventManager}, logger)
+	t.Cleanup(sm.StopCleanupRoutine)
+
+	// Adapters look up the expired sessions, as the web adapter does
+	sm.Subscribe(event.SessionExpired, func(e event.Event) {
+		if _, exists := sm.SessionGet(e.Data.(string)); exists {
+			t.Errorf("expired session %s still exists", e.Data)
+		}
+	})
+
+	const workers, rounds = 8, 50
+	ping := model.Command{Scope: "s", Operation: "ping", Args: []string{}}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				session, err := sm.SessionAdd()
+				if err != nil {
+					t.Errorf("failed to add session: %v", err)
+					return
+				}
+				// The session may be expired by the cleanup meanwhile, which the commands report
+				result, err := sm.SessionRun(session.ID, ping)
+				if err == nil && result != "pong" {
+					t.Errorf("system ping returned %v", result)
+				}
+				sm.SessionGet(session.ID)
+				if i%2 == 0 {
+					sm.SessionDelete(session.ID)
+				}
+			}
+		}()
+	}
+
+	// The cleanup expires all sessions, on the executor as the cleanup routine does
+	stop := make(chan struct{})
+	cleaned := make(chan struct{})
+	go func() {
+		defer close(cleaned)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			sm.runTask(func() (interface{}, error) {
+				sm.sessionMutex.RLock()
+				for _, session := range sm.sessions {
+					session.LastActivity = time.Time{}
+				}
+				sm.sessionMutex.RUnlock()
+				sm.cleanupInactiveSessions()
+				return nil, nil
+			})
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	<-cleaned
+
+	// Sessions added once the cleanup stopped keep running commands
+	session, err := sm.SessionAdd()
+	if err != nil {
+		t.Fatalf("failed to add session: %v", err)
+	}
+	if result, err := sm.SessionRun(session.ID, ping); err != nil || result != "pong" {
+		t.Errorf("system ping returned %v, %v", result, err)
+	}
+}
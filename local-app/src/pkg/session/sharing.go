@@ -0,0 +1,143 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"mindnoscape/local-app/src/pkg/data"
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// maxChangeAttempts is how many times a node change is tried on a mindmap other sessions keep changing meanwhile
+const maxChangeAttempts = 3
+
+// refreshMiddleware reloads the mindmap of a session before a command if another session has changed it since it
+// was loaded, so that the command sees and changes the mindmap as stored. A mindmap deleted or made private by its
+// owner meanwhile is deselected. The commands of the system, user and admin scopes don't use the mindmap.
+func (sm *SessionManager) refreshMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		switch {
+		case session.Mindmap == nil || session.User == nil:
+		case cmd.Scope == "system", cmd.Scope == "user", cmd.Scope == "admin":
+		default:
+			err := sm.mindmapRefresh(session)
+			switch {
+			case errors.Is(err, data.ErrMindmapUnavailable):
+				sm.logger.Warn(context.Background(), "Deselecting unavailable mindmap", log.Fields{"mindmapID": session.Mindmap.ID})
+				session.Mindmap, session.Node = nil, nil
+				// Mindmap commands such as select don't need the mindmap
+				if cmd.Scope != "mindmap" {
+					return nil, err
+				}
+			case err != nil:
+				return nil, err
+			}
+		}
+		return next(sm, session, cmd)
+	}
+}
+
+// changeMiddleware runs the node changes of a session on its mindmap as changes of the stored mindmap, see
+// data.DataManager.MindmapChange. A change is tried again on the reloaded mindmap if another session changed the
+// mindmap while it ran, and a failed change leaves the mindmap as stored.
+func (sm *SessionManager) changeMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		if session.Mindmap == nil || !isMutatingCommand(cmd) {
+			return next(sm, session, cmd)
+		}
+		ctx := context.Background()
+
+		for attempt := 1; ; attempt++ {
+			var result interface{}
+			err := sm.dataManager.MindmapChange(session.Mindmap, func() error {
+				var err error
+				result, err = next(sm, session, cmd)
+				return err
+			})
+			if err == nil {
+				return result, nil
+			}
+
+			// The rolled back changes are discarded from the loaded mindmap
+			if refreshErr := sm.mindmapRefresh(session); refreshErr != nil {
+				sm.logger.Error(ctx, "Failed to reload mindmap after a failed change", log.Fields{"error": refreshErr, "mindmapID": session.Mindmap.ID})
+				return nil, fmt.Errorf("%w (reloading the mindmap failed: %v)", err, refreshErr)
+			}
+			if !errors.Is(err, storage.ErrMindmapChanged) {
+				return result, err
+			}
+			if attempt == maxChangeAttempts {
+				sm.logger.Warn(ctx, "Node change given up on a mindmap changing meanwhile", log.Fields{"operation": cmd.Operation, "mindmapID": session.Mindmap.ID, "attempts": attempt})
+				return nil, fmt.Errorf("%w, try again", err)
+			}
+			sm.logger.Info(ctx, "Trying node change again on the reloaded mindmap", log.Fields{"operation": cmd.Operation, "mindmapID": session.Mindmap.ID, "attempt": attempt})
+		}
+	}
+}
+
+// mindmapRefresh reloads the mindmap of a session if another session has changed it, keeping the current node
+func (sm *SessionManager) mindmapRefresh(session *model.Session) error {
+	reloaded, err := sm.dataManager.MindmapRefresh(session.User, session.Mindmap)
+	if err != nil {
+		return err
+	}
+	if reloaded && session.Node != nil {
+		session.Node = session.Mindmap.Nodes[session.Node.ID]
+	}
+	return nil
+}
+
+// MindmapChangeSessions returns the sessions, of sessionIDs, to push a change to a mindmap to, and a copy of node,
+// the node changed if any, to push. They are the sessions with the mindmap selected whose user may see the node, a
+// node in a private subtree only going to the owner of the mindmap. The sessions and the loaded mindmaps belong to
+// the command executor, so this runs on it.
+func (sm *SessionManager) MindmapChangeSessions(mindmap *model.Mindmap, node *model.Node, sessionIDs []string) ([]string, *model.Node, error) {
+	var recipients []string
+	var changed *model.Node
+	_, err := sm.runTask(func() (interface{}, error) {
+		sm.sessionMutex.RLock()
+		defer sm.sessionMutex.RUnlock()
+		for _, sessionID := range sessionIDs {
+			session, exists := sm.sessions[sessionID]
+			if !exists || session.User == nil || session.Mindmap == nil || session.Mindmap.ID != mindmap.ID {
+				continue
+			}
+			if node != nil && !nodeVisible(session.User, mindmap, session.Mindmap, node) {
+				continue
+			}
+			recipients = append(recipients, sessionID)
+		}
+		if node != nil && len(recipients) > 0 {
+			clone := *node
+			clone.Content = maps.Clone(node.Content)
+			changed = &clone
+		}
+		return nil, nil
+	})
+	return recipients, changed, err
+}
+
+// nodeVisible reports whether user may see a node changed in mindmap, which the user has loaded as seen: the owner
+// sees all nodes, the other users the nodes outside the private subtrees. A node whose ancestors are no longer in
+// mindmap, such as one of a deleted subtree, is seen if it was loaded in seen.
+func nodeVisible(user *model.User, mindmap, seen *model.Mindmap, node *model.Node) bool {
+	if user.Username == mindmap.Owner {
+		return true
+	}
+	for n := node; n.ParentID >= 0; {
+		if n.IsPrivate() {
+			return false
+		}
+		parent, ok := mindmap.Nodes[n.ParentID]
+		if !ok {
+			_, loaded := seen.Nodes[node.ID]
+			return loaded
+		}
+		n = parent
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+const (
+	defaultSummaryDepth = 2  // Levels of branches a summary shows below the summarized node
+	maxSummaryDated     = 10 // Dated nodes a summary lists, the soonest
+)
+
+// handleMindmapSummary handles the mindmap summary command, which shows a one-page summary of the current mindmap
+// or of a branch of it, or writes it to a text or PDF file for printing
+func handleMindmapSummary(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap summary command", log.Fields{"args": cmd.Args})
+
+	identifier, filename := "", ""
+	depth := defaultSummaryDepth
+	useID, force := false, false
+	for i := 0; i < len(cmd.Args); i++ {
+		switch arg := cmd.Args[i]; {
+		case arg == "--id":
+			useID = true
+		case arg == "--force":
+			force = true
+		case arg == "--depth" || arg == "--output":
+			i++
+			if i == len(cmd.Args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			if arg == "--output" {
+				filename = cmd.Args[i]
+				break
+			}
+			n, err := strconv.Atoi(cmd.Args[i])
+			if err != nil || n < 1 {
+				sm.logger.Error(ctx, "Invalid summary depth", log.Fields{"depth": cmd.Args[i]})
+				return nil, fmt.Errorf("invalid depth '%s': must be a positive number", cmd.Args[i])
+			}
+			depth = n
+		case strings.HasPrefix(arg, "--"):
+			sm.logger.Error(ctx, "Invalid option for mindmap summary", log.Fields{"option": arg})
+			return nil, fmt.Errorf("unknown option: %s", arg)
+		case identifier == "":
+			identifier = arg
+		default:
+			return nil, errors.New("mindmap summary command accepts at most 1 node: [node] [--depth <levels>] [--output <filename>] [--force] [--id]")
+		}
+	}
+
+	var node *model.Node
+	if identifier != "" {
+		var err error
+		if node, err = getNode(sm, session.Mindmap, identifier, useID); err != nil {
+			sm.logger.Error(ctx, "Failed to get node", log.Fields{"error": err, "nodeIdentifier": identifier})
+			return nil, fmt.Errorf("failed to get node: %w", err)
+		}
+	}
+
+	summary := sm.dataManager.MindmapSummary(session.Mindmap, node, depth, session.Mindmap.Sort)
+	title, lines := sm.formatSummary(summary)
+	if filename == "" {
+		return title + "\n\n" + strings.Join(lines, "\n"), nil
+	}
+
+	format := "txt"
+	if strings.EqualFold(filepath.Ext(filename), ".pdf") {
+		format = "pdf"
+	}
+	path, err := sm.dataManager.ExportPath(session.Mindmap, filename, format)
+	if err != nil {
+		return nil, err
+	}
+	encoded := []byte(title + "\n\n" + strings.Join(lines, "\n") + "\n")
+	if format == "pdf" {
+		encoded = storage.EncodeTextPDF(title, lines)
+	}
+	if err := sm.dataManager.ExportWrite(path, encoded, force); err != nil {
+		return nil, err
+	}
+	sm.logger.Info(ctx, "Mindmap summary written", log.Fields{"mindmapID": session.Mindmap.ID, "path": path})
+	result := fmt.Sprintf("Summary written to %s", path)
+	if format == "pdf" && len(lines) > storage.PDFPageLines {
+		result += fmt.Sprintf("\nThe page holds %d of its %d lines, use a lower --depth to fit it", storage.PDFPageLines-1, len(lines))
+	}
+	return result, nil
+}
+
+// formatSummary renders a summary as a title and its lines: the size of the summarized subtree, its branches
+// as an outline with the size of each and its soonest dated nodes
+func (sm *SessionManager) formatSummary(summary model.MindmapSummary) (string, []string) {
+	style := sm.DisplayStyle()
+	title := "Summary of " + summary.Mindmap.Name
+	if summary.Node.ID != 0 {
+		title += fmt.Sprintf(": %s %s", style.DisplayIndex(summary.Node.Index), summary.Node.Name)
+	}
+
+	lines := []string{
+		"Generated " + summary.Created.Format("2006-01-02 15:04"),
+		fmt.Sprintf("%d nodes, depth %d, %d with fields, %d dated, %d overdue", summary.Stats.NodeCount, summary.Stats.Depth, summary.Fields, len(summary.Dated), summary.Overdue),
+	}
+
+	if len(summary.Branches) > 0 {
+		lines = append(lines, "", "Branches")
+		for _, branch := range summary.Branches {
+			line := fmt.Sprintf("%s%s %s", strings.Repeat(treeIndent, branch.Depth), style.DisplayIndex(branch.Node.Index), branch.Node.Name)
+			if branch.Count > 1 {
+				line += fmt.Sprintf(" (%d nodes)", branch.Count)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	if len(summary.Dated) > 0 {
+		lines = append(lines, "", "Dated")
+		for i, item := range summary.Dated {
+			if i == maxSummaryDated {
+				lines = append(lines, fmt.Sprintf("%sand %d more", treeIndent, len(summary.Dated)-i))
+				break
+			}
+			at := item.At.Format("2006-01-02 15:04")
+			if item.At.Hour() == 0 && item.At.Minute() == 0 {
+				at = item.At.Format("2006-01-02")
+			}
+			line := fmt.Sprintf("%s%-16s %-6s %s %s", treeIndent, at, item.Field, style.DisplayIndex(item.Node.Index), item.Node.Name)
+			if item.At.Before(summary.Created) {
+				line += " (overdue)"
+			}
+			lines = append(lines, line)
+		}
+	}
+	return title, lines
+}
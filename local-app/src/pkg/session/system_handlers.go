@@ -1,10 +1,16 @@
 package session
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	"mindnoscape/local-app/src/pkg/buildinfo"
+	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
 )
 
 func handleSystemExit(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
@@ -12,6 +18,150 @@ func handleSystemExit(sm *SessionManager, session *model.Session, cmd model.Comm
 	return nil, nil
 }
 
+// handleSystemPing handles the system ping command, which only keeps an idle session alive like any command does
+func handleSystemPing(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	return "pong", nil
+}
+
+// handleSystemJobs handles the system jobs command
+func handleSystemJobs(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling system jobs command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) != 0 {
+		sm.logger.Error(ctx, "Invalid number of arguments for system jobs", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("system jobs command takes no arguments")
+	}
+
+	jobs := sm.Jobs(session.ID)
+	if len(jobs) == 0 {
+		return "No background jobs", nil
+	}
+	lines := make([]string, len(jobs))
+	for i, j := range jobs {
+		lines[i] = FormatJob(j)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// handleSystemDB handles the system db command, which checks the integrity of the database or compacts it
+func handleSystemDB(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling system db command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) != 1 {
+		sm.logger.Error(ctx, "Invalid number of arguments for system db", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("system db command requires 1 argument: check|vacuum")
+	}
+
+	action := cmd.Args[0]
+	if action != "check" && action != "vacuum" {
+		sm.logger.Error(ctx, "Invalid system db action", log.Fields{"action": action})
+		return nil, fmt.Errorf("invalid system db action: %s. Must be 'check' or 'vacuum'", action)
+	}
+
+	// The database is safe to use alongside the executor, the work runs as a whole in the background
+	return sm.jobRun(session, cmd, func(run jobRunner) (interface{}, error) {
+		if action == "vacuum" {
+			reclaimed, err := sm.dataManager.DatabaseVacuum()
+			if err != nil {
+				sm.logger.Error(ctx, "Failed to vacuum database", log.Fields{"error": err})
+				return nil, fmt.Errorf("failed to vacuum database: %w", err)
+			}
+			return fmt.Sprintf("Database compacted, %d KiB reclaimed", reclaimed/1024), nil
+		}
+
+		problems, err := sm.dataManager.DatabaseCheck()
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to check database", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to check database: %w", err)
+		}
+		if len(problems) == 0 {
+			return "Database integrity check passed", nil
+		}
+		lines := []string{fmt.Sprintf("Database integrity check found %d problems:", len(problems))}
+		for _, problem := range problems {
+			lines = append(lines, "  "+problem)
+		}
+		return strings.Join(lines, "\n"), nil
+	})
+}
+
+// handleSystemGC handles the system gc command, which reports the links and attachments left without their node or
+// mindmap, and removes them with --force
+func handleSystemGC(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling system gc command", log.Fields{"args": cmd.Args})
+
+	remove := len(cmd.Args) == 1 && cmd.Args[0] == "--force"
+	orphans, err := sm.dataManager.DatabaseOrphans(remove)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to collect orphaned rows", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to collect orphaned rows: %w", err)
+	}
+	if orphans == (model.Orphans{}) {
+		return "No orphaned links or attachments", nil
+	}
+
+	found := fmt.Sprintf("%d links and %d attachments of %d KiB without their node or mindmap", orphans.Links, orphans.Attachments, orphans.AttachmentBytes/1024)
+	if !remove {
+		return "Found " + found + ", remove them with system gc --force", nil
+	}
+	return "Removed " + found + ", run system db vacuum to reclaim their space", nil
+}
+
+// handleSystemVersion handles the system version command, reporting the versions of the binary and the database
+func handleSystemVersion(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling system version command", log.Fields{"args": cmd.Args})
+
+	info := buildinfo.Get()
+	config := sm.dataManager.Config
+	build := []string{"Built with " + info.GoVersion + " for " + info.Platform}
+	if info.CGO {
+		build = append(build, "cgo")
+	}
+	if info.Tags != "" {
+		build = append(build, "tags "+info.Tags)
+	}
+	lines := []string{"Mindnoscape " + info.Version, strings.Join(build, ", ")}
+	if info.Revision != "" {
+		revision := "Revision " + info.Revision
+		if info.Time != "" {
+			revision += " of " + info.Time
+		}
+		if info.Modified {
+			revision += ", with uncommitted changes"
+		}
+		lines = append(lines, revision)
+	}
+
+	schema := sm.dataManager.DatabaseSchemaVersion()
+	lines = append(lines, fmt.Sprintf("Database schema version %d, this version supports %d", schema, storage.SchemaVersion))
+	switch {
+	case schema > storage.SchemaVersion:
+		lines = append(lines, "  The database was written by a newer version of Mindnoscape and is opened read-only, upgrade Mindnoscape to change its data")
+	case schema < storage.SchemaVersion:
+		lines = append(lines, "  The database is migrated when next opened writable")
+	}
+	lines = append(lines, fmt.Sprintf("Storage %s, %s", config.DatabaseType, filepath.Join(config.DatabaseDir, config.DatabaseFile)))
+	if config.ConfigFile != "" {
+		lines = append(lines, "Config "+config.ConfigFile)
+	}
+
+	if len(info.Modules) > 0 {
+		lines = append(lines, "Modules:")
+		for _, module := range info.Modules {
+			line := "  " + module.Path + " " + module.Version
+			if module.Replace != "" {
+				line += " => " + module.Replace
+			}
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func handleSystemHelp(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
 	return getHelp(cmd.Args), nil
 }
@@ -50,6 +200,10 @@ func getScopeHelp(scope string) string {
 	var validScope = false
 	help.WriteString(fmt.Sprintf("Commands for %s:\n\n", scope))
 	for _, cmd := range commandHelps {
+		if cmd.Scope == scope && cmd.Operation == "" {
+			// A command without operations, such as add
+			return getOperationHelp(scope, "")
+		}
 		if cmd.Scope == scope {
 			validScope = true
 			help.WriteString(fmt.Sprintf("%-15s %s\n", cmd.Operation, cmd.ShortDesc))
@@ -64,8 +218,9 @@ func getScopeHelp(scope string) string {
 func getOperationHelp(scope, operation string) string {
 	for _, cmd := range commandHelps {
 		if cmd.Scope == scope && cmd.Operation == operation {
+			cmd = helpWithFormats(cmd)
 			var help strings.Builder
-			help.WriteString(fmt.Sprintf("Command: %s %s\n", scope, operation))
+			help.WriteString(fmt.Sprintf("Command: %s\n", strings.TrimSpace(scope+" "+operation)))
 			help.WriteString(fmt.Sprintf("Description: %s\n", cmd.LongDesc))
 			help.WriteString(fmt.Sprintf("Syntax: %s\n", cmd.Syntax))
 			if len(cmd.Arguments) > 0 {
@@ -137,10 +292,46 @@ var commandHelps = []CommandHelp{
 		Scope:     "user",
 		Operation: "select",
 		ShortDesc: "Select a user",
-		LongDesc:  "Selects the specified user account. If no username is provided, deselects the current user.",
-		Syntax:    "user select [username]",
-		Arguments: []string{"username: The name of the user to select"},
-		Examples:  []string{"user select john"},
+		LongDesc:  "Selects the specified user account, and the default mindmap of the user if one is set with user default. If no username is provided, deselects the current user. The web and gRPC clients need the password of the user, so users without one can only be selected on the CLI; a password given on the CLI is checked too.",
+		Syntax:    "user select [username] [password]",
+		Arguments: []string{"username: The name of the user to select", "password: The password of the user, needed by web and gRPC clients"},
+		Examples:  []string{"user select john", "user select john secret"},
+	},
+	{
+		Scope:     "user",
+		Operation: "default",
+		ShortDesc: "Set the default mindmap",
+		LongDesc:  "Sets the mindmap selected along with the current user on user select, including the selection of the default user on start when default_user_select is set in the configuration. Without a mindmap, the default mindmap is cleared.",
+		Syntax:    "user default [mindmap]",
+		Arguments: []string{"mindmap: (Optional) The name of a mindmap accessible to the current user"},
+		Examples:  []string{"user default ideas", "user default"},
+	},
+	{
+		Scope:     "user",
+		Operation: "inbox",
+		ShortDesc: "Set the inbox node of add",
+		LongDesc:  "Sets the node the add command adds under to a node of the selected mindmap, which becomes the default mindmap. Without a node, add goes back to the top-level Inbox node of the default mindmap, added by the first add if missing. Setting another default mindmap also resets the inbox.",
+		Syntax:    "user inbox [node] [--id]",
+		Arguments: []string{"node: (Optional) The identifier of a node of an own selected mindmap, other than the root", "--id: (Optional) Use an id instead of an index"},
+		Examples:  []string{"user inbox 3", "user inbox 2.1", "user inbox"},
+	},
+	{
+		Scope:     "user",
+		Operation: "capture",
+		ShortDesc: "Capture emails as nodes",
+		LongDesc:  "Sets, shows or clears the email capture account of the current user. The capture service polls the IMAP mailbox of the account over TLS at the configured capture interval and adds each new unseen email as a node under the top-level Inbox node of the target mindmap, with the subject as name, the body as 'note' and the sender and date as 'from' and 'date' fields. Captured emails are flagged as seen. The password is stored in the database as given, as the IMAP login needs it.",
+		Syntax:    "user capture set <server> <login> <password> <mindmap> [mailbox] | show | clear",
+		Arguments: []string{"server: The IMAP server as host[:port], port 993 by default", "login: The login name of the mailbox", "password: The password of the mailbox, an app password where the provider supports them", "mindmap: The name of an own mindmap to capture into", "mailbox: (Optional) The mailbox to poll. Defaults to INBOX"},
+		Examples:  []string{"user capture set imap.example.com me@example.com app_password ideas", "user capture set mail.example.org:1993 me secret notes Capture", "user capture show", "user capture clear"},
+	},
+	{
+		Scope:     "user",
+		Operation: "calendar",
+		ShortDesc: "Subscribe calendars to dated nodes",
+		LongDesc:  "Sets, shows or clears the calendar feeds of the current user. A feed is an iCalendar of the nodes with due or remind fields of an own mindmap, or of all own mindmaps without a mindmap name, as the ics export format renders them. The web adapter serves it at a URL with an unguessable token, which calendars subscribe to and poll to stay in sync with the nodes. Anyone with the URL can read the feed, so setting a feed again gives it a new URL and the old one stops working.",
+		Syntax:    "user calendar set [mindmap] | show | clear [mindmap]",
+		Arguments: []string{"mindmap: (Optional) The name of an own mindmap the feed covers. Defaults to all own mindmaps"},
+		Examples:  []string{"user calendar set", "user calendar set projects", "user calendar show", "user calendar clear projects"},
 	},
 	{
 		Scope:     "mindmap",
@@ -173,25 +364,27 @@ var commandHelps = []CommandHelp{
 		Scope:     "mindmap",
 		Operation: "import",
 		ShortDesc: "Import a mindmap from a file",
-		LongDesc:  "Imports a mindmap from a file in JSON or XML format.",
-		Syntax:    "mindmap import <filename> [json|xml]",
-		Arguments: []string{"filename: The name of the file to import from", "format: (Optional) The file format, either 'json' or 'xml'. Defaults to 'json'"},
-		Examples:  []string{"mindmap import my_ideas.json", "mindmap import project_x.xml xml"},
+		LongDesc:  "Imports a mindmap from a file in one of the import formats, such as a JSON or XML export, an XMind (.xmind) or MindManager (.mmap) file or browser bookmarks exported as HTML. The filename is relative to the configured export directory. The embedded checksum and a detached signature (<filename>.sig) of JSON and XML files, if present, are verified before anything is imported. XMind and MindManager files are imported into a mindmap named after the file, with the central topic as the top-level node, topic notes, markers and icons as the 'notes' and 'markers' fields, web links and XMind labels as the 'url' and 'labels' fields and topic colors, fonts and shapes as the style fields. Features without a counterpart in a mindmap, such as relationships and images, are listed in a warning. Bookmark folders are imported as nodes and bookmarks as leaves with their address in the 'url' field. Markdown notes, such as of Obsidian, are imported with headings nested by level and bullet list items by indentation, inline [label:: value] annotations and label:: value lines as fields and other text as the 'notes' field; a single level 1 heading starting the file is the root and names the mindmap. FreeMind and Freeplane (.mm) files are imported with the root node naming the mindmap, node attributes as fields and notes, web links, icons, colors and fonts as the 'notes', 'url', 'markers' and style fields. An existing mindmap of the same name is replaced, unless a JSON or XML file is merged into it with one of the policies, which match the imported nodes to the existing ones by their node ID; nodes not in the mindmap are added under their parents.",
+		Syntax:    "mindmap import <filename> [{import_formats}] [--force] [--skip-existing|--update-existing|--duplicate|--sync] [--on-conflict mine|theirs|both]",
+		Arguments: []string{"filename: The name of the file to import from, relative to the export directory. Files ending in .gz or .zst are decompressed, files ending in .mnx are decrypted with the passphrase prompted for", "format: (Optional) The file format, one of the following. Defaults to the format of the file extension and 'json' otherwise", helpImportFormatList},
+		Options:   []string{"--force: Import even if the checksum or signature verification fails", "--skip-existing: Merge into the existing mindmap, keeping the nodes it already has", "--update-existing: Merge into the existing mindmap, updating the names of the nodes it already has and merging their fields", "--duplicate: Merge into the existing mindmap, adding copies of the nodes it already has", "--sync: Apply a JSON or XML export edited elsewhere to the existing mindmap, following its nested tree: nodes are matched by ID, renamed, given the fields of the file and moved to their new parents, nodes without an ID are added and nodes missing from the file are deleted. Use --force if the edits broke the checksum", "--on-conflict: How --update-existing and --sync resolve nodes changed in the mindmap since the file's copy of them: 'mine' keeps the node, 'theirs' takes the file's and 'both' keeps the node and adds the file's next to it. Without it a terminal asks for each conflict, also offering to edit the node, and otherwise the import is refused, listing the conflicts"},
+		Examples:  []string{"mindmap import my_ideas.json", "mindmap import project_x.xml xml", "mindmap import damaged.json --force", "mindmap import roadmap.xmind", "mindmap import bookmarks.html", "mindmap import my_ideas.json --update-existing", "mindmap import my_ideas.json --sync", "mindmap import my_ideas.json --sync --on-conflict both", "mindmap import out.mnx"},
 	},
 	{
 		Scope:     "mindmap",
 		Operation: "export",
 		ShortDesc: "Export a mindmap to a file",
-		LongDesc:  "Exports the current mindmap to a file in JSON or XML format.",
-		Syntax:    "mindmap export <filename> [json|xml]",
-		Arguments: []string{"filename: The name of the file to save to", "format: (Optional) The file format, either 'json' or 'xml'. Defaults to 'json'"},
-		Examples:  []string{"mindmap export my_ideas.json", "mindmap export project_x.xml xml"},
+		LongDesc:  "Exports the current mindmap to a file in one of the export formats, within the configured export directory. Only JSON, XML, Markdown and FreeMind files can be imported again, most other formats can also export the subtree of a single node. Existing files are not overwritten and mindmaps larger than the configured threshold are not exported unless forced. With --encrypt, the file is encrypted with AES-GCM by a passphrase prompted for, to share sensitive mindmaps over untrusted channels. With --clipboard, the text formats are copied to the system clipboard instead of a file.",
+		Syntax:    "mindmap export [filename] [{export_formats}] [--node <node>] [--id] [--force] [--compress[=gz|zst]] [--sign] [--include-computed] [--redact <profile>] [--encrypt] [--clipboard]",
+		Arguments: []string{"filename: (Optional) The name or template of the file to save to, relative to the export directory. Defaults to the configured export template. Templates may use {mindmap}, {owner}, {id}, {date}, {time} and {format}", "format: (Optional) The file format, one of the following. Defaults to 'json'", helpExportFormatList},
+		Options:   []string{"--node <node>: Export only the subtree of the node, in the formats exporting subtrees", "--id: Identify the node by ID instead of index", "--force: Overwrite the file if it already exists and export mindmaps larger than the configured threshold", "--compress[=gz|zst]: Compress the file with gzip (default) or zstd, adding the suffix to the filename. Filenames ending in .gz or .zst are always compressed", "--sign: Write a detached signature of the content checksum to <filename>.sig, using the current user's key", "--include-computed: Add the displayed index, depth, path and the numbers of children and descendants of each node as _index, _depth, _path, _children and _descendants fields, which are dropped again on import", "--redact <profile>: Remove or hash the content fields named by a redaction profile configured in redaction_profiles, such as 'personal'", "--encrypt: Encrypt the file with a passphrase prompted for twice, adding the .mnx suffix to the filename. Filenames ending in .mnx are always encrypted and are decrypted on import with the same passphrase", "--clipboard: Copy the export to the system clipboard instead of a file, in the text formats"},
+		Examples:  []string{"mindmap export", "mindmap export my_ideas.json", "mindmap export project_x.xml xml", "mindmap export {mindmap}-{date}.json --force", "mindmap export big_map.json --compress=zst", "mindmap export flat.json --include-computed", "mindmap export shared.json --redact personal", "mindmap export out.mnx --encrypt", "mindmap export docx", "mindmap export spec.puml plantuml --node 1.2", "mindmap export places.geojson geojson --node 2", "mindmap export plantuml --node 1.2 --clipboard"},
 	},
 	{
 		Scope:     "mindmap",
 		Operation: "select",
 		ShortDesc: "Select a mindmap",
-		LongDesc:  "Selects the specified mindmap or deselects the current mindmap if no name is provided.",
+		LongDesc:  "Selects the specified mindmap or deselects the current mindmap if no name is provided. With lazy_load_depth set in the configuration, only the root and that many levels below it are loaded, so that large mindmaps are selected at once; mindmap view loads the nodes it shows and other commands using the mindmap load the rest of it.",
 		Syntax:    "mindmap select [mindmap_name]",
 		Arguments: []string{"mindmap_name: (Optional) The name of the mindmap to select"},
 		Examples:  []string{"mindmap select", "mindmap select my_ideas"},
@@ -200,24 +393,134 @@ var commandHelps = []CommandHelp{
 		Scope:     "mindmap",
 		Operation: "list",
 		ShortDesc: "List available mindmaps",
-		LongDesc:  "Displays a list of all mindmaps accessible to the current user.",
-		Syntax:    "mindmap list",
-		Examples:  []string{"mindmap list"},
+		LongDesc:  "Displays a list of all mindmaps accessible to the current user, or a page of it.",
+		Syntax:    "mindmap list [--limit <n>] [--offset <n>]",
+		Arguments: []string{"--limit: (Optional) The number of mindmaps to show", "--offset: (Optional) The number of mindmaps to skip"},
+		Examples:  []string{"mindmap list", "mindmap list --limit 10 --offset 20"},
 	},
 	{
 		Scope:     "mindmap",
 		Operation: "view",
 		ShortDesc: "View mindmap structure",
-		LongDesc:  "Displays the structure of the current mindmap or a specific node.",
-		Syntax:    "mindmap view [index] [--id]",
-		Arguments: []string{"index: (Optional) The index of the node to view", "--id: (Optional) Show node id"},
-		Examples:  []string{"mindmap view", "mindmap view 1.2", "mindmap view --id"},
+		LongDesc:  "Displays the structure of the current mindmap or a specific node. The numbering of the nodes and whether the root is shown as a title line, as a node or not at all follow the zero_based_index and root_display settings, also in commands and document exports. The root can always be addressed as root. Nodes linked with node link list the nodes they link to, such as -> 2.1 Plan, prefixed by the mindmap if it is another one, nodes whose [[Name]] references resolved to nodes list them as [wiki: 3 Draft], and nodes with attachments list them as [attached: report.pdf]. In a mindmap loaded only a few levels deep, viewing a node loads it with lazy_load_depth levels below it, and nodes whose children are not loaded yet show their number, such as [+12 not loaded]. With --times, each node shows how long ago it was created and last modified, such as (created 5d ago, modified 2h ago). With --copy, the view is copied to the system clipboard instead of shown.",
+		Syntax:    "mindmap view [index] [--id] [--times] [--copy]",
+		Arguments: []string{"index: (Optional) The index of the node to view", "--id: (Optional) Show node id", "--times: (Optional) Show when the nodes were created and modified", "--copy: (Optional) Copy the view to the clipboard"},
+		Examples:  []string{"mindmap view", "mindmap view 1.2", "mindmap view --id", "mindmap view 1.2 --times", "mindmap view 1.2 --copy"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "check",
+		ShortDesc: "Report broken references",
+		LongDesc:  "Checks the references of the nodes of the current mindmap and reports those leading nowhere with their node and field: stored links from or to deleted nodes, [[Name]] and [[#id]] references naming no node or several, and malformed URLs. With --http, URLs are also requested and reported unless they respond successfully. Nothing is changed.",
+		Syntax:    "mindmap check links [--http] [--timeout <seconds>] [--id]",
+		Options:   []string{"--http: Request each URL, with a HEAD request where supported", "--timeout <seconds>: The time allowed for each URL to respond. Defaults to 10", "--id: Show node IDs"},
+		Examples:  []string{"mindmap check links", "mindmap check links --http --timeout 5"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "changes",
+		ShortDesc: "List recent node changes",
+		LongDesc:  "Lists the nodes added, updated, moved, sorted and deleted in the current mindmap, as recorded in the audit log, oldest first with the user who made each change. Without --since, the changes since the previous time you selected the mindmap are listed, or those of the last 7 days on a first visit.",
+		Syntax:    "mindmap changes [--since <age|date>]",
+		Options:   []string{"--since <age|date>: List the changes since an age such as 30m, 12h, 2d or 1w, or since a date as YYYY-MM-DD or YYYY-MM-DDTHH:MM"},
+		Examples:  []string{"mindmap changes", "mindmap changes --since 2d", "mindmap changes --since 2024-05-01"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "compare",
+		ShortDesc: "Compare two mindmaps",
+		LongDesc:  "Compares the nodes of two mindmaps you own or that are public, such as a map drawn from memory against a reference map. Nodes are matched by name, ignoring case and spacing, and the nodes only in either mindmap are listed along with the shared nodes under a parent of another name. With --similarity, scores between 0 and 1 are added for the shared node names, the shared words of the names and fields, and the structure, from an approximate tree edit distance counting the nodes to delete, add and move to turn the first mindmap into the second. Nothing is changed.",
+		Syntax:    "mindmap compare <mindmap_a> <mindmap_b> [--similarity]",
+		Arguments: []string{"mindmap_a: The mindmap compared, such as the reference", "mindmap_b: The mindmap it is compared with"},
+		Options:   []string{"--similarity: Add the similarity scores"},
+		Examples:  []string{"mindmap compare biology recall", "mindmap compare biology recall --similarity"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "exists",
+		ShortDesc: "Check that a mindmap exists",
+		LongDesc:  "Prints true if you own a mindmap of the given name or it is public, false otherwise, for scripts. The mindmap is not loaded or selected.",
+		Syntax:    "mindmap exists <mindmap_name>",
+		Arguments: []string{"mindmap_name: The name of the mindmap"},
+		Examples:  []string{"mindmap exists my_ideas"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "graph",
+		ShortDesc: "Emit the graph of the cross-links",
+		LongDesc:  "Emits the graph of the stored links between the nodes of the current mindmap, such as the wiki links of [[Name]] references, for analysis in graph tools: the linked nodes with their index and name and an edge for each link labeled with its type. The tree hierarchy is left out unless --tree is given, which adds all nodes and a dashed edge from each node to each child, of type tree in JSON. The graph is shown, or written to a file in the export directory if a filename is given.",
+		Syntax:    "mindmap graph [filename] [--format dot|json] [--tree] [--force]",
+		Arguments: []string{"filename: (Optional) The file to write to, relative to the export directory. Templates may use {mindmap}, {owner}, {id}, {date}, {time} and {format}"},
+		Options:   []string{"--format dot|json: Graphviz DOT, the default, or a JSON object of nodes and edges", "--tree: Include the tree hierarchy", "--force: Overwrite the file if it already exists"},
+		Examples:  []string{"mindmap graph", "mindmap graph links.dot", "mindmap graph --format json --tree", "mindmap graph {mindmap}-links.{format} --format json"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "summary",
+		ShortDesc: "Summarize a mindmap on one page",
+		LongDesc:  "Shows a compact summary of the current mindmap or of a branch of it, for printing or pasting into a status update: its node count, depth and nodes with fields, an outline of the branches down to the given depth with the size of each, in the default sort of the mindmap, and the soonest nodes with a due or remind time, marking the overdue ones. With --output, the summary is written to a file in the export directory instead, a single page PDF if the filename ends in .pdf and text otherwise.",
+		Syntax:    "mindmap summary [node] [--depth <levels>] [--output <filename>] [--force] [--id]",
+		Arguments: []string{"node: (Optional) The identifier of the branch to summarize. Defaults to the whole mindmap"},
+		Options:   []string{"--depth: Levels of branches shown below the node. Defaults to 2", "--output: The file to write to, relative to the export directory. Templates may use {mindmap}, {owner}, {id}, {date}, {time} and {format}", "--force: Overwrite the file if it already exists", "--id: Use an id instead of an index"},
+		Examples:  []string{"mindmap summary", "mindmap summary 2 --depth 1", "mindmap summary --output status-{date}.pdf", "mindmap summary --depth 3 --output {mindmap}.txt"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "set",
+		ShortDesc: "Set the default sort of the mindmap",
+		LongDesc:  "Sets the order the nodes of the current mindmap are shown in by mindmap view, kept with the mindmap for all sessions. The children of each node are ordered by a field, or by name, without changing their indexes or the stored order, so nodes are still addressed by the indexes shown. Nodes without a value in the field are compared by name, and with the number and date types the nodes whose value is not a number or a date come last. Text compares byte by byte unless collation options are given, which node sort also uses when given none. 'none' shows the mindmap in index order again. Use node sort to change the indexes instead.",
+		Syntax:    "mindmap set sort <field>|name|none [asc|desc] [auto|text|number|date] [natural] [nocase] [locale:<tag>]",
+		Arguments: []string{"field: The field to sort by, name for the node name, created or modified for when the nodes were added or last changed, none to remove the default sort", "asc|desc: (Optional) The direction. Defaults to asc", "auto|text|number|date: (Optional) How values compare: auto as numbers if both are numbers and as text otherwise, or as text, numbers or dates (YYYY-MM-DD or YYYY-MM-DDTHH:MM). Defaults to auto", "natural: (Optional) Compare the numbers within text by value, item2 before item10", "nocase: (Optional) Compare text regardless of case", "locale:<tag>: (Optional) Compare text by the rules of a language, such as locale:de or locale:sv"},
+		Examples:  []string{"mindmap set sort priority desc", "mindmap set sort due asc date", "mindmap set sort name natural nocase", "mindmap set sort none"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "reindex",
+		ShortDesc: "Rebuild the node indexes",
+		LongDesc:  "Numbers the children of each node of the current mindmap from 1 again in their current order and stores the changed indexes in one transaction, after comparing the loaded nodes with the stored ones and reloading them if they differ. The loaded and stored nodes are compared again afterwards. Mindmaps are also reindexed automatically after the number of node deletes and moves set by reindex_threshold in the configuration, 500 by default, a negative number to turn it off.",
+		Syntax:    "mindmap reindex",
+		Examples:  []string{"mindmap reindex"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "restore",
+		ShortDesc: "Restore a mindmap from a snapshot",
+		LongDesc:  "Replaces a mindmap of yours, by default the current one, with its copy in a snapshot of the backup directory, with its nodes, links and attachments, and selects it. A mindmap deleted since the snapshot is added back. The snapshot itself is left as it is, and the rest of the database is not changed. Use system backup to list the snapshots, or start Mindnoscape with --read-only on a snapshot to browse it first.",
+		Syntax:    "mindmap restore <snapshot>|latest [mindmap name]",
+		Arguments: []string{"snapshot: The name of the snapshot, with or without .db, or latest for the newest one", "mindmap name: (Optional) The mindmap to restore. Defaults to the current mindmap"},
+		Examples:  []string{"mindmap restore latest", "mindmap restore mindnoscape-20261017-153000 Projects"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "checkpoint",
+		ShortDesc: "Store a named version of the mindmap",
+		LongDesc:  "Stores the current mindmap with all its nodes, links and attachments as a version named by a label, which mindmap revert brings it back to, also in later sessions. Versions are also stored automatically after the number of node changes set by version_interval in the configuration, 100 by default, a negative number to turn it off; the newest version_retention of them are kept, 20 by default. Checkpoints are kept until the mindmap is deleted.",
+		Syntax:    "mindmap checkpoint <label>",
+		Arguments: []string{"label: The name of the checkpoint, the rest of the line"},
+		Examples:  []string{"mindmap checkpoint before restructuring"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "history",
+		ShortDesc: "List the stored versions of the mindmap",
+		LongDesc:  "Lists the versions stored of the current mindmap, newest first, with their number, time, node count and the label of checkpoints. Automatic versions are marked as such.",
+		Syntax:    "mindmap history",
+		Examples:  []string{"mindmap history"},
+	},
+	{
+		Scope:     "mindmap",
+		Operation: "revert",
+		ShortDesc: "Revert the mindmap to a stored version",
+		LongDesc:  "Brings the current mindmap back to a version listed by mindmap history: the nodes changed since are changed back, the nodes deleted added again and the nodes added deleted, and the links and attachments of the version added again. The current mindmap is stored as a version first, so a revert can be reverted too. Only the owner can revert a mindmap.",
+		Syntax:    "mindmap revert <version>",
+		Arguments: []string{"version: The number of the version, as listed by mindmap history"},
+		Examples:  []string{"mindmap revert 3"},
 	},
 	{
 		Scope:     "node",
 		Operation: "add",
 		ShortDesc: "Add a new node",
-		LongDesc:  "Adds a new node to the current mindmap.",
+		LongDesc:  "Adds a new node to the current mindmap. The lat and lon fields locate the node and must be a latitude and a longitude in decimal degrees. The layout_side (left or right), layout_order (a whole number pinning the node before its unpinned siblings) and layout_weight (heavier siblings first, top-level nodes balanced between the sides by weight) fields are layout hints for PlantUML exports. The style_color and style_fill (colors such as #4f81bd), style_font (bold, italic or both) and style_shape fields hold the style of nodes imported from other tools, which PlantUML exports apply except for the shape.",
 		Syntax:    "node add <parent> <content> [<extra field label>:<extra field value>]... [--id]",
 		Arguments: []string{"parent: The parent node identifier", "content: The content of the new node", "extra: (Optional) Extra fields in the format label:value", "--id: (Optional) Use id instead of index"},
 		Examples:  []string{"node add 1 \"New idea\"", "node add 2.1 \"Sub-idea\" priority:high --id"},
@@ -226,7 +529,7 @@ var commandHelps = []CommandHelp{
 		Scope:     "node",
 		Operation: "update",
 		ShortDesc: "Update a node",
-		LongDesc:  "Updates the content or extra fields of an existing node.",
+		LongDesc:  "Updates the content or extra fields of an existing node. The words changed in the name and fields are shown, and recorded in the audit log, as [-deleted-] {+inserted+}, colored on a terminal.",
 		Syntax:    "node update <node> <content> [<extra field label>:<extra field value>]... [--id]",
 		Arguments: []string{"node: The node identifier to modify", "content: The new content for the node", "extra: (Optional) Extra fields to modify in the format label:value", "--id: (Optional) Use id instead of index"},
 		Examples:  []string{"node update 1.1 \"Updated idea\"", "node update 2 \"Changed content\" priority:low --id"},
@@ -235,37 +538,181 @@ var commandHelps = []CommandHelp{
 		Scope:     "node",
 		Operation: "move",
 		ShortDesc: "Move a node",
-		LongDesc:  "Moves a node to a new parent in the current mindmap.",
-		Syntax:    "node move <source> <target> [--id]",
-		Arguments: []string{"source: The identifier of the node to move", "target: The identifier of the new parent node", "--id: (Optional) Use id instead of index"},
-		Examples:  []string{"node move 1.2 2.1", "node move 3 1 --id"},
+		LongDesc:  "Moves a node to a new parent in the current mindmap. With --preview, the mindmap is shown as it would look after the move, without moving anything.",
+		Syntax:    "node move <source> <target> [--id] [--preview]",
+		Arguments: []string{"source: The identifier of the node to move", "target: The identifier of the new parent node", "--id: (Optional) Use id instead of index", "--preview: (Optional) Show the result with moved nodes marked instead of moving"},
+		Examples:  []string{"node move 1.2 2.1", "node move 3 1 --id", "node move 1.2 2 --preview"},
+	},
+	{
+		Scope:     "node",
+		Operation: "copy",
+		ShortDesc: "Copy a node",
+		LongDesc:  "Copies a node with its whole subtree, names and extra fields, as the last child of a target node. The copies get new IDs and indexes, the copied nodes are left unchanged. Copying more nodes than the configured threshold requires --force.",
+		Syntax:    "node copy <source> <target> [--id] [--force]",
+		Arguments: []string{"source: The identifier of the node to copy", "target: The identifier of the parent node of the copy", "--id: (Optional) Use id instead of index", "--force: (Optional) Copy large subtrees"},
+		Examples:  []string{"node copy 1.2 2.1", "node copy 3 1 --id"},
+	},
+	{
+		Scope:     "node",
+		Operation: "indent",
+		ShortDesc: "Indent a node",
+		LongDesc:  "Makes a node, with its subtree, the last child of its previous sibling.",
+		Syntax:    "node indent <node> [--id]",
+		Arguments: []string{"node: The identifier of the node to indent", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node indent 1.2", "node indent 5 --id"},
+	},
+	{
+		Scope:     "node",
+		Operation: "outdent",
+		ShortDesc: "Outdent a node",
+		LongDesc:  "Makes a node, with its subtree, the next sibling of its parent.",
+		Syntax:    "node outdent <node> [--id]",
+		Arguments: []string{"node: The identifier of the node to outdent", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node outdent 1.2.1", "node outdent 5 --id"},
+	},
+	{
+		Scope:     "node",
+		Operation: "field",
+		ShortDesc: "Rename or delete a content field",
+		LongDesc:  "Renames or deletes a content field in all nodes of the current mindmap, or of the subtree of a node, in one pass. Nodes that already have the new field are skipped on rename. A dry run reports the affected nodes without changing them.",
+		Syntax:    "node field rename <old> <new> | delete <key> [--scope mindmap|subtree <node>] [--id] [--dry-run]",
+		Arguments: []string{"old: The field to rename", "new: The new field name", "key: The field to delete", "--scope: (Optional) The whole mindmap, the default, or the subtree of a node", "--id: (Optional) Use id instead of index", "--dry-run: (Optional) Report the affected nodes without changing them"},
+		Examples:  []string{"node field rename prio priority", "node field delete draft --scope subtree 1.2 --dry-run"},
+	},
+	{
+		Scope:     "node",
+		Operation: "swap",
+		ShortDesc: "Swap two sibling nodes",
+		LongDesc:  "Exchanges the positions of two nodes with the same parent, with their subtrees.",
+		Syntax:    "node swap <node> <node> [--id]",
+		Arguments: []string{"node: The identifiers of the two sibling nodes", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node swap 1.2 1.4", "node swap 5 7 --id"},
+	},
+	{
+		Scope:     "node",
+		Operation: "rotate",
+		ShortDesc: "Rotate the children of a node",
+		LongDesc:  "Moves the last n children of a node to the front, or the first children to the back for a negative n.",
+		Syntax:    "node rotate <parent> [n] [--id]",
+		Arguments: []string{"parent: The identifier of the node whose children are rotated", "n: (Optional) Number of positions, 1 by default", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node rotate 0", "node rotate 1.2 -2"},
+	},
+	{
+		Scope:     "node",
+		Operation: "exists",
+		ShortDesc: "Check that a node exists",
+		LongDesc:  "Prints true if the current mindmap has a node of the given index or ID, false otherwise, for scripts. Private nodes of other users do not exist for you.",
+		Syntax:    "node exists <node> [--id]",
+		Arguments: []string{"node: The node index, or ID with --id", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node exists 1.2", "node exists 42 --id"},
+	},
+	{
+		Scope:     "node",
+		Operation: "count",
+		ShortDesc: "Count nodes",
+		LongDesc:  "Prints the number of nodes of the current mindmap including its root, or of the subtree of a node including the node, for scripts.",
+		Syntax:    "node count [node] [--id]",
+		Arguments: []string{"node: (Optional) The node whose subtree to count. Defaults to the whole mindmap", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node count", "node count 1.2", "node count 42 --id"},
 	},
 	{
 		Scope:     "node",
 		Operation: "delete",
-		ShortDesc: "Delete a node",
-		LongDesc:  "Deletes a node and its subtree from the current mindmap.",
-		Syntax:    "node delete <node> [--id]",
-		Arguments: []string{"node: The identifier of the node to delete", "--id: (Optional) Use id instead of index"},
-		Examples:  []string{"node delete 1.2", "node delete 3 --id"},
+		ShortDesc: "Delete nodes",
+		LongDesc:  "Deletes nodes and their subtrees from the current mindmap, all or none of them. Nodes are selected by index, index pattern or range of siblings. Deleting subtrees larger than the configured threshold must be forced, as must deleting nodes other nodes refer to, with [[Name]] or [[#id]] references or stored links, which are listed and would be left dangling.",
+		Syntax:    "node delete <node>... [--id] [--force]",
+		Arguments: []string{"node: The identifiers of the nodes to delete, an index, a pattern such as 2.* or a range such as 1.2-1.5", "--id: (Optional) Use ids or id ranges such as 4-9 instead of indexes", "--force: (Optional) Delete subtrees larger than the configured threshold or referred to by other nodes"},
+		Examples:  []string{"node delete 1.2", "node delete 1.2 1.4 1.7", "node delete 2.*", "node delete 3-5 --id"},
+	},
+	{
+		Scope:     "node",
+		Operation: "edit",
+		ShortDesc: "Edit a node in place",
+		LongDesc:  "Edits the name of a node in the CLI, starting from its current name instead of retyping it, and saves it as node update does. With --fields the fields are edited too, as label:value after the name, otherwise they are kept. Escape cancels the edit. Only available in a terminal.",
+		Syntax:    "node edit <node> [--fields] [--id]",
+		Arguments: []string{"node: The index or ID of the node to edit", "--fields: (Optional) Edit the fields along with the name", "--id: (Optional) Use node id instead of index"},
+		Examples:  []string{"node edit 1.2", "node edit 1.2 --fields", "node edit 5 --id"},
 	},
 	{
 		Scope:     "node",
 		Operation: "find",
 		ShortDesc: "Find nodes",
-		LongDesc:  "Searches for nodes in the current mindmap based on a query string.",
-		Syntax:    "node find <query> [--id]",
-		Arguments: []string{"query: The search query string", "--id: (Optional) Show node id in the results"},
-		Examples:  []string{"node find \"important idea\"", "node find project --id"},
+		LongDesc:  "Searches for nodes in the current mindmap with a query. Each word of the query is a term, a text found in the name or the content fields of a node regardless of case, or a regular expression written as /regex/, such as /^v[0-9]+$/, which is case-sensitive unless it starts with (?i). A term can be limited to the name, the index, the content fields or the value of one content field by writing name:, index:, content: or content.<field>: in front of it, content.<field>: alone finding the nodes with the field. Terms are combined with AND and OR, in capitals, AND binding tighter; terms next to each other must all match. Words cannot contain spaces, use \\s in a regular expression instead. Queries of texts of at least 3 characters, without index: or content.<field>: terms, are searched in the full-text index of the database if SQLite is built with it, and list the best matches first; other queries list the nodes in mindmap order. With --tag only the nodes having the tag are found, looked up by the index of the tags, and the query can then be left out; --tag can be given more than once for nodes having all the tags. Long result lists can be shown a page at a time with --limit and --offset.",
+		Syntax:    "node find [<query>...] [--tag <tag>]... [--id] [--limit <n>] [--offset <n>]",
+		Arguments: []string{"query: The terms to search for, combined with AND and OR", "--tag: (Optional) A tag the nodes found must have", "--id: (Optional) Show node id in the results", "--limit: (Optional) The number of results to show", "--offset: (Optional) The number of results to skip"},
+		Examples:  []string{"node find project --id", "node find /^todo\\s/", "node find name:report content.priority:high", "node find content.status:open OR content.status:blocked", "node find content.due:", "node find --tag work", "node find report --tag work --tag urgent", "node find task --limit 10 --offset 10"},
+	},
+	{
+		Scope:     "node",
+		Operation: "tag",
+		ShortDesc: "Tag nodes and list the tags",
+		LongDesc:  "Adds tags to nodes, removes them or lists them. Nodes are selected by index, index pattern or range of siblings, as for node delete. Tags are labels kept apart from the content fields, shown after the node name in the mindmap view as #tag and found with node find --tag. They are not case-sensitive and can be written with or without the leading #. Without a node, list shows all the tags of the mindmap with the number of nodes having each.",
+		Syntax:    "node tag add <node> <tag>... | remove <node> <tag>... | list [node] [--id]",
+		Arguments: []string{"add: Add the tags to the nodes", "remove: Remove the tags from the nodes", "list: List the tags of the nodes, or of the mindmap", "node: The node identifier, an index, a pattern such as 2.* or a range such as 1.2-1.5", "tag: A tag of letters, digits, '-', '_', '.' or '/'", "--id: (Optional) Use ids or id ranges such as 4-9 instead of indexes"},
+		Examples:  []string{"node tag add 1.2 work urgent", "node tag add 2.* urgent", "node tag remove 1.2 #urgent", "node tag list 1.2", "node tag list"},
+	},
+	{
+		Scope:     "node",
+		Operation: "link",
+		ShortDesc: "Link a node to another node",
+		LongDesc:  "Links a node to another node outside the tree hierarchy, in the current mindmap or with --mindmap in another mindmap accessible to the current user, or removes the link with --remove. The links of a node are listed after it in the mindmap view and kept in json and xml exports, the links within the mindmap also being part of its link graph; links to other mindmaps name the mindmap so that they are restored on import if a mindmap of that name exists.",
+		Syntax:    "node link <source> <target> [--mindmap <mindmap>] [--remove] [--id]",
+		Arguments: []string{"source: The identifier of the node linking", "target: The identifier of the node linked to", "--mindmap: (Optional) The mindmap of the target node, the current mindmap by default", "--remove: (Optional) Remove the link instead of adding it", "--id: (Optional) Use id instead of index for both nodes"},
+		Examples:  []string{"node link 1.2 3", "node link 1.2 2.1 --mindmap research", "node link 1.2 3 --remove"},
+	},
+	{
+		Scope:     "node",
+		Operation: "attach",
+		ShortDesc: "Attach files and web addresses to a node",
+		LongDesc:  "Attaches files of the export directory, stored in the database up to 16 MiB each, or references to http and https addresses to a node. Without a file or address, lists the attachments of the node. Attachments are named by their file name or address, listed after the node in the mindmap view as [attached: ...] and kept in json and xml exports, files in base64. --save writes an attached file back to the export directory, under its own name or the file given, and --remove removes an attachment.",
+		Syntax:    "node attach <node> [<file-or-url>...] | <node> --remove <name> | <node> --save <name> [<file>] [--force] [--id]",
+		Arguments: []string{"node: The node identifier", "file-or-url: (Optional) A file of the export directory or an http or https address to attach", "--remove: (Optional) Remove the attachment of that name", "--save: (Optional) Save the attached file of that name to the export directory", "--force: (Optional) Overwrite an existing file when saving", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node attach 1.2 report.pdf", "node attach 1.2 https://example.com/spec", "node attach 1.2", "node attach 1.2 --save report.pdf report-copy.pdf", "node attach 1.2 --remove report.pdf"},
+	},
+	{
+		Scope:     "node",
+		Operation: "backlinks",
+		ShortDesc: "List nodes referring to a node",
+		LongDesc:  "Lists the nodes whose name or content refers to a node, by name as [[Name]] or by ID as [[#12]], and the nodes of this or other mindmaps linked to it with node link, marked (link). Name references are not case-sensitive.",
+		Syntax:    "node backlinks <node> [--id]",
+		Arguments: []string{"node: The identifier of the referenced node", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node backlinks 1.2", "node backlinks 5 --id"},
+	},
+	{
+		Scope:     "node",
+		Operation: "remind",
+		ShortDesc: "Set or clear the reminder of a node",
+		LongDesc:  "Sets the remind field of a node to the local time to remind of it, or clears it. Nodes without a remind field are reminded of at the time of their due field. Due reminders are checked at the configured reminder interval and delivered to the configured sinks: webhooks, a command such as notify-send, or the log.",
+		Syntax:    "node remind <node> <time>|clear [--id]",
+		Arguments: []string{"node: The node identifier", "time: The time as YYYY-MM-DDTHH:MM, or a date as YYYY-MM-DD for the start of the day, or 'clear' to remove the reminder", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node remind 1.2 2025-07-01T09:00", "node remind 1.2 clear"},
+	},
+	{
+		Scope:     "node",
+		Operation: "private",
+		ShortDesc: "Make the branch of a node private",
+		LongDesc:  "Sets or clears the private field of a node. The branch of a private node is hidden from the other users of a public mindmap: it is left out of what they view, find and export, and the mindmap can then only be changed by its owner. Only the owner can make branches private.",
+		Syntax:    "node private <node> [on|off] [--id]",
+		Arguments: []string{"node: The node identifier", "on|off: (Optional) Make the branch private or public again. Defaults to 'on'", "--id: (Optional) Use id instead of index"},
+		Examples:  []string{"node private 1.2", "node private 1.2 off"},
+	},
+	{
+		Scope:     "node",
+		Operation: "wikilink",
+		ShortDesc: "Link a node to the nodes named in its content",
+		LongDesc:  "Resolves the [[Name]] and [[#id]] references in the content of a node to the nodes they name and stores them as wiki links of the node. A name resolves if exactly one node has it. Links are also updated when a node is added or updated, a terminal then offering to add the nodes of names of no node. With --create, a node is added for each name of no node, under the top-level Inbox node or the given inbox node.",
+		Syntax:    "node wikilink <node> [--create] [--inbox <node>] [--id]",
+		Arguments: []string{"node: The identifier of the node with the references", "--create: (Optional) Add nodes for names of no node", "--inbox: (Optional) The parent of the added nodes", "--id: (Optional) Use ids instead of indexes"},
+		Examples:  []string{"node wikilink 1.2", "node wikilink 1.2 --create", "node wikilink 1.2 --create --inbox 3"},
 	},
 	{
 		Scope:     "node",
 		Operation: "sort",
 		ShortDesc: "Sort child nodes",
-		LongDesc:  "Sorts the child nodes of a specified node based on content or an extra field.",
-		Syntax:    "node sort [identifier] [field] [--reverse] [--id]",
-		Arguments: []string{"identifier: (Optional) The node whose children to sort. Defaults to root", "field: (Optional) The field to sort by. Defaults to node content", "--reverse: (Optional) Sort in descending order", "--id: (Optional) Use id instead of index"},
-		Examples:  []string{"node sort", "node sort 1.2 priority --reverse", "node sort 2 --id"},
+		LongDesc:  "Sorts the child nodes of a specified node based on content or an extra field. Text compares byte by byte, or as set by --natural, --nocase and --locale, which default to the collation options of the default sort of the mindmap set with mindmap set sort. With --sort created or modified, nodes are ordered by when they were added or last changed, oldest first unless reversed, moves and sorts also counting as changes. Sorting a subtree larger than the configured threshold must be forced. With --preview, the subtree is shown as it would look after sorting, without sorting anything.",
+		Syntax:    "node sort [identifier] [field | --sort created|modified] [--reverse] [--natural] [--nocase] [--locale <tag>] [--id] [--force] [--preview]",
+		Arguments: []string{"identifier: (Optional) The node whose children to sort. Defaults to root", "field: (Optional) The field to sort by. Defaults to node content", "--sort created|modified: (Optional) Sort by when the nodes were created or last modified instead of a field", "--reverse: (Optional) Sort in descending order", "--natural: (Optional) Compare the numbers within text by value, item2 before item10", "--nocase: (Optional) Compare text regardless of case", "--locale: (Optional) Compare text by the rules of a language, such as de or sv", "--id: (Optional) Use id instead of index", "--force: (Optional) Sort a subtree larger than the configured threshold", "--preview: (Optional) Show the result with moved nodes marked instead of sorting"},
+		Examples:  []string{"node sort", "node sort 1.2 priority --reverse", "node sort 1 --natural --nocase", "node sort 1.2 --sort modified --reverse", "node sort 3 --locale sv", "node sort 2 --id", "node sort 1 priority --preview"},
 	},
 	{
 		Scope:     "node",
@@ -283,6 +730,117 @@ var commandHelps = []CommandHelp{
 		Syntax:    "node redo",
 		Examples:  []string{"node redo"},
 	},
+	{
+		Scope:     "journal",
+		Operation: "today",
+		ShortDesc: "Go to the journal node of today",
+		LongDesc:  "Selects the configured daily journal mindmap of the current user and makes the node of today, named YYYY-MM-DD, the current node. The mindmap, the configured branch node under its root and the day node are added if missing, new day nodes get the fields and child nodes of the configured journal template.",
+		Syntax:    "journal today",
+		Examples:  []string{"journal today", "j t"},
+	},
+	{
+		Scope:     "add",
+		ShortDesc: "Add text to the inbox",
+		LongDesc:  "Adds the text as a node under the inbox of the default mindmap of the current user, set with user inbox, or under its top-level Inbox node. Without a default mindmap, adds to the inbox of the selected mindmap. With --mindmap, adds under the top-level Inbox node of another own mindmap, and with --parent under a node of the mindmap instead of its inbox. The mindmap selected stays selected, so ideas are captured without leaving the current work.",
+		Syntax:    "add [--mindmap <mindmap>] [--parent <node>] <text>",
+		Arguments: []string{"--mindmap: (Optional) The own mindmap to add to instead of the default mindmap", "--parent: (Optional) The index of the node to add under instead of the inbox", "text: The name of the node, the rest of the line as typed"},
+		Examples:  []string{"add Call the plumber", "add Ask about the release date", "add --mindmap work --parent 2 Review the budget"},
+	},
+	{
+		Scope:     "admin",
+		Operation: "audit",
+		ShortDesc: "Show the audit log",
+		LongDesc:  "Displays entries of the append-only audit log recording who ran which command, when, on which target and with which result. 'tail' shows the most recent entries, 'search' shows the most recent entries matching the query. Admins, the users listed in admin_users of the configuration, see the entries of all users, other users their own.",
+		Syntax:    "admin audit tail [count] | admin audit search <query> [count]",
+		Arguments: []string{"count: (Optional) The number of entries to show. Defaults to 20", "query: The text to search for in user, scope, operation, target, result and error"},
+		Examples:  []string{"admin audit tail", "admin audit tail 50", "admin audit search delete", "admin audit search john 100"},
+	},
+	{
+		Scope:     "system",
+		Operation: "replay",
+		ShortDesc: "Replay a command journal",
+		LongDesc:  "Re-applies the mutating commands recorded in a command journal file to the current database, for disaster recovery or to reproduce a reported problem. Redacted passwords are replayed as empty passwords.",
+		Syntax:    "system replay <file> [range]",
+		Arguments: []string{"file: The journal file to replay, within the log folder", "range: (Optional) The 1-based entries to replay, as N, N-M, N- or -M. Defaults to all entries"},
+		Examples:  []string{"system replay journal.log", "system replay journal.log 10-25"},
+	},
+	{
+		Scope:     "admin",
+		Operation: "events",
+		ShortDesc: "Show event queue metrics",
+		LongDesc:  "Displays the delivery metrics of the event queues: current and maximum queue depth, the number of published, delivered, failed, dropped and duplicate events, and the ID of the last delivered event per event type. Only for admins, the users listed in admin_users of the configuration.",
+		Syntax:    "admin events",
+		Examples:  []string{"admin events"},
+	},
+	{
+		Scope:     "admin",
+		Operation: "telemetry",
+		ShortDesc: "Show or export the command telemetry",
+		LongDesc:  "Displays how often each command was used and how often it failed, as recorded when telemetry is enabled in the configuration. Only the scope and operation of the commands are counted, without users, arguments or data, and nothing leaves the machine unless the exported file is shared. 'export' writes the counts with the error rates to a JSON file in the export directory, 'reset' deletes them. Only for admins, the users listed in admin_users of the configuration.",
+		Syntax:    "admin telemetry [show] | admin telemetry export [file] | admin telemetry reset",
+		Arguments: []string{"file: (Optional) The file to export to, within the export directory. Defaults to telemetry-{date}.json"},
+		Examples:  []string{"admin telemetry", "admin telemetry export", "admin telemetry export usage.json", "admin telemetry reset"},
+	},
+	{
+		Scope:     "system",
+		Operation: "db",
+		ShortDesc: "Check or compact the database",
+		LongDesc:  "Checks the database for corruption and broken references between its tables, reporting the problems found, or compacts the database file to reclaim the space left by deleted data.",
+		Syntax:    "system db check|vacuum",
+		Arguments: []string{"check: Check the integrity of the database", "vacuum: Compact the database file"},
+		Examples:  []string{"system db check", "system db vacuum"},
+	},
+	{
+		Scope:     "system",
+		Operation: "gc",
+		ShortDesc: "Remove orphaned links and attachments",
+		LongDesc:  "Reports the links and attachments left in the database without the node or mindmap they belong to, such as the links of nodes deleted by earlier versions, and removes them with --force. The space they take is reclaimed by a following system db vacuum.",
+		Syntax:    "system gc [--force]",
+		Arguments: []string{"--force: (Optional) Remove the orphaned rows instead of only reporting them"},
+		Examples:  []string{"system gc", "system gc --force"},
+	},
+	{
+		Scope:     "system",
+		Operation: "backup",
+		ShortDesc: "Take or list database snapshots",
+		LongDesc:  "Writes a snapshot of the whole database to the backup directory now, or lists the snapshots in it with their size and time. Snapshots are also taken every backup_interval seconds set in the configuration, a day by default, a negative number to turn them off, and when Mindnoscape exits. The oldest are deleted beyond backup_retention, 7 by default, a negative number to keep them all. Use mindmap restore to bring back a mindmap from a snapshot.",
+		Syntax:    "system backup [now|list]",
+		Arguments: []string{"now: Take a snapshot now", "list: (Default) List the snapshots, newest first"},
+		Examples:  []string{"system backup now", "system backup"},
+	},
+	{
+		Scope:     "system",
+		Operation: "version",
+		ShortDesc: "Show the version and build information",
+		LongDesc:  "Shows the version of Mindnoscape, the Go version, platform and commit it was built from, the schema version of the database and the one this version supports, the storage backend with its database file, the configuration file and the versions of the modules built in, such as to include in a bug report. A database with a newer schema was written by a newer version of Mindnoscape, which may store data this version does not know, so it is opened read-only and warned about at startup; upgrade Mindnoscape to change it.",
+		Syntax:    "system version",
+		Examples:  []string{"system version"},
+	},
+	{
+		Scope:     "system",
+		Operation: "jobs",
+		ShortDesc: "List the background jobs",
+		LongDesc:  "Lists the background jobs of the session, those running with their progress and the last 20 finished with their results. On a terminal, imports, exports to files, mindmap reindex and system db run as background jobs while other commands are taken, the prompt showing the running jobs, and their results are shown once they are done. Piped and redirected input runs them one after the other as any command. The prompt template places the jobs with {jobs}, templates without it show them in front.",
+		Syntax:    "system jobs",
+		Examples:  []string{"system jobs"},
+	},
+	{
+		Scope:     "system",
+		Operation: "transcript",
+		ShortDesc: "Record the session to a file",
+		LongDesc:  "Starts or stops recording the commands of the session to a text file, each after the user and mindmap selected when it was entered and followed by its result or error, such as to attach a reproducible trace to a bug report. The results of background jobs are recorded once they are done. Passwords are redacted from the commands and their results, other content is recorded as shown, so review the file before sharing it. Files are written to the export directory, transcript-{date}-{time}.txt if no file is given.",
+		Syntax:    "system transcript start [file] | stop",
+		Arguments: []string{"start: Start recording, to file if given", "stop: Stop recording", "file: (Optional) The file to record to, relative to the export directory, {date} and {time} are replaced"},
+		Examples:  []string{"system transcript start", "system transcript start bug-report.txt", "system transcript stop"},
+	},
+	{
+		Scope:     "system",
+		Operation: "ping",
+		ShortDesc: "Keep the session alive",
+		LongDesc:  "Replies pong. Sessions without commands for 30 minutes expire, clients that stay idle longer can ping to keep their session.",
+		Syntax:    "system ping",
+		Examples:  []string{"system ping"},
+	},
 	{
 		Scope:     "system",
 		Operation: "exit",
@@ -0,0 +1,137 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// handleSystemTranscript handles the system transcript command, which starts or stops recording the commands of the
+// session and their results to a file, such as to attach to a bug report
+func handleSystemTranscript(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling system transcript command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 || cmd.Args[0] == "stop" && len(cmd.Args) != 1 {
+		sm.logger.Error(ctx, "Invalid arguments for system transcript", log.Fields{"args": cmd.Args})
+		return nil, errors.New("system transcript command requires 1 or 2 arguments: start [file] | stop")
+	}
+
+	switch cmd.Args[0] {
+	case "start":
+		if session.Transcript != "" {
+			return nil, fmt.Errorf("a transcript is already recorded to %s, stop it first", session.Transcript)
+		}
+		filename := ""
+		if len(cmd.Args) == 2 {
+			filename = cmd.Args[1]
+		}
+		path, err := sm.dataManager.TranscriptPath(filename)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			sm.logger.Error(ctx, "Failed to create transcript directory", log.Fields{"error": err, "path": path})
+			return nil, fmt.Errorf("failed to start transcript: %w", err)
+		}
+		header := fmt.Sprintf("# Mindnoscape transcript started %s on %s/%s\n", time.Now().Format(time.RFC3339), runtime.GOOS, runtime.GOARCH)
+		if err := transcriptAppend(path, header); err != nil {
+			sm.logger.Error(ctx, "Failed to start transcript", log.Fields{"error": err, "path": path})
+			return nil, fmt.Errorf("failed to start transcript: %w", err)
+		}
+		session.Transcript = path
+		sm.logger.Info(ctx, "Transcript started", log.Fields{"sessionID": session.ID, "path": path})
+		return fmt.Sprintf("Recording the commands of the session to %s, passwords redacted", path), nil
+	case "stop":
+		if session.Transcript == "" {
+			return nil, errors.New("no transcript is recorded")
+		}
+		path := session.Transcript
+		session.Transcript = ""
+		if err := transcriptAppend(path, fmt.Sprintf("# Transcript stopped %s\n", time.Now().Format(time.RFC3339))); err != nil {
+			sm.logger.Warn(ctx, "Failed to end transcript", log.Fields{"error": err, "path": path})
+		}
+		sm.logger.Info(ctx, "Transcript stopped", log.Fields{"sessionID": session.ID, "path": path})
+		return fmt.Sprintf("Transcript recorded to %s", path), nil
+	default:
+		sm.logger.Error(ctx, "Invalid system transcript action", log.Fields{"action": cmd.Args[0]})
+		return nil, fmt.Errorf("invalid system transcript action: %s. Must be 'start' or 'stop'", cmd.Args[0])
+	}
+}
+
+// transcriptMiddleware records the commands of the sessions recording a transcript along with their results, the
+// user and mindmap selected when they were entered and the passwords redacted
+func (sm *SessionManager) transcriptMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		if session.Transcript == "" || cmd.Scope == "system" && (cmd.Operation == "transcript" || cmd.Operation == "ping") {
+			return next(sm, session, cmd)
+		}
+
+		var prompt []string
+		if session.User != nil {
+			prompt = append(prompt, session.User.Username)
+		}
+		if session.Mindmap != nil {
+			prompt = append(prompt, session.Mindmap.Name)
+		}
+		path := session.Transcript
+
+		result, err := next(sm, session, cmd)
+		sm.transcriptRecord(path, strings.TrimLeft(strings.Join(prompt, " @ ")+" > "+jobCommand(cmd), " "), cmd, result, err)
+		return result, err
+	}
+}
+
+// transcriptJob records the outcome of a background job of a session recording a transcript
+func (sm *SessionManager) transcriptJob(session *model.Session, id int, cmd model.Command, result interface{}, err error) {
+	if session.Transcript != "" {
+		sm.transcriptRecord(session.Transcript, fmt.Sprintf("# Job %d (%s) finished", id, jobCommand(cmd)), cmd, result, err)
+	}
+}
+
+// transcriptRecord appends a heading line and the result or error of a command to a transcript. The passwords
+// redacted from the command are also redacted from its result.
+func (sm *SessionManager) transcriptRecord(path, heading string, cmd model.Command, result interface{}, err error) {
+	var text string
+	switch {
+	case err != nil:
+		text = "Error: " + err.Error()
+	case result != nil:
+		text = fmt.Sprintf("%v", result)
+	}
+	redacted := redactCommandArgs(cmd)
+	for i, arg := range cmd.Args {
+		if redacted[i] != arg && arg != "" {
+			text = strings.ReplaceAll(text, arg, redactedArg)
+		}
+	}
+
+	entry := heading + "\n"
+	if text != "" {
+		entry += strings.TrimRight(text, "\n") + "\n"
+	}
+	if writeErr := transcriptAppend(path, entry); writeErr != nil {
+		sm.logger.Error(context.Background(), "Failed to write transcript", log.Fields{"error": writeErr, "path": path})
+	}
+}
+
+// transcriptAppend appends text to a transcript file
+func transcriptAppend(path, text string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
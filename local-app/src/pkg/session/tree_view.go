@@ -0,0 +1,107 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// treeIndent is the indentation per level of the rendered tree
+const treeIndent = "  "
+
+// DisplayStyle returns the configured display style of node indexes and the root node
+func (sm *SessionManager) DisplayStyle() model.DisplayStyle {
+	return sm.dataManager.Config.DisplayStyle()
+}
+
+// nodeIndex returns the index of a node as shown to users
+func (sm *SessionManager) nodeIndex(node *model.Node) string {
+	return sm.DisplayStyle().DisplayIndex(node.Index)
+}
+
+// formatTree renders the subtree of a node as an indented outline in document order, one node per line, with
+// the indexes and the root laid out following the display style. Siblings are ordered by order, in index order if
+// it is zero, their indexes staying those they are addressed by.
+// Annotate, if not nil, returns a note appended to the line of a node, such as a highlight in a preview.
+func formatTree(mindmap *model.Mindmap, node *model.Node, order model.SortSpec, style model.DisplayStyle, showID bool, annotate func(*model.Node) string) string {
+	hideRoot := style.Root() == model.RootHidden && (node == nil || node.ID == 0)
+	var view strings.Builder
+	for depth, n := range mindmap.SortedWalk(node, order) {
+		if hideRoot {
+			if n.ID == 0 {
+				continue
+			}
+			depth--
+		}
+		view.WriteString(strings.Repeat(treeIndent, depth))
+		if n.ID != 0 || style.Root() == model.RootAsNode {
+			view.WriteString(style.DisplayIndex(n.Index) + " ")
+		}
+		view.WriteString(n.Name)
+		if len(n.Tags) > 0 {
+			view.WriteString(" " + formatTags(n.Tags))
+		}
+		if showID {
+			fmt.Fprintf(&view, " (ID: %d)", n.ID)
+		}
+		if annotate != nil {
+			if note := annotate(n); note != "" {
+				view.WriteString("  " + note)
+			}
+		}
+		view.WriteString("\n")
+	}
+	return strings.TrimSuffix(view.String(), "\n")
+}
+
+// formatPreview renders the subtree of a node of a previewed mindmap, marking the nodes whose index
+// differs from the current mindmap
+func formatPreview(operation string, current, preview *model.Mindmap, node *model.Node, style model.DisplayStyle, showID bool) string {
+	moved := 0
+	annotate := func(n *model.Node) string {
+		if before, ok := current.Nodes[n.ID]; ok && before.Index != n.Index {
+			moved++
+			return fmt.Sprintf("<- moved from %s", style.DisplayIndex(before.Index))
+		}
+		return ""
+	}
+
+	// Previews show the index order they lead to
+	tree := formatTree(preview, preview.Nodes[node.ID], model.SortSpec{}, style, showID, annotate)
+	return fmt.Sprintf("Preview of %s, %d nodes moved, nothing changed:\n%s", operation, moved, tree)
+}
+
+// formatNodeTimes formats when a node was created and last modified relative to now, such as "(created 5d ago,
+// modified 2h ago)", the modification left out if the node is unchanged since it was created
+func formatNodeTimes(node *model.Node, now time.Time) string {
+	if node.Created.IsZero() {
+		return ""
+	}
+	times := "(created " + formatAge(node.Created, now)
+	if node.Updated.After(node.Created) {
+		times += ", modified " + formatAge(node.Updated, now)
+	}
+	return times + ")"
+}
+
+// formatAge formats the time elapsed since t in the largest whole unit, such as "3d ago"
+func formatAge(t, now time.Time) string {
+	elapsed := now.Sub(t)
+	day := 24 * time.Hour
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", elapsed/time.Minute)
+	case elapsed < day:
+		return fmt.Sprintf("%dh ago", elapsed/time.Hour)
+	case elapsed < 60*day:
+		return fmt.Sprintf("%dd ago", elapsed/day)
+	case elapsed < 365*day:
+		return fmt.Sprintf("%dmo ago", elapsed/(30*day))
+	default:
+		return fmt.Sprintf("%dy ago", elapsed/(365*day))
+	}
+}
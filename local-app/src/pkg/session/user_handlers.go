@@ -7,6 +7,7 @@ import (
 
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
 )
 
 // handleUserAdd handles the user add command
@@ -20,6 +21,11 @@ func handleUserAdd(sm *SessionManager, session *model.Session, cmd model.Command
 	}
 
 	username := cmd.Args[0]
+	if err := names.Validate(names.User, username); err != nil {
+		sm.logger.Error(ctx, "Invalid username", log.Fields{"error": err, "username": username})
+		return nil, err
+	}
+
 	var password string
 	if len(cmd.Args) == 2 {
 		password = cmd.Args[1]
@@ -51,11 +57,6 @@ func handleUserUpdate(sm *SessionManager, session *model.Session, cmd model.Comm
 		return nil, errors.New("invalid number of arguments for user update")
 	}
 
-	if session.User == nil {
-		sm.logger.Error(ctx, "No user selected", nil)
-		return nil, fmt.Errorf("no user selected")
-	}
-
 	username := cmd.Args[0]
 	if username != session.User.Username {
 		sm.logger.Error(ctx, "Can only update the current user", log.Fields{"requestedUser": username, "currentUser": session.User.Username})
@@ -66,6 +67,10 @@ func handleUserUpdate(sm *SessionManager, session *model.Session, cmd model.Comm
 	updateFilter := model.UserFilter{}
 
 	if len(cmd.Args) > 1 {
+		if err := names.Validate(names.User, cmd.Args[1]); err != nil {
+			sm.logger.Error(ctx, "Invalid username", log.Fields{"error": err, "username": cmd.Args[1]})
+			return nil, err
+		}
 		updateInfo.Username = cmd.Args[1]
 		updateFilter.Username = true
 		sm.logger.Debug(ctx, "Updating username", log.Fields{"newUsername": updateInfo.Username})
@@ -102,11 +107,6 @@ func handleUserDelete(sm *SessionManager, session *model.Session, cmd model.Comm
 		return nil, errors.New("invalid number of arguments for user delete")
 	}
 
-	if session.User == nil {
-		sm.logger.Error(ctx, "No user selected", nil)
-		return nil, fmt.Errorf("no user selected")
-	}
-
 	username := cmd.Args[0]
 	if username != session.User.Username {
 		sm.logger.Error(ctx, "Can only delete the current user", log.Fields{"requestedUser": username, "currentUser": session.User.Username})
@@ -133,7 +133,7 @@ func handleUserSelect(sm *SessionManager, session *model.Session, cmd model.Comm
 	ctx := context.Background()
 	sm.logger.Info(ctx, "Handling user select command", log.Fields{"args": cmd.Args})
 
-	if len(cmd.Args) != 1 {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
 		sm.logger.Error(ctx, "Invalid number of arguments for user select", log.Fields{"argCount": len(cmd.Args)})
 		return nil, errors.New("invalid number of arguments for user select")
 	}
@@ -141,6 +141,27 @@ func handleUserSelect(sm *SessionManager, session *model.Session, cmd model.Comm
 	username := cmd.Args[0]
 	sm.logger.Debug(ctx, "Attempting to select user", log.Fields{"username": username})
 
+	// Network clients prove who they are with the password, which users without one therefore lack there
+	var password string
+	if len(cmd.Args) == 2 {
+		password = cmd.Args[1]
+	}
+	if session.Authenticate && password == "" {
+		sm.logger.Warn(ctx, "User select without password refused", log.Fields{"username": username})
+		return nil, fmt.Errorf("password required to select user '%s'", username)
+	}
+	if password != "" {
+		authenticated, err := sm.dataManager.UserManager.UserAuthenticate(model.UserInfo{Username: username, PasswordHash: []byte(password)})
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to authenticate user", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to authenticate user: %w", err)
+		}
+		if !authenticated {
+			sm.logger.Warn(ctx, "Wrong password for user select", log.Fields{"username": username})
+			return nil, fmt.Errorf("wrong password for user '%s'", username)
+		}
+	}
+
 	users, err := sm.dataManager.UserManager.UserGet(model.UserInfo{Username: username}, model.UserFilter{Username: true})
 	if err != nil {
 		sm.logger.Error(ctx, "Failed to get user", log.Fields{"error": err})
@@ -155,6 +176,66 @@ func handleUserSelect(sm *SessionManager, session *model.Session, cmd model.Comm
 	session.User = user
 	sm.logger.Debug(ctx, "User selected and set in session", log.Fields{"username": user.Username})
 
+	// The mindmap is loaded as the previous user sees it, so the new user selects it again unless it is theirs and
+	// nothing of it was hidden
+	if session.Mindmap != nil && (session.Mindmap.Owner != user.Username || session.Mindmap.Hidden > 0) {
+		session.Mindmap = nil
+	}
+
 	sm.logger.Info(ctx, "User selected successfully", log.Fields{"username": username})
-	return fmt.Sprintf("User '%s' selected successfully", username), nil
+	message := fmt.Sprintf("User '%s' selected successfully", username)
+
+	// Select the default mindmap of the user as the mindmap select command would, so it is audited like one
+	if user.DefaultMindmap != "" {
+		result, err := sm.commandRun(session, model.Command{Scope: "mindmap", Operation: "select", Args: []string{user.DefaultMindmap}})
+		if err != nil {
+			sm.logger.Warn(ctx, "Failed to select default mindmap", log.Fields{"error": err, "mindmap": user.DefaultMindmap})
+			return fmt.Sprintf("%s\nDefault mindmap '%s' not selected: %v", message, user.DefaultMindmap, err), nil
+		}
+		message += fmt.Sprintf("\nMindmap %v selected", result)
+	}
+	return message, nil
+}
+
+// handleUserDefault handles the user default command, setting or clearing the mindmap selected along with the
+// current user
+func handleUserDefault(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling user default command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) > 1 {
+		sm.logger.Error(ctx, "Invalid number of arguments for user default", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("user default command accepts at most 1 argument: [mindmap]")
+	}
+
+	name := ""
+	if len(cmd.Args) == 1 {
+		mindmaps, err := sm.dataManager.MindmapManager.MindmapGet(session.User, model.MindmapInfo{Name: cmd.Args[0]}, model.MindmapFilter{Name: true})
+		if err != nil {
+			sm.logger.Error(ctx, "Failed to get mindmap", log.Fields{"error": err, "mindmapName": cmd.Args[0]})
+			return nil, fmt.Errorf("failed to get mindmap: %w", err)
+		}
+		if len(mindmaps) == 0 {
+			sm.logger.Warn(ctx, "Mindmap not found", log.Fields{"mindmapName": cmd.Args[0]})
+			return nil, fmt.Errorf("mindmap not found: %s", cmd.Args[0])
+		}
+		name = mindmaps[0].Name
+	}
+
+	// The inbox is a node of the default mindmap, another mindmap takes quick adds under its top-level Inbox node
+	filter := model.UserFilter{DefaultMindmap: true, Inbox: !names.Equal(session.User.DefaultMindmap, name, false)}
+	err := sm.dataManager.UserManager.UserUpdate(session.User, model.UserInfo{DefaultMindmap: name}, filter)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to update default mindmap", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to update default mindmap: %w", err)
+	}
+	session.User.DefaultMindmap = name
+	if filter.Inbox {
+		session.User.Inbox = 0
+	}
+
+	if name == "" {
+		return "Default mindmap cleared", nil
+	}
+	return fmt.Sprintf("Default mindmap set to '%s', it is selected along with user '%s'", name, session.User.Username), nil
 }
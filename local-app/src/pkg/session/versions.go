@@ -0,0 +1,108 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// versionMiddleware counts the node commands changing the selected mindmap, storing a version of it every
+// version_interval of them, see the configuration
+func (sm *SessionManager) versionMiddleware(next CommandHandler) CommandHandler {
+	return func(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+		result, err := next(sm, session, cmd)
+		if err == nil && isMutatingCommand(cmd) && session.User != nil && session.Mindmap != nil {
+			sm.dataManager.MindmapVersionCount(session.User, session.Mindmap)
+		}
+		return result, err
+	}
+}
+
+// handleMindmapCheckpoint handles the mindmap checkpoint command, which stores the current mindmap as a version
+// named by a label, the words of the arguments
+func handleMindmapCheckpoint(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap checkpoint command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) == 0 {
+		sm.logger.Error(ctx, "Invalid number of arguments for mindmap checkpoint", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("mindmap checkpoint command requires a label: <label>")
+	}
+
+	version, err := sm.dataManager.MindmapCheckpoint(session.User, session.Mindmap, strings.Join(cmd.Args, " "))
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to store checkpoint", log.Fields{"error": err, "mindmapID": session.Mindmap.ID})
+		return nil, fmt.Errorf("failed to store checkpoint: %w", err)
+	}
+	return fmt.Sprintf("Checkpoint '%s' stored as version %d of mindmap '%s'", version.Label, version.Version, session.Mindmap.Name), nil
+}
+
+// handleMindmapHistory handles the mindmap history command, which lists the stored versions of the current mindmap
+func handleMindmapHistory(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap history command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) != 0 {
+		sm.logger.Error(ctx, "Invalid number of arguments for mindmap history", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("mindmap history command takes no arguments")
+	}
+
+	versions, err := sm.dataManager.VersionManager.VersionGet(session.Mindmap)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return fmt.Sprintf("Mindmap '%s' has no stored versions", session.Mindmap.Name), nil
+	}
+	lines := []string{fmt.Sprintf("Versions of mindmap '%s', newest first:", session.Mindmap.Name)}
+	for _, version := range versions {
+		line := fmt.Sprintf("  %d  %s  %d nodes", version.Version, version.Created.Format(time.DateTime), version.NodeCount)
+		switch {
+		case version.Auto && version.Label != "":
+			line += "  (" + version.Label + ")"
+		case version.Auto:
+			line += "  (automatic)"
+		default:
+			line += "  " + version.Label
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// handleMindmapRevert handles the mindmap revert command, which brings the current mindmap back to a stored version
+func handleMindmapRevert(sm *SessionManager, session *model.Session, cmd model.Command) (interface{}, error) {
+	ctx := context.Background()
+	sm.logger.Info(ctx, "Handling mindmap revert command", log.Fields{"args": cmd.Args})
+
+	if len(cmd.Args) != 1 {
+		sm.logger.Error(ctx, "Invalid number of arguments for mindmap revert", log.Fields{"argCount": len(cmd.Args)})
+		return nil, errors.New("mindmap revert command requires 1 argument: <version>")
+	}
+	version, err := strconv.Atoi(cmd.Args[0])
+	if err != nil || version < 1 {
+		sm.logger.Error(ctx, "Invalid version", log.Fields{"version": cmd.Args[0]})
+		return nil, fmt.Errorf("invalid version: %s. Must be a version number shown by mindmap history", cmd.Args[0])
+	}
+
+	reverted, merge, warnings, err := sm.dataManager.MindmapRevert(session.User, session.Mindmap, version)
+	if err != nil {
+		sm.logger.Error(ctx, "Failed to revert mindmap", log.Fields{"error": err, "mindmapID": session.Mindmap.ID, "version": version})
+		return nil, fmt.Errorf("failed to revert mindmap: %w", err)
+	}
+	session.Mindmap = reverted
+
+	sm.logger.Info(ctx, "Mindmap reverted successfully", log.Fields{"mindmapID": reverted.ID, "version": version, "merge": merge})
+	result := fmt.Sprintf("Mindmap '%s' reverted to version %d: %d nodes added, %d updated, %d moved, %d deleted",
+		reverted.Name, version, merge.Added, merge.Updated, merge.Moved, merge.Deleted)
+	for _, warning := range warnings {
+		result += "\nWarning: " + warning
+	}
+	return result, nil
+}
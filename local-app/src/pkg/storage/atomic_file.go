@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile is a file that is written under a temporary name in the target directory and only
+// renamed into place once all data is written and synced, so readers never see a partially written file.
+// An interrupted write leaves the previous file, if any, untouched.
+type AtomicFile struct {
+	file *os.File
+	path string
+	done bool
+}
+
+// CreateAtomic creates the directory of path if needed and opens a temporary file next to path for writing
+func CreateAtomic(path string, perm os.FileMode) (*AtomicFile, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	return &AtomicFile{file: file, path: path}, nil
+}
+
+// Write writes data to the temporary file
+func (f *AtomicFile) Write(p []byte) (int, error) {
+	return f.file.Write(p)
+}
+
+// Close syncs the temporary file and renames it to the target path. On failure the temporary file is removed.
+func (f *AtomicFile) Close() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+
+	if err := f.file.Sync(); err != nil {
+		f.file.Close()
+		os.Remove(f.file.Name())
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := f.file.Close(); err != nil {
+		os.Remove(f.file.Name())
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	if err := os.Rename(f.file.Name(), f.path); err != nil {
+		os.Remove(f.file.Name())
+		return fmt.Errorf("failed to rename file into place: %w", err)
+	}
+
+	// Sync the directory so the rename itself survives a crash; not supported on all platforms
+	if dir, err := os.Open(filepath.Dir(f.path)); err == nil {
+		if err := dir.Sync(); err != nil && !errors.Is(err, os.ErrInvalid) {
+			dir.Close()
+			return fmt.Errorf("failed to sync directory: %w", err)
+		}
+		dir.Close()
+	}
+	return nil
+}
+
+// Abort discards the temporary file, leaving the target path untouched
+func (f *AtomicFile) Abort() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+
+	f.file.Close()
+	return os.Remove(f.file.Name())
+}
+
+// WriteFileAtomic writes data to the named file atomically, replacing any existing file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := CreateAtomic(path, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Abort()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return f.Close()
+}
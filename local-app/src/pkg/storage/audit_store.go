@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// AuditStore defines the interface for audit log storage operations.
+// The audit log is append-only, so no update or delete operations are provided.
+type AuditStore interface {
+	AuditAdd(entry model.AuditEntry) (int, error)
+	AuditGet(auditInfo model.AuditEntry, auditFilter model.AuditFilter, limit int) ([]*model.AuditEntry, error)
+	AuditSearch(query string, username string, limit int) ([]*model.AuditEntry, error)
+}
+
+// AuditStorage implements the AuditStore interface.
+type AuditStorage struct {
+	storage *Storage
+	logger  *log.Logger
+}
+
+// NewAuditStorage creates a new AuditStorage instance.
+func NewAuditStorage(storage *Storage) *AuditStorage {
+	return &AuditStorage{
+		storage: storage,
+		logger:  storage.logger,
+	}
+}
+
+// AuditAdd appends a new entry to the audit log.
+func (s *AuditStorage) AuditAdd(entry model.AuditEntry) (int, error) {
+	s.logger.Debug(context.Background(), "Adding audit entry", log.Fields{"scope": entry.Scope, "operation": entry.Operation})
+
+	db := s.storage.GetDatabase()
+	result, err := db.Exec(
+		"INSERT INTO audit_log (timestamp, session_id, username, scope, operation, mindmap_id, target, result, error, detail) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.Timestamp, entry.SessionID, entry.Username, entry.Scope, entry.Operation, entry.MindmapID, entry.Target, entry.Result, entry.Error, entry.Detail,
+	)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to add audit entry", log.Fields{"error": err})
+		return 0, fmt.Errorf("failed to add audit entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to get last insert ID", log.Fields{"error": err})
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// AuditGet retrieves the most recent audit entries matching the provided info and filter, oldest first.
+func (s *AuditStorage) AuditGet(auditInfo model.AuditEntry, auditFilter model.AuditFilter, limit int) ([]*model.AuditEntry, error) {
+	s.logger.Info(context.Background(), "Retrieving audit entries", log.Fields{"filter": auditFilter, "limit": limit})
+
+	query := "SELECT id, timestamp, session_id, username, scope, operation, mindmap_id, target, result, error, detail FROM audit_log WHERE 1=1"
+	var args []interface{}
+
+	if auditFilter.Username {
+		query += " AND username = ?"
+		args = append(args, auditInfo.Username)
+	}
+	if auditFilter.Scope {
+		query += " AND scope = ?"
+		args = append(args, auditInfo.Scope)
+	}
+	if auditFilter.Operation {
+		query += " AND operation = ?"
+		args = append(args, auditInfo.Operation)
+	}
+	if auditFilter.MindmapID {
+		query += " AND mindmap_id = ?"
+		args = append(args, auditInfo.MindmapID)
+	}
+	if auditFilter.Result {
+		query += " AND result = ?"
+		args = append(args, auditInfo.Result)
+	}
+	if auditFilter.Since {
+		query += " AND timestamp >= ?"
+		args = append(args, auditInfo.Timestamp)
+	}
+
+	return s.auditQuery(query, args, limit)
+}
+
+// AuditSearch retrieves the most recent audit entries containing the query in any of their text fields, oldest first.
+// The entries are those of a user if username is not empty.
+func (s *AuditStorage) AuditSearch(query string, username string, limit int) ([]*model.AuditEntry, error) {
+	s.logger.Info(context.Background(), "Searching audit entries", log.Fields{"query": query, "username": username, "limit": limit})
+
+	pattern := "%" + query + "%"
+	sqlQuery := "SELECT id, timestamp, session_id, username, scope, operation, mindmap_id, target, result, error, detail FROM audit_log" +
+		" WHERE (username LIKE ? OR scope LIKE ? OR operation LIKE ? OR target LIKE ? OR result LIKE ? OR error LIKE ? OR detail LIKE ?)"
+	args := []interface{}{pattern, pattern, pattern, pattern, pattern, pattern, pattern}
+	if username != "" {
+		sqlQuery += " AND username = ?"
+		args = append(args, username)
+	}
+
+	return s.auditQuery(sqlQuery, args, limit)
+}
+
+// auditQuery runs the given audit log query keeping only the last limit rows, returned in chronological order.
+func (s *AuditStorage) auditQuery(query string, args []interface{}, limit int) ([]*model.AuditEntry, error) {
+	db := s.storage.GetDatabase()
+
+	query += " ORDER BY id DESC"
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query audit entries", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		var e model.AuditEntry
+		err := rows.Scan(&e.ID, &e.Timestamp, &e.SessionID, &e.Username, &e.Scope, &e.Operation, &e.MindmapID, &e.Target, &e.Result, &e.Error, &e.Detail)
+		if err != nil {
+			s.logger.Error(context.Background(), "Failed to scan audit row", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to scan audit row: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Error iterating audit rows", log.Fields{"error": err})
+		return nil, fmt.Errorf("error iterating audit rows: %w", err)
+	}
+
+	// Reverse to chronological order
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	s.logger.Info(context.Background(), "Audit entries retrieved successfully", log.Fields{"count": len(entries)})
+	return entries, nil
+}
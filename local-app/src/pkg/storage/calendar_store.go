@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// CalendarStore defines the interface for calendar feed storage operations.
+type CalendarStore interface {
+	CalendarSet(feed model.CalendarFeed) error
+	CalendarGet(username string) ([]*model.CalendarFeed, error)
+	CalendarFind(token string) (*model.CalendarFeed, error)
+	CalendarDelete(username string, mindmapID int) (bool, error)
+	CalendarDeleteUser(username string) error
+	CalendarDeleteMindmap(mindmapID int) error
+}
+
+// CalendarStorage implements the CalendarStore interface.
+type CalendarStorage struct {
+	storage *Storage
+	logger  *log.Logger
+}
+
+// NewCalendarStorage creates a new CalendarStorage instance.
+func NewCalendarStorage(storage *Storage) *CalendarStorage {
+	return &CalendarStorage{
+		storage: storage,
+		logger:  storage.logger,
+	}
+}
+
+// CalendarSet adds a calendar feed, replacing the feed of the same user and mindmap and so its token.
+func (s *CalendarStorage) CalendarSet(feed model.CalendarFeed) error {
+	s.logger.Debug(context.Background(), "Setting calendar feed", log.Fields{"username": feed.Username, "mindmapID": feed.MindmapID})
+
+	db := s.storage.GetDatabase()
+	_, err := db.Exec(
+		"INSERT OR REPLACE INTO calendar_feeds (token, username, mindmap_id, created) VALUES (?, ?, ?, ?)",
+		feed.Token, feed.Username, feed.MindmapID, feed.Created,
+	)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to set calendar feed", log.Fields{"error": err, "username": feed.Username})
+		return fmt.Errorf("failed to set calendar feed: %w", err)
+	}
+	return nil
+}
+
+// CalendarGet retrieves the calendar feeds of a user, the feed of all own mindmaps first.
+func (s *CalendarStorage) CalendarGet(username string) ([]*model.CalendarFeed, error) {
+	s.logger.Debug(context.Background(), "Retrieving calendar feeds", log.Fields{"username": username})
+
+	db := s.storage.GetDatabase()
+	rows, err := db.Query("SELECT token, username, mindmap_id, created FROM calendar_feeds WHERE username = ? ORDER BY mindmap_id", username)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query calendar feeds", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to query calendar feeds: %w", err)
+	}
+	defer rows.Close()
+
+	var feeds []*model.CalendarFeed
+	for rows.Next() {
+		var f model.CalendarFeed
+		if err := rows.Scan(&f.Token, &f.Username, &f.MindmapID, &f.Created); err != nil {
+			s.logger.Error(context.Background(), "Failed to scan calendar feed row", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to scan calendar feed row: %w", err)
+		}
+		feeds = append(feeds, &f)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Error iterating calendar feed rows", log.Fields{"error": err})
+		return nil, fmt.Errorf("error iterating calendar feed rows: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// CalendarFind retrieves the calendar feed of a token, nil if there is none.
+func (s *CalendarStorage) CalendarFind(token string) (*model.CalendarFeed, error) {
+	db := s.storage.GetDatabase()
+	var f model.CalendarFeed
+	err := db.QueryRow("SELECT token, username, mindmap_id, created FROM calendar_feeds WHERE token = ?", token).
+		Scan(&f.Token, &f.Username, &f.MindmapID, &f.Created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query calendar feed", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to query calendar feed: %w", err)
+	}
+	return &f, nil
+}
+
+// CalendarDelete removes the calendar feed of a user and mindmap, reporting whether there was one.
+func (s *CalendarStorage) CalendarDelete(username string, mindmapID int) (bool, error) {
+	s.logger.Debug(context.Background(), "Deleting calendar feed", log.Fields{"username": username, "mindmapID": mindmapID})
+
+	db := s.storage.GetDatabase()
+	result, err := db.Exec("DELETE FROM calendar_feeds WHERE username = ? AND mindmap_id = ?", username, mindmapID)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to delete calendar feed", log.Fields{"error": err, "username": username})
+		return false, fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// CalendarDeleteUser removes the calendar feeds of a user.
+func (s *CalendarStorage) CalendarDeleteUser(username string) error {
+	s.logger.Debug(context.Background(), "Deleting calendar feeds of user", log.Fields{"username": username})
+
+	db := s.storage.GetDatabase()
+	if _, err := db.Exec("DELETE FROM calendar_feeds WHERE username = ?", username); err != nil {
+		s.logger.Error(context.Background(), "Failed to delete calendar feeds", log.Fields{"error": err, "username": username})
+		return fmt.Errorf("failed to delete calendar feeds: %w", err)
+	}
+	return nil
+}
+
+// CalendarDeleteMindmap removes the calendar feed of a mindmap.
+func (s *CalendarStorage) CalendarDeleteMindmap(mindmapID int) error {
+	s.logger.Debug(context.Background(), "Deleting calendar feed of mindmap", log.Fields{"mindmapID": mindmapID})
+
+	db := s.storage.GetDatabase()
+	if _, err := db.Exec("DELETE FROM calendar_feeds WHERE mindmap_id = ?", mindmapID); err != nil {
+		s.logger.Error(context.Background(), "Failed to delete calendar feed", log.Fields{"error": err, "mindmapID": mindmapID})
+		return fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+	return nil
+}
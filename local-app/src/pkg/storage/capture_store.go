@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// CaptureStore defines the interface for email capture account storage operations.
+type CaptureStore interface {
+	CaptureSet(account model.CaptureAccount) error
+	CaptureUpdate(account model.CaptureAccount) error
+	CaptureGet(username string) ([]*model.CaptureAccount, error)
+	CaptureDelete(username string) error
+	CaptureDeleteMindmap(mindmapID int) error
+}
+
+// CaptureStorage implements the CaptureStore interface.
+type CaptureStorage struct {
+	storage *Storage
+	logger  *log.Logger
+}
+
+// NewCaptureStorage creates a new CaptureStorage instance.
+func NewCaptureStorage(storage *Storage) *CaptureStorage {
+	return &CaptureStorage{
+		storage: storage,
+		logger:  storage.logger,
+	}
+}
+
+// CaptureSet adds or replaces the capture account of a user, including its polling state.
+func (s *CaptureStorage) CaptureSet(account model.CaptureAccount) error {
+	s.logger.Debug(context.Background(), "Setting capture account", log.Fields{"username": account.Username, "server": account.Server})
+
+	db := s.storage.GetDatabase()
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO capture_accounts (username, server, login, password, mailbox, mindmap_id, uid_validity, last_uid, captured, last_poll, last_error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		account.Username, account.Server, account.Login, account.Password, account.Mailbox, account.MindmapID,
+		account.UIDValidity, account.LastUID, account.Captured, account.LastPoll, account.LastError,
+	)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to set capture account", log.Fields{"error": err, "username": account.Username})
+		return fmt.Errorf("failed to set capture account: %w", err)
+	}
+	return nil
+}
+
+// CaptureUpdate updates the polling state of a capture account, unless its mailbox has been changed since.
+func (s *CaptureStorage) CaptureUpdate(account model.CaptureAccount) error {
+	s.logger.Debug(context.Background(), "Updating capture account state", log.Fields{"username": account.Username, "lastUID": account.LastUID})
+
+	db := s.storage.GetDatabase()
+	_, err := db.Exec(
+		`UPDATE capture_accounts SET uid_validity = ?, last_uid = ?, captured = ?, last_poll = ?, last_error = ?
+		WHERE username = ? AND server = ? AND login = ? AND mailbox = ?`,
+		account.UIDValidity, account.LastUID, account.Captured, account.LastPoll, account.LastError,
+		account.Username, account.Server, account.Login, account.Mailbox,
+	)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to update capture account", log.Fields{"error": err, "username": account.Username})
+		return fmt.Errorf("failed to update capture account: %w", err)
+	}
+	return nil
+}
+
+// CaptureGet retrieves the capture account of a user, or the accounts of all users if username is empty.
+func (s *CaptureStorage) CaptureGet(username string) ([]*model.CaptureAccount, error) {
+	s.logger.Debug(context.Background(), "Retrieving capture accounts", log.Fields{"username": username})
+
+	query := `SELECT username, server, login, password, mailbox, mindmap_id, uid_validity, last_uid, captured, last_poll, last_error
+		FROM capture_accounts`
+	var args []interface{}
+	if username != "" {
+		query += " WHERE username = ?"
+		args = append(args, username)
+	}
+
+	db := s.storage.GetDatabase()
+	rows, err := db.Query(query+" ORDER BY username", args...)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query capture accounts", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to query capture accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*model.CaptureAccount
+	for rows.Next() {
+		var a model.CaptureAccount
+		if err := rows.Scan(&a.Username, &a.Server, &a.Login, &a.Password, &a.Mailbox, &a.MindmapID,
+			&a.UIDValidity, &a.LastUID, &a.Captured, &a.LastPoll, &a.LastError); err != nil {
+			s.logger.Error(context.Background(), "Failed to scan capture account row", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to scan capture account row: %w", err)
+		}
+		accounts = append(accounts, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Error iterating capture account rows", log.Fields{"error": err})
+		return nil, fmt.Errorf("error iterating capture account rows: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// CaptureDelete removes the capture account of a user.
+func (s *CaptureStorage) CaptureDelete(username string) error {
+	s.logger.Debug(context.Background(), "Deleting capture account", log.Fields{"username": username})
+
+	db := s.storage.GetDatabase()
+	if _, err := db.Exec("DELETE FROM capture_accounts WHERE username = ?", username); err != nil {
+		s.logger.Error(context.Background(), "Failed to delete capture account", log.Fields{"error": err, "username": username})
+		return fmt.Errorf("failed to delete capture account: %w", err)
+	}
+	return nil
+}
+
+// CaptureDeleteMindmap removes the capture accounts capturing into a mindmap.
+func (s *CaptureStorage) CaptureDeleteMindmap(mindmapID int) error {
+	s.logger.Debug(context.Background(), "Deleting capture accounts of mindmap", log.Fields{"mindmapID": mindmapID})
+
+	db := s.storage.GetDatabase()
+	if _, err := db.Exec("DELETE FROM capture_accounts WHERE mindmap_id = ?", mindmapID); err != nil {
+		s.logger.Error(context.Background(), "Failed to delete capture accounts", log.Fields{"error": err, "mindmapID": mindmapID})
+		return fmt.Errorf("failed to delete capture accounts: %w", err)
+	}
+	return nil
+}
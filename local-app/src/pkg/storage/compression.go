@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// CompressionFromPath returns the compression indicated by the file name suffix, if any, before the encryption suffix
+func CompressionFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(TrimEncryptionExt(path))) {
+	case ".gz":
+		return model.CompressionGzip
+	case ".zst":
+		return model.CompressionZstd
+	default:
+		return model.CompressionNone
+	}
+}
+
+// TrimCompressionExt returns the path without its encryption and compression suffixes, exposing the format extension
+func TrimCompressionExt(path string) string {
+	path = TrimEncryptionExt(path)
+	if CompressionFromPath(path) == model.CompressionNone {
+		return path
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+// compressWriter wraps w in a streaming compressor. Closing the returned writer flushes the compressor
+// but does not close w.
+func compressWriter(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case model.CompressionNone:
+		return nopWriteCloser{w}, nil
+	case model.CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case model.CompressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+}
+
+// decompressReader wraps r in a streaming decompressor. Closing the returned reader releases the decompressor
+// but does not close r.
+func decompressReader(r io.Reader, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case model.CompressionNone:
+		return io.NopCloser(r), nil
+	case model.CompressionGzip:
+		return gzip.NewReader(r)
+	case model.CompressionZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+}
+
+// nopWriteCloser adds a no-op Close method to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing
+func (nopWriteCloser) Close() error {
+	return nil
+}
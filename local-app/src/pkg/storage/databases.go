@@ -6,6 +6,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync/atomic"
 
 	"mindnoscape/local-app/src/pkg/log"
 )
@@ -20,17 +21,21 @@ const (
 
 // Database interface defines common database operations
 type Database interface {
-	Open(dataSourceName string) error
+	Open(dataSourceName string, readOnly bool) error
 	Close() error
-	Begin() error
-	Commit() error
-	Rollback() error
+	Batch(fn func() error) error // Runs fn in a transaction, see BaseDatabase.Batch
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(query string, args ...interface{}) *sql.Row
 	InitSchema() error
 	CreateMindmapTables(mindmapID int) error
 	DropMindmapTables(mindmapID int) error
+	IntegrityCheck() ([]string, error) // Returns the problems found in the stored data, none if it is sound
+	Vacuum() (int64, error)            // Compacts the stored data, returning the bytes reclaimed if known
+	Snapshot(path string) error        // Writes a consistent copy of the stored data to a new file at path
+	SchemaVersion() (int, error)       // Returns the version of the stored schema, 0 if never set
+	SetSchemaVersion(version int) error
+	SearchIndexed(mindmapID int) (bool, error) // Reports whether the nodes of a mindmap can be searched by NodeSearch
 }
 
 // NewDatabase creates a new Database instance based on the specified driver
@@ -48,71 +53,70 @@ func NewDatabase(driver DBDriver, logger *log.Logger) (Database, error) {
 // BaseDatabase provides a base implementation of some Database methods
 type BaseDatabase struct {
 	db     *sql.DB
-	tx     *sql.Tx
+	tx     atomic.Pointer[sql.Tx] // The transaction of the running batch, nil outside of batches
 	logger *log.Logger
 }
 
-// Begin starts a new transaction
-func (b *BaseDatabase) Begin() error {
-	tx, err := b.db.Begin()
-	if err != nil {
-		b.logger.Error(context.Background(), "Failed to begin transaction", log.Fields{"error": err})
-		return err
+// Batch runs fn in a single transaction, the statements run by fn and the nested batches it runs joining it. The
+// transaction is committed if fn succeeds and rolled back otherwise. It only lives for the call of fn, and is shared
+// by all statements run meanwhile, so batches are only run by the command executor of the session manager and no
+// other goroutine writes alongside it: background jobs and routines hand their database work to the executor, and
+// the event handlers writing to the database, such as the cascades of deleting a user or a mindmap and the rename
+// of a mindmap with its root node, are published with PublishAndWait, running while the executor waits for them.
+func (b *BaseDatabase) Batch(fn func() error) error {
+	if b.tx.Load() != nil {
+		return fn()
 	}
-	b.tx = tx
-	b.logger.Info(context.Background(), "Transaction started", nil)
-	return nil
-}
 
-// Commit commits the current transaction
-func (b *BaseDatabase) Commit() error {
-	if b.tx == nil {
-		return fmt.Errorf("no active transaction")
-		b.logger.Error(context.Background(), "No active transaction to commit", nil)
-	}
-	err := b.tx.Commit()
+	ctx := context.Background()
+	tx, err := b.db.Begin()
 	if err != nil {
-		b.logger.Error(context.Background(), "Failed to commit transaction", log.Fields{"error": err})
-		return err
+		b.logger.Error(ctx, "Failed to begin transaction", log.Fields{"error": err})
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	b.tx = nil
-	b.logger.Info(context.Background(), "Transaction committed", nil)
-	return nil
-}
+	b.tx.Store(tx)
+	err = fn()
+	b.tx.Store(nil)
 
-// Rollback rolls back the current transaction
-func (b *BaseDatabase) Rollback() error {
-	if b.tx == nil {
-		b.logger.Error(context.Background(), "No active transaction to rollback", nil)
-		return fmt.Errorf("no active transaction")
-	}
-	err := b.tx.Rollback()
 	if err != nil {
-		b.logger.Error(context.Background(), "Failed to rollback transaction", log.Fields{"error": err})
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			b.logger.Error(ctx, "Failed to rollback transaction", log.Fields{"error": rollbackErr})
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		b.logger.Info(ctx, "Transaction rolled back", nil)
 		return err
 	}
-	b.tx = nil
-	b.logger.Info(context.Background(), "Transaction rolled back", nil)
+	if err := tx.Commit(); err != nil {
+		b.logger.Error(ctx, "Failed to commit transaction", log.Fields{"error": err})
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	b.logger.Info(ctx, "Transaction committed", nil)
 	return nil
 }
 
-// Exec executes a query without returning any rows
+// Exec executes a query without returning any rows, within a batch as part of its transaction
 func (b *BaseDatabase) Exec(query string, args ...interface{}) (sql.Result, error) {
 	b.logger.Debug(context.Background(), "Executing query", log.Fields{"query": query, "args": args})
-	if b.tx != nil {
-		return b.tx.Exec(query, args...)
+	if tx := b.tx.Load(); tx != nil {
+		return tx.Exec(query, args...)
 	}
 	return b.db.Exec(query, args...)
 }
 
-// Query executes a query that returns rows
+// Query executes a query that returns rows, within a batch it sees the changes of the batch
 func (b *BaseDatabase) Query(query string, args ...interface{}) (*sql.Rows, error) {
 	b.logger.Debug(context.Background(), "Querying", log.Fields{"query": query, "args": args})
+	if tx := b.tx.Load(); tx != nil {
+		return tx.Query(query, args...)
+	}
 	return b.db.Query(query, args...)
 }
 
-// QueryRow executes a query that is expected to return at most one row
+// QueryRow executes a query that is expected to return at most one row, within a batch it sees the changes of the batch
 func (b *BaseDatabase) QueryRow(query string, args ...interface{}) *sql.Row {
+	if tx := b.tx.Load(); tx != nil {
+		return tx.QueryRow(query, args...)
+	}
 	return b.db.QueryRow(query, args...)
 }
 
@@ -124,8 +128,11 @@ func (b *BaseDatabase) InitSchema() error {
 		CREATE TABLE IF NOT EXISTS users (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			username TEXT UNIQUE NOT NULL,
+			username_key TEXT,
 			password_hash BLOB NOT NULL,
 			active BOOLEAN NOT NULL DEFAULT 1,
+			default_mindmap TEXT NOT NULL DEFAULT '',
+			inbox INTEGER NOT NULL DEFAULT 0,
 			created DATETIME NOT NULL,
 			updated DATETIME NOT NULL
 		);
@@ -133,13 +140,87 @@ func (b *BaseDatabase) InitSchema() error {
 		CREATE TABLE IF NOT EXISTS mindmaps (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			mindmap_name TEXT NOT NULL,
+			mindmap_name_key TEXT,
 			owner TEXT NOT NULL,
 			is_public BOOLEAN NOT NULL DEFAULT 0,
+			sort_spec TEXT NOT NULL DEFAULT '',
+			revision INTEGER NOT NULL DEFAULT 0,
 			created DATETIME NOT NULL,
 			updated DATETIME NOT NULL,
 			FOREIGN KEY (owner) REFERENCES users(username),
 			UNIQUE (mindmap_name, owner)
 		);
+
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp DATETIME NOT NULL,
+			session_id TEXT NOT NULL,
+			username TEXT NOT NULL,
+			scope TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			mindmap_id INTEGER NOT NULL DEFAULT 0,
+			target TEXT NOT NULL,
+			result TEXT NOT NULL,
+			error TEXT NOT NULL,
+			detail TEXT NOT NULL DEFAULT ''
+		);
+
+		CREATE TABLE IF NOT EXISTS command_usage (
+			scope TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			count INTEGER NOT NULL DEFAULT 0,
+			errors INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (scope, operation)
+		);
+
+		CREATE TABLE IF NOT EXISTS node_links (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			source_mindmap_id INTEGER NOT NULL,
+			source_id INTEGER NOT NULL,
+			target_mindmap_id INTEGER NOT NULL,
+			target_id INTEGER NOT NULL,
+			link_type TEXT NOT NULL,
+			created DATETIME NOT NULL,
+			UNIQUE (source_mindmap_id, source_id, target_mindmap_id, target_id, link_type)
+		);
+
+		CREATE TABLE IF NOT EXISTS capture_accounts (
+			username TEXT PRIMARY KEY,
+			server TEXT NOT NULL,
+			login TEXT NOT NULL,
+			password TEXT NOT NULL,
+			mailbox TEXT NOT NULL,
+			mindmap_id INTEGER NOT NULL,
+			uid_validity INTEGER NOT NULL DEFAULT 0,
+			last_uid INTEGER NOT NULL DEFAULT 0,
+			captured INTEGER NOT NULL DEFAULT 0,
+			last_poll DATETIME NOT NULL,
+			last_error TEXT NOT NULL DEFAULT ''
+		);
+
+		CREATE TABLE IF NOT EXISTS calendar_feeds (
+			token TEXT PRIMARY KEY,
+			username TEXT NOT NULL,
+			mindmap_id INTEGER NOT NULL DEFAULT 0,
+			created DATETIME NOT NULL,
+			UNIQUE (username, mindmap_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS mindmap_versions (
+			mindmap_id INTEGER NOT NULL,
+			version INTEGER NOT NULL,
+			label TEXT NOT NULL DEFAULT '',
+			auto BOOLEAN NOT NULL DEFAULT 0,
+			node_count INTEGER NOT NULL,
+			data BLOB NOT NULL,
+			created DATETIME NOT NULL,
+			PRIMARY KEY (mindmap_id, version)
+		);
+
+		CREATE TABLE IF NOT EXISTS storage_settings (
+			setting TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
 	`)
 	if err != nil {
 		b.logger.Error(context.Background(), "Failed to create tables", log.Fields{"error": err})
@@ -170,7 +251,26 @@ func (b *BaseDatabase) CreateMindmapTables(mindmapID int) error {
             value TEXT NOT NULL,
             FOREIGN KEY (node_id) REFERENCES nodes_%d(id)
         );
-    `, mindmapID, mindmapID, mindmapID)
+        CREATE TABLE IF NOT EXISTS node_tags_%d (
+            node_id INTEGER NOT NULL,
+            tag TEXT NOT NULL,
+            PRIMARY KEY (node_id, tag),
+            FOREIGN KEY (node_id) REFERENCES nodes_%d(id)
+        );
+        CREATE INDEX IF NOT EXISTS node_tags_%d_tag ON node_tags_%d (tag);
+        CREATE TABLE IF NOT EXISTS node_attachments_%d (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            node_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            url TEXT NOT NULL,
+            media_type TEXT NOT NULL,
+            size INTEGER NOT NULL,
+            data BLOB,
+            created DATETIME NOT NULL,
+            UNIQUE (node_id, name),
+            FOREIGN KEY (node_id) REFERENCES nodes_%d(id)
+        );
+    `, mindmapID, mindmapID, mindmapID, mindmapID, mindmapID, mindmapID, mindmapID, mindmapID, mindmapID)
 
 	_, err := b.Exec(query)
 	if err != nil {
@@ -186,9 +286,11 @@ func (b *BaseDatabase) DropMindmapTables(mindmapID int) error {
 	b.logger.Info(context.Background(), "Dropping mindmap tables", log.Fields{"mindmapID": mindmapID})
 
 	_, err := b.Exec(fmt.Sprintf(`
-		DROP TABLE IF EXISTS nodes_%d;
+		DROP TABLE IF EXISTS node_attachments_%d;
+		DROP TABLE IF EXISTS node_tags_%d;
 		DROP TABLE IF EXISTS node_content_%d;
-	`, mindmapID, mindmapID))
+		DROP TABLE IF EXISTS nodes_%d;
+	`, mindmapID, mindmapID, mindmapID, mindmapID))
 
 	if err != nil {
 		b.logger.Error(context.Background(), "Failed to drop mindmap tables", log.Fields{"error": err, "mindmapID": mindmapID})
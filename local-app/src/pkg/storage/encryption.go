@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Encrypted files hold the magic bytes, the key derivation iterations, the salt and the nonce followed by the
+// AES-256-GCM sealed data, the header being authenticated along with the data
+const (
+	EncryptionExt        = ".mnx"
+	encryptionMagic      = "MNX1"
+	encryptionIterations = 600000
+	encryptionSaltSize   = 16
+	encryptionKeySize    = 32
+	encryptionHeaderSize = len(encryptionMagic) + 4 + encryptionSaltSize
+)
+
+var (
+	// ErrPassphraseMissing is returned when an encrypted file is written or read without a passphrase
+	ErrPassphraseMissing = errors.New("encrypted files require a passphrase")
+	// ErrDecryptionFailed is returned when an encrypted file can't be opened with the given passphrase
+	ErrDecryptionFailed = errors.New("wrong passphrase, or the file is corrupted or was modified")
+)
+
+// IsEncryptedPath reports whether the file name suffix of a path marks an encrypted file
+func IsEncryptedPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), EncryptionExt)
+}
+
+// TrimEncryptionExt returns the path without its encryption suffix, exposing the compression and format extensions
+func TrimEncryptionExt(path string) string {
+	if !IsEncryptedPath(path) {
+		return path
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+// encryptWriter wraps w in a writer encrypting the data with a key derived from passphrase. AES-GCM seals the data
+// as a whole, so it is buffered and only encrypted and written by Close, which does not close w.
+func encryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	if passphrase == "" {
+		return nil, ErrPassphraseMissing
+	}
+	return &encryptingWriter{w: w, passphrase: passphrase}, nil
+}
+
+// encryptingWriter buffers the data to encrypt, see encryptWriter
+type encryptingWriter struct {
+	w          io.Writer
+	passphrase string
+	buf        bytes.Buffer
+}
+
+// Write buffers data to encrypt
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	return e.buf.Write(p)
+}
+
+// Close encrypts the buffered data and writes it with its header
+func (e *encryptingWriter) Close() error {
+	header := make([]byte, encryptionHeaderSize)
+	copy(header, encryptionMagic)
+	binary.BigEndian.PutUint32(header[len(encryptionMagic):], encryptionIterations)
+	salt := header[len(encryptionMagic)+4:]
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := encryptionCipher(e.passphrase, salt, encryptionIterations)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header = append(header, nonce...)
+
+	sealed := aead.Seal(nil, nonce, e.buf.Bytes(), header)
+	e.buf.Reset()
+	if _, err := e.w.Write(header); err != nil {
+		return err
+	}
+	_, err = e.w.Write(sealed)
+	return err
+}
+
+// decryptReader reads all of r and returns a reader of the data decrypted with a key derived from passphrase
+func decryptReader(r io.Reader, passphrase string) (io.ReadCloser, error) {
+	if passphrase == "" {
+		return nil, ErrPassphraseMissing
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < encryptionHeaderSize || string(data[:len(encryptionMagic)]) != encryptionMagic {
+		return nil, errors.New("not an encrypted mindmap file")
+	}
+
+	iterations := int(binary.BigEndian.Uint32(data[len(encryptionMagic):]))
+	salt := data[len(encryptionMagic)+4 : encryptionHeaderSize]
+	aead, err := encryptionCipher(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < encryptionHeaderSize+aead.NonceSize() {
+		return nil, ErrDecryptionFailed
+	}
+	header := data[:encryptionHeaderSize+aead.NonceSize()]
+	nonce := header[encryptionHeaderSize:]
+
+	plain, err := aead.Open(nil, nonce, data[len(header):], header)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return io.NopCloser(bytes.NewReader(plain)), nil
+}
+
+// encryptionCipher returns the AES-256-GCM cipher keyed by the passphrase and salt. The iterations are bounded so
+// that a crafted file can't stall the key derivation.
+func encryptionCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations < 1 || iterations > 10*encryptionIterations {
+		return nil, ErrDecryptionFailed
+	}
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(passphrase), salt, iterations, encryptionKeySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key of keyLen bytes from a password with PBKDF2-HMAC-SHA256 (RFC 8018)
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	key := make([]byte, 0, keyLen+sha256.Size)
+	u := make([]byte, sha256.Size)
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u = prf.Sum(u[:0])
+		t := bytes.Clone(u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
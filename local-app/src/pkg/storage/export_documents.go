@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// maxHeadingLevel is the deepest heading level of exported documents, deeper nodes share it
+const maxHeadingLevel = 9
+
+// outlineBlock is a paragraph of an exported outline document, a heading if level is above 0
+type outlineBlock struct {
+	level int
+	text  string
+}
+
+// documentOutline lays out a mindmap as a document outline: the root is the title, the other nodes are headings
+// of the level of their depth and their content fields become body paragraphs below their heading. Following the
+// display style, the root may instead be the first heading, with the other headings a level deeper, or not shown
+// at all, leaving the title empty.
+func documentOutline(mindmap *model.Mindmap, style model.DisplayStyle) (string, []outlineBlock) {
+	title, shift := mindmap.Name, 0
+	switch style.Root() {
+	case model.RootAsNode:
+		title, shift = "", 1
+	case model.RootHidden:
+		title = ""
+	}
+
+	var blocks []outlineBlock
+	for depth, node := range mindmap.Walk(nil, nil) {
+		if depth+shift > 0 {
+			blocks = append(blocks, outlineBlock{level: min(depth+shift, maxHeadingLevel), text: node.Name})
+		}
+		for _, key := range slices.Sorted(maps.Keys(node.Content)) {
+			blocks = append(blocks, outlineBlock{text: key + ": " + node.Content[key]})
+		}
+	}
+	return title, blocks
+}
+
+// xmlText escapes text for use in XML character data
+func xmlText(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// zipFile is a file of a zip based document package
+type zipFile struct {
+	name   string
+	data   string
+	stored bool // Stored without compression, required for the ODF mimetype file
+}
+
+// writeZip packs files in order into a zip archive
+func writeZip(files []zipFile) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	modified := time.Now()
+	for _, f := range files {
+		method := zip.Deflate
+		if f.stored {
+			method = zip.Store
+		}
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: f.name, Method: method, Modified: modified})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", f.name, err)
+		}
+		if _, err := w.Write([]byte(f.data)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func init() {
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "docx",
+		Description: "Word outline with headings by node depth and content fields as paragraphs",
+		Binary:      true,
+		Subtree:     true,
+		Encode:      encodeDocx,
+	})
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "odt",
+		Description: "OpenDocument outline with headings by node depth and content fields as paragraphs",
+		Binary:      true,
+		Subtree:     true,
+		Encode:      encodeODT,
+	})
+}
+
+// encodeDocx renders a mindmap as a Word document outline
+func encodeDocx(mindmap *model.Mindmap, style model.DisplayStyle) ([]byte, error) {
+	title, blocks := documentOutline(mindmap, style)
+
+	var body strings.Builder
+	if title != "" {
+		docxParagraph(&body, "Title", title)
+	}
+	for _, block := range blocks {
+		style := ""
+		if block.level > 0 {
+			style = fmt.Sprintf("Heading%d", block.level)
+		}
+		docxParagraph(&body, style, block.text)
+	}
+
+	var styles strings.Builder
+	styles.WriteString(`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>`)
+	styles.WriteString(`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:rPr><w:b/><w:sz w:val="48"/></w:rPr></w:style>`)
+	for level := 1; level <= maxHeadingLevel; level++ {
+		fmt.Fprintf(&styles, `<w:style w:type="paragraph" w:styleId="Heading%d"><w:name w:val="heading %d"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:outlineLvl w:val="%d"/></w:pPr><w:rPr><w:b/><w:sz w:val="%d"/></w:rPr></w:style>`,
+			level, level, level-1, max(36-2*level, 22))
+	}
+
+	const wordNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	return writeZip([]zipFile{
+		{name: "[Content_Types].xml", data: xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+			`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+			`</Types>`},
+		{name: "_rels/.rels", data: xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+			`</Relationships>`},
+		{name: "word/_rels/document.xml.rels", data: xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`},
+		{name: "word/styles.xml", data: xml.Header + `<w:styles ` + wordNS + `>` + styles.String() + `</w:styles>`},
+		{name: "word/document.xml", data: xml.Header + `<w:document ` + wordNS + `><w:body>` + body.String() + `</w:body></w:document>`},
+	})
+}
+
+// docxParagraph writes a paragraph of the given style, or of the default style if empty
+func docxParagraph(b *strings.Builder, style, text string) {
+	b.WriteString("<w:p>")
+	if style != "" {
+		fmt.Fprintf(b, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+	}
+	fmt.Fprintf(b, `<w:r><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, xmlText(text))
+}
+
+// encodeODT renders a mindmap as an OpenDocument text outline
+func encodeODT(mindmap *model.Mindmap, style model.DisplayStyle) ([]byte, error) {
+	title, blocks := documentOutline(mindmap, style)
+
+	var body strings.Builder
+	if title != "" {
+		fmt.Fprintf(&body, `<text:p text:style-name="Title">%s</text:p>`, xmlText(title))
+	}
+	for _, block := range blocks {
+		if block.level > 0 {
+			fmt.Fprintf(&body, `<text:h text:style-name="Heading_20_%d" text:outline-level="%d">%s</text:h>`, block.level, block.level, xmlText(block.text))
+		} else {
+			fmt.Fprintf(&body, `<text:p text:style-name="Text_20_body">%s</text:p>`, xmlText(block.text))
+		}
+	}
+
+	const odfNS = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+		`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+		`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" ` +
+		`xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" office:version="1.2"`
+
+	var styles strings.Builder
+	styles.WriteString(`<style:style style:name="Title" style:family="paragraph"><style:text-properties fo:font-size="24pt" fo:font-weight="bold"/></style:style>`)
+	styles.WriteString(`<style:style style:name="Text_20_body" style:display-name="Text body" style:family="paragraph"/>`)
+	for level := 1; level <= maxHeadingLevel; level++ {
+		fmt.Fprintf(&styles, `<style:style style:name="Heading_20_%d" style:display-name="Heading %d" style:family="paragraph" style:default-outline-level="%d"><style:text-properties fo:font-size="%dpt" fo:font-weight="bold"/></style:style>`,
+			level, level, level, max(18-level, 11))
+	}
+
+	return writeZip([]zipFile{
+		{name: "mimetype", data: "application/vnd.oasis.opendocument.text", stored: true},
+		{name: "META-INF/manifest.xml", data: xml.Header + `<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">` +
+			`<manifest:file-entry manifest:full-path="/" manifest:media-type="application/vnd.oasis.opendocument.text"/>` +
+			`<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>` +
+			`<manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>` +
+			`</manifest:manifest>`},
+		{name: "styles.xml", data: xml.Header + `<office:document-styles ` + odfNS + `><office:styles>` + styles.String() + `</office:styles></office:document-styles>`},
+		{name: "content.xml", data: xml.Header + `<office:document-content ` + odfNS + `><office:body><office:text>` + body.String() + `</office:text></office:body></office:document-content>`},
+	})
+}
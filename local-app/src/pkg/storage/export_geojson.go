@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+func init() {
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "geojson",
+		Description: "GeoJSON points of the nodes with lat and lon fields",
+		Subtree:     true,
+		Encode:      encodeGeoJSON,
+	})
+}
+
+// geoFeature is a GeoJSON point feature of a located node
+type geoFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoPoint          `json:"geometry"`
+	Properties map[string]string `json:"properties"`
+}
+
+// geoPoint is a GeoJSON point, with its coordinates in longitude, latitude order
+type geoPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// encodeGeoJSON renders the nodes with lat and lon fields as a GeoJSON feature collection of points, in document
+// order. The name, displayed index and the other content fields of each node become the properties of its point.
+func encodeGeoJSON(mindmap *model.Mindmap, style model.DisplayStyle) ([]byte, error) {
+	features := []geoFeature{}
+	for node := range mindmap.Subtree(nil, nil) {
+		lat, lon, ok := node.Location()
+		if !ok {
+			continue
+		}
+
+		properties := map[string]string{"name": node.Name, "index": style.DisplayIndex(node.Index)}
+		for _, key := range slices.Sorted(maps.Keys(node.Content)) {
+			if key != model.LatField && key != model.LonField {
+				properties[key] = node.Content[key]
+			}
+		}
+		features = append(features, geoFeature{
+			Type:       "Feature",
+			Geometry:   geoPoint{Type: "Point", Coordinates: [2]float64{lon, lat}},
+			Properties: properties,
+		})
+	}
+	if len(features) == 0 {
+		return nil, errors.New("no nodes with lat and lon fields to export")
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"type":     "FeatureCollection",
+		"name":     mindmap.Name,
+		"features": features,
+	}, "", "  ")
+}
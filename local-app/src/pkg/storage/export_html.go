@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"bytes"
+	"html/template"
+	"maps"
+	"slices"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+func init() {
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "html",
+		Description: "Web page of the tree of nodes with their content fields, the branches collapsible",
+		Subtree:     true,
+		Encode:      encodeHTML,
+	})
+}
+
+// htmlNode is a node as rendered by htmlPage
+type htmlNode struct {
+	ID       int
+	Index    string
+	Name     string
+	Style    template.CSS // The colors and font of the style fields
+	Fields   []htmlField
+	Children []*htmlNode
+	Open     bool // The branch is expanded
+	Match    bool // The node was found by the search of the page
+}
+
+// htmlField is a content field of an htmlNode
+type htmlField struct {
+	Key, Value string
+}
+
+// HTMLView is the search of a page rendered by EncodeHTMLView
+type HTMLView struct {
+	Search  string        // The URL the search box submits its query to, as the q parameter
+	Query   string        // The query searched for, shown in the search box
+	Matches []*model.Node // The nodes found by the query, listed above the tree and expanded to in it
+	Message string        // Shown instead of the matches, such as when none are found
+}
+
+// htmlPage renders a mindmap as a page of nested lists, branches as <details> elements that readers expand and
+// collapse without scripts, styled to read on phones as on desktops
+var htmlPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 1rem; line-height: 1.4; }
+ul { list-style: none; margin: 0; padding-left: 1.2rem; }
+body > ul { padding-left: 0; }
+li { margin: .2rem 0; }
+summary { cursor: pointer; }
+.index { color: #888; margin-right: .4rem; font-variant-numeric: tabular-nums; }
+.name { padding: 0 .2rem; border-radius: .2rem; }
+.match > .name, .match > details > summary > .name { outline: 2px solid #e0a800; }
+dl { margin: .1rem 0 .2rem 1.6rem; font-size: .9em; color: #444; }
+dt { display: inline; font-weight: 600; }
+dt::after { content: ": "; }
+dd { display: inline; margin: 0; }
+dd::after { content: ""; display: block; }
+form { display: flex; gap: .4rem; margin-bottom: 1rem; }
+input { flex: 1; min-width: 0; font-size: 1rem; padding: .3rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{with .View}}<form method="get" action="{{.Search}}" role="search"><input type="search" name="q" value="{{.Query}}" placeholder="Find nodes"><button>Find</button></form>
+{{if .Message}}<p>{{.Message}}</p>{{end}}
+{{end}}{{if .Matches}}<ul>{{range .Matches}}<li><a href="#n{{.ID}}">{{template "label" .}}</a></li>{{end}}</ul>
+{{end}}{{if .Root}}{{template "node" .Root}}{{end}}
+{{if .Nodes}}<ul>{{range .Nodes}}{{template "item" .}}{{end}}</ul>{{end}}
+</body>
+</html>
+{{define "node"}}{{if .Fields}}<dl>{{range .Fields}}<dt>{{.Key}}</dt><dd>{{.Value}}</dd>{{end}}</dl>{{end}}{{end}}
+{{define "label"}}<span class="index">{{.Index}}</span><span class="name"{{with .Style}} style="{{.}}"{{end}}>{{.Name}}</span>{{end}}
+{{define "item"}}<li id="n{{.ID}}"{{if .Match}} class="match"{{end}}>{{if .Children}}<details{{if .Open}} open{{end}}><summary>{{template "label" .}}</summary>{{template "node" .}}<ul>{{range .Children}}{{template "item" .}}{{end}}</ul></details>{{else}}{{template "label" .}}{{template "node" .}}{{end}}</li>
+{{end}}`))
+
+// htmlPageData is the data of htmlPage: the title, the search of a view with the nodes it found, the content
+// fields of the root and the top-level nodes
+type htmlPageData struct {
+	Title   string
+	View    *HTMLView
+	Matches []*htmlNode
+	Root    *htmlNode
+	Nodes   []*htmlNode
+}
+
+// encodeHTML renders a mindmap as a web page: the root as the title, with its content fields, and the other nodes
+// as a tree of nested lists in document order with their displayed indexes and content fields. The style fields
+// color the names and set their font, the layout fields are not shown. The branches are expanded.
+func encodeHTML(mindmap *model.Mindmap, style model.DisplayStyle) ([]byte, error) {
+	return renderHTML(mindmap, style, nil)
+}
+
+// EncodeHTMLView renders a mindmap as encodeHTML does, for reading on the web: with a search box, the nodes found
+// by its query listed above the tree, and the branches collapsed but for those leading to the nodes found
+func EncodeHTMLView(mindmap *model.Mindmap, style model.DisplayStyle, view HTMLView) ([]byte, error) {
+	return renderHTML(mindmap, style, &view)
+}
+
+// renderHTML renders the page of encodeHTML, or of EncodeHTMLView with a view
+func renderHTML(mindmap *model.Mindmap, style model.DisplayStyle, view *HTMLView) ([]byte, error) {
+	children := make(map[int][]*model.Node)
+	for node := range mindmap.Subtree(nil, nil) {
+		children[node.ParentID] = append(children[node.ParentID], node)
+	}
+	data := htmlPageData{Title: mindmap.Name, View: view}
+	matched := make(map[int]bool)
+	if view != nil {
+		for _, node := range view.Matches {
+			matched[node.ID] = true
+			data.Matches = append(data.Matches, &htmlNode{ID: node.ID, Index: style.DisplayIndex(node.Index), Name: node.Name, Style: htmlStyle(node)})
+		}
+	}
+
+	var convert func(node *model.Node) *htmlNode
+	convert = func(node *model.Node) *htmlNode {
+		n := &htmlNode{
+			ID:    node.ID,
+			Index: style.DisplayIndex(node.Index),
+			Name:  node.Name,
+			Style: htmlStyle(node),
+			Open:  view == nil,
+			Match: matched[node.ID],
+		}
+		for _, key := range slices.Sorted(maps.Keys(node.Content)) {
+			if !model.IsStyleField(key) && !model.IsLayoutField(key) {
+				n.Fields = append(n.Fields, htmlField{Key: key, Value: node.Content[key]})
+			}
+		}
+		for _, child := range children[node.ID] {
+			c := convert(child)
+			// A view expands the branches down to the nodes found
+			n.Open = n.Open || c.Match || c.Open
+			n.Children = append(n.Children, c)
+		}
+		return n
+	}
+
+	if root := mindmap.Root; root != nil {
+		data.Root = convert(root)
+		data.Nodes, data.Root.Children = data.Root.Children, nil
+	}
+
+	var b bytes.Buffer
+	if err := htmlPage.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// htmlStyle returns the CSS of the style fields of a node. The colors are validated as #rrggbb by the fields.
+func htmlStyle(node *model.Node) template.CSS {
+	var css string
+	if color := node.StyleColor(); color != "" {
+		css += "color: " + color + "; "
+	}
+	if fill := node.StyleFill(); fill != "" {
+		css += "background: " + fill + "; "
+	}
+	bold, italic := node.StyleFont()
+	if bold {
+		css += "font-weight: bold; "
+	}
+	if italic {
+		css += "font-style: italic; "
+	}
+	return template.CSS(css)
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+func init() {
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "ics",
+		Description: "iCalendar events of the nodes with due or remind fields",
+		Subtree:     true,
+		Encode:      encodeICS,
+	})
+}
+
+const (
+	icsTimeLayout = "20060102T150405Z"
+	icsDateLayout = "20060102"
+	icsLineLength = 75 // Octets per line, longer lines are folded
+)
+
+// icsEscaper escapes the characters with a meaning in iCalendar text values
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// encodeICS renders the nodes with due or remind fields as an iCalendar of events, in document order
+func encodeICS(mindmap *model.Mindmap, style model.DisplayStyle) ([]byte, error) {
+	var reminders []*model.Reminder
+	for node := range mindmap.Subtree(nil, nil) {
+		if reminder, ok := nodeReminder(mindmap, node); ok {
+			reminders = append(reminders, reminder)
+		}
+	}
+	if len(reminders) == 0 {
+		return nil, errors.New("no nodes with due or remind fields to export")
+	}
+	return EncodeICS(mindmap.Name, reminders, style), nil
+}
+
+// EncodeICS renders reminders as an iCalendar named name, one event per node, for the ics export format and the
+// calendar feeds of the web adapter. An event starts at the due field of its node, a date alone making it an
+// all-day event, or else at its remind field. A remind field also sets an alarm of the event, the alarms of due
+// times being left to the defaults of the calendar. Events keep their UID across renderings, so that calendars
+// subscribed to a feed update them instead of adding them again.
+func EncodeICS(name string, reminders []*model.Reminder, style model.DisplayStyle) []byte {
+	var b strings.Builder
+	stamp := time.Now().UTC().Format(icsTimeLayout)
+
+	icsLine(&b, "BEGIN:VCALENDAR")
+	icsLine(&b, "VERSION:2.0")
+	icsLine(&b, "PRODID:-//Mindnoscape//Mindnoscape//EN")
+	icsLine(&b, "CALSCALE:GREGORIAN")
+	icsLine(&b, "X-WR-CALNAME:"+icsEscaper.Replace(name))
+	for _, reminder := range reminders {
+		summary := icsEscaper.Replace(reminder.Name)
+		icsLine(&b, "BEGIN:VEVENT")
+		icsLine(&b, fmt.Sprintf("UID:%d-%d@mindnoscape", reminder.MindmapID, reminder.NodeID))
+		icsLine(&b, "DTSTAMP:"+stamp)
+		icsLine(&b, icsStart(reminder))
+		icsLine(&b, "SUMMARY:"+summary)
+		icsLine(&b, "DESCRIPTION:"+icsEscaper.Replace(fmt.Sprintf("%s, node %s", reminder.MindmapName, style.DisplayIndex(reminder.Index))))
+		if reminder.Field == model.RemindField {
+			icsLine(&b, "BEGIN:VALARM")
+			icsLine(&b, "ACTION:DISPLAY")
+			icsLine(&b, "DESCRIPTION:"+summary)
+			icsLine(&b, "TRIGGER;VALUE=DATE-TIME:"+reminder.At.UTC().Format(icsTimeLayout))
+			icsLine(&b, "END:VALARM")
+		}
+		icsLine(&b, "END:VEVENT")
+	}
+	icsLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// icsStart returns the DTSTART property of the event of a reminder
+func icsStart(reminder *model.Reminder) string {
+	due := strings.TrimSpace(reminder.Due)
+	if day, err := time.ParseInLocation(model.ReminderDateLayout, due, time.Local); err == nil {
+		return "DTSTART;VALUE=DATE:" + day.Format(icsDateLayout)
+	}
+	if at, err := model.ParseReminderTime(model.DueField, due); err == nil {
+		return "DTSTART:" + at.UTC().Format(icsTimeLayout)
+	}
+	return "DTSTART:" + reminder.At.UTC().Format(icsTimeLayout)
+}
+
+// icsLine writes a content line, folded into lines of at most icsLineLength octets without splitting characters
+func icsLine(b *strings.Builder, line string) {
+	for limit := icsLineLength; len(line) > limit; limit = icsLineLength - 1 {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+func TestEncodeICS(t *testing.T) {
+	remind := time.Date(2026, 10, 18, 9, 30, 0, 0, time.Local)
+	reminders := []*model.Reminder{
+		{MindmapID: 3, MindmapName: "plans", NodeID: 1, Index: "1", Name: "Dentist", Field: model.DueField, Due: "2026-10-20"},
+		{MindmapID: 3, MindmapName: "plans", NodeID: 2, Index: "1.1", Name: "Call, then; pay\\", Field: model.RemindField, Due: "2026-10-19T14:00", At: remind},
+		{MindmapID: 3, MindmapName: "plans", NodeID: 4, Index: "2", Name: "Water plants", Field: model.RemindField, At: remind},
+		{MindmapID: 3, MindmapName: "plans", NodeID: 5, Index: "3", Name: strings.Repeat("é", 60), Field: model.DueField, Due: "2026-10-21"},
+	}
+	due := time.Date(2026, 10, 19, 14, 0, 0, 0, time.Local)
+
+	data := string(EncodeICS("plans", reminders, model.DisplayStyle{}))
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:plans\r\n",
+		"UID:3-1@mindnoscape\r\n",
+		"DTSTART;VALUE=DATE:20261020\r\n",
+		"UID:3-2@mindnoscape\r\n",
+		"DTSTART:" + due.UTC().Format(icsTimeLayout) + "\r\n",
+		`SUMMARY:Call\, then\; pay\\` + "\r\n",
+		"DESCRIPTION:plans\\, node 1.1\r\n",
+		"TRIGGER;VALUE=DATE-TIME:" + remind.UTC().Format(icsTimeLayout) + "\r\n",
+		"UID:3-4@mindnoscape\r\nDTSTAMP:",
+		"DTSTART:" + remind.UTC().Format(icsTimeLayout) + "\r\nSUMMARY:Water plants\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("EncodeICS misses %q in:\n%s", want, data)
+		}
+	}
+	if got := strings.Count(data, "BEGIN:VALARM"); got != 2 {
+		t.Errorf("EncodeICS has %d alarms, want 2 of the remind fields", got)
+	}
+
+	// Long lines are folded at 75 octets without splitting characters, unfolding to the original line
+	var unfolded []string
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		if len(line) > icsLineLength {
+			t.Errorf("line of %d octets longer than %d: %q", len(line), icsLineLength, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("folded line splits a character: %q", line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+	summary := "SUMMARY:" + strings.Repeat("é", 60)
+	found := false
+	for _, line := range unfolded {
+		found = found || line == summary
+	}
+	if !found {
+		t.Errorf("folded summary does not unfold to %q", summary)
+	}
+}
+
+func TestEncodeICSExport(t *testing.T) {
+	mindmap := &model.Mindmap{ID: 1, Name: "empty", Root: &model.Node{ID: 0, ParentID: -1, Index: "0"}}
+	mindmap.Nodes = map[int]*model.Node{0: mindmap.Root}
+	if _, err := encodeICS(mindmap, model.DisplayStyle{}); err == nil {
+		t.Error("encodeICS of a mindmap without dated nodes succeeded, want an error")
+	}
+}
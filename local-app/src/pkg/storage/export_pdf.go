@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Layout of the single A4 page of a text PDF, in points
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfTitleSize    = 14
+	pdfFontSize     = 10
+	pdfLeading      = 13
+	pdfLineRunes    = 100 // Runes of a line fitting the width of the page in the font size, longer lines are cut
+	pdfTitleLeading = 24
+)
+
+// PDFPageLines is the number of lines a text PDF page holds below its title
+const PDFPageLines = (pdfPageHeight - 2*pdfMargin - pdfTitleLeading) / pdfLeading
+
+// EncodeTextPDF renders a title and lines of text as a single page PDF document in Helvetica, for printing.
+// Lines past the page are left out, noting how many, and long lines are cut. Characters outside the Windows-1252
+// character set of the standard fonts are replaced.
+func EncodeTextPDF(title string, lines []string) []byte {
+	if len(lines) > PDFPageLines {
+		lines = append(lines[:PDFPageLines-1:PDFPageLines-1], fmt.Sprintf("(%d more lines left out)", len(lines)-PDFPageLines+1))
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "BT\n/F2 %d Tf\n%d %d Td\n(%s) Tj\n", pdfTitleSize, pdfMargin, pdfPageHeight-pdfMargin-pdfTitleSize, pdfText(title))
+	fmt.Fprintf(&content, "/F1 %d Tf\n%d TL\n0 %d Td\n", pdfFontSize, pdfLeading, -pdfTitleLeading)
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) > pdfLineRunes {
+			line = string([]rune(line)[:pdfLineRunes-3]) + "..."
+		}
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfText(line))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pdfPageWidth, pdfPageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// pdfText encodes text as the content of a PDF string in the Windows-1252 encoding of the standard fonts, with
+// question marks for the characters it lacks
+func pdfText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		switch {
+		case !ok:
+			b.WriteByte('?')
+		case c == '\\' || c == '(' || c == ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\r' || c == '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
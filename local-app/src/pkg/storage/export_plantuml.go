@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+func init() {
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "plantuml",
+		Extension:   "puml",
+		Description: "PlantUML mindmap laid out by the layout hint fields of the nodes",
+		Subtree:     true,
+		Encode:      encodePlantUML,
+	})
+}
+
+// encodePlantUML renders a mindmap in PlantUML mindmap syntax, one line per node with its depth in asterisks.
+// Nodes with content fields use the multiline form with a line per field below the name. The root is always the
+// central node, as PlantUML mindmaps have a single root. Siblings are ordered by the layout hints of the nodes,
+// which are not shown, and the top-level nodes on the left side follow a 'left side' line. The style fields color
+// the nodes and their names and set the font of the names, the shape is not shown.
+func encodePlantUML(mindmap *model.Mindmap, _ model.DisplayStyle) ([]byte, error) {
+	children := make(map[int][]*model.Node)
+	for node := range mindmap.Subtree(nil, nil) {
+		children[node.ParentID] = append(children[node.ParentID], node)
+	}
+
+	var b strings.Builder
+	var write func(node *model.Node, depth int)
+	write = func(node *model.Node, depth int) {
+		b.WriteString(strings.Repeat("*", depth+1))
+		if fill := node.StyleFill(); fill != "" {
+			b.WriteString("[" + fill + "]")
+		}
+		name := plantUMLStyled(node, plantUMLText(node.Name))
+		keys := slices.DeleteFunc(slices.Sorted(maps.Keys(node.Content)), func(key string) bool {
+			return model.IsLayoutField(key) || model.IsStyleField(key)
+		})
+		if len(keys) == 0 {
+			b.WriteString(" " + name + "\n")
+		} else {
+			b.WriteString(":" + name)
+			for _, key := range keys {
+				b.WriteString("\n" + plantUMLText(key+": "+node.Content[key]))
+			}
+			b.WriteString(";\n")
+		}
+		if depth == 0 {
+			return
+		}
+		for _, child := range model.LayoutSort(children[node.ID]) {
+			write(child, depth+1)
+		}
+	}
+
+	b.WriteString("@startmindmap\n")
+	if root := mindmap.Root; root != nil {
+		write(root, 0)
+		right, left := model.LayoutSides(children[root.ID])
+		for _, node := range right {
+			write(node, 1)
+		}
+		if len(left) > 0 {
+			b.WriteString("left side\n")
+		}
+		for _, node := range left {
+			write(node, 1)
+		}
+	}
+	b.WriteString("@endmindmap\n")
+	return []byte(b.String()), nil
+}
+
+// plantUMLText keeps text on one line and away from the ';' ending a multiline node
+func plantUMLText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.TrimRight(text, ";")
+}
+
+// plantUMLStyled applies the text color and font of a node to its name with Creole markup
+func plantUMLStyled(node *model.Node, name string) string {
+	bold, italic := node.StyleFont()
+	if bold {
+		name = "<b>" + name + "</b>"
+	}
+	if italic {
+		name = "<i>" + name + "</i>"
+	}
+	if color := node.StyleColor(); color != "" {
+		name = "<color:" + color + ">" + name + "</color>"
+	}
+	return name
+}
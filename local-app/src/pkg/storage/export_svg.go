@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+func init() {
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "svg",
+		Description: "SVG diagram of the mindmap laid out by the layout hint fields of the nodes",
+		Subtree:     true,
+		Encode:      encodeSVG,
+	})
+}
+
+// Layout of the SVG diagram, in pixels
+const (
+	svgMargin      = 20
+	svgRowHeight   = 28  // Height of a row of leaf nodes
+	svgBoxHeight   = 20  // Height of the box of a node
+	svgLevelGap    = 36  // Horizontal gap between a node and its children
+	svgPadding     = 6   // Padding around the name in the box of a node
+	svgCharWidth   = 7.2 // Estimated width of a character of the font
+	svgFontSize    = 12  // Font size of the names
+	svgMaxNameRune = 48  // Characters of a name shown, longer names are cut
+	svgDefaultFill = "#f5f5f5"
+)
+
+// svgBox is the box of a node in the diagram
+type svgBox struct {
+	label string
+	x, y  float64 // Top left corner
+	width float64
+	depth int // Levels below the root
+}
+
+// encodeSVG renders a mindmap as a diagram with the root in the middle and the top-level nodes with their subtrees
+// to its right and left, as the layout hints of the nodes split and order them. Each node is a box with its name,
+// colored and set in the font of its style fields, linked to its parent by a curve. Text is estimated to fit the
+// boxes, as the fonts of viewers are not known when rendering.
+func encodeSVG(mindmap *model.Mindmap, _ model.DisplayStyle) ([]byte, error) {
+	root := mindmap.Root
+	if root == nil {
+		return nil, fmt.Errorf("mindmap %s has no root to export", mindmap.Name)
+	}
+	children := make(map[int][]*model.Node)
+	for node := range mindmap.Subtree(nil, nil) {
+		children[node.ParentID] = append(children[node.ParentID], node)
+	}
+
+	boxes := make(map[int]*svgBox)
+	newBox := func(node *model.Node) *svgBox {
+		label := strings.Join(strings.Fields(node.Name), " ")
+		if utf8.RuneCountInString(label) > svgMaxNameRune {
+			label = string([]rune(label)[:svgMaxNameRune-1]) + "…"
+		}
+		box := &svgBox{label: label, width: float64(utf8.RuneCountInString(label))*svgCharWidth + 2*svgPadding}
+		boxes[node.ID] = box
+		return box
+	}
+	rootBox := newBox(root)
+
+	// Each side stacks the leaves of its subtrees in rows, the parents centered on their children, and aligns the
+	// nodes of a level in a column as wide as its widest node
+	right, left := model.LayoutSides(children[root.ID])
+	layoutSide := func(nodes []*model.Node, direction int) (float64, []*svgBox) {
+		var placed []*svgBox
+		var widths []float64
+		rows := 0
+		var place func(node *model.Node, depth int) float64
+		place = func(node *model.Node, depth int) float64 {
+			box := newBox(node)
+			placed = append(placed, box)
+			if len(widths) < depth {
+				widths = append(widths, 0)
+			}
+			widths[depth-1] = max(widths[depth-1], box.width)
+
+			var center float64
+			if kids := model.LayoutSort(children[node.ID]); len(kids) > 0 {
+				first := place(kids[0], depth+1)
+				last := first
+				for _, kid := range kids[1:] {
+					last = place(kid, depth+1)
+				}
+				center = (first + last) / 2
+			} else {
+				center = float64(rows)*svgRowHeight + svgRowHeight/2
+				rows++
+			}
+			box.y, box.depth = center-svgBoxHeight/2, depth
+			return center
+		}
+		for _, node := range nodes {
+			place(node, 1)
+		}
+
+		offsets := make([]float64, len(widths))
+		offset := rootBox.width/2 + svgLevelGap
+		for i, width := range widths {
+			offsets[i] = offset
+			offset += width + svgLevelGap
+		}
+		for _, box := range placed {
+			if direction > 0 {
+				box.x = offsets[box.depth-1]
+			} else {
+				box.x = -offsets[box.depth-1] - box.width
+			}
+		}
+		return float64(rows) * svgRowHeight, placed
+	}
+	rightHeight, rightBoxes := layoutSide(right, 1)
+	leftHeight, leftBoxes := layoutSide(left, -1)
+
+	// The sides are centered on the root, then the diagram is moved into view
+	height := max(rightHeight, leftHeight, svgRowHeight)
+	for _, box := range rightBoxes {
+		box.y += (height - rightHeight) / 2
+	}
+	for _, box := range leftBoxes {
+		box.y += (height - leftHeight) / 2
+	}
+	rootBox.x, rootBox.y = -rootBox.width/2, height/2-svgBoxHeight/2
+	minX, maxX := rootBox.x, rootBox.x+rootBox.width
+	for _, box := range boxes {
+		minX, maxX = min(minX, box.x), max(maxX, box.x+box.width)
+	}
+	for _, box := range boxes {
+		box.x += svgMargin - minX
+		box.y += svgMargin
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" font-family="sans-serif" font-size="%d">`+"\n",
+		maxX-minX+2*svgMargin, height+2*svgMargin, svgFontSize)
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(mindmap.Name))
+	for node := range mindmap.Subtree(nil, nil) {
+		box, parent := boxes[node.ID], boxes[node.ParentID]
+		if node.ID == root.ID || parent == nil {
+			continue
+		}
+		// Curves join the facing sides of the boxes
+		x1, x2 := parent.x+parent.width, box.x
+		if box.x < parent.x {
+			x1, x2 = parent.x, box.x+box.width
+		}
+		y1, y2 := parent.y+svgBoxHeight/2, box.y+svgBoxHeight/2
+		mid := (x1 + x2) / 2
+		fmt.Fprintf(&b, `<path d="M%.1f %.1fC%.1f %.1f %.1f %.1f %.1f %.1f" fill="none" stroke="#999"/>`+"\n", x1, y1, mid, y1, mid, y2, x2, y2)
+	}
+	for node := range mindmap.Subtree(nil, nil) {
+		box, ok := boxes[node.ID]
+		if !ok {
+			continue
+		}
+		fill := node.StyleFill()
+		if fill == "" {
+			fill = svgDefaultFill
+		}
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" rx="4" fill="%s" stroke="#999"/>`, box.x, box.y, box.width, svgBoxHeight, fill)
+		var attrs string
+		if color := node.StyleColor(); color != "" {
+			attrs += ` fill="` + color + `"`
+		}
+		bold, italic := node.StyleFont()
+		if bold || node.ID == root.ID {
+			attrs += ` font-weight="bold"`
+		}
+		if italic {
+			attrs += ` font-style="italic"`
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f"%s>%s</text>`+"\n", box.x+svgPadding, box.y+svgBoxHeight-6, attrs, html.EscapeString(box.label))
+	}
+	b.WriteString("</svg>\n")
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+const (
+	checksumPrefix  = "sha256:"
+	signatureSuffix = ".sig"
+)
+
+var (
+	// ErrChecksumMissing is returned when an imported mindmap carries no checksum
+	ErrChecksumMissing = errors.New("file has no checksum")
+	// ErrChecksumMismatch is returned when the content of an imported mindmap doesn't match its checksum
+	ErrChecksumMismatch = errors.New("checksum mismatch, the file is corrupted or was modified")
+	// ErrSignatureMissing is returned when an exported file has no detached signature
+	ErrSignatureMissing = errors.New("file is not signed")
+	// ErrSignatureInvalid is returned when a detached signature doesn't match the file content
+	ErrSignatureInvalid = errors.New("invalid signature, the file was modified or signed by someone else")
+)
+
+// MindmapChecksum returns the checksum of a mindmap's content. It is computed over the JSON encoding
+// of the mindmap without its checksum, so it is independent of the export format and compression.
+func MindmapChecksum(mindmap *model.Mindmap) (string, error) {
+	content := *mindmap
+	content.Checksum = ""
+
+	data, err := json.Marshal(&content)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode mindmap: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return checksumPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// ChecksumVerify checks the checksum embedded in an imported mindmap against its content
+func ChecksumVerify(mindmap *model.Mindmap) error {
+	if mindmap.Checksum == "" {
+		return ErrChecksumMissing
+	}
+	checksum, err := MindmapChecksum(mindmap)
+	if err != nil {
+		return err
+	}
+	if checksum != mindmap.Checksum {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// SignaturePath returns the path of the detached signature of an exported file
+func SignaturePath(path string) string {
+	return path + signatureSuffix
+}
+
+// SignatureWrite signs a checksum with the user's key and writes it as detached signature next to the exported file.
+// The key pair of the user is created in keyDir on first use.
+func SignatureWrite(path, checksum, keyDir, username string) error {
+	key, err := signingKey(keyDir, username)
+	if err != nil {
+		return err
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(checksum)))
+	return WriteFileAtomic(SignaturePath(path), []byte(signature+"\n"), 0644)
+}
+
+// SignatureVerify checks the detached signature of an exported file against the checksum and the user's public key
+func SignatureVerify(path, checksum, keyDir, username string) error {
+	data, err := os.ReadFile(SignaturePath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrSignatureMissing
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	key, err := publicKey(keyDir, username)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, []byte(checksum), signature) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// signingKey loads the private key of a user, creating a new key pair if the user has none
+func signingKey(keyDir, username string) (ed25519.PrivateKey, error) {
+	keyPath := filepath.Join(keyDir, names.Filename(username, "user")+".key")
+
+	data, err := os.ReadFile(keyPath)
+	if err == nil {
+		seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid signing key %s", keyPath)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	if err := WriteFileAtomic(keyPath, []byte(base64.StdEncoding.EncodeToString(private.Seed())+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	publicPath := filepath.Join(keyDir, names.Filename(username, "user")+".pub")
+	if err := WriteFileAtomic(publicPath, []byte(base64.StdEncoding.EncodeToString(public)+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write public key: %w", err)
+	}
+	return private, nil
+}
+
+// publicKey loads the public key of a user
+func publicKey(keyDir, username string) (ed25519.PublicKey, error) {
+	publicPath := filepath.Join(keyDir, names.Filename(username, "user")+".pub")
+
+	data, err := os.ReadFile(publicPath)
+	if err != nil {
+		return nil, fmt.Errorf("no public key for user '%s': %w", username, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key %s", publicPath)
+	}
+	return ed25519.PublicKey(key), nil
+}
@@ -5,89 +5,233 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"mindnoscape/local-app/src/pkg/log"
 	"mindnoscape/local-app/src/pkg/model"
 )
 
-// FileExport exports a mindmap to a file in the specified format (JSON or XML).
-func FileExport(mindmap *model.Mindmap, filename string, format string, logger *log.Logger) error {
+// The native formats, JSON and XML, are the only ones both exported and imported with the node IDs and integrity
+// data, the other formats are documents for other tools
+func init() {
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "json",
+		Description: "JSON, imported again with the node IDs",
+		Encode:      func(m *model.Mindmap, _ model.DisplayStyle) ([]byte, error) { return json.MarshalIndent(m, "", "  ") },
+	})
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "xml",
+		Description: "XML, imported again with the node IDs",
+		Encode:      func(m *model.Mindmap, _ model.DisplayStyle) ([]byte, error) { return xml.MarshalIndent(m, "", "  ") },
+	})
+	mustRegisterImportFormat(model.ImportFormat{
+		Name:        "json",
+		Description: "JSON export",
+		Native:      true,
+		Decode: func(data []byte, _ string) (*model.Mindmap, []string, error) {
+			var m model.Mindmap
+			return &m, nil, json.Unmarshal(data, &m)
+		},
+	})
+	mustRegisterImportFormat(model.ImportFormat{
+		Name:        "xml",
+		Description: "XML export",
+		Native:      true,
+		Decode: func(data []byte, _ string) (*model.Mindmap, []string, error) {
+			var m model.Mindmap
+			return &m, nil, xml.Unmarshal(data, &m)
+		},
+	})
+}
+
+// ImportFormat returns the import format of a file by its extension, the name or one of the extensions of the
+// format, also when compressed, or "" if unknown
+func ImportFormat(filename string) string {
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(TrimCompressionExt(filename)), "."))
+	if IsImportFormat(format) {
+		return format
+	}
+	for _, f := range ImportFormats() {
+		if slices.Contains(f.Extensions, format) {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+// FileExport exports a mindmap to a file in the specified format, see RegisterExportFormat.
+// A .gz or .zst file name suffix compresses the file, a final .mnx suffix encrypts it with a key derived from
+// passphrase. An existing file is only replaced if overwrite is set. The content checksum is embedded in the file
+// and returned. Progress, if not nil, receives the estimated progress. The display style lays out the root and the
+// node indexes of the document formats.
+func FileExport(mindmap *model.Mindmap, filename string, format string, overwrite bool, passphrase string, style model.DisplayStyle, progress model.ProgressFunc, logger *log.Logger) (string, error) {
 	logger.Info(context.Background(), "Exporting mindmap to file", log.Fields{
 		"mindmapID": mindmap.ID,
 		"filename":  filename,
 		"format":    format,
 	})
 
-	// Marshal the mindmap to the specified format
-	var data []byte
-	var err error
-	switch format {
-	case "json":
-		data, err = json.MarshalIndent(mindmap, "", "  ")
-	case "xml":
-		data, err = xml.MarshalIndent(mindmap, "", "  ")
-	default:
-		logger.Error(context.Background(), "Unsupported export format", log.Fields{"format": format})
-		return fmt.Errorf("unsupported format: %s", format)
+	// Refuse to replace an existing file unless asked to
+	if !overwrite {
+		if _, err := os.Stat(filename); err == nil {
+			logger.Warn(context.Background(), "Export file already exists", log.Fields{"filename": filename})
+			return "", fmt.Errorf("file '%s' already exists, use --force to overwrite", filename)
+		}
 	}
+
+	data, checksum, err := ExportEncode(mindmap, format, style, logger)
 	if err != nil {
-		logger.Error(context.Background(), "Failed to marshal mindmap", log.Fields{"error": err, "format": format})
-		return fmt.Errorf("failed to marshal mindmap: %w", err)
+		return "", err
+	}
+
+	// Stream the data through the compressor into a temporary file that replaces the target only once fully written
+	file, err := CreateAtomic(filename, 0644)
+	if err != nil {
+		logger.Error(context.Background(), "Failed to create file", log.Fields{"error": err, "filename": filename})
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+
+	// Compressed data is encrypted, as encrypted data doesn't compress
+	var encrypted io.Writer = file
+	var encryptor io.WriteCloser = nopWriteCloser{file}
+	if IsEncryptedPath(filename) {
+		if encryptor, err = encryptWriter(file, passphrase); err != nil {
+			file.Abort()
+			logger.Error(context.Background(), "Failed to create encryptor", log.Fields{"error": err, "filename": filename})
+			return "", err
+		}
+		encrypted = encryptor
 	}
 
-	// Ensure the directory exists
-	dir := filepath.Dir(filename)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		logger.Error(context.Background(), "Failed to create directory", log.Fields{"error": err, "directory": dir})
-		return fmt.Errorf("failed to create directory: %w", err)
+	compression := CompressionFromPath(filename)
+	compressor, err := compressWriter(encrypted, compression)
+	if err != nil {
+		file.Abort()
+		logger.Error(context.Background(), "Failed to create compressor", log.Fields{"error": err, "compression": compression})
+		return "", fmt.Errorf("failed to create compressor: %w", err)
+	}
+
+	var writer io.Writer = compressor
+	var pw *progressWriter
+	if progress != nil {
+		pw = newProgressWriter(compressor, "export", len(mindmap.Nodes), int64(len(data)), progress)
+		writer = pw
 	}
 
-	// Write the data to the file
-	err = os.WriteFile(filename, data, 0644)
+	_, err = writer.Write(data)
+	if err == nil {
+		err = compressor.Close()
+	}
+	if err == nil {
+		err = encryptor.Close()
+	}
 	if err != nil {
+		file.Abort()
 		logger.Error(context.Background(), "Failed to write file", log.Fields{"error": err, "filename": filename})
-		return fmt.Errorf("failed to write file: %w", err)
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		logger.Error(context.Background(), "Failed to write file", log.Fields{"error": err, "filename": filename})
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	if pw != nil {
+		pw.Finish()
 	}
 
 	logger.Info(context.Background(), "Mindmap exported successfully", log.Fields{
 		"mindmapID": mindmap.ID,
 		"filename":  filename,
 		"format":    format,
+		"checksum":  checksum,
 	})
-	return nil
+	return checksum, nil
+}
+
+// ExportEncode encodes a mindmap in the specified format, see RegisterExportFormat, with the content checksum embedded.
+// It returns the data and the checksum.
+func ExportEncode(mindmap *model.Mindmap, format string, style model.DisplayStyle, logger *log.Logger) ([]byte, string, error) {
+	registered, ok := exportFormat(format)
+	if !ok {
+		logger.Error(context.Background(), "Unsupported export format", log.Fields{"format": format})
+		return nil, "", fmt.Errorf("unsupported format: %s", format)
+	}
+
+	// Embed the content checksum in the exported copy
+	checksum, err := MindmapChecksum(mindmap)
+	if err != nil {
+		logger.Error(context.Background(), "Failed to compute checksum", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, "", fmt.Errorf("failed to compute checksum: %w", err)
+	}
+	exported := *mindmap
+	exported.Checksum = checksum
+
+	// Marshal the mindmap to the specified format
+	data, err := registered.Encode(&exported, style)
+	if err != nil {
+		logger.Error(context.Background(), "Failed to marshal mindmap", log.Fields{"error": err, "format": format})
+		return nil, "", fmt.Errorf("failed to marshal mindmap: %w", err)
+	}
+	return data, checksum, nil
 }
 
-// FileImport imports a mindmap from a file in the specified format (JSON or XML).
-func FileImport(filename string, format string, logger *log.Logger) (*model.Mindmap, error) {
-	// Read the file
-	data, err := os.ReadFile(filename)
+// FileImport imports a mindmap from a file in the specified format, see RegisterImportFormat.
+// A .gz or .zst file name suffix decompresses the file while reading, a final .mnx suffix decrypts it with a key
+// derived from passphrase. Warnings about the features of the file that were not imported are returned along with
+// the mindmap.
+func FileImport(filename string, format string, passphrase string, logger *log.Logger) (*model.Mindmap, []string, error) {
+	// Open the file
+	file, err := os.Open(filename)
 	if err != nil {
 		logger.Error(context.Background(), "Failed to read file", log.Fields{"error": err, "filename": filename})
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+
+	var decrypted io.Reader = file
+	if IsEncryptedPath(filename) {
+		if decrypted, err = decryptReader(file, passphrase); err != nil {
+			logger.Error(context.Background(), "Failed to decrypt file", log.Fields{"error": err, "filename": filename})
+			return nil, nil, fmt.Errorf("failed to decrypt file: %w", err)
+		}
+	}
+
+	compression := CompressionFromPath(filename)
+	reader, err := decompressReader(decrypted, compression)
+	if err != nil {
+		logger.Error(context.Background(), "Failed to create decompressor", log.Fields{"error": err, "compression": compression})
+		return nil, nil, fmt.Errorf("failed to read compressed file: %w", err)
 	}
+	defer reader.Close()
 
-	// Unmarshal the data into a mindmap structures
-	var importedMindmap model.Mindmap
-	switch format {
-	case "json":
-		err = json.Unmarshal(data, &importedMindmap)
-	case "xml":
-		err = xml.Unmarshal(data, &importedMindmap)
-	default:
+	registered, ok := importFormat(format)
+	if !ok {
 		logger.Error(context.Background(), "Unsupported import format", log.Fields{"format": format})
-		return nil, fmt.Errorf("unsupported format: %s", format)
+		return nil, nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		logger.Error(context.Background(), "Failed to read file", log.Fields{"error": err, "filename": filename})
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
 	}
+
+	// Decode the data into a mindmap structure, named by the file for formats without mindmap names
+	base := filepath.Base(TrimCompressionExt(filename))
+	importedMindmap, warnings, err := registered.Decode(data, strings.TrimSuffix(base, filepath.Ext(base)))
 	if err != nil {
 		logger.Error(context.Background(), "Failed to unmarshal data", log.Fields{"error": err, "format": format})
-		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+		return nil, nil, fmt.Errorf("failed to unmarshal data: %w", err)
 	}
 
 	logger.Info(context.Background(), "Mindmap imported successfully", log.Fields{
 		"filename":  filename,
 		"format":    format,
 		"mindmapID": importedMindmap.ID,
+		"warnings":  len(warnings),
 	})
-	return &importedMindmap, nil
+	return importedMindmap, warnings, nil
 }
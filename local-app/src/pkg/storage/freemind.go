@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+func init() {
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "freemind",
+		Extension:   "mm",
+		Description: "FreeMind mindmap, also read by Freeplane and XMind, with the fields as node attributes",
+		Subtree:     true,
+		Encode:      encodeFreeMind,
+	})
+	mustRegisterImportFormat(model.ImportFormat{
+		Name:        "freemind",
+		Extensions:  []string{"mm"},
+		Description: "FreeMind or Freeplane mindmap: node attributes as fields, notes, links, colors and icons",
+		Decode:      decodeFreeMind,
+	})
+}
+
+// freemindVersion is the file format version written, read by all FreeMind and Freeplane versions
+const freemindVersion = "1.0.1"
+
+// freemindNode is a node of a FreeMind file, as read and written
+type freemindNode struct {
+	Text       string                `xml:"TEXT,attr,omitempty"`
+	ID         string                `xml:"ID,attr,omitempty"`
+	Created    int64                 `xml:"CREATED,attr,omitempty"`  // Milliseconds since the epoch
+	Modified   int64                 `xml:"MODIFIED,attr,omitempty"` // Milliseconds since the epoch
+	Link       string                `xml:"LINK,attr,omitempty"`
+	Color      string                `xml:"COLOR,attr,omitempty"`
+	Background string                `xml:"BACKGROUND_COLOR,attr,omitempty"`
+	Style      string                `xml:"STYLE,attr,omitempty"`
+	Font       *freemindFont         `xml:"font"`
+	Icons      []freemindIcon        `xml:"icon"`
+	Rich       []freemindRichContent `xml:"richcontent"`
+	Attributes []freemindAttribute   `xml:"attribute"`
+	ArrowLinks []struct{}            `xml:"arrowlink"`
+	Clouds     []struct{}            `xml:"cloud"`
+	Hooks      []struct{}            `xml:"hook"`
+	Children   []*freemindNode       `xml:"node"`
+}
+
+// freemindFont is the font of a FreeMind node, FreeMind requires its name and size
+type freemindFont struct {
+	Name   string `xml:"NAME,attr"`
+	Size   string `xml:"SIZE,attr"`
+	Bold   string `xml:"BOLD,attr,omitempty"`
+	Italic string `xml:"ITALIC,attr,omitempty"`
+}
+
+// freemindIcon is a built-in icon of a FreeMind node
+type freemindIcon struct {
+	Builtin string `xml:"BUILTIN,attr"`
+}
+
+// freemindRichContent is the HTML of a node text, note or details
+type freemindRichContent struct {
+	Type string `xml:"TYPE,attr"`
+	HTML string `xml:",innerxml"`
+}
+
+// freemindAttribute is a name and value attribute of a FreeMind node
+type freemindAttribute struct {
+	Name  string `xml:"NAME,attr"`
+	Value string `xml:"VALUE,attr"`
+}
+
+// encodeFreeMind renders a mindmap as a FreeMind file. Every field becomes a node attribute, so the fields come
+// back unchanged on import, and the url and the text and fill colors and font style fields also set the link, the
+// colors and the font FreeMind shows.
+func encodeFreeMind(mindmap *model.Mindmap, _ model.DisplayStyle) ([]byte, error) {
+	var stack []*freemindNode // The last node of each depth
+	var top *freemindNode
+	for depth, node := range mindmap.Walk(nil, nil) {
+		fm := freemindExportNode(node)
+		stack = append(stack[:depth], fm)
+		if depth == 0 {
+			top = fm
+			continue
+		}
+		parent := stack[depth-1]
+		parent.Children = append(parent.Children, fm)
+	}
+	if top == nil {
+		return nil, errors.New("mindmap has no nodes")
+	}
+
+	document := struct {
+		XMLName xml.Name      `xml:"map"`
+		Version string        `xml:"version,attr"`
+		Root    *freemindNode `xml:"node"`
+	}{Version: freemindVersion, Root: top}
+	out, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode FreeMind file: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// freemindExportNode returns the FreeMind node of a node, without its children
+func freemindExportNode(node *model.Node) *freemindNode {
+	fm := &freemindNode{
+		Text:     node.Name,
+		ID:       "ID_" + strconv.Itoa(node.ID),
+		Created:  node.Created.UnixMilli(),
+		Modified: node.Updated.UnixMilli(),
+	}
+	if url := node.Content[urlField]; strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		fm.Link = url
+	}
+	fm.Color = node.Content[model.StyleColorField]
+	fm.Background = node.Content[model.StyleFillField]
+	if fontStyle := strings.Fields(node.Content[model.StyleFontField]); len(fontStyle) > 0 {
+		fm.Font = &freemindFont{Name: "SansSerif", Size: "12"}
+		if slices.Contains(fontStyle, model.StyleBold) {
+			fm.Font.Bold = "true"
+		}
+		if slices.Contains(fontStyle, model.StyleItalic) {
+			fm.Font.Italic = "true"
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(node.Content)) {
+		fm.Attributes = append(fm.Attributes, freemindAttribute{Name: key, Value: node.Content[key]})
+	}
+	return fm
+}
+
+// decodeFreeMind reads a FreeMind or Freeplane file. Its root node is the root, naming the mindmap if the name is
+// valid. Node attributes become fields, and notes, web links, icons, colors, the shape and the font style become
+// the notes, url, markers and style fields unless an attribute of the same name is set. Arrow links, clouds,
+// plugin hooks and the node details of Freeplane are reported as not imported.
+func decodeFreeMind(data []byte, name string) (*model.Mindmap, []string, error) {
+	var document struct {
+		XMLName xml.Name      `xml:"map"`
+		Root    *freemindNode `xml:"node"`
+	}
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, nil, fmt.Errorf("invalid FreeMind file: %w", err)
+	}
+	if document.Root == nil {
+		return nil, nil, errors.New("FreeMind file has no root node")
+	}
+
+	unmapped := make(unmappedFeatures)
+	var convert func(n *freemindNode) *importedTopic
+	convert = func(n *freemindNode) *importedTopic {
+		topic := newImportedTopic(n.Text)
+		for _, attribute := range n.Attributes {
+			if label := strings.TrimSpace(attribute.Name); label != "" {
+				topic.setField(label, attribute.Value)
+			}
+		}
+		defaults := make(map[string]string)
+		for _, rich := range n.Rich {
+			switch strings.ToUpper(rich.Type) {
+			case "NODE":
+				if topic.title == "" {
+					topic.title = xmlPlainText(rich.HTML)
+				}
+			case "NOTE":
+				defaults[notesField] = xmlPlainText(rich.HTML)
+			default:
+				unmapped.add("node details", 1)
+			}
+		}
+		if strings.HasPrefix(n.Link, "http://") || strings.HasPrefix(n.Link, "https://") {
+			defaults[urlField] = n.Link
+		} else if n.Link != "" {
+			unmapped.add("internal links", 1)
+		}
+		var icons []string
+		for _, icon := range n.Icons {
+			icons = append(icons, icon.Builtin)
+		}
+		defaults[markersField] = strings.Join(icons, ", ")
+		freemindStyle(defaults, n, unmapped)
+		for key, value := range defaults {
+			if _, ok := topic.content[key]; !ok {
+				topic.setField(key, value)
+			}
+		}
+
+		unmapped.add("arrow links", len(n.ArrowLinks))
+		unmapped.add("clouds", len(n.Clouds))
+		unmapped.add("plugin hooks", len(n.Hooks))
+		for _, child := range n.Children {
+			topic.children = append(topic.children, convert(child))
+		}
+		return topic
+	}
+
+	top := convert(document.Root)
+	var warnings []string
+	mindmapName := name
+	if title := strings.Join(strings.Fields(top.title), " "); title != "" {
+		if err := names.Validate(names.Mindmap, title); err == nil {
+			mindmapName = title
+		} else {
+			warnings = append(warnings, fmt.Sprintf("the root '%s' is not a valid mindmap name, the mindmap is named after the file", title))
+		}
+	}
+	mindmap := topicsMindmap(mindmapName, top.children)
+	if len(top.content) > 0 {
+		mindmap.Root.Content = top.content
+	}
+	return mindmap, append(warnings, unmapped.warnings("FreeMind")...), nil
+}
+
+// freemindStyle sets the style fields of a FreeMind node in fields, from its colors, shape and font style
+func freemindStyle(fields map[string]string, n *freemindNode, unmapped unmappedFeatures) {
+	for field, value := range map[string]string{model.StyleColorField: n.Color, model.StyleFillField: n.Background} {
+		if value == "" {
+			continue
+		}
+		if color, ok := importColor(value, false); ok {
+			fields[field] = color
+		} else {
+			unmapped.add("colors", 1)
+		}
+	}
+	if n.Style != "" {
+		fields[model.StyleShapeField] = strings.ToLower(n.Style)
+	}
+	if n.Font == nil {
+		return
+	}
+	var font []string
+	if n.Font.Bold == "true" {
+		font = append(font, model.StyleBold)
+	}
+	if n.Font.Italic == "true" {
+		font = append(font, model.StyleItalic)
+	}
+	fields[model.StyleFontField] = strings.Join(font, " ")
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+func init() {
+	mustRegisterImportFormat(model.ImportFormat{Name: "html", Description: "Browser bookmarks exported as HTML", Decode: decodeBookmarks})
+}
+
+// decodeBookmarks reads browser bookmarks exported in the Netscape bookmark HTML format. Folders become nodes
+// and bookmarks become leaves with the url and, if present, the description as content fields.
+// The format is loose HTML with unclosed <DT> and <p> elements, so only the elements carrying data are
+// interpreted: <H3> folder titles, <A> bookmarks, <DD> descriptions and the <DL> lists nesting the folders.
+func decodeBookmarks(data []byte, name string) (*model.Mindmap, []string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	decoder.Strict = false
+	decoder.AutoClose = append(xml.HTMLAutoClose, "p", "dt", "dd")
+	decoder.Entity = xml.HTMLEntity
+
+	top := newImportedTopic(name)
+	folders := []*importedTopic{top} // Folders of the open lists, the top-level items at the bottom
+	var folder, last *importedTopic  // Folder waiting for its list, item a description belongs to
+	var text *strings.Builder        // Text of the element being read, nil outside of titles and descriptions
+	var finish func(string)          // Stores the text once the element ends
+	flush := func() {
+		if text != nil {
+			finish(strings.TrimSpace(text.String()))
+			text = nil
+		}
+	}
+	found := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid bookmark file: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			// Descriptions are not closed, they end with the next element
+			flush()
+			parent := folders[len(folders)-1]
+			switch strings.ToLower(t.Name.Local) {
+			case "h3":
+				found = true
+				item := newImportedTopic("")
+				parent.children = append(parent.children, item)
+				folder, last = item, item
+				text, finish = &strings.Builder{}, func(s string) { item.title = s }
+			case "a":
+				found = true
+				item := newImportedTopic("")
+				item.setField(urlField, xmlAttr(t, "href"))
+				parent.children = append(parent.children, item)
+				folder, last = nil, item
+				text, finish = &strings.Builder{}, func(s string) {
+					item.title = s
+					if item.title == "" {
+						item.title = item.content[urlField]
+					}
+				}
+			case "dd":
+				if last != nil {
+					item := last
+					text, finish = &strings.Builder{}, func(s string) { item.setField(descriptionField, s) }
+				}
+			case "dl":
+				// The list following a folder title holds the items of the folder, other lists those of the parent
+				if folder != nil {
+					parent = folder
+				}
+				folders = append(folders, parent)
+				folder, last = nil, nil
+			}
+		case xml.EndElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "h3", "a":
+				flush()
+			case "dl":
+				flush()
+				if len(folders) > 1 {
+					folders = folders[:len(folders)-1]
+				}
+				folder, last = nil, nil
+			}
+		case xml.CharData:
+			if text != nil {
+				text.Write(t)
+			}
+		}
+	}
+	flush()
+	if !found {
+		return nil, nil, errors.New("no bookmarks or folders found, not a bookmark file")
+	}
+	return topicsMindmap(name, top.children), nil, nil
+}
+
+// xmlAttr returns the value of an attribute of an element, matching its name case-insensitively
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if strings.EqualFold(attr.Name.Local, name) {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,432 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+func init() {
+	mustRegisterImportFormat(model.ImportFormat{Name: "xmind", Description: "XMind file", Decode: decodeXMind})
+	mustRegisterImportFormat(model.ImportFormat{Name: "mmap", Description: "MindManager file", Decode: decodeMindManager})
+}
+
+// Content fields of nodes imported from other tools
+const (
+	notesField       = "notes"
+	markersField     = "markers"
+	urlField         = "url"
+	descriptionField = "description"
+	labelsField      = "labels"
+)
+
+// importedTopic is a topic read from the file of another tool, with the content fields of its node
+type importedTopic struct {
+	title    string
+	content  map[string]string
+	children []*importedTopic
+}
+
+// newImportedTopic returns a topic with the given title and no content
+func newImportedTopic(title string) *importedTopic {
+	return &importedTopic{title: title, content: make(map[string]string)}
+}
+
+// setField sets a content field of the topic, ignoring empty values
+func (t *importedTopic) setField(key, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		t.content[key] = value
+	}
+}
+
+// topicsMindmap builds a mindmap from imported top-level topics, such as the central topic of a mindmap file.
+// Node IDs and indexes follow document order; the IDs are replaced when the nodes are stored.
+func topicsMindmap(name string, topics []*importedTopic) *model.Mindmap {
+	now := time.Now()
+	root := &model.Node{ID: 0, ParentID: -1, Name: name, Index: "0", Created: now, Updated: now}
+	mindmap := &model.Mindmap{Name: name, Root: root, Nodes: map[int]*model.Node{0: root}, Created: now, Updated: now}
+
+	nextID := 1
+	var add func(topic *importedTopic, parent *model.Node, position int)
+	add = func(topic *importedTopic, parent *model.Node, position int) {
+		index := strconv.Itoa(position)
+		if parent.ID != 0 {
+			index = parent.Index + "." + index
+		}
+		node := &model.Node{
+			ID:       nextID,
+			ParentID: parent.ID,
+			Name:     strings.Join(strings.Fields(topic.title), " "),
+			Index:    index,
+			Content:  topic.content,
+			Created:  now,
+			Updated:  now,
+		}
+		if node.Name == "" {
+			node.Name = "(untitled)"
+		}
+		nextID++
+		mindmap.Nodes[node.ID] = node
+		parent.Children = append(parent.Children, node)
+
+		for i, child := range topic.children {
+			add(child, node, i+1)
+		}
+	}
+	for i, topic := range topics {
+		add(topic, root, i+1)
+	}
+
+	return mindmap
+}
+
+// zipEntry returns the data of a file in a zip archive
+func zipEntry(data []byte, name string) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a zip archive: %w", err)
+	}
+	f, err := archive.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("archive has no %s: %w", name, err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// xmindTopic is a topic of the content.json of XMind files
+type xmindTopic struct {
+	Title string `json:"title"`
+	Href  string `json:"href"`
+	Notes struct {
+		Plain struct {
+			Content string `json:"content"`
+		} `json:"plain"`
+	} `json:"notes"`
+	Markers []struct {
+		MarkerID string `json:"markerId"`
+	} `json:"markers"`
+	Labels []string `json:"labels"`
+	Style  struct {
+		Properties map[string]string `json:"properties"`
+	} `json:"style"`
+	Image      json.RawMessage   `json:"image"`
+	Boundaries []json.RawMessage `json:"boundaries"`
+	Summaries  []json.RawMessage `json:"summaries"`
+	Children   struct {
+		Attached []xmindTopic `json:"attached"`
+		Detached []xmindTopic `json:"detached"`
+	} `json:"children"`
+}
+
+// decodeXMind reads the first sheet of an XMind file. Files of XMind 8 and older, which have no content.json,
+// are not supported. Web links become the url field, labels the labels field and the text and fill colors, font
+// and shape of the topics the style fields; images, boundaries, summaries, relationships, attachments and the
+// other sheets are reported as not imported.
+func decodeXMind(data []byte, name string) (*model.Mindmap, []string, error) {
+	content, err := zipEntry(data, "content.json")
+	if err != nil {
+		return nil, nil, fmt.Errorf("not an XMind file or saved by XMind 8 or older: %w", err)
+	}
+
+	var sheets []struct {
+		RootTopic     *xmindTopic       `json:"rootTopic"`
+		Relationships []json.RawMessage `json:"relationships"`
+	}
+	if err := json.Unmarshal(content, &sheets); err != nil {
+		return nil, nil, fmt.Errorf("invalid XMind content: %w", err)
+	}
+	if len(sheets) == 0 || sheets[0].RootTopic == nil {
+		return nil, nil, errors.New("XMind file has no topics")
+	}
+
+	unmapped := make(unmappedFeatures)
+	unmapped.add("sheets", len(sheets)-1)
+	unmapped.add("relationships", len(sheets[0].Relationships))
+
+	var convert func(t xmindTopic) *importedTopic
+	convert = func(t xmindTopic) *importedTopic {
+		topic := newImportedTopic(t.Title)
+		topic.setField(notesField, t.Notes.Plain.Content)
+		var markers []string
+		for _, marker := range t.Markers {
+			markers = append(markers, marker.MarkerID)
+		}
+		topic.setField(markersField, strings.Join(markers, ", "))
+		topic.setField(labelsField, strings.Join(t.Labels, ", "))
+		if strings.HasPrefix(t.Href, "http://") || strings.HasPrefix(t.Href, "https://") {
+			topic.setField(urlField, t.Href)
+		} else if t.Href != "" {
+			unmapped.add("attachments and internal links", 1)
+		}
+		xmindStyle(topic, t.Style.Properties, unmapped)
+		if len(t.Image) > 0 && string(t.Image) != "null" {
+			unmapped.add("images", 1)
+		}
+		unmapped.add("boundaries", len(t.Boundaries))
+		unmapped.add("summaries", len(t.Summaries))
+		// Floating topics follow the attached ones so none are lost
+		for _, child := range append(t.Children.Attached, t.Children.Detached...) {
+			topic.children = append(topic.children, convert(child))
+		}
+		return topic
+	}
+	mindmap := topicsMindmap(name, []*importedTopic{convert(*sheets[0].RootTopic)})
+	return mindmap, unmapped.warnings("XMind"), nil
+}
+
+// xmindStyle sets the style fields of a topic from the style properties of an XMind topic
+func xmindStyle(topic *importedTopic, properties map[string]string, unmapped unmappedFeatures) {
+	var font []string
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		value := strings.TrimSpace(properties[key])
+		mapped := true
+		switch key {
+		case "fo:color", "svg:fill":
+			field := model.StyleColorField
+			if key == "svg:fill" {
+				field = model.StyleFillField
+			}
+			color, ok := importColor(value, false)
+			if ok {
+				topic.setField(field, color)
+			}
+			mapped = ok
+		case "shape-class":
+			topic.setField(model.StyleShapeField, xmindShape(value))
+		case "fo:font-weight":
+			if value == model.StyleBold || value == "600" || value == "700" || value == "800" || value == "900" {
+				font = append(font, model.StyleBold)
+			}
+		case "fo:font-style":
+			if value == model.StyleItalic {
+				font = append(font, model.StyleItalic)
+			}
+		default:
+			mapped = value == "" || value == "inherited"
+		}
+		if !mapped {
+			unmapped.add("style property "+key, 1)
+		}
+	}
+	topic.setField(model.StyleFontField, strings.Join(font, " "))
+}
+
+// xmindShape names an XMind topic shape class without its package, org.xmind.topicShape.roundedRect becomes
+// rounded-rect
+func xmindShape(class string) string {
+	class = class[strings.LastIndex(class, ".")+1:]
+	var b strings.Builder
+	for i, r := range class {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// mmapTopic is a topic of the Document.xml of MindManager files. Elements are matched in any namespace.
+type mmapTopic struct {
+	Text struct {
+		PlainText string `xml:"PlainText,attr"`
+		Font      struct {
+			Color  string `xml:"Color,attr"`
+			Bold   string `xml:"Bold,attr"`
+			Italic string `xml:"Italic,attr"`
+		} `xml:"Font"`
+	} `xml:"Text"`
+	Notes struct {
+		PreviewPlainText string `xml:"PreviewPlainText,attr"`
+		XHTML            string `xml:",innerxml"`
+	} `xml:"NotesGroup>NotesXhtmlData"`
+	Icons []struct {
+		IconType string `xml:"IconType,attr"`
+	} `xml:"IconsGroup>Icons>Icon"`
+	Task struct {
+		TaskPriority string `xml:"TaskPriority,attr"`
+	} `xml:"Task"`
+	Color struct {
+		FillColor string `xml:"FillColor,attr"`
+		LineColor string `xml:"LineColor,attr"`
+	} `xml:"Color"`
+	Shape struct {
+		SubTopicShape string `xml:"SubTopicShape,attr"`
+	} `xml:"SubTopicShape"`
+	Hyperlink struct {
+		URL string `xml:"Url,attr"`
+	} `xml:"Hyperlink"`
+	Images      []struct{}  `xml:"Image"`
+	Attachments []struct{}  `xml:"Attachments>Attachment"`
+	Callouts    []mmapTopic `xml:"Callouts>Topic"`
+	SubTopics   []mmapTopic `xml:"SubTopics>Topic"`
+	Floating    []mmapTopic `xml:"FloatingTopics>Topic"`
+}
+
+// decodeMindManager reads the central topic of a MindManager file. Icons and task priorities become the markers
+// field, web links the url field and the text and fill colors, font and shape of the topics the style fields;
+// line colors, images, attachments, callouts, boundaries and relationships are reported as not imported.
+func decodeMindManager(data []byte, name string) (*model.Mindmap, []string, error) {
+	content, err := zipEntry(data, "Document.xml")
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a MindManager file: %w", err)
+	}
+
+	var document struct {
+		Central       *mmapTopic `xml:"OneTopic>Topic"`
+		Relationships []struct{} `xml:"Relationships>Relationship"`
+		Boundaries    []struct{} `xml:"Boundaries>Boundary"`
+	}
+	if err := xml.Unmarshal(content, &document); err != nil {
+		return nil, nil, fmt.Errorf("invalid MindManager document: %w", err)
+	}
+	if document.Central == nil {
+		return nil, nil, errors.New("MindManager file has no central topic")
+	}
+
+	unmapped := make(unmappedFeatures)
+	unmapped.add("relationships", len(document.Relationships))
+	unmapped.add("boundaries", len(document.Boundaries))
+
+	var convert func(t mmapTopic) *importedTopic
+	convert = func(t mmapTopic) *importedTopic {
+		topic := newImportedTopic(t.Text.PlainText)
+		notes := t.Notes.PreviewPlainText
+		if notes == "" {
+			notes = xmlPlainText(t.Notes.XHTML)
+		}
+		topic.setField(notesField, notes)
+		var markers []string
+		if t.Task.TaskPriority != "" {
+			markers = append(markers, mmapMarker(t.Task.TaskPriority))
+		}
+		for _, icon := range t.Icons {
+			markers = append(markers, mmapMarker(icon.IconType))
+		}
+		topic.setField(markersField, strings.Join(markers, ", "))
+		if url := t.Hyperlink.URL; strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+			topic.setField(urlField, url)
+		} else if url != "" {
+			unmapped.add("attachments and internal links", 1)
+		}
+		mmapStyle(topic, t, unmapped)
+		unmapped.add("images", len(t.Images))
+		unmapped.add("attachments and internal links", len(t.Attachments))
+		unmapped.add("callouts", len(t.Callouts))
+		for _, child := range append(t.SubTopics, t.Floating...) {
+			topic.children = append(topic.children, convert(child))
+		}
+		return topic
+	}
+	mindmap := topicsMindmap(name, []*importedTopic{convert(*document.Central)})
+	return mindmap, unmapped.warnings("MindManager"), nil
+}
+
+// mmapStyle sets the style fields of a topic from the colors, font and shape of a MindManager topic
+func mmapStyle(topic *importedTopic, t mmapTopic, unmapped unmappedFeatures) {
+	for field, value := range map[string]string{model.StyleColorField: t.Text.Font.Color, model.StyleFillField: t.Color.FillColor} {
+		if value == "" {
+			continue
+		}
+		if color, ok := importColor(value, true); ok {
+			topic.setField(field, color)
+		} else {
+			unmapped.add("colors", 1)
+		}
+	}
+	if t.Color.LineColor != "" {
+		unmapped.add("line colors", 1)
+	}
+
+	var font []string
+	if t.Text.Font.Bold == "true" {
+		font = append(font, model.StyleBold)
+	}
+	if t.Text.Font.Italic == "true" {
+		font = append(font, model.StyleItalic)
+	}
+	topic.setField(model.StyleFontField, strings.Join(font, " "))
+	if t.Shape.SubTopicShape != "" {
+		topic.setField(model.StyleShapeField, xmindShape(mmapMarker(t.Shape.SubTopicShape)))
+	}
+}
+
+// importColor converts a color of another tool to the #rrggbb of the style fields, dropping the alpha channel of
+// #rrggbbaa colors, or of aarrggbb colors if alphaFirst is set as in MindManager files
+func importColor(color string, alphaFirst bool) (string, bool) {
+	hex := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(color), "#"))
+	if len(hex) == 8 {
+		if alphaFirst {
+			hex = hex[2:]
+		} else {
+			hex = hex[:6]
+		}
+	}
+	color = "#" + hex
+	if model.ParseStyle(model.StyleColorField, color) != nil {
+		return "", false
+	}
+	return color, true
+}
+
+// unmappedFeatures counts the features of an imported file that have no counterpart in a mindmap, by name
+type unmappedFeatures map[string]int
+
+// add counts occurrences of a feature
+func (u unmappedFeatures) add(feature string, count int) {
+	if count > 0 {
+		u[feature] += count
+	}
+}
+
+// warnings reports the features not imported from the file of a tool, none if all were imported
+func (u unmappedFeatures) warnings(tool string) []string {
+	if len(u) == 0 {
+		return nil
+	}
+	var parts []string
+	for _, feature := range slices.Sorted(maps.Keys(u)) {
+		parts = append(parts, fmt.Sprintf("%s (%d)", feature, u[feature]))
+	}
+	return []string{fmt.Sprintf("%s features not imported: %s", tool, strings.Join(parts, ", "))}
+}
+
+// mmapMarker strips the URN prefix of MindManager icon and priority types, urn:mindjet:Prio1 becomes Prio1
+func mmapMarker(urn string) string {
+	return urn[strings.LastIndex(urn, ":")+1:]
+}
+
+// xmlPlainText returns the character data of an XML fragment, with a line break after each block element
+func xmlPlainText(fragment string) string {
+	var b strings.Builder
+	decoder := xml.NewDecoder(strings.NewReader(fragment))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			b.Write(t)
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p", "div", "br", "li":
+				b.WriteString("\n")
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
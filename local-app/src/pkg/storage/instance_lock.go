@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrInstanceLocked is returned when another running instance holds the lock of a database
+var ErrInstanceLocked = errors.New("database is in use by another Mindnoscape instance")
+
+// InstanceLock is the exclusive lock of a database file, held by one running instance at a time so that two
+// instances never write the same database. It is a lock file next to the database holding the PID of its owner.
+type InstanceLock struct {
+	file *os.File
+	path string
+}
+
+// LockInstance takes the lock of the database at dbPath, failing with ErrInstanceLocked if another instance holds it
+func LockInstance(dbPath string) (*InstanceLock, error) {
+	path := dbPath + ".lock"
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file '%s': %w", path, err)
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		if !errors.Is(err, ErrInstanceLocked) {
+			return nil, fmt.Errorf("failed to lock '%s': %w", path, err)
+		}
+		if pid := lockOwner(path); pid != 0 {
+			return nil, fmt.Errorf("%w (PID %d), close it before starting another or use another database_dir", err, pid)
+		}
+		return nil, fmt.Errorf("%w, close it before starting another or use another database_dir", err)
+	}
+
+	// Record the owner for the message of the next instance
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &InstanceLock{file: file, path: path}, nil
+}
+
+// Release releases the lock. The lock file is kept, as removing it would let an instance locking the removed file
+// and one creating a new file run at the same time.
+func (l *InstanceLock) Release() error {
+	l.file.Truncate(0)
+	if err := unlockFile(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("failed to unlock '%s': %w", l.path, err)
+	}
+	return l.file.Close()
+}
+
+// lockOwner returns the PID recorded in a lock file, or 0 if unknown
+func lockOwner(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
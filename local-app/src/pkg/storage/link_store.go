@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// LinkStore defines the interface for cross-link storage operations.
+type LinkStore interface {
+	LinkAdd(link model.Link) (int, error)
+	LinkGet(linkInfo model.Link, linkFilter model.LinkFilter) ([]*model.Link, error)
+	LinkDelete(linkInfo model.Link, linkFilter model.LinkFilter) (int, error)
+}
+
+// LinkStorage implements the LinkStore interface.
+type LinkStorage struct {
+	storage *Storage
+	logger  *log.Logger
+}
+
+// NewLinkStorage creates a new LinkStorage instance.
+func NewLinkStorage(storage *Storage) *LinkStorage {
+	return &LinkStorage{
+		storage: storage,
+		logger:  storage.logger,
+	}
+}
+
+// LinkAdd adds a link to the database, adding an existing link is not an error.
+func (s *LinkStorage) LinkAdd(link model.Link) (int, error) {
+	s.logger.Debug(context.Background(), "Adding link", log.Fields{"link": link})
+
+	db := s.storage.GetDatabase()
+	result, err := db.Exec(
+		"INSERT OR IGNORE INTO node_links (source_mindmap_id, source_id, target_mindmap_id, target_id, link_type, created) VALUES (?, ?, ?, ?, ?, ?)",
+		link.SourceMindmapID, link.SourceID, link.TargetMindmapID, link.TargetID, link.Type, time.Now(),
+	)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to add link", log.Fields{"error": err})
+		return 0, fmt.Errorf("failed to add link: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to get last insert ID", log.Fields{"error": err})
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// LinkGet retrieves the links matching the provided info and filter.
+func (s *LinkStorage) LinkGet(linkInfo model.Link, linkFilter model.LinkFilter) ([]*model.Link, error) {
+	s.logger.Debug(context.Background(), "Retrieving links", log.Fields{"filter": linkFilter})
+
+	where, args := linkConditions(linkInfo, linkFilter)
+	query := "SELECT id, source_mindmap_id, source_id, target_mindmap_id, target_id, link_type, created FROM node_links WHERE " + where + " ORDER BY id"
+
+	db := s.storage.GetDatabase()
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query links", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to query links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*model.Link
+	for rows.Next() {
+		var l model.Link
+		if err := rows.Scan(&l.ID, &l.SourceMindmapID, &l.SourceID, &l.TargetMindmapID, &l.TargetID, &l.Type, &l.Created); err != nil {
+			s.logger.Error(context.Background(), "Failed to scan link row", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to scan link row: %w", err)
+		}
+		links = append(links, &l)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Error iterating link rows", log.Fields{"error": err})
+		return nil, fmt.Errorf("error iterating link rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// LinkDelete removes the links matching the provided info and filter and returns the number removed.
+// A filter without any field set is rejected so all links are never removed by accident.
+func (s *LinkStorage) LinkDelete(linkInfo model.Link, linkFilter model.LinkFilter) (int, error) {
+	s.logger.Debug(context.Background(), "Deleting links", log.Fields{"filter": linkFilter})
+
+	if linkFilter == (model.LinkFilter{}) {
+		return 0, fmt.Errorf("link filter not specified")
+	}
+
+	where, args := linkConditions(linkInfo, linkFilter)
+	db := s.storage.GetDatabase()
+	result, err := db.Exec("DELETE FROM node_links WHERE "+where, args...)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to delete links", log.Fields{"error": err})
+		return 0, fmt.Errorf("failed to delete links: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted link count: %w", err)
+	}
+	return int(count), nil
+}
+
+// linkConditions builds the WHERE clause and arguments of a link query from the provided info and filter
+func linkConditions(linkInfo model.Link, linkFilter model.LinkFilter) (string, []interface{}) {
+	conditions := []string{"1=1"}
+	var args []interface{}
+
+	if linkFilter.ID {
+		conditions = append(conditions, "id = ?")
+		args = append(args, linkInfo.ID)
+	}
+	if linkFilter.SourceMindmapID {
+		conditions = append(conditions, "source_mindmap_id = ?")
+		args = append(args, linkInfo.SourceMindmapID)
+	}
+	if linkFilter.SourceID {
+		conditions = append(conditions, "source_id = ?")
+		args = append(args, linkInfo.SourceID)
+	}
+	if linkFilter.TargetMindmapID {
+		conditions = append(conditions, "target_mindmap_id = ?")
+		args = append(args, linkInfo.TargetMindmapID)
+	}
+	if linkFilter.TargetID {
+		conditions = append(conditions, "target_id = ?")
+		args = append(args, linkInfo.TargetID)
+	}
+	if linkFilter.Type {
+		conditions = append(conditions, "link_type = ?")
+		args = append(args, linkInfo.Type)
+	}
+	return strings.Join(conditions, " AND "), args
+}
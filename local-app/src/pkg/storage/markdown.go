@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+func init() {
+	mustRegisterExportFormat(model.ExportFormat{
+		Name:        "markdown",
+		Extension:   "md",
+		Description: "Markdown outline of nested bullet lists with the fields as [label:: value] annotations, as read by Obsidian",
+		Subtree:     true,
+		Encode:      encodeMarkdown,
+	})
+	mustRegisterImportFormat(model.ImportFormat{
+		Name:        "markdown",
+		Extensions:  []string{"md"},
+		Description: "Markdown notes, such as of Obsidian: headings and nested bullet lists by depth, [label:: value] annotations as fields",
+		Decode:      decodeMarkdown,
+	})
+}
+
+var (
+	// markdownFieldPattern matches the start of an inline field, [label:: value]
+	markdownFieldPattern = regexp.MustCompile(`\[([\p{L}_][\p{L}\p{N}\p{M}_.-]*)::[ \t]*`)
+	// markdownFieldLinePattern matches a line of a single field, label:: value
+	markdownFieldLinePattern = regexp.MustCompile(`^([\p{L}_][\p{L}\p{N}\p{M}_.-]*)::[ \t]*(.*)$`)
+	markdownHeadingPattern   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	markdownBulletPattern    = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d{1,9}[.)])[ \t]+(.*)$`)
+)
+
+// markdownTabWidth is the indentation of a tab in nested lists
+const markdownTabWidth = 4
+
+// encodeMarkdown renders a mindmap as a Markdown outline: the root is the title heading and the other nodes are
+// nested bullet lists, indented by two spaces per level. The fields of a node follow its name as inline fields,
+// [label:: value], escaping backslashes, closing brackets and line breaks of the values with backslashes.
+func encodeMarkdown(mindmap *model.Mindmap, _ model.DisplayStyle) ([]byte, error) {
+	var b strings.Builder
+	for depth, node := range mindmap.Walk(nil, nil) {
+		if depth == 0 {
+			b.WriteString("# " + markdownNode(node) + "\n\n")
+			continue
+		}
+		b.WriteString(strings.Repeat("  ", depth-1) + "- " + markdownNode(node) + "\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// markdownNode returns the name of a node followed by its fields as inline fields
+func markdownNode(node *model.Node) string {
+	escaper := strings.NewReplacer(`\`, `\\`, "]", `\]`, "\r", "", "\n", `\n`)
+	text := strings.Join(strings.Fields(node.Name), " ")
+	for _, key := range slices.Sorted(maps.Keys(node.Content)) {
+		text += fmt.Sprintf(" [%s:: %s]", key, escaper.Replace(node.Content[key]))
+	}
+	return text
+}
+
+// markdownItem is an open heading or list item of a Markdown file, which the following deeper items nest under
+type markdownItem struct {
+	level int // Heading level, or indentation of a list item
+	topic *importedTopic
+}
+
+// decodeMarkdown reads Markdown notes as a mindmap. Headings nest by level and list items by indentation, below
+// the last heading. If the only level 1 heading starts the file, it is the root, naming the mindmap. Inline fields,
+// [label:: value], become fields of their node and the other lines below a heading or list item, such as
+// paragraphs, code blocks and label:: value lines, its notes and fields. YAML front matter is left out.
+func decodeMarkdown(data []byte, name string) (*model.Mindmap, []string, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	var warnings []string
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if end := strings.TrimSpace(lines[i]); end == "---" || end == "..." {
+				lines = lines[i+1:]
+				warnings = append(warnings, "the YAML front matter was not imported")
+				break
+			}
+		}
+	}
+
+	top := newImportedTopic(name)
+	titled := markdownTitled(lines)
+	headings := []markdownItem{{topic: top}} // Open headings, the top at the bottom
+	var items []markdownItem                 // Open list items below the last heading
+	last := top                              // Topic the other lines belong to
+	fence := ""                              // Marker of the open code block, empty outside code blocks
+	found := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			last.addNote(line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if trimmed == "" || markdownBreak(trimmed) {
+			continue
+		}
+		// A line other than a list item belongs to the list item it is indented under, and ends the list unindented
+		if len(items) > 0 && !markdownBulletPattern.MatchString(line) {
+			indent := markdownIndent(line[:len(line)-len(strings.TrimLeft(line, " \t"))])
+			for len(items) > 0 && items[len(items)-1].level >= indent {
+				items = items[:len(items)-1]
+			}
+			last = headings[len(headings)-1].topic
+			if len(items) > 0 {
+				last = items[len(items)-1].topic
+			}
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			last.addNote(line)
+			continue
+		}
+
+		if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil {
+			level := len(match[1])
+			if titled && level == 1 {
+				top.title, top.content = markdownFields(match[2])
+				titled, last = false, top
+				continue
+			}
+			for len(headings) > 1 && headings[len(headings)-1].level >= level {
+				headings = headings[:len(headings)-1]
+			}
+			item := markdownTopic(match[2])
+			parent := headings[len(headings)-1].topic
+			parent.children = append(parent.children, item)
+			headings = append(headings, markdownItem{level: level, topic: item})
+			items, last, found = nil, item, true
+			continue
+		}
+
+		if match := markdownBulletPattern.FindStringSubmatch(line); match != nil {
+			indent := markdownIndent(match[1])
+			for len(items) > 0 && items[len(items)-1].level >= indent {
+				items = items[:len(items)-1]
+			}
+			parent := headings[len(headings)-1].topic
+			if len(items) > 0 {
+				parent = items[len(items)-1].topic
+			}
+			item := markdownTopic(match[2])
+			parent.children = append(parent.children, item)
+			items = append(items, markdownItem{level: indent, topic: item})
+			last, found = item, true
+			continue
+		}
+
+		if match := markdownFieldLinePattern.FindStringSubmatch(trimmed); match != nil {
+			last.setField(match[1], match[2])
+			continue
+		}
+		last.addNote(trimmed)
+	}
+
+	if !found {
+		return nil, nil, errors.New("no headings or list items found, not a Markdown outline")
+	}
+	mindmapName := name
+	if top.title != "" {
+		if err := names.Validate(names.Mindmap, top.title); err == nil {
+			mindmapName = top.title
+		} else {
+			warnings = append(warnings, fmt.Sprintf("the title '%s' is not a valid mindmap name, the mindmap is named after the file", top.title))
+		}
+	}
+	mindmap := topicsMindmap(mindmapName, top.children)
+	if len(top.content) > 0 {
+		mindmap.Root.Content = top.content
+	}
+	return mindmap, warnings, nil
+}
+
+// markdownTitled reports whether the first line of text is the only level 1 heading, outside code blocks
+func markdownTitled(lines []string) bool {
+	count, fence := 0, ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if trimmed == "" {
+			continue
+		}
+		if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil && len(match[1]) == 1 {
+			count++
+		} else if count == 0 {
+			return false
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+		}
+	}
+	return count == 1
+}
+
+// markdownIndent returns the width of the indentation of a line, counting tabs as markdownTabWidth spaces
+func markdownIndent(indentation string) int {
+	return len(strings.ReplaceAll(indentation, "\t", strings.Repeat(" ", markdownTabWidth)))
+}
+
+// markdownBreak reports whether a line is a thematic break, such as --- or * * *
+func markdownBreak(line string) bool {
+	line = strings.ReplaceAll(strings.ReplaceAll(line, " ", ""), "\t", "")
+	return len(line) >= 3 && strings.Trim(line, line[:1]) == "" && strings.Contains("-*_", line[:1])
+}
+
+// markdownTopic returns a topic of a heading or list item, with the inline fields of its text
+func markdownTopic(text string) *importedTopic {
+	topic := newImportedTopic("")
+	topic.title, topic.content = markdownFields(text)
+	return topic
+}
+
+// markdownFields splits the inline fields, [label:: value], out of the text of a heading or list item, returning
+// the text left and the fields
+func markdownFields(text string) (string, map[string]string) {
+	content := make(map[string]string)
+	var rest strings.Builder
+	for {
+		loc := markdownFieldPattern.FindStringSubmatchIndex(text)
+		if loc == nil {
+			rest.WriteString(text)
+			break
+		}
+		value, after, ok := markdownFieldValue(text[loc[1]:])
+		if !ok {
+			rest.WriteString(text)
+			break
+		}
+		rest.WriteString(text[:loc[0]])
+		if value != "" {
+			content[text[loc[2]:loc[3]]] = value
+		}
+		text = after
+	}
+	return strings.Join(strings.Fields(rest.String()), " "), content
+}
+
+// markdownFieldValue reads the value of an inline field up to its closing bracket, undoing the escapes of
+// backslashes, closing brackets and line breaks. ok is false if the field is not closed.
+func markdownFieldValue(text string) (value, rest string, ok bool) {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte(`\]n`, text[i+1]) >= 0:
+			i++
+			if text[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(text[i])
+			}
+		case c == ']':
+			return strings.TrimSpace(b.String()), text[i+1:], true
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+// addNote appends a line to the notes field of the topic
+func (t *importedTopic) addNote(line string) {
+	if notes, ok := t.content[notesField]; ok {
+		t.content[notesField] = notes + "\n" + line
+	} else {
+		t.content[notesField] = line
+	}
+}
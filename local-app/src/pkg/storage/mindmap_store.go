@@ -2,7 +2,10 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"mindnoscape/local-app/src/pkg/log"
@@ -15,8 +18,13 @@ type MindmapStore interface {
 	MindmapGet(user *model.User, mindmapInfo model.MindmapInfo, mindmapFilter model.MindmapFilter) ([]*model.Mindmap, error)
 	MindmapUpdate(mindmap *model.Mindmap, mindmapUpdateInfo model.MindmapInfo, mindmapFilter model.MindmapFilter) error
 	MindmapDelete(mindmap *model.Mindmap) error
+	MindmapRevision(mindmapID int) (int, error)
+	MindmapRevise(mindmapID, revision int) (int, error)
 }
 
+// ErrMindmapChanged is returned by MindmapRevise when the mindmap was changed since the revision it was given
+var ErrMindmapChanged = errors.New("mindmap was changed by another session")
+
 // MindmapStorage implements the MindmapStore interface.
 type MindmapStorage struct {
 	storage *Storage
@@ -48,46 +56,33 @@ func (s *MindmapStorage) MindmapAdd(user *model.User, newMindmap model.MindmapIn
 
 	db := s.storage.GetDatabase()
 
-	// Start a transaction
-	err = db.Begin()
-	if err != nil {
-		s.logger.Error(context.Background(), "Failed to begin transaction", log.Fields{"error": err})
-		return 0, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer func() {
+	// Insert the new mindmap and create its tables in a single transaction
+	var id int64
+	err = db.Batch(func() error {
+		now := time.Now()
+		result, err := db.Exec(
+			"INSERT INTO mindmaps (mindmap_name, mindmap_name_key, owner, is_public, created, updated) VALUES (?, ?, ?, ?, ?, ?)",
+			newMindmap.Name, s.storage.nameKey(newMindmap.Name), user.Username, newMindmap.IsPublic, now, now,
+		)
 		if err != nil {
-			_ = db.Rollback()
+			s.logger.Error(context.Background(), "Failed to add mindmap", log.Fields{"error": err, "username": user.Username, "mindmapName": newMindmap.Name})
+			return fmt.Errorf("failed to add mindmap: %w", err)
 		}
-	}()
-
-	// Insert the new mindmap
-	now := time.Now()
-	result, err := db.Exec(
-		"INSERT INTO mindmaps (mindmap_name, owner, is_public, created, updated) VALUES (?, ?, ?, ?, ?)",
-		newMindmap.Name, user.Username, newMindmap.IsPublic, now, now,
-	)
-	if err != nil {
-		s.logger.Error(context.Background(), "Failed to add mindmap", log.Fields{"error": err, "username": user.Username, "mindmapName": newMindmap.Name})
-		return 0, fmt.Errorf("failed to add mindmap: %w", err)
-	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		s.logger.Error(context.Background(), "Failed to get last insert ID", log.Fields{"error": err})
-		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
-	}
+		id, err = result.LastInsertId()
+		if err != nil {
+			s.logger.Error(context.Background(), "Failed to get last insert ID", log.Fields{"error": err})
+			return fmt.Errorf("failed to get last insert ID: %w", err)
+		}
 
-	// Create tables for the new mindmap within the transaction
-	err = db.CreateMindmapTables(int(id))
+		if err := db.CreateMindmapTables(int(id)); err != nil {
+			s.logger.Error(context.Background(), "Failed to create tables for mindmap", log.Fields{"error": err, "mindmapID": id})
+			return fmt.Errorf("failed to create tables for mindmap %d: %w", id, err)
+		}
+		return nil
+	})
 	if err != nil {
-		s.logger.Error(context.Background(), "Failed to create tables for mindmap", log.Fields{"error": err, "mindmapID": id})
-		return 0, fmt.Errorf("failed to create tables for mindmap %d: %w", id, err)
-	}
-
-	// Commit the transaction
-	if err := db.Commit(); err != nil {
-		s.logger.Error(context.Background(), "Failed to commit transaction", log.Fields{"error": err})
-		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+		return 0, err
 	}
 
 	s.logger.Info(context.Background(), "Mindmap added successfully", log.Fields{"mindmapID": id, "username": user.Username, "mindmapName": newMindmap.Name})
@@ -99,7 +94,7 @@ func (s *MindmapStorage) MindmapGet(user *model.User, mindmapInfo model.MindmapI
 	s.logger.Info(context.Background(), "Retrieving mindmaps", log.Fields{"username": user.Username, "filter": mindmapFilter})
 
 	db := s.storage.GetDatabase()
-	query := "SELECT id, mindmap_name, owner, is_public, created, updated FROM mindmaps WHERE 1=1"
+	query := "SELECT id, mindmap_name, owner, is_public, sort_spec, revision, created, updated FROM mindmaps WHERE 1=1"
 	var args []interface{}
 
 	if mindmapFilter.ID {
@@ -107,8 +102,8 @@ func (s *MindmapStorage) MindmapGet(user *model.User, mindmapInfo model.MindmapI
 		args = append(args, mindmapInfo.ID)
 	}
 	if mindmapFilter.Name {
-		query += " AND mindmap_name = ?"
-		args = append(args, mindmapInfo.Name)
+		query += " AND mindmap_name_key = ?"
+		args = append(args, s.storage.nameKey(mindmapInfo.Name))
 	}
 	if mindmapFilter.Owner {
 		query += " AND owner = ?"
@@ -129,11 +124,18 @@ func (s *MindmapStorage) MindmapGet(user *model.User, mindmapInfo model.MindmapI
 	var mindmaps []*model.Mindmap
 	for rows.Next() {
 		var m model.Mindmap
-		err := rows.Scan(&m.ID, &m.Name, &m.Owner, &m.IsPublic, &m.Created, &m.Updated)
+		var sortSpec string
+		err := rows.Scan(&m.ID, &m.Name, &m.Owner, &m.IsPublic, &sortSpec, &m.Revision, &m.Created, &m.Updated)
 		if err != nil {
 			s.logger.Error(context.Background(), "Failed to scan mindmap row", log.Fields{"error": err})
 			return nil, fmt.Errorf("failed to scan mindmap row: %w", err)
 		}
+		if sortSpec != "" {
+			// A sort that can't be parsed anymore falls back to index order rather than hiding the mindmap
+			if m.Sort, err = model.ParseSortSpec(strings.Fields(sortSpec)); err != nil {
+				s.logger.Warn(context.Background(), "Invalid stored mindmap sort", log.Fields{"error": err, "mindmapID": m.ID, "sort": sortSpec})
+			}
+		}
 		mindmaps = append(mindmaps, &m)
 	}
 
@@ -151,12 +153,12 @@ func (s *MindmapStorage) MindmapUpdate(mindmap *model.Mindmap, mindmapUpdateInfo
 	s.logger.Info(context.Background(), "Updating mindmap", log.Fields{"mindmapID": mindmap.ID, "filter": mindmapFilter})
 
 	db := s.storage.GetDatabase()
-	query := "UPDATE mindmaps SET updated = ? WHERE id = ?"
-	args := []interface{}{time.Now(), mindmap.ID}
+	query := "UPDATE mindmaps SET updated = ?"
+	args := []interface{}{time.Now()}
 
 	if mindmapFilter.Name {
-		query += ", mindmap_name = ?"
-		args = append(args, mindmapUpdateInfo.Name)
+		query += ", mindmap_name = ?, mindmap_name_key = ?"
+		args = append(args, mindmapUpdateInfo.Name, s.storage.nameKey(mindmapUpdateInfo.Name))
 	}
 	if mindmapFilter.Owner {
 		query += ", owner = ?"
@@ -166,6 +168,12 @@ func (s *MindmapStorage) MindmapUpdate(mindmap *model.Mindmap, mindmapUpdateInfo
 		query += ", is_public = ?"
 		args = append(args, mindmapUpdateInfo.IsPublic)
 	}
+	if mindmapFilter.Sort {
+		query += ", sort_spec = ?"
+		args = append(args, mindmapUpdateInfo.Sort.String())
+	}
+	query += " WHERE id = ?"
+	args = append(args, mindmap.ID)
 
 	_, err := db.Exec(query, args...)
 	if err != nil {
@@ -183,31 +191,56 @@ func (s *MindmapStorage) MindmapDelete(mindmap *model.Mindmap) error {
 
 	db := s.storage.GetDatabase()
 
-	// Start a transaction
-	err := db.Begin()
+	// Drop the mindmap tables and delete the mindmap in a single transaction
+	return db.Batch(func() error {
+		if err := db.DropMindmapTables(mindmap.ID); err != nil {
+			s.logger.Error(context.Background(), "Failed to drop mindmap tables", log.Fields{"mindmap": mindmap, "error": err})
+			return fmt.Errorf("failed to drop mindmap tables: %w", err)
+		}
+
+		if _, err := db.Exec("DELETE FROM mindmaps WHERE id = ?", mindmap.ID); err != nil {
+			s.logger.Error(context.Background(), "Failed to delete mindmap", log.Fields{"mindmap": mindmap, "error": err})
+			return fmt.Errorf("failed to delete mindmap: %w", err)
+		}
+
+		s.logger.Info(context.Background(), "Mindmaps deleted successfully from storage", log.Fields{"mindmap": mindmap})
+		return nil
+	})
+}
+
+// MindmapRevision retrieves the revision of a mindmap, the number of changes committed to it, -1 for a mindmap not
+// found.
+func (s *MindmapStorage) MindmapRevision(mindmapID int) (int, error) {
+	var revision int
+	db := s.storage.GetDatabase()
+	err := db.QueryRow("SELECT revision FROM mindmaps WHERE id = ?", mindmapID).Scan(&revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, nil
+	}
 	if err != nil {
-		s.logger.Error(context.Background(), "Failed to begin transaction", log.Fields{"error": err})
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		s.logger.Error(context.Background(), "Failed to query mindmap revision", log.Fields{"error": err, "mindmapID": mindmapID})
+		return 0, fmt.Errorf("failed to query mindmap revision: %w", err)
 	}
-	defer func(db Database) {
-		_ = db.Rollback()
-	}(db)
+	return revision, nil
+}
 
-	// Drop the mindmap tables
-	err = db.DropMindmapTables(mindmap.ID)
+// MindmapRevise raises the revision of a mindmap to record a change of it, if it still is the given revision, and
+// returns the new one. ErrMindmapChanged is returned if the revision was raised by another change meanwhile.
+func (s *MindmapStorage) MindmapRevise(mindmapID, revision int) (int, error) {
+	s.logger.Debug(context.Background(), "Revising mindmap", log.Fields{"mindmapID": mindmapID, "revision": revision})
+
+	db := s.storage.GetDatabase()
+	result, err := db.Exec("UPDATE mindmaps SET revision = revision + 1 WHERE id = ? AND revision = ?", mindmapID, revision)
 	if err != nil {
-		s.logger.Error(context.Background(), "Failed to drop mindmap tables", log.Fields{"mindmap": mindmap, "error": err})
-		return fmt.Errorf("failed to drop mindmap tables: %w", err)
+		s.logger.Error(context.Background(), "Failed to revise mindmap", log.Fields{"error": err, "mindmapID": mindmapID})
+		return 0, fmt.Errorf("failed to revise mindmap: %w", err)
 	}
-
-	// Delete the mindmap from the mindmaps table
-	_, err = db.Exec("DELETE FROM mindmaps WHERE id = ?", mindmap.ID)
+	affected, err := result.RowsAffected()
 	if err != nil {
-		s.logger.Error(context.Background(), "Failed to delete mindmap", log.Fields{"mindmap": mindmap, "error": err})
-		return fmt.Errorf("failed to delete mindmap: %w", err)
+		return 0, fmt.Errorf("failed to revise mindmap: %w", err)
 	}
-
-	s.logger.Info(context.Background(), "Mindmaps deleted successfully from storage", log.Fields{"mindmap": mindmap})
-	// Commit the transaction
-	return db.Commit()
+	if affected == 0 {
+		return 0, ErrMindmapChanged
+	}
+	return revision + 1, nil
 }
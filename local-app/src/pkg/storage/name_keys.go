@@ -0,0 +1,239 @@
+// Package storage provides functionality for persisting and retrieving Mindnoscape data.
+// This file handles the normalized name keys used to enforce name uniqueness.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/names"
+)
+
+// nameKeyColumn describes a table column holding the normalized key of a name column
+type nameKeyColumn struct {
+	table     string
+	column    string
+	keyColumn string
+	index     string
+	unique    string
+}
+
+// nameKeyColumns lists the name columns that are unique under name normalization
+var nameKeyColumns = []nameKeyColumn{
+	{table: "users", column: "username", keyColumn: "username_key", index: "idx_users_username_key", unique: "username_key"},
+	{table: "mindmaps", column: "mindmap_name", keyColumn: "mindmap_name_key", index: "idx_mindmaps_name_key", unique: "owner, mindmap_name_key"},
+}
+
+// nameKey returns the key under which a name is stored and looked up
+func (s *Storage) nameKey(name string) string {
+	return names.Key(name, s.caseSensitiveNames)
+}
+
+// nameKeysSetting is the stored setting recording the case sensitivity the name keys were computed for
+const nameKeysSetting = "name_keys_case_sensitive"
+
+// migrateNameKeys adds missing name key columns, and recomputes all keys and creates the unique indexes on them
+// when the keys were computed for another case sensitivity than the configured one, or the indexes are missing.
+// Names colliding under the configured case sensitivity are reported as an error, leaving the keys as they were.
+func (s *Storage) migrateNameKeys() error {
+	ctx := context.Background()
+
+	caseSensitive := strconv.FormatBool(s.caseSensitiveNames)
+	built, err := s.setting(nameKeysSetting)
+	if err != nil {
+		return err
+	}
+	current := built == caseSensitive
+
+	for _, c := range nameKeyColumns {
+		exists, err := s.columnExists(c.table, c.keyColumn)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			s.logger.Info(ctx, "Adding name key column", log.Fields{"table": c.table, "column": c.keyColumn})
+			if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT", c.table, c.keyColumn)); err != nil {
+				s.logger.Error(ctx, "Failed to add name key column", log.Fields{"error": err, "table": c.table})
+				return fmt.Errorf("failed to add column %s to %s: %w", c.keyColumn, c.table, err)
+			}
+			current = false
+		}
+		if exists, err := s.schemaObjectExists("index", c.index); err != nil {
+			return err
+		} else if !exists {
+			current = false
+		}
+	}
+	if current {
+		s.logger.Debug(ctx, "Name keys are current", log.Fields{"caseSensitive": s.caseSensitiveNames})
+		return nil
+	}
+
+	s.logger.Info(ctx, "Migrating name keys", log.Fields{"caseSensitive": s.caseSensitiveNames, "builtFor": built})
+	err = s.db.Batch(func() error {
+		for _, c := range nameKeyColumns {
+			if _, err := s.db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", c.index)); err != nil {
+				s.logger.Error(ctx, "Failed to drop name key index", log.Fields{"error": err, "index": c.index})
+				return fmt.Errorf("failed to drop index %s: %w", c.index, err)
+			}
+			if err := s.recomputeNameKeys(c); err != nil {
+				return err
+			}
+			if err := s.nameKeyCollisions(c); err != nil {
+				return err
+			}
+			if _, err := s.db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", c.index, c.table, c.unique)); err != nil {
+				s.logger.Error(ctx, "Failed to create name key index", log.Fields{"error": err, "index": c.index})
+				return fmt.Errorf("failed to create index %s: %w", c.index, err)
+			}
+		}
+		return s.settingSet(nameKeysSetting, caseSensitive)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Name keys migrated successfully", nil)
+	return nil
+}
+
+// nameKeysCheck uses the case sensitivity the name keys were computed for in a database opened read-only, which
+// are not migrated, so that names are still looked up under their stored keys
+func (s *Storage) nameKeysCheck() error {
+	exists, err := s.schemaObjectExists("table", "storage_settings")
+	if err != nil || !exists {
+		return err
+	}
+	built, err := s.setting(nameKeysSetting)
+	if err != nil || built == "" {
+		return err
+	}
+	if caseSensitive := built == "true"; caseSensitive != s.caseSensitiveNames {
+		s.logger.Warn(context.Background(), "Name keys were computed for another case sensitivity, using it while read-only", log.Fields{"caseSensitive": caseSensitive})
+		s.caseSensitiveNames = caseSensitive
+	}
+	return nil
+}
+
+// nameKeyCollisions returns an error listing the names of a table that share their recomputed keys
+func (s *Storage) nameKeyCollisions(c nameKeyColumn) error {
+	rows, err := s.db.Query(fmt.Sprintf("SELECT group_concat(%s, ', ') FROM %s GROUP BY %s HAVING COUNT(*) > 1", c.column, c.table, c.unique))
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query colliding names", log.Fields{"error": err, "table": c.table})
+		return fmt.Errorf("failed to query colliding names of %s: %w", c.table, err)
+	}
+	defer rows.Close()
+
+	var collisions []string
+	for rows.Next() {
+		var collision string
+		if err := rows.Scan(&collision); err != nil {
+			return fmt.Errorf("failed to scan colliding names of %s: %w", c.table, err)
+		}
+		collisions = append(collisions, collision)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating colliding names of %s: %w", c.table, err)
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	s.logger.Error(context.Background(), "Names collide under the configured case sensitivity", log.Fields{"table": c.table, "collisions": collisions})
+	return fmt.Errorf("names of %s collide with case_sensitive_names %t: %s; rename them with the previous setting first",
+		c.table, s.caseSensitiveNames, strings.Join(collisions, "; "))
+}
+
+// recomputeNameKeys sets the key column of all rows of a table from its name column
+func (s *Storage) recomputeNameKeys(c nameKeyColumn) error {
+	ctx := context.Background()
+
+	rows, err := s.db.Query(fmt.Sprintf("SELECT id, %s FROM %s", c.column, c.table))
+	if err != nil {
+		s.logger.Error(ctx, "Failed to query names", log.Fields{"error": err, "table": c.table})
+		return fmt.Errorf("failed to query names of %s: %w", c.table, err)
+	}
+	keys := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan name row of %s: %w", c.table, err)
+		}
+		keys[id] = s.nameKey(name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating name rows of %s: %w", c.table, err)
+	}
+
+	return s.db.Batch(func() error {
+		for id, key := range keys {
+			if _, err := s.db.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", c.table, c.keyColumn), key, id); err != nil {
+				s.logger.Error(ctx, "Failed to update name key", log.Fields{"error": err, "table": c.table, "id": id})
+				return fmt.Errorf("failed to update name key of %s %d: %w", c.table, id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// columnExists reports whether a table has a column with the given name
+func (s *Storage) columnExists(table, column string) (bool, error) {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue interface{}
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// schemaObjectExists reports whether the database has a table or index, by its kind, with the given name
+func (s *Storage) schemaObjectExists(kind, name string) (bool, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = ? AND name = ?", kind, name).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to look up %s %s: %w", kind, name, err)
+	}
+	return count > 0, nil
+}
+
+// setting returns the value of a stored setting, empty if it is not set
+func (s *Storage) setting(name string) (string, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM storage_settings WHERE setting = ?", name).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to read setting", log.Fields{"error": err, "setting": name})
+		return "", fmt.Errorf("failed to read setting %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// settingSet stores the value of a setting
+func (s *Storage) settingSet(name, value string) error {
+	if _, err := s.db.Exec("INSERT INTO storage_settings (setting, value) VALUES (?, ?) ON CONFLICT (setting) DO UPDATE SET value = excluded.value", name, value); err != nil {
+		s.logger.Error(context.Background(), "Failed to store setting", log.Fields{"error": err, "setting": name})
+		return fmt.Errorf("failed to store setting %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package storage_test
+
+import (
+	"strings"
+	"testing"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// openStorage opens the SQLite storage in dir with the given case sensitivity of names, closed when the test ends
+func openStorage(t *testing.T, dir string, caseSensitive, readOnly bool) (*storage.Storage, error) {
+	t.Helper()
+	cfg := &model.Config{
+		DatabaseType:       string(storage.SQLite),
+		DatabaseDir:        dir,
+		DatabaseFile:       "mindnoscape.db",
+		CaseSensitiveNames: caseSensitive,
+		ReadOnly:           readOnly,
+		LogFolder:          dir,
+		CommandLog:         "command.log",
+		ErrorLog:           "error.log",
+		InfoLog:            "info.log",
+	}
+	logger, err := log.NewLogger(cfg, log.LevelError)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { logger.Close() })
+	store, err := storage.NewStorage(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, nil
+}
+
+func TestNameKeysMigration(t *testing.T) {
+	dir := t.TempDir()
+	store, err := openStorage(t, dir, true, false)
+	if err != nil {
+		t.Fatalf("failed to open storage: %v", err)
+	}
+	for _, username := range []string{"Alice", "alice", "Bob"} {
+		if _, err := store.UserAdd(model.UserInfo{Username: username, PasswordHash: []byte("hash"), Active: true}); err != nil {
+			t.Fatalf("failed to add user %s: %v", username, err)
+		}
+	}
+	store.Close()
+
+	// Names colliding regardless of case refuse the change of the setting, naming them
+	_, err = openStorage(t, dir, false, false)
+	if err == nil || !strings.Contains(err.Error(), "Alice, alice") {
+		t.Fatalf("opening with case-insensitive names returned %v, want the colliding names", err)
+	}
+
+	// The keys are left as they were, so the database still opens with the setting they were computed for
+	store, err = openStorage(t, dir, true, false)
+	if err != nil {
+		t.Fatalf("failed to open storage again: %v", err)
+	}
+	if users, err := store.UserGet(model.UserInfo{Username: "alice"}, model.UserFilter{Username: true}); err != nil || len(users) != 1 || users[0].Username != "alice" {
+		t.Fatalf("UserGet(alice) = %v, %v, want alice", users, err)
+	}
+	if _, err := store.UserAdd(model.UserInfo{Username: "Bob", PasswordHash: []byte("hash"), Active: true}); err == nil {
+		t.Fatal("added a second user Bob")
+	}
+	users, err := store.UserGet(model.UserInfo{Username: "Alice"}, model.UserFilter{Username: true})
+	if err != nil || len(users) != 1 {
+		t.Fatalf("UserGet(Alice) = %v, %v, want Alice", users, err)
+	}
+	if err := store.UserDelete(users[0]); err != nil {
+		t.Fatalf("failed to delete user Alice: %v", err)
+	}
+	store.Close()
+
+	// Without collisions the keys are recomputed, looking names up regardless of case
+	store, err = openStorage(t, dir, false, false)
+	if err != nil {
+		t.Fatalf("failed to open storage with case-insensitive names: %v", err)
+	}
+	if users, err := store.UserGet(model.UserInfo{Username: "BOB"}, model.UserFilter{Username: true}); err != nil || len(users) != 1 {
+		t.Fatalf("UserGet(BOB) = %v, %v, want Bob", users, err)
+	}
+	if _, err := store.UserAdd(model.UserInfo{Username: "ALICE", PasswordHash: []byte("hash"), Active: true}); err == nil {
+		t.Fatal("added user ALICE next to alice")
+	}
+	store.Close()
+
+	// Read-only, the keys are looked up as they were computed whatever the configuration
+	store, err = openStorage(t, dir, true, true)
+	if err != nil {
+		t.Fatalf("failed to open storage read-only: %v", err)
+	}
+	if users, err := store.UserGet(model.UserInfo{Username: "BOB"}, model.UserFilter{Username: true}); err != nil || len(users) != 1 {
+		t.Fatalf("read-only UserGet(BOB) = %v, %v, want Bob", users, err)
+	}
+}
@@ -0,0 +1,208 @@
+// Package storage provides functionality for persisting and retrieving Mindnoscape data.
+// This file handles the full-text search index of the nodes of each mindmap.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// ErrSearchUnsupported is returned by NodeSearch for queries the store can't run, which are then matched against the
+// loaded nodes instead
+var ErrSearchUnsupported = errors.New("node search not supported")
+
+// searchMinLength is the length of the shortest text the trigram index finds, shorter texts are never found by it
+const searchMinLength = 3
+
+// searchTriggers are the triggers keeping the search index of a mindmap in line with its node and content tables,
+// by the name they are created with after the mindmap ID
+var searchTriggers = []struct{ name, definition string }{
+	{"node_insert", `AFTER INSERT ON nodes_%[1]d BEGIN
+            INSERT INTO node_search_%[1]d (rowid, name, content) VALUES (new.id, new.node_name, '');
+        END`},
+	{"node_update", `AFTER UPDATE OF node_name ON nodes_%[1]d BEGIN
+            UPDATE node_search_%[1]d SET name = new.node_name WHERE rowid = new.id;
+        END`},
+	{"node_delete", `AFTER DELETE ON nodes_%[1]d BEGIN
+            DELETE FROM node_search_%[1]d WHERE rowid = old.id;
+        END`},
+	{"content_insert", `AFTER INSERT ON node_content_%[1]d BEGIN
+            UPDATE node_search_%[1]d SET content = ` + searchContent + ` WHERE rowid = new.node_id;
+        END`},
+	{"content_update", `AFTER UPDATE ON node_content_%[1]d BEGIN
+            UPDATE node_search_%[1]d SET content = ` + searchContent + ` WHERE rowid = new.node_id;
+        END`},
+	{"content_delete", `AFTER DELETE ON node_content_%[1]d BEGIN
+            UPDATE node_search_%[1]d SET content = ` + searchContent + ` WHERE rowid = old.node_id;
+        END`},
+}
+
+// searchContent is the indexed content of the node of a row of the search index: the names and values of its
+// content fields, separated so that no text spans two of them
+const searchContent = `COALESCE((SELECT group_concat(key || char(10) || value, char(10)) FROM node_content_%[1]d WHERE node_id = node_search_%[1]d.rowid), '')`
+
+// searchTrigger returns the name of a search index trigger of a mindmap
+func searchTrigger(mindmapID int, name string) string {
+	return fmt.Sprintf("node_search_%d_%s", mindmapID, name)
+}
+
+// createSearchIndex creates the search index of a mindmap and the triggers keeping it up to date. The index of a
+// mindmap without the triggers, new or last opened by a build without full-text search, is filled from its nodes.
+func (s *SQLiteDatabase) createSearchIndex(mindmapID int) error {
+	ctx := context.Background()
+
+	synced, err := s.searchIndexSynced(mindmapID)
+	if err != nil || synced {
+		return err
+	}
+	s.logger.Info(ctx, "Building node search index", log.Fields{"mindmapID": mindmapID})
+
+	_, err = s.Exec(fmt.Sprintf(`
+        CREATE VIRTUAL TABLE IF NOT EXISTS node_search_%[1]d USING fts5 (name, content, tokenize = 'trigram');
+        DELETE FROM node_search_%[1]d;
+        INSERT INTO node_search_%[1]d (rowid, name, content) SELECT id, node_name, '' FROM nodes_%[1]d;
+        UPDATE node_search_%[1]d SET content = `+searchContent+`;
+    `, mindmapID))
+	if err != nil {
+		s.logger.Error(ctx, "Failed to build node search index", log.Fields{"error": err, "mindmapID": mindmapID})
+		return fmt.Errorf("failed to build node search index: mindmap_id=%d, error=%v", mindmapID, err)
+	}
+	for _, trigger := range searchTriggers {
+		definition := "CREATE TRIGGER IF NOT EXISTS " + searchTrigger(mindmapID, trigger.name) + " " + fmt.Sprintf(trigger.definition, mindmapID)
+		if _, err := s.Exec(definition); err != nil {
+			s.logger.Error(ctx, "Failed to create node search trigger", log.Fields{"error": err, "mindmapID": mindmapID, "trigger": trigger.name})
+			return fmt.Errorf("failed to create node search trigger: mindmap_id=%d, error=%v", mindmapID, err)
+		}
+	}
+	return nil
+}
+
+// dropSearchTriggers drops the triggers of the search index of a mindmap, which a build without full-text search
+// can't run. The index itself is kept, it is filled again when the triggers are created.
+func (s *SQLiteDatabase) dropSearchTriggers(mindmapID int) error {
+	for _, trigger := range searchTriggers {
+		if _, err := s.Exec("DROP TRIGGER IF EXISTS " + searchTrigger(mindmapID, trigger.name)); err != nil {
+			s.logger.Error(context.Background(), "Failed to drop node search trigger", log.Fields{"error": err, "mindmapID": mindmapID})
+			return fmt.Errorf("failed to drop node search trigger: mindmap_id=%d, error=%v", mindmapID, err)
+		}
+	}
+	return nil
+}
+
+// searchIndexSynced reports whether a mindmap has a search index kept up to date by its triggers
+func (s *SQLiteDatabase) searchIndexSynced(mindmapID int) (bool, error) {
+	var count int
+	err := s.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE (type = 'table' AND name = ?) OR (type = 'trigger' AND name = ?)",
+		fmt.Sprintf("node_search_%d", mindmapID), searchTrigger(mindmapID, searchTriggers[len(searchTriggers)-1].name),
+	).Scan(&count)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to check node search index", log.Fields{"error": err, "mindmapID": mindmapID})
+		return false, fmt.Errorf("failed to check node search index: %w", err)
+	}
+	return count == 2, nil
+}
+
+// SearchIndexed reports whether the nodes of a mindmap can be searched in the database, by default they can't
+func (b *BaseDatabase) SearchIndexed(mindmapID int) (bool, error) {
+	return false, nil
+}
+
+// SearchIndexed reports whether the nodes of a mindmap can be searched in the database: SQLite is built with
+// full-text search and the mindmap has an up to date search index
+func (s *SQLiteDatabase) SearchIndexed(mindmapID int) (bool, error) {
+	if !s.fullText {
+		return false, nil
+	}
+	return s.searchIndexSynced(mindmapID)
+}
+
+// NodeSearch returns the IDs of the nodes of a mindmap matching a query, best match first, see model.NodeQuery.
+// Terms without a scope search the name and content of the nodes as selected by nodeFilter. Returns
+// ErrSearchUnsupported if the database has no search index for the mindmap or the index can't run the query.
+func (s *NodeStorage) NodeSearch(mindmap *model.Mindmap, query model.NodeQuery, nodeFilter model.NodeFilter) ([]int, error) {
+	ctx := context.Background()
+	s.logger.Debug(ctx, "Searching nodes in database", log.Fields{"mindmapID": mindmap.ID})
+
+	match, ok := searchMatch(query, nodeFilter)
+	if !ok {
+		return nil, ErrSearchUnsupported
+	}
+	db := s.storage.GetDatabase()
+	indexed, err := db.SearchIndexed(mindmap.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !indexed {
+		return nil, ErrSearchUnsupported
+	}
+
+	rows, err := db.Query(fmt.Sprintf("SELECT rowid FROM node_search_%d WHERE node_search_%d MATCH ? ORDER BY rank", mindmap.ID, mindmap.ID), match)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to search nodes", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to search nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan node ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+	return ids, nil
+}
+
+// searchMatch returns the FTS5 query of a node query, each text a phrase matched anywhere in the columns of its
+// scope. Returns false for queries the index can't run: with texts shorter than searchMinLength, terms other than
+// texts, or terms without a scope under a filter selecting the index or neither the name nor the content.
+func searchMatch(query model.NodeQuery, nodeFilter model.NodeFilter) (string, bool) {
+	alternatives, ok := query.TextTerms()
+	if !ok || nodeFilter.Index {
+		return "", false
+	}
+
+	var unscoped []string
+	if nodeFilter.Name {
+		unscoped = append(unscoped, model.QueryName)
+	}
+	if nodeFilter.Content {
+		unscoped = append(unscoped, model.QueryContent)
+	}
+
+	var match []string
+	for _, terms := range alternatives {
+		var all []string
+		for _, term := range terms {
+			if utf8.RuneCountInString(term.Text) < searchMinLength {
+				return "", false
+			}
+			columns := unscoped
+			if term.Scope != "" {
+				columns = []string{term.Scope}
+			}
+			if len(columns) == 0 {
+				return "", false
+			}
+			all = append(all, "{"+strings.Join(columns, " ")+"} : "+searchPhrase(term.Text))
+		}
+		match = append(match, "("+strings.Join(all, " AND ")+")")
+	}
+	return strings.Join(match, " OR "), true
+}
+
+// searchPhrase quotes a text as an FTS5 phrase, which the trigram index matches as a substring regardless of case
+func searchPhrase(text string) string {
+	return `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+}
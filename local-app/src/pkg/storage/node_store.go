@@ -17,7 +17,17 @@ type NodeStore interface {
 	NodeAdd(mindmap *model.Mindmap, newNodeInfo model.NodeInfo, forceID ...bool) (int, error)
 	NodeGet(mindmap *model.Mindmap, nodeInfo model.NodeInfo, nodeFilter model.NodeFilter) ([]*model.Node, error)
 	NodeUpdate(mindmap *model.Mindmap, node *model.Node, nodeUpdateInfo model.NodeInfo, nodeUpdateFilter model.NodeFilter) error
+	NodeIndexUpdate(mindmap *model.Mindmap, indexes map[int]string) error
 	NodeDelete(mindmap *model.Mindmap, node *model.Node) error
+	NodeGetLevels(mindmap *model.Mindmap, index string, levels int) ([]*model.Node, map[int]int, error) // Also returns the child counts of the last level
+	NodeStats(mindmap *model.Mindmap) (model.MindmapStats, error)
+	NodeTagAdd(mindmap *model.Mindmap, node *model.Node, tag string) (bool, error)    // Returns false if the node has the tag already
+	NodeTagDelete(mindmap *model.Mindmap, node *model.Node, tag string) (bool, error) // Returns false if the node has no such tag
+	NodeTagFind(mindmap *model.Mindmap, tag string) ([]int, error)                    // Returns the IDs of the nodes with the tag
+	NodeAttachmentAdd(mindmap *model.Mindmap, node *model.Node, attachment model.Attachment) (int, error)
+	NodeAttachmentGet(mindmap *model.Mindmap, node *model.Node, withData bool) ([]*model.Attachment, error) // All of the mindmap's if node is nil
+	NodeAttachmentDelete(mindmap *model.Mindmap, node *model.Node, name string) (bool, error)               // Returns false if the node has no such attachment
+	NodeSearch(mindmap *model.Mindmap, query model.NodeQuery, nodeFilter model.NodeFilter) ([]int, error)   // Returns the IDs of the matching nodes, best first, or ErrSearchUnsupported
 }
 
 // NodeStorage implements the NodeStore interface.
@@ -36,6 +46,17 @@ func NewNodeStorage(storage *Storage) *NodeStorage {
 
 // NodeAdd adds a new node to the database.
 func (s *NodeStorage) NodeAdd(mindmap *model.Mindmap, newNodeInfo model.NodeInfo, forceID ...bool) (int, error) {
+	var result int
+	err := s.storage.GetDatabase().Batch(func() error {
+		var err error
+		result, err = s.nodeAdd(mindmap, newNodeInfo, forceID...)
+		return err
+	})
+	return result, err
+}
+
+// nodeAdd is NodeAdd, run by it in a transaction
+func (s *NodeStorage) nodeAdd(mindmap *model.Mindmap, newNodeInfo model.NodeInfo, forceID ...bool) (int, error) {
 	s.logger.Info(context.Background(), "Adding new node", log.Fields{
 		"mindmapID": mindmap.ID,
 		"nodeName":  newNodeInfo.Name,
@@ -45,17 +66,12 @@ func (s *NodeStorage) NodeAdd(mindmap *model.Mindmap, newNodeInfo model.NodeInfo
 	db := s.storage.GetDatabase()
 	now := time.Now()
 
-	// Start a transaction
-	err := db.Begin()
-	if err != nil {
-		s.logger.Error(context.Background(), "Failed to begin transaction", log.Fields{"error": err})
-		return 0, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer db.Rollback()
+	var err error
 
 	// Construct the table names safely
 	nodesTable := "nodes_" + strconv.Itoa(mindmap.ID)
 	contentTable := "node_content_" + strconv.Itoa(mindmap.ID)
+	tagsTable := "node_tags_" + strconv.Itoa(mindmap.ID)
 
 	// Insert the node into nodes_{mindmap_id} table
 	var result sql.Result
@@ -87,19 +103,21 @@ func (s *NodeStorage) NodeAdd(mindmap *model.Mindmap, newNodeInfo model.NodeInfo
 	if len(newNodeInfo.Content) > 0 {
 		contentQuery := "INSERT INTO " + contentTable + " (node_id, key, value) VALUES (?, ?, ?)"
 		for key, value := range newNodeInfo.Content {
-			_, err = db.Exec(contentQuery, mindmap.ID, id, key, value)
+			_, err = db.Exec(contentQuery, id, key, value)
 			if err != nil {
 				s.logger.Error(context.Background(), "Failed to add node content", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": id})
-				db.Rollback()
 				return 0, fmt.Errorf("failed to add node content: %w", err)
 			}
 		}
 	}
 
-	// Commit the transaction
-	if err := db.Commit(); err != nil {
-		s.logger.Error(context.Background(), "Failed to commit transaction", log.Fields{"error": err})
-		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	// Insert tags into node_tags_{mindmap_id} table
+	for _, tag := range newNodeInfo.Tags {
+		_, err = db.Exec("INSERT OR IGNORE INTO "+tagsTable+" (node_id, tag) VALUES (?, ?)", id, tag)
+		if err != nil {
+			s.logger.Error(context.Background(), "Failed to add node tag", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": id})
+			return 0, fmt.Errorf("failed to add node tag: %w", err)
+		}
 	}
 
 	s.logger.Info(context.Background(), "Node added successfully", log.Fields{"mindmapID": mindmap.ID, "nodeID": id})
@@ -110,11 +128,8 @@ func (s *NodeStorage) NodeAdd(mindmap *model.Mindmap, newNodeInfo model.NodeInfo
 func (s *NodeStorage) NodeGet(mindmap *model.Mindmap, nodeInfo model.NodeInfo, nodeFilter model.NodeFilter) ([]*model.Node, error) {
 	s.logger.Info(context.Background(), "Retrieving nodes", log.Fields{"mindmap": mindmap, "nodeInfo": nodeInfo, "filter": nodeFilter})
 
-	db := s.storage.GetDatabase()
-
 	// Construct the table names safely
 	nodesTable := "nodes_" + strconv.Itoa(mindmap.ID)
-	contentTable := "node_content_" + strconv.Itoa(mindmap.ID)
 
 	query := "SELECT id, parent_id, node_name, index_value, created, updated FROM " + nodesTable + " WHERE mindmap_id = ?"
 	var args []interface{}
@@ -138,6 +153,21 @@ func (s *NodeStorage) NodeGet(mindmap *model.Mindmap, nodeInfo model.NodeInfo, n
 		args = append(args, nodeInfo.Index)
 	}
 
+	nodes, err := s.queryNodes(mindmap, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(context.Background(), "Nodes retrieved successfully", log.Fields{"mindmapID": mindmap.ID, "nodeCount": len(nodes)})
+	return nodes, nil
+}
+
+// queryNodes runs a query selecting rows of the node table of a mindmap and returns their nodes with content and tags
+func (s *NodeStorage) queryNodes(mindmap *model.Mindmap, query string, args ...interface{}) ([]*model.Node, error) {
+	db := s.storage.GetDatabase()
+	contentTable := "node_content_" + strconv.Itoa(mindmap.ID)
+	tagsTable := "node_tags_" + strconv.Itoa(mindmap.ID)
+
 	// Query the db for node
 	rows, err := db.Query(query, args...)
 	if err != nil {
@@ -168,7 +198,7 @@ func (s *NodeStorage) NodeGet(mindmap *model.Mindmap, nodeInfo model.NodeInfo, n
 	// Query the db for node content
 	for _, node := range nodes {
 		contentQuery := fmt.Sprintf("SELECT key, value FROM %s WHERE node_id = ?", contentTable)
-		contentRows, err := db.Query(contentQuery, mindmap.ID, node.ID)
+		contentRows, err := db.Query(contentQuery, node.ID)
 		if err != nil {
 			s.logger.Error(context.Background(), "Failed to query node content", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
 			return nil, fmt.Errorf("failed to query node content: %w", err)
@@ -189,25 +219,31 @@ func (s *NodeStorage) NodeGet(mindmap *model.Mindmap, nodeInfo model.NodeInfo, n
 			s.logger.Error(context.Background(), "Error iterating content rows", log.Fields{"error": err})
 			return nil, fmt.Errorf("error iterating content rows: %w", err)
 		}
+
+		node.Tags, err = s.nodeTags(tagsTable, node.ID)
+		if err != nil {
+			s.logger.Error(context.Background(), "Failed to query node tags", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
+			return nil, fmt.Errorf("failed to query node tags: %w", err)
+		}
 	}
 
-	s.logger.Info(context.Background(), "Nodes retrieved successfully", log.Fields{"mindmapID": mindmap.ID, "nodeCount": len(nodes)})
 	return nodes, nil
 }
 
 // NodeUpdate updates an existing node in the database.
 func (s *NodeStorage) NodeUpdate(mindmap *model.Mindmap, node *model.Node, nodeUpdateInfo model.NodeInfo, nodeUpdateFilter model.NodeFilter) error {
+	return s.storage.GetDatabase().Batch(func() error {
+		return s.nodeUpdate(mindmap, node, nodeUpdateInfo, nodeUpdateFilter)
+	})
+}
+
+// nodeUpdate is NodeUpdate, run by it in a transaction
+func (s *NodeStorage) nodeUpdate(mindmap *model.Mindmap, node *model.Node, nodeUpdateInfo model.NodeInfo, nodeUpdateFilter model.NodeFilter) error {
 	s.logger.Info(context.Background(), "Updating node", log.Fields{"mindmap": mindmap, "node": node, "updateInfo": nodeUpdateInfo, "filter": nodeUpdateFilter})
 
 	db := s.storage.GetDatabase()
 
 	var err error
-	if err = db.Begin(); err != nil {
-		s.logger.Error(context.Background(), "Failed to begin transaction", log.Fields{"error": err})
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer db.Rollback()
-
 	var updates []string
 	var args []interface{}
 
@@ -224,7 +260,8 @@ func (s *NodeStorage) NodeUpdate(mindmap *model.Mindmap, node *model.Node, nodeU
 		args = append(args, nodeUpdateInfo.Index)
 	}
 
-	if len(updates) > 0 {
+	// Content changes count as changes of the node too
+	if len(updates) > 0 || nodeUpdateFilter.Content {
 		updates = append(updates, "updated = ?")
 		args = append(args, time.Now())
 
@@ -250,10 +287,13 @@ func (s *NodeStorage) NodeUpdate(mindmap *model.Mindmap, node *model.Node, nodeU
 			return fmt.Errorf("failed to delete existing node content: %w", err)
 		}
 
-		// Insert new content
+		// Insert new content, empty values remove a field
 		if len(nodeUpdateInfo.Content) > 0 {
 			insertQuery := fmt.Sprintf("INSERT INTO node_content_%d (node_id, key, value) VALUES (?, ?, ?)", mindmap.ID)
 			for key, value := range nodeUpdateInfo.Content {
+				if value == "" {
+					continue
+				}
 				_, err = db.Exec(insertQuery, node.ID, key, value)
 				if err != nil {
 					s.logger.Error(context.Background(), "Failed to insert new node content", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
@@ -263,49 +303,327 @@ func (s *NodeStorage) NodeUpdate(mindmap *model.Mindmap, node *model.Node, nodeU
 		}
 	}
 
-	err = db.Commit()
-	if err != nil {
-		s.logger.Error(context.Background(), "Failed to commit transaction", log.Fields{"error": err})
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	s.logger.Info(context.Background(), "Node updated successfully", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID})
+	return nil
+}
+
+// NodeIndexUpdate sets the indexes of many nodes, given by node ID, in a single transaction
+func (s *NodeStorage) NodeIndexUpdate(mindmap *model.Mindmap, indexes map[int]string) error {
+	return s.storage.GetDatabase().Batch(func() error {
+		return s.nodeIndexUpdate(mindmap, indexes)
+	})
+}
+
+// nodeIndexUpdate is NodeIndexUpdate, run by it in a transaction
+func (s *NodeStorage) nodeIndexUpdate(mindmap *model.Mindmap, indexes map[int]string) error {
+	s.logger.Info(context.Background(), "Updating node indexes", log.Fields{"mindmapID": mindmap.ID, "count": len(indexes)})
+	if len(indexes) == 0 {
+		return nil
 	}
 
-	s.logger.Info(context.Background(), "Node updated successfully", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID})
+	db := s.storage.GetDatabase()
+
+	query := "UPDATE nodes_" + strconv.Itoa(mindmap.ID) + " SET index_value = ?, updated = ? WHERE id = ?"
+	now := time.Now()
+	for id, index := range indexes {
+		if _, err := db.Exec(query, index, now, id); err != nil {
+			s.logger.Error(context.Background(), "Failed to update node index", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": id})
+			return fmt.Errorf("failed to update index of node %d: %w", id, err)
+		}
+	}
+
+	s.logger.Info(context.Background(), "Node indexes updated successfully", log.Fields{"mindmapID": mindmap.ID, "count": len(indexes)})
 	return nil
 }
 
 // NodeDelete removes a node from the database.
 func (s *NodeStorage) NodeDelete(mindmap *model.Mindmap, node *model.Node) error {
+	return s.storage.GetDatabase().Batch(func() error {
+		return s.nodeDelete(mindmap, node)
+	})
+}
+
+// nodeDelete is NodeDelete, run by it in a transaction
+func (s *NodeStorage) nodeDelete(mindmap *model.Mindmap, node *model.Node) error {
 	s.logger.Info(context.Background(), "Deleting node", log.Fields{"mindmap": mindmap, "node": node})
 
 	db := s.storage.GetDatabase()
 
-	if err := db.Begin(); err != nil {
-		s.logger.Error(context.Background(), "Failed to begin transaction", log.Fields{"error": err})
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	// Table names cannot be query parameters
+	nodesTable := "nodes_" + strconv.Itoa(mindmap.ID)
+	contentTable := "node_content_" + strconv.Itoa(mindmap.ID)
+	tagsTable := "node_tags_" + strconv.Itoa(mindmap.ID)
+	attachmentsTable := "node_attachments_" + strconv.Itoa(mindmap.ID)
+
+	// Delete node tags
+	_, err := db.Exec("DELETE FROM "+tagsTable+" WHERE node_id = ?", node.ID)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to delete node tags", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
+		return fmt.Errorf("failed to delete node tags: %w", err)
+	}
+
+	// Delete node attachments
+	_, err = db.Exec("DELETE FROM "+attachmentsTable+" WHERE node_id = ?", node.ID)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to delete node attachments", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
+		return fmt.Errorf("failed to delete node attachments: %w", err)
+	}
+
+	// Delete the links from and to the node, including those of other mindmaps
+	_, err = db.Exec("DELETE FROM node_links WHERE (source_mindmap_id = ? AND source_id = ?) OR (target_mindmap_id = ? AND target_id = ?)",
+		mindmap.ID, node.ID, mindmap.ID, node.ID)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to delete node links", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
+		return fmt.Errorf("failed to delete node links: %w", err)
 	}
-	defer db.Rollback()
 
 	// Delete node content
-	contentQuery := "DELETE FROM node_content_? WHERE node_id = ?"
-	_, err := db.Exec(contentQuery, mindmap.ID, node.ID)
+	contentQuery := "DELETE FROM " + contentTable + " WHERE node_id = ?"
+	_, err = db.Exec(contentQuery, node.ID)
 	if err != nil {
 		s.logger.Error(context.Background(), "Failed to delete node content", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
 		return fmt.Errorf("failed to delete node content: %w", err)
 	}
 
 	// Delete node
-	nodeQuery := "DELETE FROM nodes_? WHERE id = ?"
-	_, err = db.Exec(nodeQuery, mindmap.ID, node.ID)
+	nodeQuery := "DELETE FROM " + nodesTable + " WHERE id = ?"
+	_, err = db.Exec(nodeQuery, node.ID)
 	if err != nil {
 		s.logger.Error(context.Background(), "Failed to delete node", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
 		return fmt.Errorf("failed to delete node: %w", err)
 	}
 
-	if err := db.Commit(); err != nil {
-		s.logger.Error(context.Background(), "Failed to commit transaction", log.Fields{"error": err})
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	s.logger.Info(context.Background(), "Node deleted successfully", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID})
 	return nil
 }
+
+// NodeStats counts the nodes of a mindmap and computes its depth from the node indexes, without loading the nodes
+func (s *NodeStorage) NodeStats(mindmap *model.Mindmap) (model.MindmapStats, error) {
+	s.logger.Debug(context.Background(), "Computing node stats", log.Fields{"mindmapID": mindmap.ID})
+
+	db := s.storage.GetDatabase()
+
+	// The depth of a node is the number of dots in its index plus 2, the root being at depth 1
+	nodesTable := "nodes_" + strconv.Itoa(mindmap.ID)
+	query := "SELECT COUNT(*), COALESCE(MAX(CASE WHEN id = 0 THEN 1 ELSE LENGTH(index_value) - LENGTH(REPLACE(index_value, '.', '')) + 2 END), 0) FROM " + nodesTable
+
+	var stats model.MindmapStats
+	if err := db.QueryRow(query).Scan(&stats.NodeCount, &stats.Depth); err != nil {
+		s.logger.Error(context.Background(), "Failed to compute node stats", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return model.MindmapStats{}, fmt.Errorf("failed to compute node stats: %w", err)
+	}
+	return stats, nil
+}
+
+// nodeLevel is the SQL expression of the level of a node below the root from its index, 0 for the root itself
+const nodeLevel = "CASE WHEN id = 0 THEN 0 ELSE LENGTH(index_value) - LENGTH(REPLACE(index_value, '.', '')) + 1 END"
+
+// NodeGetLevels retrieves the node of an index and its descendants down to levels below it, without the rest of the
+// mindmap. Also returns the number of children of the nodes of the last level, whose children are left out.
+func (s *NodeStorage) NodeGetLevels(mindmap *model.Mindmap, index string, levels int) ([]*model.Node, map[int]int, error) {
+	ctx := context.Background()
+	s.logger.Debug(ctx, "Retrieving node levels", log.Fields{"mindmapID": mindmap.ID, "index": index, "levels": levels})
+
+	nodesTable := "nodes_" + strconv.Itoa(mindmap.ID)
+	last := model.IndexLevel(index) + levels
+
+	// The subtree of the root is the whole mindmap, that of another node its index and those under it
+	subtree, args := "mindmap_id = ?", []interface{}{mindmap.ID}
+	if index != "0" {
+		subtree += " AND (index_value = ? OR index_value LIKE ?)"
+		args = append(args, index, index+".%")
+	}
+
+	nodes, err := s.queryNodes(mindmap,
+		"SELECT id, parent_id, node_name, index_value, created, updated FROM "+nodesTable+" WHERE "+subtree+" AND "+nodeLevel+" <= ?",
+		append(args, last)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := s.storage.GetDatabase().Query(
+		"SELECT parent_id, COUNT(*) FROM "+nodesTable+" WHERE "+subtree+" AND "+nodeLevel+" = ? GROUP BY parent_id",
+		append(args, last+1)...)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to count unloaded children", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, nil, fmt.Errorf("failed to count unloaded children: %w", err)
+	}
+	defer rows.Close()
+
+	unloaded := make(map[int]int)
+	for rows.Next() {
+		var parentID, count int
+		if err := rows.Scan(&parentID, &count); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan child count: %w", err)
+		}
+		unloaded[parentID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating child counts: %w", err)
+	}
+
+	s.logger.Debug(ctx, "Node levels retrieved", log.Fields{"mindmapID": mindmap.ID, "nodeCount": len(nodes), "unloaded": len(unloaded)})
+	return nodes, unloaded, nil
+}
+
+// nodeTags returns the tags of a node, sorted
+func (s *NodeStorage) nodeTags(tagsTable string, nodeID int) ([]string, error) {
+	rows, err := s.storage.GetDatabase().Query("SELECT tag FROM "+tagsTable+" WHERE node_id = ? ORDER BY tag", nodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// NodeTagAdd tags a node, also setting its updated time. Returns false if the node has the tag already.
+func (s *NodeStorage) NodeTagAdd(mindmap *model.Mindmap, node *model.Node, tag string) (bool, error) {
+	s.logger.Info(context.Background(), "Adding node tag", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "tag": tag})
+	return s.nodeTagChange(mindmap, node, "INSERT OR IGNORE INTO node_tags_"+strconv.Itoa(mindmap.ID)+" (node_id, tag) VALUES (?, ?)", tag)
+}
+
+// NodeTagDelete removes a tag from a node, also setting its updated time. Returns false if the node has no such tag.
+func (s *NodeStorage) NodeTagDelete(mindmap *model.Mindmap, node *model.Node, tag string) (bool, error) {
+	s.logger.Info(context.Background(), "Deleting node tag", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "tag": tag})
+	return s.nodeTagChange(mindmap, node, "DELETE FROM node_tags_"+strconv.Itoa(mindmap.ID)+" WHERE node_id = ? AND tag = ?", tag)
+}
+
+// nodeTagChange runs a query adding or deleting a tag of a node, setting the updated time of the node if it changed
+func (s *NodeStorage) nodeTagChange(mindmap *model.Mindmap, node *model.Node, query string, tag string) (bool, error) {
+	db := s.storage.GetDatabase()
+
+	changed := false
+	err := db.Batch(func() error {
+		result, err := db.Exec(query, node.ID, tag)
+		if err != nil {
+			s.logger.Error(context.Background(), "Failed to change node tag", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
+			return fmt.Errorf("failed to change node tag: %w", err)
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get changed tag count: %w", err)
+		}
+		if count == 0 {
+			return nil
+		}
+
+		_, err = db.Exec("UPDATE nodes_"+strconv.Itoa(mindmap.ID)+" SET updated = ? WHERE id = ?", time.Now(), node.ID)
+		if err != nil {
+			s.logger.Error(context.Background(), "Failed to update node time", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
+			return fmt.Errorf("failed to update node time: %w", err)
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
+
+// NodeTagFind returns the IDs of the nodes of a mindmap with a tag, in ID order, looked up by the index of the tags
+func (s *NodeStorage) NodeTagFind(mindmap *model.Mindmap, tag string) ([]int, error) {
+	s.logger.Debug(context.Background(), "Finding tagged nodes", log.Fields{"mindmapID": mindmap.ID, "tag": tag})
+
+	rows, err := s.storage.GetDatabase().Query("SELECT node_id FROM node_tags_"+strconv.Itoa(mindmap.ID)+" WHERE tag = ? ORDER BY node_id", tag)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query tagged nodes", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to query tagged nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			s.logger.Error(context.Background(), "Failed to scan tagged node", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to scan tagged node: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Error iterating tagged nodes", log.Fields{"error": err})
+		return nil, fmt.Errorf("error iterating tagged nodes: %w", err)
+	}
+	return ids, nil
+}
+
+// NodeAttachmentAdd adds an attachment to a node and returns its ID. The name must be unique among the attachments
+// of the node.
+func (s *NodeStorage) NodeAttachmentAdd(mindmap *model.Mindmap, node *model.Node, attachment model.Attachment) (int, error) {
+	s.logger.Info(context.Background(), "Adding node attachment", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "name": attachment.Name, "size": attachment.Size})
+
+	result, err := s.storage.GetDatabase().Exec(
+		"INSERT INTO node_attachments_"+strconv.Itoa(mindmap.ID)+" (node_id, name, url, media_type, size, data, created) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		node.ID, attachment.Name, attachment.URL, attachment.MediaType, attachment.Size, attachment.Data, time.Now(),
+	)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to add node attachment", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
+		return 0, fmt.Errorf("failed to add node attachment: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	return int(id), nil
+}
+
+// NodeAttachmentGet returns the attachments of a node, or of all nodes of a mindmap if node is nil, in the order
+// they were added, with their data if withData is set
+func (s *NodeStorage) NodeAttachmentGet(mindmap *model.Mindmap, node *model.Node, withData bool) ([]*model.Attachment, error) {
+	s.logger.Debug(context.Background(), "Retrieving node attachments", log.Fields{"mindmapID": mindmap.ID, "withData": withData})
+
+	data := "NULL"
+	if withData {
+		data = "data"
+	}
+	query := "SELECT id, node_id, name, url, media_type, size, " + data + ", created FROM node_attachments_" + strconv.Itoa(mindmap.ID)
+	var args []interface{}
+	if node != nil {
+		query += " WHERE node_id = ?"
+		args = append(args, node.ID)
+	}
+	rows, err := s.storage.GetDatabase().Query(query+" ORDER BY id", args...)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query node attachments", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to query node attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*model.Attachment
+	for rows.Next() {
+		var a model.Attachment
+		if err := rows.Scan(&a.ID, &a.NodeID, &a.Name, &a.URL, &a.MediaType, &a.Size, &a.Data, &a.Created); err != nil {
+			s.logger.Error(context.Background(), "Failed to scan node attachment", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to scan node attachment: %w", err)
+		}
+		attachments = append(attachments, &a)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Error iterating node attachments", log.Fields{"error": err})
+		return nil, fmt.Errorf("error iterating node attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// NodeAttachmentDelete removes an attachment of a node by name. Returns false if the node has no such attachment.
+func (s *NodeStorage) NodeAttachmentDelete(mindmap *model.Mindmap, node *model.Node, name string) (bool, error) {
+	s.logger.Info(context.Background(), "Deleting node attachment", log.Fields{"mindmapID": mindmap.ID, "nodeID": node.ID, "name": name})
+
+	result, err := s.storage.GetDatabase().Exec("DELETE FROM node_attachments_"+strconv.Itoa(mindmap.ID)+" WHERE node_id = ? AND name = ?", node.ID, name)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to delete node attachment", log.Fields{"error": err, "mindmapID": mindmap.ID, "nodeID": node.ID})
+		return false, fmt.Errorf("failed to delete node attachment: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get deleted attachment count: %w", err)
+	}
+	return count > 0, nil
+}
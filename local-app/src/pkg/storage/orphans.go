@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// Orphans counts the links and attachments whose node or mindmap no longer exists, and removes them in a single
+// transaction if remove is set. Links between mindmaps count as orphans once either end is gone.
+func (s *Storage) Orphans(remove bool) (model.Orphans, error) {
+	ctx := context.Background()
+	s.logger.Info(ctx, "Collecting orphaned rows", log.Fields{"remove": remove})
+
+	var orphans model.Orphans
+	err := s.db.Batch(func() error {
+		ids, err := s.mindmapIDs()
+		if err != nil {
+			return err
+		}
+
+		// Table names cannot be query parameters
+		linkConditions := []string{"source_mindmap_id NOT IN (SELECT id FROM mindmaps)", "target_mindmap_id NOT IN (SELECT id FROM mindmaps)"}
+		for _, id := range ids {
+			linkConditions = append(linkConditions,
+				fmt.Sprintf("(source_mindmap_id = %[1]d AND source_id NOT IN (SELECT id FROM nodes_%[1]d))", id),
+				fmt.Sprintf("(target_mindmap_id = %[1]d AND target_id NOT IN (SELECT id FROM nodes_%[1]d))", id))
+		}
+		linkWhere := strings.Join(linkConditions, " OR ")
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM node_links WHERE " + linkWhere).Scan(&orphans.Links); err != nil {
+			return fmt.Errorf("failed to count orphaned links: %w", err)
+		}
+		if remove && orphans.Links > 0 {
+			if _, err := s.db.Exec("DELETE FROM node_links WHERE " + linkWhere); err != nil {
+				return fmt.Errorf("failed to delete orphaned links: %w", err)
+			}
+		}
+
+		for _, id := range ids {
+			attachmentWhere := fmt.Sprintf("FROM node_attachments_%[1]d WHERE node_id NOT IN (SELECT id FROM nodes_%[1]d)", id)
+			var count int
+			var size int64
+			if err := s.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(size), 0) "+attachmentWhere).Scan(&count, &size); err != nil {
+				return fmt.Errorf("failed to count orphaned attachments of mindmap %d: %w", id, err)
+			}
+			orphans.Attachments += count
+			orphans.AttachmentBytes += size
+			if remove && count > 0 {
+				if _, err := s.db.Exec("DELETE " + attachmentWhere); err != nil {
+					return fmt.Errorf("failed to delete orphaned attachments of mindmap %d: %w", id, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(ctx, "Failed to collect orphaned rows", log.Fields{"error": err})
+		return model.Orphans{}, err
+	}
+
+	s.logger.Info(ctx, "Orphaned rows collected", log.Fields{"orphans": orphans, "removed": remove})
+	return orphans, nil
+}
+
+// mindmapIDs returns the IDs of the stored mindmaps
+func (s *Storage) mindmapIDs() ([]int, error) {
+	rows, err := s.db.Query("SELECT id FROM mindmaps ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mindmaps: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan mindmap ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mindmaps: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"io"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// progressChunkSize is the largest write passed on at once, so progress is reported while large payloads are written
+const progressChunkSize = 64 * 1024
+
+// progressWriter reports the progress of a streamed export in nodes. The number of nodes written is
+// estimated from the bytes written relative to the expected size of the encoded mindmap.
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	expected int64
+	progress model.Progress
+	report   model.ProgressFunc
+}
+
+// newProgressWriter wraps w to report progress of writing a mindmap with the given node count and expected size
+func newProgressWriter(w io.Writer, operation string, nodes int, expected int64, report model.ProgressFunc) *progressWriter {
+	pw := &progressWriter{
+		w:        w,
+		expected: expected,
+		progress: model.Progress{Operation: operation, Total: nodes, Started: time.Now()},
+		report:   report,
+	}
+	pw.report(pw.progress)
+	return pw
+}
+
+// Write writes to the underlying writer in chunks and reports the estimated progress, never reaching the total before Finish
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		chunk := p[written:min(written+progressChunkSize, len(p))]
+		n, err := pw.w.Write(chunk)
+		written += n
+		pw.written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		if pw.expected > 0 {
+			done := int(int64(pw.progress.Total) * pw.written / pw.expected)
+			if done >= pw.progress.Total {
+				done = pw.progress.Total - 1
+			}
+			if done > pw.progress.Done {
+				pw.progress.Done = done
+				pw.report(pw.progress)
+			}
+		}
+	}
+	return written, nil
+}
+
+// Finish reports the operation as complete
+func (pw *progressWriter) Finish() {
+	pw.progress.Done = pw.progress.Total
+	pw.report(pw.progress)
+}
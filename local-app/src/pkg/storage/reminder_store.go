@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// ReminderStore defines the interface for reminder storage operations.
+type ReminderStore interface {
+	ReminderGet() ([]*model.Reminder, error)
+}
+
+// ReminderStorage implements the ReminderStore interface.
+type ReminderStorage struct {
+	storage *Storage
+	logger  *log.Logger
+}
+
+// NewReminderStorage creates a new ReminderStorage instance.
+func NewReminderStorage(storage *Storage) *ReminderStorage {
+	return &ReminderStorage{
+		storage: storage,
+		logger:  storage.logger,
+	}
+}
+
+// ReminderGet retrieves the reminders of the nodes of all mindmaps with a valid remind or due field.
+func (s *ReminderStorage) ReminderGet() ([]*model.Reminder, error) {
+	s.logger.Debug(context.Background(), "Retrieving reminders", nil)
+
+	db := s.storage.GetDatabase()
+	rows, err := db.Query("SELECT id, mindmap_name, owner FROM mindmaps ORDER BY id")
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query mindmaps", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to query mindmaps: %w", err)
+	}
+	var mindmaps []*model.Mindmap
+	for rows.Next() {
+		var m model.Mindmap
+		if err := rows.Scan(&m.ID, &m.Name, &m.Owner); err != nil {
+			rows.Close()
+			s.logger.Error(context.Background(), "Failed to scan mindmap row", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to scan mindmap row: %w", err)
+		}
+		mindmaps = append(mindmaps, &m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Error iterating mindmap rows", log.Fields{"error": err})
+		return nil, fmt.Errorf("error iterating mindmap rows: %w", err)
+	}
+
+	var reminders []*model.Reminder
+	for _, mindmap := range mindmaps {
+		mindmapReminders, err := s.mindmapReminders(mindmap)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, mindmapReminders...)
+	}
+	return reminders, nil
+}
+
+// mindmapReminders retrieves the reminders of the nodes of a mindmap
+func (s *ReminderStorage) mindmapReminders(mindmap *model.Mindmap) ([]*model.Reminder, error) {
+	db := s.storage.GetDatabase()
+	query := fmt.Sprintf(`SELECT n.id, n.node_name, n.index_value, c.key, c.value
+		FROM nodes_%d n JOIN node_content_%d c ON c.node_id = n.id
+		WHERE c.key IN (?, ?) ORDER BY n.id`, mindmap.ID, mindmap.ID)
+	rows, err := db.Query(query, model.RemindField, model.DueField)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query node reminders", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("failed to query node reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*model.Node
+	for rows.Next() {
+		var id int
+		var name, index, key, value string
+		if err := rows.Scan(&id, &name, &index, &key, &value); err != nil {
+			s.logger.Error(context.Background(), "Failed to scan node reminder row", log.Fields{"error": err, "mindmapID": mindmap.ID})
+			return nil, fmt.Errorf("failed to scan node reminder row: %w", err)
+		}
+		if len(nodes) == 0 || nodes[len(nodes)-1].ID != id {
+			nodes = append(nodes, &model.Node{ID: id, Name: name, Index: index, Content: make(map[string]string)})
+		}
+		nodes[len(nodes)-1].Content[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Error iterating node reminder rows", log.Fields{"error": err, "mindmapID": mindmap.ID})
+		return nil, fmt.Errorf("error iterating node reminder rows: %w", err)
+	}
+
+	var reminders []*model.Reminder
+	for _, node := range nodes {
+		if reminder, ok := nodeReminder(mindmap, node); ok {
+			reminders = append(reminders, reminder)
+		}
+	}
+	return reminders, nil
+}
+
+// nodeReminder returns the reminder of a node of mindmap, ok being false if the node has no valid time to remind
+// of it. Values stored before validation or imported from files may not be valid times.
+func nodeReminder(mindmap *model.Mindmap, node *model.Node) (*model.Reminder, bool) {
+	at, field, ok := model.ReminderTime(node.Content)
+	if !ok {
+		return nil, false
+	}
+	return &model.Reminder{
+		MindmapID:   mindmap.ID,
+		MindmapName: mindmap.Name,
+		Owner:       mindmap.Owner,
+		NodeID:      node.ID,
+		Index:       node.Index,
+		Name:        node.Name,
+		Field:       field,
+		Due:         node.Content[model.DueField],
+		At:          at,
+	}, true
+}
@@ -15,11 +15,19 @@ import (
 // SQLiteDatabase implements the Database interface for SQLite
 type SQLiteDatabase struct {
 	BaseDatabase
+	lock     *InstanceLock
+	path     string
+	fullText bool // SQLite is built with FTS5, see the sqlite_fts5 build tag
 }
 
-// Open opens a connection to the SQLite database
-func (s *SQLiteDatabase) Open(dataSourceName string) error {
-	s.logger.Info(context.Background(), "Opening SQLite database", log.Fields{"dbPath": filepath.Base(dataSourceName)})
+// Open opens a connection to the SQLite database. A read-only database must exist, it is not locked against other
+// instances as it is never written.
+func (s *SQLiteDatabase) Open(dataSourceName string, readOnly bool) error {
+	s.logger.Info(context.Background(), "Opening SQLite database", log.Fields{"dbPath": filepath.Base(dataSourceName), "readOnly": readOnly})
+
+	if readOnly {
+		return s.openReadOnly(dataSourceName)
+	}
 
 	// Ensure the directory for the database file exists
 	dbDir := filepath.Dir(dataSourceName)
@@ -28,9 +36,18 @@ func (s *SQLiteDatabase) Open(dataSourceName string) error {
 		return fmt.Errorf("failed to create database directory '%s': %w", dbDir, err)
 	}
 
-	// Open the database connection with additional parameters
-	db, err := sql.Open("sqlite3", dataSourceName+"?_foreign_keys=on&_journal_mode=WAL")
+	// Refuse to share the database with another running instance
+	lock, err := LockInstance(dataSourceName)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to lock SQLite database", log.Fields{"error": err})
+		return err
+	}
+
+	// Open the database connection with additional parameters, waiting for locks held by other connections
+	// such as the sqlite3 shell instead of failing at once
+	db, err := sql.Open("sqlite3", dataSourceName+"?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000")
 	if err != nil {
+		lock.Release()
 		s.logger.Error(context.Background(), "Failed to open SQLite database", log.Fields{"error": err})
 		return fmt.Errorf("failed to open SQLite database: %v", err)
 	}
@@ -38,11 +55,13 @@ func (s *SQLiteDatabase) Open(dataSourceName string) error {
 	// Set pragmas for better performance and reliability
 	if _, err := db.Exec("PRAGMA synchronous = NORMAL"); err != nil {
 		db.Close()
+		lock.Release()
 		s.logger.Error(context.Background(), "Failed to set SQLite synchronous pragma", log.Fields{"error": err})
 		return fmt.Errorf("failed to set SQLite synchronous pragma: %w", err)
 	}
 	if _, err := db.Exec("PRAGMA cache_size = 5000"); err != nil {
 		db.Close()
+		lock.Release()
 		s.logger.Error(context.Background(), "Failed to set SQLite cache pragma", log.Fields{"error": err})
 		return fmt.Errorf("failed to set SQLite cache pragma: %w", err)
 	}
@@ -50,12 +69,79 @@ func (s *SQLiteDatabase) Open(dataSourceName string) error {
 	// Verify the connection
 	if err := db.Ping(); err != nil {
 		db.Close()
+		lock.Release()
 		s.logger.Error(context.Background(), "Failed to verify database connection", log.Fields{"error": err})
 		return fmt.Errorf("failed to verify database connection: %v", err)
 	}
 
 	s.db = db
-	s.logger.Info(context.Background(), "SQLite database opened successfully", nil)
+	s.lock = lock
+	s.path = dataSourceName
+	s.fullText = s.fullTextAvailable()
+	s.logger.Info(context.Background(), "SQLite database opened successfully", log.Fields{"fullText": s.fullText})
+	return nil
+}
+
+// openReadOnly opens a connection to an existing SQLite database that refuses all writes
+func (s *SQLiteDatabase) openReadOnly(dataSourceName string) error {
+	if _, err := os.Stat(dataSourceName); err != nil {
+		s.logger.Error(context.Background(), "SQLite database not found", log.Fields{"error": err})
+		return fmt.Errorf("cannot open database read-only: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dataSourceName+"?mode=ro&_query_only=true&_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to open SQLite database", log.Fields{"error": err})
+		return fmt.Errorf("failed to open SQLite database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		s.logger.Error(context.Background(), "Failed to verify database connection", log.Fields{"error": err})
+		return fmt.Errorf("failed to verify database connection: %v", err)
+	}
+
+	s.db = db
+	s.path = dataSourceName
+	s.fullText = s.fullTextAvailable()
+	s.logger.Info(context.Background(), "SQLite database opened read-only", log.Fields{"fullText": s.fullText})
+	return nil
+}
+
+// fullTextAvailable reports whether SQLite is built with the FTS5 full-text search of the node search indexes
+func (s *SQLiteDatabase) fullTextAvailable() bool {
+	var used bool
+	if err := s.db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used); err != nil {
+		s.logger.Warn(context.Background(), "Failed to check for full-text search", log.Fields{"error": err})
+		return false
+	}
+	return used
+}
+
+// CreateMindmapTables creates the tables of a mindmap with its search index if SQLite is built with full-text
+// search. Otherwise the triggers of an existing search index are dropped, as writing the mindmap would fail on them.
+func (s *SQLiteDatabase) CreateMindmapTables(mindmapID int) error {
+	if err := s.BaseDatabase.CreateMindmapTables(mindmapID); err != nil {
+		return err
+	}
+	if !s.fullText {
+		return s.dropSearchTriggers(mindmapID)
+	}
+	return s.createSearchIndex(mindmapID)
+}
+
+// DropMindmapTables drops the tables of a mindmap and its search index. Without full-text search, SQLite can't drop
+// the search index of a mindmap that has one, which is then left behind.
+func (s *SQLiteDatabase) DropMindmapTables(mindmapID int) error {
+	if err := s.BaseDatabase.DropMindmapTables(mindmapID); err != nil {
+		return err
+	}
+	if !s.fullText {
+		return nil
+	}
+	if _, err := s.Exec(fmt.Sprintf("DROP TABLE IF EXISTS node_search_%d", mindmapID)); err != nil {
+		s.logger.Error(context.Background(), "Failed to drop node search index", log.Fields{"error": err, "mindmapID": mindmapID})
+		return fmt.Errorf("failed to drop node search index: mindmap_id=%d, error=%v", mindmapID, err)
+	}
 	return nil
 }
 
@@ -68,6 +154,134 @@ func (s *SQLiteDatabase) Close() error {
 			return fmt.Errorf("failed to close SQLite database: %w", err)
 		}
 	}
+	if s.lock != nil {
+		if err := s.lock.Release(); err != nil {
+			s.logger.Error(context.Background(), "Failed to release database lock", log.Fields{"error": err})
+			return err
+		}
+	}
 	s.logger.Info(context.Background(), "SQLite database closed successfully", nil)
 	return nil
 }
+
+// IntegrityCheck runs the SQLite integrity and foreign key checks, returning the problems they report
+func (s *SQLiteDatabase) IntegrityCheck() ([]string, error) {
+	s.logger.Info(context.Background(), "Checking SQLite database integrity", nil)
+
+	var problems []string
+	rows, err := s.db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	for rows.Next() {
+		var message string
+		if err := rows.Scan(&message); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read integrity check: %w", err)
+		}
+		if message != "ok" {
+			problems = append(problems, message)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read integrity check: %w", err)
+	}
+
+	rows, err = s.db.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run foreign key check: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, fmt.Errorf("failed to read foreign key check: %w", err)
+		}
+		problems = append(problems, fmt.Sprintf("row %d of table %s refers to a missing row of table %s", rowID.Int64, table, parent))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read foreign key check: %w", err)
+	}
+
+	s.logger.Info(context.Background(), "SQLite database integrity checked", log.Fields{"problems": len(problems)})
+	return problems, nil
+}
+
+// Vacuum rebuilds the SQLite database file without its free pages and truncates the write-ahead log
+func (s *SQLiteDatabase) Vacuum() (int64, error) {
+	s.logger.Info(context.Background(), "Vacuuming SQLite database", nil)
+	before := s.fileSize()
+
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		s.logger.Error(context.Background(), "Failed to vacuum SQLite database", log.Fields{"error": err})
+		return 0, fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if _, err := s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		s.logger.Error(context.Background(), "Failed to checkpoint SQLite database", log.Fields{"error": err})
+		return 0, fmt.Errorf("failed to checkpoint database: %w", err)
+	}
+
+	reclaimed := max(before-s.fileSize(), 0)
+	s.logger.Info(context.Background(), "SQLite database vacuumed", log.Fields{"reclaimed": reclaimed})
+	return reclaimed, nil
+}
+
+// Snapshot writes a compacted copy of the SQLite database to a new file at path, as of the last committed
+// transaction. The copy is written under a temporary name and renamed into place, so that an interrupted snapshot
+// leaves no partial file behind.
+func (s *SQLiteDatabase) Snapshot(path string) error {
+	ctx := context.Background()
+	s.logger.Info(ctx, "Writing SQLite database snapshot", log.Fields{"path": path})
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("snapshot file already exists: %s", path)
+	}
+	temp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	os.Remove(temp)
+
+	if _, err := s.db.Exec("VACUUM INTO ?", temp); err != nil {
+		os.Remove(temp)
+		s.logger.Error(ctx, "Failed to write SQLite database snapshot", log.Fields{"error": err, "path": path})
+		return fmt.Errorf("failed to write database snapshot: %w", err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		os.Remove(temp)
+		s.logger.Error(ctx, "Failed to rename SQLite database snapshot", log.Fields{"error": err, "path": path})
+		return fmt.Errorf("failed to write database snapshot: %w", err)
+	}
+	return nil
+}
+
+// SchemaVersion returns the schema version kept in the user_version header of the SQLite database file
+func (s *SQLiteDatabase) SchemaVersion() (int, error) {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		s.logger.Error(context.Background(), "Failed to read SQLite schema version", log.Fields{"error": err})
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// SetSchemaVersion sets the schema version kept in the user_version header of the SQLite database file
+func (s *SQLiteDatabase) SetSchemaVersion(version int) error {
+	// PRAGMA statements take no parameters, the version is an integer
+	if _, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		s.logger.Error(context.Background(), "Failed to set SQLite schema version", log.Fields{"error": err, "version": version})
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+	return nil
+}
+
+// fileSize returns the size of the database file and its write-ahead log
+func (s *SQLiteDatabase) fileSize() int64 {
+	var size int64
+	for _, path := range []string{s.path, s.path + "-wal"} {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
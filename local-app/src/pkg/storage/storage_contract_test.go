@@ -0,0 +1,52 @@
+package storage_test
+
+import (
+	"testing"
+
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+	"mindnoscape/local-app/src/pkg/storage/storagetest"
+)
+
+// The contract tests run against the SQLite storage and the memory store, so the fake the other packages test
+// with behaves as the storage it stands in for
+
+func TestUserStoreSQLite(t *testing.T) {
+	storagetest.TestUserStore(t, func(t *testing.T) storage.UserStore {
+		return storage.NewUserStorage(storagetest.NewSQLiteStorage(t, false))
+	})
+}
+
+func TestUserStoreMemory(t *testing.T) {
+	storagetest.TestUserStore(t, func(t *testing.T) storage.UserStore {
+		return storagetest.NewMemoryStore(false)
+	})
+}
+
+func TestMindmapStoreSQLite(t *testing.T) {
+	storagetest.TestMindmapStore(t, func(t *testing.T) (storage.MindmapStore, storage.UserStore) {
+		store := storagetest.NewSQLiteStorage(t, false)
+		return storage.NewMindmapStorage(store), storage.NewUserStorage(store)
+	})
+}
+
+func TestMindmapStoreMemory(t *testing.T) {
+	storagetest.TestMindmapStore(t, func(t *testing.T) (storage.MindmapStore, storage.UserStore) {
+		store := storagetest.NewMemoryStore(false)
+		return store, store
+	})
+}
+
+func TestNodeStoreSQLite(t *testing.T) {
+	storagetest.TestNodeStore(t, func(t *testing.T) (storage.NodeStore, *model.Mindmap) {
+		store := storagetest.NewSQLiteStorage(t, false)
+		return storage.NewNodeStorage(store), storagetest.AddMindmap(t, storage.NewUserStorage(store), storage.NewMindmapStorage(store))
+	})
+}
+
+func TestNodeStoreMemory(t *testing.T) {
+	storagetest.TestNodeStore(t, func(t *testing.T) (storage.NodeStore, *model.Mindmap) {
+		store := storagetest.NewMemoryStore(false)
+		return store, storagetest.AddMindmap(t, store, store)
+	})
+}
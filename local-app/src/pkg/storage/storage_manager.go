@@ -15,9 +15,23 @@ type Storage struct {
 	UserStore
 	MindmapStore
 	NodeStore
-	logger *log.Logger
+	AuditStore
+	UsageStore
+	LinkStore
+	CaptureStore
+	CalendarStore
+	ReminderStore
+	VersionStore
+	caseSensitiveNames bool
+	schemaVersion      int // The schema version of the opened database
+	logger             *log.Logger
 }
 
+// SchemaVersion is the version of the database schema this build creates and migrates to, raised with each change
+// of the schema such as a new column migration. Older versions of Mindnoscape may not know the data of databases
+// with a newer schema and lose or corrupt it when writing to them, so they open such databases read-only.
+const SchemaVersion = 12
+
 // NewStorage creates a new Storage instance and initializes the database.
 func NewStorage(config *model.Config, logger *log.Logger) (*Storage, error) {
 	logger.Info(context.Background(), "Initializing storage", log.Fields{
@@ -41,27 +55,77 @@ func NewStorage(config *model.Config, logger *log.Logger) (*Storage, error) {
 	dataSourceName := filepath.Join(config.DatabaseDir, config.DatabaseFile)
 
 	// Open the database connection
-	if err := db.Open(dataSourceName); err != nil {
+	if err := db.Open(dataSourceName, config.ReadOnly); err != nil {
 		logger.Error(context.Background(), "Failed to open database connection", log.Fields{"error": err, "dataSourceName": dataSourceName})
 		return nil, fmt.Errorf("failed to open database connection '%s': %s", dataSourceName, err)
 	}
 
 	storage := &Storage{
-		db:     db,
-		logger: logger,
+		db:                 db,
+		caseSensitiveNames: config.CaseSensitiveNames,
+		logger:             logger,
 	}
 
-	// Create user and mindmap tables
-	if err := storage.initSchema(); err != nil {
+	storedVersion, err := db.SchemaVersion()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	storage.schemaVersion = storedVersion
+	// A newer schema may hold data this build doesn't know and would lose or corrupt, so the database is only read
+	if storedVersion > SchemaVersion && !config.ReadOnly {
+		logger.Warn(context.Background(), "Database schema is newer than this build, opening it read-only", log.Fields{"schemaVersion": storedVersion, "supported": SchemaVersion})
 		db.Close()
-		logger.Error(context.Background(), "Failed to initialize schema", log.Fields{"error": err})
-		return nil, fmt.Errorf("failed to initialize schema: %s", err)
+		if err := db.Open(dataSourceName, true); err != nil {
+			logger.Error(context.Background(), "Failed to reopen database read-only", log.Fields{"error": err, "dataSourceName": dataSourceName})
+			return nil, fmt.Errorf("failed to reopen database '%s' read-only: %w", dataSourceName, err)
+		}
+		config.ReadOnly = true
+	}
+
+	// A read-only database is used with the schema it has
+	if config.ReadOnly {
+		if err := storage.nameKeysCheck(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	} else {
+		// Create user and mindmap tables
+		if err := storage.initSchema(); err != nil {
+			db.Close()
+			logger.Error(context.Background(), "Failed to initialize schema", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to initialize schema: %s", err)
+		}
+
+		// Bring the normalized name keys in line with the configured case sensitivity
+		if err := storage.migrateNameKeys(); err != nil {
+			db.Close()
+			logger.Error(context.Background(), "Failed to migrate name keys", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to migrate name keys: %w", err)
+		}
+
+		// A newer schema keeps its version, so that it is still reported after the migrations it already has
+		if storedVersion < SchemaVersion {
+			if err := db.SetSchemaVersion(SchemaVersion); err != nil {
+				db.Close()
+				return nil, err
+			}
+			logger.Info(context.Background(), "Database schema migrated", log.Fields{"from": storedVersion, "to": SchemaVersion})
+			storage.schemaVersion = SchemaVersion
+		}
 	}
 
 	// Create storages
 	storage.UserStore = NewUserStorage(storage)
 	storage.MindmapStore = NewMindmapStorage(storage)
 	storage.NodeStore = NewNodeStorage(storage)
+	storage.AuditStore = NewAuditStorage(storage)
+	storage.UsageStore = NewUsageStorage(storage)
+	storage.LinkStore = NewLinkStorage(storage)
+	storage.CaptureStore = NewCaptureStorage(storage)
+	storage.CalendarStore = NewCalendarStorage(storage)
+	storage.ReminderStore = NewReminderStorage(storage)
+	storage.VersionStore = NewVersionStorage(storage)
 
 	logger.Info(context.Background(), "Storage initialized successfully", nil)
 	return storage, nil
@@ -77,6 +141,47 @@ func (s *Storage) Close() error {
 	return nil
 }
 
+// StoredSchemaVersion returns the schema version of the opened database, newer than SchemaVersion if a newer
+// version of Mindnoscape created or migrated it, older if it is opened read-only before being migrated
+func (s *Storage) StoredSchemaVersion() int {
+	return s.schemaVersion
+}
+
+// IntegrityCheck checks the stored data for corruption, returning the problems found, see Database.IntegrityCheck
+func (s *Storage) IntegrityCheck() ([]string, error) {
+	problems, err := s.db.IntegrityCheck()
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to check database integrity", log.Fields{"error": err})
+		return nil, err
+	}
+	if len(problems) > 0 {
+		s.logger.Warn(context.Background(), "Database integrity problems found", log.Fields{"problems": problems})
+	}
+	return problems, nil
+}
+
+// Vacuum compacts the stored data, returning the bytes reclaimed, see Database.Vacuum
+func (s *Storage) Vacuum() (int64, error) {
+	return s.db.Vacuum()
+}
+
+// Snapshot writes a consistent copy of the stored data to a new file at path, see Database.Snapshot
+func (s *Storage) Snapshot(path string) error {
+	return s.db.Snapshot(path)
+}
+
+// columnMigrations are the columns added to tables after their creation, databases created before lack them.
+// Adding one raises SchemaVersion.
+var columnMigrations = []struct {
+	table, column, definition string
+}{
+	{"audit_log", "detail", "TEXT NOT NULL DEFAULT ''"},      // The changes made by audited commands
+	{"users", "default_mindmap", "TEXT NOT NULL DEFAULT ''"}, // The mindmap selected along with a user
+	{"mindmaps", "sort_spec", "TEXT NOT NULL DEFAULT ''"},    // The default order a mindmap is shown in
+	{"users", "inbox", "INTEGER NOT NULL DEFAULT 0"},         // The node of the default mindmap quick adds go under
+	{"mindmaps", "revision", "INTEGER NOT NULL DEFAULT 0"},   // Changes of a mindmap, telling sessions sharing it of them
+}
+
 // initSchema initializes the database schema.
 func (s *Storage) initSchema() error {
 	s.logger.Info(context.Background(), "Initializing database schema", nil)
@@ -84,9 +189,47 @@ func (s *Storage) initSchema() error {
 		s.logger.Error(context.Background(), "Failed to initialize schema", log.Fields{"error": err})
 		return fmt.Errorf("failed to initialize schema: %w", err)
 	}
+
+	for _, m := range columnMigrations {
+		exists, err := s.columnExists(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		s.logger.Info(context.Background(), "Adding column", log.Fields{"table": m.table, "column": m.column})
+		if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)); err != nil {
+			s.logger.Error(context.Background(), "Failed to add column", log.Fields{"error": err, "table": m.table, "column": m.column})
+			return fmt.Errorf("failed to add column %s to %s: %w", m.column, m.table, err)
+		}
+	}
+
+	// Mindmaps created before a table was added to the tables of each mindmap, such as node_tags, lack it
+	return s.migrateMindmapTables()
+}
+
+// migrateMindmapTables creates the missing tables of the stored mindmaps, the existing tables are left as they are
+func (s *Storage) migrateMindmapTables() error {
+	ids, err := s.mindmapIDs()
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query mindmaps", log.Fields{"error": err})
+		return err
+	}
+
+	for _, id := range ids {
+		if err := s.db.CreateMindmapTables(id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Batch runs fn in a single database transaction, see Database.Batch
+func (s *Storage) Batch(fn func() error) error {
+	return s.db.Batch(fn)
+}
+
 // GetDatabase returns the database instance
 func (s *Storage) GetDatabase() Database {
 	return s.db
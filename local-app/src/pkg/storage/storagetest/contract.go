@@ -0,0 +1,612 @@
+package storagetest
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// The contract tests check the behavior the data managers rely on of any UserStore, MindmapStore and NodeStore.
+// A driver's tests call them with a function creating a new, empty store for each subtest, for example:
+//
+//	func TestNodeStore(t *testing.T) {
+//		storagetest.TestNodeStore(t, func(t *testing.T) (storage.NodeStore, *model.Mindmap) {
+//			store := newStore(t)
+//			return store, storagetest.AddMindmap(t, store, store)
+//		})
+//	}
+
+// TestUserStore runs the contract tests of UserStore against the stores made by newStore
+func TestUserStore(t *testing.T, newStore func(t *testing.T) storage.UserStore) {
+	t.Run("AddGet", func(t *testing.T) {
+		store := newStore(t)
+		id := addUser(t, store, "alice")
+		other := addUser(t, store, "bob")
+		if id == other {
+			t.Fatalf("UserAdd returned the same ID %d for two users", id)
+		}
+
+		users := getUsers(t, store, model.UserInfo{Username: "alice"}, model.UserFilter{Username: true}, 1)
+		u := users[0]
+		if u.ID != id || u.Username != "alice" || !u.Active || !bytes.Equal(u.PasswordHash, []byte("hash")) {
+			t.Errorf("UserGet by username = %+v, want user %d 'alice', active, with its password hash", u, id)
+		}
+		if u.DefaultMindmap != "" || u.Inbox != 0 {
+			t.Errorf("new user has default mindmap '%s' and inbox %d, want none", u.DefaultMindmap, u.Inbox)
+		}
+		if u.Created.IsZero() || u.Updated.IsZero() {
+			t.Errorf("new user has no created or updated time: %+v", u)
+		}
+		getUsers(t, store, model.UserInfo{ID: other}, model.UserFilter{ID: true}, 1)
+		getUsers(t, store, model.UserInfo{}, model.UserFilter{}, 2)
+		getUsers(t, store, model.UserInfo{Username: "carol"}, model.UserFilter{Username: true}, 0)
+	})
+
+	t.Run("AddDuplicate", func(t *testing.T) {
+		store := newStore(t)
+		addUser(t, store, "alice")
+		if _, err := store.UserAdd(model.UserInfo{Username: "alice", PasswordHash: []byte("hash"), Active: true}); err == nil {
+			t.Error("UserAdd of a taken username succeeded, want an error")
+		}
+		getUsers(t, store, model.UserInfo{}, model.UserFilter{}, 1)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		store := newStore(t)
+		id := addUser(t, store, "alice")
+		user := getUsers(t, store, model.UserInfo{ID: id}, model.UserFilter{ID: true}, 1)[0]
+
+		update := model.UserInfo{Username: "alicia", PasswordHash: []byte("new"), Active: false, DefaultMindmap: "work", Inbox: 7}
+		filter := model.UserFilter{Username: true, PasswordHash: true, Active: true, DefaultMindmap: true, Inbox: true}
+		if err := store.UserUpdate(user, update, filter); err != nil {
+			t.Fatalf("UserUpdate failed: %v", err)
+		}
+		u := getUsers(t, store, model.UserInfo{ID: id}, model.UserFilter{ID: true}, 1)[0]
+		if u.Username != "alicia" || !bytes.Equal(u.PasswordHash, []byte("new")) || u.Active || u.DefaultMindmap != "work" || u.Inbox != 7 {
+			t.Errorf("updated user = %+v, want %+v", u, update)
+		}
+		getUsers(t, store, model.UserInfo{Active: false}, model.UserFilter{Active: true}, 1)
+
+		// Fields left out of the filter keep their values
+		if err := store.UserUpdate(u, model.UserInfo{Inbox: 9}, model.UserFilter{}); err != nil {
+			t.Fatalf("UserUpdate without fields failed: %v", err)
+		}
+		if u := getUsers(t, store, model.UserInfo{ID: id}, model.UserFilter{ID: true}, 1)[0]; u.Inbox != 7 || u.Username != "alicia" {
+			t.Errorf("UserUpdate changed fields left out of the filter: %+v", u)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+		id := addUser(t, store, "alice")
+		addUser(t, store, "bob")
+		user := getUsers(t, store, model.UserInfo{ID: id}, model.UserFilter{ID: true}, 1)[0]
+		if err := store.UserDelete(user); err != nil {
+			t.Fatalf("UserDelete failed: %v", err)
+		}
+		getUsers(t, store, model.UserInfo{ID: id}, model.UserFilter{ID: true}, 0)
+		getUsers(t, store, model.UserInfo{}, model.UserFilter{}, 1)
+		addUser(t, store, "alice")
+	})
+}
+
+// TestMindmapStore runs the contract tests of MindmapStore against the stores made by newStore, each returned
+// with the user store the owners of its mindmaps are added to
+func TestMindmapStore(t *testing.T, newStore func(t *testing.T) (storage.MindmapStore, storage.UserStore)) {
+	alice, bob := &model.User{Username: "alice"}, &model.User{Username: "bob"}
+	// Owners are users of the store
+	newOwnedStore := func(t *testing.T) storage.MindmapStore {
+		store, userStore := newStore(t)
+		addUser(t, userStore, alice.Username)
+		addUser(t, userStore, bob.Username)
+		return store
+	}
+
+	t.Run("AddGet", func(t *testing.T) {
+		store := newOwnedStore(t)
+		id := addMindmap(t, store, alice, "work", true)
+		other := addMindmap(t, store, alice, "home", false)
+		if id == other {
+			t.Fatalf("MindmapAdd returned the same ID %d for two mindmaps", id)
+		}
+
+		m := getMindmaps(t, store, alice, model.MindmapInfo{Name: "work", Owner: "alice"}, model.MindmapFilter{Name: true, Owner: true}, 1)[0]
+		if m.ID != id || m.Name != "work" || m.Owner != "alice" || !m.IsPublic || !m.Sort.IsZero() {
+			t.Errorf("MindmapGet by name = %+v, want mindmap %d 'work' of alice, public, unsorted", m, id)
+		}
+		if m.Created.IsZero() || m.Updated.IsZero() {
+			t.Errorf("new mindmap has no created or updated time: %+v", m)
+		}
+		getMindmaps(t, store, alice, model.MindmapInfo{ID: other}, model.MindmapFilter{ID: true}, 1)
+		getMindmaps(t, store, alice, model.MindmapInfo{Owner: "alice"}, model.MindmapFilter{Owner: true}, 2)
+		getMindmaps(t, store, alice, model.MindmapInfo{IsPublic: true}, model.MindmapFilter{IsPublic: true}, 1)
+		getMindmaps(t, store, alice, model.MindmapInfo{Owner: "bob"}, model.MindmapFilter{Owner: true}, 0)
+	})
+
+	t.Run("AddDuplicate", func(t *testing.T) {
+		store := newOwnedStore(t)
+		addMindmap(t, store, alice, "work", false)
+		if _, err := store.MindmapAdd(alice, model.MindmapInfo{Name: "work"}); err == nil {
+			t.Error("MindmapAdd of a name the owner already has succeeded, want an error")
+		}
+		// Other owners may use the same name
+		addMindmap(t, store, bob, "work", false)
+		getMindmaps(t, store, alice, model.MindmapInfo{Name: "work"}, model.MindmapFilter{Name: true}, 2)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		store := newOwnedStore(t)
+		id := addMindmap(t, store, alice, "work", false)
+		mindmap := getMindmaps(t, store, alice, model.MindmapInfo{ID: id}, model.MindmapFilter{ID: true}, 1)[0]
+
+		sort, err := model.ParseSortSpec([]string{"due", model.SortDescending, model.SortDate})
+		if err != nil {
+			t.Fatalf("failed to parse sort: %v", err)
+		}
+		update := model.MindmapInfo{Name: "job", Owner: "bob", IsPublic: true, Sort: sort}
+		filter := model.MindmapFilter{Name: true, Owner: true, IsPublic: true, Sort: true}
+		if err := store.MindmapUpdate(mindmap, update, filter); err != nil {
+			t.Fatalf("MindmapUpdate failed: %v", err)
+		}
+		m := getMindmaps(t, store, bob, model.MindmapInfo{ID: id}, model.MindmapFilter{ID: true}, 1)[0]
+		if m.Name != "job" || m.Owner != "bob" || !m.IsPublic || m.Sort != sort {
+			t.Errorf("updated mindmap = %+v, want %+v", m, update)
+		}
+
+		// Fields left out of the filter keep their values
+		if err := store.MindmapUpdate(m, model.MindmapInfo{Name: "other"}, model.MindmapFilter{}); err != nil {
+			t.Fatalf("MindmapUpdate without fields failed: %v", err)
+		}
+		if m := getMindmaps(t, store, bob, model.MindmapInfo{ID: id}, model.MindmapFilter{ID: true}, 1)[0]; m.Name != "job" || m.Sort != sort {
+			t.Errorf("MindmapUpdate changed fields left out of the filter: %+v", m)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newOwnedStore(t)
+		id := addMindmap(t, store, alice, "work", false)
+		addMindmap(t, store, alice, "home", false)
+		mindmap := getMindmaps(t, store, alice, model.MindmapInfo{ID: id}, model.MindmapFilter{ID: true}, 1)[0]
+		if err := store.MindmapDelete(mindmap); err != nil {
+			t.Fatalf("MindmapDelete failed: %v", err)
+		}
+		getMindmaps(t, store, alice, model.MindmapInfo{ID: id}, model.MindmapFilter{ID: true}, 0)
+		getMindmaps(t, store, alice, model.MindmapInfo{}, model.MindmapFilter{}, 1)
+		addMindmap(t, store, alice, "work", false)
+	})
+
+	t.Run("Revise", func(t *testing.T) {
+		store := newOwnedStore(t)
+		id := addMindmap(t, store, alice, "work", false)
+		if m := getMindmaps(t, store, alice, model.MindmapInfo{ID: id}, model.MindmapFilter{ID: true}, 1)[0]; m.Revision != 0 {
+			t.Errorf("new mindmap has revision %d, want 0", m.Revision)
+		}
+
+		revision, err := store.MindmapRevise(id, 0)
+		if err != nil || revision != 1 {
+			t.Fatalf("MindmapRevise of revision 0 = %d, %v, want 1", revision, err)
+		}
+		// A change made at an older revision is refused
+		if _, err := store.MindmapRevise(id, 0); !errors.Is(err, storage.ErrMindmapChanged) {
+			t.Errorf("MindmapRevise of an old revision returned %v, want ErrMindmapChanged", err)
+		}
+		if revision, err := store.MindmapRevision(id); err != nil || revision != 1 {
+			t.Errorf("MindmapRevision = %d, %v, want 1", revision, err)
+		}
+		if m := getMindmaps(t, store, alice, model.MindmapInfo{ID: id}, model.MindmapFilter{ID: true}, 1)[0]; m.Revision != 1 {
+			t.Errorf("MindmapGet returned revision %d, want 1", m.Revision)
+		}
+		if revision, err := store.MindmapRevision(id + 100); err != nil || revision != -1 {
+			t.Errorf("MindmapRevision of a missing mindmap = %d, %v, want -1", revision, err)
+		}
+	})
+}
+
+// TestNodeStore runs the contract tests of NodeStore against the stores made by newStore, each returned with an
+// empty mindmap added to it, see AddMindmap
+func TestNodeStore(t *testing.T, newStore func(t *testing.T) (storage.NodeStore, *model.Mindmap)) {
+	t.Run("AddGet", func(t *testing.T) {
+		store, mindmap := newStore(t)
+		root := addNode(t, store, mindmap, model.NodeInfo{ID: 0, ParentID: -1, Name: mindmap.Name, Index: ""}, true)
+		if root != 0 {
+			t.Fatalf("NodeAdd with forced ID 0 returned ID %d", root)
+		}
+		first := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "First", Index: "1", Content: map[string]string{"due": "2026-10-20", "notes": "a\nb"}}, false)
+		second := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Second", Index: "2"}, false)
+		if first == 0 || first == second {
+			t.Fatalf("NodeAdd returned IDs %d and %d, want distinct IDs other than the root's", first, second)
+		}
+
+		n := getNodes(t, store, mindmap, model.NodeInfo{ID: first}, model.NodeFilter{ID: true}, 1)[0]
+		if n.MindmapID != mindmap.ID || n.ParentID != 0 || n.Name != "First" || n.Index != "1" {
+			t.Errorf("NodeGet by ID = %+v, want node 'First' at index 1 under the root of mindmap %d", n, mindmap.ID)
+		}
+		if want := map[string]string{"due": "2026-10-20", "notes": "a\nb"}; !maps.Equal(n.Content, want) {
+			t.Errorf("node fields = %v, want %v", n.Content, want)
+		}
+		if n.Created.IsZero() || n.Updated.IsZero() {
+			t.Errorf("new node has no created or updated time: %+v", n)
+		}
+		if n := getNodes(t, store, mindmap, model.NodeInfo{ID: second}, model.NodeFilter{ID: true}, 1)[0]; n.Content == nil {
+			t.Error("node without fields has nil content, want an empty map")
+		}
+
+		getNodes(t, store, mindmap, model.NodeInfo{}, model.NodeFilter{}, 3)
+		getNodes(t, store, mindmap, model.NodeInfo{ParentID: 0}, model.NodeFilter{ParentID: true}, 2)
+		getNodes(t, store, mindmap, model.NodeInfo{Name: "Second"}, model.NodeFilter{Name: true}, 1)
+		getNodes(t, store, mindmap, model.NodeInfo{Index: "2"}, model.NodeFilter{Index: true}, 1)
+		getNodes(t, store, mindmap, model.NodeInfo{Name: "Third"}, model.NodeFilter{Name: true}, 0)
+
+		// A forced ID leaves the automatic IDs clear of it
+		forced := addNode(t, store, mindmap, model.NodeInfo{ID: second + 10, ParentID: 0, Name: "Forced", Index: "3"}, true)
+		if next := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Next", Index: "4"}, false); next <= forced {
+			t.Errorf("NodeAdd after forced ID %d returned ID %d, want a greater one", forced, next)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		store, mindmap := newStore(t)
+		addNode(t, store, mindmap, model.NodeInfo{ID: 0, ParentID: -1, Name: mindmap.Name}, true)
+		parent := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Parent", Index: "1"}, false)
+		id := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Child", Index: "2", Content: map[string]string{"a": "1", "b": "2"}}, false)
+		node := getNodes(t, store, mindmap, model.NodeInfo{ID: id}, model.NodeFilter{ID: true}, 1)[0]
+
+		update := model.NodeInfo{ParentID: parent, Name: "Moved", Index: "1.1", Content: map[string]string{"a": "3", "b": "", "c": "4"}}
+		filter := model.NodeFilter{ParentID: true, Name: true, Index: true, Content: true}
+		if err := store.NodeUpdate(mindmap, node, update, filter); err != nil {
+			t.Fatalf("NodeUpdate failed: %v", err)
+		}
+		n := getNodes(t, store, mindmap, model.NodeInfo{ID: id}, model.NodeFilter{ID: true}, 1)[0]
+		if n.ParentID != parent || n.Name != "Moved" || n.Index != "1.1" {
+			t.Errorf("updated node = %+v, want node 'Moved' at index 1.1 under node %d", n, parent)
+		}
+		// Content replaces the fields, empty values removing them
+		if want := map[string]string{"a": "3", "c": "4"}; !maps.Equal(n.Content, want) {
+			t.Errorf("updated node fields = %v, want %v", n.Content, want)
+		}
+		if n.Updated.Before(node.Updated) {
+			t.Errorf("updated node's updated time %v is before the previous %v", n.Updated, node.Updated)
+		}
+
+		// Fields left out of the filter keep their values
+		if err := store.NodeUpdate(mindmap, n, model.NodeInfo{Name: "Other"}, model.NodeFilter{}); err != nil {
+			t.Fatalf("NodeUpdate without fields failed: %v", err)
+		}
+		if n := getNodes(t, store, mindmap, model.NodeInfo{ID: id}, model.NodeFilter{ID: true}, 1)[0]; n.Name != "Moved" || len(n.Content) != 2 {
+			t.Errorf("NodeUpdate changed fields left out of the filter: %+v", n)
+		}
+	})
+
+	t.Run("IndexUpdate", func(t *testing.T) {
+		store, mindmap := newStore(t)
+		addNode(t, store, mindmap, model.NodeInfo{ID: 0, ParentID: -1, Name: mindmap.Name}, true)
+		first := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "First", Index: "1"}, false)
+		second := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Second", Index: "2"}, false)
+
+		if err := store.NodeIndexUpdate(mindmap, map[int]string{first: "2", second: "1"}); err != nil {
+			t.Fatalf("NodeIndexUpdate failed: %v", err)
+		}
+		if err := store.NodeIndexUpdate(mindmap, nil); err != nil {
+			t.Fatalf("NodeIndexUpdate without indexes failed: %v", err)
+		}
+		for id, want := range map[int]string{first: "2", second: "1"} {
+			if n := getNodes(t, store, mindmap, model.NodeInfo{ID: id}, model.NodeFilter{ID: true}, 1)[0]; n.Index != want {
+				t.Errorf("node %d has index '%s' after NodeIndexUpdate, want '%s'", id, n.Index, want)
+			}
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store, mindmap := newStore(t)
+		addNode(t, store, mindmap, model.NodeInfo{ID: 0, ParentID: -1, Name: mindmap.Name}, true)
+		id := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Gone", Index: "1", Content: map[string]string{"a": "1"}}, false)
+		addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Kept", Index: "2"}, false)
+		node := getNodes(t, store, mindmap, model.NodeInfo{ID: id}, model.NodeFilter{ID: true}, 1)[0]
+
+		if err := store.NodeDelete(mindmap, node); err != nil {
+			t.Fatalf("NodeDelete failed: %v", err)
+		}
+		getNodes(t, store, mindmap, model.NodeInfo{ID: id}, model.NodeFilter{ID: true}, 0)
+		getNodes(t, store, mindmap, model.NodeInfo{}, model.NodeFilter{}, 2)
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		store, mindmap := newStore(t)
+		addNode(t, store, mindmap, model.NodeInfo{ID: 0, ParentID: -1, Name: mindmap.Name}, true)
+		first := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "First", Index: "1", Tags: []string{"work", "home"}}, false)
+		second := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Second", Index: "2"}, false)
+		node := getNodes(t, store, mindmap, model.NodeInfo{ID: first}, model.NodeFilter{ID: true}, 1)[0]
+		if want := []string{"home", "work"}; !slices.Equal(node.Tags, want) {
+			t.Errorf("node tags = %v, want %v sorted", node.Tags, want)
+		}
+
+		other := getNodes(t, store, mindmap, model.NodeInfo{ID: second}, model.NodeFilter{ID: true}, 1)[0]
+		if added, err := store.NodeTagAdd(mindmap, other, "work"); err != nil || !added {
+			t.Fatalf("NodeTagAdd = %v, %v, want true", added, err)
+		}
+		if added, err := store.NodeTagAdd(mindmap, other, "work"); err != nil || added {
+			t.Errorf("NodeTagAdd of a tag the node has = %v, %v, want false", added, err)
+		}
+		if ids, err := store.NodeTagFind(mindmap, "work"); err != nil || !slices.Equal(ids, []int{first, second}) {
+			t.Errorf("NodeTagFind = %v, %v, want %v", ids, err, []int{first, second})
+		}
+
+		if deleted, err := store.NodeTagDelete(mindmap, node, "work"); err != nil || !deleted {
+			t.Fatalf("NodeTagDelete = %v, %v, want true", deleted, err)
+		}
+		if deleted, err := store.NodeTagDelete(mindmap, node, "work"); err != nil || deleted {
+			t.Errorf("NodeTagDelete of a tag the node lacks = %v, %v, want false", deleted, err)
+		}
+		if n := getNodes(t, store, mindmap, model.NodeInfo{ID: first}, model.NodeFilter{ID: true}, 1)[0]; !slices.Equal(n.Tags, []string{"home"}) {
+			t.Errorf("node tags after NodeTagDelete = %v, want [home]", n.Tags)
+		}
+
+		// The tags of a deleted node go with it
+		if err := store.NodeDelete(mindmap, other); err != nil {
+			t.Fatalf("NodeDelete failed: %v", err)
+		}
+		if ids, err := store.NodeTagFind(mindmap, "work"); err != nil || len(ids) != 0 {
+			t.Errorf("NodeTagFind after NodeDelete = %v, %v, want no nodes", ids, err)
+		}
+	})
+
+	t.Run("Attachments", func(t *testing.T) {
+		store, mindmap := newStore(t)
+		addNode(t, store, mindmap, model.NodeInfo{ID: 0, ParentID: -1, Name: mindmap.Name}, true)
+		first := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "First", Index: "1"}, false)
+		second := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Second", Index: "2"}, false)
+		node := getNodes(t, store, mindmap, model.NodeInfo{ID: first}, model.NodeFilter{ID: true}, 1)[0]
+		other := getNodes(t, store, mindmap, model.NodeInfo{ID: second}, model.NodeFilter{ID: true}, 1)[0]
+
+		file := model.Attachment{Name: "notes.txt", MediaType: "text/plain", Size: 5, Data: []byte("hello")}
+		if _, err := store.NodeAttachmentAdd(mindmap, node, file); err != nil {
+			t.Fatalf("NodeAttachmentAdd failed: %v", err)
+		}
+		if _, err := store.NodeAttachmentAdd(mindmap, node, model.Attachment{Name: "https://example.com", URL: "https://example.com"}); err != nil {
+			t.Fatalf("NodeAttachmentAdd of a reference failed: %v", err)
+		}
+		if _, err := store.NodeAttachmentAdd(mindmap, node, file); err == nil {
+			t.Error("NodeAttachmentAdd of a name the node has succeeded, want an error")
+		}
+		if _, err := store.NodeAttachmentAdd(mindmap, other, file); err != nil {
+			t.Fatalf("NodeAttachmentAdd of the name to another node failed: %v", err)
+		}
+
+		attachments, err := store.NodeAttachmentGet(mindmap, node, true)
+		if err != nil || len(attachments) != 2 {
+			t.Fatalf("NodeAttachmentGet = %d attachments, %v, want 2", len(attachments), err)
+		}
+		if a := attachments[0]; a.Name != file.Name || a.NodeID != first || a.MediaType != file.MediaType || a.Size != file.Size || !bytes.Equal(a.Data, file.Data) {
+			t.Errorf("NodeAttachmentGet returned %+v, want %+v of node %d", a, file, first)
+		}
+		if a := attachments[1]; a.URL != "https://example.com" || len(a.Data) != 0 {
+			t.Errorf("NodeAttachmentGet returned reference %+v, want its URL without data", a)
+		}
+		if all, err := store.NodeAttachmentGet(mindmap, nil, false); err != nil || len(all) != 3 || all[0].Data != nil {
+			t.Errorf("NodeAttachmentGet of the mindmap = %d attachments, %v, want 3 without data", len(all), err)
+		}
+
+		if deleted, err := store.NodeAttachmentDelete(mindmap, node, file.Name); err != nil || !deleted {
+			t.Fatalf("NodeAttachmentDelete = %v, %v, want true", deleted, err)
+		}
+		if deleted, err := store.NodeAttachmentDelete(mindmap, node, file.Name); err != nil || deleted {
+			t.Errorf("NodeAttachmentDelete of a name the node lacks = %v, %v, want false", deleted, err)
+		}
+
+		// The attachments of a deleted node go with it
+		if err := store.NodeDelete(mindmap, other); err != nil {
+			t.Fatalf("NodeDelete failed: %v", err)
+		}
+		if all, err := store.NodeAttachmentGet(mindmap, nil, false); err != nil || len(all) != 1 {
+			t.Errorf("NodeAttachmentGet after NodeDelete = %d attachments, %v, want 1", len(all), err)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		store, mindmap := newStore(t)
+		addNode(t, store, mindmap, model.NodeInfo{ID: 0, ParentID: -1, Name: mindmap.Name}, true)
+		plan := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Project plan", Index: "1", Content: map[string]string{"status": "draft"}}, false)
+		notes := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Notes", Index: "2", Content: map[string]string{"about": "the project budget"}}, false)
+		addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Other", Index: "3"}, false)
+
+		search := func(query string, filter model.NodeFilter) ([]int, error) {
+			t.Helper()
+			q, err := model.ParseNodeQuery(query)
+			if err != nil {
+				t.Fatalf("ParseNodeQuery(%q) failed: %v", query, err)
+			}
+			return store.NodeSearch(mindmap, q, filter)
+		}
+		all := model.NodeFilter{Name: true, Content: true}
+
+		// Regular expressions are never searched by a store
+		if _, err := search("/^proj/", all); !errors.Is(err, storage.ErrSearchUnsupported) {
+			t.Fatalf("NodeSearch of a regular expression = %v, want ErrSearchUnsupported", err)
+		}
+		if _, err := search("project", all); errors.Is(err, storage.ErrSearchUnsupported) {
+			t.Skip("store has no search index")
+		}
+
+		for _, c := range []struct {
+			query  string
+			filter model.NodeFilter
+			want   []int
+		}{
+			{"PROJECT", all, []int{plan, notes}},
+			{"project", model.NodeFilter{Name: true}, []int{plan}},
+			{"name:project", all, []int{plan}},
+			{"content:status", all, []int{plan}},
+			{"project budget", all, []int{notes}},
+			{"draft OR budget", all, []int{plan, notes}},
+			{"missing", all, nil},
+		} {
+			ids, err := search(c.query, c.filter)
+			if err != nil {
+				t.Fatalf("NodeSearch(%q) failed: %v", c.query, err)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, c.want) {
+				t.Errorf("NodeSearch(%q) = %v, want %v", c.query, ids, c.want)
+			}
+		}
+
+		// The index follows the changes of the nodes
+		node := getNodes(t, store, mindmap, model.NodeInfo{ID: plan}, model.NodeFilter{ID: true}, 1)[0]
+		if err := store.NodeUpdate(mindmap, node, model.NodeInfo{Name: "Schedule", Content: map[string]string{"status": ""}}, model.NodeFilter{Name: true, Content: true}); err != nil {
+			t.Fatalf("NodeUpdate failed: %v", err)
+		}
+		if ids, err := search("schedule", all); err != nil || !slices.Equal(ids, []int{plan}) {
+			t.Errorf("NodeSearch of an updated name = %v, %v, want [%d]", ids, err, plan)
+		}
+		if ids, err := search("draft", all); err != nil || len(ids) != 0 {
+			t.Errorf("NodeSearch of a removed field = %v, %v, want none", ids, err)
+		}
+		if err := store.NodeDelete(mindmap, getNodes(t, store, mindmap, model.NodeInfo{ID: notes}, model.NodeFilter{ID: true}, 1)[0]); err != nil {
+			t.Fatalf("NodeDelete failed: %v", err)
+		}
+		if ids, err := search("budget", all); err != nil || len(ids) != 0 {
+			t.Errorf("NodeSearch of a deleted node = %v, %v, want none", ids, err)
+		}
+	})
+
+	t.Run("GetLevels", func(t *testing.T) {
+		store, mindmap := newStore(t)
+		addNode(t, store, mindmap, model.NodeInfo{ID: 0, ParentID: -1, Name: mindmap.Name}, true)
+		parent := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Parent", Index: "1"}, false)
+		child := addNode(t, store, mindmap, model.NodeInfo{ParentID: parent, Name: "Child", Index: "1.1"}, false)
+		addNode(t, store, mindmap, model.NodeInfo{ParentID: child, Name: "Grandchild", Index: "1.1.1"}, false)
+		addNode(t, store, mindmap, model.NodeInfo{ParentID: child, Name: "Grandchild 2", Index: "1.1.2"}, false)
+		addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Sibling", Index: "2"}, false)
+		checkLevels := func(index string, levels int, wantNames []string, wantUnloaded map[int]int) {
+			t.Helper()
+			nodes, unloaded, err := store.NodeGetLevels(mindmap, index, levels)
+			if err != nil {
+				t.Fatalf("NodeGetLevels failed: %v", err)
+			}
+			var names []string
+			for _, n := range nodes {
+				names = append(names, n.Name)
+			}
+			slices.Sort(names)
+			slices.Sort(wantNames)
+			if !slices.Equal(names, wantNames) {
+				t.Errorf("NodeGetLevels(%s, %d) nodes = %v, want %v", index, levels, names, wantNames)
+			}
+			if !maps.Equal(unloaded, wantUnloaded) {
+				t.Errorf("NodeGetLevels(%s, %d) unloaded = %v, want %v", index, levels, unloaded, wantUnloaded)
+			}
+		}
+
+		checkLevels("0", 1, []string{mindmap.Name, "Parent", "Sibling"}, map[int]int{parent: 1})
+		checkLevels("0", 3, []string{mindmap.Name, "Child", "Grandchild", "Grandchild 2", "Parent", "Sibling"}, map[int]int{})
+		checkLevels("1", 1, []string{"Child", "Parent"}, map[int]int{child: 2})
+		checkLevels("1.1", 0, []string{"Child"}, map[int]int{child: 2})
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		store, mindmap := newStore(t)
+		checkStats := func(want model.MindmapStats) {
+			t.Helper()
+			stats, err := store.NodeStats(mindmap)
+			if err != nil {
+				t.Fatalf("NodeStats failed: %v", err)
+			}
+			if stats != want {
+				t.Errorf("NodeStats = %+v, want %+v", stats, want)
+			}
+		}
+
+		checkStats(model.MindmapStats{})
+		addNode(t, store, mindmap, model.NodeInfo{ID: 0, ParentID: -1, Name: mindmap.Name}, true)
+		checkStats(model.MindmapStats{NodeCount: 1, Depth: 1})
+		parent := addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Parent", Index: "1"}, false)
+		addNode(t, store, mindmap, model.NodeInfo{ParentID: parent, Name: "Child", Index: "1.1"}, false)
+		addNode(t, store, mindmap, model.NodeInfo{ParentID: 0, Name: "Sibling", Index: "2"}, false)
+		checkStats(model.MindmapStats{NodeCount: 4, Depth: 3})
+	})
+}
+
+// AddMindmap adds an empty mindmap to a store, for the stores the node contract tests run against
+func AddMindmap(t testing.TB, userStore storage.UserStore, mindmapStore storage.MindmapStore) *model.Mindmap {
+	t.Helper()
+	user := &model.User{Username: "owner"}
+	if _, err := userStore.UserAdd(model.UserInfo{Username: user.Username, PasswordHash: []byte("hash"), Active: true}); err != nil {
+		t.Fatalf("UserAdd failed: %v", err)
+	}
+	id := addMindmap(t, mindmapStore, user, "nodes", false)
+	return getMindmaps(t, mindmapStore, user, model.MindmapInfo{ID: id}, model.MindmapFilter{ID: true}, 1)[0]
+}
+
+// addUser adds an active user with a password hash, failing the test on errors
+func addUser(t testing.TB, store storage.UserStore, username string) int {
+	t.Helper()
+	id, err := store.UserAdd(model.UserInfo{Username: username, PasswordHash: []byte("hash"), Active: true})
+	if err != nil {
+		t.Fatalf("UserAdd of '%s' failed: %v", username, err)
+	}
+	return id
+}
+
+// getUsers gets users, failing the test on errors or unless want users are returned
+func getUsers(t testing.TB, store storage.UserStore, info model.UserInfo, filter model.UserFilter, want int) []*model.User {
+	t.Helper()
+	users, err := store.UserGet(info, filter)
+	if err != nil {
+		t.Fatalf("UserGet failed: %v", err)
+	}
+	if len(users) != want {
+		t.Fatalf("UserGet of %+v by %+v returned %d users, want %d", info, filter, len(users), want)
+	}
+	return users
+}
+
+// addMindmap adds a mindmap, failing the test on errors
+func addMindmap(t testing.TB, store storage.MindmapStore, user *model.User, name string, public bool) int {
+	t.Helper()
+	id, err := store.MindmapAdd(user, model.MindmapInfo{Name: name, IsPublic: public})
+	if err != nil {
+		t.Fatalf("MindmapAdd of '%s' failed: %v", name, err)
+	}
+	return id
+}
+
+// getMindmaps gets mindmaps, failing the test on errors or unless want mindmaps are returned
+func getMindmaps(t testing.TB, store storage.MindmapStore, user *model.User, info model.MindmapInfo, filter model.MindmapFilter, want int) []*model.Mindmap {
+	t.Helper()
+	mindmaps, err := store.MindmapGet(user, info, filter)
+	if err != nil {
+		t.Fatalf("MindmapGet failed: %v", err)
+	}
+	if len(mindmaps) != want {
+		t.Fatalf("MindmapGet of %+v by %+v returned %d mindmaps, want %d", info, filter, len(mindmaps), want)
+	}
+	return mindmaps
+}
+
+// addNode adds a node, with the ID of info if forceID is set, failing the test on errors
+func addNode(t testing.TB, store storage.NodeStore, mindmap *model.Mindmap, info model.NodeInfo, forceID bool) int {
+	t.Helper()
+	id, err := store.NodeAdd(mindmap, info, forceID)
+	if err != nil {
+		t.Fatalf("NodeAdd of '%s' failed: %v", info.Name, err)
+	}
+	return id
+}
+
+// getNodes gets nodes, failing the test on errors or unless want nodes are returned
+func getNodes(t testing.TB, store storage.NodeStore, mindmap *model.Mindmap, info model.NodeInfo, filter model.NodeFilter, want int) []*model.Node {
+	t.Helper()
+	nodes, err := store.NodeGet(mindmap, info, filter)
+	if err != nil {
+		t.Fatalf("NodeGet failed: %v", err)
+	}
+	if len(nodes) != want {
+		t.Fatalf("NodeGet of %+v by %+v returned %d nodes, want %d", info, filter, len(nodes), want)
+	}
+	return nodes
+}
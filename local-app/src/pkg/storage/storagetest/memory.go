@@ -0,0 +1,617 @@
+// Package storagetest provides test doubles of the storage interfaces and the contract tests any implementation
+// of them must pass, so new storage drivers are validated the same way as the SQLite storage.
+package storagetest
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/names"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// MemoryStore is an in-memory fake of the user, mindmap and node stores, behaving as the SQLite storage does.
+// It is safe for concurrent use and hands out copies, so changes to returned values don't reach the store.
+type MemoryStore struct {
+	mu            sync.Mutex
+	caseSensitive bool
+	users         map[int]*model.User
+	mindmaps      map[int]*model.Mindmap
+	nodes         map[int]map[int]*model.Node // Nodes by node ID, by mindmap ID
+	attachments   map[int][]*model.Attachment // Node attachments in the order added, by mindmap ID
+	nextUserID    int
+	nextMindmapID int
+	nextNodeID    map[int]int // Next automatic node ID, by mindmap ID
+	nextAttachID  int
+}
+
+var (
+	_ storage.UserStore    = (*MemoryStore)(nil)
+	_ storage.MindmapStore = (*MemoryStore)(nil)
+	_ storage.NodeStore    = (*MemoryStore)(nil)
+)
+
+// NewMemoryStore creates an empty MemoryStore, comparing user and mindmap names case-sensitively if caseSensitive
+// is set, as configured by case_sensitive_names
+func NewMemoryStore(caseSensitive bool) *MemoryStore {
+	return &MemoryStore{
+		caseSensitive: caseSensitive,
+		users:         make(map[int]*model.User),
+		mindmaps:      make(map[int]*model.Mindmap),
+		nodes:         make(map[int]map[int]*model.Node),
+		attachments:   make(map[int][]*model.Attachment),
+		nextUserID:    1,
+		nextMindmapID: 1,
+		nextNodeID:    make(map[int]int),
+		nextAttachID:  1,
+	}
+}
+
+// UserAdd adds a new user, refusing a username already taken under name normalization
+func (s *MemoryStore) UserAdd(newUser model.UserInfo) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if names.Equal(u.Username, newUser.Username, s.caseSensitive) {
+			return 0, fmt.Errorf("failed to add user: username '%s' already exists", newUser.Username)
+		}
+	}
+
+	now := time.Now()
+	id := s.nextUserID
+	s.nextUserID++
+	s.users[id] = &model.User{
+		ID:           id,
+		Username:     newUser.Username,
+		PasswordHash: slices.Clone(newUser.PasswordHash),
+		Active:       newUser.Active,
+		Created:      now,
+		Updated:      now,
+	}
+	return id, nil
+}
+
+// UserGet returns the users matching the fields of userInfo selected by userFilter, in the order they were added
+func (s *MemoryStore) UserGet(userInfo model.UserInfo, userFilter model.UserFilter) ([]*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var users []*model.User
+	for _, id := range slices.Sorted(maps.Keys(s.users)) {
+		u := s.users[id]
+		if (userFilter.ID && u.ID != userInfo.ID) ||
+			(userFilter.Username && !names.Equal(u.Username, userInfo.Username, s.caseSensitive)) ||
+			(userFilter.Active && u.Active != userInfo.Active) {
+			continue
+		}
+		users = append(users, copyUser(u))
+	}
+	return users, nil
+}
+
+// UserUpdate sets the fields of userUpdateInfo selected by userFilter on a user, a missing user is not an error
+func (s *MemoryStore) UserUpdate(user *model.User, userUpdateInfo model.UserInfo, userFilter model.UserFilter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[user.ID]
+	if !ok {
+		return nil
+	}
+	if userFilter.Username {
+		for _, other := range s.users {
+			if other.ID != u.ID && names.Equal(other.Username, userUpdateInfo.Username, s.caseSensitive) {
+				return fmt.Errorf("failed to update user: username '%s' already exists", userUpdateInfo.Username)
+			}
+		}
+		u.Username = userUpdateInfo.Username
+	}
+	if userFilter.PasswordHash {
+		u.PasswordHash = slices.Clone(userUpdateInfo.PasswordHash)
+	}
+	if userFilter.Active {
+		u.Active = userUpdateInfo.Active
+	}
+	if userFilter.DefaultMindmap {
+		u.DefaultMindmap = userUpdateInfo.DefaultMindmap
+	}
+	if userFilter.Inbox {
+		u.Inbox = userUpdateInfo.Inbox
+	}
+	u.Updated = time.Now()
+	return nil
+}
+
+// UserDelete removes a user, leaving its mindmaps as the SQLite storage does
+func (s *MemoryStore) UserDelete(user *model.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, user.ID)
+	return nil
+}
+
+// MindmapAdd adds a new mindmap owned by user, refusing a name the user already has under name normalization and
+// owners that are not users
+func (s *MemoryStore) MindmapAdd(user *model.User, newMindmapInfo model.MindmapInfo) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.userExists(user.Username) {
+		return 0, fmt.Errorf("failed to add mindmap: owner '%s' not found", user.Username)
+	}
+	for _, m := range s.mindmaps {
+		if m.Owner == user.Username && names.Equal(m.Name, newMindmapInfo.Name, s.caseSensitive) {
+			return 0, fmt.Errorf("mindmap with name '%s' already exists for this user", newMindmapInfo.Name)
+		}
+	}
+
+	now := time.Now()
+	id := s.nextMindmapID
+	s.nextMindmapID++
+	s.mindmaps[id] = &model.Mindmap{
+		ID:       id,
+		Name:     newMindmapInfo.Name,
+		Owner:    user.Username,
+		IsPublic: newMindmapInfo.IsPublic,
+		Created:  now,
+		Updated:  now,
+	}
+	s.nodes[id] = make(map[int]*model.Node)
+	s.nextNodeID[id] = 1
+	return id, nil
+}
+
+// MindmapGet returns the mindmaps matching the fields of mindmapInfo selected by mindmapFilter, without their
+// nodes, in the order they were added
+func (s *MemoryStore) MindmapGet(_ *model.User, mindmapInfo model.MindmapInfo, mindmapFilter model.MindmapFilter) ([]*model.Mindmap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var mindmaps []*model.Mindmap
+	for _, id := range slices.Sorted(maps.Keys(s.mindmaps)) {
+		m := s.mindmaps[id]
+		if (mindmapFilter.ID && m.ID != mindmapInfo.ID) ||
+			(mindmapFilter.Name && !names.Equal(m.Name, mindmapInfo.Name, s.caseSensitive)) ||
+			(mindmapFilter.Owner && m.Owner != mindmapInfo.Owner) ||
+			(mindmapFilter.IsPublic && m.IsPublic != mindmapInfo.IsPublic) {
+			continue
+		}
+		copied := *m
+		mindmaps = append(mindmaps, &copied)
+	}
+	return mindmaps, nil
+}
+
+// MindmapUpdate sets the fields of mindmapUpdateInfo selected by mindmapFilter on a mindmap, a missing mindmap is
+// not an error
+func (s *MemoryStore) MindmapUpdate(mindmap *model.Mindmap, mindmapUpdateInfo model.MindmapInfo, mindmapFilter model.MindmapFilter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.mindmaps[mindmap.ID]
+	if !ok {
+		return nil
+	}
+	if mindmapFilter.Owner && !s.userExists(mindmapUpdateInfo.Owner) {
+		return fmt.Errorf("failed to update mindmap: owner '%s' not found", mindmapUpdateInfo.Owner)
+	}
+	if mindmapFilter.Name {
+		m.Name = mindmapUpdateInfo.Name
+	}
+	if mindmapFilter.Owner {
+		m.Owner = mindmapUpdateInfo.Owner
+	}
+	if mindmapFilter.IsPublic {
+		m.IsPublic = mindmapUpdateInfo.IsPublic
+	}
+	if mindmapFilter.Sort {
+		m.Sort = mindmapUpdateInfo.Sort
+	}
+	m.Updated = time.Now()
+	return nil
+}
+
+// MindmapDelete removes a mindmap and all its nodes
+func (s *MemoryStore) MindmapDelete(mindmap *model.Mindmap) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.mindmaps, mindmap.ID)
+	delete(s.nodes, mindmap.ID)
+	delete(s.attachments, mindmap.ID)
+	delete(s.nextNodeID, mindmap.ID)
+	return nil
+}
+
+// MindmapRevision returns the revision of a mindmap, -1 for a missing mindmap
+func (s *MemoryStore) MindmapRevision(mindmapID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m, ok := s.mindmaps[mindmapID]; ok {
+		return m.Revision, nil
+	}
+	return -1, nil
+}
+
+// MindmapRevise raises the revision of a mindmap if it is the given one
+func (s *MemoryStore) MindmapRevise(mindmapID, revision int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.mindmaps[mindmapID]
+	if !ok || m.Revision != revision {
+		return 0, storage.ErrMindmapChanged
+	}
+	m.Revision++
+	return m.Revision, nil
+}
+
+// NodeAdd adds a new node to a mindmap, with the ID of newNodeInfo if forceID is set and the next free ID otherwise
+func (s *MemoryStore) NodeAdd(mindmap *model.Mindmap, newNodeInfo model.NodeInfo, forceID ...bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add node: %w", err)
+	}
+
+	id := s.nextNodeID[mindmap.ID]
+	if len(forceID) > 0 && forceID[0] {
+		id = newNodeInfo.ID
+		if _, exists := nodes[id]; exists {
+			return 0, fmt.Errorf("failed to add node with forced ID: node %d already exists", id)
+		}
+	}
+	s.nextNodeID[mindmap.ID] = max(s.nextNodeID[mindmap.ID], id+1)
+
+	now := time.Now()
+	nodes[id] = &model.Node{
+		ID:        id,
+		MindmapID: mindmap.ID,
+		ParentID:  newNodeInfo.ParentID,
+		Name:      newNodeInfo.Name,
+		Index:     newNodeInfo.Index,
+		Content:   make(map[string]string, len(newNodeInfo.Content)),
+		Created:   now,
+		Updated:   now,
+	}
+	maps.Copy(nodes[id].Content, newNodeInfo.Content)
+	for _, tag := range newNodeInfo.Tags {
+		if !slices.Contains(nodes[id].Tags, tag) {
+			nodes[id].Tags = append(nodes[id].Tags, tag)
+		}
+	}
+	slices.Sort(nodes[id].Tags)
+	return id, nil
+}
+
+// NodeGet returns the nodes of a mindmap matching the fields of nodeInfo selected by nodeFilter, with their fields
+// and without their children, by node ID
+func (s *MemoryStore) NodeGet(mindmap *model.Mindmap, nodeInfo model.NodeInfo, nodeFilter model.NodeFilter) ([]*model.Node, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nodes: %w", err)
+	}
+
+	var nodes []*model.Node
+	for _, id := range slices.Sorted(maps.Keys(all)) {
+		n := all[id]
+		if (nodeFilter.ID && n.ID != nodeInfo.ID) ||
+			(nodeFilter.ParentID && n.ParentID != nodeInfo.ParentID) ||
+			(nodeFilter.Name && n.Name != nodeInfo.Name) ||
+			(nodeFilter.Index && n.Index != nodeInfo.Index) {
+			continue
+		}
+		copied := *n
+		copied.Content = maps.Clone(n.Content)
+		copied.Tags = slices.Clone(n.Tags)
+		nodes = append(nodes, &copied)
+	}
+	return nodes, nil
+}
+
+// NodeGetLevels returns the node of an index and its descendants down to levels below it, and the number of
+// children of the nodes of the last level
+func (s *MemoryStore) NodeGetLevels(mindmap *model.Mindmap, index string, levels int) ([]*model.Node, map[int]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query nodes: %w", err)
+	}
+
+	last := model.IndexLevel(index) + levels
+	var nodes []*model.Node
+	unloaded := make(map[int]int)
+	for _, id := range slices.Sorted(maps.Keys(all)) {
+		n := all[id]
+		level := 0
+		if n.ID != 0 {
+			level = model.IndexLevel(n.Index)
+		}
+		if index != "0" && n.Index != index && !strings.HasPrefix(n.Index, index+".") {
+			continue
+		}
+		switch {
+		case level == last+1:
+			unloaded[n.ParentID]++
+		case level <= last:
+			copied := *n
+			copied.Content = maps.Clone(n.Content)
+			copied.Tags = slices.Clone(n.Tags)
+			nodes = append(nodes, &copied)
+		}
+	}
+	return nodes, unloaded, nil
+}
+
+// NodeUpdate sets the fields of nodeUpdateInfo selected by nodeUpdateFilter on a node. Content replaces all the
+// fields of the node, leaving out those with empty values.
+func (s *MemoryStore) NodeUpdate(mindmap *model.Mindmap, node *model.Node, nodeUpdateInfo model.NodeInfo, nodeUpdateFilter model.NodeFilter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+	n, ok := nodes[node.ID]
+	if !ok {
+		return nil
+	}
+
+	changed := nodeUpdateFilter.Content
+	if nodeUpdateFilter.Name {
+		n.Name, changed = nodeUpdateInfo.Name, true
+	}
+	if nodeUpdateFilter.ParentID {
+		n.ParentID, changed = nodeUpdateInfo.ParentID, true
+	}
+	if nodeUpdateFilter.Index {
+		n.Index, changed = nodeUpdateInfo.Index, true
+	}
+	if nodeUpdateFilter.Content {
+		n.Content = copyContent(nodeUpdateInfo.Content)
+	}
+	if changed {
+		n.Updated = time.Now()
+	}
+	return nil
+}
+
+// NodeIndexUpdate sets the indexes of many nodes of a mindmap, given by node ID
+func (s *MemoryStore) NodeIndexUpdate(mindmap *model.Mindmap, indexes map[int]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(indexes) == 0 {
+		return nil
+	}
+	nodes, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return fmt.Errorf("failed to update node indexes: %w", err)
+	}
+	now := time.Now()
+	for id, index := range indexes {
+		if n, ok := nodes[id]; ok {
+			n.Index, n.Updated = index, now
+		}
+	}
+	return nil
+}
+
+// NodeDelete removes a node of a mindmap, leaving its children as the SQLite storage does
+func (s *MemoryStore) NodeDelete(mindmap *model.Mindmap, node *model.Node) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return fmt.Errorf("failed to delete node: %w", err)
+	}
+	delete(nodes, node.ID)
+	s.attachments[mindmap.ID] = slices.DeleteFunc(s.attachments[mindmap.ID], func(a *model.Attachment) bool { return a.NodeID == node.ID })
+	return nil
+}
+
+// NodeStats counts the nodes of a mindmap and computes its depth from the node indexes
+func (s *MemoryStore) NodeStats(mindmap *model.Mindmap) (model.MindmapStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return model.MindmapStats{}, fmt.Errorf("failed to compute node stats: %w", err)
+	}
+	stats := model.MindmapStats{NodeCount: len(nodes)}
+	for _, n := range nodes {
+		depth := 1
+		if n.ID != 0 {
+			depth = strings.Count(n.Index, ".") + 2
+		}
+		stats.Depth = max(stats.Depth, depth)
+	}
+	return stats, nil
+}
+
+// NodeTagAdd tags a node of a mindmap, returning false if it has the tag already
+func (s *MemoryStore) NodeTagAdd(mindmap *model.Mindmap, node *model.Node, tag string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return false, fmt.Errorf("failed to change node tag: %w", err)
+	}
+	n, ok := nodes[node.ID]
+	if !ok || slices.Contains(n.Tags, tag) {
+		return false, nil
+	}
+	n.Tags = append(n.Tags, tag)
+	slices.Sort(n.Tags)
+	n.Updated = time.Now()
+	return true, nil
+}
+
+// NodeTagDelete removes a tag from a node of a mindmap, returning false if it has no such tag
+func (s *MemoryStore) NodeTagDelete(mindmap *model.Mindmap, node *model.Node, tag string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return false, fmt.Errorf("failed to change node tag: %w", err)
+	}
+	n, ok := nodes[node.ID]
+	if !ok {
+		return false, nil
+	}
+	i := slices.Index(n.Tags, tag)
+	if i < 0 {
+		return false, nil
+	}
+	n.Tags = slices.Delete(n.Tags, i, i+1)
+	n.Updated = time.Now()
+	return true, nil
+}
+
+// NodeTagFind returns the IDs of the nodes of a mindmap with a tag, in ID order
+func (s *MemoryStore) NodeTagFind(mindmap *model.Mindmap, tag string) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tagged nodes: %w", err)
+	}
+	var ids []int
+	for _, id := range slices.Sorted(maps.Keys(nodes)) {
+		if slices.Contains(nodes[id].Tags, tag) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// userExists reports whether a user of the exact username exists, the owners of mindmaps must
+func (s *MemoryStore) userExists(username string) bool {
+	for _, u := range s.users {
+		if u.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeAttachmentAdd adds an attachment to a node of a mindmap, refusing a name the node has already
+func (s *MemoryStore) NodeAttachmentAdd(mindmap *model.Mindmap, node *model.Node, attachment model.Attachment) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, err := s.mindmapNodes(mindmap)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add node attachment: %w", err)
+	}
+	if _, ok := nodes[node.ID]; !ok {
+		return 0, fmt.Errorf("failed to add node attachment: node %d not found", node.ID)
+	}
+	for _, a := range s.attachments[mindmap.ID] {
+		if a.NodeID == node.ID && a.Name == attachment.Name {
+			return 0, fmt.Errorf("failed to add node attachment: node %d has an attachment named '%s'", node.ID, attachment.Name)
+		}
+	}
+
+	attachment.ID = s.nextAttachID
+	s.nextAttachID++
+	attachment.NodeID = node.ID
+	attachment.Data = slices.Clone(attachment.Data)
+	attachment.Created = time.Now()
+	s.attachments[mindmap.ID] = append(s.attachments[mindmap.ID], &attachment)
+	return attachment.ID, nil
+}
+
+// NodeAttachmentGet returns copies of the attachments of a node, or of all nodes of a mindmap if node is nil, in
+// the order they were added, with their data if withData is set
+func (s *MemoryStore) NodeAttachmentGet(mindmap *model.Mindmap, node *model.Node, withData bool) ([]*model.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.mindmapNodes(mindmap); err != nil {
+		return nil, fmt.Errorf("failed to query node attachments: %w", err)
+	}
+	var attachments []*model.Attachment
+	for _, a := range s.attachments[mindmap.ID] {
+		if node != nil && a.NodeID != node.ID {
+			continue
+		}
+		copied := *a
+		copied.Data = nil
+		if withData {
+			copied.Data = slices.Clone(a.Data)
+		}
+		attachments = append(attachments, &copied)
+	}
+	return attachments, nil
+}
+
+// NodeAttachmentDelete removes an attachment of a node of a mindmap by name, returning false if there is none
+func (s *MemoryStore) NodeAttachmentDelete(mindmap *model.Mindmap, node *model.Node, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.mindmapNodes(mindmap); err != nil {
+		return false, fmt.Errorf("failed to delete node attachment: %w", err)
+	}
+	i := slices.IndexFunc(s.attachments[mindmap.ID], func(a *model.Attachment) bool { return a.NodeID == node.ID && a.Name == name })
+	if i < 0 {
+		return false, nil
+	}
+	s.attachments[mindmap.ID] = slices.Delete(s.attachments[mindmap.ID], i, i+1)
+	return true, nil
+}
+
+// NodeSearch has no index to search, the nodes are matched against the loaded mindmap instead
+func (s *MemoryStore) NodeSearch(mindmap *model.Mindmap, query model.NodeQuery, nodeFilter model.NodeFilter) ([]int, error) {
+	return nil, storage.ErrSearchUnsupported
+}
+
+// mindmapNodes returns the nodes of a mindmap, which must have been added
+func (s *MemoryStore) mindmapNodes(mindmap *model.Mindmap) (map[int]*model.Node, error) {
+	nodes, ok := s.nodes[mindmap.ID]
+	if !ok {
+		return nil, fmt.Errorf("mindmap %d not found", mindmap.ID)
+	}
+	return nodes, nil
+}
+
+// copyUser returns a copy of a stored user, without its mindmaps
+func copyUser(u *model.User) *model.User {
+	copied := *u
+	copied.PasswordHash = slices.Clone(u.PasswordHash)
+	copied.Mindmaps = nil
+	return &copied
+}
+
+// copyContent returns a copy of the fields of a node without the empty ones, which updates remove
+func copyContent(content map[string]string) map[string]string {
+	copied := make(map[string]string, len(content))
+	for key, value := range content {
+		if value != "" {
+			copied[key] = value
+		}
+	}
+	return copied
+}
@@ -0,0 +1,41 @@
+package storagetest
+
+import (
+	"testing"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+	"mindnoscape/local-app/src/pkg/storage"
+)
+
+// NewSQLiteStorage opens a SQLite storage in a temporary directory of the test, closed and removed when the test
+// ends, to run the contract tests against the reference implementation or compare a driver with it
+func NewSQLiteStorage(t testing.TB, caseSensitive bool) *storage.Storage {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &model.Config{
+		DatabaseType:       string(storage.SQLite),
+		DatabaseDir:        dir,
+		DatabaseFile:       "mindnoscape.db",
+		CaseSensitiveNames: caseSensitive,
+		LogFolder:          dir,
+		CommandLog:         "command.log",
+		ErrorLog:           "error.log",
+		InfoLog:            "info.log",
+	}
+
+	logger, err := log.NewLogger(cfg, log.LevelError)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	store, err := storage.NewStorage(cfg, logger)
+	if err != nil {
+		logger.Close()
+		t.Fatalf("failed to open storage: %v", err)
+	}
+	t.Cleanup(func() {
+		store.Close()
+		logger.Close()
+	})
+	return store
+}
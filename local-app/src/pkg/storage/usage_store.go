@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// UsageStore defines the interface for command telemetry storage operations.
+type UsageStore interface {
+	UsageAdd(scope, operation string, failed bool) error
+	UsageGet() ([]*model.CommandUsage, error)
+	UsageReset() error
+}
+
+// UsageStorage implements the UsageStore interface.
+type UsageStorage struct {
+	storage *Storage
+	logger  *log.Logger
+}
+
+// NewUsageStorage creates a new UsageStorage instance.
+func NewUsageStorage(storage *Storage) *UsageStorage {
+	return &UsageStorage{
+		storage: storage,
+		logger:  storage.logger,
+	}
+}
+
+// UsageAdd counts a use of a command, and its failure if it failed.
+func (s *UsageStorage) UsageAdd(scope, operation string, failed bool) error {
+	s.logger.Debug(context.Background(), "Adding command usage", log.Fields{"scope": scope, "operation": operation, "failed": failed})
+
+	errors := 0
+	if failed {
+		errors = 1
+	}
+
+	db := s.storage.GetDatabase()
+	_, err := db.Exec(
+		`INSERT INTO command_usage (scope, operation, count, errors) VALUES (?, ?, 1, ?)
+		ON CONFLICT (scope, operation) DO UPDATE SET count = count + 1, errors = errors + excluded.errors`,
+		scope, operation, errors,
+	)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to add command usage", log.Fields{"error": err})
+		return fmt.Errorf("failed to add command usage: %w", err)
+	}
+	return nil
+}
+
+// UsageGet retrieves the usage of all commands used, most used first.
+func (s *UsageStorage) UsageGet() ([]*model.CommandUsage, error) {
+	s.logger.Info(context.Background(), "Retrieving command usage", nil)
+
+	db := s.storage.GetDatabase()
+	rows, err := db.Query("SELECT scope, operation, count, errors FROM command_usage ORDER BY count DESC, scope, operation")
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query command usage", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to query command usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*model.CommandUsage
+	for rows.Next() {
+		var u model.CommandUsage
+		if err := rows.Scan(&u.Scope, &u.Operation, &u.Count, &u.Errors); err != nil {
+			s.logger.Error(context.Background(), "Failed to scan command usage", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to scan command usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Failed to read command usage", log.Fields{"error": err})
+		return nil, fmt.Errorf("failed to read command usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// UsageReset deletes the recorded usage of all commands.
+func (s *UsageStorage) UsageReset() error {
+	s.logger.Info(context.Background(), "Resetting command usage", nil)
+
+	db := s.storage.GetDatabase()
+	if _, err := db.Exec("DELETE FROM command_usage"); err != nil {
+		s.logger.Error(context.Background(), "Failed to reset command usage", log.Fields{"error": err})
+		return fmt.Errorf("failed to reset command usage: %w", err)
+	}
+	return nil
+}
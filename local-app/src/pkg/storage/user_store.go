@@ -38,31 +38,26 @@ func (s *UserStorage) UserAdd(newUser model.UserInfo) (int, error) {
 	db := s.storage.GetDatabase()
 	now := time.Now()
 
-	err := db.Begin()
-	if err != nil {
-		s.logger.Error(context.Background(), "Failed to begin transaction", log.Fields{"error": err})
-		return 0, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer db.Rollback()
-
-	result, err := db.Exec(
-		"INSERT INTO users (username, password_hash, active, created, updated) VALUES (?, ?, ?, ?, ?)",
-		newUser.Username, newUser.PasswordHash, newUser.Active, now, now,
-	)
-	if err != nil {
-		s.logger.Error(context.Background(), "Failed to add user", log.Fields{"error": err, "user": newUser})
-		return 0, fmt.Errorf("failed to add user: %w", err)
-	}
+	var id int64
+	err := db.Batch(func() error {
+		result, err := db.Exec(
+			"INSERT INTO users (username, username_key, password_hash, active, created, updated) VALUES (?, ?, ?, ?, ?, ?)",
+			newUser.Username, s.storage.nameKey(newUser.Username), newUser.PasswordHash, newUser.Active, now, now,
+		)
+		if err != nil {
+			s.logger.Error(context.Background(), "Failed to add user", log.Fields{"error": err, "user": newUser})
+			return fmt.Errorf("failed to add user: %w", err)
+		}
 
-	id, err := result.LastInsertId()
+		id, err = result.LastInsertId()
+		if err != nil {
+			s.logger.Error(context.Background(), "Failed to get last insert ID", log.Fields{"error": err})
+			return fmt.Errorf("failed to get last insert ID: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		s.logger.Error(context.Background(), "Failed to get last insert ID", log.Fields{"error": err})
-		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
-	}
-
-	if err := db.Commit(); err != nil {
-		s.logger.Error(context.Background(), "Failed to commit transaction", log.Fields{"error": err})
-		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+		return 0, err
 	}
 
 	s.logger.Info(context.Background(), "User added successfully", log.Fields{"userID": id, "username": newUser.Username})
@@ -74,7 +69,7 @@ func (s *UserStorage) UserGet(userInfo model.UserInfo, userFilter model.UserFilt
 	s.logger.Info(context.Background(), "Retrieving users", log.Fields{"filter": userFilter})
 
 	db := s.storage.GetDatabase()
-	query := "SELECT id, username, password_hash, active, created, updated FROM users WHERE 1=1"
+	query := "SELECT id, username, password_hash, active, default_mindmap, inbox, created, updated FROM users WHERE 1=1"
 	var args []interface{}
 
 	if userFilter.ID {
@@ -82,8 +77,8 @@ func (s *UserStorage) UserGet(userInfo model.UserInfo, userFilter model.UserFilt
 		args = append(args, userInfo.ID)
 	}
 	if userFilter.Username {
-		query += " AND username = ?"
-		args = append(args, userInfo.Username)
+		query += " AND username_key = ?"
+		args = append(args, s.storage.nameKey(userInfo.Username))
 	}
 	if userFilter.Active {
 		query += " AND active = ?"
@@ -100,7 +95,7 @@ func (s *UserStorage) UserGet(userInfo model.UserInfo, userFilter model.UserFilt
 	var users []*model.User
 	for rows.Next() {
 		var u model.User
-		err := rows.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Active, &u.Created, &u.Updated)
+		err := rows.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Active, &u.DefaultMindmap, &u.Inbox, &u.Created, &u.Updated)
 		if err != nil {
 			s.logger.Error(context.Background(), "Failed to scan user row", log.Fields{"error": err})
 			return nil, fmt.Errorf("failed to scan user row: %w", err)
@@ -119,15 +114,15 @@ func (s *UserStorage) UserGet(userInfo model.UserInfo, userFilter model.UserFilt
 
 // UserUpdate updates an existing user in the database.
 func (s *UserStorage) UserUpdate(user *model.User, userUpdateInfo model.UserInfo, userFilter model.UserFilter) error {
-	s.logger.Info(context.Background(), "Deleting user", log.Fields{"user": user})
+	s.logger.Info(context.Background(), "Updating user", log.Fields{"user": user})
 
 	db := s.storage.GetDatabase()
-	query := "UPDATE users SET updated = ? WHERE id = ?"
-	args := []interface{}{time.Now(), user.ID}
+	query := "UPDATE users SET updated = ?"
+	args := []interface{}{time.Now()}
 
 	if userFilter.Username {
-		query += ", username = ?"
-		args = append(args, userUpdateInfo.Username)
+		query += ", username = ?, username_key = ?"
+		args = append(args, userUpdateInfo.Username, s.storage.nameKey(userUpdateInfo.Username))
 	}
 	if userFilter.PasswordHash {
 		query += ", password_hash = ?"
@@ -137,6 +132,16 @@ func (s *UserStorage) UserUpdate(user *model.User, userUpdateInfo model.UserInfo
 		query += ", active = ?"
 		args = append(args, userUpdateInfo.Active)
 	}
+	if userFilter.DefaultMindmap {
+		query += ", default_mindmap = ?"
+		args = append(args, userUpdateInfo.DefaultMindmap)
+	}
+	if userFilter.Inbox {
+		query += ", inbox = ?"
+		args = append(args, userUpdateInfo.Inbox)
+	}
+	query += " WHERE id = ?"
+	args = append(args, user.ID)
 
 	_, err := db.Exec(query, args...)
 	if err != nil {
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"mindnoscape/local-app/src/pkg/log"
+	"mindnoscape/local-app/src/pkg/model"
+)
+
+// VersionStore defines the interface for mindmap version storage operations.
+type VersionStore interface {
+	VersionAdd(version *model.MindmapVersion, mindmap *model.Mindmap) error
+	VersionGet(mindmapID int) ([]*model.MindmapVersion, error)
+	VersionMindmap(mindmapID, version int) (*model.Mindmap, error)
+	VersionDelete(mindmapID int, versions []int) error
+	VersionDeleteMindmap(mindmapID int) error
+}
+
+// VersionStorage implements the VersionStore interface.
+type VersionStorage struct {
+	storage *Storage
+	logger  *log.Logger
+}
+
+// NewVersionStorage creates a new VersionStorage instance.
+func NewVersionStorage(storage *Storage) *VersionStorage {
+	return &VersionStorage{
+		storage: storage,
+		logger:  storage.logger,
+	}
+}
+
+// VersionAdd stores a copy of a mindmap as its next version, setting the version number. The mindmap is stored as
+// gzip compressed JSON, as exported.
+func (s *VersionStorage) VersionAdd(version *model.MindmapVersion, mindmap *model.Mindmap) error {
+	s.logger.Debug(context.Background(), "Adding mindmap version", log.Fields{"mindmapID": version.MindmapID, "label": version.Label})
+
+	var data bytes.Buffer
+	writer, err := compressWriter(&data, model.CompressionGzip)
+	if err != nil {
+		return fmt.Errorf("failed to compress mindmap version: %w", err)
+	}
+	if err := json.NewEncoder(writer).Encode(mindmap); err != nil {
+		s.logger.Error(context.Background(), "Failed to encode mindmap version", log.Fields{"error": err, "mindmapID": version.MindmapID})
+		return fmt.Errorf("failed to encode mindmap version: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress mindmap version: %w", err)
+	}
+
+	db := s.storage.GetDatabase()
+	err = db.QueryRow(
+		`INSERT INTO mindmap_versions (mindmap_id, version, label, auto, node_count, data, created)
+		SELECT ?, COALESCE(MAX(version), 0) + 1, ?, ?, ?, ?, ? FROM mindmap_versions WHERE mindmap_id = ?
+		RETURNING version`,
+		version.MindmapID, version.Label, version.Auto, version.NodeCount, data.Bytes(), version.Created, version.MindmapID,
+	).Scan(&version.Version)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to add mindmap version", log.Fields{"error": err, "mindmapID": version.MindmapID})
+		return fmt.Errorf("failed to add mindmap version: %w", err)
+	}
+	return nil
+}
+
+// VersionGet retrieves the versions of a mindmap without their data, newest first.
+func (s *VersionStorage) VersionGet(mindmapID int) ([]*model.MindmapVersion, error) {
+	s.logger.Debug(context.Background(), "Retrieving mindmap versions", log.Fields{"mindmapID": mindmapID})
+
+	db := s.storage.GetDatabase()
+	rows, err := db.Query("SELECT mindmap_id, version, label, auto, node_count, created FROM mindmap_versions WHERE mindmap_id = ? ORDER BY version DESC", mindmapID)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query mindmap versions", log.Fields{"error": err, "mindmapID": mindmapID})
+		return nil, fmt.Errorf("failed to query mindmap versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*model.MindmapVersion
+	for rows.Next() {
+		var v model.MindmapVersion
+		if err := rows.Scan(&v.MindmapID, &v.Version, &v.Label, &v.Auto, &v.NodeCount, &v.Created); err != nil {
+			s.logger.Error(context.Background(), "Failed to scan mindmap version row", log.Fields{"error": err})
+			return nil, fmt.Errorf("failed to scan mindmap version row: %w", err)
+		}
+		versions = append(versions, &v)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Error(context.Background(), "Error iterating mindmap version rows", log.Fields{"error": err})
+		return nil, fmt.Errorf("error iterating mindmap version rows: %w", err)
+	}
+	return versions, nil
+}
+
+// VersionMindmap retrieves the copy of a mindmap stored as one of its versions.
+func (s *VersionStorage) VersionMindmap(mindmapID, version int) (*model.Mindmap, error) {
+	s.logger.Debug(context.Background(), "Retrieving mindmap version", log.Fields{"mindmapID": mindmapID, "version": version})
+
+	var data []byte
+	db := s.storage.GetDatabase()
+	err := db.QueryRow("SELECT data FROM mindmap_versions WHERE mindmap_id = ? AND version = ?", mindmapID, version).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("version %d not found", version)
+	}
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to query mindmap version", log.Fields{"error": err, "mindmapID": mindmapID, "version": version})
+		return nil, fmt.Errorf("failed to query mindmap version: %w", err)
+	}
+
+	reader, err := decompressReader(bytes.NewReader(data), model.CompressionGzip)
+	if err != nil {
+		s.logger.Error(context.Background(), "Failed to decompress mindmap version", log.Fields{"error": err, "mindmapID": mindmapID, "version": version})
+		return nil, fmt.Errorf("failed to decompress mindmap version: %w", err)
+	}
+	defer reader.Close()
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress mindmap version: %w", err)
+	}
+	var mindmap model.Mindmap
+	if err := json.Unmarshal(decoded, &mindmap); err != nil {
+		s.logger.Error(context.Background(), "Failed to decode mindmap version", log.Fields{"error": err, "mindmapID": mindmapID, "version": version})
+		return nil, fmt.Errorf("failed to decode mindmap version: %w", err)
+	}
+	return &mindmap, nil
+}
+
+// VersionDelete deletes versions of a mindmap.
+func (s *VersionStorage) VersionDelete(mindmapID int, versions []int) error {
+	if len(versions) == 0 {
+		return nil
+	}
+	s.logger.Debug(context.Background(), "Deleting mindmap versions", log.Fields{"mindmapID": mindmapID, "versions": versions})
+
+	args := []interface{}{mindmapID}
+	for _, version := range versions {
+		args = append(args, version)
+	}
+	db := s.storage.GetDatabase()
+	query := "DELETE FROM mindmap_versions WHERE mindmap_id = ? AND version IN (?" + strings.Repeat(", ?", len(versions)-1) + ")"
+	if _, err := db.Exec(query, args...); err != nil {
+		s.logger.Error(context.Background(), "Failed to delete mindmap versions", log.Fields{"error": err, "mindmapID": mindmapID})
+		return fmt.Errorf("failed to delete mindmap versions: %w", err)
+	}
+	return nil
+}
+
+// VersionDeleteMindmap deletes all versions of a mindmap.
+func (s *VersionStorage) VersionDeleteMindmap(mindmapID int) error {
+	s.logger.Info(context.Background(), "Deleting all versions of mindmap", log.Fields{"mindmapID": mindmapID})
+
+	db := s.storage.GetDatabase()
+	if _, err := db.Exec("DELETE FROM mindmap_versions WHERE mindmap_id = ?", mindmapID); err != nil {
+		s.logger.Error(context.Background(), "Failed to delete mindmap versions", log.Fields{"error": err, "mindmapID": mindmapID})
+		return fmt.Errorf("failed to delete mindmap versions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+// Package terminal adapts the terminal of the platform for the escape sequences the CLI and the log viewer write.
+package terminal
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package terminal
+
+// EnableSequences does nothing where terminals process ANSI escape sequences by default
+func EnableSequences() error {
+	return nil
+}
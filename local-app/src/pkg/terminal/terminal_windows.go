@@ -0,0 +1,25 @@
+//go:build windows
+
+package terminal
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// EnableSequences turns on the processing of ANSI escape sequences by the Windows console of the standard output
+// and error, which consoles before Windows Terminal leave off, so colors and cursor movements show instead of
+// the raw sequences. Outputs that are not a console are left as they are.
+func EnableSequences() error {
+	for _, handle := range []windows.Handle{windows.Stdout, windows.Stderr} {
+		var mode uint32
+		if err := windows.GetConsoleMode(handle, &mode); err != nil {
+			continue
+		}
+		if err := windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+			return fmt.Errorf("failed to enable escape sequences of the console: %w", err)
+		}
+	}
+	return nil
+}